@@ -23,7 +23,11 @@ type MetadataHTTPServer interface {
 }
 
 func RegisterMetadataHTTPServer(s *http.Server, srv MetadataHTTPServer) {
-	r := s.Route("/")
+	RegisterMetadataHTTPServerTo(s.Route("/"), srv)
+}
+
+// RegisterMetadataHTTPServerTo registers the routes of the service to the router, e.g. of a group of a prefix and filters.
+func RegisterMetadataHTTPServerTo(r *http.Router, srv MetadataHTTPServer) {
 	r.GET("/services", _Metadata_ListServices0_HTTP_Handler(srv))
 	r.GET("/services/{name}", _Metadata_GetServiceDesc0_HTTP_Handler(srv))
 }
@@ -3,6 +3,7 @@ package kratos
 import (
 	"context"
 	"errors"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
@@ -33,6 +34,9 @@ type App struct {
 	cancel   context.CancelFunc
 	mu       sync.Mutex
 	instance *registry.ServiceInstance
+	drain    sync.Once
+	// failure is the first error reported by ReportError.
+	failure error
 }
 
 // New create an application lifecycle manager.
@@ -40,6 +44,7 @@ func New(opts ...Option) *App {
 	o := options{
 		ctx:              context.Background(),
 		sigs:             []os.Signal{syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT},
+		reloadSigs:       defaultReloadSignals(),
 		registrarTimeout: 10 * time.Second,
 		stopTimeout:      10 * time.Second,
 	}
@@ -82,6 +87,10 @@ func (a *App) Endpoint() []string {
 
 // Run executes all OnStart hooks registered with the application's Lifecycle.
 func (a *App) Run() error {
+	levels, err := a.levels()
+	if err != nil {
+		return err
+	}
 	instance, err := a.buildInstance()
 	if err != nil {
 		return err
@@ -91,28 +100,50 @@ func (a *App) Run() error {
 	a.mu.Unlock()
 	sctx := NewContext(a.ctx, a)
 	eg, ctx := errgroup.WithContext(sctx)
-	wg := sync.WaitGroup{}
 
 	for _, fn := range a.opts.beforeStart {
 		if err = fn(sctx); err != nil {
 			return err
 		}
 	}
-	for _, srv := range a.opts.servers {
-		srv := srv
-		eg.Go(func() error {
-			<-ctx.Done() // wait for stop signal
-			stopCtx, cancel := context.WithTimeout(NewContext(a.opts.ctx, a), a.opts.stopTimeout)
-			defer cancel()
-			return srv.Stop(stopCtx)
-		})
-		wg.Add(1)
-		eg.Go(func() error {
-			wg.Done() // here is to ensure server start has begun running before register, so defer is not needed
-			return srv.Start(NewContext(a.opts.ctx, a))
-		})
-	}
-	wg.Wait()
+	if err = a.checkDependencies(sctx); err != nil {
+		return err
+	}
+	var (
+		mu      sync.Mutex
+		started [][]Component
+	)
+	mu.Lock()
+	eg.Go(func() error {
+		<-ctx.Done() // wait for stop signal
+		mu.Lock()
+		defer mu.Unlock()
+		var err error
+		for i := len(started) - 1; i >= 0; i-- {
+			if e := a.stopLevel(started[i]); e != nil && err == nil {
+				err = e
+			}
+		}
+		return err
+	})
+	for _, level := range levels {
+		comps, e := a.startLevel(ctx, eg, level)
+		started = append(started, comps)
+		if e != nil {
+			mu.Unlock()
+			a.cancel()
+			_ = eg.Wait()
+			return e
+		}
+	}
+	mu.Unlock()
+	if a.opts.selfTestTimeout > 0 {
+		if err = a.selfTest(ctx, instance); err != nil {
+			a.cancel()
+			_ = eg.Wait()
+			return err
+		}
+	}
 	if a.opts.registrar != nil {
 		rctx, rcancel := context.WithTimeout(ctx, a.opts.registrarTimeout)
 		defer rcancel()
@@ -128,20 +159,39 @@ func (a *App) Run() error {
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, a.opts.sigs...)
+	defer signal.Stop(c)
+	var rc chan os.Signal
+	if len(a.opts.reload) > 0 && len(a.opts.reloadSigs) > 0 {
+		rc = make(chan os.Signal, 1)
+		signal.Notify(rc, a.opts.reloadSigs...)
+		defer signal.Stop(rc)
+	}
 	eg.Go(func() error {
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-c:
-			return a.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-c:
+				return a.Stop()
+			case <-rc:
+				_ = a.Reload()
+			}
 		}
 	})
+	eg.Go(func() error {
+		return a.runService(ctx)
+	})
 	if err = eg.Wait(); err != nil && !errors.Is(err, context.Canceled) {
 		return err
 	}
 	for _, fn := range a.opts.afterStop {
 		err = fn(sctx)
 	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.failure != nil {
+		return a.failure
+	}
 	return err
 }
 
@@ -152,15 +202,8 @@ func (a *App) Stop() (err error) {
 		err = fn(sctx)
 	}
 
-	a.mu.Lock()
-	instance := a.instance
-	a.mu.Unlock()
-	if a.opts.registrar != nil && instance != nil {
-		ctx, cancel := context.WithTimeout(NewContext(a.ctx, a), a.opts.registrarTimeout)
-		defer cancel()
-		if err = a.opts.registrar.Deregister(ctx, instance); err != nil {
-			return err
-		}
+	if e := a.Drain(sctx); e != nil {
+		return e
 	}
 	if a.cancel != nil {
 		a.cancel()
@@ -168,18 +211,74 @@ func (a *App) Stop() (err error) {
 	return err
 }
 
+// Drain deregisters the instance, drains the servers and waits for the drain
+// delay, so that the load balancers stop sending new requests before the servers
+// stop. It is called by Stop, or earlier by a pre-stop hook, e.g. the DrainHandler.
+func (a *App) Drain(ctx context.Context) (err error) {
+	a.drain.Do(func() {
+		a.mu.Lock()
+		instance := a.instance
+		a.mu.Unlock()
+		if a.opts.registrar != nil && instance != nil {
+			rctx, cancel := context.WithTimeout(NewContext(a.ctx, a), a.opts.registrarTimeout)
+			defer cancel()
+			if err = a.opts.registrar.Deregister(rctx, instance); err != nil {
+				return
+			}
+		}
+		for _, srv := range a.servers() {
+			if d, ok := srv.(transport.Drainer); ok {
+				if e := d.Drain(ctx); e != nil {
+					log.Errorf("failed to drain server: %v", e)
+				}
+			}
+		}
+		if a.opts.drainDelay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(a.opts.drainDelay):
+			}
+		}
+	})
+	return err
+}
+
+// DrainHandler returns an HTTP handler which drains the app, e.g. for the
+// pre-stop hook of kubernetes, it responds once the drain delay has passed.
+func (a *App) DrainHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := a.Drain(req.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// Reload executes all Reload hooks registered with the application.
+func (a *App) Reload() (err error) {
+	sctx := NewContext(a.ctx, a)
+	for _, fn := range a.opts.reload {
+		if e := fn(sctx); e != nil {
+			log.Errorf("failed to reload app: %v", e)
+			err = e
+		}
+	}
+	return err
+}
+
 func (a *App) buildInstance() (*registry.ServiceInstance, error) {
 	endpoints := make([]string, 0, len(a.opts.endpoints))
 	for _, e := range a.opts.endpoints {
 		endpoints = append(endpoints, e.String())
 	}
 	if len(endpoints) == 0 {
-		for _, srv := range a.opts.servers {
-			if r, ok := srv.(transport.Endpointer); ok {
-				e, err := r.Endpoint()
-				if err != nil {
-					return nil, err
-				}
+		for _, srv := range a.servers() {
+			es, err := serverEndpoints(srv)
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range es {
 				endpoints = append(endpoints, e.String())
 			}
 		}
@@ -3,8 +3,11 @@ package kratos
 import (
 	"context"
 	"errors"
+	stdhttp "net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -283,3 +286,92 @@ func TestApp_Context(t *testing.T) {
 		})
 	}
 }
+
+type drainServer struct {
+	events *[]string
+}
+
+func (s drainServer) Start(context.Context) error { return nil }
+
+func (s drainServer) Stop(context.Context) error {
+	*s.events = append(*s.events, "stop")
+	return nil
+}
+
+func (s drainServer) Drain(context.Context) error {
+	*s.events = append(*s.events, "drain")
+	return nil
+}
+
+type drainRegistrar struct {
+	events *[]string
+}
+
+func (r drainRegistrar) Register(context.Context, *registry.ServiceInstance) error { return nil }
+
+func (r drainRegistrar) Deregister(context.Context, *registry.ServiceInstance) error {
+	*r.events = append(*r.events, "deregister")
+	return nil
+}
+
+func TestApp_Drain(t *testing.T) {
+	var events []string
+	app := New(
+		Server(drainServer{events: &events}),
+		Registrar(drainRegistrar{events: &events}),
+		DrainDelay(50*time.Millisecond),
+	)
+	app.instance = &registry.ServiceInstance{ID: "1"}
+	start := time.Now()
+	res := httptest.NewRecorder()
+	app.DrainHandler().ServeHTTP(res, httptest.NewRequest(stdhttp.MethodPost, "/drain", nil))
+	if res.Code != stdhttp.StatusOK {
+		t.Fatalf("expected 200 got %d", res.Code)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Error("expected the drain delay waited")
+	}
+	if err := app.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(events, []string{"deregister", "drain"}) {
+		t.Errorf("expected drained once got %v", events)
+	}
+}
+
+func TestApp_SelfTest(t *testing.T) {
+	var (
+		app     *App
+		started bool
+	)
+	app = New(
+		Server(http.NewServer(http.Address("127.0.0.1:0")), grpc.NewServer(grpc.Address("127.0.0.1:0"))),
+		SelfTest(time.Second),
+		AfterStart(func(ctx context.Context) error {
+			started = true
+			go func() { _ = app.Stop() }()
+			return nil
+		}),
+	)
+	if err := app.Run(); err != nil || !started {
+		t.Fatalf("expected the self-test passed, got %v", err)
+	}
+
+	u, _ := url.Parse("http://127.0.0.1:1")
+	app = New(
+		Server(http.NewServer(http.Address("127.0.0.1:0"))),
+		Endpoint(u),
+		SelfTest(200*time.Millisecond),
+		Registrar(&mockRegistry{service: make(map[string]*registry.ServiceInstance)}),
+	)
+	done := make(chan error, 1)
+	go func() { done <- app.Run() }()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "self-test of endpoint http://127.0.0.1:1") {
+			t.Errorf("expected the self-test failed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the app failed to run")
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package kratos
+
+import (
+	"context"
+	"os"
+	"syscall"
+)
+
+func defaultReloadSignals() []os.Signal {
+	return []os.Signal{syscall.SIGHUP}
+}
+
+// runService is only meaningful on platforms with a service control manager.
+func (a *App) runService(context.Context) error {
+	return nil
+}
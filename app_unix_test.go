@@ -0,0 +1,35 @@
+//go:build !windows
+
+package kratos
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestApp_ReloadSignal(t *testing.T) {
+	reloaded := make(chan struct{}, 1)
+	app := New(
+		Name("kratos"),
+		Reload(func(_ context.Context) error {
+			reloaded <- struct{}{}
+			return nil
+		}),
+	)
+	time.AfterFunc(100*time.Millisecond, func() {
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	})
+	go func() {
+		select {
+		case <-reloaded:
+		case <-time.After(3 * time.Second):
+			t.Error("expected reload hook to be called")
+		}
+		_ = app.Stop()
+	}()
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,60 @@
+//go:build windows
+
+package kratos
+
+import (
+	"context"
+	"os"
+
+	"golang.org/x/sys/windows/svc"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// windows has no SIGHUP equivalent, reload is driven by the service control manager.
+func defaultReloadSignals() []os.Signal {
+	return nil
+}
+
+// runService runs the app under the windows service control manager
+// when the process was started as a windows service.
+func (a *App) runService(ctx context.Context) error {
+	ok, err := svc.IsWindowsService()
+	if err != nil || !ok {
+		return err
+	}
+	return svc.Run(a.opts.name, &service{app: a, ctx: ctx})
+}
+
+type service struct {
+	app *App
+	ctx context.Context
+}
+
+// Execute implements svc.Handler.
+func (s *service) Execute(_ []string, req <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptParamChange
+	status <- svc.Status{State: svc.StartPending}
+	status <- svc.Status{State: svc.Running, Accepts: accepts}
+	for {
+		select {
+		case <-s.ctx.Done():
+			status <- svc.Status{State: svc.StopPending}
+			return false, 0
+		case c := <-req:
+			switch c.Cmd {
+			case svc.Interrogate:
+				status <- c.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				if err := s.app.Stop(); err != nil {
+					log.Errorf("failed to stop windows service: %v", err)
+				}
+				return false, 0
+			case svc.ParamChange:
+				_ = s.app.Reload()
+				status <- c.CurrentStatus
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// CmdRoutes represents the routes command.
+var CmdRoutes = &cobra.Command{
+	Use:   "routes",
+	Short: "Export the routes of a service",
+	Long:  "Export the routes of a running service served by routes.Handler in JSON. Example: kratos routes http://127.0.0.1:9090/debug/routes -o routes.json",
+	Args:  cobra.ExactArgs(1),
+	Run:   run,
+}
+
+var (
+	output  string
+	timeout time.Duration
+)
+
+func init() {
+	CmdRoutes.Flags().StringVarP(&output, "output", "o", "", "output file, default is stdout")
+	CmdRoutes.Flags().DurationVarP(&timeout, "timeout", "t", 10*time.Second, "request timeout")
+}
+
+func run(_ *cobra.Command, args []string) {
+	if err := export(args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "\033[31mERROR: %s\033[m\n", err)
+		os.Exit(1)
+	}
+}
+
+func export(url string) error {
+	client := &http.Client{Timeout: timeout}
+	res, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s: %s", res.Status, bytes.TrimSpace(data))
+	}
+	var doc struct {
+		Routes []json.RawMessage `json:"routes"`
+	}
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid routes document: %w", err)
+	}
+	var buf bytes.Buffer
+	if err = json.Indent(&buf, data, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	if output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err = os.WriteFile(output, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("%d routes exported to %s\n", len(doc.Routes), output)
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/change"
 	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/project"
 	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/proto"
+	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/routes"
 	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/run"
 	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/upgrade"
 )
@@ -25,6 +26,7 @@ func init() {
 	rootCmd.AddCommand(upgrade.CmdUpgrade)
 	rootCmd.AddCommand(change.CmdChange)
 	rootCmd.AddCommand(run.CmdRun)
+	rootCmd.AddCommand(routes.CmdRoutes)
 }
 
 func main() {
@@ -0,0 +1,76 @@
+// Code generated by {{.Generator}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	context "context"
+	http "github.com/go-kratos/kratos/v2/transport/http"
+	binding "github.com/go-kratos/kratos/v2/transport/http/binding"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the kratos package it is being compiled against.
+var _ = new(context.Context)
+var _ = binding.EncodeURL
+
+const _ = http.SupportPackageIsVersion1
+
+{{range .Types}}
+{{- if .Comment}}
+// {{.Name}} {{.Comment}}
+{{- end}}
+{{- if .Type}}
+type {{.Name}} {{.Type}}
+{{else}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{- if .Comment}}
+	// {{.Comment}}
+	{{- end}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+}
+{{end}}
+{{end}}
+
+{{- $svrType := .ServiceType -}}
+{{- $svrName := .ServiceName -}}
+{{range .Methods}}
+const Operation{{$svrType}}{{.Name}} = "/{{$svrName}}/{{.OriginalName}}"
+{{- end}}
+
+type {{.ServiceType}}HTTPClient interface {
+{{- range .Methods}}
+	{{- if .Comment}}
+	// {{.Name}} {{.Comment}}
+	{{- end}}
+	{{.Name}}(ctx context.Context, req *{{.Request}}, opts ...http.CallOption) (rsp *{{.Reply}}, err error)
+{{- end}}
+}
+
+type {{.ServiceType}}HTTPClientImpl struct{
+	cc *http.Client
+}
+
+func New{{.ServiceType}}HTTPClient (client *http.Client) {{.ServiceType}}HTTPClient {
+	return &{{.ServiceType}}HTTPClientImpl{client}
+}
+
+{{range .Methods}}
+func (c *{{$svrType}}HTTPClientImpl) {{.Name}}(ctx context.Context, in *{{.Request}}, opts ...http.CallOption) (*{{.Reply}}, error) {
+	var out {{.Reply}}
+	pattern := "{{.Path}}"
+	path := binding.EncodeURL(pattern, in, true)
+	opts = append(opts, http.Operation(Operation{{$svrType}}{{.Name}}))
+	opts = append(opts, http.PathTemplate(pattern))
+	{{if .HasBody -}}
+	err := c.cc.Invoke(ctx, "{{.Method}}", path, in.Body, &out, opts...)
+	{{else -}}
+	err := c.cc.Invoke(ctx, "{{.Method}}", path, nil, &out, opts...)
+	{{end -}}
+	if err != nil {
+		return nil, err
+	}
+	return &out, err
+}
+{{end}}
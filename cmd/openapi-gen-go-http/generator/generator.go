@@ -0,0 +1,541 @@
+// Package generator generates kratos HTTP clients from OpenAPI 3 documents.
+package generator
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"go/format"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed client.tpl
+var clientTemplate string
+
+// Option is generator option.
+type Option func(*options)
+
+type options struct {
+	packageName string
+	serviceName string
+	generator   string
+}
+
+// WithPackage with the package name of the generated file, default is api.
+func WithPackage(name string) Option {
+	return func(o *options) {
+		o.packageName = name
+	}
+}
+
+// WithService with the service name of the generated client,
+// default is derived from the title of the document.
+func WithService(name string) Option {
+	return func(o *options) {
+		o.serviceName = name
+	}
+}
+
+// WithGenerator with the generator name written into the file header.
+func WithGenerator(name string) Option {
+	return func(o *options) {
+		o.generator = name
+	}
+}
+
+type fileDesc struct {
+	Generator   string
+	Package     string
+	ServiceType string
+	ServiceName string
+	Types       []*typeDesc
+	Methods     []*methodDesc
+}
+
+type typeDesc struct {
+	Name    string
+	Comment string
+	// Type is set for non struct types, e.g. []Pet.
+	Type   string
+	Fields []*fieldDesc
+}
+
+type fieldDesc struct {
+	Name    string
+	Type    string
+	Tag     string
+	Comment string
+}
+
+type methodDesc struct {
+	Name         string
+	OriginalName string
+	Comment      string
+	Method       string
+	Path         string
+	Request      string
+	Reply        string
+	HasBody      bool
+}
+
+// Generate generates the kratos HTTP client of an OpenAPI 3 document in YAML or JSON.
+func Generate(spec []byte, opts ...Option) ([]byte, error) {
+	o := &options{packageName: "api", generator: "openapi-gen-go-http"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	doc, err := parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	if o.serviceName == "" {
+		o.serviceName = goName(doc.Info.Title)
+	}
+	if o.serviceName == "" {
+		return nil, fmt.Errorf("openapi: missing service name, the document has no title")
+	}
+	g := &generator{
+		doc:     doc,
+		types:   make(map[string]*typeDesc),
+		named:   make(map[string]string),
+		structs: make(map[string]bool),
+	}
+	file := &fileDesc{
+		Generator:   o.generator,
+		Package:     o.packageName,
+		ServiceType: o.serviceName,
+		ServiceName: o.serviceName,
+	}
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.component(name)
+	}
+	if file.Methods, err = g.methods(); err != nil {
+		return nil, err
+	}
+	file.Types = g.order
+	buf := new(bytes.Buffer)
+	tmpl, err := template.New("client").Parse(strings.TrimSpace(clientTemplate))
+	if err != nil {
+		return nil, err
+	}
+	if err = tmpl.Execute(buf, file); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("openapi: format generated code: %w", err)
+	}
+	return src, nil
+}
+
+type generator struct {
+	doc   *document
+	types map[string]*typeDesc
+	order []*typeDesc
+	// named maps the component schema names to the type names.
+	named   map[string]string
+	structs map[string]bool
+}
+
+var methodOrder = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodPatch, http.MethodHead, http.MethodOptions,
+}
+
+func (g *generator) methods() ([]*methodDesc, error) {
+	paths := make([]string, 0, len(g.doc.Paths))
+	for p := range g.doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var methods []*methodDesc
+	for _, p := range paths {
+		item := g.doc.Paths[p]
+		ops := map[string]*operation{
+			http.MethodGet: item.Get, http.MethodPut: item.Put, http.MethodPost: item.Post,
+			http.MethodDelete: item.Delete, http.MethodPatch: item.Patch,
+			http.MethodHead: item.Head, http.MethodOptions: item.Options,
+		}
+		for _, method := range methodOrder {
+			op := ops[method]
+			if op == nil {
+				continue
+			}
+			m, err := g.method(p, method, item, op)
+			if err != nil {
+				return nil, err
+			}
+			methods = append(methods, m)
+		}
+	}
+	return methods, nil
+}
+
+func (g *generator) method(path, method string, item *pathItem, op *operation) (*methodDesc, error) {
+	original := op.OperationID
+	if original == "" {
+		original = goName(strings.ToLower(method) + " " + path)
+	}
+	name := g.unique(goName(original))
+	m := &methodDesc{
+		Name:         name,
+		OriginalName: original,
+		Comment:      comment(op.Summary, op.Description),
+		Method:       method,
+		Path:         path,
+	}
+	req := &typeDesc{Name: g.unique(name + "Request")}
+	g.add(req)
+	params := make(map[string]*parameter)
+	var order []string
+	for _, p := range append(append([]*parameter(nil), item.Parameters...), op.Parameters...) {
+		p, err := g.parameter(p)
+		if err != nil {
+			return nil, err
+		}
+		if p.In != "path" && p.In != "query" {
+			continue
+		}
+		if _, ok := params[p.Name]; !ok {
+			order = append(order, p.Name)
+		}
+		params[p.Name] = p
+	}
+	for _, key := range order {
+		p := params[key]
+		req.Fields = append(req.Fields, &fieldDesc{
+			Name:    goName(p.Name),
+			Type:    g.goType(p.Schema, req.Name+goName(p.Name), false),
+			Tag:     fmt.Sprintf("`json:%q`", p.Name+",omitempty"),
+			Comment: comment(p.Description, ""),
+		})
+	}
+	if op.RequestBody != nil {
+		b, err := g.body(op.RequestBody)
+		if err != nil {
+			return nil, err
+		}
+		if s := jsonSchema(b); s != nil {
+			m.HasBody = true
+			req.Fields = append(req.Fields, &fieldDesc{
+				Name:    "Body",
+				Type:    g.goType(s, req.Name+"Body", true),
+				Tag:     "`json:\"-\"`",
+				Comment: comment(b.Description, ""),
+			})
+		}
+	}
+	reply, err := g.reply(name, op)
+	if err != nil {
+		return nil, err
+	}
+	m.Request = req.Name
+	m.Reply = reply
+	return m, nil
+}
+
+// reply returns the reply type of the first successful JSON response.
+func (g *generator) reply(name string, op *operation) (string, error) {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		b, err := g.body(op.Responses[code])
+		if err != nil {
+			return "", err
+		}
+		s := jsonSchema(b)
+		if s == nil {
+			continue
+		}
+		if s.Ref != "" {
+			return g.goType(s, "", false), nil
+		}
+		if g.properties(s) != nil {
+			return g.goType(s, name+"Reply", false), nil
+		}
+		reply := &typeDesc{
+			Name:    g.unique(name + "Reply"),
+			Comment: comment(b.Description, ""),
+			Type:    g.goType(s, name+"ReplyItem", false),
+		}
+		g.add(reply)
+		return reply.Name, nil
+	}
+	reply := &typeDesc{Name: g.unique(name + "Reply")}
+	g.add(reply)
+	return reply.Name, nil
+}
+
+func (g *generator) parameter(p *parameter) (*parameter, error) {
+	for p.Ref != "" {
+		name := strings.TrimPrefix(p.Ref, "#/components/parameters/")
+		ref, ok := g.doc.Components.Parameters[name]
+		if !ok {
+			return nil, fmt.Errorf("openapi: unresolved parameter %s", p.Ref)
+		}
+		p = ref
+	}
+	return p, nil
+}
+
+func (g *generator) body(b *body) (*body, error) {
+	for b.Ref != "" {
+		var (
+			ref *body
+			ok  bool
+		)
+		if name := strings.TrimPrefix(b.Ref, "#/components/requestBodies/"); name != b.Ref {
+			ref, ok = g.doc.Components.RequestBodies[name]
+		} else {
+			ref, ok = g.doc.Components.Responses[strings.TrimPrefix(b.Ref, "#/components/responses/")]
+		}
+		if !ok {
+			return nil, fmt.Errorf("openapi: unresolved body %s", b.Ref)
+		}
+		b = ref
+	}
+	return b, nil
+}
+
+// jsonSchema returns the schema of the JSON content.
+func jsonSchema(b *body) *schema {
+	for _, ct := range []string{"application/json", "*/*"} {
+		if mt, ok := b.Content[ct]; ok && mt.Schema != nil {
+			return mt.Schema
+		}
+	}
+	for ct, mt := range b.Content {
+		if strings.HasSuffix(ct, "+json") && mt.Schema != nil {
+			return mt.Schema
+		}
+	}
+	return nil
+}
+
+// component declares the type of the component schema.
+func (g *generator) component(name string) string {
+	if t, ok := g.named[name]; ok {
+		return t
+	}
+	s, ok := g.doc.Components.Schemas[name]
+	if !ok {
+		return "interface{}"
+	}
+	t := &typeDesc{Name: g.unique(goName(name)), Comment: comment(s.Description, "")}
+	g.named[name] = t.Name
+	g.add(t)
+	g.define(t, s)
+	return t.Name
+}
+
+// define fills the type with the schema, as a struct or another type.
+func (g *generator) define(t *typeDesc, s *schema) {
+	props := g.properties(s)
+	if props == nil {
+		t.Type = g.goType(s, t.Name+"Item", false)
+		if g.structs[t.Type] {
+			t.Type = "= " + t.Type
+			g.structs[t.Name] = true
+		}
+		return
+	}
+	g.structs[t.Name] = true
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := props[name]
+		t.Fields = append(t.Fields, &fieldDesc{
+			Name:    goName(name),
+			Type:    g.goType(p, t.Name+goName(name), true),
+			Tag:     fmt.Sprintf("`json:%q`", name+",omitempty"),
+			Comment: comment(p.Description, ""),
+		})
+	}
+}
+
+// properties returns the merged object properties of the schema and its allOf,
+// or nil if the schema is not an object with properties.
+func (g *generator) properties(s *schema) map[string]*schema {
+	if len(s.AllOf) == 0 {
+		if len(s.Properties) == 0 {
+			return nil
+		}
+		return s.Properties
+	}
+	props := make(map[string]*schema)
+	for k, v := range s.Properties {
+		props[k] = v
+	}
+	for _, sub := range s.AllOf {
+		for sub.Ref != "" {
+			ref, ok := g.doc.Components.Schemas[refName(sub.Ref)]
+			if !ok {
+				break
+			}
+			sub = ref
+		}
+		for k, v := range g.properties(sub) {
+			props[k] = v
+		}
+	}
+	return props
+}
+
+// goType returns the Go type of the schema, inline objects are declared with the name hint.
+// Struct types are returned as pointers when ptr is true.
+func (g *generator) goType(s *schema, hint string, ptr bool) string {
+	if s == nil {
+		return "interface{}"
+	}
+	pointer := func(t string) string {
+		if ptr && g.structs[t] {
+			return "*" + t
+		}
+		return t
+	}
+	if s.Ref != "" {
+		return pointer(g.component(refName(s.Ref)))
+	}
+	if len(s.AllOf) == 1 && len(s.Properties) == 0 {
+		return g.goType(s.AllOf[0], hint, ptr)
+	}
+	if g.properties(s) != nil {
+		t := &typeDesc{Name: g.unique(hint), Comment: comment(s.Description, "")}
+		g.add(t)
+		g.define(t, s)
+		return pointer(t.Name)
+	}
+	switch s.Type {
+	case "array":
+		return "[]" + g.goType(s.Items, hint+"Item", true)
+	case "object", "":
+		if s.AdditionalProperties.schema != nil {
+			return "map[string]" + g.goType(s.AdditionalProperties.schema, hint+"Value", true)
+		}
+		if s.Type == "object" {
+			return "map[string]interface{}"
+		}
+		return "interface{}"
+	case "string":
+		return "string"
+	case "integer":
+		if s.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	}
+	return "interface{}"
+}
+
+func (g *generator) add(t *typeDesc) {
+	g.types[t.Name] = t
+	g.order = append(g.order, t)
+}
+
+// unique returns the name, with a number suffix if it is already declared.
+func (g *generator) unique(name string) string {
+	if name == "" {
+		name = "Type"
+	}
+	if _, ok := g.types[name]; !ok {
+		return name
+	}
+	for i := 2; ; i++ {
+		if n := fmt.Sprintf("%s%d", name, i); g.types[n] == nil {
+			return n
+		}
+	}
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// comment returns the first line of the summary or the description.
+func comment(summary, description string) string {
+	c := summary
+	if c == "" {
+		c = description
+	}
+	c = strings.TrimSpace(c)
+	if i := strings.IndexByte(c, '\n'); i >= 0 {
+		c = strings.TrimSpace(c[:i])
+	}
+	return c
+}
+
+var initialisms = map[string]struct{}{
+	"API": {}, "ASCII": {}, "CPU": {}, "CSS": {}, "DNS": {}, "EOF": {}, "GUID": {},
+	"HTML": {}, "HTTP": {}, "HTTPS": {}, "ID": {}, "IP": {}, "JSON": {}, "QPS": {},
+	"RAM": {}, "RPC": {}, "SQL": {}, "SSH": {}, "TCP": {}, "TLS": {}, "TTL": {},
+	"UDP": {}, "UI": {}, "UID": {}, "URI": {}, "URL": {}, "UUID": {}, "XML": {},
+}
+
+// goName converts the name into an exported Go identifier, e.g. pet_id -> PetID.
+func goName(name string) string {
+	var (
+		words []string
+		word  []rune
+	)
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+		if len(word) > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	var b strings.Builder
+	for _, w := range words {
+		if _, ok := initialisms[strings.ToUpper(w)]; ok {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		rs := []rune(strings.ToLower(w))
+		rs[0] = unicode.ToUpper(rs[0])
+		b.WriteString(string(rs))
+	}
+	s := b.String()
+	if s != "" && unicode.IsDigit([]rune(s)[0]) {
+		s = "X" + s
+	}
+	return s
+}
@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	spec, err := os.ReadFile("testdata/petstore.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := Generate(spec, WithPackage("petstore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := strings.Join(strings.Fields(string(src)), " ")
+	for _, want := range []string{
+		"package petstore",
+		"type SwaggerPetstoreHTTPClient interface",
+		"ListPets(ctx context.Context, req *ListPetsRequest, opts ...http.CallOption) (rsp *Pets, err error)",
+		"CreatePet(ctx context.Context, req *CreatePetRequest, opts ...http.CallOption) (rsp *Pet, err error)",
+		"ShowPetByID(ctx context.Context, req *ShowPetByIDRequest, opts ...http.CallOption) (rsp *ShowPetByIDReply, err error)",
+		"DeletePetsPetID(ctx context.Context, req *DeletePetsPetIDRequest, opts ...http.CallOption) (rsp *DeletePetsPetIDReply, err error)",
+		"type Pets []*Pet",
+		"ID int64 `json:\"id,omitempty\"`",
+		"Labels map[string]string `json:\"labels,omitempty\"`",
+		"Tag string `json:\"tag,omitempty\"`",
+		"Limit int32 `json:\"limit,omitempty\"`",
+		"Tags []string `json:\"tags,omitempty\"`",
+		"PetID string `json:\"petId,omitempty\"`",
+		"Body *NewPet `json:\"-\"`",
+		"Owners []*ShowPetByIDReplyOwnersItem `json:\"owners,omitempty\"`",
+		"Pet *Pet `json:\"pet,omitempty\"`",
+		`err := c.cc.Invoke(ctx, "POST", path, in.Body, &out, opts...)`,
+		`const OperationSwaggerPetstoreListPets = "/SwaggerPetstore/listPets"`,
+		`const OperationSwaggerPetstoreDeletePetsPetID = "/SwaggerPetstore/DeletePetsPetID"`,
+	} {
+		if want = strings.Join(strings.Fields(want), " "); !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"petId":            "PetID",
+		"pet_id":           "PetID",
+		"get /pets/{id}":   "GetPetsID",
+		"HTTPServer":       "HTTPServer",
+		"Swagger Petstore": "SwaggerPetstore",
+		"2fa":              "X2fa",
+	}
+	for in, want := range tests {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	if _, err := Generate([]byte(`swagger: "2.0"`)); err == nil {
+		t.Error("expected error for swagger 2 document")
+	}
+}
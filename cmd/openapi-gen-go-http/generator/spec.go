@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is the subset of an OpenAPI 3 document used by the generator.
+type document struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Info       info                 `yaml:"info"`
+	Paths      map[string]*pathItem `yaml:"paths"`
+	Components components           `yaml:"components"`
+}
+
+type info struct {
+	Title string `yaml:"title"`
+}
+
+type components struct {
+	Schemas       map[string]*schema    `yaml:"schemas"`
+	Parameters    map[string]*parameter `yaml:"parameters"`
+	RequestBodies map[string]*body      `yaml:"requestBodies"`
+	Responses     map[string]*body      `yaml:"responses"`
+}
+
+type pathItem struct {
+	Parameters []*parameter `yaml:"parameters"`
+	Get        *operation   `yaml:"get"`
+	Put        *operation   `yaml:"put"`
+	Post       *operation   `yaml:"post"`
+	Delete     *operation   `yaml:"delete"`
+	Patch      *operation   `yaml:"patch"`
+	Head       *operation   `yaml:"head"`
+	Options    *operation   `yaml:"options"`
+}
+
+type operation struct {
+	OperationID string           `yaml:"operationId"`
+	Summary     string           `yaml:"summary"`
+	Description string           `yaml:"description"`
+	Parameters  []*parameter     `yaml:"parameters"`
+	RequestBody *body            `yaml:"requestBody"`
+	Responses   map[string]*body `yaml:"responses"`
+}
+
+type parameter struct {
+	Ref         string  `yaml:"$ref"`
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Description string  `yaml:"description"`
+	Required    bool    `yaml:"required"`
+	Schema      *schema `yaml:"schema"`
+}
+
+// body is a request body or a response.
+type body struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Content     map[string]*mediaType `yaml:"content"`
+}
+
+type mediaType struct {
+	Schema *schema `yaml:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `yaml:"$ref"`
+	Type                 schemaType         `yaml:"type"`
+	Format               string             `yaml:"format"`
+	Description          string             `yaml:"description"`
+	Properties           map[string]*schema `yaml:"properties"`
+	Items                *schema            `yaml:"items"`
+	AdditionalProperties additional         `yaml:"additionalProperties"`
+	AllOf                []*schema          `yaml:"allOf"`
+}
+
+// schemaType is the type of a schema, OpenAPI 3.1 allows a list like [string, "null"].
+type schemaType string
+
+func (t *schemaType) UnmarshalYAML(n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		*t = schemaType(n.Value)
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			if c.Value != "null" {
+				*t = schemaType(c.Value)
+				break
+			}
+		}
+	default:
+		return fmt.Errorf("openapi: invalid schema type at line %d", n.Line)
+	}
+	return nil
+}
+
+// additional is the additionalProperties of a schema, which is a bool or a schema.
+type additional struct {
+	*schema
+}
+
+func (a *additional) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		var allowed bool
+		if err := n.Decode(&allowed); err != nil {
+			return err
+		}
+		if allowed {
+			a.schema = &schema{}
+		}
+		return nil
+	}
+	a.schema = new(schema)
+	return n.Decode(a.schema)
+}
+
+// parse parses an OpenAPI document in YAML or JSON.
+func parse(data []byte) (*document, error) {
+	doc := new(document)
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	if doc.OpenAPI == "" {
+		return nil, fmt.Errorf("openapi: missing openapi version, only OpenAPI 3 documents are supported")
+	}
+	return doc, nil
+}
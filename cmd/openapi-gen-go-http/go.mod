@@ -0,0 +1,5 @@
+module github.com/go-kratos/kratos/cmd/openapi-gen-go-http/v2
+
+go 1.19
+
+require gopkg.in/yaml.v3 v3.0.1
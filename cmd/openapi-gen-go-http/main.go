@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-kratos/kratos/cmd/openapi-gen-go-http/v2/generator"
+)
+
+var (
+	showVersion = flag.Bool("version", false, "print the version and exit")
+	packageName = flag.String("package", "api", "package name of the generated file")
+	serviceName = flag.String("service", "", "service name of the generated client, default is the document title")
+	output      = flag.String("o", "", "output file, default is stdout")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: openapi-gen-go-http [flags] openapi.yaml\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *showVersion {
+		fmt.Printf("openapi-gen-go-http %v\n", release)
+		return
+	}
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0)); err != nil {
+		fmt.Fprintf(os.Stderr, "openapi-gen-go-http: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(spec string) error {
+	data, err := os.ReadFile(spec)
+	if err != nil {
+		return err
+	}
+	src, err := generator.Generate(data,
+		generator.WithPackage(*packageName),
+		generator.WithService(*serviceName),
+		generator.WithGenerator("openapi-gen-go-http "+release),
+	)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*output, src, 0o644)
+}
@@ -0,0 +1,4 @@
+package main
+
+// release is the current openapi-gen-go-http version.
+const release = "v2.7.2"
@@ -15,7 +15,11 @@ type {{.ServiceType}}HTTPServer interface {
 }
 
 func Register{{.ServiceType}}HTTPServer(s *http.Server, srv {{.ServiceType}}HTTPServer) {
-	r := s.Route("/")
+	Register{{.ServiceType}}HTTPServerTo(s.Route("/"), srv)
+}
+
+// Register{{.ServiceType}}HTTPServerTo registers the routes of the service to the router, e.g. of a group of a prefix and filters.
+func Register{{.ServiceType}}HTTPServerTo(r *http.Router, srv {{.ServiceType}}HTTPServer) {
 	{{- range .Methods}}
 	r.{{.Method}}("{{.Path}}", _{{$svrType}}_{{.Name}}{{.Num}}_HTTP_Handler(srv))
 	{{- end}}
@@ -84,3 +88,64 @@ func (c *{{$svrType}}HTTPClientImpl) {{.Name}}(ctx context.Context, in *{{.Reque
 	return &out, err
 }
 {{end}}
+{{- if .Mock}}
+{{$syncMutex := .SyncMutex}}
+{{$errorsNew := .ErrorsNew}}
+
+var _ {{.ServiceType}}HTTPClient = (*{{.ServiceType}}HTTPClientMock)(nil)
+
+// {{.ServiceType}}HTTPClientMock is a mock {{.ServiceType}}HTTPClient for the unit tests, of which
+// the replies are set by the On methods. The calls without the replies return
+// the 501 MOCK_NOT_IMPLEMENTED errors.
+type {{.ServiceType}}HTTPClientMock struct {
+	mu    {{$syncMutex}}
+	funcs map[string]interface{}
+	calls map[string][]interface{}
+}
+
+func New{{.ServiceType}}HTTPClientMock() *{{.ServiceType}}HTTPClientMock {
+	return &{{.ServiceType}}HTTPClientMock{
+		funcs: make(map[string]interface{}),
+		calls: make(map[string][]interface{}),
+	}
+}
+
+{{range .MethodSets}}
+// On{{.Name}} sets the reply of {{.Name}}.
+func (m *{{$svrType}}HTTPClientMock) On{{.Name}}(rsp *{{.Reply}}, err error) *{{$svrType}}HTTPClientMock {
+	return m.On{{.Name}}Func(func(context.Context, *{{.Request}}) (*{{.Reply}}, error) {
+		return rsp, err
+	})
+}
+
+// On{{.Name}}Func sets the func replying {{.Name}}.
+func (m *{{$svrType}}HTTPClientMock) On{{.Name}}Func(fn func(context.Context, *{{.Request}}) (*{{.Reply}}, error)) *{{$svrType}}HTTPClientMock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.funcs["{{.Name}}"] = fn
+	return m
+}
+
+// {{.Name}}Calls returns the requests of the calls of {{.Name}}.
+func (m *{{$svrType}}HTTPClientMock) {{.Name}}Calls() []*{{.Request}} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	calls := make([]*{{.Request}}, 0, len(m.calls["{{.Name}}"]))
+	for _, in := range m.calls["{{.Name}}"] {
+		calls = append(calls, in.(*{{.Request}}))
+	}
+	return calls
+}
+
+func (m *{{$svrType}}HTTPClientMock) {{.Name}}(ctx context.Context, in *{{.Request}}, opts ...http.CallOption) (*{{.Reply}}, error) {
+	m.mu.Lock()
+	m.calls["{{.Name}}"] = append(m.calls["{{.Name}}"], in)
+	fn, _ := m.funcs["{{.Name}}"].(func(context.Context, *{{.Request}}) (*{{.Reply}}, error))
+	m.mu.Unlock()
+	if fn == nil {
+		return nil, {{$errorsNew}}(501, "MOCK_NOT_IMPLEMENTED", "{{$svrType}}HTTPClientMock.{{.Name}} is not mocked")
+	}
+	return fn(ctx, in)
+}
+{{end}}
+{{- end}}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"go/parser"
+	"go/token"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -98,3 +101,42 @@ func TestReplaceBoundary(t *testing.T) {
 		t.Fatal(`"/test/{message.namespace=*}/name/{message.name=*}" should be "/test/{message.namespace:.*}/name/{message.name:.*}"`)
 	}
 }
+
+func TestMockClient(t *testing.T) {
+	sd := &serviceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods:     []*methodDesc{{Name: "SayHello", OriginalName: "SayHello", Request: "HelloRequest", Reply: "HelloReply", Path: "/hello/{name}", Method: "GET", HasVars: true}},
+	}
+	if strings.Contains(sd.execute(), "GreeterHTTPClientMock") {
+		t.Fatal("expected no mock client by default")
+	}
+	sd.Mock, sd.SyncMutex, sd.ErrorsNew = true, "sync.Mutex", "errors.New"
+	src := sd.execute()
+	for _, want := range []string{"func NewGreeterHTTPClientMock()", "OnSayHello(rsp *HelloReply, err error)", "SayHelloCalls() []*HelloRequest"} {
+		if !strings.Contains(src, want) {
+			t.Errorf("expected %q in the mock client", want)
+		}
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "", "package helloworld\n"+src, 0); err != nil {
+		t.Errorf("expected the valid mock client, got %v", err)
+	}
+}
+
+func TestRegisterTo(t *testing.T) {
+	sd := &serviceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods:     []*methodDesc{{Name: "SayHello", OriginalName: "SayHello", Request: "HelloRequest", Reply: "HelloReply", Path: "/hello/{name}", Method: "GET", HasVars: true}},
+	}
+	src := sd.execute()
+	for _, want := range []string{
+		"RegisterGreeterHTTPServerTo(s.Route(\"/\"), srv)",
+		"func RegisterGreeterHTTPServerTo(r *http.Router, srv GreeterHTTPServer) {",
+		"r.GET(\"/hello/{name}\", _Greeter_SayHello0_HTTP_Handler(srv))",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("expected %q in the server", want)
+		}
+	}
+}
@@ -16,6 +16,11 @@ type serviceDesc struct {
 	Metadata    string // api/helloworld/helloworld.proto
 	Methods     []*methodDesc
 	MethodSets  map[string]*methodDesc
+
+	// mock client
+	Mock      bool
+	SyncMutex string // sync.Mutex
+	ErrorsNew string // errors.New
 }
 
 type methodDesc struct {
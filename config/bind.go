@@ -0,0 +1,80 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Bind calls fn with the value of the key and again on its changes, so that
+// the components can react to a watched key, e.g. the timeout of a server:
+//
+//	config.Bind(c, "server.http.timeout", srv.SetTimeout)
+//
+// The values are converted into T by the Value methods, the durations can be
+// strings, e.g. "5s", and the other types are scanned. It replaces the observer
+// of the key, if any, see Config.Watch.
+func Bind[T any](c Config, key string, fn func(T)) error {
+	v, err := valueOf[T](c.Value(key))
+	if err != nil {
+		return err
+	}
+	fn(v)
+	return c.Watch(key, func(_ string, value Value) {
+		if v, err := valueOf[T](value); err == nil {
+			fn(v)
+		}
+	})
+}
+
+// Var is the value of a watched key, updated on its changes, see Bind.
+type Var[T any] struct {
+	v atomic.Value
+}
+
+// NewVar returns the Var of the key.
+func NewVar[T any](c Config, key string) (*Var[T], error) {
+	v := &Var[T]{}
+	if err := Bind(c, key, v.store); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (v *Var[T]) store(value T) {
+	v.v.Store(&value)
+}
+
+// Load returns the current value.
+func (v *Var[T]) Load() T {
+	return *v.v.Load().(*T)
+}
+
+func valueOf[T any](value Value) (T, error) {
+	var (
+		v   T
+		err error
+	)
+	switch p := any(&v).(type) {
+	case *bool:
+		*p, err = value.Bool()
+	case *string:
+		*p, err = value.String()
+	case *int:
+		var i int64
+		i, err = value.Int()
+		*p = int(i)
+	case *int64:
+		*p, err = value.Int()
+	case *float64:
+		*p, err = value.Float()
+	case *time.Duration:
+		if s, ok := value.Load().(string); ok {
+			*p, err = time.ParseDuration(s)
+		} else {
+			*p, err = value.Duration()
+		}
+	default:
+		err = value.Scan(p)
+	}
+	return v, err
+}
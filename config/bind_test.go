@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+const _testBindJSON = `
+{
+    "server":{
+        "timeout":"5s",
+        "port":8000,
+        "limit":0.5
+    }
+}`
+
+func TestBind(t *testing.T) {
+	c := New(WithSource(newTestJSONSource(_testBindJSON)))
+	defer c.Close()
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	var timeout time.Duration
+	if err := Bind(c, "server.timeout", func(v time.Duration) { timeout = v }); err != nil {
+		t.Fatal(err)
+	}
+	if timeout != 5*time.Second {
+		t.Errorf("expected 5s got %v", timeout)
+	}
+	if err := Bind(c, "server.port", func(int) {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Bind(c, "server.none", func(string) {}); err == nil {
+		t.Error("expected an error of the missing key")
+	}
+
+	port, err := NewVar[int](c, "server.port")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port.Load() != 8000 {
+		t.Errorf("expected 8000 got %d", port.Load())
+	}
+	limit, err := NewVar[float64](c, "server.limit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit.Load() != 0.5 {
+		t.Errorf("expected 0.5 got %v", limit.Load())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/imdario/mergo"
@@ -39,13 +40,18 @@ type config struct {
 	cached    sync.Map
 	observers sync.Map
 	watchers  []Watcher
+	changes   atomic.Value
+	// lock guards the merges against snapshots, so a snapshot never observes
+	// a merged but unresolved watch update.
+	lock   sync.RWMutex
+	closed chan struct{}
+	once   sync.Once
 }
 
 // New a config with options.
 func New(opts ...Option) Config {
 	o := options{
-		decoder:  defaultDecoder,
-		resolver: defaultResolver,
+		decoder: defaultDecoder,
 		merge: func(dst, src interface{}) error {
 			return mergo.Map(dst, src, mergo.WithOverride)
 		},
@@ -53,9 +59,16 @@ func New(opts ...Option) Config {
 	for _, opt := range opts {
 		opt(&o)
 	}
+	if o.resolver == nil {
+		o.resolver = defaultResolver
+		if o.envFallback {
+			o.resolver = envResolver
+		}
+	}
 	return &config{
 		opts:   o,
 		reader: newReader(o),
+		closed: make(chan struct{}),
 	}
 }
 
@@ -71,49 +84,87 @@ func (c *config) watch(w Watcher) {
 			log.Errorf("failed to watch next config: %v", err)
 			continue
 		}
-		if err := c.reader.Merge(kvs...); err != nil {
-			log.Errorf("failed to merge next config: %v", err)
-			continue
-		}
-		if err := c.reader.Resolve(); err != nil {
-			log.Errorf("failed to resolve next config: %v", err)
+		if err := c.apply(kvs); err != nil {
 			continue
 		}
-		c.cached.Range(func(key, value interface{}) bool {
-			k := key.(string)
-			v := value.(Value)
-			if n, ok := c.reader.Value(k); ok && reflect.TypeOf(n.Load()) == reflect.TypeOf(v.Load()) && !reflect.DeepEqual(n.Load(), v.Load()) {
-				v.Store(n.Load())
-				if o, ok := c.observers.Load(k); ok {
-					o.(Observer)(k, v)
-				}
+		c.notify()
+	}
+}
+
+// notify updates the cached values and calls their observers.
+func (c *config) notify() {
+	c.cached.Range(func(key, value interface{}) bool {
+		k := key.(string)
+		v := value.(Value)
+		if n, ok := c.reader.Value(k); ok && reflect.TypeOf(n.Load()) == reflect.TypeOf(v.Load()) && !reflect.DeepEqual(n.Load(), v.Load()) {
+			v.Store(n.Load())
+			if o, ok := c.observers.Load(k); ok {
+				o.(Observer)(k, v)
 			}
-			return true
-		})
+		}
+		return true
+	})
+}
+
+// apply merges and resolves the watched key values in one step.
+func (c *config) apply(kvs []*KeyValue) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	prev, _ := c.snapshot()
+	if err := c.reader.Merge(kvs...); err != nil {
+		log.Errorf("failed to merge next config: %v", err)
+		return err
 	}
+	if err := c.reader.Resolve(); err != nil {
+		log.Errorf("failed to resolve next config: %v", err)
+		return err
+	}
+	if next, err := c.snapshot(); err == nil {
+		c.changes.Store(c.diff(prev, next))
+	}
+	return nil
 }
 
 func (c *config) Load() error {
 	for _, src := range c.opts.sources {
-		kvs, err := src.Load()
+		timeout, policy := c.sourceOptions(src)
+		kvs, err := loadSource(src, timeout)
 		if err != nil {
+			switch policy {
+			case LoadContinue:
+				log.Warnf("failed to load config source, skipped: %v", err)
+				continue
+			case LoadRetryAsync:
+				log.Warnf("failed to load config source, retry in background: %v", err)
+				go c.retry(src, timeout)
+				continue
+			}
 			return err
 		}
 		for _, v := range kvs {
 			log.Debugf("config loaded: %s format: %s", v.Key, v.Format)
 		}
-		if err = c.reader.Merge(kvs...); err != nil {
-			log.Errorf("failed to merge config source: %v", err)
-			return err
-		}
 		w, err := src.Watch()
 		if err != nil {
 			log.Errorf("failed to watch config source: %v", err)
 			return err
 		}
-		c.watchers = append(c.watchers, w)
+		// the sources are loaded and watched unlocked, so that a slow source
+		// does not block the snapshots, only the merge is locked.
+		c.lock.Lock()
+		if err = c.reader.Merge(kvs...); err == nil {
+			c.watchers = append(c.watchers, w)
+		}
+		c.lock.Unlock()
+		if err != nil {
+			_ = w.Stop()
+			log.Errorf("failed to merge config source: %v", err)
+			return err
+		}
 		go c.watch(w)
 	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	if err := c.reader.Resolve(); err != nil {
 		log.Errorf("failed to resolve config source: %v", err)
 		return err
@@ -149,6 +200,9 @@ func (c *config) Watch(key string, o Observer) error {
 }
 
 func (c *config) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	for _, w := range c.watchers {
 		if err := w.Stop(); err != nil {
 			return err
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maskedValue = "***"
+
+var _ Dumper = (*config)(nil)
+
+// Dumper dumps the effective config of a running instance.
+type Dumper interface {
+	// Dump returns the effective merged config with secret keys masked.
+	Dump() (map[string]interface{}, error)
+	// Changes returns the changes applied by the last watch update.
+	Changes() []Change
+}
+
+// ChangeType is the type of config change.
+type ChangeType string
+
+const (
+	// ChangeAdded is a key added by the update.
+	ChangeAdded ChangeType = "added"
+	// ChangeRemoved is a key removed by the update.
+	ChangeRemoved ChangeType = "removed"
+	// ChangeModified is a key whose value was modified by the update.
+	ChangeModified ChangeType = "modified"
+)
+
+// Change is a single key change applied by a watch update.
+type Change struct {
+	Key  string      `json:"key"`
+	Type ChangeType  `json:"type"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// Dump returns the effective merged config with secret keys masked.
+func (c *config) Dump() (map[string]interface{}, error) {
+	c.lock.RLock()
+	values, err := c.snapshot()
+	c.lock.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	c.mask("", values)
+	return values, nil
+}
+
+// Changes returns the changes applied by the last watch update.
+func (c *config) Changes() []Change {
+	changes, _ := c.changes.Load().([]Change)
+	return changes
+}
+
+func (c *config) snapshot() (map[string]interface{}, error) {
+	data, err := c.reader.Source()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if err = json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (c *config) mask(prefix string, values map[string]interface{}) {
+	for k, v := range values {
+		values[k] = c.maskValue(joinKey(prefix, k), v)
+	}
+}
+
+// maskValue masks the value of the key, or the secrets nested in it, the
+// elements of an array are keyed by their indexes.
+func (c *config) maskValue(key string, v interface{}) interface{} {
+	if c.isSecret(key) {
+		return maskedValue
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c.mask(key, v)
+	case []interface{}:
+		for i, e := range v {
+			v[i] = c.maskValue(joinKey(key, strconv.Itoa(i)), e)
+		}
+	}
+	return v
+}
+
+// isSecret reports whether the key matches one of the registered secret keys,
+// a '*' segment in a secret key matches any single key segment.
+func (c *config) isSecret(key string) bool {
+	segments := strings.Split(key, ".")
+	for _, secret := range c.opts.secretKeys {
+		patterns := strings.Split(secret, ".")
+		if len(patterns) != len(segments) {
+			continue
+		}
+		matched := true
+		for i, p := range patterns {
+			if p != "*" && p != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecretPath reports whether the key or any of its parent keys is secret.
+func (c *config) isSecretPath(key string) bool {
+	segments := strings.Split(key, ".")
+	for i := range segments {
+		if c.isSecret(strings.Join(segments[:i+1], ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *config) diff(prev, next map[string]interface{}) []Change {
+	before, after := make(map[string]interface{}), make(map[string]interface{})
+	flatten("", prev, before)
+	flatten("", next, after)
+	changes := make([]Change, 0)
+	for k, v := range before {
+		n, ok := after[k]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Key: k, Type: ChangeRemoved, Old: v})
+		case !reflect.DeepEqual(v, n):
+			changes = append(changes, Change{Key: k, Type: ChangeModified, Old: v, New: n})
+		}
+	}
+	for k, v := range after {
+		if _, ok := before[k]; !ok {
+			changes = append(changes, Change{Key: k, Type: ChangeAdded, New: v})
+		}
+	}
+	for i, change := range changes {
+		if c.isSecretPath(change.Key) {
+			if change.Old != nil {
+				changes[i].Old = maskedValue
+			}
+			if change.New != nil {
+				changes[i].New = maskedValue
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+func flatten(prefix string, src map[string]interface{}, dst map[string]interface{}) {
+	for k, v := range src {
+		flattenValue(joinKey(prefix, k), v, dst)
+	}
+}
+
+func flattenValue(key string, v interface{}, dst map[string]interface{}) {
+	switch sub := v.(type) {
+	case map[string]interface{}:
+		if len(sub) > 0 {
+			flatten(key, sub, dst)
+			return
+		}
+	case []interface{}:
+		if len(sub) > 0 {
+			for i, e := range sub {
+				flattenValue(joinKey(key, strconv.Itoa(i)), e, dst)
+			}
+			return
+		}
+	}
+	dst[key] = v
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// NewHandler new a handler that renders the effective config
+// and the changes applied by the last watch update as JSON.
+func NewHandler(c Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		d, ok := c.(Dumper)
+		if !ok {
+			http.Error(w, "config dump is not supported", http.StatusNotImplemented)
+			return
+		}
+		values, err := d.Dump()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(struct {
+			Config  map[string]interface{} `json:"config"`
+			Changes []Change               `json:"changes"`
+		}{
+			Config:  values,
+			Changes: d.Changes(),
+		})
+	})
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testDumpSource struct {
+	data string
+	next chan string
+}
+
+func (s *testDumpSource) Load() ([]*KeyValue, error) {
+	return []*KeyValue{{Key: "json", Value: []byte(s.data), Format: "json"}}, nil
+}
+
+func (s *testDumpSource) Watch() (Watcher, error) {
+	return &testDumpWatcher{next: s.next, exit: make(chan struct{})}, nil
+}
+
+type testDumpWatcher struct {
+	next chan string
+	exit chan struct{}
+}
+
+func (w *testDumpWatcher) Next() ([]*KeyValue, error) {
+	select {
+	case data := <-w.next:
+		return []*KeyValue{{Key: "json", Value: []byte(data), Format: "json"}}, nil
+	case <-w.exit:
+		return nil, nil
+	}
+}
+
+func (w *testDumpWatcher) Stop() error {
+	close(w.exit)
+	return nil
+}
+
+func TestDump(t *testing.T) {
+	src := &testDumpSource{
+		data: `{"server":{"addr":"0.0.0.0"},"data":{"mysql":{"password":"secret"},"redis":{"password":"secret"}}}`,
+		next: make(chan string),
+	}
+	c := New(WithSource(src), WithSecretKeys("data.*.password"))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	d, ok := c.(Dumper)
+	if !ok {
+		t.Fatal("config is not a Dumper")
+	}
+	values, err := d.Dump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := values["data"].(map[string]interface{})
+	if v := data["mysql"].(map[string]interface{})["password"]; v != maskedValue {
+		t.Errorf("expected %s got %v", maskedValue, v)
+	}
+	if v := values["server"].(map[string]interface{})["addr"]; v != "0.0.0.0" {
+		t.Errorf("expected 0.0.0.0 got %v", v)
+	}
+	if v, _ := c.Value("data.mysql.password").String(); v != "secret" {
+		t.Errorf("expected secret got %v", v)
+	}
+
+	src.next <- `{"server":{"addr":"127.0.0.1","port":8000},"data":{"mysql":{"password":"changed"}}}`
+	time.Sleep(100 * time.Millisecond)
+	changes := d.Changes()
+	want := []Change{
+		{Key: "data.mysql.password", Type: ChangeModified, Old: maskedValue, New: maskedValue},
+		{Key: "server.addr", Type: ChangeModified, Old: "0.0.0.0", New: "127.0.0.1"},
+		{Key: "server.port", Type: ChangeAdded, New: float64(8000)},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %v got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("expected %v got %v", want[i], changes[i])
+		}
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	src := &testDumpSource{
+		data: `{"server":{"addr":"0.0.0.0","token":"secret"}}`,
+		next: make(chan string),
+	}
+	c := New(WithSource(src), WithSecretKeys("server.token"))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	rec := httptest.NewRecorder()
+	NewHandler(c).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", rec.Code)
+	}
+	var res struct {
+		Config  map[string]map[string]string `json:"config"`
+		Changes []Change                     `json:"changes"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Config["server"]["token"] != maskedValue {
+		t.Errorf("expected %s got %s", maskedValue, res.Config["server"]["token"])
+	}
+	if len(res.Changes) != 0 {
+		t.Errorf("expected no changes got %v", res.Changes)
+	}
+}
+
+func TestDumpSecretParent(t *testing.T) {
+	src := &testDumpSource{
+		data: `{"data":{"mysql":{"dsn":"root:secret@/db"}},"users":[{"name":"a","password":"secret"}]}`,
+		next: make(chan string),
+	}
+	c := New(WithSource(src), WithSecretKeys("data.mysql", "users.*.password"))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	d := c.(Dumper)
+	values, err := d.Dump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := values["data"].(map[string]interface{})["mysql"]; v != maskedValue {
+		t.Errorf("expected %s got %v", maskedValue, v)
+	}
+	user := values["users"].([]interface{})[0].(map[string]interface{})
+	if user["password"] != maskedValue || user["name"] != "a" {
+		t.Errorf("expected the password of the user masked, got %v", user)
+	}
+
+	src.next <- `{"data":{"mysql":{"dsn":"root:changed@/db"}},"users":[{"name":"a","password":"changed"}]}`
+	time.Sleep(100 * time.Millisecond)
+	changes := d.Changes()
+	want := []Change{
+		{Key: "data.mysql.dsn", Type: ChangeModified, Old: maskedValue, New: maskedValue},
+		{Key: "users.0.password", Type: ChangeModified, Old: maskedValue, New: maskedValue},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %v got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("expected %v got %v", want[i], changes[i])
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+var (
+	_ config.Source  = (*dir)(nil)
+	_ config.Watcher = (*dirWatcher)(nil)
+)
+
+// DirOption is directory source option.
+type DirOption func(*dir)
+
+// WithDebounce with the quiet period of the changes before the reload, default is 100ms,
+// so that the swap of a Kubernetes ConfigMap is reloaded once.
+func WithDebounce(d time.Duration) DirOption {
+	return func(s *dir) {
+		s.debounce = d
+	}
+}
+
+type dir struct {
+	path     string
+	debounce time.Duration
+}
+
+// NewDirSource new a source of the tree of the config files of the directory,
+// e.g. a mounted Kubernetes ConfigMap. The files of the registered formats,
+// e.g. YAML, JSON or TOML of contrib/encoding/toml, are loaded by the relative
+// paths in order, the hidden ones are ignored and the symlinks are followed.
+// Any change reloads the whole tree, so that a symlink swap is seen atomically.
+func NewDirSource(path string, opts ...DirOption) config.Source {
+	s := &dir{path: path, debounce: 100 * time.Millisecond}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// dirFormat returns the codec format of the file name, empty if not registered.
+func dirFormat(name string) string {
+	f := format(name)
+	if f == "yml" {
+		f = "yaml"
+	}
+	if encoding.GetCodec(f) == nil {
+		return ""
+	}
+	return f
+}
+
+// walk calls fn with the relative paths of the files and the directories of
+// the tree, following the symlinks and skipping the hidden entries.
+func (s *dir) walk(fn func(rel string, isDir bool) error) error {
+	var visit func(rel string) error
+	visit = func(rel string) error {
+		entries, err := os.ReadDir(filepath.Join(s.path, rel))
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			name := filepath.Join(rel, e.Name())
+			fi, err := os.Stat(filepath.Join(s.path, name))
+			if err != nil {
+				// a dangling symlink during a swap.
+				continue
+			}
+			if fi.IsDir() {
+				if err := fn(name, true); err != nil {
+					return err
+				}
+				if err := visit(name); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := fn(name, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return visit("")
+}
+
+func (s *dir) Load() (kvs []*config.KeyValue, err error) {
+	err = s.walk(func(rel string, isDir bool) error {
+		f := dirFormat(rel)
+		if isDir || f == "" {
+			return nil
+		}
+		data, err := os.ReadFile(filepath.Join(s.path, rel))
+		if err != nil {
+			return err
+		}
+		kvs = append(kvs, &config.KeyValue{Key: filepath.ToSlash(rel), Format: f, Value: data})
+		return nil
+	})
+	return
+}
+
+func (s *dir) Watch() (config.Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &dirWatcher{s: s, fw: fw}
+	if err := w.add(); err != nil {
+		_ = fw.Close()
+		return nil, err
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	return w, nil
+}
+
+type dirWatcher struct {
+	s  *dir
+	fw *fsnotify.Watcher
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// add watches the directories of the tree, the new ones are added on reloads.
+func (w *dirWatcher) add() error {
+	if err := w.fw.Add(w.s.path); err != nil {
+		return err
+	}
+	return w.s.walk(func(rel string, isDir bool) error {
+		if !isDir {
+			return nil
+		}
+		return w.fw.Add(filepath.Join(w.s.path, rel))
+	})
+}
+
+func (w *dirWatcher) Next() ([]*config.KeyValue, error) {
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case <-w.fw.Events:
+	case err := <-w.fw.Errors:
+		return nil, err
+	}
+	// waits for the quiet period, e.g. of the several events of a symlink swap.
+	timer := time.NewTimer(w.s.debounce)
+	defer timer.Stop()
+	for quiet := false; !quiet; {
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case <-w.fw.Events:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(w.s.debounce)
+		case <-timer.C:
+			quiet = true
+		}
+	}
+	if err := w.add(); err != nil {
+		return nil, err
+	}
+	return w.s.Load()
+}
+
+func (w *dirWatcher) Stop() error {
+	w.cancel()
+	return w.fw.Close()
+}
@@ -0,0 +1,94 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+// writeConfigMap writes the files as a Kubernetes ConfigMap volume, of which
+// the files are the symlinks into the ..data symlink of a timestamped directory.
+func writeConfigMap(t *testing.T, dir, version string, files map[string]string) {
+	ts := filepath.Join(dir, "..ts_"+version)
+	if err := os.MkdirAll(ts, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(ts, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			if err := os.Symlink(filepath.Join("..data", name), link); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(filepath.Base(ts), tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigMap(t, dir, "1", map[string]string{
+		"server.yml": "server:\n  timeout: 1s\n",
+		"log.json":   `{"log":{"level":"info"}}`,
+		"README.md":  "not a config",
+	})
+	if err := os.MkdirAll(filepath.Join(dir, "data"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "data", "db.yaml"), []byte("db:\n  dsn: mysql\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := NewDirSource(dir, WithDebounce(50*time.Millisecond))
+	kvs, err := src.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]string{}
+	for _, kv := range kvs {
+		keys[kv.Key] = kv.Format
+	}
+	if len(keys) != 3 || keys["server.yml"] != "yaml" || keys["log.json"] != "json" || keys["data/db.yaml"] != "yaml" {
+		t.Fatalf("unexpected key values %v", keys)
+	}
+
+	c := config.New(config.WithSource(src))
+	defer c.Close()
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	timeout, err := config.NewVar[time.Duration](c, "server.timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	level, err := config.NewVar[string](c, "log.level")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timeout.Load() != time.Second || level.Load() != "info" {
+		t.Fatalf("unexpected values %v %v", timeout.Load(), level.Load())
+	}
+
+	writeConfigMap(t, dir, "2", map[string]string{
+		"server.yml": "server:\n  timeout: 3s\n",
+		"log.json":   `{"log":{"level":"debug"}}`,
+	})
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) && (timeout.Load() != 3*time.Second || level.Load() != "debug") {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if timeout.Load() != 3*time.Second || level.Load() != "debug" {
+		t.Errorf("expected the swapped values, got %v %v", timeout.Load(), level.Load())
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// ErrLoadTimeout is returned when a config source does not load in time.
+var ErrLoadTimeout = errors.New("config source load timeout")
+
+const (
+	retryMinDelay = time.Second
+	retryMaxDelay = 30 * time.Second
+)
+
+// LoadPolicy is the policy applied when a config source fails to load.
+type LoadPolicy int
+
+const (
+	// LoadFailFast aborts Load with the source error, it is the default policy.
+	LoadFailFast LoadPolicy = iota
+	// LoadContinue skips the failed source and continues with the config
+	// loaded from the other sources.
+	LoadContinue
+	// LoadRetryAsync skips the failed source and keeps retrying it in the
+	// background with backoff, the source is merged and watched once loaded.
+	LoadRetryAsync
+)
+
+// SourceOption is config source load option.
+type SourceOption func(*sourceOptions)
+
+type sourceOptions struct {
+	timeout time.Duration
+	policy  *LoadPolicy
+}
+
+// SourceTimeout with the source load timeout, zero means no timeout.
+func SourceTimeout(d time.Duration) SourceOption {
+	return func(o *sourceOptions) {
+		o.timeout = d
+	}
+}
+
+// SourcePolicy with the source load failure policy.
+func SourcePolicy(p LoadPolicy) SourceOption {
+	return func(o *sourceOptions) {
+		o.policy = &p
+	}
+}
+
+// Wrap wraps a source with load options which override
+// the config level WithLoadTimeout and WithLoadPolicy.
+func Wrap(src Source, opts ...SourceOption) Source {
+	ws := &wrappedSource{Source: src}
+	for _, o := range opts {
+		o(&ws.opts)
+	}
+	return ws
+}
+
+type wrappedSource struct {
+	Source
+	opts sourceOptions
+}
+
+func (c *config) sourceOptions(src Source) (time.Duration, LoadPolicy) {
+	timeout, policy := c.opts.loadTimeout, c.opts.loadPolicy
+	if ws, ok := src.(*wrappedSource); ok {
+		if ws.opts.timeout > 0 {
+			timeout = ws.opts.timeout
+		}
+		if ws.opts.policy != nil {
+			policy = *ws.opts.policy
+		}
+	}
+	return timeout, policy
+}
+
+// loadSource loads the source within the timeout.
+func loadSource(src Source, timeout time.Duration) ([]*KeyValue, error) {
+	if timeout <= 0 {
+		return src.Load()
+	}
+	type result struct {
+		kvs []*KeyValue
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		kvs, err := src.Load()
+		ch <- result{kvs: kvs, err: err}
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-ch:
+		return r.kvs, r.err
+	case <-timer.C:
+		return nil, ErrLoadTimeout
+	}
+}
+
+// retry keeps loading the source in the background until it succeeds or the config is closed.
+func (c *config) retry(src Source, timeout time.Duration) {
+	delay := retryMinDelay
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-time.After(delay):
+		}
+		kvs, err := loadSource(src, timeout)
+		var w Watcher
+		if err == nil {
+			w, err = src.Watch()
+		}
+		if err == nil {
+			// the config of the source may be fixed by the next load.
+			if err = c.apply(kvs); err != nil {
+				_ = w.Stop()
+			}
+		}
+		if err == nil {
+			c.lock.Lock()
+			select {
+			case <-c.closed:
+				c.lock.Unlock()
+				_ = w.Stop()
+				return
+			default:
+			}
+			c.watchers = append(c.watchers, w)
+			c.lock.Unlock()
+			c.notify()
+			go c.watch(w)
+			log.Infof("config source loaded after retry")
+			return
+		}
+		log.Warnf("failed to retry config source: %v, next retry in %v", err, delay)
+		if delay *= 2; delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testLoadSource struct {
+	data  string
+	delay time.Duration
+	fails int32
+	// invalid is the number of loads of an invalid config after the failures.
+	invalid int32
+	calls   int32
+}
+
+func (s *testLoadSource) Load() ([]*KeyValue, error) {
+	calls := atomic.AddInt32(&s.calls, 1)
+	if calls <= s.fails {
+		return nil, errors.New("source unavailable")
+	}
+	if calls <= s.fails+s.invalid {
+		return []*KeyValue{{Key: "test", Value: []byte("{"), Format: "json"}}, nil
+	}
+	time.Sleep(s.delay)
+	return []*KeyValue{{Key: "test", Value: []byte(s.data), Format: "json"}}, nil
+}
+
+func (s *testLoadSource) Watch() (Watcher, error) {
+	return &testDumpWatcher{next: make(chan string), exit: make(chan struct{})}, nil
+}
+
+func TestLoadTimeout(t *testing.T) {
+	c := New(
+		WithSource(&testLoadSource{data: `{"a":1}`, delay: time.Second}),
+		WithLoadTimeout(10*time.Millisecond),
+	)
+	if err := c.Load(); !errors.Is(err, ErrLoadTimeout) {
+		t.Fatalf("expected %v, got %v", ErrLoadTimeout, err)
+	}
+}
+
+func TestLoadContinue(t *testing.T) {
+	c := New(
+		WithSource(
+			&testLoadSource{data: `{"a":1}`},
+			Wrap(&testLoadSource{data: `{"b":2}`, delay: time.Second}, SourceTimeout(10*time.Millisecond)),
+		),
+		WithLoadPolicy(LoadContinue),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if v, err := c.Value("a").Int(); err != nil || v != 1 {
+		t.Fatalf("expected 1, got %v %v", v, err)
+	}
+	if _, err := c.Value("b").Int(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected %v, got %v", ErrNotFound, err)
+	}
+}
+
+func TestLoadRetryAsync(t *testing.T) {
+	src := &testLoadSource{data: `{"a":1}`, fails: 1}
+	c := New(WithSource(Wrap(src, SourcePolicy(LoadRetryAsync))))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	v := c.Value("a")
+	if _, err := v.Int(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected %v, got %v", ErrNotFound, err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if n, err := c.Value("a").Int(); err == nil && n == 1 {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("source was not loaded by retry")
+}
+
+func TestLoadRetryAsyncApply(t *testing.T) {
+	src := &testLoadSource{data: `{"a":1}`, fails: 1, invalid: 1}
+	c := New(WithSource(Wrap(src, SourcePolicy(LoadRetryAsync))))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if n, err := c.Value("a").Int(); err == nil && n == 1 {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("source was not loaded by retry after the invalid config")
+}
+
+func TestLoadUnlocked(t *testing.T) {
+	c := New(WithSource(
+		&testLoadSource{data: `{"a":1}`},
+		&testLoadSource{data: `{"b":2}`, delay: 500 * time.Millisecond},
+	))
+	done := make(chan error, 1)
+	go func() { done <- c.Load() }()
+	defer c.Close()
+	time.Sleep(50 * time.Millisecond)
+	start := time.Now()
+	if _, err := c.(Dumper).Dump(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 200*time.Millisecond {
+		t.Errorf("expected the dump not blocked by the slow source, took %v", d)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
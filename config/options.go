@@ -2,8 +2,10 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/encoding"
 )
@@ -25,6 +27,11 @@ type options struct {
 	decoder  Decoder
 	resolver Resolver
 	merge    Merge
+
+	secretKeys  []string
+	loadTimeout time.Duration
+	loadPolicy  LoadPolicy
+	envFallback bool
 }
 
 // WithSource with config source.
@@ -45,6 +52,15 @@ func WithDecoder(d Decoder) Option {
 	}
 }
 
+// WithEnvFallback with the environment variables resolving the placeholders of
+// the default resolver whose keys are not in the config, e.g. ${DB_PASSWORD},
+// default is no fallback, so that only the config sources are resolved.
+func WithEnvFallback() Option {
+	return func(o *options) {
+		o.envFallback = true
+	}
+}
+
 // WithResolver with config resolver.
 func WithResolver(r Resolver) Option {
 	return func(o *options) {
@@ -59,6 +75,36 @@ func WithMergeFunc(m Merge) Option {
 	}
 }
 
+// WithSecretKeys with config keys masked when the config is dumped, together
+// with the keys nested in them, a '*' segment matches any single key segment
+// or array index, e.g. "data.*.password".
+func WithSecretKeys(keys ...string) Option {
+	return func(o *options) {
+		o.secretKeys = keys
+	}
+}
+
+// WithLoadTimeout with the default load timeout of every source,
+// zero means no timeout.
+func WithLoadTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.loadTimeout = d
+	}
+}
+
+// WithLoadPolicy with the default load failure policy of every source.
+func WithLoadPolicy(p LoadPolicy) Option {
+	return func(o *options) {
+		o.loadPolicy = p
+	}
+}
+
+// DefaultDecoder is the default config decoder, so that a decoder of WithDecoder
+// can wrap it, e.g. to decrypt the values.
+func DefaultDecoder(src *KeyValue, target map[string]interface{}) error {
+	return defaultDecoder(src, target)
+}
+
 // defaultDecoder decode config from source KeyValue
 // to target map[string]interface{} using src.Format codec.
 func defaultDecoder(src *KeyValue, target map[string]interface{}) error {
@@ -85,11 +131,23 @@ func defaultDecoder(src *KeyValue, target map[string]interface{}) error {
 // defaultResolver resolve placeholder in map value,
 // placeholder format in ${key:default}.
 func defaultResolver(input map[string]interface{}) error {
+	return resolvePlaceholders(input, false)
+}
+
+// envResolver is the defaultResolver whose keys not in the config are looked
+// up in the environment variables, e.g. ${DB_PASSWORD}.
+func envResolver(input map[string]interface{}) error {
+	return resolvePlaceholders(input, true)
+}
+
+func resolvePlaceholders(input map[string]interface{}, env bool) error {
 	mapper := func(name string) string {
 		args := strings.SplitN(strings.TrimSpace(name), ":", 2) //nolint:gomnd
 		if v, has := readValue(input, args[0]); has {
 			s, _ := v.String()
 			return s
+		} else if v, ok := os.LookupEnv(args[0]); env && ok {
+			return v
 		} else if len(args) > 1 { // default value
 			return args[1]
 		}
@@ -76,6 +76,13 @@ func (r *reader) Resolve() error {
 	return r.opts.resolver(r.values)
 }
 
+// root returns a deep copy of the values of the reader.
+func (r *reader) root() map[string]interface{} {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return deepCopy(r.values).(map[string]interface{})
+}
+
 func (r *reader) cloneMap() (map[string]interface{}, error) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
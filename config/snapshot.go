@@ -0,0 +1,86 @@
+package config
+
+var (
+	_ Snapshotter = (*config)(nil)
+	_ Snapshot    = (*snapshot)(nil)
+)
+
+// Snapshot is an immutable point-in-time view of a config subtree,
+// related keys read from the same snapshot are always consistent
+// even while a watch update is being applied.
+type Snapshot interface {
+	// Value returns the value of the key relative to the snapshot root,
+	// an empty key returns the root value.
+	Value(key string) Value
+	// Scan decodes the whole snapshot into v.
+	Scan(v interface{}) error
+}
+
+// Snapshotter takes consistent snapshots of a config.
+type Snapshotter interface {
+	// Snapshot returns a snapshot of the subtree at key,
+	// an empty key returns a snapshot of the whole config.
+	Snapshot(key string) (Snapshot, error)
+}
+
+type snapshot struct {
+	root interface{}
+}
+
+// Snapshot returns a snapshot of the subtree at key.
+func (c *config) Snapshot(key string) (Snapshot, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if key == "" {
+		// the root is copied like the subtrees, so that the values keep their types.
+		return &snapshot{root: c.reader.(*reader).root()}, nil
+	}
+	v, ok := c.reader.Value(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &snapshot{root: deepCopy(v.Load())}, nil
+}
+
+func (s *snapshot) Value(key string) Value {
+	if key == "" {
+		av := &atomicValue{}
+		av.Store(s.root)
+		return av
+	}
+	values, ok := s.root.(map[string]interface{})
+	if !ok {
+		return &errValue{err: ErrNotFound}
+	}
+	if v, ok := readValue(values, key); ok {
+		return v
+	}
+	return &errValue{err: ErrNotFound}
+}
+
+func (s *snapshot) Scan(v interface{}) error {
+	data, err := marshalJSON(s.root)
+	if err != nil {
+		return err
+	}
+	return unmarshalJSON(data, v)
+}
+
+func deepCopy(src interface{}) interface{} {
+	switch v := src.(type) {
+	case map[string]interface{}:
+		dst := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			dst[k] = deepCopy(val)
+		}
+		return dst
+	case []interface{}:
+		dst := make([]interface{}, len(v))
+		for i, val := range v {
+			dst[i] = deepCopy(val)
+		}
+		return dst
+	default:
+		return src
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	src := &testDumpSource{
+		data: `{"server":{"host":"127.0.0.1","port":8000,"tls":{"cert":"a.crt","key":"a.key"}},"endpoints":["a","b"]}`,
+		next: make(chan string),
+	}
+	c := New(WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	s, ok := c.(Snapshotter)
+	if !ok {
+		t.Fatal("config is not a Snapshotter")
+	}
+	snap, err := s.Snapshot("server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.next <- `{"server":{"host":"0.0.0.0","port":9000,"tls":{"cert":"b.crt","key":"b.key"}}}`
+	time.Sleep(100 * time.Millisecond)
+
+	if v, _ := c.Value("server.port").Int(); v != 9000 {
+		t.Errorf("expected 9000 got %d", v)
+	}
+	if v, _ := snap.Value("host").String(); v != "127.0.0.1" {
+		t.Errorf("expected 127.0.0.1 got %s", v)
+	}
+	if v, _ := snap.Value("port").Int(); v != 8000 {
+		t.Errorf("expected 8000 got %d", v)
+	}
+	if v, _ := snap.Value("tls.cert").String(); v != "a.crt" {
+		t.Errorf("expected a.crt got %s", v)
+	}
+	if _, err = snap.Value("notfound").String(); err != ErrNotFound {
+		t.Errorf("expected %v got %v", ErrNotFound, err)
+	}
+	var server struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+		TLS  struct {
+			Cert string `json:"cert"`
+			Key  string `json:"key"`
+		} `json:"tls"`
+	}
+	if err = snap.Scan(&server); err != nil {
+		t.Fatal(err)
+	}
+	if server.Host != "127.0.0.1" || server.Port != 8000 || server.TLS.Key != "a.key" {
+		t.Errorf("unexpected scan result: %+v", server)
+	}
+
+	root, err := s.Snapshot("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := root.Value("server.tls.cert").String(); v != "b.crt" {
+		t.Errorf("expected b.crt got %s", v)
+	}
+	if vs, _ := root.Value("endpoints").Slice(); len(vs) != 2 {
+		t.Errorf("expected 2 got %d", len(vs))
+	}
+	if _, err = s.Snapshot("notfound"); err != ErrNotFound {
+		t.Errorf("expected %v got %v", ErrNotFound, err)
+	}
+}
+
+func TestSnapshotRootTypes(t *testing.T) {
+	c := New(
+		WithSource(&testDumpSource{data: `{}`, next: make(chan string)}),
+		WithDecoder(func(_ *KeyValue, v map[string]interface{}) error {
+			v["server"] = map[string]interface{}{"port": 8000, "timeout": int64(3)}
+			return nil
+		}),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	root, err := c.(Snapshotter).Snapshot("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := c.(Snapshotter).Snapshot("server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"port", "timeout"} {
+		rv, sv := root.Value("server."+key).Load(), sub.Value(key).Load()
+		if reflect.TypeOf(rv) != reflect.TypeOf(sv) {
+			t.Errorf("%s: expected the same types of the root and the subtree, got %T and %T", key, rv, sv)
+		}
+	}
+}
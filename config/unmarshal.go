@@ -0,0 +1,301 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrRequired is the error of a missing required key.
+var ErrRequired = errors.New("required")
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// byteUnits are the units of ByteSize, the SI units are of 1000 and the IEC ones of 1024.
+var byteUnits = map[string]float64{
+	"":    1,
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// ByteSize is a size in bytes decoded from a number or a string of a unit,
+// e.g. "512KB" or "1.5GiB".
+type ByteSize int64
+
+// ParseByteSize parses the size of a unit, e.g. "64MB", "1.5GiB" or "1024".
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	unit, ok := byteUnits[strings.ToUpper(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size unit %q", s)
+	}
+	return ByteSize(n * unit), nil
+}
+
+// UnmarshalJSON decodes the size from a number or a string.
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n int64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		*b = ByteSize(n)
+		return nil
+	}
+	size, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// FieldError is the error of a key of Unmarshal.
+type FieldError struct {
+	Key string
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return e.Key + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is the errors of the keys of Unmarshal.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return "config: " + strings.Join(msgs, "; ")
+}
+
+// Unmarshal scans the config into the struct v like Config.Scan, of which the
+// fields are of the keys of their json tags, and:
+//
+//	Timeout time.Duration     `json:"timeout" default:"5s"`
+//	MaxBody config.ByteSize   `json:"max_body" default:"4MB"`
+//	DSN     string            `json:"dsn" required:"true"`
+//
+// The default tag is the value of a missing key, the required tag rejects a
+// missing or empty key, e.g. of an unset ${ENV} of WithEnvFallback, and the durations are parsed
+// from the strings. The errors of all the keys are returned at once by a
+// ValidationError, so that a service fails fast at startup with every mistake,
+// then v is validated by its Validate method, if any.
+func Unmarshal(c Config, v interface{}) error {
+	if _, ok := v.(proto.Message); ok {
+		return c.Scan(v)
+	}
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: unmarshal into a non struct pointer %T", v)
+	}
+	m := make(map[string]interface{})
+	if err := c.Scan(&m); err != nil {
+		return err
+	}
+	var errs []*FieldError
+	prepare(t.Elem(), m, "", &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	if vv, ok := v.(interface{ Validate() error }); ok {
+		return vv.Validate()
+	}
+	return nil
+}
+
+// prepare applies the defaults, checks the required keys and converts the
+// values of the fields of the struct type in the map, for json.Unmarshal.
+func prepare(t reflect.Type, m map[string]interface{}, prefix string, errs *[]*FieldError) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		ft := indirect(f.Type)
+		if name == "" {
+			if f.Anonymous && ft.Kind() == reflect.Struct {
+				prepare(ft, m, prefix, errs)
+				continue
+			}
+			name = f.Name
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		value, ok := m[name]
+		if !ok || value == nil {
+			if def, has := f.Tag.Lookup("default"); has {
+				dv, err := parseDefault(ft, def)
+				if err != nil {
+					*errs = append(*errs, &FieldError{Key: key, Err: err})
+					continue
+				}
+				value, ok = dv, true
+			}
+		}
+		required := f.Tag.Get("required") == "true"
+		if !ok || value == nil {
+			if required {
+				*errs = append(*errs, &FieldError{Key: key, Err: ErrRequired})
+			} else if isStruct(ft) {
+				// the defaults and the required keys of a missing struct.
+				sub := make(map[string]interface{})
+				prepare(ft, sub, key, errs)
+				if len(sub) > 0 {
+					m[name] = sub
+				}
+			}
+			continue
+		}
+		if required && value == "" {
+			*errs = append(*errs, &FieldError{Key: key, Err: ErrRequired})
+			continue
+		}
+		m[name] = convert(ft, value, key, errs)
+	}
+}
+
+// convert converts the value of the type, e.g. of the durations and the byte sizes of the strings.
+func convert(t reflect.Type, value interface{}, key string, errs *[]*FieldError) interface{} {
+	t = indirect(t)
+	switch {
+	case t == durationType:
+		if s, ok := value.(string); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				*errs = append(*errs, &FieldError{Key: key, Err: err})
+				return value
+			}
+			return int64(d)
+		}
+	case t == byteSizeType:
+		if s, ok := value.(string); ok {
+			b, err := ParseByteSize(s)
+			if err != nil {
+				*errs = append(*errs, &FieldError{Key: key, Err: err})
+				return value
+			}
+			return int64(b)
+		}
+	case isStruct(t):
+		if sub, ok := value.(map[string]interface{}); ok {
+			prepare(t, sub, key, errs)
+		}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if items, ok := value.([]interface{}); ok {
+			for i := range items {
+				items[i] = convert(t.Elem(), items[i], key+"["+strconv.Itoa(i)+"]", errs)
+			}
+		}
+	case t.Kind() == reflect.Map:
+		if sub, ok := value.(map[string]interface{}); ok {
+			for k := range sub {
+				sub[k] = convert(t.Elem(), sub[k], key+"."+k, errs)
+			}
+		}
+	}
+	return value
+}
+
+// parseDefault parses the default tag of the type, the values of the unknown
+// types are decoded as JSON, the slices of strings may be separated by commas.
+func parseDefault(t reflect.Type, s string) (interface{}, error) {
+	switch {
+	case t == durationType:
+		d, err := time.ParseDuration(s)
+		return int64(d), err
+	case t == byteSizeType:
+		b, err := ParseByteSize(s)
+		return int64(b), err
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return s, nil
+	case reflect.Bool:
+		return strconv.ParseBool(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(s, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(s, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(s, 64)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(s), "[") {
+			items := make([]interface{}, 0)
+			for _, item := range strings.Split(s, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+			return items, nil
+		}
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, fmt.Errorf("invalid default %q: %w", s, err)
+	}
+	return v, nil
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isStruct reports whether the fields of the struct type are decoded by Unmarshal.
+func isStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t == timeType {
+		return false
+	}
+	_, ok := reflect.New(t).Interface().(json.Unmarshaler)
+	return !ok
+}
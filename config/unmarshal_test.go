@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const _testUnmarshalJSON = `
+{
+    "server":{
+        "addr":"0.0.0.0:8000",
+        "timeout":"2s",
+        "max_body":"1.5KiB",
+        "tags":["a"],
+        "token":"${TEST_UNMARSHAL_TOKEN}"
+    },
+    "backends":[
+        {"name":"a","timeout":"1s"},
+        {"name":"b"}
+    ]
+}`
+
+type testBackend struct {
+	Name    string        `json:"name" required:"true"`
+	Timeout time.Duration `json:"timeout" default:"3s"`
+}
+
+type testUnmarshalConfig struct {
+	Server struct {
+		Addr    string        `json:"addr" required:"true"`
+		Timeout time.Duration `json:"timeout" default:"1s"`
+		Idle    time.Duration `json:"idle" default:"1m"`
+		MaxBody ByteSize      `json:"max_body"`
+		Tags    []string      `json:"tags" default:"x,y"`
+		Token   string        `json:"token" required:"true"`
+		Debug   bool          `json:"debug" default:"true"`
+	} `json:"server"`
+	Data *struct {
+		Driver string `json:"driver" default:"mysql"`
+	} `json:"data"`
+	Backends []testBackend `json:"backends"`
+}
+
+func (c *testUnmarshalConfig) Validate() error {
+	if c.Server.Timeout > c.Server.Idle {
+		return errors.New("the timeout exceeds the idle timeout")
+	}
+	return nil
+}
+
+func newTestUnmarshalConfig(t *testing.T, data string, opts ...Option) Config {
+	c := New(append([]Option{WithSource(newTestJSONSource(data))}, opts...)...)
+	t.Cleanup(func() { _ = c.Close() })
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestEnvFallback(t *testing.T) {
+	t.Setenv("TEST_UNMARSHAL_TOKEN", "secret")
+	data := `{"token":"${TEST_UNMARSHAL_TOKEN}"}`
+	if v, _ := newTestUnmarshalConfig(t, data).Value("token").String(); v != "" {
+		t.Errorf("expected no environment variables by default, got %q", v)
+	}
+	if v, _ := newTestUnmarshalConfig(t, data, WithEnvFallback()).Value("token").String(); v != "secret" {
+		t.Errorf("expected the environment variable, got %q", v)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Setenv("TEST_UNMARSHAL_TOKEN", "secret")
+	var conf testUnmarshalConfig
+	if err := Unmarshal(newTestUnmarshalConfig(t, _testUnmarshalJSON, WithEnvFallback()), &conf); err != nil {
+		t.Fatal(err)
+	}
+	s := conf.Server
+	if s.Addr != "0.0.0.0:8000" || s.Timeout != 2*time.Second || s.Idle != time.Minute || s.MaxBody != 1536 || !s.Debug {
+		t.Errorf("unexpected server %+v", s)
+	}
+	if !reflect.DeepEqual(s.Tags, []string{"a"}) || s.Token != "secret" {
+		t.Errorf("unexpected tags %v or token %s", s.Tags, s.Token)
+	}
+	if conf.Data == nil || conf.Data.Driver != "mysql" {
+		t.Errorf("expected the default of the missing data, got %+v", conf.Data)
+	}
+	want := []testBackend{{Name: "a", Timeout: time.Second}, {Name: "b", Timeout: 3 * time.Second}}
+	if !reflect.DeepEqual(conf.Backends, want) {
+		t.Errorf("expected %+v got %+v", want, conf.Backends)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	c := newTestUnmarshalConfig(t, `{"server":{"timeout":"soon","max_body":"1XB"},"backends":[{}]}`)
+	var conf testUnmarshalConfig
+	err := Unmarshal(c, &conf)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	var keys []string
+	for _, fe := range verr.Errors {
+		keys = append(keys, fe.Key)
+	}
+	want := []string{"server.addr", "server.timeout", "server.max_body", "server.token", "backends[0].name"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected the errors of %v got %v", want, keys)
+	}
+	if !errors.Is(verr.Errors[0], ErrRequired) || !strings.Contains(err.Error(), "server.addr: required") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	c = newTestUnmarshalConfig(t, `{"server":{"addr":":80","token":"t","timeout":"2m"}}`)
+	if err = Unmarshal(c, &conf); err == nil || err.Error() != "the timeout exceeds the idle timeout" {
+		t.Errorf("expected the error of Validate, got %v", err)
+	}
+	if err = Unmarshal(c, conf); err == nil {
+		t.Error("expected an error of a non pointer")
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteSize
+		ok   bool
+	}{
+		{"1024", 1024, true},
+		{"64MB", 64e6, true},
+		{"1.5 GiB", 1.5 * (1 << 30), true},
+		{"2kib", 2048, true},
+		{"MB", 0, false},
+		{"1XB", 0, false},
+	}
+	for _, test := range tests {
+		got, err := ParseByteSize(test.in)
+		if (err == nil) != test.ok || got != test.want {
+			t.Errorf("%s: expected %d %v got %d %v", test.in, test.want, test.ok, got, err)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+module github.com/go-kratos/kratos/contrib/config/sops/v2
+
+go 1.19
+
+require (
+	filippo.io/age v1.1.1
+	github.com/go-kratos/kratos/v2 v2.7.2
+)
+
+require (
+	github.com/imdario/mergo v0.3.16 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	golang.org/x/crypto v0.4.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
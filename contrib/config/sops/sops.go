@@ -0,0 +1,160 @@
+package sops
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+// metadataKey is the key of the metadata of the files encrypted by SOPS.
+const metadataKey = "sops"
+
+var encrypted = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.*),tag:(.*),type:(.*)\]$`)
+
+// Option is sops decoder option.
+type Option func(o *options)
+
+type options struct {
+	decoder config.Decoder
+}
+
+// WithDecoder with the decoder of the key values before the decryption,
+// default is config.DefaultDecoder.
+func WithDecoder(d config.Decoder) Option {
+	return func(o *options) {
+		o.decoder = d
+	}
+}
+
+// NewDecoder new a config decoder decrypting the values of the files
+// encrypted by SOPS with the age identities, e.g. of SOPS_AGE_KEY_FILE parsed
+// by age.ParseIdentities. The other files are decoded as is:
+//
+//	config.New(config.WithSource(source), config.WithDecoder(sops.NewDecoder(identities)))
+//
+// The MAC of the files is not verified, every value is authenticated by its
+// path of the document on decryption.
+func NewDecoder(identities []age.Identity, opts ...Option) config.Decoder {
+	o := &options{decoder: config.DefaultDecoder}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(kv *config.KeyValue, target map[string]interface{}) error {
+		if err := o.decoder(kv, target); err != nil {
+			return err
+		}
+		metadata, ok := target[metadataKey].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		key, err := dataKey(metadata, identities)
+		if err != nil {
+			return fmt.Errorf("sops: failed to decrypt the data key of %s: %w", kv.Key, err)
+		}
+		delete(target, metadataKey)
+		for k, v := range target {
+			if target[k], err = decrypt(key, v, []string{k}); err != nil {
+				return fmt.Errorf("sops: failed to decrypt %s of %s: %w", k, kv.Key, err)
+			}
+		}
+		return nil
+	}
+}
+
+// dataKey decrypts the data key of the age recipients of the metadata.
+func dataKey(metadata map[string]interface{}, identities []age.Identity) ([]byte, error) {
+	recipients, _ := metadata["age"].([]interface{})
+	if len(recipients) == 0 {
+		return nil, errors.New("no age recipients")
+	}
+	var err error
+	for _, r := range recipients {
+		m, _ := r.(map[string]interface{})
+		enc, _ := m["enc"].(string)
+		var reader io.Reader
+		if reader, err = age.Decrypt(armor.NewReader(strings.NewReader(enc)), identities...); err != nil {
+			continue
+		}
+		return io.ReadAll(reader)
+	}
+	return nil, err
+}
+
+// decrypt decrypts the encrypted values of the tree of the path, the items of
+// a list share the path of the list as SOPS does.
+func decrypt(key []byte, value interface{}, path []string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			var err error
+			if v[k], err = decrypt(key, item, append(path[:len(path):len(path)], k)); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			var err error
+			if v[i], err = decrypt(key, item, path); err != nil {
+				return nil, err
+			}
+		}
+	case string:
+		return decryptValue(key, v, strings.Join(path, ":")+":")
+	}
+	return value, nil
+}
+
+// decryptValue decrypts the value of ENC[AES256_GCM,...] into its type, the
+// other values are returned as is.
+func decryptValue(key []byte, value, additionalData string) (interface{}, error) {
+	m := encrypted.FindStringSubmatch(value)
+	if m == nil {
+		return value, nil
+	}
+	var parts [3][]byte
+	for i := range parts {
+		b, err := base64.StdEncoding.DecodeString(m[i+1])
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = b
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, err
+	}
+	switch m[4] {
+	case "str":
+		return string(plain), nil
+	case "int":
+		return strconv.Atoi(string(plain))
+	case "float":
+		return strconv.ParseFloat(string(plain), 64)
+	case "bool":
+		return strconv.ParseBool(string(plain))
+	case "bytes":
+		return plain, nil
+	default:
+		return nil, fmt.Errorf("unknown type %s", m[4])
+	}
+}
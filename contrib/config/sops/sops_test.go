@@ -0,0 +1,127 @@
+package sops
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+// encryptValue encrypts the value as SOPS does.
+func encryptValue(t *testing.T, key []byte, value, typ, additionalData string) string {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := make([]byte, 32)
+	if _, err = rand.Read(iv); err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := gcm.Seal(nil, iv, []byte(value), []byte(additionalData))
+	data, tag := out[:len(out)-gcm.Overhead()], out[len(out)-gcm.Overhead():]
+	enc := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]", enc(data), enc(iv), enc(tag), typ)
+}
+
+// encryptKey encrypts the data key to the recipient as SOPS does.
+func encryptKey(t *testing.T, key []byte, recipient age.Recipient) string {
+	var buf bytes.Buffer
+	aw := armor.NewWriter(&buf)
+	w, err := age.Encrypt(aw, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write(key); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestDecoder(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := make([]byte, 32)
+	if _, err = io.ReadFull(rand.Reader, key); err != nil {
+		t.Fatal(err)
+	}
+	doc := map[string]interface{}{
+		"data": map[string]interface{}{
+			"password": encryptValue(t, key, "secret", "str", "data:password:"),
+			"port":     encryptValue(t, key, "3306", "int", "data:port:"),
+			"hosts": []interface{}{
+				encryptValue(t, key, "db1", "str", "data:hosts:"),
+				"db2",
+			},
+		},
+		"debug": encryptValue(t, key, "true", "bool", "debug:"),
+		"sops": map[string]interface{}{
+			"age": []interface{}{
+				map[string]interface{}{"recipient": identity.Recipient().String(), "enc": encryptKey(t, key, identity.Recipient())},
+			},
+		},
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoder := NewDecoder([]age.Identity{identity})
+	target := make(map[string]interface{})
+	if err = decoder(&config.KeyValue{Key: "secret.json", Value: b, Format: "json"}, target); err != nil {
+		t.Fatal(err)
+	}
+	data := target["data"].(map[string]interface{})
+	if data["password"] != "secret" || data["port"] != 3306 || target["debug"] != true {
+		t.Errorf("unexpected decrypted values %v", target)
+	}
+	if hosts := data["hosts"].([]interface{}); hosts[0] != "db1" || hosts[1] != "db2" {
+		t.Errorf("unexpected decrypted list %v", hosts)
+	}
+	if _, ok := target["sops"]; ok {
+		t.Error("expected the metadata removed")
+	}
+
+	// the value moved to another path fails to authenticate.
+	doc["debug"] = encryptValue(t, key, "true", "bool", "data:debug:")
+	b, _ = json.Marshal(doc)
+	if err = decoder(&config.KeyValue{Key: "secret.json", Value: b, Format: "json"}, make(map[string]interface{})); err == nil {
+		t.Error("expected an error of the value of another path")
+	}
+
+	// the other identities fail to decrypt the data key.
+	other, _ := age.GenerateX25519Identity()
+	if err = NewDecoder([]age.Identity{other})(&config.KeyValue{Key: "secret.json", Value: b, Format: "json"}, make(map[string]interface{})); err == nil {
+		t.Error("expected an error of the other identity")
+	}
+}
+
+func TestDecoderPlain(t *testing.T) {
+	target := make(map[string]interface{})
+	if err := NewDecoder(nil)(&config.KeyValue{Key: "app.json", Value: []byte(`{"name":"app"}`), Format: "json"}, target); err != nil {
+		t.Fatal(err)
+	}
+	if target["name"] != "app" {
+		t.Errorf("unexpected values %v", target)
+	}
+}
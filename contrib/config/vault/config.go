@@ -0,0 +1,151 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+// Option is vault config option.
+type Option func(o *options)
+
+type options struct {
+	ctx      context.Context
+	secrets  []secretPath
+	interval time.Duration
+}
+
+type secretPath struct {
+	path string
+	key  string
+}
+
+// WithContext with the context of the requests to vault.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithSecret with the path of a secret read into the key of the config, e.g.
+// WithSecret("secret/data/app", "") of KV v2 merged at the root, or
+// WithSecret("database/creds/app", "data.database") of dynamic credentials,
+// of which the lease is renewed and the secret is read again once expired.
+func WithSecret(path, key string) Option {
+	return func(o *options) {
+		o.secrets = append(o.secrets, secretPath{path: path, key: key})
+	}
+}
+
+// WithPollInterval with the interval of reading again the secrets without a
+// lease, e.g. of KV, default is 1m, 0 disables polling.
+func WithPollInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.interval = d
+	}
+}
+
+type source struct {
+	client  *api.Client
+	options *options
+
+	mu sync.Mutex
+	// the last read secrets and values by the paths.
+	secrets map[string]*api.Secret
+	values  map[string][]byte
+}
+
+// New new a vault config source reading the secrets of the paths, at least
+// one WithSecret is required.
+func New(client *api.Client, opts ...Option) (config.Source, error) {
+	options := &options{
+		ctx:      context.Background(),
+		interval: time.Minute,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if len(options.secrets) == 0 {
+		return nil, errors.New("secret path invalid")
+	}
+
+	return &source{
+		client:  client,
+		options: options,
+		secrets: make(map[string]*api.Secret),
+		values:  make(map[string][]byte),
+	}, nil
+}
+
+// Load return the config values
+func (s *source) Load() ([]*config.KeyValue, error) {
+	kvs := make([]*config.KeyValue, 0, len(s.options.secrets))
+	for _, sp := range s.options.secrets {
+		kv, err := s.read(sp)
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, kv)
+	}
+	return kvs, nil
+}
+
+// read reads the secret of the path, its data is encoded to JSON nested under the key.
+func (s *source) read(sp secretPath) (*config.KeyValue, error) {
+	secret, err := s.client.Logical().ReadWithContext(s.options.ctx, sp.path)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("vault: secret %s not found", sp.path)
+	}
+	data := secret.Data
+	// the secrets of KV v2 are wrapped into the data with the metadata.
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+	var value interface{} = data
+	if sp.key != "" {
+		keys := strings.Split(sp.key, ".")
+		for i := len(keys) - 1; i >= 0; i-- {
+			value = map[string]interface{}{keys[i]: value}
+		}
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.secrets[sp.path] = secret
+	s.values[sp.path] = b
+	s.mu.Unlock()
+	return &config.KeyValue{
+		Key:    sp.path,
+		Value:  b,
+		Format: "json",
+	}, nil
+}
+
+// secret returns the last read secret of the path.
+func (s *source) secret(path string) (*api.Secret, []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.secrets[path], s.values[path]
+}
+
+// Watch return the watcher
+func (s *source) Watch() (config.Watcher, error) {
+	return newWatcher(s)
+}
@@ -0,0 +1,106 @@
+package vault
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+// newTestVault returns a client of a fake vault serving a KV v2 secret and
+// the dynamic credentials of a short non-renewable lease.
+func newTestVault(t *testing.T, version *atomic.Int32) *api.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			body = map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     map[string]interface{}{"server": map[string]interface{}{"token": "kv"}},
+					"metadata": map[string]interface{}{"version": 1},
+				},
+			}
+		case "/v1/database/creds/app":
+			body = map[string]interface{}{
+				"lease_id":       "database/creds/app/1",
+				"lease_duration": 1,
+				"renewable":      false,
+				"data":           map[string]interface{}{"username": "user", "version": version.Add(1)},
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	cfg := api.DefaultConfig()
+	cfg.Address = srv.URL
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetToken("test")
+	return client
+}
+
+func TestConfig(t *testing.T) {
+	if _, err := New(nil); err == nil {
+		t.Fatal("expected an error without secrets")
+	}
+
+	var version atomic.Int32
+	client := newTestVault(t, &version)
+	source, err := New(client,
+		WithSecret("secret/data/app", ""),
+		WithSecret("database/creds/app", "data.database"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := config.New(config.WithSource(source))
+	defer c.Close()
+	if err = c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := c.Value("server.token").String(); err != nil || token != "kv" {
+		t.Errorf("expected the token of KV v2, got %s %v", token, err)
+	}
+	if username, err := c.Value("data.database.username").String(); err != nil || username != "user" {
+		t.Errorf("expected the username of the credentials, got %s %v", username, err)
+	}
+
+	// the credentials are read again once the lease expires.
+	changed := make(chan int64, 1)
+	if err = c.Watch("data.database.version", func(_ string, v config.Value) {
+		n, _ := v.Int()
+		changed <- n
+	}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case n := <-changed:
+		if n < 2 {
+			t.Errorf("expected the new credentials, got version %d", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the credentials were not read again after the lease expired")
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	var version atomic.Int32
+	source, err := New(newTestVault(t, &version), WithSecret("secret/data/none", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = source.Load(); err == nil {
+		t.Error("expected an error of the missing secret")
+	}
+}
@@ -0,0 +1,169 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+// retryInterval is the interval of reading again a secret failed to read.
+const retryInterval = time.Second
+
+type watcher struct {
+	source *source
+	ch     chan []*config.KeyValue
+	errCh  chan error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newWatcher(s *source) (*watcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watcher{
+		source: s,
+		ch:     make(chan []*config.KeyValue),
+		errCh:  make(chan error),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	var polled []secretPath
+	for _, sp := range s.options.secrets {
+		secret, _ := s.secret(sp.path)
+		if secret == nil {
+			if _, err := s.read(sp); err != nil {
+				cancel()
+				return nil, err
+			}
+			secret, _ = s.secret(sp.path)
+		}
+		if secret.LeaseID != "" {
+			go w.renew(sp)
+		} else {
+			polled = append(polled, sp)
+		}
+	}
+	if len(polled) > 0 && s.options.interval > 0 {
+		go w.poll(polled)
+	}
+
+	return w, nil
+}
+
+// renew renews the lease of the secret, which is read again once the lease
+// expires or fails to renew, e.g. of the max TTL of dynamic credentials.
+func (w *watcher) renew(sp secretPath) {
+	for {
+		secret, _ := w.source.secret(sp.path)
+		lw, err := w.source.client.NewLifetimeWatcher(&api.LifetimeWatcherInput{Secret: secret})
+		if err != nil {
+			w.send(nil, err)
+			return
+		}
+		go lw.Start()
+	renewing:
+		for {
+			select {
+			case <-w.ctx.Done():
+				lw.Stop()
+				return
+			case <-lw.RenewCh():
+			case <-lw.DoneCh():
+				break renewing
+			}
+		}
+		lw.Stop()
+		for {
+			kv, err := w.source.read(sp)
+			if err == nil {
+				if !w.send([]*config.KeyValue{kv}, nil) {
+					return
+				}
+				break
+			}
+			if !w.send(nil, err) || !w.sleep(retryInterval) {
+				return
+			}
+		}
+	}
+}
+
+// poll reads again the secrets without a lease, the changed ones are sent.
+func (w *watcher) poll(secrets []secretPath) {
+	ticker := time.NewTicker(w.source.options.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		var kvs []*config.KeyValue
+		for _, sp := range secrets {
+			_, prev := w.source.secret(sp.path)
+			kv, err := w.source.read(sp)
+			if err != nil {
+				if !w.send(nil, err) {
+					return
+				}
+				continue
+			}
+			if !bytes.Equal(prev, kv.Value) {
+				kvs = append(kvs, kv)
+			}
+		}
+		if len(kvs) > 0 && !w.send(kvs, nil) {
+			return
+		}
+	}
+}
+
+// send sends the values or the error to Next, false if the watcher is stopped.
+func (w *watcher) send(kvs []*config.KeyValue, err error) bool {
+	ch, errCh := w.ch, w.errCh
+	if err != nil {
+		ch = nil
+	} else {
+		errCh = nil
+	}
+	select {
+	case ch <- kvs:
+		return true
+	case errCh <- err:
+		return true
+	case <-w.ctx.Done():
+		return false
+	}
+}
+
+func (w *watcher) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-w.ctx.Done():
+		return false
+	}
+}
+
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	select {
+	case kvs := <-w.ch:
+		return kvs, nil
+	case err := <-w.errCh:
+		return nil, err
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	}
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	return nil
+}
@@ -0,0 +1,18 @@
+module github.com/go-kratos/kratos/contrib/diagnostics/gops/v2
+
+go 1.19
+
+require (
+	github.com/go-kratos/kratos/v2 v2.7.2
+	github.com/google/gops v0.3.28
+)
+
+require (
+	github.com/go-playground/form/v4 v4.2.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
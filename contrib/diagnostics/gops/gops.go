@@ -0,0 +1,71 @@
+package gops
+
+import (
+	"context"
+
+	"github.com/google/gops/agent"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Server = (*Agent)(nil)
+
+// Option is gops agent option.
+type Option func(*Agent)
+
+// Address with the agent listen address, default is 127.0.0.1:0.
+func Address(addr string) Option {
+	return func(a *Agent) {
+		a.opts.Addr = addr
+	}
+}
+
+// ConfigDir with the directory of the agent port files, which are
+// discovered by the gops command.
+func ConfigDir(dir string) Option {
+	return func(a *Agent) {
+		a.opts.ConfigDir = dir
+	}
+}
+
+// ReuseAddr with SO_REUSEADDR and SO_REUSEPORT set on the agent listener.
+func ReuseAddr(reuse bool) Option {
+	return func(a *Agent) {
+		a.opts.ReuseSocketAddrAndPort = reuse
+	}
+}
+
+// Agent is a gops diagnostics agent running with the app lifecycle,
+// so that the operators could inspect the goroutines, GC and memory stats,
+// and dump the heap profile with the gops command.
+//
+//	app := kratos.New(kratos.Server(httpSrv, gops.NewAgent()))
+type Agent struct {
+	opts agent.Options
+}
+
+// NewAgent creates a gops agent.
+func NewAgent(opts ...Option) *Agent {
+	a := &Agent{opts: agent.Options{Addr: "127.0.0.1:0"}}
+	for _, o := range opts {
+		o(a)
+	}
+	return a
+}
+
+// Start starts the gops agent.
+func (a *Agent) Start(context.Context) error {
+	if err := agent.Listen(a.opts); err != nil {
+		return err
+	}
+	log.Info("[gops] agent started")
+	return nil
+}
+
+// Stop stops the gops agent and removes the port file.
+func (a *Agent) Stop(context.Context) error {
+	agent.Close()
+	log.Info("[gops] agent stopping")
+	return nil
+}
@@ -0,0 +1,47 @@
+package gops
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAgent(t *testing.T) {
+	dir := t.TempDir()
+	a := NewAgent(ConfigDir(dir))
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	port, err := os.ReadFile(filepath.Join(dir, strconv.Itoa(os.Getpid())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("tcp", "127.0.0.1:"+string(port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the version signal.
+	if _, err = conn.Write([]byte{0x4}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(conn)
+	_ = conn.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "go") {
+		t.Errorf("expected go version got %s", b)
+	}
+
+	if err = a.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, strconv.Itoa(os.Getpid()))); !os.IsNotExist(err) {
+		t.Errorf("expected the port file removed, got %v", err)
+	}
+}
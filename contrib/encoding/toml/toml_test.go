@@ -0,0 +1,47 @@
+package toml
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+)
+
+type server struct {
+	Addr string `toml:"addr"`
+	Port int    `toml:"port"`
+}
+
+func TestCodec(t *testing.T) {
+	c := codec{}
+	if c.Name() != Name {
+		t.Errorf("unexpected name %s", c.Name())
+	}
+	in := server{Addr: "127.0.0.1", Port: 8000}
+	data, err := c.Marshal(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out server
+	if err := c.Unmarshal(data, &out); err != nil || !reflect.DeepEqual(in, out) {
+		t.Errorf("expected %v got %v %v", in, out, err)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "server.toml"), []byte("[server]\naddr = \"0.0.0.0\"\nport = 9000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := config.New(config.WithSource(file.NewDirSource(dir)))
+	defer c.Close()
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if port, err := c.Value("server.port").Int(); err != nil || port != 9000 {
+		t.Errorf("expected the port of the toml file, got %d %v", port, err)
+	}
+}
@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos/kratos/v2/transport/http/idempotency"
+)
+
+// locked is the value of the keys in progress.
+const locked = "\x00locked"
+
+var unlock = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+var _ idempotency.Store = (*Store)(nil)
+
+// Option is redis store option.
+type Option func(*Store)
+
+// WithPrefix with the prefix of the redis keys, default is "idempotency:".
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// Store is an idempotency.Store of redis, the responses are stored as JSON.
+type Store struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewStore creates a redis store.
+func NewStore(client redis.UniversalClient, opts ...Option) *Store {
+	s := &Store{
+		client: client,
+		prefix: "idempotency:",
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Lock marks the key in progress if it has no response.
+func (s *Store) Lock(ctx context.Context, key string, ttl time.Duration) (*idempotency.Response, error) {
+	key = s.prefix + key
+	for {
+		ok, err := s.client.SetNX(ctx, key, locked, ttl).Result()
+		if err != nil || ok {
+			return nil, err
+		}
+		data, err := s.client.Get(ctx, key).Result()
+		if err == redis.Nil {
+			// the key expired or was unlocked just now.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if data == locked {
+			return nil, idempotency.ErrInProgress
+		}
+		res := new(idempotency.Response)
+		if err = json.Unmarshal([]byte(data), res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+}
+
+// Save stores the response of the key.
+func (s *Store) Save(ctx context.Context, key string, res *idempotency.Response, ttl time.Duration) error {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+key, data, ttl).Err()
+}
+
+// Unlock removes the key if it is in progress.
+func (s *Store) Unlock(ctx context.Context, key string) error {
+	return unlock.Run(ctx, s.client, []string{s.prefix + key}, locked).Err()
+}
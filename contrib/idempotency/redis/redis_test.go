@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos/kratos/v2/transport/http/idempotency"
+)
+
+func TestStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	s := NewStore(client, WithPrefix("test:"))
+	ctx := context.Background()
+
+	if res, err := s.Lock(ctx, "k", time.Second); res != nil || err != nil {
+		t.Fatalf("expected the key locked got %v %v", res, err)
+	}
+	if !mr.Exists("test:k") {
+		t.Error("expected the prefixed key")
+	}
+	if _, err := s.Lock(ctx, "k", time.Second); err != idempotency.ErrInProgress {
+		t.Errorf("expected %v got %v", idempotency.ErrInProgress, err)
+	}
+	if err := s.Unlock(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Lock(ctx, "k", time.Second); err != nil {
+		t.Errorf("expected the unlocked key locked again got %v", err)
+	}
+	want := &idempotency.Response{Status: http.StatusCreated, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{"id":1}`), Fingerprint: "f"}
+	if err := s.Save(ctx, "k", want, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Unlock(ctx, "k"); err != nil || !mr.Exists("test:k") {
+		t.Errorf("expected the saved response kept got %v", err)
+	}
+	res, err := s.Lock(ctx, "k", time.Second)
+	if err != nil || res == nil || res.Status != want.Status || string(res.Body) != string(want.Body) || res.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("expected %v got %v %v", want, res, err)
+	}
+	mr.FastForward(2 * time.Minute)
+	if res, err := s.Lock(ctx, "k", time.Second); res != nil || err != nil {
+		t.Errorf("expected the response to expire got %v %v", res, err)
+	}
+}
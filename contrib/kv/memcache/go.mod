@@ -0,0 +1,18 @@
+module github.com/go-kratos/kratos/contrib/kv/memcache/v2
+
+go 1.19
+
+require (
+	github.com/bradfitz/gomemcache v0.0.0-20230905024940-24af94b03874
+	github.com/go-kratos/kratos/v2 v2.7.2
+)
+
+require (
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel v1.16.0 // indirect
+	go.opentelemetry.io/otel/metric v1.16.0 // indirect
+	go.opentelemetry.io/otel/trace v1.16.0 // indirect
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
@@ -0,0 +1,117 @@
+// Package memcache provides a kv.Store of memcached, the connection pool is
+// configured by memcache.Client.MaxIdleConns, TLS by memcache.Client.DialContext,
+// e.g. DialTLS, and the operations are traced by wrapping the store with kv.Trace.
+package memcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/go-kratos/kratos/v2/kv"
+)
+
+// maxRelativeTTL is the max expiration in seconds which memcached treats as
+// relative, the larger ones are unix timestamps.
+const maxRelativeTTL = 30 * 24 * time.Hour
+
+var _ kv.Store = (*Store)(nil)
+
+// Option is memcache store option.
+type Option func(*Store)
+
+// WithPrefix with the prefix of the memcached keys.
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// DialTLS returns a dial func of memcache.Client.DialContext connecting with TLS.
+func DialTLS(conf *tls.Config) func(ctx context.Context, network, address string) (net.Conn, error) {
+	d := &tls.Dialer{Config: conf}
+	return d.DialContext
+}
+
+// Store is a kv.Store of memcached, the ttl is rounded up to seconds.
+type Store struct {
+	client *memcache.Client
+	prefix string
+}
+
+// NewStore creates a memcache store.
+func NewStore(client *memcache.Client, opts ...Option) *Store {
+	s := &Store{client: client}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Get returns the value of the key.
+func (s *Store) Get(_ context.Context, key string) ([]byte, error) {
+	item, err := s.client.Get(s.prefix + key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil, kv.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.Value, nil
+}
+
+// Set sets the value of the key.
+func (s *Store) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(&memcache.Item{Key: s.prefix + key, Value: value, Expiration: expiration(ttl)})
+}
+
+// SetNX sets the value of the key if it does not exist.
+func (s *Store) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	err := s.client.Add(&memcache.Item{Key: s.prefix + key, Value: value, Expiration: expiration(ttl)})
+	if errors.Is(err, memcache.ErrNotStored) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete removes the key.
+func (s *Store) Delete(_ context.Context, key string) error {
+	err := s.client.Delete(s.prefix + key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil
+	}
+	return err
+}
+
+// CompareAndDelete removes the key if its value equals to the old value, by
+// swapping it with an expired item.
+func (s *Store) CompareAndDelete(_ context.Context, key string, old []byte) (bool, error) {
+	item, err := s.client.Get(s.prefix + key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return false, nil
+	}
+	if err != nil || !bytes.Equal(item.Value, old) {
+		return false, err
+	}
+	item.Expiration = -1
+	err = s.client.CompareAndSwap(item)
+	if errors.Is(err, memcache.ErrCASConflict) || errors.Is(err, memcache.ErrNotStored) || errors.Is(err, memcache.ErrCacheMiss) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func expiration(ttl time.Duration) int32 {
+	if ttl <= 0 {
+		return 0
+	}
+	if ttl > maxRelativeTTL {
+		return int32(time.Now().Add(ttl).Unix())
+	}
+	return int32((ttl + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,146 @@
+package memcache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/go-kratos/kratos/v2/kv/kvtest"
+)
+
+type item struct {
+	value   []byte
+	cas     uint64
+	expires time.Time
+}
+
+// server is a fake memcached serving the commands used by the store.
+type server struct {
+	mu    sync.Mutex
+	items map[string]*item
+	cas   uint64
+	now   time.Time
+}
+
+func (s *server) serve(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func (s *server) get(key string) *item {
+	it, ok := s.items[key]
+	if !ok || (!it.expires.IsZero() && !s.now.Before(it.expires)) {
+		return nil
+	}
+	return it
+}
+
+func (s *server) handle(conn net.Conn) {
+	defer conn.Close()
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			return
+		}
+		s.mu.Lock()
+		switch f[0] {
+		case "gets", "get":
+			for _, key := range f[1:] {
+				if it := s.get(key); it != nil {
+					fmt.Fprintf(rw, "VALUE %s 0 %d %d\r\n%s\r\n", key, len(it.value), it.cas, it.value)
+				}
+			}
+			fmt.Fprint(rw, "END\r\n")
+		case "set", "add", "cas":
+			size, _ := strconv.Atoi(f[4])
+			value := make([]byte, size+2)
+			if _, err = io.ReadFull(rw, value); err != nil {
+				s.mu.Unlock()
+				return
+			}
+			exp, _ := strconv.Atoi(f[3])
+			cur := s.get(f[1])
+			switch {
+			case f[0] == "add" && cur != nil:
+				fmt.Fprint(rw, "NOT_STORED\r\n")
+			case f[0] == "cas" && cur == nil:
+				fmt.Fprint(rw, "NOT_FOUND\r\n")
+			case f[0] == "cas" && strconv.FormatUint(cur.cas, 10) != f[5]:
+				fmt.Fprint(rw, "EXISTS\r\n")
+			case exp < 0:
+				delete(s.items, f[1])
+				fmt.Fprint(rw, "STORED\r\n")
+			default:
+				s.cas++
+				it := &item{value: value[:size], cas: s.cas}
+				if exp > 0 {
+					it.expires = s.now.Add(time.Duration(exp) * time.Second)
+				}
+				s.items[f[1]] = it
+				fmt.Fprint(rw, "STORED\r\n")
+			}
+		case "delete":
+			if s.get(f[1]) == nil {
+				fmt.Fprint(rw, "NOT_FOUND\r\n")
+			} else {
+				delete(s.items, f[1])
+				fmt.Fprint(rw, "DELETED\r\n")
+			}
+		default:
+			fmt.Fprint(rw, "ERROR\r\n")
+		}
+		s.mu.Unlock()
+		if err = rw.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	srv := &server{items: make(map[string]*item), now: time.Now()}
+	client := memcache.New(srv.serve(t))
+	s := NewStore(client, WithPrefix("test:"))
+	kvtest.Run(t, s, func() {
+		srv.mu.Lock()
+		srv.now = srv.now.Add(2 * time.Second)
+		srv.mu.Unlock()
+	})
+}
+
+func TestExpiration(t *testing.T) {
+	if got := expiration(1500 * time.Millisecond); got != 2 {
+		t.Errorf("expected 2 got %d", got)
+	}
+	if got := expiration(0); got != 0 {
+		t.Errorf("expected 0 got %d", got)
+	}
+	if got := expiration(60 * 24 * time.Hour); int64(got) < time.Now().Unix() {
+		t.Errorf("expected a unix timestamp got %d", got)
+	}
+}
@@ -0,0 +1,23 @@
+module github.com/go-kratos/kratos/contrib/kv/redis/v2
+
+go 1.19
+
+require (
+	github.com/alicebob/miniredis/v2 v2.30.5
+	github.com/go-kratos/kratos/v2 v2.7.2
+	github.com/redis/go-redis/v9 v9.0.5
+)
+
+require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/yuin/gopher-lua v1.1.0 // indirect
+	go.opentelemetry.io/otel v1.16.0 // indirect
+	go.opentelemetry.io/otel/metric v1.16.0 // indirect
+	go.opentelemetry.io/otel/trace v1.16.0 // indirect
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
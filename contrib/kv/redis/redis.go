@@ -0,0 +1,72 @@
+// Package redis provides a kv.Store of redis, the connection pool and TLS are
+// configured by the options of the client, e.g. redis.Options.PoolSize and TLSConfig,
+// and the operations are traced by wrapping the store with kv.Trace.
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos/kratos/v2/kv"
+)
+
+var compareAndDelete = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+var _ kv.Store = (*Store)(nil)
+
+// Option is redis store option.
+type Option func(*Store)
+
+// WithPrefix with the prefix of the redis keys.
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// Store is a kv.Store of redis.
+type Store struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewStore creates a redis store.
+func NewStore(client redis.UniversalClient, opts ...Option) *Store {
+	s := &Store{client: client}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Get returns the value of the key.
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	v, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, kv.ErrNotFound
+	}
+	return v, err
+}
+
+// Set sets the value of the key.
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+// SetNX sets the value of the key if it does not exist.
+func (s *Store) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.prefix+key, value, ttl).Result()
+}
+
+// Delete removes the key.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+// CompareAndDelete removes the key if its value equals to the old value.
+func (s *Store) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	n, err := compareAndDelete.Run(ctx, s.client, []string{s.prefix + key}, old).Int()
+	return n == 1, err
+}
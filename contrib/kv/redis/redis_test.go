@@ -0,0 +1,27 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos/kratos/v2/kv/kvtest"
+)
+
+func TestStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	s := NewStore(client, WithPrefix("test:"))
+	kvtest.Run(t, s, func() { mr.FastForward(2 * time.Second) })
+
+	if err := s.Set(context.Background(), "k", []byte("v"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if !mr.Exists("test:k") {
+		t.Error("expected the prefixed key")
+	}
+}
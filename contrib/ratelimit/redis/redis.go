@@ -0,0 +1,146 @@
+package redis
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos/kratos/v2/middleware/ratelimit"
+)
+
+var tokenBucket = redis.NewScript(`
+local now, rate, burst = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local st = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(st[1]) or burst
+local last = tonumber(st[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - last) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "last", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+var slidingWindow = redis.NewScript(`
+local now, window, limit = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local st = redis.call("HMGET", KEYS[1], "start", "count", "prev")
+local start = tonumber(st[1]) or now
+local count = tonumber(st[2]) or 0
+local prev = tonumber(st[3]) or 0
+local elapsed = now - start
+if elapsed >= window then
+	local windows = math.floor(elapsed / window)
+	if windows > 1 then prev = 0 else prev = count end
+	count = 0
+	start = start + windows * window
+end
+local left = window - (now - start)
+local used = math.floor(prev * left / window) + count
+local allowed = 0
+if used < limit then
+	count = count + 1
+	used = used + 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "start", start, "count", count, "prev", prev)
+redis.call("PEXPIRE", KEYS[1], 2 * window)
+return {allowed, used, left, count}
+`)
+
+var _ ratelimit.Store = (*Store)(nil)
+
+// Option is redis store option.
+type Option func(*Store)
+
+// WithPrefix with the prefix of the redis keys, default is "ratelimit:".
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// Store is a ratelimit.Store of redis shared by the instances, whose clocks
+// are expected to be in sync since the time of the requests is of the instances.
+type Store struct {
+	client redis.UniversalClient
+	prefix string
+	now    func() time.Time
+}
+
+// NewStore creates a redis store.
+func NewStore(client redis.UniversalClient, opts ...Option) *Store {
+	s := &Store{
+		client: client,
+		prefix: "ratelimit:",
+		now:    time.Now,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Take takes a request of the key under the rule.
+func (s *Store) Take(ctx context.Context, key string, rule ratelimit.Rule) (ratelimit.Result, error) {
+	key = s.prefix + key
+	now := s.now().UnixMilli()
+	window := rule.Window.Milliseconds()
+	if rule.Algorithm == ratelimit.SlidingWindow {
+		vals, err := slidingWindow.Run(ctx, s.client, []string{key}, now, window, rule.Limit).Int64Slice()
+		if err != nil {
+			return ratelimit.Result{}, err
+		}
+		res := ratelimit.Result{
+			Allowed:   vals[0] == 1,
+			Limit:     rule.Limit,
+			Remaining: rule.Limit - int(vals[1]),
+			Reset:     time.Duration(vals[2]) * time.Millisecond,
+		}
+		if res.Remaining < 0 {
+			res.Remaining = 0
+		}
+		if !res.Allowed {
+			res.RetryAfter = res.Reset
+		}
+		if vals[3] > 0 {
+			// the current count decays during the next window.
+			res.Reset += rule.Window
+		}
+		return res, nil
+	}
+	burst := rule.Burst
+	if burst <= 0 {
+		burst = rule.Limit
+	}
+	rate := float64(rule.Limit) / float64(window) // tokens per millisecond
+	vals, err := tokenBucket.Run(ctx, s.client, []string{key}, now, strconv.FormatFloat(rate, 'g', -1, 64), burst).Slice()
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	allowed, _ := vals[0].(int64)
+	text, _ := vals[1].(string)
+	tokens, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	res := ratelimit.Result{
+		Allowed:   allowed == 1,
+		Limit:     burst,
+		Remaining: int(tokens),
+		Reset:     millis((float64(burst) - tokens) / rate),
+	}
+	if !res.Allowed {
+		res.RetryAfter = millis((1 - tokens) / rate)
+	}
+	return res, nil
+}
+
+func millis(ms float64) time.Duration {
+	return time.Duration(math.Ceil(ms * float64(time.Millisecond)))
+}
@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos/kratos/v2/middleware/ratelimit"
+)
+
+func TestStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	now := time.Unix(1000, 0)
+	s := NewStore(client, WithPrefix("test:"))
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	rule := ratelimit.Rule{Limit: 2, Window: time.Second, Burst: 3}
+	for i, want := range []bool{true, true, true, false} {
+		res, err := s.Take(ctx, "a", rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Allowed != want {
+			t.Errorf("request %d: expected %v got %v", i, want, res.Allowed)
+		}
+		if !res.Allowed && res.RetryAfter != 500*time.Millisecond {
+			t.Errorf("expected retry after 500ms got %v", res.RetryAfter)
+		}
+	}
+	if !mr.Exists("test:a") {
+		t.Error("expected the prefixed key")
+	}
+	now = now.Add(500 * time.Millisecond)
+	if res, _ := s.Take(ctx, "a", rule); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("expected a refilled token, got %+v", res)
+	}
+
+	rule = ratelimit.Rule{Algorithm: ratelimit.SlidingWindow, Limit: 4, Window: time.Second}
+	for i := 0; i < 4; i++ {
+		if res, err := s.Take(ctx, "b", rule); err != nil || !res.Allowed {
+			t.Fatalf("request %d: expected allowed, got %+v %v", i, res, err)
+		}
+	}
+	if res, _ := s.Take(ctx, "b", rule); res.Allowed || res.Remaining != 0 {
+		t.Errorf("expected rejected, got %+v", res)
+	}
+	now = now.Add(1250 * time.Millisecond)
+	if res, _ := s.Take(ctx, "b", rule); !res.Allowed {
+		t.Errorf("expected allowed, got %+v", res)
+	}
+	if res, _ := s.Take(ctx, "b", rule); res.Allowed {
+		t.Errorf("expected rejected, got %+v", res)
+	}
+}
@@ -0,0 +1,113 @@
+package chi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+var _ khttp.Mux = (*Mux)(nil)
+
+type headerRoute struct {
+	prefix  string
+	key     string
+	val     string
+	handler http.Handler
+}
+
+// Mux is a kratos http router backend based on chi.
+type Mux struct {
+	router  chi.Router
+	headers []headerRoute
+	routes  []khttp.RouteInfo
+}
+
+// New creates a chi router backend, it can be used with
+// the kratos http server option RouterMux.
+func New() *Mux {
+	return &Mux{router: chi.NewRouter()}
+}
+
+// ServeHTTP dispatches the request to the header routes first,
+// then to the chi router.
+func (m *Mux) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	for _, r := range m.headers {
+		if strings.HasPrefix(req.URL.Path, r.prefix) && req.Header.Get(r.key) == r.val {
+			r.handler.ServeHTTP(w, req)
+			return
+		}
+	}
+	m.router.ServeHTTP(w, req)
+}
+
+// Handle registers the handler for the given method and path template.
+func (m *Mux) Handle(method, path string, h http.Handler) error {
+	if method == "" {
+		return register(func() { m.router.Handle(path, h) })
+	}
+	if err := register(func() { m.router.Method(method, path, h) }); err != nil {
+		return err
+	}
+	m.routes = append(m.routes, khttp.RouteInfo{Method: method, Path: path})
+	return nil
+}
+
+// HandlePrefix registers the handler for the given path prefix.
+func (m *Mux) HandlePrefix(prefix string, h http.Handler) error {
+	return register(func() { m.router.Handle(prefix+"*", h) })
+}
+
+// HandleHeader registers the handler for requests of the path prefix with the
+// given header value, which are matched before the path routes.
+func (m *Mux) HandleHeader(prefix, key, val string, h http.Handler) error {
+	m.headers = append(m.headers, headerRoute{prefix: prefix, key: key, val: val, handler: h})
+	return nil
+}
+
+// register returns the panic of the chi router as the error of the route.
+func register(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("chi: %v", r)
+		}
+	}()
+	fn()
+	return nil
+}
+
+// Vars returns the path variables of the matched route.
+func (m *Mux) Vars(req *http.Request) map[string]string {
+	rctx := chi.RouteContext(req.Context())
+	if rctx == nil {
+		return nil
+	}
+	vars := make(map[string]string, len(rctx.URLParams.Keys))
+	for i, k := range rctx.URLParams.Keys {
+		vars[k] = rctx.URLParams.Values[i]
+	}
+	return vars
+}
+
+// NotFound sets the handler called when no route matches.
+func (m *Mux) NotFound(h http.Handler) {
+	m.router.NotFound(h.ServeHTTP)
+}
+
+// MethodNotAllowed sets the handler called when the route matches but the method does not.
+func (m *Mux) MethodNotAllowed(h http.Handler) {
+	m.router.MethodNotAllowed(h.ServeHTTP)
+}
+
+// Walk walks all the routes registered with a method.
+func (m *Mux) Walk(fn khttp.WalkRouteFunc) error {
+	for _, r := range m.routes {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package chi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type user struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestMux(t *testing.T) {
+	srv := khttp.NewServer(khttp.RouterMux(New()), khttp.PathPrefix("/api"))
+	route := srv.Route("/v1")
+	route.GET("/users/{id:[0-9]+}", func(ctx khttp.Context) error {
+		tr, ok := khttp.RequestFromServerContext(ctx)
+		if !ok {
+			t.Fatal("transport not found")
+		}
+		var u user
+		if err := ctx.BindVars(&u); err != nil {
+			return err
+		}
+		u.Name = tr.URL.Query().Get("name")
+		return ctx.Result(200, &u)
+	})
+	srv.HandlePrefix("/static/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.URL.Path)
+	}))
+	srv.HandleHeader("X-Test", "header", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "header")
+	})
+
+	tests := []struct {
+		path   string
+		header string
+		code   int
+		body   string
+	}{
+		{"/api/v1/users/1?name=kratos", "", http.StatusOK, `{"id":"1","name":"kratos"}`},
+		{"/api/v1/users/kratos", "", http.StatusNotFound, ""},
+		{"/api/static/index.html", "", http.StatusOK, "/api/static/index.html"},
+		{"/api/any", "header", http.StatusOK, "header"},
+		{"/any", "header", http.StatusNotFound, ""},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if test.header != "" {
+			req.Header.Set("X-Test", test.header)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != test.code {
+			t.Errorf("%s: expected %d got %d", test.path, test.code, rec.Code)
+		}
+		if test.body != "" && rec.Body.String() != test.body {
+			t.Errorf("%s: expected %s got %s", test.path, test.body, rec.Body.String())
+		}
+	}
+
+	var routes []khttp.RouteInfo
+	if err := srv.WalkRoute(func(r khttp.RouteInfo) error {
+		routes = append(routes, r)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Path != "/api/v1/users/{id:[0-9]+}" {
+		t.Errorf("unexpected routes: %v", routes)
+	}
+}
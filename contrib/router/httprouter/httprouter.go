@@ -0,0 +1,225 @@
+package httprouter
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+var _ khttp.Mux = (*Mux)(nil)
+
+// methods are registered for the routes without method.
+var methods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// {name} or {name:regexp} -> :name
+var varPattern = regexp.MustCompile(`{([^{}:]+)(:[^{}]*)?}`)
+
+type headerRoute struct {
+	prefix  string
+	key     string
+	val     string
+	handler http.Handler
+}
+
+// methodRoute is the handle of a method and a path registered in httprouter,
+// which is registered once, so that a route of the method and a route without
+// method of the same path do not conflict, the former takes precedence.
+type methodRoute struct {
+	handler http.Handler
+	any     http.Handler
+}
+
+func (r *methodRoute) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.handler != nil {
+		r.handler.ServeHTTP(w, req)
+		return
+	}
+	r.any.ServeHTTP(w, req)
+}
+
+// Mux is a kratos http router backend based on httprouter.
+// Path variables in the kratos template syntax {name} are converted into
+// the httprouter syntax :name, the regexp constraints of {name:regexp} are
+// checked once the route is matched, and the requests not matching them are
+// not found.
+type Mux struct {
+	router  *httprouter.Router
+	headers []headerRoute
+	routes  []khttp.RouteInfo
+	handles map[string]*methodRoute
+}
+
+// New creates a httprouter router backend, it can be used with
+// the kratos http server option RouterMux.
+func New() *Mux {
+	return &Mux{router: httprouter.New(), handles: make(map[string]*methodRoute)}
+}
+
+// ServeHTTP dispatches the request to the header routes first,
+// then to the httprouter router.
+func (m *Mux) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	for _, r := range m.headers {
+		if strings.HasPrefix(req.URL.Path, r.prefix) && req.Header.Get(r.key) == r.val {
+			r.handler.ServeHTTP(w, req)
+			return
+		}
+	}
+	m.router.ServeHTTP(w, req)
+}
+
+// Handle registers the handler for the given method and path template, the
+// handler without method serves the methods without a route of the path.
+func (m *Mux) Handle(method, path string, h http.Handler) error {
+	pattern := varPattern.ReplaceAllString(path, ":$1")
+	h, err := m.constrain(path, h)
+	if err != nil {
+		return err
+	}
+	if method == "" {
+		for _, method := range methods {
+			r, err := m.handle(method, pattern)
+			if err != nil {
+				return err
+			}
+			r.any = h
+		}
+		return nil
+	}
+	r, err := m.handle(method, pattern)
+	if err != nil {
+		return err
+	}
+	if r.handler != nil {
+		return fmt.Errorf("httprouter: a handle is already registered for method %s and path %s", method, path)
+	}
+	r.handler = h
+	m.routes = append(m.routes, khttp.RouteInfo{Method: method, Path: path})
+	return nil
+}
+
+// handle returns the handle of the method and the pattern, which is
+// registered in httprouter at the first call.
+func (m *Mux) handle(method, pattern string) (*methodRoute, error) {
+	key := method + " " + pattern
+	if r, ok := m.handles[key]; ok {
+		return r, nil
+	}
+	r := &methodRoute{}
+	if err := register(func() { m.router.Handler(method, pattern, r) }); err != nil {
+		return nil, err
+	}
+	m.handles[key] = r
+	return r, nil
+}
+
+// constrain returns the handler serving the requests whose path variables
+// match the regexp constraints of the path, the others are not found.
+func (m *Mux) constrain(path string, h http.Handler) (http.Handler, error) {
+	constraints := make(map[string]*regexp.Regexp)
+	for _, v := range varPattern.FindAllStringSubmatch(path, -1) {
+		if v[2] == "" {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + v[2][1:] + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("httprouter: invalid constraint of the path %s: %v", path, err)
+		}
+		constraints[v[1]] = re
+	}
+	if len(constraints) == 0 {
+		return h, nil
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		params := httprouter.ParamsFromContext(req.Context())
+		for name, re := range constraints {
+			if !re.MatchString(params.ByName(name)) {
+				m.notFound(w, req)
+				return
+			}
+		}
+		h.ServeHTTP(w, req)
+	}), nil
+}
+
+func (m *Mux) notFound(w http.ResponseWriter, req *http.Request) {
+	if m.router.NotFound != nil {
+		m.router.NotFound.ServeHTTP(w, req)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+// HandlePrefix registers the handler for the given path prefix,
+// the prefix must end with a slash.
+func (m *Mux) HandlePrefix(prefix string, h http.Handler) error {
+	for _, method := range methods {
+		if err := register(func() { m.router.Handler(method, prefix+"*filepath", h) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleHeader registers the handler for requests of the path prefix with the
+// given header value, which are matched before the path routes.
+func (m *Mux) HandleHeader(prefix, key, val string, h http.Handler) error {
+	m.headers = append(m.headers, headerRoute{prefix: prefix, key: key, val: val, handler: h})
+	return nil
+}
+
+// register returns the panic of httprouter as the error of the route, e.g. of
+// the conflicting wildcards.
+func register(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("httprouter: %v", r)
+		}
+	}()
+	fn()
+	return nil
+}
+
+// Vars returns the path variables of the matched route.
+func (m *Mux) Vars(req *http.Request) map[string]string {
+	params := httprouter.ParamsFromContext(req.Context())
+	vars := make(map[string]string, len(params))
+	for _, p := range params {
+		vars[p.Key] = p.Value
+	}
+	return vars
+}
+
+// NotFound sets the handler called when no route matches.
+func (m *Mux) NotFound(h http.Handler) {
+	m.router.NotFound = h
+}
+
+// MethodNotAllowed sets the handler called when the route matches but the method does not.
+func (m *Mux) MethodNotAllowed(h http.Handler) {
+	m.router.MethodNotAllowed = h
+}
+
+// Walk walks all the routes registered with a method.
+func (m *Mux) Walk(fn khttp.WalkRouteFunc) error {
+	for _, r := range m.routes {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package httprouter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type user struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestMux(t *testing.T) {
+	srv := khttp.NewServer(khttp.RouterMux(New()), khttp.PathPrefix("/api"))
+	route := srv.Route("/v1")
+	route.GET("/users/{id}", func(ctx khttp.Context) error {
+		tr, ok := khttp.RequestFromServerContext(ctx)
+		if !ok {
+			t.Fatal("transport not found")
+		}
+		var u user
+		if err := ctx.BindVars(&u); err != nil {
+			return err
+		}
+		u.Name = tr.URL.Query().Get("name")
+		return ctx.Result(200, &u)
+	})
+	srv.HandlePrefix("/static/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.URL.Path)
+	}))
+	srv.HandleHeader("X-Test", "header", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "header")
+	})
+
+	tests := []struct {
+		path   string
+		header string
+		code   int
+		body   string
+	}{
+		{"/api/v1/users/1?name=kratos", "", http.StatusOK, `{"id":"1","name":"kratos"}`},
+		{"/api/v1/users", "", http.StatusNotFound, ""},
+		{"/api/static/index.html", "", http.StatusOK, "/api/static/index.html"},
+		{"/api/any", "header", http.StatusOK, "header"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if test.header != "" {
+			req.Header.Set("X-Test", test.header)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != test.code {
+			t.Errorf("%s: expected %d got %d", test.path, test.code, rec.Code)
+		}
+		if test.body != "" && rec.Body.String() != test.body {
+			t.Errorf("%s: expected %s got %s", test.path, test.body, rec.Body.String())
+		}
+	}
+
+	var routes []khttp.RouteInfo
+	if err := srv.WalkRoute(func(r khttp.RouteInfo) error {
+		routes = append(routes, r)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Path != "/api/v1/users/{id}" {
+		t.Errorf("unexpected routes: %v", routes)
+	}
+}
+
+func TestMuxAnyMethod(t *testing.T) {
+	handler := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, body)
+		})
+	}
+	m := New()
+	m.Handle("", "/users", handler("any"))
+	m.Handle(http.MethodGet, "/users", handler("get"))
+	m.Handle(http.MethodPost, "/jobs", handler("post"))
+	m.Handle("", "/jobs", handler("any"))
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodGet, "/users", "get"},
+		{http.MethodPut, "/users", "any"},
+		{http.MethodPost, "/jobs", "post"},
+		{http.MethodDelete, "/jobs", "any"},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(test.method, test.path, nil))
+		if rec.Body.String() != test.body {
+			t.Errorf("%s %s: expected %s got %s", test.method, test.path, test.body, rec.Body.String())
+		}
+	}
+
+	if err := m.Handle(http.MethodGet, "/users", handler("get")); err == nil {
+		t.Error("expected the error of the duplicate route")
+	}
+	if err := m.Handle(http.MethodGet, "/users/{name}/{id}", handler("get")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Handle(http.MethodGet, "/users/{id}/{name}", handler("get")); err == nil {
+		t.Error("expected the error of the conflicting wildcards")
+	}
+}
+
+func TestMuxConstraint(t *testing.T) {
+	m := New()
+	if err := m.Handle(http.MethodGet, "/users/{id:[0-9]+}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, m.Vars(r)["id"])
+	})); err != nil {
+		t.Fatal(err)
+	}
+	for path, code := range map[string]int{"/users/1": http.StatusOK, "/users/a1": http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != code {
+			t.Errorf("%s: expected %d got %d", path, code, rec.Code)
+		}
+	}
+	if err := m.Handle(http.MethodGet, "/jobs/{id:[}", http.NotFoundHandler()); err == nil {
+		t.Error("expected the error of the invalid constraint")
+	}
+}
+
+func TestMuxHeaderPrefix(t *testing.T) {
+	srv := khttp.NewServer(khttp.RouterMux(New()), khttp.PathPrefix("/api"))
+	srv.HandleHeader("X-Test", "header", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "header")
+	})
+	for path, code := range map[string]int{"/api/index": http.StatusOK, "/other": http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Test", "header")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != code {
+			t.Errorf("%s: expected %d got %d", path, code, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/http/admin"
+)
+
+// AuditSink writes the admin audit records into a SQL table.
+type AuditSink struct {
+	db      *sql.DB
+	insert  string
+	timeout time.Duration
+}
+
+// NewAuditSink creates an audit sink of the database, e.g.
+// admin.WithAudit(sqlstore.NewAuditSink(db, sqlstore.Postgres).Write).
+func NewAuditSink(db *sql.DB, d Dialect, opts ...Option) *AuditSink {
+	table := newOptions(opts).auditTable
+	return &AuditSink{
+		db:      db,
+		insert:  d.bind("INSERT INTO " + table + " (occurred_at, principal, method, path, peer, status, allowed) VALUES (?, ?, ?, ?, ?, ?, ?)"),
+		timeout: 5 * time.Second,
+	}
+}
+
+// Write writes the record, the errors are logged so that the admin request
+// is not failed by the audit.
+func (s *AuditSink) Write(r admin.Record) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	if _, err := s.db.ExecContext(ctx, s.insert, r.Time.UTC(), r.Principal, r.Method, r.Path, r.Peer, r.Status, r.Allowed); err != nil {
+		log.Errorf("[SQLStore] failed to write audit record: %v", err)
+	}
+}
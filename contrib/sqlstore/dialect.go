@@ -0,0 +1,73 @@
+// Package sqlstore provides the database/sql stores of the idempotency keys and
+// the admin audit records, for Postgres, MySQL and SQLite. For pgx, open the
+// database with github.com/jackc/pgx/v5/stdlib and use the Postgres dialect.
+package sqlstore
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect is the SQL dialect of a database.
+type Dialect struct {
+	// Name is the name of the dialect.
+	Name string
+	// Placeholder returns the n-th bind placeholder, starting at 1.
+	Placeholder func(n int) string
+	// InsertIgnore is the INSERT statement prefix which ignores the duplicate keys,
+	// with the %s of the table, and OnConflict is the suffix of it.
+	InsertIgnore string
+	OnConflict   string
+	// Blob, AutoIncrement and Timestamp are the column types.
+	Blob          string
+	AutoIncrement string
+	Timestamp     string
+}
+
+var (
+	// Postgres is the dialect of PostgreSQL.
+	Postgres = Dialect{
+		Name:          "postgres",
+		Placeholder:   func(n int) string { return "$" + strconv.Itoa(n) },
+		InsertIgnore:  "INSERT INTO %s",
+		OnConflict:    " ON CONFLICT DO NOTHING",
+		Blob:          "BYTEA",
+		AutoIncrement: "BIGSERIAL PRIMARY KEY",
+		Timestamp:     "TIMESTAMP",
+	}
+	// MySQL is the dialect of MySQL.
+	MySQL = Dialect{
+		Name:          "mysql",
+		Placeholder:   func(int) string { return "?" },
+		InsertIgnore:  "INSERT IGNORE INTO %s",
+		Blob:          "LONGBLOB",
+		AutoIncrement: "BIGINT AUTO_INCREMENT PRIMARY KEY",
+		Timestamp:     "DATETIME(6)",
+	}
+	// SQLite is the dialect of SQLite.
+	SQLite = Dialect{
+		Name:          "sqlite",
+		Placeholder:   func(int) string { return "?" },
+		InsertIgnore:  "INSERT OR IGNORE INTO %s",
+		Blob:          "BLOB",
+		AutoIncrement: "INTEGER PRIMARY KEY AUTOINCREMENT",
+		Timestamp:     "DATETIME",
+	}
+)
+
+// bind replaces the ? of the query with the placeholders of the dialect.
+func (d Dialect) bind(query string) string {
+	var (
+		b strings.Builder
+		n int
+	)
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString(d.Placeholder(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
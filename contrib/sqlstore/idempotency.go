@@ -0,0 +1,101 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport/http/idempotency"
+)
+
+var _ idempotency.Store = (*IdempotencyStore)(nil)
+
+// IdempotencyStore is an idempotency.Store of a SQL table, the responses are
+// stored as JSON, the keys in progress have a NULL response.
+type IdempotencyStore struct {
+	db      *sql.DB
+	lock    string
+	expired string
+	get     string
+	save    string
+	unlock  string
+	sweep   string
+	now     func() time.Time
+}
+
+// NewIdempotencyStore creates an idempotency store of the database.
+func NewIdempotencyStore(db *sql.DB, d Dialect, opts ...Option) *IdempotencyStore {
+	table := newOptions(opts).idempotencyTable
+	return &IdempotencyStore{
+		db:      db,
+		lock:    d.bind(fmt.Sprintf(d.InsertIgnore, table) + " (idempotency_key, response, expires_at) VALUES (?, NULL, ?)" + d.OnConflict),
+		expired: d.bind("DELETE FROM " + table + " WHERE idempotency_key = ? AND expires_at <= ?"),
+		get:     d.bind("SELECT response FROM " + table + " WHERE idempotency_key = ?"),
+		save:    d.bind("UPDATE " + table + " SET response = ?, expires_at = ? WHERE idempotency_key = ?"),
+		unlock:  d.bind("DELETE FROM " + table + " WHERE idempotency_key = ? AND response IS NULL"),
+		sweep:   d.bind("DELETE FROM " + table + " WHERE expires_at <= ?"),
+		now:     time.Now,
+	}
+}
+
+// Lock marks the key in progress if it has no response.
+func (s *IdempotencyStore) Lock(ctx context.Context, key string, ttl time.Duration) (*idempotency.Response, error) {
+	now := s.now()
+	for {
+		if _, err := s.db.ExecContext(ctx, s.expired, key, now.UnixMilli()); err != nil {
+			return nil, err
+		}
+		res, err := s.db.ExecContext(ctx, s.lock, key, now.Add(ttl).UnixMilli())
+		if err != nil {
+			return nil, err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 1 {
+			return nil, err
+		}
+		var data []byte
+		err = s.db.QueryRowContext(ctx, s.get, key).Scan(&data)
+		if errors.Is(err, sql.ErrNoRows) {
+			// the key was unlocked just now.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			return nil, idempotency.ErrInProgress
+		}
+		r := new(idempotency.Response)
+		if err = json.Unmarshal(data, r); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+}
+
+// Save stores the response of the key.
+func (s *IdempotencyStore) Save(ctx context.Context, key string, res *idempotency.Response, ttl time.Duration) error {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, s.save, data, s.now().Add(ttl).UnixMilli(), key)
+	return err
+}
+
+// Unlock removes the key if it is in progress.
+func (s *IdempotencyStore) Unlock(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, s.unlock, key)
+	return err
+}
+
+// Sweep removes the expired keys, e.g. periodically by a cron job.
+func (s *IdempotencyStore) Sweep(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.sweep, s.now().UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
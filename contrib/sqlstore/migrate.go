@@ -0,0 +1,72 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Option is sql store option.
+type Option func(*options)
+
+type options struct {
+	idempotencyTable string
+	auditTable       string
+}
+
+// WithIdempotencyTable with the table of the idempotency keys, default is "idempotency_keys".
+func WithIdempotencyTable(name string) Option {
+	return func(o *options) {
+		o.idempotencyTable = name
+	}
+}
+
+// WithAuditTable with the table of the audit records, default is "admin_audit".
+func WithAuditTable(name string) Option {
+	return func(o *options) {
+		o.auditTable = name
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		idempotencyTable: "idempotency_keys",
+		auditTable:       "admin_audit",
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Migrate creates the tables of the stores if they do not exist.
+func Migrate(ctx context.Context, db *sql.DB, d Dialect, opts ...Option) error {
+	for _, stmt := range Schema(d, opts...) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("sqlstore: migrate: %w", err)
+		}
+	}
+	return nil
+}
+
+// Schema returns the DDL statements of the tables, e.g. for a migration tool.
+func Schema(d Dialect, opts ...Option) []string {
+	o := newOptions(opts)
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	idempotency_key VARCHAR(255) NOT NULL PRIMARY KEY,
+	response %s,
+	expires_at BIGINT NOT NULL
+)`, o.idempotencyTable, d.Blob),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id %s,
+	occurred_at %s NOT NULL,
+	principal VARCHAR(255) NOT NULL,
+	method VARCHAR(16) NOT NULL,
+	path VARCHAR(1024) NOT NULL,
+	peer VARCHAR(64) NOT NULL,
+	status INTEGER NOT NULL,
+	allowed BOOLEAN NOT NULL
+)`, o.auditTable, d.AutoIncrement, d.Timestamp),
+	}
+}
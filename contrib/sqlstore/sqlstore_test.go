@@ -0,0 +1,92 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/go-kratos/kratos/v2/transport/http/admin"
+	"github.com/go-kratos/kratos/v2/transport/http/idempotency"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	if err = Migrate(context.Background(), db, SQLite); err != nil {
+		t.Fatal(err)
+	}
+	// migrating twice is a no-op.
+	if err = Migrate(context.Background(), db, SQLite); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestBind(t *testing.T) {
+	if got := Postgres.bind("a = ? AND b = ?"); got != "a = $1 AND b = $2" {
+		t.Errorf("unexpected %s", got)
+	}
+	if got := MySQL.bind("a = ?"); got != "a = ?" {
+		t.Errorf("unexpected %s", got)
+	}
+}
+
+func TestIdempotencyStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewIdempotencyStore(openDB(t), SQLite)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if res, err := s.Lock(ctx, "k", time.Second); res != nil || err != nil {
+		t.Fatalf("expected the key locked got %v %v", res, err)
+	}
+	if _, err := s.Lock(ctx, "k", time.Second); err != idempotency.ErrInProgress {
+		t.Errorf("expected %v got %v", idempotency.ErrInProgress, err)
+	}
+	if err := s.Unlock(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Lock(ctx, "k", time.Second); err != nil {
+		t.Errorf("expected the unlocked key locked again got %v", err)
+	}
+	now = now.Add(2 * time.Second)
+	if _, err := s.Lock(ctx, "k", time.Second); err != nil {
+		t.Errorf("expected the expired lock to be taken over got %v", err)
+	}
+	want := &idempotency.Response{Status: http.StatusCreated, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{"id":1}`)}
+	if err := s.Save(ctx, "k", want, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Unlock(ctx, "k")
+	res, err := s.Lock(ctx, "k", time.Second)
+	if err != nil || res == nil || res.Status != want.Status || string(res.Body) != string(want.Body) {
+		t.Errorf("expected %v got %v %v", want, res, err)
+	}
+	now = now.Add(2 * time.Minute)
+	if n, err := s.Sweep(ctx); err != nil || n != 1 {
+		t.Errorf("expected 1 key swept got %d %v", n, err)
+	}
+}
+
+func TestAuditSink(t *testing.T) {
+	db := openDB(t)
+	s := NewAuditSink(db, SQLite, WithAuditTable("admin_audit"))
+	s.Write(admin.Record{Time: time.Now(), Principal: "ops", Method: http.MethodGet, Path: "/debug/pprof/", Peer: "10.0.0.1", Status: 200, Allowed: true})
+	var (
+		principal string
+		status    int
+		allowed   bool
+	)
+	err := db.QueryRow("SELECT principal, status, allowed FROM admin_audit").Scan(&principal, &status, &allowed)
+	if err != nil || principal != "ops" || status != 200 || !allowed {
+		t.Errorf("unexpected record %s %d %v %v", principal, status, allowed, err)
+	}
+}
@@ -0,0 +1,51 @@
+// Package echo adapts the echo handlers and the kratos handlers to each other,
+// for the incremental migration of the echo services to the kratos server.
+package echo
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type routeKey struct{}
+
+type route struct {
+	template string
+	vars     map[string]string
+}
+
+// Mount mounts the echo instance under the path prefix of the kratos server, the
+// routes of the instance include the prefix, e.g. of an echo group. The echo
+// handlers get the kratos server transport from the request context.
+func Mount(srv *khttp.Server, prefix string, e *echo.Echo) {
+	srv.HandlePrefix(prefix, e)
+}
+
+// Handler wraps the kratos handler, e.g. of a migrated route, as an echo handler.
+// The echo route path is the kratos path template and the echo params are the
+// kratos path variables.
+func Handler(r *khttp.Router, h khttp.HandlerFunc, filters ...khttp.FilterFunc) echo.HandlerFunc {
+	next := r.Handler(h, externalRoute, filters...)
+	return func(c echo.Context) error {
+		names, values := c.ParamNames(), c.ParamValues()
+		vars := make(map[string]string, len(names))
+		for i, name := range names {
+			if i < len(values) {
+				vars[name] = values[i]
+			}
+		}
+		req := c.Request()
+		ctx := context.WithValue(req.Context(), routeKey{}, route{template: c.Path(), vars: vars})
+		next.ServeHTTP(c.Response(), req.WithContext(ctx))
+		return nil
+	}
+}
+
+func externalRoute(req *http.Request) (string, map[string]string) {
+	r, _ := req.Context().Value(routeKey{}).(route)
+	return r.template, r.vars
+}
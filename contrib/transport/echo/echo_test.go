@@ -0,0 +1,49 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func TestEcho(t *testing.T) {
+	srv := khttp.NewServer()
+	e := echo.New()
+	legacy := e.Group("/legacy")
+	legacy.GET("/ping", func(c echo.Context) error {
+		_, ok := transport.FromServerContext(c.Request().Context())
+		if !ok {
+			return c.String(http.StatusOK, "pong false")
+		}
+		return c.String(http.StatusOK, "pong true")
+	})
+	legacy.GET("/users/:id", Handler(srv.Route("/"), func(ctx khttp.Context) error {
+		var in struct {
+			ID string `json:"id"`
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		tr, _ := transport.FromServerContext(ctx)
+		return ctx.Result(http.StatusOK, map[string]string{"id": in.ID, "operation": tr.Operation()})
+	}))
+	Mount(srv, "/legacy", e)
+
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/legacy/ping", nil))
+	if res.Code != http.StatusOK || res.Body.String() != "pong true" {
+		t.Errorf("unexpected response %d %s", res.Code, res.Body.String())
+	}
+	res = httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/legacy/users/42", nil))
+	body := res.Body.String()
+	if res.Code != http.StatusOK || !strings.Contains(body, `"id":"42"`) || !strings.Contains(body, `"operation":"/legacy/users/:id"`) {
+		t.Errorf("unexpected response %d %s", res.Code, body)
+	}
+}
@@ -0,0 +1,46 @@
+// Package gin adapts the gin handlers and the kratos handlers to each other,
+// for the incremental migration of the gin services to the kratos server.
+package gin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type routeKey struct{}
+
+type route struct {
+	template string
+	vars     map[string]string
+}
+
+// Mount mounts the gin engine under the path prefix of the kratos server, the
+// routes of the engine include the prefix, e.g. of a gin router group. The gin
+// handlers get the kratos server transport from the request context.
+func Mount(srv *khttp.Server, prefix string, engine *gin.Engine) {
+	srv.HandlePrefix(prefix, engine)
+}
+
+// Handler wraps the kratos handler, e.g. of a migrated route, as a gin handler.
+// The gin route path is the kratos path template and the gin params are the
+// kratos path variables.
+func Handler(r *khttp.Router, h khttp.HandlerFunc, filters ...khttp.FilterFunc) gin.HandlerFunc {
+	next := r.Handler(h, externalRoute, filters...)
+	return func(c *gin.Context) {
+		vars := make(map[string]string, len(c.Params))
+		for _, p := range c.Params {
+			vars[p.Key] = p.Value
+		}
+		ctx := context.WithValue(c.Request.Context(), routeKey{}, route{template: c.FullPath(), vars: vars})
+		next.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
+}
+
+func externalRoute(req *http.Request) (string, map[string]string) {
+	r, _ := req.Context().Value(routeKey{}).(route)
+	return r.template, r.vars
+}
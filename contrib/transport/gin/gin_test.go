@@ -0,0 +1,47 @@
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func TestGin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := khttp.NewServer()
+	engine := gin.New()
+	legacy := engine.Group("/legacy")
+	legacy.GET("/ping", func(c *gin.Context) {
+		_, ok := transport.FromServerContext(c.Request.Context())
+		c.String(http.StatusOK, "pong %v", ok)
+	})
+	legacy.GET("/users/:id", Handler(srv.Route("/"), func(ctx khttp.Context) error {
+		var in struct {
+			ID string `json:"id"`
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		tr, _ := transport.FromServerContext(ctx)
+		return ctx.Result(http.StatusOK, map[string]string{"id": in.ID, "operation": tr.Operation()})
+	}))
+	Mount(srv, "/legacy", engine)
+
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/legacy/ping", nil))
+	if res.Code != http.StatusOK || res.Body.String() != "pong true" {
+		t.Errorf("unexpected response %d %s", res.Code, res.Body.String())
+	}
+	res = httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/legacy/users/42", nil))
+	body := res.Body.String()
+	if res.Code != http.StatusOK || !strings.Contains(body, `"id":"42"`) || !strings.Contains(body, `"operation":"/legacy/users/:id"`) {
+		t.Errorf("unexpected response %d %s", res.Code, body)
+	}
+}
@@ -0,0 +1,202 @@
+package grpcweb
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const (
+	grpcContentType    = "application/grpc"
+	grpcWebContentType = "application/grpc-web"
+	grpcWebTextType    = "application/grpc-web-text"
+	websocketProtocol  = "grpc-websockets"
+)
+
+// Option is gRPC-Web bridge option.
+type Option func(*options)
+
+type options struct {
+	websocket  bool
+	originFunc func(origin string) bool
+	origins    map[string]bool
+}
+
+// WithWebsocket with websocket transport enabled, which supports
+// the client and bidirectional streaming.
+func WithWebsocket(enable bool) Option {
+	return func(o *options) {
+		o.websocket = enable
+	}
+}
+
+// WithOriginFunc with the func checking the allowed CORS and websocket origins,
+// default only the same origin is allowed. The credentials are not allowed for
+// the origins of it, which are of WithAllowedOrigins only.
+func WithOriginFunc(fn func(origin string) bool) Option {
+	return func(o *options) {
+		o.originFunc = fn
+	}
+}
+
+// WithAllowedOrigins with the allowlist of the CORS and websocket origins,
+// e.g. "https://app.example.com", whose credentialed requests are allowed by
+// Access-Control-Allow-Credentials. It is preferred over WithOriginFunc.
+func WithAllowedOrigins(origins ...string) Option {
+	return func(o *options) {
+		o.origins = make(map[string]bool, len(origins))
+		for _, origin := range origins {
+			o.origins[origin] = true
+		}
+	}
+}
+
+// Handler translates the gRPC-Web requests into calls on a gRPC server.
+type Handler struct {
+	server http.Handler
+	opts   options
+}
+
+// NewHandler creates a gRPC-Web bridge for the gRPC server, the kratos
+// gRPC server and *grpc.Server both can be used as the server handler.
+func NewHandler(server http.Handler, opts ...Option) *Handler {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Handler{server: server, opts: o}
+}
+
+// Filter returns an HTTP server filter which serves the gRPC-Web requests
+// with the bridge and passes the other requests through.
+//
+//	srv := http.NewServer(http.Filter(grpcweb.Filter(grpcSrv)))
+func Filter(server http.Handler, opts ...Option) khttp.FilterFunc {
+	h := NewHandler(server, opts...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if h.IsGRPCWebRequest(req) || h.IsWebsocketRequest(req) || h.isPreflight(req) {
+				h.ServeHTTP(w, req)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// IsGRPCWebRequest reports whether the request is a gRPC-Web request.
+func (h *Handler) IsGRPCWebRequest(req *http.Request) bool {
+	return req.Method == http.MethodPost && strings.HasPrefix(req.Header.Get("Content-Type"), grpcWebContentType)
+}
+
+// IsWebsocketRequest reports whether the request is a gRPC-Web websocket request.
+func (h *Handler) IsWebsocketRequest(req *http.Request) bool {
+	return h.opts.websocket &&
+		strings.EqualFold(req.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(req.Header.Get("Sec-Websocket-Protocol"), websocketProtocol)
+}
+
+func (h *Handler) isPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions &&
+		req.Header.Get("Origin") != "" &&
+		strings.Contains(strings.ToLower(req.Header.Get("Access-Control-Request-Headers")), "x-grpc-web")
+}
+
+// ServeHTTP serves the gRPC-Web, websocket and CORS preflight requests.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch {
+	case h.isPreflight(req):
+		h.servePreflight(w, req)
+	case h.IsWebsocketRequest(req):
+		h.serveWebsocket(w, req)
+	case h.IsGRPCWebRequest(req):
+		h.serveGRPCWeb(w, req)
+	default:
+		http.Error(w, "unsupported gRPC-Web request", http.StatusBadRequest)
+	}
+}
+
+// allowOrigin reports whether the origin of the request is allowed, and
+// whether its credentials are, which are of the allowlist only.
+func (h *Handler) allowOrigin(req *http.Request, origin string) (allowed, credentials bool) {
+	switch {
+	case h.opts.origins != nil:
+		allowed = h.opts.origins[origin]
+		return allowed, allowed
+	case h.opts.originFunc != nil:
+		return h.opts.originFunc(origin), false
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, req.Host), false
+}
+
+// setOrigin sets the CORS headers of the allowed origin, false if it is not allowed.
+func (h *Handler) setOrigin(w http.ResponseWriter, req *http.Request, origin string) bool {
+	allowed, credentials := h.allowOrigin(req, origin)
+	if !allowed {
+		return false
+	}
+	header := w.Header()
+	header.Set("Access-Control-Allow-Origin", origin)
+	if credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	header.Add("Vary", "Origin")
+	return true
+}
+
+func (h *Handler) servePreflight(w http.ResponseWriter, req *http.Request) {
+	if !h.setOrigin(w, req, req.Header.Get("Origin")) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	header := w.Header()
+	header.Set("Access-Control-Allow-Methods", http.MethodPost)
+	header.Set("Access-Control-Allow-Headers", req.Header.Get("Access-Control-Request-Headers"))
+	header.Set("Access-Control-Max-Age", "600")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) serveGRPCWeb(w http.ResponseWriter, req *http.Request) {
+	contentType := req.Header.Get("Content-Type")
+	text := strings.HasPrefix(contentType, grpcWebTextType)
+	if origin := req.Header.Get("Origin"); origin != "" && !h.setOrigin(w, req, origin) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	r := toGRPCRequest(req, grpcContentTypeOf(contentType))
+	if text {
+		r.Body = io.NopCloser(base64.NewDecoder(base64.StdEncoding, req.Body))
+	}
+	resp := newWebResponse(w, contentType, text)
+	h.server.ServeHTTP(resp, r)
+	resp.finish()
+}
+
+// toGRPCRequest makes the request look like a gRPC HTTP/2 request.
+func toGRPCRequest(req *http.Request, contentType string) *http.Request {
+	r := req.Clone(req.Context())
+	r.ProtoMajor, r.ProtoMinor, r.Proto = 2, 0, "HTTP/2.0"
+	r.Method = http.MethodPost
+	r.Header.Set("Content-Type", contentType)
+	r.Header.Set("Te", "trailers")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	return r
+}
+
+// grpcContentTypeOf converts the gRPC-Web content type into the gRPC one,
+// e.g. application/grpc-web-text+proto to application/grpc+proto.
+func grpcContentTypeOf(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	if i := strings.IndexByte(contentType, '+'); i >= 0 {
+		return grpcContentType + contentType[i:]
+	}
+	return grpcContentType
+}
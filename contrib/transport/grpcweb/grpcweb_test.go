@@ -0,0 +1,270 @@
+package grpcweb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+)
+
+func frame(msg []byte) []byte {
+	b := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(b[1:5], uint32(len(msg)))
+	return append(b, msg...)
+}
+
+func parseFrames(t *testing.T, data []byte) (msgs [][]byte, trailers http.Header) {
+	for len(data) >= 5 {
+		flag := data[0]
+		n := int(binary.BigEndian.Uint32(data[1:5]))
+		if len(data) < 5+n {
+			t.Fatalf("unexpected frame length %d", n)
+		}
+		payload := data[5 : 5+n]
+		data = data[5+n:]
+		if flag&trailerFlag == 0 {
+			msgs = append(msgs, payload)
+			continue
+		}
+		trailers = parseHeader(t, payload)
+	}
+	return msgs, trailers
+}
+
+func parseHeader(t *testing.T, payload []byte) http.Header {
+	payload = append(append([]byte{}, payload...), "\r\n"...)
+	mh, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(payload))).ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	return http.Header(mh)
+}
+
+func newTestServer(opts ...Option) *httptest.Server {
+	gs := grpc.NewServer()
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("next"))
+	})
+	return httptest.NewServer(Filter(gs, opts...)(next))
+}
+
+func healthRequest(t *testing.T) []byte {
+	b, err := proto.Marshal(&grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame(b)
+}
+
+func assertServing(t *testing.T, msgs [][]byte, trailers http.Header) {
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message got %d", len(msgs))
+	}
+	var reply grpc_health_v1.HealthCheckResponse
+	if err := proto.Unmarshal(msgs[0], &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("expected %v got %v", grpc_health_v1.HealthCheckResponse_SERVING, reply.Status)
+	}
+	if s := trailers.Get("grpc-status"); s != "0" {
+		t.Errorf("expected grpc-status 0 got %q", s)
+	}
+}
+
+func TestGRPCWeb(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/grpc.health.v1.Health/Check", "application/grpc-web+proto", bytes.NewReader(healthRequest(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/grpc-web+proto" {
+		t.Errorf("expected content type %s got %s", "application/grpc-web+proto", ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, trailers := parseFrames(t, body)
+	assertServing(t, msgs, trailers)
+}
+
+func TestGRPCWebText(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	req := base64.StdEncoding.EncodeToString(healthRequest(t))
+	resp, err := http.Post(srv.URL+"/grpc.health.v1.Health/Check", "application/grpc-web-text", strings.NewReader(req))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every flushed chunk is padded, so decode the quantums one by one.
+	var data []byte
+	for i := 0; i+4 <= len(body); i += 4 {
+		b, err := base64.StdEncoding.DecodeString(string(body[i : i+4]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, b...)
+	}
+	msgs, trailers := parseFrames(t, data)
+	assertServing(t, msgs, trailers)
+}
+
+func TestGRPCWebError(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/grpc.health.v1.Health/Unknown", "application/grpc-web+proto", bytes.NewReader(healthRequest(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, trailers := parseFrames(t, body)
+	if trailers == nil {
+		trailers = resp.Header
+	}
+	if s := trailers.Get("grpc-status"); s != "12" {
+		t.Errorf("expected grpc-status 12 got %q", s)
+	}
+}
+
+func TestFilterPassThrough(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "next" {
+		t.Errorf("expected %s got %s", "next", body)
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	srv := newTestServer(WithOriginFunc(func(origin string) bool {
+		return origin == "https://example.com"
+	}))
+	defer srv.Close()
+
+	for origin, code := range map[string]int{
+		"https://example.com": http.StatusNoContent,
+		"https://other.com":   http.StatusForbidden,
+	} {
+		req, _ := http.NewRequest(http.MethodOptions, srv.URL+"/grpc.health.v1.Health/Check", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != code {
+			t.Errorf("%s: expected %d got %d", origin, code, resp.StatusCode)
+		}
+	}
+}
+
+func TestWebsocket(t *testing.T) {
+	srv := newTestServer(WithWebsocket(true))
+	defer srv.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{websocketProtocol}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/grpc.health.v1.Health/Check", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err = conn.WriteMessage(websocket.BinaryMessage, []byte("content-type: application/grpc-web+proto\r\nx-grpc-web: 1\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.WriteMessage(websocket.BinaryMessage, append([]byte{wsDataFlag}, healthRequest(t)...)); err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.WriteMessage(websocket.BinaryMessage, []byte{wsFinishFlag}); err != nil {
+		t.Fatal(err)
+	}
+	var data []byte
+	for {
+		_, b, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		data = append(data, b...)
+	}
+	// the first frame is the response header.
+	header := data[:5+binary.BigEndian.Uint32(data[1:5])]
+	if header[0] != trailerFlag {
+		t.Fatalf("expected header frame got flag %d", header[0])
+	}
+	msgs, trailers := parseFrames(t, data[len(header):])
+	assertServing(t, msgs, trailers)
+}
+
+func TestPreflightCredentials(t *testing.T) {
+	preflight := func(srv *httptest.Server, origin string) *http.Response {
+		req, _ := http.NewRequest(http.MethodOptions, srv.URL+"/grpc.health.v1.Health/Check", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	srv := newTestServer()
+	defer srv.Close()
+	if resp := preflight(srv, "https://evil.com"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected the other origins denied by default, got %d", resp.StatusCode)
+	}
+	resp := preflight(srv, srv.URL)
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("expected the same origin allowed without credentials, got %d %v", resp.StatusCode, resp.Header)
+	}
+
+	allowed := newTestServer(WithAllowedOrigins("https://app.example.com"))
+	defer allowed.Close()
+	resp = preflight(allowed, "https://app.example.com")
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("expected the allowlisted origin with credentials, got %d %v", resp.StatusCode, resp.Header)
+	}
+	if resp := preflight(allowed, "https://evil.com"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected the origin not allowlisted denied, got %d", resp.StatusCode)
+	}
+
+	fn := newTestServer(WithOriginFunc(func(string) bool { return true }))
+	defer fn.Close()
+	if resp := preflight(fn, "https://any.com"); resp.Header.Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("expected no credentials of the origin func, got %v", resp.Header)
+	}
+}
@@ -0,0 +1,144 @@
+package grpcweb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// trailerFlag is the MSB of the gRPC-Web frame flag which indicates a trailer frame.
+const trailerFlag = 1 << 7
+
+// webResponse translates the gRPC response into a gRPC-Web response,
+// trailers are written into the body as the last frame.
+type webResponse struct {
+	w           http.ResponseWriter
+	header      http.Header
+	contentType string
+	text        bool
+	out         io.Writer
+	enc         io.WriteCloser
+	wroteHeader bool
+}
+
+func newWebResponse(w http.ResponseWriter, contentType string, text bool) *webResponse {
+	r := &webResponse{w: w, header: make(http.Header), contentType: contentType, text: text, out: w}
+	if text {
+		r.enc = base64.NewEncoder(base64.StdEncoding, w)
+		r.out = r.enc
+	}
+	return r
+}
+
+func (r *webResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *webResponse) WriteHeader(code int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	header := r.w.Header()
+	trailers := trailerKeys(r.header)
+	exposed := make([]string, 0, len(r.header))
+	for k, v := range r.header {
+		if k == "Trailer" || trailers[k] || strings.HasPrefix(k, http.TrailerPrefix) {
+			continue
+		}
+		header[k] = v
+		exposed = append(exposed, k)
+	}
+	sort.Strings(exposed)
+	if len(exposed) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(exposed, ", "))
+	}
+	header.Set("Content-Type", r.contentType)
+	header.Del("Content-Length")
+	r.w.WriteHeader(code)
+}
+
+func (r *webResponse) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.out.Write(b)
+}
+
+func (r *webResponse) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if r.text {
+		// every flushed chunk is encoded with padding, so that
+		// the client could decode it before the end of the stream.
+		_ = r.enc.Close()
+		r.enc = base64.NewEncoder(base64.StdEncoding, r.w)
+		r.out = r.enc
+	}
+	if f, ok := r.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish writes the trailers frame.
+func (r *webResponse) finish() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	_, _ = r.out.Write(trailerFrame(trailersOf(r.header)))
+	r.Flush()
+}
+
+// trailersOf returns the trailers in the response header, which are
+// declared by the Trailer header or prefixed with http.TrailerPrefix.
+func trailersOf(header http.Header) http.Header {
+	trailers := make(http.Header)
+	declared := trailerKeys(header)
+	for k, v := range header {
+		if declared[k] {
+			trailers[strings.ToLower(k)] = v
+		} else if strings.HasPrefix(k, http.TrailerPrefix) {
+			trailers[strings.ToLower(strings.TrimPrefix(k, http.TrailerPrefix))] = v
+		}
+	}
+	return trailers
+}
+
+func trailerKeys(header http.Header) map[string]bool {
+	keys := make(map[string]bool)
+	for _, v := range header.Values("Trailer") {
+		for _, k := range strings.Split(v, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				keys[http.CanonicalHeaderKey(k)] = true
+			}
+		}
+	}
+	return keys
+}
+
+// trailerFrame encodes the header into a gRPC-Web trailer frame.
+func trailerFrame(header http.Header) []byte {
+	var buf bytes.Buffer
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range header[k] {
+			buf.WriteString(k)
+			buf.WriteString(": ")
+			buf.WriteString(v)
+			buf.WriteString("\r\n")
+		}
+	}
+	frame := make([]byte, 5, 5+buf.Len())
+	frame[0] = trailerFlag
+	binary.BigEndian.PutUint32(frame[1:5], uint32(buf.Len()))
+	return append(frame, buf.Bytes()...)
+}
@@ -0,0 +1,175 @@
+package grpcweb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsDataFlag prefixes a client message carrying gRPC frames.
+	wsDataFlag = 0
+	// wsFinishFlag is the client message which closes the request stream.
+	wsFinishFlag = 1
+)
+
+var errTextMessage = errors.New("grpcweb: websocket text messages are not supported")
+
+func (h *Handler) serveWebsocket(w http.ResponseWriter, req *http.Request) {
+	upgrader := websocket.Upgrader{
+		Subprotocols: []string{websocketProtocol},
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true
+			}
+			allowed, _ := h.allowOrigin(r, origin)
+			return allowed
+		},
+	}
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	header, err := readHeader(conn)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	r := toGRPCRequest(req.WithContext(ctx), grpcContentTypeOf(header.Get("Content-Type")))
+	for k, v := range header {
+		if k == "Content-Type" {
+			continue
+		}
+		r.Header[k] = v
+	}
+	r.Body = &wsReader{conn: conn, cancel: cancel}
+	r.URL.Scheme, r.RequestURI = "", r.URL.RequestURI()
+	resp := &wsResponse{conn: conn, header: make(http.Header)}
+	h.server.ServeHTTP(resp, r)
+	resp.finish()
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+// readHeader reads the request header which is the first websocket message.
+func readHeader(conn *websocket.Conn) (http.Header, error) {
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasSuffix(data, []byte("\r\n\r\n")) {
+		data = append(data, "\r\n\r\n"...)
+	}
+	mh, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(data))).ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return http.Header(mh), nil
+}
+
+// wsReader reads the request body from the websocket messages.
+type wsReader struct {
+	conn    *websocket.Conn
+	cancel  context.CancelFunc
+	buf     []byte
+	done    bool
+	lastErr error
+}
+
+func (r *wsReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if r.lastErr != nil {
+			return 0, r.lastErr
+		}
+		typ, data, err := r.conn.ReadMessage()
+		if err != nil {
+			// the client went away.
+			r.lastErr = err
+			r.cancel()
+			return 0, err
+		}
+		if typ == websocket.TextMessage {
+			r.lastErr = errTextMessage
+			return 0, errTextMessage
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if data[0] == wsFinishFlag {
+			r.done = true
+			continue
+		}
+		r.buf = data[1:]
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *wsReader) Close() error {
+	return nil
+}
+
+// wsResponse writes the gRPC response into the websocket messages,
+// the header and trailers are written as the gRPC-Web trailer frames.
+type wsResponse struct {
+	conn        *websocket.Conn
+	header      http.Header
+	wroteHeader bool
+}
+
+func (r *wsResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *wsResponse) WriteHeader(int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	declared := trailerKeys(r.header)
+	header := make(http.Header)
+	for k, v := range r.header {
+		if k == "Trailer" || declared[k] || strings.HasPrefix(k, http.TrailerPrefix) {
+			continue
+		}
+		header[strings.ToLower(k)] = v
+	}
+	_ = r.conn.WriteMessage(websocket.BinaryMessage, trailerFrame(header))
+}
+
+func (r *wsResponse) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if err := r.conn.WriteMessage(websocket.BinaryMessage, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (r *wsResponse) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+}
+
+func (r *wsResponse) finish() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	_ = r.conn.WriteMessage(websocket.BinaryMessage, trailerFrame(trailersOf(r.header)))
+}
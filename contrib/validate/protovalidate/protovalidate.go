@@ -0,0 +1,59 @@
+// Package protovalidate validates the requests with the CEL rules of buf
+// protovalidate, as a ValidateFunc of the validator middleware:
+//
+//	v, err := protovalidate.New()
+//	...
+//	validate.Validator(validate.WithValidateFunc(v))
+package protovalidate
+
+import (
+	"github.com/bufbuild/protovalidate-go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/middleware/validate"
+)
+
+// violation is a validate.FieldError of a protovalidate violation.
+type violation struct {
+	field  string
+	reason string
+}
+
+func (v violation) Field() string  { return v.field }
+func (v violation) Reason() string { return v.reason }
+func (v violation) Error() string  { return v.field + ": " + v.reason }
+
+// violations is the validate.FieldError of all the protovalidate violations.
+type violations struct {
+	*protovalidate.ValidationError
+	errs []error
+}
+
+func (v violations) AllErrors() []error { return v.errs }
+
+// New returns the ValidateFunc of the proto messages, the other requests are skipped.
+func New(opts ...protovalidate.ValidatorOption) (validate.ValidateFunc, error) {
+	v, err := protovalidate.New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return func(req interface{}) error {
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return nil
+		}
+		return convert(v.Validate(msg))
+	}, nil
+}
+
+func convert(err error) error {
+	verr, ok := err.(*protovalidate.ValidationError)
+	if !ok {
+		return err
+	}
+	errs := make([]error, 0, len(verr.Violations))
+	for _, v := range verr.Violations {
+		errs = append(errs, violation{field: v.GetFieldPath(), reason: v.GetMessage()})
+	}
+	return violations{ValidationError: verr, errs: errs}
+}
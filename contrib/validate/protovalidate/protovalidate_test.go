@@ -0,0 +1,70 @@
+package protovalidate
+
+import (
+	"context"
+	"testing"
+
+	pv "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware/validate"
+)
+
+// newUser returns a message of a name with the min_len 3 rule.
+func newUser(t *testing.T, name string) proto.Message {
+	opts := &descriptorpb.FieldOptions{}
+	proto.SetExtension(opts, pv.E_Field, &pv.FieldConstraints{
+		Type: &pv.FieldConstraints_String_{String_: &pv.StringRules{MinLen: proto.Uint64(3)}},
+	})
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("user.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"buf/validate/validate.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("name"),
+				JsonName: proto.String("name"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				Options:  opts,
+			}},
+		}},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := fd.Messages().Get(0)
+	msg := dynamicpb.NewMessage(md)
+	msg.Set(md.Fields().Get(0), protoreflect.ValueOfString(name))
+	return msg
+}
+
+func TestValidator(t *testing.T) {
+	fn, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := validate.Validator(validate.WithValidateFunc(fn))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	if _, err := h(context.Background(), newUser(t, "kratos")); err != nil {
+		t.Errorf("expected the valid user, got %v", err)
+	}
+	if _, err := h(context.Background(), "not a message"); err != nil {
+		t.Errorf("expected the other requests skipped, got %v", err)
+	}
+	_, err = h(context.Background(), newUser(t, "k"))
+	e := errors.FromError(err)
+	if !errors.IsBadRequest(err) || e.Metadata["name"] == "" {
+		t.Errorf("expected the violation of the name, got %v with metadata %v", err, e.Metadata)
+	}
+}
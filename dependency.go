@@ -0,0 +1,86 @@
+package kratos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// maxBackoff is the max backoff between the attempts of a dependency check.
+const maxBackoff = 30 * time.Second
+
+// Dependency is a startup dependency of the app, e.g. a database ping, which
+// must pass before the servers start.
+type Dependency struct {
+	// Name is the name of the dependency.
+	Name string
+	// Check checks the dependency, e.g. a health.Checker.
+	Check func(context.Context) error
+	// Timeout is the timeout of each attempt, default is 5s.
+	Timeout time.Duration
+	// Retries is the number of the retries after the first attempt.
+	Retries int
+	// Backoff is the backoff before the first retry, which doubles
+	// after each retry up to 30s, default is 1s.
+	Backoff time.Duration
+	// FailOpen logs the failure and starts the app anyway.
+	FailOpen bool
+}
+
+// Dependencies with the startup dependencies, which are checked concurrently
+// after the BeforeStart hooks and before the servers start.
+func Dependencies(deps ...Dependency) Option {
+	return func(o *options) { o.dependencies = append(o.dependencies, deps...) }
+}
+
+// checkDependencies checks the dependencies concurrently.
+func (a *App) checkDependencies(ctx context.Context) error {
+	var eg errgroup.Group
+	for _, dep := range a.opts.dependencies {
+		dep := dep
+		eg.Go(func() error {
+			err := dep.wait(ctx)
+			if err == nil {
+				return nil
+			}
+			if dep.FailOpen {
+				log.Warnf("dependency %s is not ready, starting anyway: %v", dep.Name, err)
+				return nil
+			}
+			return fmt.Errorf("kratos: dependency %q: %w", dep.Name, err)
+		})
+	}
+	return eg.Wait()
+}
+
+// wait checks the dependency with the retries.
+func (d Dependency) wait(ctx context.Context) (err error) {
+	timeout, backoff := d.Timeout, d.Backoff
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	for attempt := 0; ; attempt++ {
+		actx, cancel := context.WithTimeout(ctx, timeout)
+		err = d.Check(actx)
+		cancel()
+		if err == nil || attempt >= d.Retries {
+			return err
+		}
+		log.Infof("dependency %s is not ready, retrying in %s: %v", d.Name, backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
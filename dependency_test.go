@@ -0,0 +1,57 @@
+package kratos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDependencies(t *testing.T) {
+	attempts := 0
+	db := Dependency{
+		Name: "db",
+		Check: func(context.Context) error {
+			if attempts++; attempts < 3 {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+		Retries: 2,
+		Backoff: time.Millisecond,
+	}
+	app := New(Dependencies(db))
+	if err := app.checkDependencies(context.Background()); err != nil || attempts != 3 {
+		t.Errorf("expected ready after 3 attempts got %d %v", attempts, err)
+	}
+
+	attempts = 0
+	db.Retries = 1
+	app = New(Dependencies(db))
+	if err := app.checkDependencies(context.Background()); err == nil || !strings.Contains(err.Error(), `dependency "db"`) {
+		t.Errorf("expected dependency error got %v", err)
+	}
+
+	attempts = 0
+	db.FailOpen = true
+	app = New(Dependencies(db))
+	if err := app.checkDependencies(context.Background()); err != nil {
+		t.Errorf("expected fail open got %v", err)
+	}
+}
+
+func TestDependencyTimeout(t *testing.T) {
+	dep := Dependency{
+		Name: "registry",
+		Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		Timeout: 10 * time.Millisecond,
+	}
+	app := New(Dependencies(dep))
+	if err := app.Run(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v got %v", context.DeadlineExceeded, err)
+	}
+}
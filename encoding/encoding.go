@@ -1,7 +1,10 @@
 package encoding
 
 import (
+	"io"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // Codec defines the interface Transport uses to encode and decode messages.  Note
@@ -18,10 +21,21 @@ type Codec interface {
 	Name() string
 }
 
-var registeredCodecs = make(map[string]Codec)
+// WriterCodec is a Codec which encodes the values into the writers, e.g. of
+// the HTTP responses, without returning the byte slices of them.
+type WriterCodec interface {
+	Codec
+	// MarshalTo writes the wire format of v to w.
+	MarshalTo(w io.Writer, v interface{}) error
+}
+
+var (
+	mu               sync.RWMutex
+	registeredCodecs = make(map[string]Codec)
+)
 
 // RegisterCodec registers the provided Codec for use with all Transport clients and
-// servers.
+// servers, which is safe to be called at runtime, e.g. to replace a Codec.
 func RegisterCodec(codec Codec) {
 	if codec == nil {
 		panic("cannot register a nil Codec")
@@ -30,7 +44,9 @@ func RegisterCodec(codec Codec) {
 		panic("cannot register Codec with empty string result for Name()")
 	}
 	contentSubtype := strings.ToLower(codec.Name())
+	mu.Lock()
 	registeredCodecs[contentSubtype] = codec
+	mu.Unlock()
 }
 
 // GetCodec gets a registered Codec by content-subtype, or nil if no Codec is
@@ -38,5 +54,19 @@ func RegisterCodec(codec Codec) {
 //
 // The content-subtype is expected to be lowercase.
 func GetCodec(contentSubtype string) Codec {
+	mu.RLock()
+	defer mu.RUnlock()
 	return registeredCodecs[contentSubtype]
 }
+
+// Codecs returns the content-subtypes of the registered Codecs, sorted.
+func Codecs() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(registeredCodecs))
+	for name := range registeredCodecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
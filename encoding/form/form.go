@@ -75,7 +75,7 @@ func (c codec) Unmarshal(data []byte, v interface{}) error {
 		return DecodeValues(m, vs)
 	}
 
-	return c.decoder.Decode(v, vs)
+	return decodeStruct(c.decoder, v, rv.Type(), vs)
 }
 
 func (codec) Name() string {
@@ -34,6 +34,31 @@ func DecodeValues(msg proto.Message, values url.Values) error {
 	return nil
 }
 
+// UnknownValues returns the keys of url values that do not match any field of the proto message.
+func UnknownValues(msg proto.Message, values url.Values) []string {
+	var unknown []string
+	for key := range values {
+		if !hasFieldPath(msg.ProtoReflect(), strings.Split(key, fieldSeparater)) {
+			unknown = append(unknown, key)
+		}
+	}
+	return unknown
+}
+
+func hasFieldPath(v protoreflect.Message, fieldPath []string) bool {
+	for i, fieldName := range fieldPath {
+		fd := getFieldDescriptor(v, fieldName)
+		if fd == nil {
+			return false
+		}
+		if i == len(fieldPath)-1 || fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return true
+		}
+		v = v.Get(fd).Message()
+	}
+	return true
+}
+
 func populateFieldValues(v protoreflect.Message, fieldPath []string, values []string) error {
 	if len(fieldPath) < 1 {
 		return errors.New("no field path")
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"testing"
 
@@ -48,6 +49,18 @@ func TestDecodeValues(t *testing.T) {
 	}
 }
 
+func TestUnknownValues(t *testing.T) {
+	form, err := url.ParseQuery("id=2233&very_simple.component=5566&very_simple.unknown=1&simples=3344&unknown=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := UnknownValues(&complex.Complex{}, form)
+	sort.Strings(unknown)
+	if want := []string{"unknown", "very_simple.unknown"}; !reflect.DeepEqual(unknown, want) {
+		t.Errorf("want %v, got %v", want, unknown)
+	}
+}
+
 func TestGetFieldDescriptor(t *testing.T) {
 	comp := &complex.Complex{}
 
@@ -0,0 +1,204 @@
+package form
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/form/v4"
+)
+
+// The struct tags of the fields of the structs decoded from the values:
+//
+//	form:"name,comma"        the name of the field, default is the one of the json tag,
+//	                         and the comma makes a slice of a comma separated value.
+//	default:"10"             the value of the field of no value, comma separated of a slice.
+//	layout:"2006-01-02"      the layout of the time.Time field, default is RFC 3339,
+//	                         "2006-01-02T15:04:05", "2006-01-02" or the unix seconds.
+//
+// The values of the map fields are either "labels[env]=prod" or "labels.env=prod",
+// and the ones of the time.Duration fields are of time.ParseDuration.
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
+)
+
+// FieldError is the error of the value of the field of the struct decoded.
+type FieldError struct {
+	// Field is the name of the field, e.g. "filter.name" or "ids[1]".
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid value of field %s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func init() {
+	decoder.RegisterTagNameFunc(fieldName)
+	encoder.RegisterTagNameFunc(fieldName)
+	decoder.RegisterCustomTypeFunc(func(vals []string) (interface{}, error) {
+		return time.ParseDuration(vals[0])
+	}, time.Duration(0))
+	decoder.RegisterCustomTypeFunc(func(vals []string) (interface{}, error) {
+		return parseTime(vals[0])
+	}, time.Time{})
+}
+
+// fieldName returns the name of the field of the form tag, or of the json tag.
+func fieldName(f reflect.StructField) string {
+	if name, _, _ := strings.Cut(f.Tag.Get("form"), ","); name != "" {
+		return name
+	}
+	return f.Tag.Get("json")
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// decodeStruct decodes the values to the struct of the tags of its fields.
+func decodeStruct(d *form.Decoder, v interface{}, t reflect.Type, vs url.Values) error {
+	if err := prepareValues(vs, t, ""); err != nil {
+		return err
+	}
+	err := d.Decode(v, vs)
+	if errs, ok := err.(form.DecodeErrors); ok && len(errs) > 0 {
+		fields := make([]string, 0, len(errs))
+		for field := range errs {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		return &FieldError{Field: fields[0], Err: errs[fields[0]]}
+	}
+	return err
+}
+
+// prepareValues sets the defaults, and rewrites the comma separated, the
+// time layout and the dotted map values of the fields of the struct type.
+func prepareValues(vs url.Values, t reflect.Type, prefix string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(fieldName(f), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		key := prefix + name
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if def, ok := f.Tag.Lookup("default"); ok && (ft.Kind() != reflect.Struct || ft == timeType) && !hasValues(vs, key) {
+			if ft.Kind() == reflect.Slice {
+				vs[key] = strings.Split(def, ",")
+			} else {
+				vs[key] = []string{def}
+			}
+		}
+		_, opts, _ := strings.Cut(f.Tag.Get("form"), ",")
+		switch ft.Kind() {
+		case reflect.Slice:
+			if hasOption(opts, "comma") && len(vs[key]) > 0 {
+				vs[key] = splitValues(vs[key])
+			}
+			if elem := ft.Elem(); elem == timeType || (elem.Kind() == reflect.Ptr && elem.Elem() == timeType) {
+				ft = timeType
+			}
+		case reflect.Map:
+			for k, v := range vs {
+				if strings.HasPrefix(k, key+".") {
+					mk := key + "[" + strings.TrimPrefix(k, key+".") + "]"
+					vs[mk] = append(vs[mk], v...)
+					delete(vs, k)
+				}
+			}
+		case reflect.Struct:
+			if ft != timeType {
+				if f.Anonymous {
+					key = prefix
+				} else {
+					key += "."
+				}
+				if err := prepareValues(vs, ft, key); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if layout := f.Tag.Get("layout"); layout != "" && ft == timeType {
+			for i, s := range vs[key] {
+				if s == "" {
+					continue
+				}
+				tm, err := time.Parse(layout, s)
+				if err != nil {
+					return &FieldError{Field: key, Err: err}
+				}
+				vs[key][i] = tm.Format(time.RFC3339Nano)
+			}
+		}
+	}
+	return nil
+}
+
+// hasValues reports whether there are the values of the key or of its fields.
+func hasValues(vs url.Values, key string) bool {
+	if _, ok := vs[key]; ok {
+		return true
+	}
+	for k := range vs {
+		if strings.HasPrefix(k, key+".") || strings.HasPrefix(k, key+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasOption(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+func splitValues(values []string) []string {
+	var res []string
+	for _, v := range values {
+		res = append(res, strings.Split(v, ",")...)
+	}
+	return res
+}
@@ -0,0 +1,83 @@
+package form
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+type Filter struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags" form:"tags,comma"`
+}
+
+type Page struct {
+	Size int `json:"size" default:"20"`
+}
+
+type ListRequest struct {
+	Page
+	Filter  Filter            `json:"filter"`
+	Owner   *Filter           `json:"owner"`
+	IDs     []int64           `json:"ids"`
+	Labels  map[string]string `json:"labels"`
+	Order   string            `form:"order_by" default:"name"`
+	Since   time.Time         `json:"since" layout:"2006-01-02"`
+	Until   *time.Time        `json:"until"`
+	Timeout time.Duration     `json:"timeout"`
+	Limit   *int              `json:"limit"`
+}
+
+func TestDecodeStruct(t *testing.T) {
+	data := "filter.name=kratos&filter.tags=a,b&owner.name=go&ids=1&ids=2&labels.env=prod&labels[zone]=a" +
+		"&order_by=id&since=2024-01-02&until=2024-01-03T04:05:06Z&timeout=1.5s&limit=10"
+	var req ListRequest
+	if err := encoding.GetCodec(Name).Unmarshal([]byte(data), &req); err != nil {
+		t.Fatal(err)
+	}
+	limit := 10
+	until := time.Date(2024, 1, 3, 4, 5, 6, 0, time.UTC)
+	want := ListRequest{
+		Page:    Page{Size: 20},
+		Filter:  Filter{Name: "kratos", Tags: []string{"a", "b"}},
+		Owner:   &Filter{Name: "go"},
+		IDs:     []int64{1, 2},
+		Labels:  map[string]string{"env": "prod", "zone": "a"},
+		Order:   "id",
+		Since:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Until:   &until,
+		Timeout: 1500 * time.Millisecond,
+		Limit:   &limit,
+	}
+	if !reflect.DeepEqual(req, want) {
+		t.Errorf("expected %+v got %+v", want, req)
+	}
+
+	req = ListRequest{}
+	if err := encoding.GetCodec(Name).Unmarshal([]byte("size=5"), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Size != 5 || req.Order != "name" {
+		t.Errorf("unexpected defaults %+v", req)
+	}
+}
+
+func TestDecodeStructError(t *testing.T) {
+	tests := map[string]string{
+		"ids=1&ids=a":          "ids",
+		"since=2024/01/02":     "since",
+		"timeout=1":            "timeout",
+		"filter.name=a&size=x": "size",
+	}
+	for data, field := range tests {
+		var req ListRequest
+		err := encoding.GetCodec(Name).Unmarshal([]byte(data), &req)
+		var fe *FieldError
+		if !errors.As(err, &fe) || fe.Field != field {
+			t.Errorf("%s: expected the error of field %s got %v", data, field, err)
+		}
+	}
+}
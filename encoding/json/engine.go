@@ -0,0 +1,97 @@
+package json
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// maxBufferSize is the max size of the pooled proto buffers of MarshalTo.
+const maxBufferSize = 64 << 10
+
+// Encoder encodes the values into a writer.
+type Encoder interface {
+	Encode(v interface{}) error
+}
+
+// Engine is the JSON engine of the values other than the proto messages, e.g.
+// of the sonic or the go-json:
+//
+//	json.SetEngine(json.Engine{
+//		Marshal:   sonic.Marshal,
+//		Unmarshal: sonic.Unmarshal,
+//		NewEncoder: func(w io.Writer) json.Encoder {
+//			return sonic.ConfigDefault.NewEncoder(w)
+//		},
+//	})
+type Engine struct {
+	Marshal   func(v interface{}) ([]byte, error)
+	Unmarshal func(data []byte, v interface{}) error
+	// NewEncoder returns the encoder writing the values to w directly, the
+	// values are marshaled by Marshal if it is nil.
+	NewEncoder func(w io.Writer) Encoder
+}
+
+var (
+	engine atomic.Value
+
+	protoBuffers = sync.Pool{New: func() interface{} { return new([]byte) }}
+)
+
+func init() {
+	engine.Store(&Engine{Marshal: json.Marshal, Unmarshal: json.Unmarshal})
+}
+
+// SetEngine sets the JSON engine of the codec, which is safe to be called at
+// runtime, the funcs of encoding/json are used if they are nil.
+func SetEngine(e Engine) {
+	if e.Marshal == nil {
+		e.Marshal = json.Marshal
+	}
+	if e.Unmarshal == nil {
+		e.Unmarshal = json.Unmarshal
+	}
+	engine.Store(&e)
+}
+
+func currentEngine() *Engine {
+	return engine.Load().(*Engine)
+}
+
+// MarshalTo writes the JSON of v to w, by the encoder of the engine, or by the
+// Marshal of the engine if it has no encoder.
+func (codec) MarshalTo(w io.Writer, v interface{}) error {
+	switch m := v.(type) {
+	case json.Marshaler:
+		data, err := m.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case proto.Message:
+		b := protoBuffers.Get().(*[]byte)
+		data, err := MarshalOptions.MarshalAppend((*b)[:0], m)
+		if err == nil {
+			_, err = w.Write(data)
+		}
+		if cap(data) <= maxBufferSize {
+			*b = data
+			protoBuffers.Put(b)
+		}
+		return err
+	}
+	e := currentEngine()
+	if e.NewEncoder != nil {
+		return e.NewEncoder(w).Encode(v)
+	}
+	data, err := e.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,95 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	testData "github.com/go-kratos/kratos/v2/internal/testdata/encoding"
+)
+
+func TestJSON_MarshalTo(t *testing.T) {
+	tests := []interface{}{
+		&testMessage{Field1: "a", Field2: "<b>", Embed: &testEmbed{Level1a: 1}},
+		&testData.TestModel{Id: 1, Name: "go-kratos", Hobby: []string{"1", "2"}},
+		json.RawMessage(`{"a":1}`),
+		map[string]int{"a": 1},
+	}
+	for _, v := range tests {
+		want, err := (codec{}).Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err = (codec{}).MarshalTo(&buf, v); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != string(want) {
+			t.Errorf("expected %s got %s", want, buf.String())
+		}
+	}
+	if err := (codec{}).MarshalTo(io.Discard, make(chan int)); err == nil {
+		t.Error("expected the error of the unsupported type")
+	}
+}
+
+type testEncoder struct {
+	w io.Writer
+}
+
+func (e testEncoder) Encode(v interface{}) error {
+	_, err := e.w.Write([]byte("encoded"))
+	return err
+}
+
+func TestSetEngine(t *testing.T) {
+	defer SetEngine(Engine{})
+	var marshaled, unmarshaled bool
+	SetEngine(Engine{
+		Marshal: func(v interface{}) ([]byte, error) {
+			marshaled = true
+			return json.Marshal(v)
+		},
+		Unmarshal: func(data []byte, v interface{}) error {
+			unmarshaled = true
+			return json.Unmarshal(data, v)
+		},
+		NewEncoder: func(w io.Writer) Encoder { return testEncoder{w} },
+	})
+	data, err := (codec{}).Marshal(&testMessage{Field1: "a"})
+	if err != nil || !marshaled {
+		t.Errorf("expected marshaled by the engine, got %s %v", data, err)
+	}
+	var m testMessage
+	if err = (codec{}).Unmarshal(data, &m); err != nil || !unmarshaled || m.Field1 != "a" {
+		t.Errorf("expected unmarshaled by the engine, got %v %v", m, err)
+	}
+	var buf bytes.Buffer
+	if err = (codec{}).MarshalTo(&buf, &m); err != nil || buf.String() != "encoded" {
+		t.Errorf("expected encoded by the engine, got %s %v", buf.String(), err)
+	}
+	// the proto messages are of protojson.
+	buf.Reset()
+	if err = (codec{}).MarshalTo(&buf, &testData.TestModel{Id: 1}); err != nil || buf.String() == "encoded" {
+		t.Errorf("expected encoded by protojson, got %s %v", buf.String(), err)
+	}
+}
+
+func TestSetEngineWithoutEncoder(t *testing.T) {
+	defer SetEngine(Engine{})
+	SetEngine(Engine{
+		Marshal: func(v interface{}) ([]byte, error) {
+			return []byte("marshaled"), nil
+		},
+	})
+	var buf bytes.Buffer
+	if err := (codec{}).MarshalTo(&buf, &testMessage{Field1: "a"}); err != nil || buf.String() != "marshaled" {
+		t.Errorf("expected marshaled by the engine, got %s %v", buf.String(), err)
+	}
+	// the values not supported by encoding/json are marshaled by the engine too.
+	buf.Reset()
+	if err := (codec{}).MarshalTo(&buf, make(chan int)); err != nil || buf.String() != "marshaled" {
+		t.Errorf("expected marshaled by the engine, got %s %v", buf.String(), err)
+	}
+}
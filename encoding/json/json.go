@@ -28,6 +28,8 @@ func init() {
 	encoding.RegisterCodec(codec{})
 }
 
+var _ encoding.WriterCodec = codec{}
+
 // codec is a Codec implementation with json.
 type codec struct{}
 
@@ -38,7 +40,7 @@ func (codec) Marshal(v interface{}) ([]byte, error) {
 	case proto.Message:
 		return MarshalOptions.Marshal(m)
 	default:
-		return json.Marshal(m)
+		return currentEngine().Marshal(m)
 	}
 }
 
@@ -59,7 +61,7 @@ func (codec) Unmarshal(data []byte, v interface{}) error {
 		if m, ok := reflect.Indirect(rv).Interface().(proto.Message); ok {
 			return UnmarshalOptions.Unmarshal(data, m)
 		}
-		return json.Unmarshal(data, m)
+		return currentEngine().Unmarshal(data, m)
 	}
 }
 
@@ -0,0 +1,170 @@
+package errors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// CauseMetadataKey is the metadata key of the serialized cause chain.
+const CauseMetadataKey = "kratos-cause"
+
+// maxCauseDepth is the max depth of the serialized cause chain.
+const maxCauseDepth = 16
+
+var (
+	causeMu   sync.RWMutex
+	sentinels = make(map[string]error)
+)
+
+// RegisterCause registers the sentinel error by its unique name, e.g.
+// "io.EOF", so that the causes equal to it are reconstructed as matching it
+// by Is on the other side of the transports, both sides must register it.
+func RegisterCause(name string, sentinel error) {
+	if name == "" || sentinel == nil {
+		panic("errors: cannot register a cause without name or error")
+	}
+	causeMu.Lock()
+	defer causeMu.Unlock()
+	sentinels[name] = sentinel
+}
+
+// cause is a serialized error of the cause chain.
+type cause struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Name    string `json:"name,omitempty"`
+	Stack   string `json:"stack,omitempty"`
+}
+
+// CauseOption is a cause chain serialization option.
+type CauseOption func(*causeOptions)
+
+type causeOptions struct {
+	stack bool
+}
+
+// WithStackDigest with the digests of the stack traces of the causes formatted
+// by "%+v", e.g. of github.com/pkg/errors, to correlate them with the server
+// logs without leaking the traces.
+func WithStackDigest() CauseOption {
+	return func(o *causeOptions) {
+		o.stack = true
+	}
+}
+
+// EncodeCause returns the error of err with its cause chain serialized into
+// the metadata, the types and the messages, see DecodeCause.
+func EncodeCause(err error, opts ...CauseOption) *Error {
+	if err == nil {
+		return nil
+	}
+	o := &causeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	next := err
+	se := new(Error)
+	if errors.As(err, &se) {
+		next = se.cause
+	} else {
+		se = FromError(err)
+	}
+	var chain []cause
+	for ; next != nil && len(chain) < maxCauseDepth; next = errors.Unwrap(next) {
+		c := cause{Type: fmt.Sprintf("%T", next), Message: next.Error(), Name: sentinelName(next)}
+		if _, ok := next.(fmt.Formatter); ok && o.stack {
+			sum := sha256.Sum256([]byte(fmt.Sprintf("%+v", next)))
+			c.Stack = hex.EncodeToString(sum[:8])
+		}
+		chain = append(chain, c)
+	}
+	if len(chain) == 0 {
+		return se
+	}
+	data, _ := json.Marshal(chain)
+	ret := Clone(se)
+	ret.Metadata[CauseMetadataKey] = string(data)
+	return ret
+}
+
+func sentinelName(err error) string {
+	if !reflect.TypeOf(err).Comparable() {
+		return ""
+	}
+	causeMu.RLock()
+	defer causeMu.RUnlock()
+	for name, sentinel := range sentinels {
+		if reflect.TypeOf(sentinel).Comparable() && sentinel == err {
+			return name
+		}
+	}
+	return ""
+}
+
+// DecodeCause returns the error of err with the cause chain reconstructed from
+// its metadata, serialized by EncodeCause, so that Is matches the registered
+// sentinel errors of the chain. The causes are returned as they are otherwise.
+func DecodeCause(err error) error {
+	se := new(Error)
+	if !errors.As(err, &se) {
+		se = FromError(err)
+	}
+	if se == nil {
+		return err
+	}
+	data, ok := se.Metadata[CauseMetadataKey]
+	if !ok {
+		return err
+	}
+	var chain []cause
+	if json.Unmarshal([]byte(data), &chain) != nil {
+		return err
+	}
+	var next error
+	for i := len(chain) - 1; i >= 0; i-- {
+		c := &RemoteCause{Type: chain[i].Type, Message: chain[i].Message, Stack: chain[i].Stack, next: next}
+		if chain[i].Name != "" {
+			causeMu.RLock()
+			c.sentinel = sentinels[chain[i].Name]
+			causeMu.RUnlock()
+		}
+		next = c
+	}
+	ret := Clone(se)
+	delete(ret.Metadata, CauseMetadataKey)
+	ret.cause = next
+	return ret
+}
+
+// RemoteCause is a cause of the error reconstructed by DecodeCause.
+type RemoteCause struct {
+	// Type is the Go type of the cause, e.g. "*fs.PathError".
+	Type string
+	// Message is the message of the cause.
+	Message string
+	// Stack is the stack digest of the cause, see WithStackDigest.
+	Stack string
+
+	sentinel error
+	next     error
+}
+
+func (e *RemoteCause) Error() string { return e.Message }
+
+// Unwrap returns the next cause of the chain.
+func (e *RemoteCause) Unwrap() error { return e.next }
+
+// Is matches the registered sentinel error of the cause.
+func (e *RemoteCause) Is(target error) bool {
+	return e.sentinel != nil && errors.Is(e.sentinel, target)
+}
+
+// As finds the registered sentinel error of the cause matching target.
+func (e *RemoteCause) As(target interface{}) bool {
+	return e.sentinel != nil && errors.As(e.sentinel, target)
+}
@@ -0,0 +1,50 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"google.golang.org/grpc/status"
+)
+
+var errNotFound = fmt.Errorf("record not found")
+
+func TestCauseChain(t *testing.T) {
+	RegisterCause("io.EOF", io.EOF)
+	RegisterCause("test.NotFound", errNotFound)
+
+	err := NotFound("USER_NOT_FOUND", "user not found").WithCause(fmt.Errorf("query user: %w", errNotFound))
+	se := EncodeCause(err)
+	if se.Metadata[CauseMetadataKey] == "" {
+		t.Fatal("expected the cause chain in the metadata")
+	}
+	if len(err.Metadata) != 0 {
+		t.Error("expected the error not modified")
+	}
+
+	// over the wire, only the status is kept.
+	wire := FromError(status.Convert(se).Err())
+	if Is(wire, errNotFound) {
+		t.Fatal("expected the cause lost before decoding")
+	}
+	got := DecodeCause(wire)
+	if !Is(got, errNotFound) || Is(got, io.EOF) {
+		t.Errorf("expected the reconstructed cause to match the sentinel, got %v", got)
+	}
+	if !IsNotFound(got) || Reason(got) != "USER_NOT_FOUND" {
+		t.Errorf("expected the status kept, got %v", got)
+	}
+	if _, ok := FromError(got).Metadata[CauseMetadataKey]; ok {
+		t.Error("expected the cause metadata removed")
+	}
+	var rc *RemoteCause
+	if !As(got, &rc) || rc.Message != "query user: record not found" || rc.Type != "*fmt.wrapError" {
+		t.Errorf("unexpected remote cause %+v", rc)
+	}
+
+	plain := DecodeCause(EncodeCause(io.EOF))
+	if !Is(plain, io.EOF) || Code(plain) != UnknownCode {
+		t.Errorf("expected the plain error encoded as its own cause, got %v", plain)
+	}
+}
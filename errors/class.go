@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// Class is the classification of an error, it decides whether a call is retried,
+// counted as a circuit breaker failure or is reported as such by the metrics.
+type Class uint8
+
+const (
+	// ClassNone is the class of nil errors.
+	ClassNone Class = iota
+	// ClassTransient is the class of errors which may succeed if retried,
+	// e.g. the transport failures and unavailable servers.
+	ClassTransient
+	// ClassPermanent is the class of errors which fail the same if retried,
+	// e.g. the invalid requests and canceled calls.
+	ClassPermanent
+	// ClassThrottled is the class of errors of the rate limited or rejected calls,
+	// which should not be retried before backing off.
+	ClassThrottled
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassNone:
+		return "none"
+	case ClassTransient:
+		return "transient"
+	case ClassPermanent:
+		return "permanent"
+	case ClassThrottled:
+		return "throttled"
+	default:
+		return "unknown"
+	}
+}
+
+var classes = struct {
+	sync.RWMutex
+	codes   map[int]Class
+	reasons map[string]Class
+}{
+	codes: map[int]Class{
+		408: ClassTransient,
+		429: ClassThrottled,
+		500: ClassTransient,
+		502: ClassTransient,
+		503: ClassTransient,
+		504: ClassTransient,
+	},
+	reasons: map[string]Class{},
+}
+
+// RegisterCode registers the class of the errors with the code, the codes
+// which are not registered are permanent except the defaults: 408, 500, 502,
+// 503 and 504 are transient and 429 is throttled.
+func RegisterCode(code int, c Class) {
+	classes.Lock()
+	defer classes.Unlock()
+	classes.codes[code] = c
+}
+
+// RegisterReason registers the class of the errors with the reason,
+// which takes precedence over the class of the code.
+func RegisterReason(reason string, c Class) {
+	classes.Lock()
+	defer classes.Unlock()
+	classes.reasons[reason] = c
+}
+
+// Classify returns the class of the error. Canceled contexts are permanent and
+// exceeded deadlines are transient, the other errors are classified by the
+// reason and code of FromError, so that the errors which are not a status
+// error, e.g. the transport failures, are transient as unknown 500 errors.
+func Classify(err error) Class {
+	if err == nil {
+		return ClassNone
+	}
+	if Is(err, context.Canceled) {
+		return ClassPermanent
+	}
+	if Is(err, context.DeadlineExceeded) {
+		return ClassTransient
+	}
+	se := FromError(err)
+	classes.RLock()
+	defer classes.RUnlock()
+	if c, ok := classes.reasons[se.Reason]; ok && se.Reason != UnknownReason {
+		return c
+	}
+	if c, ok := classes.codes[int(se.Code)]; ok {
+		return c
+	}
+	return ClassPermanent
+}
@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	RegisterReason("QUOTA_EXHAUSTED", ClassThrottled)
+	RegisterCode(409, ClassTransient)
+	defer RegisterCode(409, ClassPermanent)
+	tests := []struct {
+		name string
+		err  error
+		want Class
+	}{
+		{"nil", nil, ClassNone},
+		{"unavailable", ServiceUnavailable("", ""), ClassTransient},
+		{"internal", InternalServer("", ""), ClassTransient},
+		{"bad request", BadRequest("", ""), ClassPermanent},
+		{"not implemented", New(501, "", ""), ClassPermanent},
+		{"too many requests", New(429, "", ""), ClassThrottled},
+		{"wrapped", fmt.Errorf("call: %w", GatewayTimeout("", "")), ClassTransient},
+		{"transport", errors.New("connection refused"), ClassTransient},
+		{"canceled", fmt.Errorf("call: %w", context.Canceled), ClassPermanent},
+		{"deadline", context.DeadlineExceeded, ClassTransient},
+		{"custom reason", BadRequest("QUOTA_EXHAUSTED", ""), ClassThrottled},
+		{"custom code", Conflict("", ""), ClassTransient},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Classify(test.err); got != test.want {
+				t.Errorf("expected %v got %v", test.want, got)
+			}
+		})
+	}
+}
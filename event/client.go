@@ -0,0 +1,83 @@
+package event
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/encoding/json"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// ClientOption is event client option.
+type ClientOption func(*Client)
+
+// WithCodec with the codec of the values, default is json.
+func WithCodec(name string) ClientOption {
+	return func(c *Client) {
+		c.codec = encoding.GetCodec(name)
+	}
+}
+
+// WithMiddleware with client middleware, the requests of which are the *Message.
+func WithMiddleware(m ...middleware.Middleware) ClientOption {
+	return func(c *Client) {
+		c.middleware = m
+	}
+}
+
+// WithEndpoint with the endpoint of the broker of the client transport.
+func WithEndpoint(endpoint string) ClientOption {
+	return func(c *Client) {
+		c.endpoint = endpoint
+	}
+}
+
+// Client is an event client sending the values of the topics.
+type Client struct {
+	sender     Sender
+	codec      encoding.Codec
+	middleware []middleware.Middleware
+	endpoint   string
+	handler    middleware.Handler
+}
+
+// NewClient new an event client of the sender.
+func NewClient(sender Sender, opts ...ClientOption) *Client {
+	c := &Client{sender: sender, codec: encoding.GetCodec(json.Name)}
+	for _, o := range opts {
+		o(c)
+	}
+	c.handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, c.sender.Send(ctx, req.(*Message))
+	}
+	if len(c.middleware) > 0 {
+		c.handler = middleware.Chain(c.middleware...)(c.handler)
+	}
+	return c
+}
+
+// Send sends the value of the topic encoded by the codec, or as it is if it
+// is a []byte, and returns once the broker has it.
+func (c *Client) Send(ctx context.Context, topic, key string, v interface{}) error {
+	msg, err := encode(c.codec, topic, key, v)
+	if err != nil {
+		return err
+	}
+	return c.SendMessage(ctx, msg)
+}
+
+// SendMessage sends the message through the middleware.
+func (c *Client) SendMessage(ctx context.Context, msg *Message) error {
+	if msg.Header == nil {
+		msg.Header = map[string]string{}
+	}
+	ctx = transport.NewClientContext(ctx, &Transport{endpoint: c.endpoint, message: msg, replyHeader: headerCarrier{}})
+	_, err := c.handler(ctx, msg)
+	return err
+}
+
+// Close closes the sender.
+func (c *Client) Close() error {
+	return c.sender.Close()
+}
@@ -0,0 +1,160 @@
+// Package event is the transport of the events, of which the Sender and the
+// Receiver are the brokers, e.g. the MemoryBroker in process or the webhooks
+// of HTTP, and the Kafka or NATS clients implementing them. The Client sends
+// the values encoded by the codecs through the client middleware, and the
+// Server receives them through the server middleware, and starts and stops
+// with kratos.App as the RPC servers:
+//
+//	client := event.NewClient(sender, event.WithMiddleware(tracing.Client()))
+//	err := client.Send(ctx, "user.created", user.ID, &pb.UserCreated{Id: user.ID})
+//
+//	srv := event.NewServer(event.Middleware(recovery.Recovery(), tracing.Server()))
+//	srv.Handle(receiver, func(ctx context.Context, msg *event.Message) error {
+//		var e pb.UserCreated
+//		if err := event.Decode(msg, &e); err != nil {
+//			return err
+//		}
+//		return service.OnUserCreated(ctx, &e)
+//	})
+//
+// The messages are delivered at least once, a message is acknowledged if its
+// handler returns nil, or else redelivered, so the handlers are idempotent.
+package event
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/encoding/json"
+	"github.com/go-kratos/kratos/v2/internal/httputil"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Message is an event message of a topic.
+type Message struct {
+	Topic string
+	// Key is the key of the ordering or of the partition, if the broker supports.
+	Key string
+	// Header is the header of the message, e.g. the Content-Type of the codec
+	// of the value and the trace context.
+	Header map[string]string
+	Value  []byte
+}
+
+// Handler handles a message, which is acknowledged if it returns nil, or else redelivered.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Sender sends the messages to a broker.
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+	Close() error
+}
+
+// Receiver receives the messages of its topics from a broker.
+type Receiver interface {
+	// Receive delivers the messages to the handler until ctx is done.
+	Receive(ctx context.Context, handler Handler) error
+	Close() error
+}
+
+// Decode decodes the value of the message by the codec of its Content-Type
+// header, default is json.
+func Decode(msg *Message, v interface{}) error {
+	codec := encoding.GetCodec(json.Name)
+	if ct := msg.Header["Content-Type"]; ct != "" {
+		if codec = encoding.GetCodec(httputil.ContentSubtype(ct)); codec == nil {
+			return fmt.Errorf("event: unregistered Content-Type: %s", ct)
+		}
+	}
+	return codec.Unmarshal(msg.Value, v)
+}
+
+func encode(codec encoding.Codec, topic, key string, v interface{}) (*Message, error) {
+	value, ok := v.([]byte)
+	if !ok {
+		var err error
+		if value, err = codec.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	return &Message{
+		Topic:  topic,
+		Key:    key,
+		Header: map[string]string{"Content-Type": httputil.ContentType(codec.Name())},
+		Value:  value,
+	}, nil
+}
+
+var _ transport.Transporter = (*Transport)(nil)
+
+// Transport is an event transport of a message.
+type Transport struct {
+	endpoint    string
+	message     *Message
+	replyHeader headerCarrier
+}
+
+// Kind returns the transport kind.
+func (tr *Transport) Kind() transport.Kind {
+	return transport.KindEvent
+}
+
+// Endpoint returns the transport endpoint.
+func (tr *Transport) Endpoint() string {
+	return tr.endpoint
+}
+
+// Operation returns the transport operation, which is the topic of the message.
+func (tr *Transport) Operation() string {
+	return tr.message.Topic
+}
+
+// RequestHeader returns the header of the message.
+func (tr *Transport) RequestHeader() transport.Header {
+	return headerCarrier(tr.message.Header)
+}
+
+// ReplyHeader returns the reply header, which is not delivered.
+func (tr *Transport) ReplyHeader() transport.Header {
+	return tr.replyHeader
+}
+
+// Message returns the message.
+func (tr *Transport) Message() *Message {
+	return tr.message
+}
+
+type headerCarrier map[string]string
+
+// Get returns the value associated with the passed key.
+func (hc headerCarrier) Get(key string) string {
+	return hc[key]
+}
+
+// Set stores the key-value pair.
+func (hc headerCarrier) Set(key string, value string) {
+	hc[key] = value
+}
+
+// Add sets the value of key, as the message headers are single valued.
+func (hc headerCarrier) Add(key string, value string) {
+	hc[key] = value
+}
+
+// Keys lists the keys stored in this carrier.
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range hc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns a slice of values associated with the passed key.
+func (hc headerCarrier) Values(key string) []string {
+	if v, ok := hc[key]; ok {
+		return []string{v}
+	}
+	return nil
+}
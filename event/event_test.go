@@ -0,0 +1,95 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type user struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func serve(t *testing.T, srv *Server) {
+	t.Helper()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Start(context.Background()) }()
+	t.Cleanup(func() {
+		if err := srv.Stop(context.Background()); err != nil {
+			t.Error(err)
+		}
+		if err := <-errc; err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestMemoryBroker(t *testing.T) {
+	broker := NewMemoryBroker(16)
+	propagate := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromClientContext(ctx); ok && tr.Kind() == transport.KindEvent {
+				tr.RequestHeader().Set("trace", "1")
+			}
+			return handler(ctx, req)
+		}
+	}
+	client := NewClient(broker.Sender(), WithMiddleware(propagate))
+	var trace atomic.Value
+	srv := NewServer(Middleware(func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				trace.Store(tr.Operation() + " " + tr.RequestHeader().Get("trace"))
+			}
+			return handler(ctx, req)
+		}
+	}))
+	received := make(chan user, 1)
+	var attempts atomic.Int32
+	srv.Handle(broker.Receiver("user.created"), func(ctx context.Context, msg *Message) error {
+		if attempts.Add(1) == 1 {
+			return errors.New("unavailable")
+		}
+		var u user
+		if err := Decode(msg, &u); err != nil {
+			return err
+		}
+		received <- u
+		return nil
+	})
+	serve(t, srv)
+
+	if err := client.Send(context.Background(), "user.created", "1", &user{ID: "1", Name: "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case u := <-received:
+		if u.Name != "kratos" {
+			t.Errorf("unexpected user %+v", u)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("expected a redelivery, got %d attempts", n)
+	}
+	if v := trace.Load(); v != "user.created 1" {
+		t.Errorf("unexpected trace %v", v)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var v map[string]string
+	if err := Decode(&Message{Value: []byte(`{"a":"b"}`)}, &v); err != nil || v["a"] != "b" {
+		t.Errorf("unexpected %v %v", v, err)
+	}
+	if err := Decode(&Message{Header: map[string]string{"Content-Type": "application/unknown"}}, &v); err == nil {
+		t.Error("expected the unregistered codec")
+	}
+}
@@ -0,0 +1,97 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// redeliverDelay is the delay of the redelivery of the messages failed.
+const redeliverDelay = 100 * time.Millisecond
+
+// MemoryBroker is an in-memory broker of the topics, of which the messages are
+// lost if the process exits, e.g. of the tests and of a single instance.
+type MemoryBroker struct {
+	mu     sync.Mutex
+	topics map[string]chan *Message
+	size   int
+}
+
+// NewMemoryBroker new an in-memory broker buffering the size of the messages
+// of a topic, the senders are blocked if it is full.
+func NewMemoryBroker(size int) *MemoryBroker {
+	return &MemoryBroker{topics: make(map[string]chan *Message), size: size}
+}
+
+func (b *MemoryBroker) topic(name string) chan *Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch, ok := b.topics[name]
+	if !ok {
+		ch = make(chan *Message, b.size)
+		b.topics[name] = ch
+	}
+	return ch
+}
+
+// Sender returns the sender of the broker.
+func (b *MemoryBroker) Sender() Sender {
+	return memorySender{b}
+}
+
+// Receiver returns the receiver of the messages of the topic, the receivers
+// of a topic compete for them.
+func (b *MemoryBroker) Receiver(topic string) Receiver {
+	return memoryReceiver{broker: b, topic: topic}
+}
+
+type memorySender struct {
+	broker *MemoryBroker
+}
+
+func (s memorySender) Send(ctx context.Context, msg *Message) error {
+	m := *msg
+	m.Header = make(map[string]string, len(msg.Header))
+	for k, v := range msg.Header {
+		m.Header[k] = v
+	}
+	select {
+	case s.broker.topic(m.Topic) <- &m:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (memorySender) Close() error { return nil }
+
+type memoryReceiver struct {
+	broker *MemoryBroker
+	topic  string
+}
+
+// Receive delivers the messages until ctx is done, the messages failed are
+// redelivered after a delay unless the buffer is full.
+func (r memoryReceiver) Receive(ctx context.Context, handler Handler) error {
+	ch := r.broker.topic(r.topic)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-ch:
+			if err := handler(ctx, msg); err != nil {
+				time.AfterFunc(redeliverDelay, func() {
+					select {
+					case ch <- msg:
+					default:
+						log.Errorf("[event] memory broker dropped the message of %s: %v", r.topic, err)
+					}
+				})
+			}
+		}
+	}
+}
+
+func (memoryReceiver) Close() error { return nil }
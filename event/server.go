@@ -0,0 +1,139 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	ictx "github.com/go-kratos/kratos/v2/internal/context"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Server = (*Server)(nil)
+
+// ErrServerStarted is returned by Start if the server is started.
+var ErrServerStarted = errors.New("event: server started")
+
+// ServerOption is event server option.
+type ServerOption func(*Server)
+
+// Middleware with server middleware, the requests of which are the *Message.
+func Middleware(m ...middleware.Middleware) ServerOption {
+	return func(s *Server) {
+		s.middleware = m
+	}
+}
+
+type subscription struct {
+	receiver Receiver
+	handler  middleware.Handler
+}
+
+// Server is an event server receiving the messages of the receivers.
+type Server struct {
+	middleware []middleware.Middleware
+
+	mu      sync.Mutex
+	subs    []subscription
+	started bool
+	stop    context.CancelFunc
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewServer creates an event server by options.
+func NewServer(opts ...ServerOption) *Server {
+	srv := &Server{}
+	for _, o := range opts {
+		o(srv)
+	}
+	return srv
+}
+
+// Handle registers the handler of the messages of the receiver, before the server is started.
+func (s *Server) Handle(r Receiver, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, h(ctx, req.(*Message))
+	}
+	if len(s.middleware) > 0 {
+		next = middleware.Chain(s.middleware...)(next)
+	}
+	s.subs = append(s.subs, subscription{receiver: r, handler: next})
+}
+
+// Start starts receiving the messages and blocks until the server is stopped,
+// or a receiver fails.
+func (s *Server) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return ErrServerStarted
+	}
+	s.started = true
+	// the handlers outlive the receivers and ctx until they are stopped.
+	base, cancel := context.WithCancel(ictx.Detach(ctx))
+	recvCtx, stop := context.WithCancel(ctx)
+	s.stop, s.cancel = stop, cancel
+	errc := make(chan error, len(s.subs))
+	for _, sub := range s.subs {
+		s.wg.Add(1)
+		go func(sub subscription) {
+			defer s.wg.Done()
+			err := sub.receiver.Receive(recvCtx, func(_ context.Context, msg *Message) error {
+				if msg.Header == nil {
+					msg.Header = map[string]string{}
+				}
+				ctx := transport.NewServerContext(base, &Transport{message: msg, replyHeader: headerCarrier{}})
+				_, err := sub.handler(ctx, msg)
+				return err
+			})
+			if err != nil && recvCtx.Err() == nil {
+				errc <- err
+			}
+		}(sub)
+	}
+	s.mu.Unlock()
+	log.Infof("[event] server started with %d receivers", len(s.subs))
+	select {
+	case <-recvCtx.Done():
+		return nil
+	case err := <-errc:
+		stop()
+		return err
+	}
+}
+
+// Stop stops receiving the messages, waits for the ones being handled until
+// ctx is done, when their contexts are canceled, and closes the receivers.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	stop, cancel, subs := s.stop, s.cancel, s.subs
+	s.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+	log.Info("[event] server stopping")
+	stop()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	cancel()
+	for _, sub := range subs {
+		if cerr := sub.receiver.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,183 @@
+// Package webhook is the event broker of the HTTP webhooks, of which the
+// Sender posts the messages to the URL of the Receiver, which is an
+// http.Handler mounted on the HTTP server of the receiving service:
+//
+//	receiver := webhook.NewReceiver("user.created")
+//	httpSrv.Handle("/events", receiver)
+//	eventSrv.Handle(receiver, handler)
+//
+// A message is acknowledged by a 2xx response, so that the Send of the
+// messages failed returns the error, and the sender retries them.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/event"
+)
+
+// ContentType is the content type of the messages posted.
+const ContentType = "application/vnd.kratos.event+json"
+
+// envelope is the JSON of a message, whose value is in base64.
+type envelope struct {
+	Topic  string            `json:"topic"`
+	Key    string            `json:"key,omitempty"`
+	Header map[string]string `json:"header,omitempty"`
+	Value  []byte            `json:"value"`
+}
+
+// SenderOption is webhook sender option.
+type SenderOption func(*Sender)
+
+// WithClient with the HTTP client of the sender, default is http.DefaultClient.
+func WithClient(c *http.Client) SenderOption {
+	return func(s *Sender) {
+		s.client = c
+	}
+}
+
+// WithHeader with the header of the requests, e.g. the Authorization of the receiver.
+func WithHeader(header http.Header) SenderOption {
+	return func(s *Sender) {
+		s.header = header
+	}
+}
+
+var _ event.Sender = (*Sender)(nil)
+
+// Sender posts the messages to the URL of a receiver.
+type Sender struct {
+	url    string
+	client *http.Client
+	header http.Header
+}
+
+// NewSender new a sender of the URL of the receiver.
+func NewSender(url string, opts ...SenderOption) *Sender {
+	s := &Sender{url: url, client: http.DefaultClient}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Send posts the message, and returns the error if it is not acknowledged.
+func (s *Sender) Send(ctx context.Context, msg *event.Message) error {
+	body, err := json.Marshal(envelope{Topic: msg.Topic, Key: msg.Key, Header: msg.Header, Value: msg.Value})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range s.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", ContentType)
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook: message of %s not acknowledged: %s", msg.Topic, res.Status)
+	}
+	return nil
+}
+
+// Close closes the idle connections of the client.
+func (s *Sender) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+var (
+	_ event.Receiver = (*Receiver)(nil)
+	_ http.Handler   = (*Receiver)(nil)
+)
+
+// Receiver is the http.Handler receiving the messages posted of its topics.
+type Receiver struct {
+	topics map[string]struct{}
+
+	mu      sync.RWMutex
+	handler event.Handler
+	ctx     context.Context
+	closed  chan struct{}
+	once    sync.Once
+}
+
+// NewReceiver new a receiver of the topics, or of any topic if none.
+func NewReceiver(topics ...string) *Receiver {
+	r := &Receiver{topics: make(map[string]struct{}, len(topics)), closed: make(chan struct{})}
+	for _, t := range topics {
+		r.topics[t] = struct{}{}
+	}
+	return r
+}
+
+// Receive delivers the messages posted to the handler until ctx is done,
+// the messages are rejected with 503 when it is not receiving.
+func (r *Receiver) Receive(ctx context.Context, handler event.Handler) error {
+	r.mu.Lock()
+	r.handler, r.ctx = handler, ctx
+	r.mu.Unlock()
+	select {
+	case <-ctx.Done():
+	case <-r.closed:
+	}
+	r.mu.Lock()
+	r.handler, r.ctx = nil, nil
+	r.mu.Unlock()
+	return nil
+}
+
+// Close stops receiving the messages.
+func (r *Receiver) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return nil
+}
+
+// ServeHTTP handles a message posted, replying 204 if it is acknowledged,
+// or 500 if the handler fails so that it is sent again.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var env envelope
+	if err := json.NewDecoder(req.Body).Decode(&env); err != nil || env.Topic == "" {
+		http.Error(w, "invalid event message", http.StatusBadRequest)
+		return
+	}
+	if _, ok := r.topics[env.Topic]; len(r.topics) > 0 && !ok {
+		http.Error(w, "unknown event topic", http.StatusNotFound)
+		return
+	}
+	r.mu.RLock()
+	handler, ctx := r.handler, r.ctx
+	r.mu.RUnlock()
+	if handler == nil {
+		http.Error(w, "event receiver not receiving", http.StatusServiceUnavailable)
+		return
+	}
+	if env.Header == nil {
+		env.Header = map[string]string{}
+	}
+	msg := &event.Message{Topic: env.Topic, Key: env.Key, Header: env.Header, Value: env.Value}
+	// the handler is not canceled with the request, but with the receiver.
+	if err := handler(ctx, msg); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
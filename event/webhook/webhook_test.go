@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/event"
+)
+
+func TestWebhook(t *testing.T) {
+	receiver := NewReceiver("user.created")
+	hs := httptest.NewServer(receiver)
+	defer hs.Close()
+	client := event.NewClient(NewSender(hs.URL))
+	defer client.Close()
+
+	if err := client.Send(context.Background(), "user.created", "1", map[string]string{"id": "1"}); err == nil {
+		t.Error("expected the message rejected before the receiver is receiving")
+	}
+
+	srv := event.NewServer()
+	received := make(chan map[string]string, 1)
+	srv.Handle(receiver, func(ctx context.Context, msg *event.Message) error {
+		var v map[string]string
+		if err := event.Decode(msg, &v); err != nil {
+			return err
+		}
+		if v["id"] == "fail" {
+			return errors.New("failed")
+		}
+		received <- v
+		return nil
+	})
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Start(context.Background()) }()
+	defer func() {
+		_ = srv.Stop(context.Background())
+		<-errc
+	}()
+
+	var err error
+	for i := 0; i < 100; i++ {
+		if err = client.Send(context.Background(), "user.created", "1", map[string]string{"id": "1"}); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := <-received; v["id"] != "1" {
+		t.Errorf("unexpected message %v", v)
+	}
+	if err := client.Send(context.Background(), "user.created", "1", map[string]string{"id": "fail"}); err == nil {
+		t.Error("expected the message not acknowledged")
+	}
+	if err := client.Send(context.Background(), "user.deleted", "1", []byte(`{}`)); err == nil {
+		t.Error("expected the unknown topic")
+	}
+}
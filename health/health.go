@@ -0,0 +1,271 @@
+// Package health provides the liveness and readiness checks of a service,
+// exposed as /healthz and /readyz on the HTTP server and as grpc.health.v1
+// on the gRPC server, and gates the registry registration by the readiness.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Status is the status of a health check.
+type Status string
+
+const (
+	// StatusUp means the check passed.
+	StatusUp Status = "up"
+	// StatusDown means the check failed.
+	StatusDown Status = "down"
+)
+
+// Checker checks the health of a dependency, e.g. a database.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is a func which implements the Checker.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f(ctx).
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Result is the result of the health checks.
+type Result struct {
+	Status Status            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Option is health server option.
+type Option func(*Server)
+
+// WithTimeout with the timeout of the checks, default is 5s.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.timeout = timeout
+	}
+}
+
+// WithInterval with the interval the readiness is polled at, by the registrar
+// waiting for the readiness and the gRPC health watchers, default is 1s.
+func WithInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		s.interval = interval
+	}
+}
+
+// Server is the health server which the services register the checkers into.
+// The readiness is down until Resume is called, and after Shutdown is called.
+type Server struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	mu        sync.RWMutex
+	liveness  map[string]Checker
+	readiness map[string]Checker
+	serving   atomic.Bool
+	timeout   time.Duration
+	interval  time.Duration
+}
+
+// NewServer creates a health server.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		liveness:  make(map[string]Checker),
+		readiness: make(map[string]Checker),
+		timeout:   5 * time.Second,
+		interval:  time.Second,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// AddLiveness adds a liveness checker, a failed liveness means the instance
+// should be restarted.
+func (s *Server) AddLiveness(name string, c Checker) {
+	s.mu.Lock()
+	s.liveness[name] = c
+	s.mu.Unlock()
+}
+
+// AddReadiness adds a readiness checker, a failed readiness means the instance
+// should not receive traffic.
+func (s *Server) AddReadiness(name string, c Checker) {
+	s.mu.Lock()
+	s.readiness[name] = c
+	s.mu.Unlock()
+}
+
+// Resume marks the server serving, the readiness is then reported by the checkers.
+func (s *Server) Resume() {
+	s.serving.Store(true)
+}
+
+// Shutdown marks the server not serving, the readiness is then always down.
+func (s *Server) Shutdown() {
+	s.serving.Store(false)
+}
+
+// Live runs the liveness checkers.
+func (s *Server) Live(ctx context.Context) Result {
+	s.mu.RLock()
+	checkers := copyCheckers(s.liveness)
+	s.mu.RUnlock()
+	return s.check(ctx, checkers)
+}
+
+// Ready runs the readiness checkers, the readiness is down if the server is not serving.
+func (s *Server) Ready(ctx context.Context) Result {
+	if !s.serving.Load() {
+		return Result{Status: StatusDown}
+	}
+	s.mu.RLock()
+	checkers := copyCheckers(s.readiness)
+	s.mu.RUnlock()
+	return s.check(ctx, checkers)
+}
+
+func copyCheckers(m map[string]Checker) map[string]Checker {
+	checkers := make(map[string]Checker, len(m))
+	for name, c := range m {
+		checkers[name] = c
+	}
+	return checkers
+}
+
+// check runs the checkers concurrently with the timeout.
+func (s *Server) check(ctx context.Context, checkers map[string]Checker) Result {
+	res := Result{Status: StatusUp}
+	if len(checkers) == 0 {
+		return res
+	}
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	res.Checks = make(map[string]string, len(checkers))
+	for name, c := range checkers {
+		name, c := name, c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := string(StatusUp)
+			if err := c.Check(ctx); err != nil {
+				status = err.Error()
+			}
+			mu.Lock()
+			res.Checks[name] = status
+			if status != string(StatusUp) {
+				res.Status = StatusDown
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return res
+}
+
+// LivenessHandler returns the HTTP handler of the liveness.
+func (s *Server) LivenessHandler() http.Handler {
+	return handler(s.Live)
+}
+
+// ReadinessHandler returns the HTTP handler of the readiness.
+func (s *Server) ReadinessHandler() http.Handler {
+	return handler(s.Ready)
+}
+
+func handler(check func(context.Context) Result) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		res := check(req.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if res.Status != StatusUp {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	})
+}
+
+// RegisterHTTP registers /healthz and /readyz on the HTTP server.
+func (s *Server) RegisterHTTP(srv *khttp.Server) {
+	srv.Handle("/healthz", s.LivenessHandler())
+	srv.Handle("/readyz", s.ReadinessHandler())
+}
+
+// RegisterGRPC registers the grpc.health.v1 service on the gRPC server, which
+// must be created with the grpc.CustomHealth option.
+func (s *Server) RegisterGRPC(srv grpc.ServiceRegistrar) {
+	grpc_health_v1.RegisterHealthServer(srv, s)
+}
+
+// Check implements grpc_health_v1.HealthServer, the empty service is the
+// readiness of the server, the others are the readiness checkers.
+func (s *Server) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	st, err := s.servingStatus(ctx, req.GetService())
+	if err != nil {
+		return nil, err
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: st}, nil
+}
+
+// Watch implements grpc_health_v1.HealthServer, the status is polled at the interval.
+func (s *Server) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	last := grpc_health_v1.HealthCheckResponse_UNKNOWN
+	for {
+		st, err := s.servingStatus(stream.Context(), req.GetService())
+		if err != nil {
+			st = grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+		if st != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+		select {
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) servingStatus(ctx context.Context, service string) (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+	res := s.Ready(ctx)
+	if service != "" {
+		s.mu.RLock()
+		_, ok := s.readiness[service]
+		s.mu.RUnlock()
+		if !ok {
+			return grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN, status.Error(codes.NotFound, "unknown service")
+		}
+		if res.Status == StatusUp || res.Checks[service] == string(StatusUp) {
+			return grpc_health_v1.HealthCheckResponse_SERVING, nil
+		}
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING, nil
+	}
+	if res.Status == StatusUp {
+		return grpc_health_v1.HealthCheckResponse_SERVING, nil
+	}
+	return grpc_health_v1.HealthCheckResponse_NOT_SERVING, nil
+}
@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func get(h http.Handler, path string) (int, Result) {
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+	var r Result
+	_ = json.NewDecoder(res.Body).Decode(&r)
+	return res.Code, r
+}
+
+func TestServer(t *testing.T) {
+	var dbErr error
+	h := NewServer()
+	h.AddLiveness("loop", CheckerFunc(func(context.Context) error { return nil }))
+	h.AddReadiness("db", CheckerFunc(func(context.Context) error { return dbErr }))
+	srv := khttp.NewServer()
+	h.RegisterHTTP(srv)
+
+	if code, r := get(srv, "/healthz"); code != http.StatusOK || r.Status != StatusUp || r.Checks["loop"] != "up" {
+		t.Errorf("expected live got %d %v", code, r)
+	}
+	if code, _ := get(srv, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected not ready before resume got %d", code)
+	}
+	h.Resume()
+	if code, r := get(srv, "/readyz"); code != http.StatusOK || r.Checks["db"] != "up" {
+		t.Errorf("expected ready got %d %v", code, r)
+	}
+	dbErr = errors.New("connection refused")
+	if code, r := get(srv, "/readyz"); code != http.StatusServiceUnavailable || r.Checks["db"] != "connection refused" {
+		t.Errorf("expected db down got %d %v", code, r)
+	}
+	dbErr = nil
+	h.Shutdown()
+	if code, _ := get(srv, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected not ready after shutdown got %d", code)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	h := NewServer()
+	h.AddReadiness("db", CheckerFunc(func(context.Context) error { return nil }))
+	ctx := context.Background()
+	res, err := h.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil || res.Status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected not serving got %v %v", res, err)
+	}
+	h.Resume()
+	for _, service := range []string{"", "db"} {
+		res, err = h.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		if err != nil || res.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+			t.Errorf("%q: expected serving got %v %v", service, res, err)
+		}
+	}
+	if _, err = h.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "unknown"}); err == nil {
+		t.Error("expected unknown service error")
+	}
+}
+
+type mockRegistrar struct {
+	registered bool
+}
+
+func (r *mockRegistrar) Register(context.Context, *registry.ServiceInstance) error {
+	r.registered = true
+	return nil
+}
+
+func (r *mockRegistrar) Deregister(context.Context, *registry.ServiceInstance) error {
+	r.registered = false
+	return nil
+}
+
+func TestRegistrar(t *testing.T) {
+	ready := make(chan struct{})
+	h := NewServer(WithInterval(time.Millisecond))
+	h.AddReadiness("cache", CheckerFunc(func(context.Context) error {
+		select {
+		case <-ready:
+			return nil
+		default:
+			return errors.New("warming up")
+		}
+	}))
+	mock := &mockRegistrar{}
+	r := Registrar(h, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := r.Register(ctx, &registry.ServiceInstance{}); !errors.Is(err, context.DeadlineExceeded) || mock.registered {
+		t.Fatalf("expected not registered until ready got %v", err)
+	}
+	close(ready)
+	if err := r.Register(context.Background(), &registry.ServiceInstance{}); err != nil || !mock.registered {
+		t.Fatalf("expected registered got %v", err)
+	}
+	if err := r.Deregister(context.Background(), &registry.ServiceInstance{}); err != nil || mock.registered {
+		t.Fatalf("expected deregistered got %v", err)
+	}
+	if h.Ready(context.Background()).Status != StatusDown {
+		t.Error("expected not ready after deregister")
+	}
+}
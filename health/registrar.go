@@ -0,0 +1,42 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+type registrar struct {
+	registry.Registrar
+	health *Server
+}
+
+// Registrar wraps the registrar so that the instance is registered once the
+// readiness is up, and the readiness is down before the instance is deregistered,
+// e.g. kratos.Registrar(health.Registrar(h, r)).
+func Registrar(h *Server, r registry.Registrar) registry.Registrar {
+	return &registrar{Registrar: r, health: h}
+}
+
+// Register resumes the health server and waits for the readiness until the
+// context is done, then registers the instance.
+func (r *registrar) Register(ctx context.Context, service *registry.ServiceInstance) error {
+	r.health.Resume()
+	ticker := time.NewTicker(r.health.interval)
+	defer ticker.Stop()
+	for r.health.Ready(ctx).Status != StatusUp {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return r.Registrar.Register(ctx, service)
+}
+
+// Deregister shuts down the health server and deregisters the instance.
+func (r *registrar) Deregister(ctx context.Context, service *registry.ServiceInstance) error {
+	r.health.Shutdown()
+	return r.Registrar.Deregister(ctx, service)
+}
@@ -0,0 +1,105 @@
+package activation
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// listenFdsStart is the first file descriptor passed by systemd.
+const listenFdsStart = 3
+
+var (
+	once      sync.Once
+	listeners map[string][]inherited
+	listenErr error
+	mu        sync.Mutex
+)
+
+// FileListener returns a copy of the network listener of the inherited file descriptor.
+func FileListener(fd uintptr, name string) (net.Listener, error) {
+	f := os.NewFile(fd, name)
+	if f == nil {
+		return nil, fmt.Errorf("invalid listener fd: %d", fd)
+	}
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+// Listener returns the listener passed by the systemd socket activation
+// with the name in LISTEN_FDNAMES, an empty name returns the first one.
+// Every listener is returned once.
+func Listener(name string) (net.Listener, error) {
+	once.Do(func() {
+		listeners, listenErr = parse(listenFdsStart, true)
+	})
+	if listenErr != nil {
+		return nil, listenErr
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return take(listeners, name)
+}
+
+type inherited struct {
+	lis net.Listener
+	fd  int
+}
+
+func take(all map[string][]inherited, name string) (net.Listener, error) {
+	if name == "" {
+		// the lowest file descriptor goes first.
+		for k, ls := range all {
+			if len(ls) > 0 && (name == "" || ls[0].fd < all[name][0].fd) {
+				name = k
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("no socket activation listener found")
+		}
+	}
+	ls := all[name]
+	if len(ls) == 0 {
+		return nil, fmt.Errorf("no socket activation listener found: %s", name)
+	}
+	all[name] = ls[1:]
+	return ls[0].lis, nil
+}
+
+// parse parses the listeners from LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES.
+func parse(start int, unset bool) (map[string][]inherited, error) {
+	if unset {
+		defer func() {
+			_ = os.Unsetenv("LISTEN_PID")
+			_ = os.Unsetenv("LISTEN_FDS")
+			_ = os.Unsetenv("LISTEN_FDNAMES")
+		}()
+	}
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, fmt.Errorf("no socket activation for the process")
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS: %q", os.Getenv("LISTEN_FDS"))
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	all := make(map[string][]inherited, n)
+	for i := 0; i < n; i++ {
+		fd := start + i
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		lis, err := FileListener(uintptr(fd), name)
+		if err != nil {
+			// not a stream socket, e.g. a datagram socket.
+			continue
+		}
+		all[name] = append(all[name], inherited{lis: lis, fd: fd})
+	}
+	return all, nil
+}
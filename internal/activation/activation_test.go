@@ -0,0 +1,51 @@
+package activation
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	f, err := lis.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "1")
+	t.Setenv("LISTEN_FDNAMES", "web")
+	all, err := parse(int(f.Fd()), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = take(all, "grpc"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	got, err := take(all, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Close()
+	if got.Addr().String() != lis.Addr().String() {
+		t.Errorf("expected %s got %s", lis.Addr(), got.Addr())
+	}
+	if _, err = take(all, "web"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestParseNoActivation(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+	if _, err := parse(listenFdsStart, false); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
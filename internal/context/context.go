@@ -113,3 +113,19 @@ func (mc *mergeCtx) Value(key interface{}) interface{} {
 	}
 	return mc.parent2.Value(key)
 }
+
+type detachedCtx struct {
+	context.Context
+}
+
+// Detach returns a context of the values of the parent, which is not canceled
+// nor has the deadline of the parent.
+func Detach(parent context.Context) context.Context {
+	return detachedCtx{parent}
+}
+
+func (detachedCtx) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedCtx) Done() <-chan struct{} { return nil }
+
+func (detachedCtx) Err() error { return nil }
@@ -278,3 +278,19 @@ func Test_Err2(t *testing.T) {
 		t.Errorf("expect %v, got %v", context.Canceled, ctx.Err())
 	}
 }
+
+func TestDetach(t *testing.T) {
+	type key struct{}
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "v"), time.Second)
+	cancel()
+	ctx := Detach(parent)
+	if ctx.Err() != nil || ctx.Done() != nil {
+		t.Errorf("expected the context not canceled, got %v", ctx.Err())
+	}
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expected no deadline")
+	}
+	if ctx.Value(key{}) != "v" {
+		t.Error("expected the values of the parent")
+	}
+}
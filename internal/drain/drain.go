@@ -0,0 +1,112 @@
+package drain
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// Header is the header, or the gRPC trailer in lower case, sent by the servers
+// in the drain phase.
+const Header = "X-Drain"
+
+// Set is the set of the draining or overloaded nodes, they are avoided by
+// the selection until their hints expire.
+type Set struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	nodes map[string]time.Time
+	now   func() time.Time
+}
+
+// New new a drain set, the nodes are avoided for ttl unless the hints have delays.
+func New(ttl time.Duration) *Set {
+	return &Set{ttl: ttl, nodes: make(map[string]time.Time), now: time.Now}
+}
+
+// Add avoids the node of the address for the delay, or the ttl if it is not positive.
+func (s *Set) Add(addr string, delay time.Duration) {
+	if delay <= 0 {
+		delay = s.ttl
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	until := s.now().Add(delay)
+	if until.After(s.nodes[addr]) {
+		s.nodes[addr] = until
+	}
+}
+
+// Draining reports whether the node of the address is avoided.
+func (s *Set) Draining(addr string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.draining(addr, s.now())
+}
+
+func (s *Set) draining(addr string, now time.Time) bool {
+	until, ok := s.nodes[addr]
+	if ok && !now.Before(until) {
+		delete(s.nodes, addr)
+		return false
+	}
+	return ok
+}
+
+// Filter returns the node filter skipping the draining nodes,
+// all the nodes are selected if every one of them is draining.
+func (s *Set) Filter() selector.NodeFilter {
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if len(s.nodes) == 0 {
+			return nodes
+		}
+		now := s.now()
+		newNodes := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if !s.draining(n.Address(), now) {
+				newNodes = append(newNodes, n)
+			}
+		}
+		if len(newNodes) == 0 {
+			return nodes
+		}
+		return newNodes
+	}
+}
+
+// Hint returns the drain hint of the HTTP response, the node is draining if the
+// response has the drain header, or closes the connection with Retry-After.
+// The delay is of the Retry-After header, which 429 and 503 responses may have
+// without draining the node.
+func Hint(res *http.Response) (draining bool, delay time.Duration) {
+	delay, ok := RetryAfter(res.Header.Get("Retry-After"))
+	draining = res.Header.Get(Header) != "" || (ok && res.Close)
+	return draining, delay
+}
+
+// RetryAfter parses the Retry-After header in seconds or an HTTP date.
+func RetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := time.Until(t); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
@@ -0,0 +1,63 @@
+package drain
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func TestSet(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := New(10 * time.Second)
+	s.now = func() time.Time { return now }
+	nodes := []selector.Node{
+		selector.NewNode("http", "127.0.0.1:8000", &registry.ServiceInstance{}),
+		selector.NewNode("http", "127.0.0.1:8001", &registry.ServiceInstance{}),
+	}
+	s.Add("127.0.0.1:8000", 0)
+	if got := s.Filter()(context.Background(), nodes); len(got) != 1 || got[0].Address() != "127.0.0.1:8001" {
+		t.Errorf("expect the draining node skipped, got %v", got)
+	}
+	s.Add("127.0.0.1:8001", time.Second)
+	if got := s.Filter()(context.Background(), nodes); len(got) != 2 {
+		t.Errorf("expect all the nodes when every one is draining, got %d", len(got))
+	}
+	now = now.Add(2 * time.Second)
+	if s.Draining("127.0.0.1:8001") || !s.Draining("127.0.0.1:8000") {
+		t.Errorf("expect the hint of 8001 expired and 8000 draining")
+	}
+	now = now.Add(10 * time.Second)
+	if s.Draining("127.0.0.1:8000") {
+		t.Errorf("expect the hint of 8000 expired")
+	}
+}
+
+func TestHint(t *testing.T) {
+	tests := []struct {
+		header   http.Header
+		close    bool
+		draining bool
+		delay    time.Duration
+	}{
+		{http.Header{Header: []string{"true"}}, false, true, 0},
+		{http.Header{"Retry-After": []string{"3"}}, true, true, 3 * time.Second},
+		{http.Header{"Retry-After": []string{"3"}}, false, false, 3 * time.Second},
+		{http.Header{}, true, false, 0},
+	}
+	for i, test := range tests {
+		draining, delay := Hint(&http.Response{Header: test.header, Close: test.close})
+		if draining != test.draining || delay != test.delay {
+			t.Errorf("%d: got %v %v want %v %v", i, draining, delay, test.draining, test.delay)
+		}
+	}
+	if d, ok := RetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)); !ok || d <= 0 || d > time.Minute {
+		t.Errorf("expect the delay of the HTTP date, got %v", d)
+	}
+	if _, ok := RetryAfter("soon"); ok {
+		t.Errorf("expect the invalid Retry-After ignored")
+	}
+}
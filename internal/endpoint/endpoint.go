@@ -2,8 +2,12 @@ package endpoint
 
 import (
 	"net/url"
+	"strings"
 )
 
+// UnixScheme is the scheme of the unix domain socket endpoints.
+const UnixScheme = "unix"
+
 // NewEndpoint new an Endpoint URL.
 func NewEndpoint(scheme, host string) *url.URL {
 	return &url.URL{Scheme: scheme, Host: host}
@@ -20,6 +24,9 @@ func ParseEndpoint(endpoints []string, scheme string) (string, error) {
 		if u.Scheme == scheme {
 			return u.Host, nil
 		}
+		if u.Scheme == UnixScheme && u.Query().Get("protocol") == scheme {
+			return UnixAddress(u), nil
+		}
 	}
 	return "", nil
 }
@@ -32,3 +39,41 @@ func Scheme(scheme string, isSecure bool) string {
 	}
 	return scheme
 }
+
+// NewUnixEndpoint new a unix domain socket Endpoint URL, the protocol scheme
+// is kept in the query, e.g. unix:///tmp/app.sock?protocol=grpc.
+// The abstract socket name starts with '@', e.g. unix:@app?protocol=grpc.
+func NewUnixEndpoint(scheme, name string) *url.URL {
+	u := &url.URL{Scheme: UnixScheme, RawQuery: url.Values{"protocol": {scheme}}.Encode()}
+	if strings.HasPrefix(name, "@") {
+		u.Opaque = name
+	} else {
+		u.Path = name
+	}
+	return u
+}
+
+// UnixAddress returns the dial address of the unix domain socket Endpoint URL,
+// e.g. unix:///tmp/app.sock or unix:@app.
+func UnixAddress(u *url.URL) string {
+	if u.Opaque != "" {
+		return UnixScheme + ":" + u.Opaque
+	}
+	return UnixScheme + "://" + u.Path
+}
+
+// ParseUnixAddress returns the socket name of the unix domain socket address,
+// which ok is false if the address is not a unix address.
+func ParseUnixAddress(addr string) (name string, ok bool) {
+	if !strings.HasPrefix(addr, UnixScheme+":") {
+		return "", false
+	}
+	name = strings.TrimPrefix(addr, UnixScheme+":")
+	if strings.HasPrefix(name, "//") {
+		name = strings.TrimPrefix(name, "//")
+	}
+	if i := strings.IndexByte(name, '?'); i >= 0 {
+		name = name[:i]
+	}
+	return name, name != ""
+}
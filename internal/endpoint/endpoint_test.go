@@ -124,3 +124,38 @@ func TestSchema(t *testing.T) {
 		}
 	}
 }
+
+func TestUnixEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		address  string
+	}{
+		{name: "/tmp/app.sock", endpoint: "unix:///tmp/app.sock?protocol=grpc", address: "unix:///tmp/app.sock"},
+		{name: "@app", endpoint: "unix:@app?protocol=grpc", address: "unix:@app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := NewUnixEndpoint("grpc", tt.name)
+			if u.String() != tt.endpoint {
+				t.Errorf("NewUnixEndpoint() = %v, want %v", u.String(), tt.endpoint)
+			}
+			got, err := ParseEndpoint([]string{"http://127.0.0.1:8000", u.String()}, "grpc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.address {
+				t.Errorf("ParseEndpoint() = %v, want %v", got, tt.address)
+			}
+			if got, _ := ParseEndpoint([]string{u.String()}, "http"); got != "" {
+				t.Errorf("ParseEndpoint() = %v, want empty", got)
+			}
+			if name, ok := ParseUnixAddress(got); !ok || name != tt.name {
+				t.Errorf("ParseUnixAddress() = %v, want %v", name, tt.name)
+			}
+		})
+	}
+	if _, ok := ParseUnixAddress("127.0.0.1:8000"); ok {
+		t.Errorf("ParseUnixAddress() = %v, want false", ok)
+	}
+}
@@ -90,3 +90,15 @@ func Extract(hostPort string, lis net.Listener) (string, error) {
 	}
 	return "", nil
 }
+
+// ParseCIDR parses the CIDR, or the IP as the CIDR of the single address.
+func ParseCIDR(cidr string) (*net.IPNet, error) {
+	if ip := net.ParseIP(cidr); ip != nil {
+		if v4 := ip.To4(); v4 != nil {
+			return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}, nil //nolint:gomnd
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil //nolint:gomnd
+	}
+	_, n, err := net.ParseCIDR(cidr)
+	return n, err
+}
@@ -147,3 +147,15 @@ func TestIpIsUp(t *testing.T) {
 		println(interfaces[i].Name, interfaces[i].Flags&net.FlagUp)
 	}
 }
+
+func TestParseCIDR(t *testing.T) {
+	for cidr, want := range map[string]string{"10.0.0.0/8": "10.0.0.0/8", "10.1.2.3": "10.1.2.3/32", "::1": "::1/128"} {
+		n, err := ParseCIDR(cidr)
+		if err != nil || n.String() != want {
+			t.Errorf("%s: expected %s got %v %v", cidr, want, n, err)
+		}
+	}
+	if _, err := ParseCIDR("10.0.0.0/33"); err == nil {
+		t.Error("expected an error")
+	}
+}
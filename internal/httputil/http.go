@@ -1,6 +1,7 @@
 package httputil
 
 import (
+	"strconv"
 	"strings"
 )
 
@@ -32,3 +33,12 @@ func ContentSubtype(contentType string) string {
 	}
 	return contentType[left+1 : right]
 }
+
+// ProtocolVersion returns the HTTP version of the protocol, e.g. "1.1", "2" or
+// "3", of the network.protocol.version attributes.
+func ProtocolVersion(major, minor int) string {
+	if major >= 2 {
+		return strconv.Itoa(major)
+	}
+	return strconv.Itoa(major) + "." + strconv.Itoa(minor)
+}
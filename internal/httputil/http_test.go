@@ -45,3 +45,20 @@ func TestContentType(t *testing.T) {
 		})
 	}
 }
+
+func TestProtocolVersion(t *testing.T) {
+	tests := []struct {
+		major, minor int
+		want         string
+	}{
+		{1, 0, "1.0"},
+		{1, 1, "1.1"},
+		{2, 0, "2"},
+		{3, 0, "3"},
+	}
+	for _, test := range tests {
+		if got := ProtocolVersion(test.major, test.minor); got != test.want {
+			t.Errorf("expected %q got %q", test.want, got)
+		}
+	}
+}
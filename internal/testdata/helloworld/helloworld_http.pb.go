@@ -24,7 +24,11 @@ type GreeterHTTPServer interface {
 }
 
 func RegisterGreeterHTTPServer(s *http.Server, srv GreeterHTTPServer) {
-	r := s.Route("/")
+	RegisterGreeterHTTPServerTo(s.Route("/"), srv)
+}
+
+// RegisterGreeterHTTPServerTo registers the routes of the service to the router, e.g. of a group of a prefix and filters.
+func RegisterGreeterHTTPServerTo(r *http.Router, srv GreeterHTTPServer) {
 	r.GET("/helloworld/{name}", _Greeter_SayHello0_HTTP_Handler(srv))
 }
 
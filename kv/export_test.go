@@ -0,0 +1,8 @@
+package kv
+
+import "time"
+
+// SetNow sets the clock of the memory store.
+func SetNow(m *Memory, now func() time.Time) {
+	m.now = now
+}
@@ -0,0 +1,29 @@
+// Package kv defines the key value Store shared by the subsystems which keep
+// their state out of process, e.g. the idempotency keys, so that a backend
+// like redis or memcached is implemented once for all of them.
+package kv
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Get if the key does not exist or expired.
+var ErrNotFound = errors.New("kv: key not found")
+
+// Store is a key value store, a zero ttl means the key never expires.
+type Store interface {
+	// Get returns the value of the key, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set sets the value of the key for the ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX sets the value of the key for the ttl if the key does not exist,
+	// and reports whether it is set.
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// Delete removes the key.
+	Delete(ctx context.Context, key string) error
+	// CompareAndDelete removes the key if its value equals to the old value,
+	// and reports whether it is removed.
+	CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error)
+}
@@ -0,0 +1,51 @@
+// Package kvtest provides the conformance tests of the kv.Store implementations.
+package kvtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/kv"
+)
+
+// Run runs the conformance tests of the store, the expire func makes the keys
+// set with a ttl of a second expire, e.g. by fast forwarding the server clock.
+func Run(t *testing.T, s kv.Store, expire func()) {
+	ctx := context.Background()
+	if _, err := s.Get(ctx, "kvtest:missing"); !errors.Is(err, kv.ErrNotFound) {
+		t.Errorf("expected %v got %v", kv.ErrNotFound, err)
+	}
+	if err := s.Set(ctx, "kvtest:k", []byte("v1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := s.Get(ctx, "kvtest:k"); err != nil || string(v) != "v1" {
+		t.Errorf("expected v1 got %q %v", v, err)
+	}
+	if ok, err := s.SetNX(ctx, "kvtest:k", []byte("v2"), 0); err != nil || ok {
+		t.Errorf("expected the existing key not set got %v %v", ok, err)
+	}
+	if ok, err := s.CompareAndDelete(ctx, "kvtest:k", []byte("v2")); err != nil || ok {
+		t.Errorf("expected the key of another value not deleted got %v %v", ok, err)
+	}
+	if ok, err := s.CompareAndDelete(ctx, "kvtest:k", []byte("v1")); err != nil || !ok {
+		t.Errorf("expected the key deleted got %v %v", ok, err)
+	}
+	if ok, err := s.SetNX(ctx, "kvtest:k", []byte("v3"), time.Second); err != nil || !ok {
+		t.Errorf("expected the key set got %v %v", ok, err)
+	}
+	if err := s.Delete(ctx, "kvtest:k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "kvtest:k"); !errors.Is(err, kv.ErrNotFound) {
+		t.Errorf("expected the key deleted got %v", err)
+	}
+	if err := s.Set(ctx, "kvtest:ttl", []byte("v"), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	expire()
+	if _, err := s.Get(ctx, "kvtest:ttl"); !errors.Is(err, kv.ErrNotFound) {
+		t.Errorf("expected the key expired got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	value   []byte
+	expires time.Time
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// Memory is a Store in the process memory, the expired keys are swept at
+// most once a minute when a key is set.
+type Memory struct {
+	mu      sync.Mutex
+	entries map[string]entry
+	swept   time.Time
+	now     func() time.Time
+}
+
+var _ Store = (*Memory)(nil)
+
+// NewMemory creates a memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		entries: make(map[string]entry),
+		now:     time.Now,
+	}
+}
+
+// Get returns the value of the key.
+func (m *Memory) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok || e.expired(m.now()) {
+		return nil, ErrNotFound
+	}
+	return e.value, nil
+}
+
+// Set sets the value of the key.
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.set(key, value, ttl)
+	return nil
+}
+
+// SetNX sets the value of the key if it does not exist.
+func (m *Memory) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok && !e.expired(m.now()) {
+		return false, nil
+	}
+	m.set(key, value, ttl)
+	return true, nil
+}
+
+// Delete removes the key.
+func (m *Memory) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// CompareAndDelete removes the key if its value equals to the old value.
+func (m *Memory) CompareAndDelete(_ context.Context, key string, old []byte) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok || e.expired(m.now()) || !bytes.Equal(e.value, old) {
+		return false, nil
+	}
+	delete(m.entries, key)
+	return true, nil
+}
+
+func (m *Memory) set(key string, value []byte, ttl time.Duration) {
+	now := m.now()
+	if now.Sub(m.swept) >= time.Minute {
+		m.swept = now
+		for k, e := range m.entries {
+			if e.expired(now) {
+				delete(m.entries, k)
+			}
+		}
+	}
+	e := entry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		e.expires = now.Add(ttl)
+	}
+	m.entries[key] = e
+}
@@ -0,0 +1,16 @@
+package kv_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/kv"
+	"github.com/go-kratos/kratos/v2/kv/kvtest"
+)
+
+func TestMemory(t *testing.T) {
+	now := time.Now()
+	m := kv.NewMemory()
+	kv.SetNow(m, func() time.Time { return now })
+	kvtest.Run(t, m, func() { now = now.Add(2 * time.Second) })
+}
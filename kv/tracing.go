@@ -0,0 +1,95 @@
+package kv
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceOption is tracing store option.
+type TraceOption func(*tracing)
+
+// WithTracerProvider with the tracer provider, default is the global provider.
+func WithTracerProvider(provider trace.TracerProvider) TraceOption {
+	return func(t *tracing) {
+		t.provider = provider
+	}
+}
+
+// WithSystem with the db.system attribute of the spans, e.g. redis or memcached.
+func WithSystem(system string) TraceOption {
+	return func(t *tracing) {
+		t.system = system
+	}
+}
+
+type tracing struct {
+	store    Store
+	provider trace.TracerProvider
+	tracer   trace.Tracer
+	system   string
+}
+
+// Trace wraps the store so that each operation is traced by a client span
+// named "kv.<operation>", ErrNotFound is not recorded as an error.
+func Trace(s Store, opts ...TraceOption) Store {
+	t := &tracing{store: s}
+	for _, o := range opts {
+		o(t)
+	}
+	if t.provider == nil {
+		t.provider = otel.GetTracerProvider()
+	}
+	t.tracer = t.provider.Tracer("kratos/kv")
+	return t
+}
+
+func (t *tracing) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{attribute.String("db.operation", operation)}
+	if t.system != "" {
+		attrs = append(attrs, attribute.String("db.system", t.system))
+	}
+	return t.tracer.Start(ctx, "kv."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+func end(span trace.Span, err error) {
+	if err != nil && err != ErrNotFound {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t *tracing) Get(ctx context.Context, key string) (value []byte, err error) {
+	ctx, span := t.start(ctx, "get")
+	defer func() { end(span, err) }()
+	return t.store.Get(ctx, key)
+}
+
+func (t *tracing) Set(ctx context.Context, key string, value []byte, ttl time.Duration) (err error) {
+	ctx, span := t.start(ctx, "set")
+	defer func() { end(span, err) }()
+	return t.store.Set(ctx, key, value, ttl)
+}
+
+func (t *tracing) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (ok bool, err error) {
+	ctx, span := t.start(ctx, "setnx")
+	defer func() { end(span, err) }()
+	return t.store.SetNX(ctx, key, value, ttl)
+}
+
+func (t *tracing) Delete(ctx context.Context, key string) (err error) {
+	ctx, span := t.start(ctx, "delete")
+	defer func() { end(span, err) }()
+	return t.store.Delete(ctx, key)
+}
+
+func (t *tracing) CompareAndDelete(ctx context.Context, key string, old []byte) (ok bool, err error) {
+	ctx, span := t.start(ctx, "compare_and_delete")
+	defer func() { end(span, err) }()
+	return t.store.CompareAndDelete(ctx, key, old)
+}
@@ -0,0 +1,25 @@
+package kv
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	s := Trace(NewMemory(), WithTracerProvider(tp), WithSystem("memory"))
+	ctx := context.Background()
+	_ = s.Set(ctx, "k", []byte("v"), 0)
+	_, _ = s.Get(ctx, "missing")
+	spans := exporter.GetSpans()
+	if len(spans) != 2 || spans[0].Name != "kv.set" || spans[1].Name != "kv.get" {
+		t.Fatalf("unexpected spans %v", spans)
+	}
+	if len(spans[1].Events) != 0 {
+		t.Errorf("expected not found not recorded got %v", spans[1].Events)
+	}
+}
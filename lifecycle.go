@@ -0,0 +1,241 @@
+package kratos
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Component is a lifecycle unit of the app, e.g. a server or a resource like a
+// database, which is started after the components it depends on and stopped
+// before them.
+type Component struct {
+	// Name is the unique name of the component.
+	Name string
+	// DependsOn are the names of the components it depends on.
+	DependsOn []string
+	// Server is started in the background, it is considered started once it runs.
+	Server transport.Server
+	// OnStart and OnStop are the hooks of a resource, the dependents are
+	// started once OnStart returns.
+	OnStart func(context.Context) error
+	OnStop  func(context.Context) error
+}
+
+// ServerError is the error of a server returned by Run, of which the server
+// failed to start, panicked, or reported by ReportError once it started.
+type ServerError struct {
+	// Name is the name of the component of the server, if any.
+	Name   string
+	Server transport.Server
+	Err    error
+}
+
+func (e *ServerError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("kratos: server %q: %v", e.Name, e.Err)
+	}
+	return fmt.Sprintf("kratos: server %T: %v", e.Server, e.Err)
+}
+
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+type serverKey struct{}
+
+// ReportError reports the error of a server once it started, e.g. of a
+// listener served in the background, by the context passed to its Start, so
+// that the app stops gracefully and Run returns the error instead of the
+// server panicking. It returns false if the context is not of an app.
+func ReportError(ctx context.Context, err error) bool {
+	app, ok := FromContext(ctx)
+	if !ok || err == nil {
+		return false
+	}
+	a, ok := app.(*App)
+	if !ok {
+		return false
+	}
+	serr := &ServerError{Err: err}
+	if c, ok := ctx.Value(serverKey{}).(Component); ok {
+		serr.Name, serr.Server = c.Name, c.Server
+	}
+	a.mu.Lock()
+	first := a.failure == nil
+	if first {
+		a.failure = serr
+	}
+	a.mu.Unlock()
+	if first {
+		log.Errorf("%v, stopping the app", serr)
+		go func() {
+			if e := a.Stop(); e != nil {
+				log.Errorf("failed to stop the app: %v", e)
+			}
+		}()
+	}
+	return true
+}
+
+// levels sorts the components topologically into levels, the components
+// of a level only depend on those of the previous levels and are started
+// concurrently. The servers of the Server option are the last level.
+func (a *App) levels() ([][]Component, error) {
+	comps := a.opts.components
+	index := make(map[string]int, len(comps))
+	for i, c := range comps {
+		if c.Name == "" {
+			return nil, fmt.Errorf("kratos: component %d has no name", i)
+		}
+		if _, ok := index[c.Name]; ok {
+			return nil, fmt.Errorf("kratos: duplicate component %q", c.Name)
+		}
+		index[c.Name] = i
+	}
+	indegree := make([]int, len(comps))
+	dependents := make([][]int, len(comps))
+	for i, c := range comps {
+		for _, dep := range c.DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return nil, fmt.Errorf("kratos: component %q depends on unknown component %q", c.Name, dep)
+			}
+			indegree[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+	var (
+		levels [][]Component
+		next   []int
+		sorted int
+	)
+	for i := range comps {
+		if indegree[i] == 0 {
+			next = append(next, i)
+		}
+	}
+	for len(next) > 0 {
+		level := make([]Component, 0, len(next))
+		var ready []int
+		for _, i := range next {
+			level = append(level, comps[i])
+			for _, j := range dependents[i] {
+				if indegree[j]--; indegree[j] == 0 {
+					ready = append(ready, j)
+				}
+			}
+		}
+		sorted += len(next)
+		levels = append(levels, level)
+		sort.Ints(ready) // the declaration order
+		next = ready
+	}
+	if sorted != len(comps) {
+		var cycle []string
+		for i, c := range comps {
+			if indegree[i] > 0 {
+				cycle = append(cycle, c.Name)
+			}
+		}
+		return nil, fmt.Errorf("kratos: dependency cycle among components %s", strings.Join(cycle, ", "))
+	}
+	if len(a.opts.servers) > 0 {
+		level := make([]Component, 0, len(a.opts.servers))
+		for _, srv := range a.opts.servers {
+			level = append(level, Component{Server: srv})
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// servers returns the servers of the Server option and the components.
+func (a *App) servers() []transport.Server {
+	servers := make([]transport.Server, 0, len(a.opts.servers)+len(a.opts.components))
+	for _, c := range a.opts.components {
+		if c.Server != nil {
+			servers = append(servers, c.Server)
+		}
+	}
+	return append(servers, a.opts.servers...)
+}
+
+// startLevel starts the components of a level concurrently, the servers are
+// started in the background by eg, and waits for the OnStart hooks. It returns
+// the components started, whose OnStart hooks succeeded.
+func (a *App) startLevel(ctx context.Context, eg *errgroup.Group, level []Component) ([]Component, error) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		hooks   errgroup.Group
+		started = make([]Component, 0, len(level))
+	)
+	for _, c := range level {
+		c := c
+		if c.OnStart != nil {
+			hooks.Go(func() error {
+				if err := c.OnStart(ctx); err != nil {
+					return fmt.Errorf("kratos: start component %q: %w", c.Name, err)
+				}
+				mu.Lock()
+				started = append(started, c)
+				mu.Unlock()
+				return nil
+			})
+		} else {
+			mu.Lock()
+			started = append(started, c)
+			mu.Unlock()
+		}
+		if c.Server != nil {
+			wg.Add(1)
+			eg.Go(func() (err error) {
+				wg.Done() // here is to ensure server start has begun running before register, so defer is not needed
+				defer func() {
+					if r := recover(); r != nil {
+						err = &ServerError{Name: c.Name, Server: c.Server, Err: fmt.Errorf("panic: %v", r)}
+					}
+				}()
+				sctx := context.WithValue(NewContext(a.opts.ctx, a), serverKey{}, c)
+				if err = c.Server.Start(sctx); err != nil {
+					return &ServerError{Name: c.Name, Server: c.Server, Err: err}
+				}
+				return nil
+			})
+		}
+	}
+	wg.Wait()
+	err := hooks.Wait()
+	return started, err
+}
+
+// stopLevel stops the components of a level concurrently with the stop timeout.
+func (a *App) stopLevel(level []Component) error {
+	var eg errgroup.Group
+	for _, c := range level {
+		c := c
+		eg.Go(func() error {
+			ctx, cancel := context.WithTimeout(NewContext(a.opts.ctx, a), a.opts.stopTimeout)
+			defer cancel()
+			var err error
+			if c.Server != nil {
+				err = c.Server.Stop(ctx)
+			}
+			if c.OnStop != nil {
+				if e := c.OnStop(ctx); e != nil && err == nil {
+					err = fmt.Errorf("kratos: stop component %q: %w", c.Name, e)
+				}
+			}
+			return err
+		})
+	}
+	return eg.Wait()
+}
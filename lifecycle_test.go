@@ -0,0 +1,166 @@
+package kratos
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+}
+
+func (r *recorder) hook(event string, err error) func(context.Context) error {
+	return func(context.Context) error {
+		r.add(event)
+		return err
+	}
+}
+
+type recordServer struct {
+	name string
+	r    *recorder
+	stop chan struct{}
+}
+
+func (s *recordServer) Start(context.Context) error {
+	s.r.add("start " + s.name)
+	<-s.stop
+	return nil
+}
+
+func (s *recordServer) Stop(context.Context) error {
+	s.r.add("stop " + s.name)
+	close(s.stop)
+	return nil
+}
+
+func TestComponents(t *testing.T) {
+	r := &recorder{}
+	app := New(
+		Server(&recordServer{name: "http", r: r, stop: make(chan struct{})}),
+		Components(
+			Component{Name: "db", DependsOn: []string{"config"}, OnStart: r.hook("start db", nil), OnStop: r.hook("stop db", nil)},
+			Component{Name: "config", OnStart: r.hook("start config", nil), OnStop: r.hook("stop config", nil)},
+		),
+	)
+	time.AfterFunc(100*time.Millisecond, func() { _ = app.Stop() })
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"start config", "start db", "start http", "stop http", "stop db", "stop config"}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("expected %v got %v", want, r.events)
+	}
+}
+
+func TestComponentsStartFailed(t *testing.T) {
+	r := &recorder{}
+	app := New(
+		Server(&recordServer{name: "http", r: r, stop: make(chan struct{})}),
+		Components(
+			Component{Name: "config", OnStart: r.hook("start config", nil), OnStop: r.hook("stop config", nil)},
+			Component{Name: "db", DependsOn: []string{"config"}, OnStart: r.hook("start db", errors.New("refused")), OnStop: r.hook("stop db", nil)},
+		),
+	)
+	if err := app.Run(); err == nil || !strings.Contains(err.Error(), "refused") {
+		t.Fatalf("expected start error got %v", err)
+	}
+	want := []string{"start config", "start db", "stop config"}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("expected %v got %v", want, r.events)
+	}
+}
+
+func TestComponentsInvalid(t *testing.T) {
+	tests := []struct {
+		comps []Component
+		err   string
+	}{
+		{[]Component{{Name: "a", DependsOn: []string{"b"}}, {Name: "b", DependsOn: []string{"a"}}}, "dependency cycle among components a, b"},
+		{[]Component{{Name: "a", DependsOn: []string{"c"}}}, `depends on unknown component "c"`},
+		{[]Component{{Name: "a"}, {Name: "a"}}, `duplicate component "a"`},
+		{[]Component{{}}, "has no name"},
+	}
+	for _, test := range tests {
+		app := New(Components(test.comps...))
+		if err := app.Run(); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("expected %s got %v", test.err, err)
+		}
+	}
+}
+
+type failServer struct {
+	stop    chan struct{}
+	start   func(ctx context.Context) error
+	stopped bool
+}
+
+func (s *failServer) Start(ctx context.Context) error {
+	if err := s.start(ctx); err != nil {
+		return err
+	}
+	<-s.stop
+	return nil
+}
+
+func (s *failServer) Stop(context.Context) error {
+	s.stopped = true
+	close(s.stop)
+	return nil
+}
+
+func TestServerError(t *testing.T) {
+	failed := errors.New("certificate expired")
+	tests := []struct {
+		name  string
+		start func(ctx context.Context) error
+		err   string
+	}{
+		{"start", func(context.Context) error { return failed }, `kratos: server "lis": certificate expired`},
+		{"panic", func(context.Context) error { panic("address in use") }, `kratos: server "lis": panic: address in use`},
+		{"report", func(ctx context.Context) error {
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				if !ReportError(ctx, failed) {
+					t.Error("expected the error reported")
+				}
+				// only the first error is returned.
+				ReportError(ctx, errors.New("closed"))
+			}()
+			return nil
+		}, `kratos: server "lis": certificate expired`},
+	}
+	for _, test := range tests {
+		srv := &failServer{stop: make(chan struct{}), start: test.start}
+		app := New(Components(Component{Name: "lis", Server: srv}))
+		err := app.Run()
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: expected %s got %v", test.name, test.err, err)
+		}
+		var serr *ServerError
+		if !errors.As(err, &serr) || serr.Server != srv {
+			t.Errorf("%s: expected the server error, got %v", test.name, err)
+		}
+		if test.name != "panic" && !errors.Is(err, failed) {
+			t.Errorf("%s: expected the error of the server, got %v", test.name, err)
+		}
+		if !srv.stopped && test.name == "report" {
+			t.Errorf("%s: expected the server stopped", test.name)
+		}
+	}
+	if ReportError(context.Background(), failed) {
+		t.Error("expected no app of the context")
+	}
+}
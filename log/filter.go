@@ -12,6 +12,14 @@ func FilterLevel(level Level) FilterOption {
 	}
 }
 
+// FilterLevelFunc with the func of the current filter level, e.g. of a watched
+// config key by config.NewVar, overriding FilterLevel.
+func FilterLevelFunc(f func() Level) FilterOption {
+	return func(opts *Filter) {
+		opts.levelFunc = f
+	}
+}
+
 // FilterKey with filter key.
 func FilterKey(key ...string) FilterOption {
 	return func(o *Filter) {
@@ -44,6 +52,8 @@ type Filter struct {
 	key    map[interface{}]struct{}
 	value  map[interface{}]struct{}
 	filter func(level Level, keyvals ...interface{}) bool
+
+	levelFunc func() Level
 }
 
 // NewFilter new a logger filter.
@@ -61,7 +71,11 @@ func NewFilter(logger Logger, opts ...FilterOption) *Filter {
 
 // Log Print log by level and keyvals.
 func (f *Filter) Log(level Level, keyvals ...interface{}) error {
-	if level < f.level {
+	if f.levelFunc != nil {
+		if level < f.levelFunc() {
+			return nil
+		}
+	} else if level < f.level {
 		return nil
 	}
 	// prefixkv contains the slice of arguments defined as prefixes during the log initialization
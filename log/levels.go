@@ -0,0 +1,219 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ModuleKey is the key of the module of the logs, e.g. log.With(logger, log.ModuleKey, "transport/http").
+const ModuleKey = "module"
+
+// defaultModule is the module of the default level of the specs.
+const defaultModule = "default"
+
+// Levels is a registry of the runtime levels of the modules, e.g. of
+// "transport/http=debug,default=info". A module without a level is of the
+// level of its closest parent, e.g. "transport/http/binding" of
+// "transport/http", or else of the default level.
+type Levels struct {
+	mu      sync.RWMutex
+	def     Level
+	modules map[string]Level
+}
+
+// NewLevels new a levels registry of the default level.
+func NewLevels(def Level) *Levels {
+	return &Levels{def: def, modules: make(map[string]Level)}
+}
+
+// Level returns the level of the module.
+func (l *Levels) Level(module string) Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for module != "" {
+		if level, ok := l.modules[module]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(module, '/')
+		if i < 0 {
+			break
+		}
+		module = module[:i]
+	}
+	return l.def
+}
+
+// SetLevel sets the level of the module, the empty module or "default" sets the default level.
+func (l *Levels) SetLevel(module string, level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if module == "" || module == defaultModule {
+		l.def = level
+		return
+	}
+	l.modules[module] = level
+}
+
+// Unset removes the level of the module, which is of its parent afterwards.
+func (l *Levels) Unset(module string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.modules, module)
+}
+
+// Set replaces the levels of the modules by the spec of the comma separated
+// module=level pairs, e.g. "transport/http=debug,default=info", so that the
+// levels can be bound to a watched config key. The default level is kept if
+// the spec has none.
+func (l *Levels) Set(spec string) error {
+	def, modules, err := parseLevels(spec)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if def != nil {
+		l.def = *def
+	}
+	l.modules = modules
+	return nil
+}
+
+// String returns the spec of the levels, sorted by the modules.
+func (l *Levels) String() string {
+	def, modules := l.snapshot()
+	names := make([]string, 0, len(modules))
+	for module := range modules {
+		names = append(names, module)
+	}
+	sort.Strings(names)
+	pairs := []string{defaultModule + "=" + strings.ToLower(def.String())}
+	for _, module := range names {
+		pairs = append(pairs, module+"="+strings.ToLower(modules[module].String()))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (l *Levels) snapshot() (Level, map[string]Level) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	modules := make(map[string]Level, len(l.modules))
+	for module, level := range l.modules {
+		modules[module] = level
+	}
+	return l.def, modules
+}
+
+// Logger returns a logger dropping the logs below the level of their module,
+// which is of the ModuleKey of the key values, e.g. of log.With, or else of
+// the module given, if any.
+func (l *Levels) Logger(logger Logger, module ...string) Logger {
+	m := ""
+	if len(module) > 0 {
+		m = module[0]
+	}
+	return &levelLogger{logger: logger, levels: l, module: m}
+}
+
+type levelLogger struct {
+	logger Logger
+	levels *Levels
+	module string
+}
+
+func (l *levelLogger) Log(level Level, keyvals ...interface{}) error {
+	module := l.module
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if keyvals[i] == ModuleKey {
+			module = fmt.Sprint(keyvals[i+1])
+			break
+		}
+	}
+	if level < l.levels.Level(module) {
+		return nil
+	}
+	return l.logger.Log(level, keyvals...)
+}
+
+// parseLevels parses the spec of the levels, def is nil if the spec has no default level.
+func parseLevels(spec string) (def *Level, modules map[string]Level, err error) {
+	modules = make(map[string]Level)
+	for _, pair := range strings.Split(spec, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		module, name, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, nil, fmt.Errorf("log: invalid level pair %q", pair)
+		}
+		level, ok := parseLevel(strings.TrimSpace(name))
+		if !ok {
+			return nil, nil, fmt.Errorf("log: invalid level %q of %s", name, module)
+		}
+		if module = strings.TrimSpace(module); module == "" || module == defaultModule {
+			def = &level
+			continue
+		}
+		modules[module] = level
+	}
+	return def, modules, nil
+}
+
+// parseLevel parses the level like ParseLevel, and reports whether it is valid.
+func parseLevel(s string) (Level, bool) {
+	level := ParseLevel(s)
+	return level, strings.EqualFold(level.String(), s)
+}
+
+// NewLevelsHandler new a handler of the levels, e.g. mounted by HandleAdmin
+// of the HTTP server:
+//
+//	GET                                      the levels as JSON
+//	PUT    ?module=transport/http&level=debug sets the level of the module
+//	PUT    ?spec=transport/http=debug          replaces the levels by the spec
+//	DELETE ?module=transport/http              removes the level of the module
+func NewLevelsHandler(l *Levels) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			if query.Has("spec") {
+				if err := l.Set(query.Get("spec")); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				break
+			}
+			level, ok := parseLevel(query.Get("level"))
+			if !ok {
+				http.Error(w, fmt.Sprintf("log: invalid level %q", query.Get("level")), http.StatusBadRequest)
+				return
+			}
+			l.SetLevel(query.Get("module"), level)
+		case http.MethodDelete:
+			l.Unset(query.Get("module"))
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		def, modules := l.snapshot()
+		names := make(map[string]string, len(modules))
+		for module, level := range modules {
+			names[module] = strings.ToLower(level.String())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(struct {
+			Default string            `json:"default"`
+			Modules map[string]string `json:"modules"`
+		}{
+			Default: strings.ToLower(def.String()),
+			Modules: names,
+		})
+	})
+}
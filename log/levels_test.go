@@ -0,0 +1,86 @@
+package log
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	l := NewLevels(LevelInfo)
+	if err := l.Set("transport/http=debug, transport=warn"); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]Level{
+		"transport/http":         LevelDebug,
+		"transport/http/binding": LevelDebug,
+		"transport/grpc":         LevelWarn,
+		"registry":               LevelInfo,
+		"":                       LevelInfo,
+	}
+	for module, want := range tests {
+		if got := l.Level(module); got != want {
+			t.Errorf("%s: expected %v got %v", module, want, got)
+		}
+	}
+	if got := l.String(); got != "default=info,transport=warn,transport/http=debug" {
+		t.Errorf("unexpected spec %s", got)
+	}
+
+	l.SetLevel("default", LevelError)
+	l.Unset("transport")
+	if l.Level("transport/grpc") != LevelError {
+		t.Errorf("expected the default level, got %v", l.Level("transport/grpc"))
+	}
+	for _, spec := range []string{"transport", "transport=verbose"} {
+		if err := l.Set(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
+
+func TestLevelsLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLevels(LevelInfo)
+	l.SetLevel("transport/http", LevelDebug)
+	logger := l.Logger(NewStdLogger(&buf))
+	h := NewHelper(With(logger, ModuleKey, "transport/http"))
+	h.Debug("http debug")
+	NewHelper(With(logger, ModuleKey, "registry")).Debug("registry debug")
+	NewHelper(l.Logger(NewStdLogger(&buf), "transport/http/binding")).Debug("binding debug")
+	got := buf.String()
+	if !strings.Contains(got, "http debug") || strings.Contains(got, "registry debug") || !strings.Contains(got, "binding debug") {
+		t.Errorf("unexpected logs %s", got)
+	}
+}
+
+func TestLevelsHandler(t *testing.T) {
+	l := NewLevels(LevelInfo)
+	h := NewLevelsHandler(l)
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, httptest.NewRequest(method, target, nil))
+		return res
+	}
+	res := serve(http.MethodPut, "/?module=transport/http&level=debug")
+	if res.Code != http.StatusOK || strings.TrimSpace(res.Body.String()) != `{"default":"info","modules":{"transport/http":"debug"}}` {
+		t.Errorf("unexpected response %d %s", res.Code, res.Body.String())
+	}
+	if l.Level("transport/http") != LevelDebug {
+		t.Error("expected the level set")
+	}
+	if res = serve(http.MethodPut, "/?module=transport/http&level=verbose"); res.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 got %d", res.Code)
+	}
+	if res = serve(http.MethodPut, "/?spec=default=warn,registry=error"); res.Code != http.StatusOK || l.String() != "default=warn,registry=error" {
+		t.Errorf("unexpected levels %d %s", res.Code, l.String())
+	}
+	if res = serve(http.MethodDelete, "/?module=registry"); res.Code != http.StatusOK || l.String() != "default=warn" {
+		t.Errorf("unexpected levels %d %s", res.Code, l.String())
+	}
+	if res = serve(http.MethodPatch, "/"); res.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 got %d", res.Code)
+	}
+}
@@ -0,0 +1,229 @@
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// levelFatal is the slog level of LevelFatal.
+const levelFatal = slog.LevelError + 4
+
+// SlogOption is slog adapter option.
+type SlogOption func(*slogOptions)
+
+type slogOptions struct {
+	skip      int
+	callerKey string
+	msgKey    string
+}
+
+// SlogCallerSkip with the number of the frames skipped to the caller of the
+// logger for the source of the slog records, default is 4 of a Helper over a
+// logger of With, as DefaultCaller.
+func SlogCallerSkip(skip int) SlogOption {
+	return func(o *slogOptions) {
+		o.skip = skip
+	}
+}
+
+// SlogCallerKey with the key of the caller of the slog records logged by the
+// handler, e.g. "caller" in place of DefaultCaller which would be of slog,
+// default is none.
+func SlogCallerKey(key string) SlogOption {
+	return func(o *slogOptions) {
+		o.callerKey = key
+	}
+}
+
+// SlogMessageKey with the key of the message, default is DefaultMessageKey.
+func SlogMessageKey(key string) SlogOption {
+	return func(o *slogOptions) {
+		o.msgKey = key
+	}
+}
+
+func newSlogOptions(opts []SlogOption) slogOptions {
+	o := slogOptions{skip: 4, msgKey: DefaultMessageKey}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// SlogLevel returns the slog level of the level.
+func SlogLevel(level Level) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return levelFatal
+	}
+}
+
+// FromSlogLevel returns the level of the slog level, the levels between are
+// rounded down, e.g. slog.LevelInfo+2 is LevelInfo.
+func FromSlogLevel(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	case level < levelFatal:
+		return LevelError
+	default:
+		return LevelFatal
+	}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+	opts   slogOptions
+}
+
+// NewSlogLogger new a logger writing the records into the slog logger, the
+// message is of the message key and the other key values are the attributes,
+// so that they are formatted once by the slog handler.
+func NewSlogLogger(logger *slog.Logger, opts ...SlogOption) Logger {
+	return &slogLogger{logger: logger, opts: newSlogOptions(opts)}
+}
+
+func (l *slogLogger) Log(level Level, keyvals ...interface{}) error {
+	ctx := context.Background()
+	lv := SlogLevel(level)
+	if !l.logger.Enabled(ctx, lv) {
+		return nil
+	}
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, "KEYVALS UNPAIRED")
+	}
+	var (
+		msg   string
+		attrs = make([]slog.Attr, 0, len(keyvals)/2)
+	)
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if key == l.opts.msgKey && msg == "" {
+			msg = fmt.Sprint(keyvals[i+1])
+			continue
+		}
+		attrs = append(attrs, slog.Any(key, keyvals[i+1]))
+	}
+	var pcs [1]uintptr
+	runtime.Callers(l.opts.skip, pcs[:])
+	r := slog.NewRecord(time.Now(), lv, msg, pcs[0])
+	r.AddAttrs(attrs...)
+	return l.logger.Handler().Handle(ctx, r)
+}
+
+type slogHandler struct {
+	logger Logger
+	opts   slogOptions
+	prefix []interface{}
+	group  string
+}
+
+// NewSlogHandler new an slog handler writing the records into the logger, the
+// attributes of the groups are of the keys joined by dots, e.g. "http.method".
+// The records are logged with their context, so that the valuers of the logger
+// are bound to it, e.g. the trace id.
+func NewSlogHandler(logger Logger, opts ...SlogOption) slog.Handler {
+	return &slogHandler{logger: logger, opts: newSlogOptions(opts)}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if f, ok := h.logger.(*Filter); ok {
+		min := f.level
+		if f.levelFunc != nil {
+			min = f.levelFunc()
+		}
+		return FromSlogLevel(level) >= min
+	}
+	return true
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	kvs := make([]interface{}, 0, len(h.prefix)+2*r.NumAttrs()+4)
+	if h.opts.callerKey != "" && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		kvs = append(kvs, h.opts.callerKey, shortFile(frame.File)+":"+strconv.Itoa(frame.Line))
+	}
+	kvs = append(kvs, h.prefix...)
+	kvs = append(kvs, h.opts.msgKey, r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		kvs = appendAttr(kvs, h.group, a)
+		return true
+	})
+	return WithContext(ctx, h.logger).Log(FromSlogLevel(r.Level), kvs...)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.prefix = append([]interface{}(nil), h.prefix...)
+	for _, a := range attrs {
+		c.prefix = appendAttr(c.prefix, h.group, a)
+	}
+	return &c
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.group = joinGroup(h.group, name)
+	return &c
+}
+
+// appendAttr appends the key values of the attribute, of which the groups are flattened.
+func appendAttr(kvs []interface{}, group string, a slog.Attr) []interface{} {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kvs
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		g := group
+		if a.Key != "" {
+			g = joinGroup(group, a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			kvs = appendAttr(kvs, g, ga)
+		}
+		return kvs
+	}
+	return append(kvs, joinGroup(group, a.Key), a.Value.Any())
+}
+
+func joinGroup(group, key string) string {
+	if group == "" {
+		return key
+	}
+	return group + "." + key
+}
+
+// shortFile returns the pkg/file of the file as Caller.
+func shortFile(file string) string {
+	n := 0
+	for i := len(file) - 1; i >= 0; i-- {
+		if file[i] == '/' {
+			if n++; n == 2 {
+				return file[i+1:]
+			}
+		}
+	}
+	return file
+}
@@ -0,0 +1,71 @@
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	sl := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{AddSource: true, Level: slog.LevelDebug}))
+	h := NewHelper(With(NewSlogLogger(sl), "service", "kratos"))
+	h.Infow("msg", "hello", "user", "alice")
+
+	var rec map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["msg"] != "hello" || rec["level"] != "INFO" || rec["service"] != "kratos" || rec["user"] != "alice" {
+		t.Errorf("unexpected record %v", rec)
+	}
+	source, _ := rec["source"].(map[string]interface{})
+	if file, _ := source["file"].(string); !strings.HasSuffix(file, "slog_test.go") {
+		t.Errorf("expected the source of the caller, got %v", source)
+	}
+
+	buf.Reset()
+	_ = NewSlogLogger(sl).Log(LevelFatal, "msg", "fatal")
+	if !strings.Contains(buf.String(), `"level":"ERROR+4"`) {
+		t.Errorf("unexpected fatal record %s", buf.String())
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	type ctxKey struct{}
+	var buf bytes.Buffer
+	logger := With(NewStdLogger(&buf), "trace", Valuer(func(ctx context.Context) interface{} {
+		return ctx.Value(ctxKey{})
+	}))
+	sl := slog.New(NewSlogHandler(logger, SlogCallerKey("caller")))
+	ctx := context.WithValue(context.Background(), ctxKey{}, "t1")
+	sl.With("a", 1).WithGroup("http").InfoContext(ctx, "hello", "method", "GET", slog.Group("req", "id", 7))
+
+	want := "INFO trace=t1 caller=log/slog_test.go:"
+	got := strings.TrimSpace(buf.String())
+	if !strings.HasPrefix(got, want) || !strings.HasSuffix(got, " a=1 msg=hello http.method=GET http.req.id=7") {
+		t.Errorf("unexpected line %s", got)
+	}
+
+	filtered := slog.New(NewSlogHandler(NewFilter(logger, FilterLevel(LevelWarn))))
+	if filtered.Enabled(ctx, slog.LevelInfo) || !filtered.Enabled(ctx, slog.LevelError) {
+		t.Error("expected the level of the filter")
+	}
+}
+
+func TestSlogLevel(t *testing.T) {
+	for _, level := range []Level{LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal} {
+		if got := FromSlogLevel(SlogLevel(level)); got != level {
+			t.Errorf("expected %v got %v", level, got)
+		}
+	}
+	if got := FromSlogLevel(slog.LevelInfo + 2); got != LevelInfo {
+		t.Errorf("expected info got %v", got)
+	}
+}
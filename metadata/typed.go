@@ -0,0 +1,89 @@
+package metadata
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BinarySuffix is the suffix of the keys of the binary values, which are
+// base64 encoded in the HTTP headers and sent as is in the gRPC metadata.
+const BinarySuffix = "-bin"
+
+// IsBinary reports whether the key is of a binary value.
+func IsBinary(key string) bool {
+	return strings.HasSuffix(strings.ToLower(key), BinarySuffix)
+}
+
+// GetInt returns the int value of the key, an error if it is missing or invalid.
+func (m Metadata) GetInt(key string) (int64, error) {
+	return strconv.ParseInt(m.Get(key), 10, 64)
+}
+
+// GetBool returns the bool value of the key, an error if it is missing or invalid.
+func (m Metadata) GetBool(key string) (bool, error) {
+	return strconv.ParseBool(m.Get(key))
+}
+
+// GetDuration returns the duration value of the key, e.g. "1.5s", an error
+// if it is missing or invalid.
+func (m Metadata) GetDuration(key string) (time.Duration, error) {
+	return time.ParseDuration(m.Get(key))
+}
+
+// GetTime returns the time value of the key in RFC 3339, or in the unix
+// seconds, an error if it is missing or invalid.
+func (m Metadata) GetTime(key string) (time.Time, error) {
+	v := m.Get(key)
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Parse(time.RFC3339Nano, v)
+}
+
+// GetBinary returns the binary value of the key, which is suffixed by
+// BinarySuffix if it is not.
+func (m Metadata) GetBinary(key string) []byte {
+	v, ok := m[binaryKey(key)]
+	if !ok || len(v) == 0 {
+		return nil
+	}
+	return []byte(v[0])
+}
+
+// SetInt stores the int value of the key.
+func (m Metadata) SetInt(key string, value int64) {
+	m.Set(key, strconv.FormatInt(value, 10))
+}
+
+// SetBool stores the bool value of the key.
+func (m Metadata) SetBool(key string, value bool) {
+	m.Set(key, strconv.FormatBool(value))
+}
+
+// SetDuration stores the duration value of the key.
+func (m Metadata) SetDuration(key string, value time.Duration) {
+	m.Set(key, value.String())
+}
+
+// SetTime stores the time value of the key in RFC 3339.
+func (m Metadata) SetTime(key string, value time.Time) {
+	m.Set(key, value.Format(time.RFC3339Nano))
+}
+
+// SetBinary stores the binary value of the key, which is suffixed by
+// BinarySuffix if it is not, so that it is binary safe across the transports.
+func (m Metadata) SetBinary(key string, value []byte) {
+	if key == "" || len(value) == 0 {
+		return
+	}
+	m[binaryKey(key)] = []string{string(value)}
+}
+
+func binaryKey(key string) string {
+	key = strings.ToLower(key)
+	if !strings.HasSuffix(key, BinarySuffix) {
+		key += BinarySuffix
+	}
+	return key
+}
@@ -0,0 +1,43 @@
+package metadata
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTypedAccessors(t *testing.T) {
+	md := New()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	md.SetInt("x-md-global-count", 42)
+	md.SetBool("x-md-global-debug", true)
+	md.SetDuration("x-md-global-budget", 1500*time.Millisecond)
+	md.SetTime("x-md-global-since", now)
+	md.SetBinary("x-md-global-token", []byte{0, 0xff})
+	md.Set("x-md-global-unix", "1700000000")
+
+	if v, err := md.GetInt("X-Md-Global-Count"); err != nil || v != 42 {
+		t.Errorf("unexpected int %d %v", v, err)
+	}
+	if v, err := md.GetBool("x-md-global-debug"); err != nil || !v {
+		t.Errorf("unexpected bool %v %v", v, err)
+	}
+	if v, err := md.GetDuration("x-md-global-budget"); err != nil || v != 1500*time.Millisecond {
+		t.Errorf("unexpected duration %v %v", v, err)
+	}
+	if v, err := md.GetTime("x-md-global-since"); err != nil || !v.Equal(now) {
+		t.Errorf("unexpected time %v %v", v, err)
+	}
+	if v, err := md.GetTime("x-md-global-unix"); err != nil || v.Unix() != 1700000000 {
+		t.Errorf("unexpected unix time %v %v", v, err)
+	}
+	if md.Values("x-md-global-token-bin") == nil || !bytes.Equal(md.GetBinary("x-md-global-token"), []byte{0, 0xff}) {
+		t.Errorf("unexpected binary %v", md)
+	}
+	if _, err := md.GetInt("x-md-global-missing"); err == nil {
+		t.Error("expected an error of the missing key")
+	}
+	if !IsBinary("X-Token-Bin") || IsBinary("x-token") {
+		t.Error("unexpected IsBinary")
+	}
+}
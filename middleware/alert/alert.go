@@ -0,0 +1,185 @@
+package alert
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Kind is the kind of an alert event.
+type Kind string
+
+const (
+	// KindPanic is the event of a recovered panic.
+	KindPanic Kind = "panic"
+	// KindErrorRate is the event of an error rate above the threshold.
+	KindErrorRate Kind = "error_rate"
+	// KindBreakerOpen is the event of the requests rejected by an open circuit breaker.
+	KindBreakerOpen Kind = "breaker_open"
+)
+
+// Event is an alert event.
+type Event struct {
+	Kind      Kind      `json:"kind"`
+	Operation string    `json:"operation"`
+	Message   string    `json:"message"`
+	Time      time.Time `json:"time"`
+	// Suppressed is the number of the events of the same kind and operation
+	// which are debounced since the last alert.
+	Suppressed int `json:"suppressed"`
+}
+
+// key returns the debounce key of the event.
+func (e Event) key() string {
+	return string(e.Kind) + ":" + e.Operation
+}
+
+// Alerter sends the alert events, e.g. to a log, webhook or pager.
+type Alerter interface {
+	Alert(ctx context.Context, e Event) error
+}
+
+// NotifierOption is notifier option.
+type NotifierOption func(*Notifier)
+
+// WithDebounce with the min interval between the alerts of the same kind and
+// operation, the events within the interval are counted as suppressed. Default is 5m.
+func WithDebounce(d time.Duration) NotifierOption {
+	return func(n *Notifier) {
+		n.debounce = d
+	}
+}
+
+// WithRateLimit with the max alerts sent within the interval, the others are
+// dropped. Default is 10 alerts a minute.
+func WithRateLimit(limit int, interval time.Duration) NotifierOption {
+	return func(n *Notifier) {
+		n.limit = limit
+		n.interval = interval
+	}
+}
+
+// WithTimeout with the timeout of sending an alert, default is 5s.
+func WithTimeout(d time.Duration) NotifierOption {
+	return func(n *Notifier) {
+		n.timeout = d
+	}
+}
+
+// Notifier debounces and rate limits the events, the alerts are sent
+// in background so that a slow alerter does not block the requests.
+type Notifier struct {
+	alerter  Alerter
+	debounce time.Duration
+	limit    int
+	interval time.Duration
+	timeout  time.Duration
+	now      func() time.Time
+
+	mu         sync.Mutex
+	last       map[string]time.Time
+	suppressed map[string]int
+	sent       []time.Time
+
+	events  chan Event
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewNotifier creates a notifier sending the alerts with the alerter.
+func NewNotifier(alerter Alerter, opts ...NotifierOption) *Notifier {
+	n := &Notifier{
+		alerter:    alerter,
+		debounce:   5 * time.Minute,
+		limit:      10,
+		interval:   time.Minute,
+		timeout:    5 * time.Second,
+		now:        time.Now,
+		last:       make(map[string]time.Time),
+		suppressed: make(map[string]int),
+		events:     make(chan Event, 64),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(n)
+	}
+	go n.run()
+	return n
+}
+
+// Notify sends the alert of the event unless it is debounced or rate limited.
+func (n *Notifier) Notify(e Event) {
+	if e.Time.IsZero() {
+		e.Time = n.now()
+	}
+	if !n.allow(&e) {
+		return
+	}
+	select {
+	case n.events <- e:
+	default:
+		log.Warnf("[Alert] dropped the %s alert of %s: the queue is full", e.Kind, e.Operation)
+	}
+}
+
+func (n *Notifier) allow(e *Event) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	key := e.key()
+	if last, ok := n.last[key]; ok && e.Time.Sub(last) < n.debounce {
+		n.suppressed[key]++
+		return false
+	}
+	start := 0
+	for start < len(n.sent) && e.Time.Sub(n.sent[start]) >= n.interval {
+		start++
+	}
+	n.sent = n.sent[start:]
+	if len(n.sent) >= n.limit {
+		n.suppressed[key]++
+		return false
+	}
+	n.sent = append(n.sent, e.Time)
+	n.last[key] = e.Time
+	e.Suppressed = n.suppressed[key]
+	delete(n.suppressed, key)
+	return true
+}
+
+func (n *Notifier) run() {
+	defer close(n.stopped)
+	for {
+		select {
+		case e := <-n.events:
+			n.send(e)
+		case <-n.done:
+			for {
+				select {
+				case e := <-n.events:
+					n.send(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (n *Notifier) send(e Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+	defer cancel()
+	if err := n.alerter.Alert(ctx, e); err != nil {
+		log.Errorf("[Alert] failed to send the %s alert of %s: %v", e.Kind, e.Operation, err)
+	}
+}
+
+// Close stops the notifier after the queued alerts are sent.
+func (n *Notifier) Close() error {
+	n.once.Do(func() { close(n.done) })
+	<-n.stopped
+	return nil
+}
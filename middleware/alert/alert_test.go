@@ -0,0 +1,97 @@
+package alert
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware/circuitbreaker"
+)
+
+type recordAlerter struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (a *recordAlerter) Alert(_ context.Context, e Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, e)
+	return nil
+}
+
+func TestNotifier(t *testing.T) {
+	now := time.Unix(1000, 0)
+	a := &recordAlerter{}
+	n := NewNotifier(a, WithDebounce(time.Minute), WithRateLimit(2, time.Hour))
+	n.now = func() time.Time { return now }
+
+	n.Notify(Event{Kind: KindPanic, Operation: "/a"})
+	n.Notify(Event{Kind: KindPanic, Operation: "/a"})
+	n.Notify(Event{Kind: KindPanic, Operation: "/a"})
+	n.Notify(Event{Kind: KindErrorRate, Operation: "/a"})
+	n.Notify(Event{Kind: KindPanic, Operation: "/b"})
+	now = now.Add(2 * time.Minute)
+	n.Notify(Event{Kind: KindPanic, Operation: "/a"})
+	_ = n.Close()
+
+	if len(a.events) != 2 {
+		t.Fatalf("expected 2 alerts got %v", a.events)
+	}
+	if e := a.events[0]; e.Kind != KindPanic || e.Operation != "/a" || e.Suppressed != 0 || !e.Time.Equal(time.Unix(1000, 0)) {
+		t.Errorf("unexpected alert %+v", e)
+	}
+	if e := a.events[1]; e.Kind != KindErrorRate {
+		t.Errorf("expected the other kind not debounced got %+v", e)
+	}
+
+	a.events = nil
+	n = NewNotifier(a, WithDebounce(time.Minute))
+	n.now = func() time.Time { return now }
+	n.Notify(Event{Kind: KindPanic, Operation: "/a"})
+	n.Notify(Event{Kind: KindPanic, Operation: "/a"})
+	now = now.Add(2 * time.Minute)
+	n.Notify(Event{Kind: KindPanic, Operation: "/a"})
+	_ = n.Close()
+	if len(a.events) != 2 || a.events[1].Suppressed != 1 {
+		t.Errorf("expected the suppressed events counted got %+v", a.events)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	a := &recordAlerter{}
+	n := NewNotifier(a, WithRateLimit(100, time.Minute))
+	m := Client(n, WithErrorRate(0.5, 4, time.Minute))
+
+	call := func(err error) {
+		_, _ = m(func(ctx context.Context, req interface{}) (interface{}, error) { return nil, err })(context.Background(), nil)
+	}
+	call(nil)
+	call(errors.BadRequest("", ""))
+	call(errors.ServiceUnavailable("", ""))
+	call(nil)
+	call(errors.ServiceUnavailable("", ""))
+	call(errors.ServiceUnavailable("", ""))
+	call(circuitbreaker.ErrNotAllowed)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected the panic to be re-panicked")
+			}
+		}()
+		_, _ = m(func(ctx context.Context, req interface{}) (interface{}, error) { panic("boom") })(context.Background(), nil)
+	}()
+	_ = n.Close()
+
+	if len(a.events) != 3 {
+		t.Fatalf("expected 3 alerts got %+v", a.events)
+	}
+	if e := a.events[0]; e.Kind != KindErrorRate || e.Message != "error rate 50% of 6 requests within 1m0s" {
+		t.Errorf("unexpected alert %+v", e)
+	}
+	if a.events[1].Kind != KindBreakerOpen || a.events[2].Kind != KindPanic || a.events[2].Message != "panic: boom" {
+		t.Errorf("unexpected alerts %+v", a.events[1:])
+	}
+}
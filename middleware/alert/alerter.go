@@ -0,0 +1,131 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const pagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+type logAlerter struct {
+	log *log.Helper
+}
+
+// NewLogAlerter returns an alerter which logs the events at the error level.
+func NewLogAlerter(logger log.Logger) Alerter {
+	return &logAlerter{log: log.NewHelper(logger)}
+}
+
+func (a *logAlerter) Alert(ctx context.Context, e Event) error {
+	a.log.WithContext(ctx).Errorw(
+		"alert", e.Kind,
+		"operation", e.Operation,
+		"message", e.Message,
+		"suppressed", e.Suppressed,
+	)
+	return nil
+}
+
+// HTTPOption is the option of the alerters sending HTTP requests.
+type HTTPOption func(*httpAlerter)
+
+// WithHTTPClient with the HTTP client, default is http.DefaultClient.
+func WithHTTPClient(c *http.Client) HTTPOption {
+	return func(a *httpAlerter) {
+		a.client = c
+	}
+}
+
+// WithHeader with a header of the requests, e.g. the authorization of a webhook.
+func WithHeader(key, value string) HTTPOption {
+	return func(a *httpAlerter) {
+		a.header.Set(key, value)
+	}
+}
+
+// WithEndpoint with the URL of the requests, e.g. the PagerDuty Events API of another region.
+func WithEndpoint(url string) HTTPOption {
+	return func(a *httpAlerter) {
+		a.url = url
+	}
+}
+
+type httpAlerter struct {
+	url    string
+	client *http.Client
+	header http.Header
+	body   func(Event) interface{}
+}
+
+func newHTTPAlerter(url string, body func(Event) interface{}, opts []HTTPOption) *httpAlerter {
+	a := &httpAlerter{
+		url:    url,
+		client: http.DefaultClient,
+		header: http.Header{"Content-Type": {"application/json"}},
+		body:   body,
+	}
+	for _, o := range opts {
+		o(a)
+	}
+	return a
+}
+
+func (a *httpAlerter) Alert(ctx context.Context, e Event) error {
+	data, err := json.Marshal(a.body(e))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header = a.header.Clone()
+	res, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("alert: unexpected status %d of %s", res.StatusCode, a.url)
+	}
+	return nil
+}
+
+// NewWebhook returns an alerter which posts the events as JSON to the URL.
+func NewWebhook(url string, opts ...HTTPOption) Alerter {
+	return newHTTPAlerter(url, func(e Event) interface{} { return e }, opts)
+}
+
+// NewPagerDuty returns an alerter which triggers the PagerDuty incidents of the
+// integration routing key with the Events API v2, the events of the same kind
+// and operation are deduplicated into an incident.
+func NewPagerDuty(routingKey string, opts ...HTTPOption) Alerter {
+	source, _ := os.Hostname()
+	return newHTTPAlerter(pagerDutyURL, func(e Event) interface{} {
+		severity := "error"
+		if e.Kind == KindPanic {
+			severity = "critical"
+		}
+		return map[string]interface{}{
+			"routing_key":  routingKey,
+			"event_action": "trigger",
+			"dedup_key":    e.key(),
+			"payload": map[string]interface{}{
+				"summary":   fmt.Sprintf("[%s] %s: %s", e.Kind, e.Operation, e.Message),
+				"source":    source,
+				"severity":  severity,
+				"timestamp": e.Time,
+				"custom_details": map[string]interface{}{
+					"operation":  e.Operation,
+					"suppressed": e.Suppressed,
+				},
+			},
+		}
+	}, opts)
+}
@@ -0,0 +1,46 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPAlerters(t *testing.T) {
+	var (
+		header http.Header
+		body   map[string]interface{}
+		status = http.StatusAccepted
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body = nil
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	e := Event{Kind: KindPanic, Operation: "/hello.v1/Say", Message: "panic: boom", Time: time.Unix(1000, 0)}
+
+	if err := NewWebhook(srv.URL, WithHeader("Authorization", "Bearer token")).Alert(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("Authorization") != "Bearer token" || body["kind"] != "panic" || body["operation"] != "/hello.v1/Say" {
+		t.Errorf("unexpected webhook request %v %v", header, body)
+	}
+
+	if err := NewPagerDuty("key", WithEndpoint(srv.URL)).Alert(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := body["payload"].(map[string]interface{})
+	if body["routing_key"] != "key" || body["dedup_key"] != "panic:/hello.v1/Say" || payload["severity"] != "critical" || payload["summary"] != "[panic] /hello.v1/Say: panic: boom" {
+		t.Errorf("unexpected pagerduty event %v", body)
+	}
+
+	status = http.StatusBadRequest
+	if err := NewWebhook(srv.URL).Alert(context.Background(), e); err == nil {
+		t.Error("expected status error")
+	}
+}
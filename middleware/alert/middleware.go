@@ -0,0 +1,130 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/circuitbreaker"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Option is alert middleware option.
+type Option func(*options)
+
+// WithErrorRate with the error rate threshold of an operation within the window,
+// which is checked once the window has the min requests. Default is 0.5 of at
+// least 20 requests within 1m.
+func WithErrorRate(threshold float64, minRequests int, window time.Duration) Option {
+	return func(o *options) {
+		o.threshold = threshold
+		o.minRequests = minRequests
+		o.window = window
+	}
+}
+
+type options struct {
+	threshold   float64
+	minRequests int
+	window      time.Duration
+}
+
+// Server is a server middleware which notifies the panics and the error rate
+// spikes of the operations. It re-panics after notifying, so it should be
+// placed after the recovery middleware.
+func Server(n *Notifier, opts ...Option) middleware.Middleware {
+	return newMiddleware(n, opts, func(ctx context.Context) string {
+		if tr, ok := transport.FromServerContext(ctx); ok {
+			return tr.Operation()
+		}
+		return ""
+	})
+}
+
+// Client is a client middleware which notifies the panics, the error rate spikes
+// and the requests rejected by the circuit breaker middleware, which should be
+// placed after it.
+func Client(n *Notifier, opts ...Option) middleware.Middleware {
+	return newMiddleware(n, opts, func(ctx context.Context) string {
+		if tr, ok := transport.FromClientContext(ctx); ok {
+			return tr.Operation()
+		}
+		return ""
+	})
+}
+
+func newMiddleware(n *Notifier, opts []Option, operation func(context.Context) string) middleware.Middleware {
+	o := &options{
+		threshold:   0.5,
+		minRequests: 20,
+		window:      time.Minute,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	rates := &errorRates{opts: o, windows: make(map[string]*window), now: time.Now}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			op := operation(ctx)
+			defer func() {
+				if rerr := recover(); rerr != nil {
+					n.Notify(Event{Kind: KindPanic, Operation: op, Message: fmt.Sprintf("panic: %v", rerr)})
+					panic(rerr)
+				}
+			}()
+			reply, err = handler(ctx, req)
+			if errors.Is(err, circuitbreaker.ErrNotAllowed) {
+				n.Notify(Event{Kind: KindBreakerOpen, Operation: op, Message: "requests are rejected by the open circuit breaker"})
+				return
+			}
+			if msg, ok := rates.observe(op, err); ok {
+				n.Notify(Event{Kind: KindErrorRate, Operation: op, Message: msg})
+			}
+			return
+		}
+	}
+}
+
+type window struct {
+	start    time.Time
+	requests int
+	errors   int
+}
+
+// errorRates counts the requests and errors of the operations in fixed windows,
+// the errors are the transient and throttled ones.
+type errorRates struct {
+	opts *options
+	now  func() time.Time
+
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+// observe counts the request and reports whether the error rate of the
+// operation is above the threshold.
+func (r *errorRates) observe(operation string, err error) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	w, ok := r.windows[operation]
+	if !ok || now.Sub(w.start) >= r.opts.window {
+		w = &window{start: now}
+		r.windows[operation] = w
+	}
+	w.requests++
+	if c := errors.Classify(err); c == errors.ClassTransient || c == errors.ClassThrottled {
+		w.errors++
+	}
+	if w.requests < r.opts.minRequests {
+		return "", false
+	}
+	rate := float64(w.errors) / float64(w.requests)
+	if rate < r.opts.threshold {
+		return "", false
+	}
+	return fmt.Sprintf("error rate %.0f%% of %d requests within %s", rate*100, w.requests, r.opts.window), true
+}
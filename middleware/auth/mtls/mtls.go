@@ -0,0 +1,195 @@
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type identityKey struct{}
+
+// reason holds the error reason.
+const reason string = "UNAUTHORIZED"
+
+var (
+	ErrMissingConnectionState = errors.Unauthorized(reason, "TLS connection state is missing")
+	ErrMissingCertificate     = errors.Unauthorized(reason, "client certificate is missing")
+	ErrUnverifiedCertificate  = errors.Unauthorized(reason, "client certificate is not verified")
+	ErrMissingSPIFFEID        = errors.Unauthorized(reason, "client certificate has no SPIFFE ID")
+	ErrInvalidSPIFFEID        = errors.Unauthorized(reason, "client SPIFFE ID is not allowed")
+)
+
+// Identity is the authenticated peer identity.
+type Identity struct {
+	// Certificate is the leaf certificate presented by the peer.
+	Certificate *x509.Certificate
+	// VerifiedChains are the chains verified by the TLS handshake.
+	VerifiedChains [][]*x509.Certificate
+	// SPIFFEID is the spiffe:// URI SAN of the certificate, if any.
+	SPIFFEID *url.URL
+}
+
+// Name returns the SPIFFE ID of the peer, or the subject common name
+// when the certificate has no SPIFFE ID.
+func (i *Identity) Name() string {
+	if i.SPIFFEID != nil {
+		return i.SPIFFEID.String()
+	}
+	return i.Certificate.Subject.CommonName
+}
+
+// Option is mtls option.
+type Option func(*options)
+
+type options struct {
+	trustDomains map[string]struct{}
+	spiffeIDs    map[string]struct{}
+	metadataKey  string
+	verify       func(context.Context, *Identity) error
+}
+
+// WithTrustDomains only accepts SPIFFE IDs from the given trust domains.
+func WithTrustDomains(domains ...string) Option {
+	return func(o *options) {
+		if o.trustDomains == nil {
+			o.trustDomains = make(map[string]struct{}, len(domains))
+		}
+		for _, d := range domains {
+			o.trustDomains[d] = struct{}{}
+		}
+	}
+}
+
+// WithSPIFFEIDs only accepts the given SPIFFE IDs, e.g. spiffe://example.org/ns/default/sa/api.
+func WithSPIFFEIDs(ids ...string) Option {
+	return func(o *options) {
+		if o.spiffeIDs == nil {
+			o.spiffeIDs = make(map[string]struct{}, len(ids))
+		}
+		for _, id := range ids {
+			o.spiffeIDs[id] = struct{}{}
+		}
+	}
+}
+
+// WithMetadataKey stores the peer identity name into the server metadata with the key,
+// e.g. x-md-local-peer-identity.
+func WithMetadataKey(key string) Option {
+	return func(o *options) {
+		o.metadataKey = key
+	}
+}
+
+// WithVerifyFunc with a custom verification of the peer identity.
+func WithVerifyFunc(f func(context.Context, *Identity) error) Option {
+	return func(o *options) {
+		o.verify = f
+	}
+}
+
+// Server is a server middleware that enforces verified client certificates and
+// extracts the peer identity from them. The server TLS config should use
+// tls.RequireAndVerifyClientCert so that the certificate chains are verified.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			state, ok := connectionState(ctx)
+			if !ok {
+				return nil, ErrMissingConnectionState
+			}
+			id, err := o.identity(state)
+			if err != nil {
+				return nil, err
+			}
+			if o.verify != nil {
+				if err := o.verify(ctx, id); err != nil {
+					return nil, err
+				}
+			}
+			if o.metadataKey != "" {
+				if md, ok := metadata.FromServerContext(ctx); ok {
+					md.Set(o.metadataKey, id.Name())
+				}
+			}
+			return handler(NewContext(ctx, id), req)
+		}
+	}
+}
+
+func (o *options) identity(state *tls.ConnectionState) (*Identity, error) {
+	if len(state.PeerCertificates) == 0 {
+		return nil, ErrMissingCertificate
+	}
+	if len(state.VerifiedChains) == 0 {
+		return nil, ErrUnverifiedCertificate
+	}
+	id := &Identity{
+		Certificate:    state.PeerCertificates[0],
+		VerifiedChains: state.VerifiedChains,
+	}
+	for _, u := range id.Certificate.URIs {
+		if u.Scheme == "spiffe" {
+			id.SPIFFEID = u
+			break
+		}
+	}
+	if o.trustDomains == nil && o.spiffeIDs == nil {
+		return id, nil
+	}
+	if id.SPIFFEID == nil {
+		return nil, ErrMissingSPIFFEID
+	}
+	if o.trustDomains != nil {
+		if _, ok := o.trustDomains[id.SPIFFEID.Host]; !ok {
+			return nil, ErrInvalidSPIFFEID
+		}
+	}
+	if o.spiffeIDs != nil {
+		if _, ok := o.spiffeIDs[id.SPIFFEID.String()]; !ok {
+			return nil, ErrInvalidSPIFFEID
+		}
+	}
+	return id, nil
+}
+
+// connectionState returns the TLS state of the HTTP request or the gRPC peer.
+func connectionState(ctx context.Context) (*tls.ConnectionState, bool) {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if ht, ok := tr.(http.Transporter); ok {
+			if state := ht.Request().TLS; state != nil {
+				return state, true
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			return &info.State, true
+		}
+	}
+	return nil, false
+}
+
+// NewContext put the peer identity into context.
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext extract the peer identity from context.
+func FromContext(ctx context.Context) (id *Identity, ok bool) {
+	id, ok = ctx.Value(identityKey{}).(*Identity)
+	return
+}
@@ -0,0 +1,116 @@
+package mtls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func newCertificate(t *testing.T, spiffeID string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if spiffeID != "" {
+		u, err := url.Parse(spiffeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl.URIs = []*url.URL{u}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func peerContext(cert *x509.Certificate, verified bool) context.Context {
+	state := tls.ConnectionState{}
+	if cert != nil {
+		state.PeerCertificates = []*x509.Certificate{cert}
+		if verified {
+			state.VerifiedChains = [][]*x509.Certificate{{cert}}
+		}
+	}
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+}
+
+func TestServer(t *testing.T) {
+	const spiffeID = "spiffe://example.org/ns/default/sa/api"
+	tests := []struct {
+		name string
+		ctx  context.Context
+		opts []Option
+		err  error
+		want string
+	}{
+		{"no tls", context.Background(), nil, ErrMissingConnectionState, ""},
+		{"no certificate", peerContext(nil, false), nil, ErrMissingCertificate, ""},
+		{"unverified", peerContext(newCertificate(t, spiffeID), false), nil, ErrUnverifiedCertificate, ""},
+		{"common name", peerContext(newCertificate(t, ""), true), nil, nil, "client"},
+		{"spiffe id", peerContext(newCertificate(t, spiffeID), true), nil, nil, spiffeID},
+		{"missing spiffe id", peerContext(newCertificate(t, ""), true), []Option{WithTrustDomains("example.org")}, ErrMissingSPIFFEID, ""},
+		{"trust domain", peerContext(newCertificate(t, spiffeID), true), []Option{WithTrustDomains("example.org")}, nil, spiffeID},
+		{"foreign trust domain", peerContext(newCertificate(t, spiffeID), true), []Option{WithTrustDomains("example.com")}, ErrInvalidSPIFFEID, ""},
+		{"allowed id", peerContext(newCertificate(t, spiffeID), true), []Option{WithSPIFFEIDs(spiffeID)}, nil, spiffeID},
+		{"denied id", peerContext(newCertificate(t, spiffeID), true), []Option{WithSPIFFEIDs("spiffe://example.org/other")}, ErrInvalidSPIFFEID, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var name string
+			next := func(ctx context.Context, req interface{}) (interface{}, error) {
+				id, ok := FromContext(ctx)
+				if !ok {
+					t.Fatal("expected identity in context")
+				}
+				name = id.Name()
+				return "reply", nil
+			}
+			_, err := Server(test.opts...)(next)(test.ctx, "req")
+			if err != test.err {
+				t.Fatalf("expected error %v got %v", test.err, err)
+			}
+			if name != test.want {
+				t.Errorf("expected identity %q got %q", test.want, name)
+			}
+		})
+	}
+}
+
+func TestVerifyFunc(t *testing.T) {
+	denied := errors.New("denied")
+	m := Server(WithVerifyFunc(func(_ context.Context, id *Identity) error {
+		if id.Certificate.Subject.CommonName != "admin" {
+			return denied
+		}
+		return nil
+	}))
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return "reply", nil }
+	if _, err := m(next)(peerContext(newCertificate(t, ""), true), "req"); !errors.Is(err, denied) {
+		t.Errorf("expected %v got %v", denied, err)
+	}
+}
@@ -0,0 +1,251 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// KeySetOption is key set option.
+type KeySetOption func(*KeySet)
+
+// WithRefreshInterval with the interval the keys are refreshed in background, default is 15m.
+func WithRefreshInterval(d time.Duration) KeySetOption {
+	return func(s *KeySet) {
+		s.interval = d
+	}
+}
+
+// WithMinRefreshInterval with the min interval between the refreshes triggered
+// by the unknown key ids, default is 1m.
+func WithMinRefreshInterval(d time.Duration) KeySetOption {
+	return func(s *KeySet) {
+		s.minInterval = d
+	}
+}
+
+// WithHTTPClient with the HTTP client fetching the keys, default is http.DefaultClient.
+func WithHTTPClient(c *http.Client) KeySetOption {
+	return func(s *KeySet) {
+		s.client = c
+	}
+}
+
+// KeySet caches the public keys of a JWKS endpoint, which are refreshed in
+// background and when a token is signed by an unknown key id.
+type KeySet struct {
+	url         string
+	client      *http.Client
+	interval    time.Duration
+	minInterval time.Duration
+	cancel      context.CancelFunc
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	refreshed time.Time
+	refresh   sync.Mutex
+}
+
+// NewKeySet creates a key set of the JWKS endpoint, the keys are fetched once
+// before it returns so that the misconfigured endpoints fail fast.
+func NewKeySet(ctx context.Context, url string, opts ...KeySetOption) (*KeySet, error) {
+	s := &KeySet{
+		url:         url,
+		client:      http.DefaultClient,
+		interval:    15 * time.Minute,
+		minInterval: time.Minute,
+		keys:        make(map[string]crypto.PublicKey),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	ctx, s.cancel = context.WithCancel(context.Background())
+	go s.run(ctx)
+	return s, nil
+}
+
+func (s *KeySet) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				log.Errorf("[OIDC] failed to refresh the keys of %s: %v", s.url, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Refresh fetches the keys of the JWKS endpoint.
+func (s *KeySet) Refresh(ctx context.Context) error {
+	s.refresh.Lock()
+	defer s.refresh.Unlock()
+	return s.fetch(ctx)
+}
+
+// refreshStale fetches the keys if they are not refreshed within the min interval.
+func (s *KeySet) refreshStale(ctx context.Context) error {
+	s.refresh.Lock()
+	defer s.refresh.Unlock()
+	s.mu.RLock()
+	fresh := time.Since(s.refreshed) < s.minInterval
+	s.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+	return s.fetch(ctx)
+}
+
+func (s *KeySet) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: unexpected JWKS status %d", res.StatusCode)
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&set); err != nil {
+		return err
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			log.Warnf("[OIDC] skip the key %q of %s: %v", k.Kid, s.url, err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	s.mu.Lock()
+	s.keys = keys
+	s.refreshed = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// Keyfunc returns the key of the token kid header, the keys are refreshed
+// if the kid is unknown and they are not refreshed within the min interval.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := s.key(kid); ok {
+		return key, nil
+	}
+	if err := s.refreshStale(context.Background()); err != nil {
+		return nil, err
+	}
+	if key, ok := s.key(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("oidc: unknown key id %q", kid)
+}
+
+func (s *KeySet) key(kid string) (crypto.PublicKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// Close stops refreshing the keys.
+func (s *KeySet) Close() error {
+	s.cancel()
+	return nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
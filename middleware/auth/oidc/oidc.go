@@ -0,0 +1,151 @@
+package oidc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	authjwt "github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const bearerWord = "Bearer"
+
+// ErrInsufficientScope is returned when the token does not have the required scopes.
+var ErrInsufficientScope = errors.Forbidden("FORBIDDEN", "token scope is insufficient")
+
+// defaultMethods are the asymmetric signing methods of the JWKS keys.
+var defaultMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Option is oidc option.
+type Option func(*options)
+
+type options struct {
+	issuer   string
+	audience string
+	leeway   time.Duration
+	methods  []string
+}
+
+// WithIssuer with the required iss claim of the tokens.
+func WithIssuer(iss string) Option {
+	return func(o *options) {
+		o.issuer = iss
+	}
+}
+
+// WithAudience with the required aud claim of the tokens.
+func WithAudience(aud string) Option {
+	return func(o *options) {
+		o.audience = aud
+	}
+}
+
+// WithLeeway with the leeway of the exp, nbf and iat claims validation.
+func WithLeeway(d time.Duration) Option {
+	return func(o *options) {
+		o.leeway = d
+	}
+}
+
+// WithValidMethods with the accepted signing methods, default are the RSA, ECDSA and EdDSA methods.
+func WithValidMethods(methods ...string) Option {
+	return func(o *options) {
+		o.methods = methods
+	}
+}
+
+// Server is a server auth middleware which validates the Bearer tokens with the keys
+// of the key set, the jwt.MapClaims are put into context by auth/jwt.NewContext.
+func Server(keys *KeySet, opts ...Option) middleware.Middleware {
+	o := &options{methods: defaultMethods}
+	for _, opt := range opts {
+		opt(o)
+	}
+	parserOpts := []jwt.ParserOption{jwt.WithValidMethods(o.methods), jwt.WithExpirationRequired()}
+	if o.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(o.issuer))
+	}
+	if o.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(o.audience))
+	}
+	if o.leeway > 0 {
+		parserOpts = append(parserOpts, jwt.WithLeeway(o.leeway))
+	}
+	parser := jwt.NewParser(parserOpts...)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return nil, authjwt.ErrWrongContext
+			}
+			auths := strings.SplitN(tr.RequestHeader().Get("Authorization"), " ", 2)
+			if len(auths) != 2 || !strings.EqualFold(auths[0], bearerWord) {
+				return nil, authjwt.ErrMissingJwtToken
+			}
+			claims := jwt.MapClaims{}
+			if _, err := parser.ParseWithClaims(auths[1], claims, keys.Keyfunc); err != nil {
+				switch {
+				case errors.Is(err, jwt.ErrTokenExpired), errors.Is(err, jwt.ErrTokenNotValidYet):
+					return nil, authjwt.ErrTokenExpired
+				case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+					return nil, authjwt.ErrUnSupportSigningMethod
+				default:
+					return nil, authjwt.ErrTokenInvalid
+				}
+			}
+			return handler(authjwt.NewContext(ctx, claims), req)
+		}
+	}
+}
+
+// RequireScopes is a middleware which requires the token in context to have all
+// the scopes of the scope or scp claim, e.g. per route with the selector middleware:
+//
+//	selector.Server(oidc.RequireScopes("orders:write")).Path("/api.order.v1.Order/Create").Build()
+func RequireScopes(scopes ...string) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			claims, ok := authjwt.FromContext(ctx)
+			if !ok {
+				return nil, authjwt.ErrMissingJwtToken
+			}
+			granted := Scopes(claims)
+			for _, scope := range scopes {
+				if _, ok := granted[scope]; !ok {
+					return nil, ErrInsufficientScope
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Scopes returns the scopes of the space separated scope claim, or the scp claim
+// which may be a list.
+func Scopes(claims jwt.Claims) map[string]struct{} {
+	scopes := make(map[string]struct{})
+	mc, ok := claims.(jwt.MapClaims)
+	if !ok {
+		return scopes
+	}
+	for _, name := range []string{"scope", "scp"} {
+		switch v := mc[name].(type) {
+		case string:
+			for _, s := range strings.Fields(v) {
+				scopes[s] = struct{}{}
+			}
+		case []interface{}:
+			for _, s := range v {
+				if s, ok := s.(string); ok {
+					scopes[s] = struct{}{}
+				}
+			}
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,216 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	authjwt "github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string      { return http.Header(hc).Get(key) }
+func (hc headerCarrier) Set(key, value string)      { http.Header(hc).Set(key, value) }
+func (hc headerCarrier) Add(key, value string)      { http.Header(hc).Add(key, value) }
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type testTransport struct{ header headerCarrier }
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.header }
+func (tr *testTransport) ReplyHeader() transport.Header   { return headerCarrier{} }
+
+type jwks struct {
+	mu      sync.Mutex
+	fetches int
+	keys    map[string]*rsa.PrivateKey
+}
+
+func (s *jwks) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	for kid, key := range s.keys {
+		set.Keys = append(set.Keys, jsonWebKey{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	_ = json.NewEncoder(w).Encode(set)
+}
+
+func (s *jwks) add(t *testing.T, kid string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	s.keys[kid] = key
+	s.mu.Unlock()
+	return key
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestServer(t *testing.T) {
+	set := &jwks{keys: make(map[string]*rsa.PrivateKey)}
+	key := set.add(t, "k1")
+	ts := httptest.NewServer(set)
+	defer ts.Close()
+
+	keys, err := NewKeySet(context.Background(), ts.URL, WithMinRefreshInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer keys.Close()
+
+	exp := time.Now().Add(time.Hour).Unix()
+	claims := func(m jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{"iss": "https://issuer", "aud": "kratos", "sub": "user", "exp": exp, "scope": "orders:read orders:write"}
+		for k, v := range m {
+			c[k] = v
+		}
+		return c
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		claims, _ := authjwt.FromContext(ctx)
+		return claims.(jwt.MapClaims)["sub"], nil
+	}
+	tests := []struct {
+		name  string
+		token func() string
+		err   error
+	}{
+		{name: "valid", token: func() string { return signToken(t, key, "k1", claims(nil)) }},
+		{name: "rotated", token: func() string { return signToken(t, set.add(t, "k2"), "k2", claims(nil)) }},
+		{name: "expired", token: func() string {
+			return signToken(t, key, "k1", claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}))
+		}, err: authjwt.ErrTokenExpired},
+		{name: "issuer", token: func() string { return signToken(t, key, "k1", claims(jwt.MapClaims{"iss": "https://other"})) }, err: authjwt.ErrTokenInvalid},
+		{name: "audience", token: func() string { return signToken(t, key, "k1", claims(jwt.MapClaims{"aud": "other"})) }, err: authjwt.ErrTokenInvalid},
+		{name: "unknown key", token: func() string { return signToken(t, key, "k3", claims(nil)) }, err: authjwt.ErrUnSupportSigningMethod},
+		{name: "hmac", token: func() string {
+			s, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims(nil)).SignedString([]byte("secret"))
+			return s
+		}, err: authjwt.ErrUnSupportSigningMethod},
+	}
+	server := Server(keys, WithIssuer("https://issuer"), WithAudience("kratos"))
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := headerCarrier{}
+			header.Set("Authorization", "Bearer "+test.token())
+			ctx := transport.NewServerContext(context.Background(), &testTransport{header: header})
+			reply, err := server(handler)(ctx, nil)
+			if err != test.err {
+				t.Fatalf("expected %v got %v", test.err, err)
+			}
+			if err == nil && reply != "user" {
+				t.Errorf("expected sub claim got %v", reply)
+			}
+		})
+	}
+
+	ctx := transport.NewServerContext(context.Background(), &testTransport{header: headerCarrier{}})
+	if _, err := server(handler)(ctx, nil); err != authjwt.ErrMissingJwtToken {
+		t.Errorf("expected %v got %v", authjwt.ErrMissingJwtToken, err)
+	}
+	if _, err := server(handler)(context.Background(), nil); err != authjwt.ErrWrongContext {
+		t.Errorf("expected %v got %v", authjwt.ErrWrongContext, err)
+	}
+}
+
+func TestKeySetRefreshLimit(t *testing.T) {
+	set := &jwks{keys: make(map[string]*rsa.PrivateKey)}
+	key := set.add(t, "k1")
+	ts := httptest.NewServer(set)
+	defer ts.Close()
+
+	keys, err := NewKeySet(context.Background(), ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer keys.Close()
+	token := signToken(t, key, "unknown", jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()})
+	for i := 0; i < 3; i++ {
+		if _, err := jwt.Parse(token, keys.Keyfunc); err == nil {
+			t.Fatal("expected unknown key error")
+		}
+	}
+	if set.fetches != 1 {
+		t.Errorf("expected the refreshes to be rate limited, got %d fetches", set.fetches)
+	}
+
+	if _, err := NewKeySet(context.Background(), ts.URL+"/missing", WithHTTPClient(&http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, nil
+	})})); err == nil {
+		t.Error("expected JWKS status error")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestRequireScopes(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	tests := []struct {
+		name   string
+		claims jwt.Claims
+		scopes []string
+		err    error
+	}{
+		{name: "scope", claims: jwt.MapClaims{"scope": "orders:read orders:write"}, scopes: []string{"orders:write"}},
+		{name: "scp", claims: jwt.MapClaims{"scp": []interface{}{"orders:read", "orders:write"}}, scopes: []string{"orders:read", "orders:write"}},
+		{name: "insufficient", claims: jwt.MapClaims{"scope": "orders:read"}, scopes: []string{"orders:write"}, err: ErrInsufficientScope},
+		{name: "registered", claims: &jwt.RegisteredClaims{}, scopes: []string{"orders:read"}, err: ErrInsufficientScope},
+		{name: "missing", scopes: []string{"orders:read"}, err: authjwt.ErrMissingJwtToken},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.claims != nil {
+				ctx = authjwt.NewContext(ctx, test.claims)
+			}
+			_, err := middleware.Chain(RequireScopes(test.scopes...))(handler)(ctx, nil)
+			if err != test.err {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+		})
+	}
+}
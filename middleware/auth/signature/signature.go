@@ -0,0 +1,213 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/encoding/json"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type keyIDKey struct{}
+
+const (
+	// KeyIDHeader holds the id of the signing key.
+	KeyIDHeader = "X-Signature-Key-Id"
+	// TimestampHeader holds the signing unix time in seconds.
+	TimestampHeader = "X-Signature-Timestamp"
+	// SignatureHeader holds the base64 encoded HMAC-SHA256 signature.
+	SignatureHeader = "X-Signature"
+
+	// reason holds the error reason.
+	reason string = "UNAUTHORIZED"
+)
+
+var (
+	ErrMissingSignature = errors.Unauthorized(reason, "request signature is missing")
+	ErrInvalidSignature = errors.Unauthorized(reason, "request signature is invalid")
+	ErrExpiredSignature = errors.Unauthorized(reason, "request signature timestamp is out of the clock skew")
+	ErrUnknownKey       = errors.Unauthorized(reason, "request signing key is unknown")
+	ErrMissingKeyFunc   = errors.Unauthorized(reason, "keyFunc is missing")
+	ErrWrongContext     = errors.Unauthorized(reason, "Wrong context for middleware")
+)
+
+// KeyFunc returns the secret of the signing key id.
+type KeyFunc func(ctx context.Context, keyID string) ([]byte, error)
+
+// Option is signature option.
+type Option func(*options)
+
+type options struct {
+	clockSkew time.Duration
+	now       func() time.Time
+}
+
+// WithClockSkew with the max difference between the signing time and the server time, default is 5m.
+func WithClockSkew(d time.Duration) Option {
+	return func(o *options) {
+		o.clockSkew = d
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		clockSkew: 5 * time.Minute,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Server is a server middleware which verifies the HMAC signature of the requests
+// over the method, path, timestamp and body hash, the key id is put into context.
+func Server(keyFunc KeyFunc, opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return nil, ErrWrongContext
+			}
+			if keyFunc == nil {
+				return nil, ErrMissingKeyFunc
+			}
+			header := tr.RequestHeader()
+			keyID, timestamp, signature := header.Get(KeyIDHeader), header.Get(TimestampHeader), header.Get(SignatureHeader)
+			if keyID == "" || timestamp == "" || signature == "" {
+				return nil, ErrMissingSignature
+			}
+			sec, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				return nil, ErrInvalidSignature
+			}
+			if skew := o.now().Sub(time.Unix(sec, 0)); skew > o.clockSkew || skew < -o.clockSkew {
+				return nil, ErrExpiredSignature
+			}
+			mac, err := base64.StdEncoding.DecodeString(signature)
+			if err != nil {
+				return nil, ErrInvalidSignature
+			}
+			key, err := keyFunc(ctx, keyID)
+			if err != nil || len(key) == 0 {
+				return nil, ErrUnknownKey
+			}
+			expected, err := sign(tr, req, key, timestamp)
+			if err != nil {
+				return nil, err
+			}
+			if !hmac.Equal(mac, expected) {
+				return nil, ErrInvalidSignature
+			}
+			return handler(NewContext(ctx, keyID), req)
+		}
+	}
+}
+
+// Client is a client middleware which signs the requests with the key.
+func Client(keyID string, key []byte, opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromClientContext(ctx)
+			if !ok {
+				return nil, ErrWrongContext
+			}
+			timestamp := strconv.FormatInt(o.now().Unix(), 10)
+			mac, err := sign(tr, req, key, timestamp)
+			if err != nil {
+				return nil, err
+			}
+			header := tr.RequestHeader()
+			header.Set(KeyIDHeader, keyID)
+			header.Set(TimestampHeader, timestamp)
+			header.Set(SignatureHeader, base64.StdEncoding.EncodeToString(mac))
+			return handler(ctx, req)
+		}
+	}
+}
+
+// sign computes the HMAC-SHA256 of the canonical request:
+//
+//	METHOD\nPATH\nTIMESTAMP\nHEX(SHA256(BODY))
+//
+// The method, path and body are of the HTTP request, or GRPC, the operation
+// and the deterministic proto encoding of the message for the other transports.
+func sign(tr transport.Transporter, req interface{}, key []byte, timestamp string) ([]byte, error) {
+	var (
+		method, path string
+		body         []byte
+		err          error
+	)
+	if ht, ok := tr.(http.Transporter); ok && ht.Request() != nil {
+		r := ht.Request()
+		method, path = r.Method, r.URL.RequestURI()
+		if body, err = requestBody(r); err != nil {
+			return nil, errors.BadRequest("CODEC", err.Error())
+		}
+	} else {
+		method, path = strings.ToUpper(tr.Kind().String()), tr.Operation()
+		if body, err = marshal(req); err != nil {
+			return nil, errors.BadRequest("CODEC", err.Error())
+		}
+	}
+	sum := sha256.Sum256(body)
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(method + "\n" + path + "\n" + timestamp + "\n" + hex.EncodeToString(sum[:])))
+	return h.Sum(nil), nil
+}
+
+// requestBody reads the body of the request and resets it to be read again.
+func requestBody(r *http.Request) ([]byte, error) {
+	if r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	if r.Body == nil {
+		return nil, nil
+	}
+	data, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	return data, err
+}
+
+func marshal(req interface{}) ([]byte, error) {
+	switch m := req.(type) {
+	case nil:
+		return nil, nil
+	case proto.Message:
+		return proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	default:
+		return encoding.GetCodec(json.Name).Marshal(m)
+	}
+}
+
+// NewContext put the signing key id into context.
+func NewContext(ctx context.Context, keyID string) context.Context {
+	return context.WithValue(ctx, keyIDKey{}, keyID)
+}
+
+// FromContext extract the signing key id from context.
+func FromContext(ctx context.Context) (keyID string, ok bool) {
+	keyID, ok = ctx.Value(keyIDKey{}).(string)
+	return
+}
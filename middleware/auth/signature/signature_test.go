@@ -0,0 +1,155 @@
+package signature
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type headerCarrier map[string]string
+
+func (hc headerCarrier) Get(key string) string { return hc[key] }
+func (hc headerCarrier) Set(key, value string) { hc[key] = value }
+func (hc headerCarrier) Add(key, value string) { hc[key] = value }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range hc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+func (hc headerCarrier) Values(key string) []string { return []string{hc[key]} }
+
+type testTransport struct {
+	operation string
+	header    headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return tr.operation }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.header }
+func (tr *testTransport) ReplyHeader() transport.Header   { return headerCarrier{} }
+
+func keyFunc(_ context.Context, keyID string) ([]byte, error) {
+	if keyID == "service-a" {
+		return []byte("secret"), nil
+	}
+	return nil, errors.New(404, "NOT_FOUND", "key not found")
+}
+
+func TestSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	header := headerCarrier{}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		keyID, _ := FromContext(ctx)
+		return keyID, nil
+	}
+	clientCtx := transport.NewClientContext(context.Background(), &testTransport{operation: "/hello.v1/Say", header: header})
+	if _, err := Client("service-a", []byte("secret"), func(o *options) { o.now = func() time.Time { return now } })(handler)(clientCtx, map[string]string{"name": "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+	if header[KeyIDHeader] != "service-a" || header[TimestampHeader] != "1700000000" || header[SignatureHeader] == "" {
+		t.Fatalf("unexpected header %v", header)
+	}
+
+	tests := []struct {
+		name      string
+		operation string
+		req       interface{}
+		header    func(headerCarrier)
+		skew      time.Duration
+		err       error
+	}{
+		{name: "valid", operation: "/hello.v1/Say", req: map[string]string{"name": "kratos"}},
+		{name: "skew", operation: "/hello.v1/Say", req: map[string]string{"name": "kratos"}, skew: time.Minute},
+		{name: "expired", operation: "/hello.v1/Say", req: map[string]string{"name": "kratos"}, skew: 10 * time.Minute, err: ErrExpiredSignature},
+		{name: "body", operation: "/hello.v1/Say", req: map[string]string{"name": "go"}, err: ErrInvalidSignature},
+		{name: "operation", operation: "/hello.v1/Delete", req: map[string]string{"name": "kratos"}, err: ErrInvalidSignature},
+		{name: "key", operation: "/hello.v1/Say", req: map[string]string{"name": "kratos"}, header: func(h headerCarrier) { h[KeyIDHeader] = "service-b" }, err: ErrUnknownKey},
+		{name: "missing", operation: "/hello.v1/Say", header: func(h headerCarrier) { delete(h, SignatureHeader) }, err: ErrMissingSignature},
+		{name: "malformed", operation: "/hello.v1/Say", header: func(h headerCarrier) { h[SignatureHeader] = "!" }, err: ErrInvalidSignature},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := headerCarrier{}
+			for k, v := range header {
+				h[k] = v
+			}
+			if test.header != nil {
+				test.header(h)
+			}
+			ctx := transport.NewServerContext(context.Background(), &testTransport{operation: test.operation, header: h})
+			server := Server(keyFunc, func(o *options) { o.now = func() time.Time { return now.Add(test.skew) } })
+			reply, err := server(handler)(ctx, test.req)
+			if err != test.err {
+				t.Fatalf("expected %v got %v", test.err, err)
+			}
+			if err == nil && reply != "service-a" {
+				t.Errorf("expected key id in context got %v", reply)
+			}
+		})
+	}
+
+	if _, err := Server(nil)(handler)(transport.NewServerContext(context.Background(), &testTransport{header: header}), nil); err != ErrMissingKeyFunc {
+		t.Errorf("expected %v got %v", ErrMissingKeyFunc, err)
+	}
+	if _, err := Server(keyFunc)(handler)(context.Background(), nil); err != ErrWrongContext {
+		t.Errorf("expected %v got %v", ErrWrongContext, err)
+	}
+	if _, err := Client("service-a", []byte("secret"))(handler)(context.Background(), nil); err != ErrWrongContext {
+		t.Errorf("expected %v got %v", ErrWrongContext, err)
+	}
+}
+
+func TestSignatureHTTP(t *testing.T) {
+	srv := http.NewServer(http.Middleware(Server(keyFunc)))
+	srv.Route("/").POST("/hello/{name}", func(ctx http.Context) error {
+		var in map[string]string
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			keyID, _ := FromContext(ctx)
+			return map[string]string{"key": keyID, "name": req.(map[string]string)["name"]}, nil
+		})
+		out, err := h(ctx, in)
+		if err != nil {
+			return err
+		}
+		return ctx.Result(200, out)
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	newClient := func(m ...middleware.Middleware) *http.Client {
+		client, err := http.NewClient(context.Background(), http.WithEndpoint(strings.TrimPrefix(ts.URL, "http://")), http.WithMiddleware(m...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	client := newClient(Client("service-a", []byte("secret")))
+	defer client.Close()
+	var reply map[string]string
+	if err := client.Invoke(context.Background(), "POST", "/hello/kratos?lang=go", map[string]string{"name": "kratos"}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply["key"] != "service-a" || reply["name"] != "kratos" {
+		t.Errorf("unexpected reply %v", reply)
+	}
+
+	client = newClient(Client("service-a", []byte("wrong")))
+	defer client.Close()
+	err := client.Invoke(context.Background(), "POST", "/hello/kratos", map[string]string{"name": "kratos"}, &reply)
+	if errors.Code(err) != 401 || errors.FromError(err).Message != ErrInvalidSignature.Message {
+		t.Errorf("expected %v got %v", ErrInvalidSignature, err)
+	}
+}
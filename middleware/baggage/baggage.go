@@ -0,0 +1,60 @@
+// Package baggage propagates the W3C baggage of the requests, i.e. the
+// "baggage" header, to the outgoing calls, e.g. the tenant or the budget of
+// the whole call chain, so that it is visible to every downstream service.
+package baggage
+
+import (
+	"context"
+	"net/url"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var propagator = propagation.Baggage{}
+
+// Server is a server middleware which extracts the baggage of the request header into context.
+func Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				ctx = propagator.Extract(ctx, tr.RequestHeader())
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Client is a client middleware which injects the baggage of context into the request header.
+func Client() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				propagator.Inject(ctx, tr.RequestHeader())
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Get returns the value of the baggage member of the key in context.
+func Get(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// Set returns a copy of context with the baggage member of the key and the
+// value, an error if they are invalid.
+func Set(ctx context.Context, key, value string) (context.Context, error) {
+	m, err := baggage.NewMember(key, url.QueryEscape(value))
+	if err != nil {
+		return ctx, err
+	}
+	b, err := baggage.FromContext(ctx).SetMember(m)
+	if err != nil {
+		return ctx, err
+	}
+	return baggage.ContextWithBaggage(ctx, b), nil
+}
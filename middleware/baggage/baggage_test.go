@@ -0,0 +1,73 @@
+package baggage
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+
+// Keys lists the keys stored in this carrier.
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns a slice value associated with the passed key.
+func (hc headerCarrier) Values(key string) []string {
+	return http.Header(hc).Values(key)
+}
+
+type testTransport struct{ header headerCarrier }
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.header }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.header }
+
+func TestPropagation(t *testing.T) {
+	in := &testTransport{header: headerCarrier{}}
+	in.header.Set("baggage", "tenant=acme,budget=250ms")
+	out := &testTransport{header: headerCarrier{}}
+
+	var tenant string
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		tenant = Get(ctx, "tenant")
+		ctx, err := Set(ctx, "user", "alice@example")
+		if err != nil {
+			return nil, err
+		}
+		return Client()(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		})(transport.NewClientContext(ctx, out), req)
+	}
+	if _, err := Server()(next)(transport.NewServerContext(context.Background(), in), nil); err != nil {
+		t.Fatal(err)
+	}
+	if tenant != "acme" {
+		t.Errorf("expected tenant acme got %q", tenant)
+	}
+	got := propagator.Extract(context.Background(), out.header)
+	if Get(got, "tenant") != "acme" || Get(got, "budget") != "250ms" || Get(got, "user") != "alice@example" {
+		t.Errorf("unexpected propagated baggage %q", out.header.Get("baggage"))
+	}
+}
+
+func TestSetInvalid(t *testing.T) {
+	if _, err := Set(context.Background(), "invalid key", "value"); err == nil {
+		t.Error("expected an error")
+	}
+}
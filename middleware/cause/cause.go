@@ -0,0 +1,39 @@
+// Package cause serializes the cause chains of the errors across the transports,
+// which are lost over the wire otherwise, so that errors.Is matches the sentinel
+// errors registered by errors.RegisterCause on the clients.
+package cause
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// Server is a server middleware serializing the cause chains of the returned
+// errors into their metadata, see errors.EncodeCause.
+func Server(opts ...errors.CauseOption) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			reply, err := handler(ctx, req)
+			if err != nil {
+				return reply, errors.EncodeCause(err, opts...)
+			}
+			return reply, nil
+		}
+	}
+}
+
+// Client is a client middleware reconstructing the cause chains of the returned
+// errors from their metadata, see errors.DecodeCause.
+func Client() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			reply, err := handler(ctx, req)
+			if err != nil {
+				return reply, errors.DecodeCause(err)
+			}
+			return reply, nil
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package cause
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+var errConflict = fmt.Errorf("version conflict")
+
+func TestCause(t *testing.T) {
+	errors.RegisterCause("cause.Conflict", errConflict)
+	server := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.Conflict("CONFLICT", "conflict").WithCause(errConflict)
+	})
+	// wire drops the cause but keeps the status as the transports do.
+	wire := func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, err := server(ctx, req)
+		se := errors.FromError(err)
+		return nil, errors.New(int(se.Code), se.Reason, se.Message).WithMetadata(se.Metadata)
+	}
+	_, err := Client()(wire)(context.Background(), nil)
+	if !errors.Is(err, errConflict) || !errors.IsConflict(err) {
+		t.Errorf("expected the cause reconstructed, got %v", err)
+	}
+}
@@ -15,6 +15,11 @@ import (
 // ErrNotAllowed is request failed due to circuit breaker triggered.
 var ErrNotAllowed = errors.New(503, "CIRCUITBREAKER", "request failed due to circuit breaker triggered")
 
+func init() {
+	// the rejected requests are not retried before backing off.
+	errors.RegisterReason(ErrNotAllowed.Reason, errors.ClassThrottled)
+}
+
 // Option is circuit breaker option.
 type Option func(*options)
 
@@ -63,7 +68,7 @@ func Client(opts ...Option) middleware.Middleware {
 			}
 			// allowed
 			reply, err := handler(ctx, req)
-			if err != nil && (errors.IsInternalServer(err) || errors.IsServiceUnavailable(err) || errors.IsGatewayTimeout(err)) {
+			if failed(err) {
 				breaker.MarkFailed()
 			} else {
 				breaker.MarkSuccess()
@@ -72,3 +77,10 @@ func Client(opts ...Option) middleware.Middleware {
 		}
 	}
 }
+
+// failed reports whether the error is a failure of the breaker,
+// which are the transient and throttled errors.
+func failed(err error) bool {
+	c := errors.Classify(err)
+	return c == errors.ClassTransient || c == errors.ClassThrottled
+}
@@ -0,0 +1,85 @@
+package circuitbreaker
+
+import (
+	"context"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+
+	"github.com/go-kratos/kratos/v2/internal/group"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+type rejectedKey struct{}
+
+// NodeBreaker tracks a circuit breaker per node address. Its node filter skips
+// the nodes with open breakers during load balancing, and its middleware marks
+// the result of every call on the breaker of the node which served it.
+type NodeBreaker struct {
+	group *group.Group
+}
+
+// NewNodeBreaker returns a node breaker, the breakers are created by
+// NewStateBreaker unless WithCircuitBreaker or WithGroup is given.
+func NewNodeBreaker(opts ...Option) *NodeBreaker {
+	opt := &options{
+		group: group.NewGroup(func() interface{} {
+			return NewStateBreaker()
+		}),
+	}
+	for _, o := range opts {
+		o(opt)
+	}
+	return &NodeBreaker{group: opt.group}
+}
+
+func (b *NodeBreaker) breaker(address string) circuitbreaker.CircuitBreaker {
+	return b.group.Get(address).(circuitbreaker.CircuitBreaker)
+}
+
+// Filter returns the node filter which skips the nodes with open breakers.
+func (b *NodeBreaker) Filter() selector.NodeFilter {
+	return func(ctx context.Context, nodes []selector.Node) []selector.Node {
+		filtered := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			var open bool
+			if sb, ok := b.breaker(n.Address()).(StateBreaker); ok {
+				open = sb.Open()
+			} else {
+				open = b.breaker(n.Address()).Allow() != nil
+			}
+			if !open {
+				filtered = append(filtered, n)
+			}
+		}
+		if len(filtered) == 0 && len(nodes) > 0 {
+			if rejected, ok := ctx.Value(rejectedKey{}).(*bool); ok {
+				*rejected = true
+			}
+		}
+		return filtered
+	}
+}
+
+// Middleware returns the client middleware which marks the call results on the
+// node breakers, it returns ErrNotAllowed when the breakers of all nodes are open.
+func (b *NodeBreaker) Middleware() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var rejected bool
+			reply, err := handler(context.WithValue(ctx, rejectedKey{}, &rejected), req)
+			if rejected {
+				return nil, ErrNotAllowed
+			}
+			if p, ok := selector.FromPeerContext(ctx); ok && p.Node != nil {
+				breaker := b.breaker(p.Node.Address())
+				if failed(err) {
+					breaker.MarkFailed()
+				} else {
+					breaker.MarkSuccess()
+				}
+			}
+			return reply, err
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func TestNodeBreaker(t *testing.T) {
+	b := NewNodeBreaker(WithCircuitBreaker(func() circuitbreaker.CircuitBreaker {
+		return NewStateBreaker(FailureThreshold(1))
+	}))
+	nodes := []selector.Node{
+		selector.NewNode("http", "127.0.0.1:8000", nil),
+		selector.NewNode("http", "127.0.0.1:8001", nil),
+	}
+	filter := b.Filter()
+	call := func(addr string, callErr error) error {
+		next := func(ctx context.Context, req interface{}) (interface{}, error) {
+			candidates := filter(ctx, nodes)
+			if len(candidates) == 0 {
+				return nil, kratoserrors.ServiceUnavailable("NODE_NOT_FOUND", "no available node")
+			}
+			p, _ := selector.FromPeerContext(ctx)
+			for _, n := range candidates {
+				if n.Address() == addr {
+					p.Node = n
+				}
+			}
+			return "reply", callErr
+		}
+		ctx := selector.NewPeerContext(context.Background(), &selector.Peer{})
+		_, err := b.Middleware()(next)(ctx, "req")
+		return err
+	}
+
+	if err := call("127.0.0.1:8000", kratoserrors.ServiceUnavailable("UNAVAILABLE", "")); err == nil {
+		t.Fatal("expected the call error")
+	}
+	if got := filter(context.Background(), nodes); len(got) != 1 || got[0].Address() != "127.0.0.1:8001" {
+		t.Fatalf("expected the broken node skipped, got %v", got)
+	}
+	if err := call("127.0.0.1:8001", kratoserrors.BadRequest("BAD", "")); err == nil {
+		t.Fatal("expected the call error")
+	}
+	if got := filter(context.Background(), nodes); len(got) != 1 {
+		t.Fatalf("expected client errors not to open the breaker, got %v", got)
+	}
+	_ = call("127.0.0.1:8001", kratoserrors.InternalServer("INTERNAL", ""))
+	if err := call("127.0.0.1:8001", nil); !errors.Is(err, ErrNotAllowed) {
+		t.Fatalf("expected %v got %v", ErrNotAllowed, err)
+	}
+}
@@ -0,0 +1,122 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+)
+
+// StateBreaker is a circuit breaker which can report whether it is open
+// without counting a request, which the node filter of NodeBreaker relies on.
+type StateBreaker interface {
+	circuitbreaker.CircuitBreaker
+	// Open reports whether the breaker rejects requests.
+	Open() bool
+}
+
+// BreakerOption is state breaker option.
+type BreakerOption func(*stateBreaker)
+
+// FailureThreshold with the consecutive failures which open the breaker, default is 5.
+func FailureThreshold(n int) BreakerOption {
+	return func(b *stateBreaker) {
+		b.failureThreshold = n
+	}
+}
+
+// SuccessThreshold with the consecutive successful probes which close
+// a half-open breaker, default is 1.
+func SuccessThreshold(n int) BreakerOption {
+	return func(b *stateBreaker) {
+		b.successThreshold = n
+	}
+}
+
+// OpenTimeout with the duration the breaker stays open before it turns
+// half-open and lets probe requests through, default is 10s.
+func OpenTimeout(d time.Duration) BreakerOption {
+	return func(b *stateBreaker) {
+		b.openTimeout = d
+	}
+}
+
+const (
+	stateClosed = iota
+	stateOpen
+	stateHalfOpen
+)
+
+type stateBreaker struct {
+	mu               sync.Mutex
+	state            int
+	failures         int
+	successes        int
+	openedAt         time.Time
+	failureThreshold int
+	successThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+}
+
+// NewStateBreaker returns a closed, open and half-open state circuit breaker.
+func NewStateBreaker(opts ...BreakerOption) StateBreaker {
+	b := &stateBreaker{
+		failureThreshold: 5,
+		successThreshold: 1,
+		openTimeout:      10 * time.Second,
+		now:              time.Now,
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Allow returns circuitbreaker.ErrNotAllowed when the breaker is open.
+func (b *stateBreaker) Allow() error {
+	if b.Open() {
+		return circuitbreaker.ErrNotAllowed
+	}
+	return nil
+}
+
+func (b *stateBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == stateOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		b.state = stateHalfOpen
+		b.successes = 0
+	}
+	return b.state == stateOpen
+}
+
+func (b *stateBreaker) MarkSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	if b.state == stateHalfOpen {
+		if b.successes++; b.successes >= b.successThreshold {
+			b.state = stateClosed
+		}
+	}
+}
+
+func (b *stateBreaker) MarkFailed() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case stateHalfOpen:
+		b.trip()
+	case stateClosed:
+		if b.failures++; b.failures >= b.failureThreshold {
+			b.trip()
+		}
+	}
+}
+
+func (b *stateBreaker) trip() {
+	b.state = stateOpen
+	b.openedAt = b.now()
+	b.failures = 0
+}
@@ -0,0 +1,45 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStateBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewStateBreaker(FailureThreshold(2), SuccessThreshold(2), OpenTimeout(time.Second)).(*stateBreaker)
+	b.now = func() time.Time { return now }
+
+	b.MarkFailed()
+	b.MarkSuccess()
+	b.MarkFailed()
+	if b.Open() {
+		t.Fatal("expected closed breaker after non consecutive failures")
+	}
+	b.MarkFailed()
+	if !b.Open() || b.Allow() == nil {
+		t.Fatal("expected open breaker after consecutive failures")
+	}
+
+	now = now.Add(time.Second)
+	if b.Open() {
+		t.Fatal("expected half-open breaker after the open timeout")
+	}
+	b.MarkFailed()
+	if !b.Open() {
+		t.Fatal("expected open breaker after a failed probe")
+	}
+
+	now = now.Add(time.Second)
+	if b.Open() {
+		t.Fatal("expected half-open breaker after the open timeout")
+	}
+	b.MarkSuccess()
+	if b.state != stateHalfOpen {
+		t.Fatalf("expected half-open breaker, got state %d", b.state)
+	}
+	b.MarkSuccess()
+	if b.state != stateClosed {
+		t.Fatalf("expected closed breaker after successful probes, got state %d", b.state)
+	}
+}
@@ -0,0 +1,177 @@
+package coalesce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// KeyFunc returns the key of the identical requests, which are coalesced,
+// empty means the request is not coalesced.
+type KeyFunc func(ctx context.Context, req interface{}) string
+
+// Option is coalesce option.
+type Option func(*options)
+
+type options struct {
+	key KeyFunc
+}
+
+// WithKey with the key of the identical requests, default is DefaultKey.
+func WithKey(fn KeyFunc) Option {
+	return func(o *options) {
+		o.key = fn
+	}
+}
+
+// DefaultKey is the operation and the normalized args of the HTTP GET and
+// HEAD requests, the proto messages are marshaled deterministically and the
+// other ones in JSON, whose map keys are sorted.
+func DefaultKey(ctx context.Context, req interface{}) string {
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok {
+		return ""
+	}
+	ht, ok := tr.(khttp.Transporter)
+	if !ok || (ht.Request().Method != http.MethodGet && ht.Request().Method != http.MethodHead) {
+		return ""
+	}
+	var (
+		args []byte
+		err  error
+	)
+	if m, ok := req.(proto.Message); ok {
+		args, err = proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	} else {
+		args, err = json.Marshal(req)
+	}
+	if err != nil {
+		return ""
+	}
+	return ht.Request().Method + " " + tr.Operation() + "\x00" + string(args)
+}
+
+// call is an in-flight execution of the coalesced requests.
+type call struct {
+	done    chan struct{}
+	reply   interface{}
+	err     error
+	header  map[string][]string
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// Server is a server middleware collapsing the concurrent identical requests
+// into a single execution of the handler, whose result is fanned out to all of
+// them, e.g. to protect the hot endpoints during the cache stampedes. The reply
+// is shared and must not be modified, the reply headers are copied.
+//
+// The execution runs with the context of the first request without its
+// cancellation, a request canceled leaves it to the others, and it is
+// canceled once all of them are canceled.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{key: DefaultKey}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var (
+		mu    sync.Mutex
+		calls = make(map[string]*call)
+	)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			key := o.key(ctx, req)
+			if key == "" {
+				return handler(ctx, req)
+			}
+			mu.Lock()
+			c, ok := calls[key]
+			if ok {
+				c.waiters++
+			} else {
+				cctx, cancel := context.WithCancel(detach{ctx})
+				c = &call{done: make(chan struct{}), waiters: 1, cancel: cancel}
+				calls[key] = c
+				go func() {
+					c.reply, c.err = handler(cctx, req)
+					c.header = snapshotHeader(cctx)
+					mu.Lock()
+					if calls[key] == c {
+						delete(calls, key)
+					}
+					mu.Unlock()
+					cancel()
+					close(c.done)
+				}()
+			}
+			mu.Unlock()
+			select {
+			case <-c.done:
+				if ok {
+					copyHeader(ctx, c.header)
+				}
+				return c.reply, c.err
+			case <-ctx.Done():
+				mu.Lock()
+				if c.waiters--; c.waiters == 0 {
+					if calls[key] == c {
+						delete(calls, key)
+					}
+					c.cancel()
+				}
+				mu.Unlock()
+				return nil, ctx.Err()
+			}
+		}
+	}
+}
+
+// snapshotHeader returns a copy of the reply header set by the execution,
+// before the first request goes on with it.
+func snapshotHeader(ctx context.Context) map[string][]string {
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok {
+		return nil
+	}
+	header := tr.ReplyHeader()
+	snapshot := make(map[string][]string)
+	for _, k := range header.Keys() {
+		snapshot[k] = append([]string(nil), header.Values(k)...)
+	}
+	return snapshot
+}
+
+// copyHeader copies the reply header of the execution to the request.
+func copyHeader(ctx context.Context, from map[string][]string) {
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok {
+		return
+	}
+	to := tr.ReplyHeader()
+	for k, vs := range from {
+		for i, v := range vs {
+			if i == 0 {
+				to.Set(k, v)
+			} else {
+				to.Add(k, v)
+			}
+		}
+	}
+}
+
+// detach is the context with the values of the parent but without its
+// deadline and cancellation.
+type detach struct{ parent context.Context }
+
+func (d detach) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (d detach) Done() <-chan struct{}             { return nil }
+func (d detach) Err() error                        { return nil }
+func (d detach) Value(key interface{}) interface{} { return d.parent.Value(key) }
@@ -0,0 +1,111 @@
+package coalesce
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func TestServer(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	m := Server(WithKey(func(_ context.Context, req interface{}) string { return req.(string) }))
+	h := m(func(ctx context.Context, req interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return req, nil
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if reply, err := h(context.Background(), "a"); err != nil || reply != "a" {
+				t.Errorf("expected the shared reply, got %v %v", reply, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected a single execution, got %d", n)
+	}
+}
+
+func TestServerCancel(t *testing.T) {
+	canceled := make(chan struct{})
+	m := Server(WithKey(func(_ context.Context, req interface{}) string { return "a" }))
+	h := m(func(ctx context.Context, req interface{}) (interface{}, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	})
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, ctx := range []context.Context{ctx1, ctx2} {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			_, _ = h(ctx, nil)
+		}(ctx)
+	}
+	time.Sleep(20 * time.Millisecond)
+	cancel1()
+	select {
+	case <-canceled:
+		t.Fatal("expected the execution to continue for the other request")
+	case <-time.After(20 * time.Millisecond):
+	}
+	cancel2()
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the execution canceled once all the requests are canceled")
+	}
+	wg.Wait()
+}
+
+func TestDefaultKey(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	srv := khttp.NewServer(khttp.Middleware(Server()))
+	srv.Route("/").GET("/hot", func(ctx khttp.Context) error {
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return map[string]string{"hello": "kratos"}, nil
+		})
+		reply, err := h(ctx, map[string]string{"q": ctx.Query().Get("q")})
+		if err != nil {
+			return err
+		}
+		ctx.Response().Header().Set("X-Test", "1")
+		return ctx.Result(http.StatusOK, reply)
+	})
+	var wg sync.WaitGroup
+	for _, q := range []string{"a", "a", "a", "b"} {
+		wg.Add(1)
+		go func(q string) {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hot?q="+q, nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected 200, got %d", rec.Code)
+			}
+		}(q)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expected an execution per distinct query, got %d", n)
+	}
+}
@@ -0,0 +1,143 @@
+// Package deadline propagates the remaining deadline of the requests to the
+// outgoing calls, so that the downstream services enforce the end-to-end
+// budget instead of each hop resetting to its own timeout. The gRPC calls
+// propagate it natively, the HTTP ones by the Grpc-Timeout header of the
+// gRPC wire format, e.g. "1500m" of 1.5s.
+package deadline
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Header is the header of the timeout of the HTTP requests.
+const Header = "Grpc-Timeout"
+
+// maxTimeoutValue is the max value of the timeout of 8 digits.
+const maxTimeoutValue = 100000000 - 1
+
+// Option is deadline option.
+type Option func(*options)
+
+type options struct {
+	max time.Duration
+}
+
+// WithMax with the max timeout propagated from the clients, e.g. of the
+// untrusted callers, default is unlimited.
+func WithMax(d time.Duration) Option {
+	return func(o *options) {
+		o.max = d
+	}
+}
+
+// Server is a server middleware bounding the context by the timeout of the
+// Header of the HTTP requests, the gRPC ones are bounded by grpc natively.
+func Server(opts ...Option) middleware.Middleware {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok || tr.Kind() != transport.KindHTTP {
+				return handler(ctx, req)
+			}
+			timeout, err := ParseTimeout(tr.RequestHeader().Get(Header))
+			if err != nil {
+				return handler(ctx, req)
+			}
+			if o.max > 0 && timeout > o.max {
+				timeout = o.max
+			}
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Client is a client middleware propagating the remaining deadline of the
+// context by the Header of the HTTP requests, the calls whose deadline is
+// exceeded fail with context.DeadlineExceeded without being sent.
+func Client() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				return handler(ctx, req)
+			}
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return nil, context.DeadlineExceeded
+			}
+			if tr, ok := transport.FromClientContext(ctx); ok && tr.Kind() == transport.KindHTTP {
+				tr.RequestHeader().Set(Header, FormatTimeout(remaining))
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// FormatTimeout formats the timeout in the gRPC wire format of at most 8
+// digits and the unit, e.g. "1500m", rounded up to the unit.
+func FormatTimeout(d time.Duration) string {
+	if d <= 0 {
+		return "0n"
+	}
+	units := []struct {
+		unit byte
+		d    time.Duration
+	}{
+		{'n', time.Nanosecond},
+		{'u', time.Microsecond},
+		{'m', time.Millisecond},
+		{'S', time.Second},
+		{'M', time.Minute},
+		{'H', time.Hour},
+	}
+	for _, u := range units {
+		if v := (d + u.d - 1) / u.d; v <= maxTimeoutValue {
+			return strconv.FormatInt(int64(v), 10) + string(u.unit)
+		}
+	}
+	return strconv.FormatInt(maxTimeoutValue, 10) + "H"
+}
+
+// ParseTimeout parses the timeout of the gRPC wire format, e.g. "1500m".
+func ParseTimeout(s string) (time.Duration, error) {
+	if len(s) < 2 || len(s) > 9 { //nolint:gomnd
+		return 0, fmt.Errorf("deadline: invalid timeout %q", s)
+	}
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, fmt.Errorf("deadline: invalid timeout unit of %q", s)
+	}
+	v, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("deadline: invalid timeout %q", s)
+	}
+	if max := int64(1<<63-1) / int64(unit); v > max {
+		return time.Duration(1<<63 - 1), nil
+	}
+	return time.Duration(v) * unit, nil
+}
@@ -0,0 +1,149 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+
+// Keys lists the keys stored in this carrier.
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns a slice value associated with the passed key.
+func (hc headerCarrier) Values(key string) []string {
+	return http.Header(hc).Values(key)
+}
+
+type testTransport struct {
+	kind   transport.Kind
+	header headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return tr.kind }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.header }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.header }
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		d time.Duration
+		s string
+	}{
+		{0, "0n"},
+		{time.Nanosecond, "1n"},
+		{1500 * time.Millisecond, "1500000u"},
+		{200 * time.Second, "200000m"},
+		{100 * time.Hour, "360000S"},
+		{1500*time.Millisecond + 1, "1500001u"},
+	}
+	for _, test := range tests {
+		if s := FormatTimeout(test.d); s != test.s {
+			t.Errorf("%v: expected %s got %s", test.d, test.s, s)
+		}
+	}
+	for s, want := range map[string]time.Duration{"1H": time.Hour, "30M": 30 * time.Minute, "2S": 2 * time.Second, "1500m": 1500 * time.Millisecond, "5u": 5 * time.Microsecond, "7n": 7} {
+		if d, err := ParseTimeout(s); err != nil || d != want {
+			t.Errorf("%s: expected %v got %v %v", s, want, d, err)
+		}
+	}
+	for _, s := range []string{"", "1", "1x", "-1S", "123456789S", "aS"} {
+		if _, err := ParseTimeout(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	tr := &testTransport{kind: transport.KindHTTP, header: headerCarrier{}}
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	ctx := transport.NewClientContext(context.Background(), tr)
+	if _, err := Client()(next)(ctx, nil); err != nil || tr.header.Get(Header) != "" {
+		t.Fatalf("expected no header without deadline, got %q %v", tr.header.Get(Header), err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if _, err := Client()(next)(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	d, err := ParseTimeout(tr.header.Get(Header))
+	if err != nil || d <= 0 || d > time.Second {
+		t.Errorf("unexpected timeout %q %v", tr.header.Get(Header), err)
+	}
+
+	grpcTr := &testTransport{kind: transport.KindGRPC, header: headerCarrier{}}
+	if _, err = Client()(next)(transport.NewClientContext(ctx, grpcTr), nil); err != nil || grpcTr.header.Get(Header) != "" {
+		t.Errorf("expected no header of gRPC, got %q %v", grpcTr.header.Get(Header), err)
+	}
+
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+	if _, err = Client()(next)(expired, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestServer(t *testing.T) {
+	var got time.Duration
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if deadline, ok := ctx.Deadline(); ok {
+			got = time.Until(deadline)
+		} else {
+			got = 0
+		}
+		return "ok", nil
+	}
+	tests := []struct {
+		name   string
+		header string
+		opts   []Option
+		parent time.Duration
+		max    time.Duration
+	}{
+		{"none", "", nil, 0, 0},
+		{"invalid", "1x", nil, 0, 0},
+		{"header", "2S", nil, 0, 2 * time.Second},
+		{"max", "1H", []Option{WithMax(time.Second)}, 0, time.Second},
+		{"shorter parent", "1H", nil, time.Second, time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tr := &testTransport{kind: transport.KindHTTP, header: headerCarrier{}}
+			if test.header != "" {
+				tr.header.Set(Header, test.header)
+			}
+			ctx := context.Background()
+			if test.parent > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, test.parent)
+				defer cancel()
+			}
+			if _, err := Server(test.opts...)(next)(transport.NewServerContext(ctx, tr), nil); err != nil {
+				t.Fatal(err)
+			}
+			if got > test.max || (test.max > 0 && got < test.max-100*time.Millisecond) {
+				t.Errorf("expected the deadline of %v got %v", test.max, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package debug
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Header is the request header of the debug token.
+const Header = "X-Debug-Token"
+
+type debugKey struct{}
+
+// NewToken returns a debug token signed by the secret which expires after the ttl,
+// the token is "<expiry unix seconds>.<base64 HMAC-SHA256 of the expiry>".
+func NewToken(secret []byte, ttl time.Duration) string {
+	expiry := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	return expiry + "." + sign(secret, expiry)
+}
+
+// Verify reports whether the token is signed by the secret and not expired.
+func Verify(secret []byte, token string) bool {
+	if len(secret) == 0 {
+		return false
+	}
+	expiry, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	sec, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || time.Now().Unix() > sec {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(sign(secret, expiry)))
+}
+
+func sign(secret []byte, expiry string) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(expiry))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Filter is an HTTP server filter which starts a debug session for the
+// requests with a valid debug token, the timeline of the request is captured
+// as a Server-Timing header. It must be a filter of the http.Filter option.
+func Filter(secret []byte) khttp.FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if token := req.Header.Get(Header); token != "" && Verify(secret, token) {
+				req = khttp.CaptureTimeline(req.WithContext(NewContext(req.Context())))
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// Server is a server middleware which starts a debug session for the requests
+// with a valid debug token, e.g. of gRPC. It should be placed before the tracing
+// middleware so that the Sampler samples the span of the request.
+func Server(secret []byte) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if FromContext(ctx) {
+				return handler(ctx, req)
+			}
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				if token := tr.RequestHeader().Get(Header); token != "" && Verify(secret, token) {
+					ctx = NewContext(ctx)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// NewContext starts a debug session in context.
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugKey{}, true)
+}
+
+// FromContext reports whether the context is of a debug session.
+func FromContext(ctx context.Context) bool {
+	v, _ := ctx.Value(debugKey{}).(bool)
+	return v
+}
+
+// sessionKey is the key of the debug session value bound by NewLogger.
+type sessionKey struct{}
+
+type logger struct {
+	logger log.Logger
+	level  log.Level
+}
+
+// NewLogger returns a logger which drops the logs below the level, except those
+// of the debug sessions logged with log.WithContext, e.g. by a log.Helper.
+func NewLogger(l log.Logger, level log.Level) log.Logger {
+	return log.With(&logger{logger: l, level: level}, sessionKey{}, log.Valuer(func(ctx context.Context) interface{} {
+		return FromContext(ctx)
+	}))
+}
+
+func (l *logger) Log(level log.Level, keyvals ...interface{}) error {
+	debug := false
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if _, ok := keyvals[i].(sessionKey); ok {
+			debug, _ = keyvals[i+1].(bool)
+			keyvals = append(keyvals[:i:i], keyvals[i+2:]...)
+			break
+		}
+	}
+	if level < l.level && !debug {
+		return nil
+	}
+	return l.logger.Log(level, keyvals...)
+}
+
+type sampler struct {
+	sdktrace.Sampler
+}
+
+// Sampler returns a trace sampler which samples the spans of the debug sessions,
+// and the other spans by the base sampler.
+func Sampler(base sdktrace.Sampler) sdktrace.Sampler {
+	return sampler{Sampler: base}
+}
+
+func (s sampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if p.ParentContext != nil && FromContext(p.ParentContext) {
+		return sdktrace.AlwaysSample().ShouldSample(p)
+	}
+	return s.Sampler.ShouldSample(p)
+}
+
+func (s sampler) Description() string {
+	return "DebugSampler{" + s.Sampler.Description() + "}"
+}
@@ -0,0 +1,132 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+var secret = []byte("secret")
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string      { return http.Header(hc).Get(key) }
+func (hc headerCarrier) Set(key, value string)      { http.Header(hc).Set(key, value) }
+func (hc headerCarrier) Add(key, value string)      { http.Header(hc).Add(key, value) }
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+func (hc headerCarrier) Keys() []string             { return nil }
+
+type testTransport struct{ header headerCarrier }
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.header }
+func (tr *testTransport) ReplyHeader() transport.Header   { return headerCarrier{} }
+
+func TestVerify(t *testing.T) {
+	token := NewToken(secret, time.Minute)
+	expired := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	tests := []struct {
+		name   string
+		secret []byte
+		token  string
+		want   bool
+	}{
+		{"valid", secret, token, true},
+		{"wrong secret", []byte("other"), token, false},
+		{"no secret", nil, token, false},
+		{"expired", secret, expired + "." + sign(secret, expired), false},
+		{"tampered", secret, "9" + token, false},
+		{"malformed", secret, "token", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Verify(test.secret, test.token); got != test.want {
+				t.Errorf("expected %v got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	var debug bool
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		debug = FromContext(ctx)
+		return nil, nil
+	}
+	for _, token := range []string{NewToken(secret, time.Minute), "invalid"} {
+		header := headerCarrier{}
+		header.Set(Header, token)
+		_, _ = Server(secret)(handler)(transport.NewServerContext(context.Background(), &testTransport{header: header}), nil)
+		if want := token != "invalid"; debug != want {
+			t.Errorf("expected debug %v got %v", want, debug)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := log.NewHelper(NewLogger(log.NewStdLogger(buf), log.LevelInfo))
+	srv := khttp.NewServer(khttp.Filter(Filter(secret)))
+	srv.Route("/").GET("/debug", func(ctx khttp.Context) error {
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			logger.WithContext(ctx).Debug("verbose")
+			return nil, nil
+		})
+		_, _ = h(ctx, nil)
+		return ctx.String(http.StatusOK, "ok")
+	})
+
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/debug", nil))
+	if buf.Len() != 0 || res.Header().Get("Server-Timing") != "" {
+		t.Errorf("expected no debug output got %q %q", buf.String(), res.Header().Get("Server-Timing"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/debug", nil)
+	req.Header.Set(Header, NewToken(secret, time.Minute))
+	res = httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if out := buf.String(); !strings.Contains(out, "DEBUG msg=verbose") || strings.Contains(out, "sessionKey") {
+		t.Errorf("expected the debug log got %q", out)
+	}
+	if !strings.Contains(res.Header().Get("Server-Timing"), "handler;dur=") {
+		t.Errorf("expected the timeline captured got %q", res.Header().Get("Server-Timing"))
+	}
+}
+
+func TestLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := NewLogger(log.NewStdLogger(buf), log.LevelInfo)
+	_ = logger.Log(log.LevelDebug, "msg", "dropped")
+	_ = log.WithContext(NewContext(context.Background()), logger).Log(log.LevelDebug, "msg", "kept")
+	_ = logger.Log(log.LevelInfo, "msg", "info")
+	if out := buf.String(); out != "DEBUG msg=kept\nINFO msg=info\n" {
+		t.Errorf("unexpected logs %q", out)
+	}
+}
+
+func TestSampler(t *testing.T) {
+	s := Sampler(sdktrace.NeverSample())
+	if r := s.ShouldSample(sdktrace.SamplingParameters{ParentContext: context.Background()}); r.Decision != sdktrace.Drop {
+		t.Errorf("expected the base sampler decision got %v", r.Decision)
+	}
+	if r := s.ShouldSample(sdktrace.SamplingParameters{ParentContext: NewContext(context.Background())}); r.Decision != sdktrace.RecordAndSample {
+		t.Errorf("expected the debug session sampled got %v", r.Decision)
+	}
+	if s.Description() != "DebugSampler{AlwaysOffSampler}" {
+		t.Errorf("unexpected description %s", s.Description())
+	}
+}
@@ -0,0 +1,293 @@
+// Package dump captures the request and reply bodies of the operations for
+// diagnosing the serialization, e.g. of the selected operations of the
+// selector middleware:
+//
+//	d := dump.New(dump.WithRedactFields("password"))
+//	selector.Server(d.Server()).Prefix("/helloworld.v1.Greeter/").Build()
+//
+// The dumper is disabled until Enable, so that it can be toggled at runtime,
+// e.g. of a watched config key of config.Bind.
+package dump
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/json" // the codec of the bodies
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Redacted is the value of the redacted fields and headers.
+const Redacted = "[REDACTED]"
+
+// defaultMaxSize is the default max size of a dumped body.
+const defaultMaxSize = 4096
+
+// defaultRedactHeaders are the headers redacted by default.
+var defaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// Record is a dump of an operation.
+type Record struct {
+	// Kind is "server" or "client".
+	Kind          string
+	Operation     string
+	RequestHeader map[string][]string
+	ReplyHeader   map[string][]string
+	// Request and Reply are the JSON bodies, capped to the max size.
+	Request   string
+	Reply     string
+	Truncated bool
+	Code      int32
+	Reason    string
+	Latency   time.Duration
+}
+
+// Sink is the destination of the records.
+type Sink interface {
+	Dump(ctx context.Context, r *Record)
+}
+
+// SinkFunc is a func of a Sink.
+type SinkFunc func(ctx context.Context, r *Record)
+
+// Dump calls f(ctx, r).
+func (f SinkFunc) Dump(ctx context.Context, r *Record) {
+	f(ctx, r)
+}
+
+// Option is dump option.
+type Option func(*options)
+
+type options struct {
+	sink          Sink
+	maxSize       int
+	redactFields  map[string]struct{}
+	redactHeaders map[string]struct{}
+	enabled       bool
+}
+
+// WithSink with the sink of the records, default is the logger of the context.
+func WithSink(sink Sink) Option {
+	return func(o *options) {
+		o.sink = sink
+	}
+}
+
+// WithLogger with the logger of the records, default is the global logger.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.sink = logSink{logger: logger}
+	}
+}
+
+// WithMaxSize with the max size of a dumped body, default is 4KB.
+func WithMaxSize(size int) Option {
+	return func(o *options) {
+		o.maxSize = size
+	}
+}
+
+// WithRedactFields with the JSON fields redacted at any depth of the bodies, case-insensitive.
+func WithRedactFields(fields ...string) Option {
+	return func(o *options) {
+		for _, f := range fields {
+			o.redactFields[strings.ToLower(f)] = struct{}{}
+		}
+	}
+}
+
+// WithRedactHeaders with the redacted headers besides the ones of the
+// credentials, e.g. Authorization and Cookie, case-insensitive.
+func WithRedactHeaders(headers ...string) Option {
+	return func(o *options) {
+		for _, h := range headers {
+			o.redactHeaders[strings.ToLower(h)] = struct{}{}
+		}
+	}
+}
+
+// WithEnabled with whether the dumper is enabled initially, default is false.
+func WithEnabled(enabled bool) Option {
+	return func(o *options) {
+		o.enabled = enabled
+	}
+}
+
+// Dumper dumps the operations of its middlewares while enabled.
+type Dumper struct {
+	opts    options
+	enabled atomic.Bool
+}
+
+// New new a dumper with options.
+func New(opts ...Option) *Dumper {
+	o := options{
+		sink:          logSink{},
+		maxSize:       defaultMaxSize,
+		redactFields:  make(map[string]struct{}),
+		redactHeaders: make(map[string]struct{}),
+	}
+	for _, h := range defaultRedactHeaders {
+		o.redactHeaders[strings.ToLower(h)] = struct{}{}
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	d := &Dumper{opts: o}
+	d.enabled.Store(o.enabled)
+	return d
+}
+
+// Enable enables or disables the dumper.
+func (d *Dumper) Enable(enabled bool) {
+	d.enabled.Store(enabled)
+}
+
+// Enabled reports whether the dumper is enabled.
+func (d *Dumper) Enabled() bool {
+	return d.enabled.Load()
+}
+
+// Server is a server middleware dumping the operations.
+func (d *Dumper) Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if !d.Enabled() {
+				return handler(ctx, req)
+			}
+			tr, _ := transport.FromServerContext(ctx)
+			return d.dump(ctx, "server", tr, handler, req)
+		}
+	}
+}
+
+// Client is a client middleware dumping the operations.
+func (d *Dumper) Client() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if !d.Enabled() {
+				return handler(ctx, req)
+			}
+			tr, _ := transport.FromClientContext(ctx)
+			return d.dump(ctx, "client", tr, handler, req)
+		}
+	}
+}
+
+func (d *Dumper) dump(ctx context.Context, kind string, tr transport.Transporter, handler middleware.Handler, req interface{}) (interface{}, error) {
+	start := time.Now()
+	reply, err := handler(ctx, req)
+	r := &Record{Kind: kind, Latency: time.Since(start)}
+	var reqTruncated, replyTruncated bool
+	r.Request, reqTruncated = d.body(req)
+	if err == nil {
+		r.Reply, replyTruncated = d.body(reply)
+	}
+	r.Truncated = reqTruncated || replyTruncated
+	if se := errors.FromError(err); se != nil {
+		r.Code, r.Reason = se.Code, se.Reason
+	}
+	if tr != nil {
+		r.Operation = tr.Operation()
+		r.RequestHeader = d.header(tr.RequestHeader())
+		r.ReplyHeader = d.header(tr.ReplyHeader())
+	}
+	d.opts.sink.Dump(ctx, r)
+	return reply, err
+}
+
+// body returns the redacted JSON of the message, capped to the max size.
+func (d *Dumper) body(v interface{}) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	b, err := encoding.GetCodec("json").Marshal(v)
+	if err != nil {
+		return "<" + err.Error() + ">", false
+	}
+	if len(d.opts.redactFields) > 0 {
+		var tree interface{}
+		if json.Unmarshal(b, &tree) == nil {
+			if rb, rerr := json.Marshal(d.redact(tree)); rerr == nil {
+				b = rb
+			}
+		}
+	}
+	if d.opts.maxSize <= 0 || len(b) <= d.opts.maxSize {
+		return string(b), false
+	}
+	n := d.opts.maxSize
+	for n > 0 && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	return string(b[:n]), true
+}
+
+func (d *Dumper) redact(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range t {
+			if _, ok := d.opts.redactFields[strings.ToLower(k)]; ok {
+				t[k] = Redacted
+				continue
+			}
+			t[k] = d.redact(fv)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = d.redact(e)
+		}
+	}
+	return v
+}
+
+func (d *Dumper) header(h transport.Header) map[string][]string {
+	if h == nil {
+		return nil
+	}
+	keys := h.Keys()
+	if len(keys) == 0 {
+		return nil
+	}
+	m := make(map[string][]string, len(keys))
+	for _, k := range keys {
+		if _, ok := d.opts.redactHeaders[strings.ToLower(k)]; ok {
+			m[k] = []string{Redacted}
+			continue
+		}
+		m[k] = h.Values(k)
+	}
+	return m
+}
+
+// logSink logs the records at the info level.
+type logSink struct {
+	logger log.Logger
+}
+
+func (s logSink) Dump(ctx context.Context, r *Record) {
+	logger := s.logger
+	if logger == nil {
+		logger = log.GetLogger()
+	}
+	_ = log.WithContext(ctx, logger).Log(log.LevelInfo,
+		"kind", r.Kind,
+		"operation", r.Operation,
+		"request_header", r.RequestHeader,
+		"request", r.Request,
+		"reply_header", r.ReplyHeader,
+		"reply", r.Reply,
+		"truncated", r.Truncated,
+		"code", r.Code,
+		"reason", r.Reason,
+		"latency", r.Latency.Seconds(),
+	)
+}
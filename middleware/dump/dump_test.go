@@ -0,0 +1,111 @@
+package dump
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string      { return http.Header(hc).Get(key) }
+func (hc headerCarrier) Set(key, value string)      { http.Header(hc).Set(key, value) }
+func (hc headerCarrier) Add(key, value string)      { http.Header(hc).Add(key, value) }
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type testTransport struct {
+	request headerCarrier
+	reply   headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "/test.v1.Test/Login" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.request }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.reply }
+
+type loginRequest struct {
+	User     string            `json:"user"`
+	Password string            `json:"password"`
+	Extra    map[string]string `json:"extra"`
+}
+
+func TestServer(t *testing.T) {
+	var records []*Record
+	d := New(
+		WithSink(SinkFunc(func(_ context.Context, r *Record) { records = append(records, r) })),
+		WithRedactFields("Password", "token"),
+		WithRedactHeaders("X-Api-Key"),
+	)
+	tr := &testTransport{request: headerCarrier{}, reply: headerCarrier{}}
+	tr.request.Set("Authorization", "Bearer t")
+	tr.request.Set("X-Api-Key", "k")
+	tr.request.Set("X-Trace", "1")
+	ctx := transport.NewServerContext(context.Background(), tr)
+	handler := d.Server()(func(context.Context, interface{}) (interface{}, error) {
+		return map[string]interface{}{"items": []interface{}{map[string]interface{}{"token": "x", "id": 1}}}, nil
+	})
+	req := &loginRequest{User: "alice", Password: "secret", Extra: map[string]string{"token": "y"}}
+	if _, err := handler(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Fatal("expected no records while disabled")
+	}
+
+	d.Enable(true)
+	if _, err := handler(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected a record got %d", len(records))
+	}
+	r := records[0]
+	if r.Kind != "server" || r.Operation != "/test.v1.Test/Login" || r.Truncated {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if r.Request != `{"extra":{"token":"[REDACTED]"},"password":"[REDACTED]","user":"alice"}` {
+		t.Errorf("unexpected request %s", r.Request)
+	}
+	if r.Reply != `{"items":[{"id":1,"token":"[REDACTED]"}]}` {
+		t.Errorf("unexpected reply %s", r.Reply)
+	}
+	h := r.RequestHeader
+	if h["Authorization"][0] != Redacted || h["X-Api-Key"][0] != Redacted || h["X-Trace"][0] != "1" {
+		t.Errorf("unexpected header %v", h)
+	}
+	if req.Password != "secret" {
+		t.Error("expected the request untouched")
+	}
+}
+
+func TestClientTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	d := New(WithLogger(log.NewStdLogger(&buf)), WithMaxSize(16), WithEnabled(true))
+	ctx := transport.NewClientContext(context.Background(), &testTransport{request: headerCarrier{}, reply: headerCarrier{}})
+	_, err := d.Client()(func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.NotFound("USER_NOT_FOUND", "not found")
+	})(ctx, &loginRequest{User: strings.Repeat("é", 16)})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("expected the error of the handler, got %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{"kind=client", "truncated=true", "code=404", "reason=USER_NOT_FOUND", `request={"user":"ééé`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s in %s", want, got)
+		}
+	}
+}
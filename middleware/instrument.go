@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Observer observes the execution of each middleware instrumented by
+// Instrument. It returns the context of the middleware, e.g. of a child span,
+// and the func called once the middleware returns, with the duration of it
+// excluding the one of the next handler, and the error of it, which is nil if
+// the error is returned by the next handler.
+type Observer func(ctx context.Context, name string) (context.Context, func(self time.Duration, err error))
+
+type frameKey struct{}
+
+// names caches the names of the middleware by the pointers of their funcs.
+var names sync.Map
+
+// frame is the call of an instrumented middleware, which records the duration
+// and the error of its next handler.
+type frame struct {
+	next time.Duration
+	err  error
+}
+
+// Instrument returns the middleware observed by o, which are named by Name.
+func Instrument(o Observer, m ...Middleware) []Middleware {
+	ms := make([]Middleware, len(m))
+	for i, mw := range m {
+		pc := reflect.ValueOf(mw).Pointer()
+		name, ok := names.Load(pc)
+		if !ok {
+			name, _ = names.LoadOrStore(pc, Name(mw))
+		}
+		ms[i] = instrument(o, name.(string), mw)
+	}
+	return ms
+}
+
+func instrument(o Observer, name string, m Middleware) Middleware {
+	return func(next Handler) Handler {
+		h := m(func(ctx context.Context, req interface{}) (interface{}, error) {
+			start := time.Now()
+			reply, err := next(ctx, req)
+			if f, ok := ctx.Value(frameKey{}).(*frame); ok {
+				f.next += time.Since(start)
+				f.err = err
+			}
+			return reply, err
+		})
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			ctx, done := o(ctx, name)
+			f := &frame{}
+			start := time.Now()
+			reply, err := h(context.WithValue(ctx, frameKey{}, f), req)
+			self := err
+			if err != nil && err == f.err {
+				self = nil
+			}
+			done(time.Since(start)-f.next, self)
+			return reply, err
+		}
+	}
+}
+
+// Name returns the name of the middleware, which is the func returning it,
+// e.g. "recovery.Recovery" of recovery.Recovery().
+func Name(m Middleware) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(m).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	// the path of the package is trimmed, e.g. of github.com/go-kratos/kratos/v2/middleware/recovery.
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	// the closures of the func, e.g. recovery.Recovery.func1.
+	for {
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 || !isClosure(name[i+1:]) {
+			break
+		}
+		name = name[:i]
+	}
+	return name
+}
+
+// isClosure reports whether the element of a func name is of a closure, e.g.
+// "func1" or "1".
+func isClosure(s string) bool {
+	s = strings.TrimPrefix(s, "func")
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
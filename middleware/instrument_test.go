@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func sleepMiddleware(d time.Duration) Middleware {
+	return func(h Handler) Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			time.Sleep(d)
+			return h(ctx, req)
+		}
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	errDenied := errors.New("denied")
+	deny := func(h Handler) Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if req == "deny" {
+				return nil, errDenied
+			}
+			return h(ctx, req)
+		}
+	}
+	type record struct {
+		self time.Duration
+		err  error
+	}
+	records := make(map[string]record)
+	o := func(ctx context.Context, name string) (context.Context, func(time.Duration, error)) {
+		return ctx, func(self time.Duration, err error) { records[name] = record{self, err} }
+	}
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return req, nil
+	}
+	h := Chain(Instrument(o, sleepMiddleware(10*time.Millisecond), deny)...)(next)
+	if reply, err := h(context.Background(), "ok"); err != nil || reply != "ok" {
+		t.Fatalf("unexpected reply %v %v", reply, err)
+	}
+	if r := records["middleware.sleepMiddleware"]; r.self < 10*time.Millisecond || r.self >= 20*time.Millisecond {
+		t.Errorf("expected the self duration of the sleep middleware, got %v", records)
+	}
+	if r, ok := records["middleware.TestInstrument"]; !ok || r.self >= 10*time.Millisecond {
+		t.Errorf("expected the self duration of the deny middleware, got %v", records)
+	}
+
+	if _, err := h(context.Background(), "deny"); !errors.Is(err, errDenied) {
+		t.Fatalf("expected errDenied, got %v", err)
+	}
+	if records["middleware.TestInstrument"].err != errDenied || records["middleware.sleepMiddleware"].err != nil {
+		t.Errorf("expected the error of the deny middleware only, got %v", records)
+	}
+}
+
+func TestName(t *testing.T) {
+	if name := Name(test1Middleware); name != "middleware.test1Middleware" {
+		t.Errorf("unexpected name %q", name)
+	}
+	if name := Name(sleepMiddleware(0)); name != "middleware.sleepMiddleware" {
+		t.Errorf("unexpected name %q", name)
+	}
+}
@@ -0,0 +1,117 @@
+package killswitch
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const reason = "KILLSWITCH"
+
+// Rule disables the operation, or the operations of the prefix if it ends with *.
+// The requests are rejected with the code, 503 or 405, and the message.
+type Rule struct {
+	Operation string `json:"operation"`
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+}
+
+func (r Rule) match(operation string) bool {
+	if prefix := strings.TrimSuffix(r.Operation, "*"); prefix != r.Operation {
+		return strings.HasPrefix(operation, prefix)
+	}
+	return r.Operation == operation
+}
+
+func (r Rule) err() *errors.Error {
+	code, message := r.Code, r.Message
+	if code != http.StatusMethodNotAllowed {
+		code = http.StatusServiceUnavailable
+	}
+	if message == "" {
+		message = "the operation is disabled"
+	}
+	return errors.New(code, reason, message)
+}
+
+type rules struct {
+	rules []Rule
+	errs  []*errors.Error
+}
+
+// Switch holds the rules of the disabled operations which can be replaced at runtime.
+type Switch struct {
+	rules atomic.Value
+}
+
+// New creates a switch with the rules.
+func New(rs ...Rule) *Switch {
+	s := &Switch{}
+	s.Set(rs)
+	return s
+}
+
+// Set replaces the rules of the switch.
+func (s *Switch) Set(rs []Rule) {
+	v := &rules{rules: rs, errs: make([]*errors.Error, 0, len(rs))}
+	for _, r := range rs {
+		v.errs = append(v.errs, r.err())
+	}
+	s.rules.Store(v)
+}
+
+// Rules returns the rules of the switch.
+func (s *Switch) Rules() []Rule {
+	return s.rules.Load().(*rules).rules
+}
+
+// Disabled returns the error of the first rule which disables the operation.
+func (s *Switch) Disabled(operation string) (*errors.Error, bool) {
+	v := s.rules.Load().(*rules)
+	for i, r := range v.rules {
+		if r.match(operation) {
+			return v.errs[i], true
+		}
+	}
+	return nil, false
+}
+
+// Watch sets the rules of the config key and replaces them on the config changes,
+// so that the operations are disabled without redeploying. The key must exist,
+// e.g. as an empty list of rules.
+func (s *Switch) Watch(c config.Config, key string) error {
+	var rs []Rule
+	if err := c.Value(key).Scan(&rs); err != nil {
+		return err
+	}
+	s.Set(rs)
+	return c.Watch(key, func(_ string, v config.Value) {
+		var rs []Rule
+		if err := v.Scan(&rs); err != nil {
+			log.Errorf("[KillSwitch] failed to scan the rules of %s: %v", key, err)
+			return
+		}
+		s.Set(rs)
+	})
+}
+
+// Server is a server middleware which rejects the requests of the disabled operations.
+func Server(s *Switch) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				if err, ok := s.Disabled(tr.Operation()); ok {
+					return nil, err
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package killswitch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type testTransport struct{ operation string }
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return tr.operation }
+func (tr *testTransport) RequestHeader() transport.Header { return nil }
+func (tr *testTransport) ReplyHeader() transport.Header   { return nil }
+
+type testSource struct {
+	data    string
+	changes chan string
+}
+
+func (s *testSource) Load() ([]*config.KeyValue, error) {
+	return []*config.KeyValue{{Key: "test", Value: []byte(s.data), Format: "json"}}, nil
+}
+
+func (s *testSource) Watch() (config.Watcher, error) { return s, nil }
+
+func (s *testSource) Next() ([]*config.KeyValue, error) {
+	data, ok := <-s.changes
+	if !ok {
+		return nil, context.Canceled
+	}
+	return []*config.KeyValue{{Key: "test", Value: []byte(data), Format: "json"}}, nil
+}
+
+func (s *testSource) Stop() error { return nil }
+
+func TestSwitch(t *testing.T) {
+	s := New(
+		Rule{Operation: "/order.v1.Order/Create", Message: "orders are paused"},
+		Rule{Operation: "/admin.v1.*", Code: 405},
+		Rule{Operation: "/user.v1.User/Get", Code: 400},
+	)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	tests := []struct {
+		operation string
+		code      int
+		message   string
+	}{
+		{"/order.v1.Order/Create", 503, "orders are paused"},
+		{"/order.v1.Order/Get", 200, ""},
+		{"/admin.v1.Admin/Delete", 405, "the operation is disabled"},
+		{"/user.v1.User/Get", 503, "the operation is disabled"},
+	}
+	for _, test := range tests {
+		t.Run(test.operation, func(t *testing.T) {
+			ctx := transport.NewServerContext(context.Background(), &testTransport{operation: test.operation})
+			reply, err := Server(s)(handler)(ctx, nil)
+			if test.code == 200 {
+				if err != nil || reply != "ok" {
+					t.Errorf("expected the operation enabled got %v", err)
+				}
+				return
+			}
+			if se := errors.FromError(err); se.Code != int32(test.code) || se.Reason != reason || se.Message != test.message {
+				t.Errorf("expected %d %s got %v", test.code, test.message, err)
+			}
+		})
+	}
+}
+
+func TestSwitchWatch(t *testing.T) {
+	src := &testSource{data: `{"killswitch":[{"operation":"/a"}]}`, changes: make(chan string)}
+	c := config.New(config.WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	s := New()
+	if err := s.Watch(c, "killswitch"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Disabled("/a"); !ok {
+		t.Error("expected /a disabled")
+	}
+	src.changes <- `{"killswitch":[{"operation":"/b"}]}`
+	deadline := time.Now().Add(time.Second)
+	for {
+		_, a := s.Disabled("/a")
+		_, b := s.Disabled("/b")
+		if !a && b {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the rules replaced got %v", s.Rules())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := s.Watch(c, "missing"); err == nil {
+		t.Error("expected the missing key error")
+	}
+}
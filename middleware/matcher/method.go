@@ -0,0 +1,76 @@
+package matcher
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type methodMatcher struct {
+	Matcher
+	newMatcher func() Matcher
+	methods    map[string]Matcher
+}
+
+// NewMethod new a middleware matcher of the HTTP method aware selectors, e.g.
+// "POST /v1/users/*", of which the operations are matched by the matchers of
+// newMatcher, e.g. New. The middleware of the selectors without the methods are
+// applied first, then the ones of the method of the HTTP requests.
+func NewMethod(newMatcher func() Matcher) Matcher {
+	return &methodMatcher{
+		Matcher:    newMatcher(),
+		newMatcher: newMatcher,
+		methods:    make(map[string]Matcher),
+	}
+}
+
+func (m *methodMatcher) Add(selector string, ms ...middleware.Middleware) {
+	method, rest, ok := strings.Cut(selector, " ")
+	if !ok || method == "" || strings.ToUpper(method) != method {
+		m.Matcher.Add(selector, ms...)
+		return
+	}
+	mm, ok := m.methods[method]
+	if !ok {
+		mm = m.newMatcher()
+		m.methods[method] = mm
+	}
+	mm.Add(strings.TrimSpace(rest), ms...)
+}
+
+func (m *methodMatcher) MatchContext(ctx context.Context, operation string) []middleware.Middleware {
+	ms := m.Matcher.Match(operation)
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok {
+		return ms
+	}
+	ht, ok := tr.(interface{ Request() *http.Request })
+	if !ok {
+		return ms
+	}
+	if mm, ok := m.methods[ht.Request().Method]; ok {
+		ms = append(ms, mm.Match(operation)...)
+	}
+	return ms
+}
+
+// Selectors returns the selectors of the operation of all the methods, the
+// method ones are prefixed by their methods.
+func (m *methodMatcher) Selectors(operation string) []string {
+	selectors := m.Matcher.Selectors(operation)
+	methods := make([]string, 0, len(m.methods))
+	for method := range m.methods {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		for _, s := range m.methods[method].Selectors(operation) {
+			selectors = append(selectors, method+" "+s)
+		}
+	}
+	return selectors
+}
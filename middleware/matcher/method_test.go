@@ -0,0 +1,36 @@
+package matcher
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type testTransport struct {
+	transport.Transporter
+	req *http.Request
+}
+
+func (tr *testTransport) Request() *http.Request { return tr.req }
+
+func TestMethodMatcher(t *testing.T) {
+	m := NewMethod(New)
+	m.Use(logging("logging"))
+	m.Add("/users/*", logging("users"))
+	m.Add("POST /users/*", logging("create"))
+
+	post := transport.NewServerContext(context.Background(), &testTransport{req: &http.Request{Method: http.MethodPost}})
+	if ms := MatchContext(post, m, "/users/1"); len(ms) != 3 || !equal(ms, "logging", "users", "create") {
+		t.Fatal("expected the middleware of the method")
+	}
+	get := transport.NewServerContext(context.Background(), &testTransport{req: &http.Request{Method: http.MethodGet}})
+	if ms := MatchContext(get, m, "/users/1"); len(ms) != 2 || !equal(ms, "logging", "users") {
+		t.Fatal("expected the middleware without the method")
+	}
+	if s := m.Selectors("/users/1"); !reflect.DeepEqual(s, []string{"*", "/users/*", "POST /users/*"}) {
+		t.Errorf("unexpected selectors %v", s)
+	}
+}
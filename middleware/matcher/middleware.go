@@ -0,0 +1,105 @@
+// Package matcher matches the middleware of the operations by the selectors
+// of the servers' Use, it is the extension point of the alternative matchers,
+// see the MiddlewareMatcher options of the HTTP and gRPC servers.
+package matcher
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// Matcher is a middleware matcher.
+type Matcher interface {
+	Use(ms ...middleware.Middleware)
+	Add(selector string, ms ...middleware.Middleware)
+	Match(operation string) []middleware.Middleware
+	Selectors(operation string) []string
+}
+
+// ContextMatcher is a Matcher matching the middleware by the request context
+// too, e.g. by the HTTP method of the server transport.
+type ContextMatcher interface {
+	MatchContext(ctx context.Context, operation string) []middleware.Middleware
+}
+
+// MatchContext returns the middleware of the operation, matched by the request
+// context if the matcher is a ContextMatcher.
+func MatchContext(ctx context.Context, m Matcher, operation string) []middleware.Middleware {
+	if cm, ok := m.(ContextMatcher); ok {
+		return cm.MatchContext(ctx, operation)
+	}
+	return m.Match(operation)
+}
+
+// New new a middleware matcher of the exact and the prefix selectors, e.g.
+// "/foo/bar" and "/foo/*", the exact one or the longest prefix one is matched.
+func New() Matcher {
+	return &matcher{
+		matchs: make(map[string][]middleware.Middleware),
+	}
+}
+
+type matcher struct {
+	prefix   []string
+	defaults []middleware.Middleware
+	matchs   map[string][]middleware.Middleware
+}
+
+func (m *matcher) Use(ms ...middleware.Middleware) {
+	m.defaults = ms
+}
+
+func (m *matcher) Add(selector string, ms ...middleware.Middleware) {
+	if strings.HasSuffix(selector, "*") {
+		selector = strings.TrimSuffix(selector, "*")
+		m.prefix = append(m.prefix, selector)
+		// sort the prefix:
+		//  - /foo/bar
+		//  - /foo
+		sort.Slice(m.prefix, func(i, j int) bool {
+			return m.prefix[i] > m.prefix[j]
+		})
+	}
+	m.matchs[selector] = ms
+}
+
+func (m *matcher) Match(operation string) []middleware.Middleware {
+	ms := make([]middleware.Middleware, 0, len(m.defaults))
+	if len(m.defaults) > 0 {
+		ms = append(ms, m.defaults...)
+	}
+	if next, ok := m.matchs[operation]; ok {
+		return append(ms, next...)
+	}
+	for _, prefix := range m.prefix {
+		if strings.HasPrefix(operation, prefix) {
+			return append(ms, m.matchs[prefix]...)
+		}
+	}
+	return ms
+}
+
+// Selectors returns the selectors of the middleware matching the operation,
+// "*" is of the default middleware.
+func (m *matcher) Selectors(operation string) []string {
+	var selectors []string
+	if len(m.defaults) > 0 {
+		selectors = append(selectors, "*")
+	}
+	if _, ok := m.matchs[operation]; ok {
+		return append(selectors, operation)
+	}
+	for _, prefix := range m.prefix {
+		if strings.HasPrefix(operation, prefix) {
+			if prefix == "" && len(selectors) > 0 {
+				// the "*" selector is the same as the default middleware.
+				return selectors
+			}
+			return append(selectors, prefix+"*")
+		}
+	}
+	return selectors
+}
@@ -2,6 +2,7 @@ package matcher
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/middleware"
@@ -59,4 +60,11 @@ func TestMatcher(t *testing.T) {
 	} else if !equal(ms, "logging", "foo/bar/*") {
 		t.Fatal("not equal")
 	}
+
+	if s := m.Selectors("/foo/bar/x"); !reflect.DeepEqual(s, []string{"*", "/foo/bar/*"}) {
+		t.Fatalf("unexpected selectors %v", s)
+	}
+	if s := m.Selectors("/"); !reflect.DeepEqual(s, []string{"*"}) {
+		t.Fatalf("unexpected selectors %v", s)
+	}
 }
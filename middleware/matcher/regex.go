@@ -0,0 +1,58 @@
+package matcher
+
+import (
+	"regexp"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+type rule struct {
+	selector string
+	re       *regexp.Regexp
+	ms       []middleware.Middleware
+}
+
+type regexMatcher struct {
+	defaults []middleware.Middleware
+	rules    []rule
+}
+
+// NewRegex new a middleware matcher of the regular expression selectors, e.g.
+// "^/api\.v1\..*/(Create|Update)", the middleware of all the selectors matching
+// the operation are applied in the order of Add. It panics if a selector is not
+// a valid regular expression.
+func NewRegex() Matcher {
+	return &regexMatcher{}
+}
+
+func (m *regexMatcher) Use(ms ...middleware.Middleware) {
+	m.defaults = ms
+}
+
+func (m *regexMatcher) Add(selector string, ms ...middleware.Middleware) {
+	m.rules = append(m.rules, rule{selector: selector, re: regexp.MustCompile(selector), ms: ms})
+}
+
+func (m *regexMatcher) Match(operation string) []middleware.Middleware {
+	ms := make([]middleware.Middleware, 0, len(m.defaults))
+	ms = append(ms, m.defaults...)
+	for _, r := range m.rules {
+		if r.re.MatchString(operation) {
+			ms = append(ms, r.ms...)
+		}
+	}
+	return ms
+}
+
+func (m *regexMatcher) Selectors(operation string) []string {
+	var selectors []string
+	if len(m.defaults) > 0 {
+		selectors = append(selectors, "*")
+	}
+	for _, r := range m.rules {
+		if r.re.MatchString(operation) {
+			selectors = append(selectors, r.selector)
+		}
+	}
+	return selectors
+}
@@ -0,0 +1,23 @@
+package matcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegexMatcher(t *testing.T) {
+	m := NewRegex()
+	m.Use(logging("logging"))
+	m.Add(`^/api\.v1\.`, logging("v1"))
+	m.Add(`/(Create|Update)\w*$`, logging("write"))
+
+	if ms := m.Match("/api.v1.Users/CreateUser"); len(ms) != 3 || !equal(ms, "logging", "v1", "write") {
+		t.Fatal("expected the middleware of all the matching selectors in order")
+	}
+	if ms := m.Match("/api.v2.Users/GetUser"); len(ms) != 1 || !equal(ms, "logging") {
+		t.Fatal("expected the default middleware only")
+	}
+	if s := m.Selectors("/api.v2.Users/UpdateUser"); !reflect.DeepEqual(s, []string{"*", `/(Create|Update)\w*$`}) {
+		t.Errorf("unexpected selectors %v", s)
+	}
+}
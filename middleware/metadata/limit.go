@@ -0,0 +1,125 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metadata"
+)
+
+// Action is the action taken on the propagated metadata violating the limits.
+type Action int
+
+const (
+	// ActionTrim drops the violating entries and logs them.
+	ActionTrim Action = iota
+	// ActionReject fails the request with a BadRequest error.
+	ActionReject
+	// ActionLog logs the violations and propagates all the entries.
+	ActionLog
+)
+
+// Limits are the limits of the propagated metadata, a zero limit is unlimited.
+// The keys must be valid header tokens and the values must not contain control
+// characters, e.g. CR and LF, but the binary ones of the keys suffixed by "-bin".
+type Limits struct {
+	// MaxCount is the max number of the key value pairs.
+	MaxCount int
+	// MaxSize is the max total bytes of the keys and the values.
+	MaxSize int
+	// MaxValueSize is the max bytes of a value.
+	MaxValueSize int
+}
+
+// WithLimits with the limits of the propagated metadata and the action on the
+// violations, preventing the header bloat amplified across the service chains.
+// The entries are checked in the order of the keys.
+func WithLimits(limits Limits, action Action) Option {
+	return func(o *options) {
+		o.limits = &limits
+		o.action = action
+	}
+}
+
+// limit checks the metadata against the limits.
+func (o *options) limit(ctx context.Context, md metadata.Metadata) (metadata.Metadata, error) {
+	if o.limits == nil || len(md) == 0 {
+		return md, nil
+	}
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var (
+		out        = make(metadata.Metadata, len(md))
+		count      int
+		size       int
+		violations []string
+	)
+	for _, k := range keys {
+		for _, v := range md[k] {
+			var violation string
+			switch {
+			case !validKey(k):
+				violation = fmt.Sprintf("invalid key %q", k)
+			case !metadata.IsBinary(k) && !validValue(v):
+				violation = fmt.Sprintf("forbidden characters in the value of %q", k)
+			case o.limits.MaxValueSize > 0 && len(v) > o.limits.MaxValueSize:
+				violation = fmt.Sprintf("value of %q exceeds %d bytes", k, o.limits.MaxValueSize)
+			case o.limits.MaxCount > 0 && count >= o.limits.MaxCount:
+				violation = fmt.Sprintf("%q exceeds %d entries", k, o.limits.MaxCount)
+			case o.limits.MaxSize > 0 && size+len(k)+len(v) > o.limits.MaxSize:
+				violation = fmt.Sprintf("%q exceeds %d bytes", k, o.limits.MaxSize)
+			}
+			if violation != "" {
+				violations = append(violations, violation)
+				if o.action != ActionLog {
+					continue
+				}
+			}
+			count++
+			size += len(k) + len(v)
+			out[k] = append(out[k], v)
+		}
+	}
+	if len(violations) == 0 {
+		return out, nil
+	}
+	if o.action == ActionReject {
+		return nil, errors.BadRequest("METADATA_LIMIT", strings.Join(violations, "; "))
+	}
+	log.Context(ctx).Warnf("metadata violates the limits: %s", strings.Join(violations, "; "))
+	return out, nil
+}
+
+// validKey reports whether the key is a header token.
+func validKey(k string) bool {
+	if k == "" {
+		return false
+	}
+	for i := 0; i < len(k); i++ {
+		c := k[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			continue
+		}
+		if !strings.ContainsRune("!#$%&'*+-.^_`|~", rune(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// validValue reports whether the value has no control characters but tab.
+func validValue(v string) bool {
+	for i := 0; i < len(v); i++ {
+		if c := v[i]; c < ' ' && c != '\t' || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package metadata
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestServerLimits(t *testing.T) {
+	hc := headerCarrier{}
+	hc.Set("x-md-global-a", "1")
+	hc.Set("x-md-global-b", "2")
+	hc.Set("x-md-global-c", "3")
+	hc.Set("x-md-global-big", strings.Repeat("x", 64))
+	hc.Set("x-md-global-crlf", "a\r\nb")
+	ctx := transport.NewServerContext(context.Background(), &testTransport{hc})
+	limits := Limits{MaxCount: 2, MaxValueSize: 32}
+
+	var got metadata.Metadata
+	hs := func(ctx context.Context, in interface{}) (interface{}, error) {
+		got, _ = metadata.FromServerContext(ctx)
+		return in, nil
+	}
+	if _, err := Server(WithLimits(limits, ActionTrim))(hs)(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got.Get("x-md-global-a") != "1" || got.Get("x-md-global-b") != "2" {
+		t.Errorf("expected a and b, got %v", got)
+	}
+
+	if _, err := Server(WithLimits(limits, ActionLog))(hs)(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 5 {
+		t.Errorf("expected all the entries, got %v", got)
+	}
+
+	_, err := Server(WithLimits(limits, ActionReject))(hs)(ctx, nil)
+	if !errors.IsBadRequest(err) {
+		t.Errorf("expected BadRequest, got %v", err)
+	}
+}
+
+func TestClientLimits(t *testing.T) {
+	serverMD := metadata.New()
+	serverMD.Set("x-md-global-a", "1")
+	serverMD.Set("x-md-global-b", "2")
+	ctx := metadata.NewServerContext(context.Background(), serverMD)
+	ctx = metadata.NewClientContext(ctx, metadata.Metadata{"bad key": {"v"}})
+	hc := headerCarrier{}
+	ctx = transport.NewClientContext(ctx, &testTransport{hc})
+	hs := func(ctx context.Context, in interface{}) (interface{}, error) {
+		return in, nil
+	}
+	if _, err := Client(WithLimits(Limits{MaxSize: 16}, ActionTrim))(hs)(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if hc.Get("x-md-global-a") != "1" || hc.Get("x-md-global-b") != "" || len(hc) != 1 {
+		t.Errorf("expected only a, got %v", hc)
+	}
+}
@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"context"
+	"encoding/base64"
 	"strings"
 
 	"github.com/go-kratos/kratos/v2/metadata"
@@ -13,8 +14,19 @@ import (
 type Option func(*options)
 
 type options struct {
-	prefix []string
-	md     metadata.Metadata
+	prefix   []string
+	allow    []string
+	deny     []string
+	rewrites []rewrite
+	md       metadata.Metadata
+	limits   *Limits
+	action   Action
+}
+
+// rewrite is a rewrite of the key prefix.
+type rewrite struct {
+	from string
+	to   string
 }
 
 func (o *options) hasPrefix(key string) bool {
@@ -27,6 +39,75 @@ func (o *options) hasPrefix(key string) bool {
 	return false
 }
 
+// propagated reports whether the key is propagated, which is of the prefixes
+// or the allowlist, but not of the denylist.
+func (o *options) propagated(key string) bool {
+	k := strings.ToLower(key)
+	if matchKey(o.deny, k) {
+		return false
+	}
+	return o.hasPrefix(k) || matchKey(o.allow, k)
+}
+
+// rewrite returns the key of which the prefix is rewritten by the first rewrite matched.
+func (o *options) rewrite(key string) string {
+	k := strings.ToLower(key)
+	for _, r := range o.rewrites {
+		if strings.HasPrefix(k, r.from) {
+			return r.to + k[len(r.from):]
+		}
+	}
+	return k
+}
+
+// matchKey reports whether the key matches any of the patterns, which are
+// the keys or the prefixes suffixed by '*', e.g. "x-request-*".
+func matchKey(patterns []string, key string) bool {
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(key, p[:len(p)-1]) {
+				return true
+			}
+		} else if key == p {
+			return true
+		}
+	}
+	return false
+}
+
+// lowers returns the keys in lower case.
+func lowers(keys []string) []string {
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, strings.ToLower(k))
+	}
+	return out
+}
+
+// headerValue encodes the binary values of the HTTP headers in base64, which
+// are sent as is in the gRPC metadata.
+func headerValue(kind transport.Kind, key, value string) string {
+	if kind == transport.KindHTTP && metadata.IsBinary(key) {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	return value
+}
+
+// metadataValue decodes the binary values of the HTTP headers, it reports
+// false if the value is invalid.
+func metadataValue(kind transport.Kind, key, value string) (string, bool) {
+	if kind != transport.KindHTTP || !metadata.IsBinary(key) {
+		return value, true
+	}
+	b, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		if b, err = base64.RawStdEncoding.DecodeString(value); err != nil {
+			return "", false
+		}
+	}
+	return string(b), true
+}
+
 // WithConstants with constant metadata key value.
 func WithConstants(md metadata.Metadata) Option {
 	return func(o *options) {
@@ -41,7 +122,34 @@ func WithPropagatedPrefix(prefix ...string) Option {
 	}
 }
 
-// Server is middleware server-side metadata.
+// WithAllowlist with the keys propagated besides the ones of the prefixes,
+// e.g. "x-request-id", or the prefixes suffixed by '*', e.g. "x-b3-*".
+func WithAllowlist(keys ...string) Option {
+	return func(o *options) {
+		o.allow = append(o.allow, lowers(keys)...)
+	}
+}
+
+// WithDenylist with the keys never propagated, even of the prefixes or the
+// allowlist, e.g. "x-md-global-debug", or the prefixes suffixed by '*'.
+func WithDenylist(keys ...string) Option {
+	return func(o *options) {
+		o.deny = append(o.deny, lowers(keys)...)
+	}
+}
+
+// WithPrefixRewrite with the rewrite of the key prefix, from the headers to the
+// metadata on the server, e.g. of "x-global-" to "x-md-global-" of a legacy
+// service, and from the metadata to the headers on the client. The keys are
+// checked against the propagation policy after the rewrites.
+func WithPrefixRewrite(from, to string) Option {
+	return func(o *options) {
+		o.rewrites = append(o.rewrites, rewrite{from: strings.ToLower(from), to: strings.ToLower(to)})
+	}
+}
+
+// Server is middleware server-side metadata. The binary values of the keys
+// suffixed by "-bin" are decoded from base64 of the HTTP headers.
 func Server(opts ...Option) middleware.Middleware {
 	options := &options{
 		prefix: []string{"x-md-"}, // x-md-global-, x-md-local
@@ -56,22 +164,35 @@ func Server(opts ...Option) middleware.Middleware {
 				return handler(ctx, req)
 			}
 
-			md := options.md.Clone()
+			in := metadata.New()
 			header := tr.RequestHeader()
-			for _, k := range header.Keys() {
-				if options.hasPrefix(k) {
-					for _, v := range header.Values(k) {
-						md.Add(k, v)
+			for _, hk := range header.Keys() {
+				k := options.rewrite(hk)
+				if !options.propagated(k) {
+					continue
+				}
+				for _, v := range header.Values(hk) {
+					if v, ok := metadataValue(tr.Kind(), k, v); ok {
+						in.Add(k, v)
 					}
 				}
 			}
+			in, err = options.limit(ctx, in)
+			if err != nil {
+				return nil, err
+			}
+			md := options.md.Clone()
+			for k, vList := range in {
+				md[k] = append(md[k], vList...)
+			}
 			ctx = metadata.NewServerContext(ctx, md)
 			return handler(ctx, req)
 		}
 	}
 }
 
-// Client is middleware client-side metadata.
+// Client is middleware client-side metadata. The binary values of the keys
+// suffixed by "-bin" are encoded in base64 of the HTTP headers.
 func Client(opts ...Option) middleware.Middleware {
 	options := &options{
 		prefix: []string{"x-md-global-"},
@@ -89,27 +210,39 @@ func Client(opts ...Option) middleware.Middleware {
 			header := tr.RequestHeader()
 			// x-md-local-
 			for k, vList := range options.md {
+				k = options.rewrite(k)
 				for _, v := range vList {
-					header.Add(k, v)
+					header.Add(k, headerValue(tr.Kind(), k, v))
 				}
 			}
+			out := metadata.New()
 			if md, ok := metadata.FromClientContext(ctx); ok {
 				for k, vList := range md {
 					for _, v := range vList {
-						header.Add(k, v)
+						out.Add(k, v)
 					}
 				}
 			}
 			// x-md-global-
 			if md, ok := metadata.FromServerContext(ctx); ok {
 				for k, vList := range md {
-					if options.hasPrefix(k) {
+					if options.propagated(k) {
 						for _, v := range vList {
-							header.Add(k, v)
+							out.Add(k, v)
 						}
 					}
 				}
 			}
+			out, err = options.limit(ctx, out)
+			if err != nil {
+				return nil, err
+			}
+			for k, vList := range out {
+				hk := options.rewrite(k)
+				for _, v := range vList {
+					header.Add(hk, headerValue(tr.Kind(), hk, v))
+				}
+			}
 			return handler(ctx, req)
 		}
 	}
@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"net/http"
 	"reflect"
@@ -177,3 +178,45 @@ func TestOptions_hasPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestPropagationPolicy(t *testing.T) {
+	header := headerCarrier{}
+	header.Set("x-global-tenant", "t1")
+	header.Set("x-md-global-debug", "true")
+	header.Set("x-md-global-token-bin", base64.StdEncoding.EncodeToString([]byte{0, 1, 2}))
+	header.Set("x-request-id", "r1")
+	header.Set("x-other", "o1")
+	opts := []Option{
+		WithPrefixRewrite("x-global-", "x-md-global-"),
+		WithAllowlist("X-Request-*"),
+		WithDenylist("x-md-global-debug"),
+	}
+	var md metadata.Metadata
+	h := Server(opts...)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		md, _ = metadata.FromServerContext(ctx)
+		return nil, nil
+	})
+	if _, err := h(transport.NewServerContext(context.Background(), &testTransport{header: header}), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := metadata.Metadata{
+		"x-md-global-tenant":    {"t1"},
+		"x-md-global-token-bin": {"\x00\x01\x02"},
+		"x-request-id":          {"r1"},
+	}
+	if !reflect.DeepEqual(md, want) {
+		t.Errorf("expected %v got %v", want, md)
+	}
+
+	out := headerCarrier{}
+	c := Client(WithPrefixRewrite("x-md-global-", "x-global-"))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	ctx := metadata.NewServerContext(context.Background(), md)
+	if _, err := c(transport.NewClientContext(ctx, &testTransport{header: out}), nil); err != nil {
+		t.Fatal(err)
+	}
+	if out.Get("x-global-tenant") != "t1" || out.Get("x-global-token-bin") != "AAEC" || out.Get("x-request-id") != "" {
+		t.Errorf("unexpected header %v", out)
+	}
+}
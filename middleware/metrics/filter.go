@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/internal/httputil"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Filter is an HTTP server filter counting the response bytes on the wire and
+// before compression per operation and tenant, e.g. for the egress cost. It must
+// wrap the compression filter to count the compressed bytes on the wire. The
+// latencies of the requests are observed by the histogram of WithRequestDuration,
+// including the ones not served by the kratos routes.
+func Filter(opts ...Option) khttp.FilterFunc {
+	op := options{
+		tenant: func(req *http.Request) string { return req.Header.Get("X-Tenant") },
+	}
+	for _, o := range opts {
+		o(&op)
+	}
+	return func(next http.Handler) http.Handler {
+		if op.responseBytes == nil && op.requestDuration == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			req, operation := khttp.RecordOperation(req)
+			req, route := khttp.RecordRoute(req)
+			req, uncompressed := khttp.RecordUncompressedSize(req)
+			rw := &countWriter{ResponseWriter: w, code: http.StatusOK}
+			next.ServeHTTP(rw, req)
+			if op.requestDuration != nil {
+				op.requestDuration.With(req.Method, route(), strconv.Itoa(rw.code), httputil.ProtocolVersion(req.ProtoMajor, req.ProtoMinor)).
+					Observe(time.Since(start).Seconds())
+			}
+			if op.responseBytes == nil {
+				return
+			}
+			encoding := rw.Header().Get("Content-Encoding")
+			size := uncompressed()
+			if size < 0 {
+				size = rw.size
+			}
+			tenant := op.tenant(req)
+			op.responseBytes.With(operation(), tenant, encoding, "wire").Add(float64(rw.size))
+			op.responseBytes.With(operation(), tenant, encoding, "uncompressed").Add(float64(size))
+		})
+	}
+}
+
+type countWriter struct {
+	http.ResponseWriter
+	size        int64
+	code        int
+	wroteHeader bool
+}
+
+func (w *countWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.code, w.wroteHeader = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *countWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *countWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *countWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"context"
+	"net/http"
 	"strconv"
 	"time"
 
@@ -28,11 +29,62 @@ func WithSeconds(c metrics.Observer) Option {
 	}
 }
 
+// WithErrors with errors counter, the errors are counted by their errors.Class.
+func WithErrors(c metrics.Counter) Option {
+	return func(o *options) {
+		o.errors = c
+	}
+}
+
+// WithResponseBytes with response bytes counter of the HTTP Filter.
+func WithResponseBytes(c metrics.Counter) Option {
+	return func(o *options) {
+		o.responseBytes = c
+	}
+}
+
+// WithRequestDuration with the request latency histogram of the HTTP Filter
+// and Transport, whose labels are of the HTTP requests, e.g. the protocol
+// versions lost by the middleware.
+func WithRequestDuration(c metrics.Observer) Option {
+	return func(o *options) {
+		o.requestDuration = c
+	}
+}
+
+// WithHandshakeDuration with the handshake latency histogram of the HTTP
+// Transport, of the TCP connections and the TLS handshakes of them.
+func WithHandshakeDuration(c metrics.Observer) Option {
+	return func(o *options) {
+		o.handshakeDuration = c
+	}
+}
+
+// WithTenant with the func returning the tenant of the requests counted by
+// the HTTP Filter, default is the X-Tenant request header.
+func WithTenant(fn func(req *http.Request) string) Option {
+	return func(o *options) {
+		o.tenant = fn
+	}
+}
+
 type options struct {
 	// counter: <client/server>_requests_code_total{kind, operation, code, reason}
 	requests metrics.Counter
+	// counter: <client/server>_requests_errors_total{kind, operation, class}
+	errors metrics.Counter
 	// histogram: <client/server>_requests_seconds_bucket{kind, operation}
 	seconds metrics.Observer
+	// counter: server_response_bytes_total{operation, tenant, encoding, size}
+	// the size is "wire" or "uncompressed".
+	responseBytes metrics.Counter
+	// histogram: <client/server>_http_request_duration_seconds_bucket{method, route, code, protocol}
+	// the route is the host of the Transport.
+	requestDuration metrics.Observer
+	// histogram: client_http_handshake_duration_seconds_bucket{host, phase}
+	// the phase is "connect" or "tls".
+	handshakeDuration metrics.Observer
+	tenant            func(req *http.Request) string
 }
 
 // Server is middleware server-side metrics.
@@ -62,6 +114,9 @@ func Server(opts ...Option) middleware.Middleware {
 			if op.requests != nil {
 				op.requests.With(kind, operation, strconv.Itoa(code), reason).Inc()
 			}
+			if op.errors != nil && err != nil {
+				op.errors.With(kind, operation, errors.Classify(err).String()).Inc()
+			}
 			if op.seconds != nil {
 				op.seconds.With(kind, operation).Observe(time.Since(startTime).Seconds())
 			}
@@ -97,6 +152,9 @@ func Client(opts ...Option) middleware.Middleware {
 			if op.requests != nil {
 				op.requests.With(kind, operation, strconv.Itoa(code), reason).Inc()
 			}
+			if op.errors != nil && err != nil {
+				op.errors.With(kind, operation, errors.Classify(err).String()).Inc()
+			}
 			if op.seconds != nil {
 				op.seconds.With(kind, operation).Observe(time.Since(startTime).Seconds())
 			}
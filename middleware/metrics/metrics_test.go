@@ -3,9 +3,15 @@ package metrics
 import (
 	"context"
 	"errors"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 	"github.com/go-kratos/kratos/v2/transport/http"
 )
@@ -154,3 +160,159 @@ func TestClient(t *testing.T) {
 		t.Error(`The server must return a "Hello valid" response.`)
 	}
 }
+
+type labelCounter struct {
+	values map[string]float64
+	lvs    []string
+}
+
+func (m *labelCounter) With(lvs ...string) metrics.Counter {
+	return &labelCounter{values: m.values, lvs: lvs}
+}
+
+func (m *labelCounter) Inc() {
+	m.Add(1)
+}
+
+func (m *labelCounter) Add(delta float64) {
+	m.values[strings.Join(m.lvs, ",")] += delta
+}
+
+func TestWithErrors(t *testing.T) {
+	counter := &labelCounter{values: make(map[string]float64)}
+	handler := func(err error) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, err
+		}
+	}
+	ctx := transport.NewServerContext(context.Background(), &http.Transport{})
+	for _, err := range []error{nil, kratoserrors.ServiceUnavailable("", ""), kratoserrors.BadRequest("", ""), kratoserrors.New(429, "", "")} {
+		_, _ = Server(WithErrors(counter))(handler(err))(ctx, nil)
+	}
+	want := map[string]float64{"http,,transient": 1, "http,,permanent": 1, "http,,throttled": 1}
+	if !reflect.DeepEqual(counter.values, want) {
+		t.Errorf("expected %v got %v", want, counter.values)
+	}
+}
+
+type bytesCounter struct {
+	values map[string]float64
+	lvs    []string
+}
+
+func (c *bytesCounter) With(lvs ...string) metrics.Counter {
+	return &bytesCounter{values: c.values, lvs: lvs}
+}
+
+func (c *bytesCounter) Inc() {
+	c.Add(1)
+}
+
+func (c *bytesCounter) Add(delta float64) {
+	c.values[strings.Join(c.lvs, ",")] += delta
+}
+
+func TestFilter(t *testing.T) {
+	c := &bytesCounter{values: make(map[string]float64)}
+	srv := http.NewServer(http.Filter(Filter(WithResponseBytes(c)), compressFilter))
+	srv.Route("/").GET("/users/{id}", func(ctx http.Context) error {
+		http.SetOperation(ctx, "/users.v1.Users/GetUser")
+		return ctx.String(200, strings.Repeat("kratos", 100))
+	})
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	req.Header.Set("X-Tenant", "acme")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+	if got := c.values["/users.v1.Users/GetUser,acme,identity,uncompressed"]; got != 600 {
+		t.Errorf("expected 600 uncompressed bytes got %v in %v", got, c.values)
+	}
+	if got := c.values["/users.v1.Users/GetUser,acme,identity,wire"]; got != 300 {
+		t.Errorf("expected 300 wire bytes got %v in %v", got, c.values)
+	}
+}
+
+// compressFilter halves the response body, like a compression filter.
+func compressFilter(next stdhttp.Handler) stdhttp.Handler {
+	return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, req *stdhttp.Request) {
+		w.Header().Set("Content-Encoding", "identity")
+		next.ServeHTTP(halfWriter{ResponseWriter: w, req: req}, req)
+	})
+}
+
+type halfWriter struct {
+	stdhttp.ResponseWriter
+	req *stdhttp.Request
+}
+
+func (w halfWriter) Write(b []byte) (int, error) {
+	http.AddUncompressedSize(w.req.Context(), int64(len(b)))
+	_, err := w.ResponseWriter.Write(b[:len(b)/2])
+	return len(b), err
+}
+
+func TestObserver(t *testing.T) {
+	counter := &labelCounter{values: make(map[string]float64)}
+	seconds := &mockObserver{}
+	deny := func(middleware.Handler) middleware.Handler {
+		return func(context.Context, interface{}) (interface{}, error) {
+			return nil, kratoserrors.Unauthorized("", "")
+		}
+	}
+	ctx := transport.NewServerContext(context.Background(), &http.Transport{})
+	ms := middleware.Instrument(Observer(WithSeconds(seconds), WithErrors(counter)), Server(), deny)
+	_, _ = middleware.Chain(ms...)(func(context.Context, interface{}) (interface{}, error) { return nil, nil })(ctx, nil)
+	want := map[string]float64{"http,,metrics.TestObserver,permanent": 1}
+	if !reflect.DeepEqual(counter.values, want) {
+		t.Errorf("expected %v got %v", want, counter.values)
+	}
+	if seconds.value <= 0 {
+		t.Errorf("expected the seconds of the middleware, got %v", seconds.value)
+	}
+}
+
+type labelObserver struct {
+	values map[string]int
+	lvs    []string
+}
+
+func (o *labelObserver) With(lvs ...string) metrics.Observer {
+	return &labelObserver{values: o.values, lvs: lvs}
+}
+
+func (o *labelObserver) Observe(float64) {
+	o.values[strings.Join(o.lvs, ",")]++
+}
+
+func TestFilterRequestDuration(t *testing.T) {
+	o := &labelObserver{values: make(map[string]int)}
+	srv := http.NewServer(http.Filter(Filter(WithRequestDuration(o))))
+	srv.Route("/").GET("/users/{id}", func(ctx http.Context) error {
+		return ctx.String(201, "")
+	})
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+	want := map[string]int{"GET,/users/{id},201,1.1": 1}
+	if !reflect.DeepEqual(o.values, want) {
+		t.Errorf("expected %v got %v", want, o.values)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, _ *stdhttp.Request) {
+		w.WriteHeader(stdhttp.StatusAccepted)
+	}))
+	defer srv.Close()
+	requests := &labelObserver{values: make(map[string]int)}
+	handshakes := &labelObserver{values: make(map[string]int)}
+	client := &stdhttp.Client{Transport: Transport(srv.Client().Transport, WithRequestDuration(requests), WithHandshakeDuration(handshakes))}
+	res, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	if want := map[string]int{"GET," + host + ",202,1.1": 1}; !reflect.DeepEqual(requests.values, want) {
+		t.Errorf("expected %v got %v", want, requests.values)
+	}
+	if want := map[string]int{host + ",connect": 1, host + ",tls": 1}; !reflect.DeepEqual(handshakes.values, want) {
+		t.Errorf("expected %v got %v", want, handshakes.values)
+	}
+}
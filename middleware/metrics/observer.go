@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Observer returns the observer of the middleware of the servers, e.g. of
+// the MiddlewareObserver option of the HTTP and the gRPC servers, recording
+// the durations of each middleware excluding the next handlers by the
+// histogram of WithSeconds:
+//
+//	server_middleware_seconds_bucket{kind, operation, middleware}
+//
+// and the errors returned by them by the counter of WithErrors:
+//
+//	server_middleware_errors_total{kind, operation, middleware, class}
+func Observer(opts ...Option) middleware.Observer {
+	op := options{}
+	for _, o := range opts {
+		o(&op)
+	}
+	return func(ctx context.Context, name string) (context.Context, func(time.Duration, error)) {
+		var kind, operation string
+		if info, ok := transport.FromServerContext(ctx); ok {
+			kind = info.Kind().String()
+			operation = info.Operation()
+		}
+		return ctx, func(self time.Duration, err error) {
+			if op.seconds != nil {
+				op.seconds.With(kind, operation, name).Observe(self.Seconds())
+			}
+			if op.errors != nil && err != nil {
+				op.errors.With(kind, operation, name, errors.Classify(err).String()).Inc()
+			}
+		}
+	}
+}
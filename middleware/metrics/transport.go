@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/internal/httputil"
+)
+
+// Transport returns the round tripper observing the latencies of the requests
+// by the histogram of WithRequestDuration, and of the TCP connections and the
+// TLS handshakes of them by the one of WithHandshakeDuration, e.g. of the
+// WithTransport option of the HTTP client. The base is http.DefaultTransport
+// if it is nil.
+func Transport(base http.RoundTripper, opts ...Option) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	op := options{}
+	for _, o := range opts {
+		o(&op)
+	}
+	return roundTripper(func(req *http.Request) (*http.Response, error) {
+		if op.handshakeDuration != nil {
+			var connectStart, tlsStart time.Time
+			host := req.URL.Host
+			req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+				ConnectStart: func(string, string) { connectStart = time.Now() },
+				ConnectDone: func(_, _ string, err error) {
+					if err == nil && !connectStart.IsZero() {
+						op.handshakeDuration.With(host, "connect").Observe(time.Since(connectStart).Seconds())
+					}
+				},
+				TLSHandshakeStart: func() { tlsStart = time.Now() },
+				TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+					if err == nil && !tlsStart.IsZero() {
+						op.handshakeDuration.With(host, "tls").Observe(time.Since(tlsStart).Seconds())
+					}
+				},
+			}))
+		}
+		start := time.Now()
+		res, err := base.RoundTrip(req)
+		if op.requestDuration != nil {
+			code, protocol := "", ""
+			if res != nil {
+				code, protocol = strconv.Itoa(res.StatusCode), httputil.ProtocolVersion(res.ProtoMajor, res.ProtoMinor)
+			}
+			op.requestDuration.With(req.Method, req.URL.Host, code, protocol).Observe(time.Since(start).Seconds())
+		}
+		return res, err
+	})
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
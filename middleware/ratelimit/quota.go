@@ -0,0 +1,213 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// QuotaOption is quota limiter option.
+type QuotaOption func(*Quota)
+
+// WithTenant with the func which returns the tenant of the request,
+// default is the X-Tenant request header.
+func WithTenant(fn func(ctx context.Context) string) QuotaOption {
+	return func(q *Quota) {
+		q.tenant = fn
+	}
+}
+
+// WithOperationQuota with the quota of the operation, which overrides the default quota.
+func WithOperationQuota(operation string, rate float64, burst int) QuotaOption {
+	return func(q *Quota) {
+		q.operations[operation] = limit{rate: rate, burst: burst}
+	}
+}
+
+type limit struct {
+	rate  float64
+	burst int
+}
+
+// QuotaState is the limiter state of a tenant and operation.
+type QuotaState struct {
+	Tenant    string    `json:"tenant"`
+	Operation string    `json:"operation"`
+	Rate      float64   `json:"rate"`
+	Burst     int       `json:"burst"`
+	Remaining float64   `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+	Allowed   uint64    `json:"allowed"`
+	Rejected  uint64    `json:"rejected"`
+}
+
+type quotaKey struct {
+	tenant    string
+	operation string
+}
+
+type quotaBucket struct {
+	limit
+	tokens   float64
+	last     time.Time
+	allowed  uint64
+	rejected uint64
+}
+
+// refill adds the tokens generated since the last update.
+func (b *quotaBucket) refill(now time.Time) {
+	b.tokens = math.Min(float64(b.burst), b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// reset returns the time when the bucket is full again.
+func (b *quotaBucket) reset() time.Time {
+	missing := float64(b.burst) - b.tokens
+	if missing <= 0 || b.rate <= 0 {
+		return b.last
+	}
+	return b.last.Add(time.Duration(missing / b.rate * float64(time.Second)))
+}
+
+// Quota is a token bucket limiter per tenant and operation,
+// whose state can be inspected for the throttling dashboards.
+type Quota struct {
+	limit
+	tenant     func(ctx context.Context) string
+	operations map[string]limit
+	now        func() time.Time
+
+	mu      sync.Mutex
+	buckets map[quotaKey]*quotaBucket
+	swept   time.Time
+}
+
+// NewQuota creates a quota limiter with the default rate and burst of a tenant and operation.
+func NewQuota(rate float64, burst int, opts ...QuotaOption) *Quota {
+	q := &Quota{
+		limit:      limit{rate: rate, burst: burst},
+		tenant:     tenantFromHeader,
+		operations: make(map[string]limit),
+		now:        time.Now,
+		buckets:    make(map[quotaKey]*quotaBucket),
+	}
+	for _, o := range opts {
+		o(q)
+	}
+	return q
+}
+
+func tenantFromHeader(ctx context.Context) string {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		return tr.RequestHeader().Get("X-Tenant")
+	}
+	return ""
+}
+
+// Allow reports whether a request of the tenant and operation may proceed.
+func (q *Quota) Allow(tenant, operation string) bool {
+	now := q.now()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.sweep(now)
+	key := quotaKey{tenant: tenant, operation: operation}
+	b, ok := q.buckets[key]
+	if !ok {
+		l, ok := q.operations[operation]
+		if !ok {
+			l = q.limit
+		}
+		b = &quotaBucket{limit: l, tokens: float64(l.burst), last: now}
+		q.buckets[key] = b
+	}
+	b.refill(now)
+	if b.tokens < 1 {
+		b.rejected++
+		return false
+	}
+	b.tokens--
+	b.allowed++
+	return true
+}
+
+// sweep removes the buckets which have been refilled, at most once a minute.
+func (q *Quota) sweep(now time.Time) {
+	if now.Sub(q.swept) < time.Minute {
+		return
+	}
+	q.swept = now
+	for key, b := range q.buckets {
+		if !now.Before(b.reset()) {
+			delete(q.buckets, key)
+		}
+	}
+}
+
+// States returns the current states of the tenant, or of all tenants if the tenant is empty,
+// sorted by tenant and operation.
+func (q *Quota) States(tenant string) []QuotaState {
+	now := q.now()
+	q.mu.Lock()
+	states := make([]QuotaState, 0, len(q.buckets))
+	for key, b := range q.buckets {
+		if tenant != "" && key.tenant != tenant {
+			continue
+		}
+		b.refill(now)
+		states = append(states, QuotaState{
+			Tenant:    key.tenant,
+			Operation: key.operation,
+			Rate:      b.rate,
+			Burst:     b.burst,
+			Remaining: b.tokens,
+			Reset:     b.reset(),
+			Allowed:   b.allowed,
+			Rejected:  b.rejected,
+		})
+	}
+	q.mu.Unlock()
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Tenant != states[j].Tenant {
+			return states[i].Tenant < states[j].Tenant
+		}
+		return states[i].Operation < states[j].Operation
+	})
+	return states
+}
+
+// Middleware returns the server middleware which rejects the requests
+// exceeding the quota with ErrLimitExceed.
+func (q *Quota) Middleware() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var operation string
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				operation = tr.Operation()
+			}
+			if !q.Allow(q.tenant(ctx), operation) {
+				return nil, ErrLimitExceed
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Handler returns the admin handler which returns the quota states in JSON,
+// the tenant query filters the states of a tenant.
+func (q *Quota) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(q.States(req.URL.Query().Get("tenant")))
+	})
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type testTransport struct {
+	operation string
+	header    http.Header
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return tr.operation }
+func (tr *testTransport) RequestHeader() transport.Header { return headerCarrier(tr.header) }
+func (tr *testTransport) ReplyHeader() transport.Header   { return headerCarrier{} }
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string      { return http.Header(hc).Get(key) }
+func (hc headerCarrier) Set(key, value string)      { http.Header(hc).Set(key, value) }
+func (hc headerCarrier) Add(key, value string)      { http.Header(hc).Add(key, value) }
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range hc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestQuota(t *testing.T) {
+	now := time.Unix(1000, 0)
+	q := NewQuota(1, 2, WithOperationQuota("/login", 0.5, 1))
+	q.now = func() time.Time { return now }
+
+	for i, want := range []bool{true, true, false} {
+		if got := q.Allow("a", "/list"); got != want {
+			t.Errorf("request %d: expected %v got %v", i, want, got)
+		}
+	}
+	if !q.Allow("b", "/list") {
+		t.Error("expected tenants to have separate quotas")
+	}
+	if !q.Allow("a", "/login") || q.Allow("a", "/login") {
+		t.Error("expected the operation quota of burst 1")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	states := q.States("a")
+	if len(states) != 2 {
+		t.Fatalf("expected 2 states got %v", states)
+	}
+	list, login := states[0], states[1]
+	if login.Operation != "/login" || login.Remaining != 0.25 || login.Reset != now.Add(1500*time.Millisecond) {
+		t.Errorf("unexpected login state %+v", login)
+	}
+	if list.Operation != "/list" || list.Remaining != 0.5 || list.Allowed != 2 || list.Rejected != 1 {
+		t.Errorf("unexpected list state %+v", list)
+	}
+	if n := len(q.States("")); n != 3 {
+		t.Errorf("expected 3 states got %d", n)
+	}
+
+	// the refilled buckets are swept.
+	now = now.Add(time.Minute)
+	q.Allow("b", "/list")
+	if n := len(q.States("")); n != 1 {
+		t.Errorf("expected 1 state after sweep got %d", n)
+	}
+}
+
+func TestQuotaMiddleware(t *testing.T) {
+	q := NewQuota(0, 1)
+	h := q.Middleware()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	ctx := transport.NewServerContext(context.Background(), &testTransport{
+		operation: "/hello",
+		header:    http.Header{"X-Tenant": []string{"a"}},
+	})
+	if _, err := h(ctx, nil); err != nil {
+		t.Fatalf("expected allowed got %v", err)
+	}
+	if _, err := h(ctx, nil); err != ErrLimitExceed {
+		t.Fatalf("expected %v got %v", ErrLimitExceed, err)
+	}
+
+	res := httptest.NewRecorder()
+	q.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/debug/quota?tenant=a", nil))
+	var states []QuotaState
+	if err := json.NewDecoder(res.Body).Decode(&states); err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 || states[0].Tenant != "a" || states[0].Operation != "/hello" || states[0].Rejected != 1 {
+		t.Errorf("unexpected states %+v", states)
+	}
+	res = httptest.NewRecorder()
+	q.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/debug/quota", nil))
+	if res.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 got %d", res.Code)
+	}
+}
@@ -0,0 +1,156 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/peer"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// KeyFunc returns the rate limit key of the request.
+type KeyFunc func(ctx context.Context) string
+
+// KeyOperation is the operation of the request.
+func KeyOperation(ctx context.Context) string {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		return tr.Operation()
+	}
+	return ""
+}
+
+// KeyHeader returns the KeyFunc of the request header, e.g. an API key.
+func KeyHeader(key string) KeyFunc {
+	return func(ctx context.Context) string {
+		if tr, ok := transport.FromServerContext(ctx); ok {
+			return tr.RequestHeader().Get(key)
+		}
+		return ""
+	}
+}
+
+// KeyClientIP is the IP of the HTTP client, forwarded by the trusted proxies
+// of the server if any, or of the gRPC peer.
+func KeyClientIP(ctx context.Context) string {
+	var addr string
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if ht, ok := tr.(*http.Transport); ok && ht.ClientIP() != "" {
+			return ht.ClientIP()
+		}
+		if ht, ok := tr.(http.Transporter); ok {
+			addr = ht.Request().RemoteAddr
+		}
+	}
+	if addr == "" {
+		if p, ok := peer.FromContext(ctx); ok {
+			addr = p.Addr.String()
+		}
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// RateOption is rate limit middleware option.
+type RateOption func(*rateOptions)
+
+type rateOptions struct {
+	key     KeyFunc
+	store   Store
+	prefix  string
+	headers bool
+	rule    func() Rule
+}
+
+// WithKey with the key of the requests sharing a quota, default is KeyOperation.
+func WithKey(fn KeyFunc) RateOption {
+	return func(o *rateOptions) {
+		o.key = fn
+	}
+}
+
+// WithStore with the store of the rate limit states, default is a MemoryStore.
+func WithStore(s Store) RateOption {
+	return func(o *rateOptions) {
+		o.store = s
+	}
+}
+
+// WithPrefix with the prefix of the keys in the store, default is "ratelimit",
+// the middlewares of the different rules sharing a store need their own prefixes.
+func WithPrefix(prefix string) RateOption {
+	return func(o *rateOptions) {
+		o.prefix = prefix
+	}
+}
+
+// WithRateHeaders with whether the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset reply headers are set, default is true.
+func WithRateHeaders(enabled bool) RateOption {
+	return func(o *rateOptions) {
+		o.headers = enabled
+	}
+}
+
+// WithRule with the func of the current rule, e.g. of a watched config key by
+// config.NewVar, overriding the rule of Rate.
+func WithRule(fn func() Rule) RateOption {
+	return func(o *rateOptions) {
+		o.rule = fn
+	}
+}
+
+// Rate is a server middleware limiting the requests of a key under the rule,
+// those exceeding it are rejected with ErrLimitExceed and Retry-After. The
+// rules of the routes are configured by the selector middleware, e.g.
+//
+//	selector.Server(ratelimit.Rate(rule, ratelimit.WithKey(ratelimit.KeyClientIP))).Path("/api.v1.Login/Login").Build()
+//
+// The requests are allowed if the store fails.
+func Rate(rule Rule, opts ...RateOption) middleware.Middleware {
+	o := &rateOptions{key: KeyOperation, prefix: "ratelimit", headers: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.store == nil {
+		o.store = NewMemoryStore()
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			r := rule
+			if o.rule != nil {
+				r = o.rule()
+			}
+			res, err := o.store.Take(ctx, o.prefix+":"+o.key(ctx), r)
+			if err != nil {
+				log.Context(ctx).Errorf("ratelimit: failed to take the request: %v", err)
+				return handler(ctx, req)
+			}
+			if tr, ok := transport.FromServerContext(ctx); ok && o.headers {
+				header := tr.ReplyHeader()
+				header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+				header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+				header.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+				if !res.Allowed {
+					header.Set("Retry-After", ceilSeconds(res.RetryAfter))
+				}
+			}
+			if !res.Allowed {
+				return nil, ErrLimitExceed
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type replyTransport struct {
+	testTransport
+	reply http.Header
+}
+
+func (tr *replyTransport) ReplyHeader() transport.Header { return headerCarrier(tr.reply) }
+
+func TestMemoryStoreTokenBucket(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	rule := Rule{Limit: 2, Window: time.Second, Burst: 3}
+	for i, want := range []bool{true, true, true, false} {
+		res, _ := s.Take(context.Background(), "a", rule)
+		if res.Allowed != want {
+			t.Errorf("request %d: expected %v got %v", i, want, res.Allowed)
+		}
+		if !res.Allowed && res.RetryAfter != 500*time.Millisecond {
+			t.Errorf("expected retry after 500ms got %v", res.RetryAfter)
+		}
+	}
+	now = now.Add(500 * time.Millisecond)
+	if res, _ := s.Take(context.Background(), "a", rule); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("expected a refilled token, got %+v", res)
+	}
+}
+
+func TestMemoryStoreSlidingWindow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	rule := Rule{Algorithm: SlidingWindow, Limit: 4, Window: time.Second}
+	for i := 0; i < 4; i++ {
+		if res, _ := s.Take(context.Background(), "a", rule); !res.Allowed {
+			t.Fatalf("request %d: expected allowed", i)
+		}
+	}
+	if res, _ := s.Take(context.Background(), "a", rule); res.Allowed || res.Remaining != 0 {
+		t.Errorf("expected rejected, got %+v", res)
+	}
+	// a quarter into the next window, the previous count weights 3.
+	now = now.Add(1250 * time.Millisecond)
+	if res, _ := s.Take(context.Background(), "a", rule); !res.Allowed {
+		t.Errorf("expected allowed, got %+v", res)
+	}
+	if res, _ := s.Take(context.Background(), "a", rule); res.Allowed {
+		t.Errorf("expected rejected, got %+v", res)
+	}
+	now = now.Add(2 * time.Second)
+	if res, _ := s.Take(context.Background(), "a", rule); !res.Allowed || res.Remaining != 3 {
+		t.Errorf("expected a fresh window, got %+v", res)
+	}
+}
+
+func TestRate(t *testing.T) {
+	m := Rate(Rule{Limit: 1, Window: time.Minute}, WithKey(KeyHeader("X-API-Key")))
+	h := m(func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil })
+	call := func(key string) (http.Header, error) {
+		tr := &replyTransport{
+			testTransport: testTransport{operation: "/test", header: http.Header{"X-Api-Key": []string{key}}},
+			reply:         http.Header{},
+		}
+		_, err := h(transport.NewServerContext(context.Background(), tr), nil)
+		return tr.reply, err
+	}
+	reply, err := call("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Get("RateLimit-Limit") != "1" || reply.Get("RateLimit-Remaining") != "0" || reply.Get("RateLimit-Reset") != "60" {
+		t.Errorf("unexpected rate limit headers %v", reply)
+	}
+	if reply, err = call("a"); !errors.Is(err, ErrLimitExceed) || reply.Get("Retry-After") != "60" {
+		t.Errorf("expected the limit exceeded with Retry-After, got %v %v", err, reply)
+	}
+	if _, err = call("b"); err != nil {
+		t.Errorf("expected the keys to have separate quotas, got %v", err)
+	}
+}
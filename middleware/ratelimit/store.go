@@ -0,0 +1,166 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Algorithm is the rate limit algorithm of a rule.
+type Algorithm int
+
+const (
+	// TokenBucket refills the bucket at Limit per Window up to Burst tokens,
+	// every request takes a token.
+	TokenBucket Algorithm = iota
+	// SlidingWindow allows Limit requests in any Window, weighting the count
+	// of the previous fixed window by its overlap with the sliding one.
+	SlidingWindow
+)
+
+// Rule is a rate limit rule of Limit requests per Window.
+type Rule struct {
+	Algorithm Algorithm
+	Limit     int
+	Window    time.Duration
+	// Burst is the bucket size of TokenBucket, default is Limit.
+	Burst int
+}
+
+func (r Rule) burst() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Limit
+}
+
+// Result is the result of taking a request under a rule.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the quota is fully available again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed if it is rejected.
+	RetryAfter time.Duration
+}
+
+// Store stores the rate limit states of the keys, e.g. MemoryStore for a
+// single instance, or a Redis store shared by the instances.
+type Store interface {
+	// Take takes a request of the key under the rule.
+	Take(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+type memoryState struct {
+	// tokens and last are of the token bucket.
+	tokens float64
+	last   time.Time
+	// start, count and prev are of the sliding window.
+	start time.Time
+	count int
+	prev  int
+}
+
+// MemoryStore is an in-memory Store of a single instance.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]*memoryState
+	swept  time.Time
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewMemoryStore new an in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]*memoryState), now: time.Now}
+}
+
+// Take takes a request of the key under the rule.
+func (s *MemoryStore) Take(_ context.Context, key string, rule Rule) (Result, error) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if 2*rule.Window > s.ttl {
+		s.ttl = 2 * rule.Window
+	}
+	s.sweep(now)
+	st, ok := s.states[key]
+	if !ok {
+		st = &memoryState{tokens: float64(rule.burst()), last: now, start: now}
+		s.states[key] = st
+	}
+	if rule.Algorithm == SlidingWindow {
+		return st.slide(now, rule), nil
+	}
+	return st.take(now, rule), nil
+}
+
+// sweep removes the states idle for the longest window twice, at most once a minute.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+	for key, st := range s.states {
+		if now.Sub(st.last) > s.ttl {
+			delete(s.states, key)
+		}
+	}
+}
+
+func (st *memoryState) take(now time.Time, rule Rule) Result {
+	rate := float64(rule.Limit) / rule.Window.Seconds()
+	burst := float64(rule.burst())
+	st.tokens = math.Min(burst, st.tokens+now.Sub(st.last).Seconds()*rate)
+	st.last = now
+	res := Result{Limit: rule.burst()}
+	if st.tokens >= 1 {
+		st.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - st.tokens) / rate)
+	}
+	res.Remaining = int(st.tokens)
+	res.Reset = seconds((burst - st.tokens) / rate)
+	return res
+}
+
+func (st *memoryState) slide(now time.Time, rule Rule) Result {
+	st.last = now
+	if elapsed := now.Sub(st.start); elapsed >= rule.Window {
+		windows := int64(elapsed / rule.Window)
+		st.prev = st.count
+		if windows > 1 {
+			st.prev = 0
+		}
+		st.count = 0
+		st.start = st.start.Add(time.Duration(windows) * rule.Window)
+	}
+	left := rule.Window - now.Sub(st.start)
+	weight := float64(left) / float64(rule.Window)
+	used := int(math.Floor(float64(st.prev)*weight)) + st.count
+	res := Result{Limit: rule.Limit, Reset: left}
+	if used < rule.Limit {
+		st.count++
+		used++
+		res.Allowed = true
+	} else {
+		res.RetryAfter = left
+	}
+	if res.Remaining = rule.Limit - used; res.Remaining < 0 {
+		res.Remaining = 0
+	}
+	if st.count > 0 {
+		// the current count decays during the next window.
+		res.Reset += rule.Window
+	}
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,281 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	jwtauth "github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// ErrQuotaExceed is the tenant quota exceeded.
+var ErrQuotaExceed = errors.New(429, "QUOTA_EXCEEDED", "tenant quota exceeded")
+
+// TenantQuota is the quota of a tenant in every Window.
+type TenantQuota struct {
+	// Requests is the number of the requests, zero is unlimited.
+	Requests int64
+	// Bytes is the number of the bytes of the requests and the replies, zero is unlimited.
+	Bytes  int64
+	Window time.Duration
+}
+
+// UsageStore stores the usages of the keys in the fixed windows, e.g.
+// MemoryUsageStore for a single instance, or a Redis store of INCRBY and
+// EXPIRE shared by the instances.
+type UsageStore interface {
+	// Add adds n to the usage of the key in the current window, and returns
+	// the usage and the time until the window is reset.
+	Add(ctx context.Context, key string, n int64, window time.Duration) (used int64, reset time.Duration, err error)
+}
+
+// KeyClaim returns the KeyFunc of the claim of the JWT of the jwt middleware, e.g. "tenant_id".
+func KeyClaim(claim string) KeyFunc {
+	return func(ctx context.Context) string {
+		claims, ok := jwtauth.FromContext(ctx)
+		if !ok {
+			return ""
+		}
+		if mc, ok := claims.(jwt.MapClaims); ok {
+			if v, ok := mc[claim]; ok && v != nil {
+				return fmt.Sprint(v)
+			}
+			return ""
+		}
+		if claim == "sub" {
+			sub, _ := claims.GetSubject()
+			return sub
+		}
+		return ""
+	}
+}
+
+// TenantOption is tenant quota middleware option.
+type TenantOption func(*tenantOptions)
+
+type quotaGroup struct {
+	name       string
+	quota      TenantQuota
+	operations []string
+}
+
+type tenantOptions struct {
+	key    KeyFunc
+	store  UsageStore
+	groups []quotaGroup
+	quotas func(tenant, group string) (TenantQuota, bool)
+}
+
+// WithTenantKey with the tenant of the requests, e.g. KeyClaim("tenant_id"),
+// default is the X-Tenant request header.
+func WithTenantKey(fn KeyFunc) TenantOption {
+	return func(o *tenantOptions) {
+		o.key = fn
+	}
+}
+
+// WithUsageStore with the store of the usages, default is a MemoryUsageStore.
+func WithUsageStore(s UsageStore) TenantOption {
+	return func(o *tenantOptions) {
+		o.store = s
+	}
+}
+
+// WithQuotaGroup with the group of the operations sharing the quota of a
+// tenant, which are the operations or their prefixes, e.g. "/api.v1.Report/",
+// the first group matched applies, and the other operations share the
+// default quota.
+func WithQuotaGroup(name string, quota TenantQuota, operations ...string) TenantOption {
+	return func(o *tenantOptions) {
+		o.groups = append(o.groups, quotaGroup{name: name, quota: quota, operations: operations})
+	}
+}
+
+// WithTenantQuotas with the quotas of the tenants of their plans, which
+// override the ones of the groups, or the default of an empty group.
+func WithTenantQuotas(fn func(tenant, group string) (TenantQuota, bool)) TenantOption {
+	return func(o *tenantOptions) {
+		o.quotas = fn
+	}
+}
+
+// Tenant is a server middleware limiting the requests and the bandwidth of
+// the tenants under the quotas, those exceeding any are rejected with
+// ErrQuotaExceed and Retry-After, and the X-Quota-Remaining reply header is
+// the remaining requests, and X-Quota-Remaining-Bytes the bytes. The bytes
+// are the proto sizes of the requests, and of the replies which count after
+// the replies. The requests of no tenant and of the store failed are allowed.
+func Tenant(quota TenantQuota, opts ...TenantOption) middleware.Middleware {
+	o := &tenantOptions{key: KeyHeader("X-Tenant")}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.store == nil {
+		o.store = NewMemoryUsageStore()
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tenant := o.key(ctx)
+			if tenant == "" {
+				return handler(ctx, req)
+			}
+			var (
+				operation string
+				header    transport.Header
+			)
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				operation = tr.Operation()
+				header = tr.ReplyHeader()
+			}
+			group, q := o.quota(tenant, operation, quota)
+			if q.Window <= 0 {
+				return handler(ctx, req)
+			}
+			key := "quota:" + tenant + ":" + group
+			var (
+				exceeded bool
+				retry    time.Duration
+			)
+			if q.Requests > 0 {
+				used, reset, err := o.store.Add(ctx, key+":requests", 1, q.Window)
+				if err != nil {
+					log.Context(ctx).Errorf("ratelimit: failed to add the tenant requests: %v", err)
+					return handler(ctx, req)
+				}
+				if header != nil {
+					header.Set("X-Quota-Remaining", strconv.FormatInt(remaining(q.Requests, used), 10))
+				}
+				if used > q.Requests {
+					exceeded, retry = true, reset
+				}
+			}
+			if q.Bytes > 0 {
+				used, reset, err := o.store.Add(ctx, key+":bytes", messageSize(req), q.Window)
+				if err != nil {
+					log.Context(ctx).Errorf("ratelimit: failed to add the tenant bytes: %v", err)
+					return handler(ctx, req)
+				}
+				if header != nil {
+					header.Set("X-Quota-Remaining-Bytes", strconv.FormatInt(remaining(q.Bytes, used), 10))
+				}
+				if used > q.Bytes {
+					exceeded = true
+					if reset > retry {
+						retry = reset
+					}
+				}
+			}
+			if exceeded {
+				if header != nil {
+					header.Set("Retry-After", ceilSeconds(retry))
+				}
+				return nil, ErrQuotaExceed
+			}
+			reply, err := handler(ctx, req)
+			if n := messageSize(reply); q.Bytes > 0 && n > 0 {
+				if _, _, err := o.store.Add(ctx, key+":bytes", n, q.Window); err != nil {
+					log.Context(ctx).Errorf("ratelimit: failed to add the tenant bytes: %v", err)
+				}
+			}
+			return reply, err
+		}
+	}
+}
+
+// quota returns the group and the quota of the tenant and operation.
+func (o *tenantOptions) quota(tenant, operation string, quota TenantQuota) (string, TenantQuota) {
+	var group string
+	for _, g := range o.groups {
+		if matchOperation(operation, g.operations) {
+			group, quota = g.name, g.quota
+			break
+		}
+	}
+	if o.quotas != nil {
+		if q, ok := o.quotas(tenant, group); ok {
+			quota = q
+		}
+	}
+	return group, quota
+}
+
+func matchOperation(operation string, operations []string) bool {
+	for _, op := range operations {
+		if operation == op || (strings.HasSuffix(op, "/") && strings.HasPrefix(operation, op)) {
+			return true
+		}
+	}
+	return false
+}
+
+func remaining(limit, used int64) int64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+func messageSize(v interface{}) int64 {
+	if m, ok := v.(proto.Message); ok {
+		return int64(proto.Size(m))
+	}
+	return 0
+}
+
+var _ UsageStore = (*MemoryUsageStore)(nil)
+
+type usageWindow struct {
+	end  time.Time
+	used int64
+}
+
+// MemoryUsageStore is an in-memory UsageStore of a single instance.
+type MemoryUsageStore struct {
+	mu      sync.Mutex
+	windows map[string]*usageWindow
+	swept   time.Time
+	now     func() time.Time
+}
+
+// NewMemoryUsageStore new an in-memory usage store.
+func NewMemoryUsageStore() *MemoryUsageStore {
+	return &MemoryUsageStore{windows: make(map[string]*usageWindow), now: time.Now}
+}
+
+// Add adds n to the usage of the key in the current window.
+func (s *MemoryUsageStore) Add(_ context.Context, key string, n int64, window time.Duration) (int64, time.Duration, error) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.end) {
+		w = &usageWindow{end: now.Add(window)}
+		s.windows[key] = w
+	}
+	w.used += n
+	return w.used, w.end.Sub(now), nil
+}
+
+// sweep removes the windows ended, at most once a minute.
+func (s *MemoryUsageStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+	for key, w := range s.windows {
+		if !now.Before(w.end) {
+			delete(s.windows, key)
+		}
+	}
+}
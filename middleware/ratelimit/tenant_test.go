@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	jwtauth "github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestMemoryUsageStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewMemoryUsageStore()
+	s.now = func() time.Time { return now }
+	if used, reset, _ := s.Add(context.Background(), "a", 2, time.Minute); used != 2 || reset != time.Minute {
+		t.Errorf("unexpected usage %d %v", used, reset)
+	}
+	now = now.Add(10 * time.Second)
+	if used, reset, _ := s.Add(context.Background(), "a", 3, time.Minute); used != 5 || reset != 50*time.Second {
+		t.Errorf("unexpected usage %d %v", used, reset)
+	}
+	now = now.Add(time.Minute)
+	if used, _, _ := s.Add(context.Background(), "a", 1, time.Minute); used != 1 {
+		t.Errorf("expected a fresh window got %d", used)
+	}
+}
+
+func TestTenant(t *testing.T) {
+	m := Tenant(TenantQuota{Requests: 2, Window: time.Minute},
+		WithQuotaGroup("reports", TenantQuota{Requests: 1, Window: time.Minute}, "/api.v1.Report/"),
+		WithTenantQuotas(func(tenant, group string) (TenantQuota, bool) {
+			if tenant == "premium" && group == "" {
+				return TenantQuota{Requests: 10, Window: time.Minute}, true
+			}
+			return TenantQuota{}, false
+		}),
+	)
+	h := m(func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil })
+	call := func(tenant, operation string) (http.Header, error) {
+		tr := &replyTransport{
+			testTransport: testTransport{operation: operation, header: http.Header{"X-Tenant": []string{tenant}}},
+			reply:         http.Header{},
+		}
+		_, err := h(transport.NewServerContext(context.Background(), tr), nil)
+		return tr.reply, err
+	}
+	for i, want := range []string{"1", "0"} {
+		reply, err := call("a", "/api.v1.User/Get")
+		if err != nil || reply.Get("X-Quota-Remaining") != want {
+			t.Fatalf("request %d: unexpected %v %v", i, reply, err)
+		}
+	}
+	if reply, err := call("a", "/api.v1.User/List"); !errors.Is(err, ErrQuotaExceed) || reply.Get("Retry-After") != "60" {
+		t.Errorf("expected the quota exceeded with Retry-After, got %v %v", err, reply)
+	}
+	if _, err := call("a", "/api.v1.Report/Export"); err != nil {
+		t.Errorf("expected the quota of the group, got %v", err)
+	}
+	if _, err := call("a", "/api.v1.Report/Export"); !errors.Is(err, ErrQuotaExceed) {
+		t.Errorf("expected the group quota exceeded, got %v", err)
+	}
+	if reply, err := call("premium", "/api.v1.User/Get"); err != nil || reply.Get("X-Quota-Remaining") != "9" {
+		t.Errorf("expected the quota of the tenant, got %v %v", reply, err)
+	}
+	if _, err := call("", "/api.v1.User/Get"); err != nil {
+		t.Errorf("expected the requests of no tenant allowed, got %v", err)
+	}
+}
+
+func TestTenantBytes(t *testing.T) {
+	reply := wrapperspb.String("0123456789")
+	m := Tenant(TenantQuota{Bytes: 20, Window: time.Minute}, WithTenantKey(KeyClaim("tenant")))
+	h := m(func(ctx context.Context, req interface{}) (interface{}, error) { return reply, nil })
+	ctx := jwtauth.NewContext(context.Background(), jwt.MapClaims{"tenant": "a"})
+	call := func() (http.Header, error) {
+		tr := &replyTransport{testTransport: testTransport{operation: "/test", header: http.Header{}}, reply: http.Header{}}
+		_, err := h(transport.NewServerContext(ctx, tr), wrapperspb.String("abc"))
+		return tr.reply, err
+	}
+	// the request is 5 bytes and the reply 12.
+	if header, err := call(); err != nil || header.Get("X-Quota-Remaining-Bytes") != "15" {
+		t.Fatalf("unexpected %v %v", header, err)
+	}
+	if header, err := call(); !errors.Is(err, ErrQuotaExceed) || header.Get("X-Quota-Remaining-Bytes") != "0" {
+		t.Errorf("expected the bandwidth exceeded, got %v %v", header, err)
+	}
+}
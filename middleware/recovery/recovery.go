@@ -7,7 +7,9 @@ import (
 
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metadata"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
 )
 
 // Latency is recovery latency context key
@@ -19,11 +21,38 @@ var ErrUnknownRequest = errors.InternalServer("UNKNOWN", "unknown request error"
 // HandlerFunc is recovery handler func.
 type HandlerFunc func(ctx context.Context, req, err interface{}) error
 
+// Report is the structured report of a recovered panic.
+type Report struct {
+	// Panic is the recovered value.
+	Panic interface{}
+	// Stack is the stack of the panicking goroutine.
+	Stack []byte
+	// Kind is the transport kind of the request, e.g. "http".
+	Kind string
+	// Operation is the operation of the request.
+	Operation string
+	// Metadata is the metadata of the request, see middleware/metadata.
+	Metadata metadata.Metadata
+	// Request is the request.
+	Request interface{}
+	// Time is the time of the panic.
+	Time time.Time
+}
+
+// Hook is called with the reports of the panics, e.g. to send them to Sentry.
+type Hook func(ctx context.Context, r *Report)
+
+// ConvertFunc converts the panic into a typed error, e.g. of the panics of a
+// known type, nil means it is not converted and falls back to the handler.
+type ConvertFunc func(p interface{}) error
+
 // Option is recovery option.
 type Option func(*options)
 
 type options struct {
-	handler HandlerFunc
+	handler    HandlerFunc
+	hooks      []Hook
+	converters []ConvertFunc
 }
 
 // WithHandler with recovery handler.
@@ -33,6 +62,21 @@ func WithHandler(h HandlerFunc) Option {
 	}
 }
 
+// WithHook with the hook of the panic reports, the hooks are called in order.
+func WithHook(h Hook) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, h)
+	}
+}
+
+// WithConverter with the converter of the panics into the errors, which are
+// tried in order before the handler.
+func WithConverter(c ConvertFunc) Option {
+	return func(o *options) {
+		o.converters = append(o.converters, c)
+	}
+}
+
 // Recovery is a server middleware that recovers from any panics.
 func Recovery(opts ...Option) middleware.Middleware {
 	op := options{
@@ -53,6 +97,17 @@ func Recovery(opts ...Option) middleware.Middleware {
 					buf = buf[:n]
 					log.Context(ctx).Errorf("%v: %+v\n%s\n", rerr, req, buf)
 					ctx = context.WithValue(ctx, Latency{}, time.Since(startTime).Seconds())
+					if len(op.hooks) > 0 {
+						report := newReport(ctx, req, rerr, buf)
+						for _, h := range op.hooks {
+							callHook(ctx, h, report)
+						}
+					}
+					for _, c := range op.converters {
+						if err = c(rerr); err != nil {
+							return
+						}
+					}
 					err = op.handler(ctx, req, rerr)
 				}
 			}()
@@ -60,3 +115,30 @@ func Recovery(opts ...Option) middleware.Middleware {
 		}
 	}
 }
+
+// callHook calls the hook, of which the panics are dropped not to crash the process.
+func callHook(ctx context.Context, h Hook, r *Report) {
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			log.Context(ctx).Errorf("recovery hook panicked: %v", rerr)
+		}
+	}()
+	h(ctx, r)
+}
+
+func newReport(ctx context.Context, req, p interface{}, stack []byte) *Report {
+	r := &Report{
+		Panic:   p,
+		Stack:   stack,
+		Request: req,
+		Time:    time.Now(),
+	}
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		r.Kind = tr.Kind().String()
+		r.Operation = tr.Operation()
+	}
+	if md, ok := metadata.FromServerContext(ctx); ok {
+		r.Metadata = md.Clone()
+	}
+	return r
+}
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/transport"
 )
 
 func TestOnce(t *testing.T) {
@@ -40,3 +42,45 @@ func TestNotPanic(t *testing.T) {
 		t.Errorf("e isn't nil")
 	}
 }
+
+type testTransport struct {
+	transport.Transporter
+}
+
+func (tr *testTransport) Kind() transport.Kind { return transport.KindGRPC }
+func (tr *testTransport) Operation() string    { return "/test.v1.Test/Panic" }
+
+type errNotImplemented struct{}
+
+func TestHooksAndConverters(t *testing.T) {
+	var report *Report
+	ctx := transport.NewServerContext(context.Background(), &testTransport{})
+	ctx = metadata.NewServerContext(ctx, metadata.New(map[string][]string{"x-md-global-user": {"kratos"}}))
+	m := Recovery(
+		WithHook(func(ctx context.Context, r *Report) { panic("broken hook") }),
+		WithHook(func(ctx context.Context, r *Report) { report = r }),
+		WithConverter(func(p interface{}) error {
+			if _, ok := p.(errNotImplemented); ok {
+				return errors.New(501, "NOT_IMPLEMENTED", "not implemented")
+			}
+			return nil
+		}),
+	)
+	_, err := m(func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic(errNotImplemented{})
+	})(ctx, "req")
+	if errors.Code(err) != 501 {
+		t.Errorf("expected the converted error, got %v", err)
+	}
+	if report == nil || report.Operation != "/test.v1.Test/Panic" || report.Kind != "grpc" || report.Request != "req" ||
+		report.Metadata.Get("x-md-global-user") != "kratos" || len(report.Stack) == 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	_, err = m(func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("other")
+	})(ctx, "req")
+	if !errors.Is(err, ErrUnknownRequest) {
+		t.Errorf("expected the unconverted panic handled, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Header is the default header of the request id.
+const Header = "X-Request-ID"
+
+// maxLength is the max length of a propagated request id.
+const maxLength = 128
+
+type requestIDKey struct{}
+
+// Option is request id option.
+type Option func(*options)
+
+type options struct {
+	header    string
+	generator func() string
+}
+
+// WithHeader with the header of the request id, e.g. X-Correlation-ID, default is X-Request-ID.
+func WithHeader(header string) Option {
+	return func(o *options) {
+		o.header = header
+	}
+}
+
+// WithGenerator with the generator of the request ids, default is a random UUID.
+func WithGenerator(f func() string) Option {
+	return func(o *options) {
+		o.generator = f
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		header:    Header,
+		generator: uuid.NewString,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Server is a server middleware which propagates the request id of the request
+// header, or generates one if it is missing or invalid. The id is put into context
+// and set on the reply header.
+func Server(opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			id := tr.RequestHeader().Get(o.header)
+			if !valid(id) {
+				id = o.generator()
+			}
+			tr.ReplyHeader().Set(o.header, id)
+			return handler(NewContext(ctx, id), req)
+		}
+	}
+}
+
+// Client is a client middleware which forwards the request id in context
+// on the outgoing requests, e.g. the HTTP headers or the gRPC metadata.
+func Client(opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				if id, ok := FromContext(ctx); ok && tr.RequestHeader().Get(o.header) == "" {
+					tr.RequestHeader().Set(o.header, id)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// valid reports whether the request id is non-empty, not too long and printable,
+// so that a client cannot inject into the logs.
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// ID returns a request id valuer of the logger.
+func ID() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		id, _ := FromContext(ctx)
+		return id
+	}
+}
+
+// NewContext put the request id into context.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// FromContext extract the request id from context.
+func FromContext(ctx context.Context) (id string, ok bool) {
+	id, ok = ctx.Value(requestIDKey{}).(string)
+	return
+}
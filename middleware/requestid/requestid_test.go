@@ -0,0 +1,93 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string      { return http.Header(hc).Get(key) }
+func (hc headerCarrier) Set(key, value string)      { http.Header(hc).Set(key, value) }
+func (hc headerCarrier) Add(key, value string)      { http.Header(hc).Add(key, value) }
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type testTransport struct {
+	request headerCarrier
+	reply   headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.request }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.reply }
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"propagated", "abc-123", "abc-123"},
+		{"generated", "", "generated"},
+		{"invalid", "a\nb", "generated"},
+		{"too long", strings.Repeat("a", maxLength+1), "generated"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tr := &testTransport{request: headerCarrier{}, reply: headerCarrier{}}
+			if test.header != "" {
+				tr.request.Set(Header, test.header)
+			}
+			var id string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				id, _ = FromContext(ctx)
+				return nil, nil
+			}
+			server := Server(WithGenerator(func() string { return "generated" }))
+			_, _ = server(handler)(transport.NewServerContext(context.Background(), tr), nil)
+			if id != test.want || tr.reply.Get(Header) != test.want {
+				t.Errorf("expected %s got %s and reply header %s", test.want, id, tr.reply.Get(Header))
+			}
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	tr := &testTransport{request: headerCarrier{}}
+	ctx := transport.NewClientContext(NewContext(context.Background(), "abc-123"), tr)
+	_, _ = Client(WithHeader("X-Correlation-ID"))(handler)(ctx, nil)
+	if got := tr.request.Get("X-Correlation-ID"); got != "abc-123" {
+		t.Errorf("expected the request id forwarded got %s", got)
+	}
+
+	tr = &testTransport{request: headerCarrier{}}
+	_, _ = Client()(handler)(transport.NewClientContext(context.Background(), tr), nil)
+	if len(tr.request) != 0 {
+		t.Errorf("expected no header without request id got %v", tr.request)
+	}
+}
+
+func TestID(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := log.With(log.NewStdLogger(buf), "request_id", ID())
+	_ = log.WithContext(NewContext(context.Background(), "abc-123"), logger).Log(log.LevelInfo, "msg", "hello")
+	if !strings.Contains(buf.String(), "request_id=abc-123") {
+		t.Errorf("expected the request id logged got %s", buf.String())
+	}
+}
@@ -0,0 +1,133 @@
+// Package split routes a percentage of the requests of an operation to an
+// alternative handler for the A/B testing, the requests are bucketed by a
+// stable key, e.g. the user id, so that a user always sees the same variant.
+package split
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	// Control is the variant of the requests served by the registered handler.
+	Control = "control"
+	// Treatment is the variant of the requests served by the alternative handler.
+	Treatment = "treatment"
+)
+
+// Experiment routes Percent of the requests of the operation to the Handler.
+type Experiment struct {
+	// Name is the unique name of the experiment, which salts the buckets.
+	Name string
+	// Operation is the operation of the requests, e.g. "/helloworld.v1.Greeter/SayHello".
+	Operation string
+	// Percent is the percentage of the requests, from 0 to 100.
+	Percent float64
+	// Handler is the alternative implementation of the operation.
+	Handler middleware.Handler
+}
+
+// KeyFunc returns the stable bucketing key of the request,
+// empty means the request is served by the registered handler.
+type KeyFunc func(ctx context.Context) string
+
+// Option is split option.
+type Option func(*options)
+
+type options struct {
+	key KeyFunc
+}
+
+// WithKey with the bucketing key of the requests, default is the X-User-ID request header.
+func WithKey(fn KeyFunc) Option {
+	return func(o *options) {
+		o.key = fn
+	}
+}
+
+func userFromHeader(ctx context.Context) string {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		return tr.RequestHeader().Get("X-User-ID")
+	}
+	return ""
+}
+
+type variantsKey struct{}
+
+// Server is a server middleware routing the requests of the experiment to its
+// handler by their buckets. The variant is put into context for the logs, see
+// Valuer, and set as the "experiment.<name>" attribute of the current span.
+func Server(e Experiment, opts ...Option) middleware.Middleware {
+	o := &options{key: userFromHeader}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok || tr.Operation() != e.Operation {
+				return handler(ctx, req)
+			}
+			variant := Control
+			if key := o.key(ctx); key != "" && bucket(e.Name, key) < e.Percent {
+				variant = Treatment
+			}
+			ctx = NewContext(ctx, e.Name, variant)
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("experiment."+e.Name, variant))
+			if variant == Treatment && e.Handler != nil {
+				return e.Handler(ctx, req)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// bucket returns the bucket of the key in the experiment, from 0 to 100.
+func bucket(name, key string) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+	return float64(h.Sum64()%10000) / 100
+}
+
+// NewContext put the variant of the experiment into context.
+func NewContext(ctx context.Context, name, variant string) context.Context {
+	prev, _ := ctx.Value(variantsKey{}).(map[string]string)
+	variants := make(map[string]string, len(prev)+1)
+	for k, v := range prev {
+		variants[k] = v
+	}
+	variants[name] = variant
+	return context.WithValue(ctx, variantsKey{}, variants)
+}
+
+// FromContext extract the variant of the experiment from context.
+func FromContext(ctx context.Context, name string) (variant string, ok bool) {
+	variants, _ := ctx.Value(variantsKey{}).(map[string]string)
+	variant, ok = variants[name]
+	return
+}
+
+// Valuer returns the valuer of the logger, the variants of the experiments
+// sorted by name, e.g. "checkout=treatment,search=control".
+func Valuer() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		variants, _ := ctx.Value(variantsKey{}).(map[string]string)
+		pairs := make([]string, 0, len(variants))
+		for k, v := range variants {
+			pairs = append(pairs, k+"="+v)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	}
+}
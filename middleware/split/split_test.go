@@ -0,0 +1,82 @@
+package split
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string      { return http.Header(hc).Get(key) }
+func (hc headerCarrier) Set(key, value string)      { http.Header(hc).Set(key, value) }
+func (hc headerCarrier) Add(key, value string)      { http.Header(hc).Add(key, value) }
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range hc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type testTransport struct {
+	operation string
+	header    http.Header
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return tr.operation }
+func (tr *testTransport) RequestHeader() transport.Header { return headerCarrier(tr.header) }
+func (tr *testTransport) ReplyHeader() transport.Header   { return headerCarrier{} }
+
+func TestServer(t *testing.T) {
+	m := Server(Experiment{
+		Name:      "greeting",
+		Operation: "/hello",
+		Percent:   20,
+		Handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+			return Treatment, nil
+		},
+	})
+	h := m(func(ctx context.Context, req interface{}) (interface{}, error) {
+		if v, _ := FromContext(ctx, "greeting"); v != Control && v != "" {
+			t.Errorf("expected the control variant, got %s", v)
+		}
+		return Control, nil
+	})
+	call := func(operation, user string) string {
+		ctx := transport.NewServerContext(context.Background(), &testTransport{
+			operation: operation,
+			header:    http.Header{"X-User-Id": []string{user}},
+		})
+		reply, _ := h(ctx, nil)
+		return reply.(string)
+	}
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		user := strconv.Itoa(i)
+		variant := call("/hello", user)
+		if call("/hello", user) != variant {
+			t.Fatalf("expected the stable variant of user %s", user)
+		}
+		counts[variant]++
+	}
+	if counts[Treatment] < 150 || counts[Treatment] > 250 {
+		t.Errorf("expected about 20%% of the treatment, got %v", counts)
+	}
+	if call("/other", "1") != Control || call("/hello", "") != Control {
+		t.Error("expected the other operations and the requests without keys served by the registered handler")
+	}
+}
+
+func TestValuer(t *testing.T) {
+	ctx := NewContext(NewContext(context.Background(), "search", Control), "checkout", Treatment)
+	if v := Valuer()(ctx); v != "checkout=treatment,search=control" {
+		t.Errorf("unexpected valuer %v", v)
+	}
+}
@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-kratos/kratos/v2/internal/httputil"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func newHTTPOptions(opts []Option) options {
+	op := options{
+		propagator: propagation.NewCompositeTextMapPropagator(Metadata{}, propagation.Baggage{}, propagation.TraceContext{}),
+		tracerName: "kratos",
+	}
+	for _, o := range opts {
+		o(&op)
+	}
+	if op.tracerProvider == nil {
+		op.tracerProvider = otel.GetTracerProvider()
+	}
+	return op
+}
+
+// Filter is an HTTP server filter starting the span of each request, like
+// otelhttp, including the ones not served by the kratos routes, e.g. of
+// HandlePrefix or the 404s. The spans have the transport attributes lost by
+// the Server middleware, e.g. the protocol version, and are the parents of the
+// spans of it.
+func Filter(opts ...Option) khttp.FilterFunc {
+	op := newHTTPOptions(opts)
+	tracer := op.tracerProvider.Tracer(op.tracerName)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx := op.propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			ctx, span := tracer.Start(ctx, "HTTP "+req.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(requestAttributes(req)...),
+			)
+			defer span.End()
+			req, route := khttp.RecordRoute(req.WithContext(ctx))
+			sw := &statusWriter{ResponseWriter: w, code: http.StatusOK}
+			next.ServeHTTP(sw, req)
+			if route := route(); route != "" {
+				span.SetName(req.Method + " " + route)
+				span.SetAttributes(attribute.String("http.route", route))
+			}
+			endHTTPSpan(span, sw.code)
+		})
+	}
+}
+
+// Transport returns the round tripper starting the client span of each
+// request, like otelhttp, e.g. of the WithTransport option of the HTTP
+// client. The spans have the protocol versions of the responses, e.g. "2" of
+// the HTTP/2 connections or "3" of the HTTP/3 round trippers. The base is
+// http.DefaultTransport if it is nil.
+func Transport(base http.RoundTripper, opts ...Option) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	op := newHTTPOptions(opts)
+	tracer := op.tracerProvider.Tracer(op.tracerName)
+	return roundTripper(func(req *http.Request) (*http.Response, error) {
+		ctx, span := tracer.Start(req.Context(), "HTTP "+req.Method,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(requestAttributes(req)...),
+		)
+		defer span.End()
+		req = req.Clone(ctx)
+		op.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+		res, err := base.RoundTrip(req)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		span.SetAttributes(attribute.String("network.protocol.version", httputil.ProtocolVersion(res.ProtoMajor, res.ProtoMinor)))
+		endHTTPSpan(span, res.StatusCode)
+		return res, nil
+	})
+}
+
+func requestAttributes(req *http.Request) []attribute.KeyValue {
+	scheme := "http"
+	if req.URL.Scheme != "" {
+		scheme = req.URL.Scheme
+	} else if req.TLS != nil {
+		scheme = "https"
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	return []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+		attribute.String("url.scheme", scheme),
+		attribute.String("url.path", req.URL.Path),
+		attribute.String("server.address", host),
+		attribute.String("network.protocol.name", "http"),
+		attribute.String("network.protocol.version", httputil.ProtocolVersion(req.ProtoMajor, req.ProtoMinor)),
+		attribute.String("user_agent.original", req.UserAgent()),
+	}
+}
+
+// endHTTPSpan sets the status of the span of the response status code, the
+// 5xx ones are errors.
+func endHTTPSpan(span trace.Span, code int) {
+	span.SetAttributes(attribute.Int("http.response.status_code", code))
+	if code >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(code))
+	}
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.code, w.wroteHeader = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
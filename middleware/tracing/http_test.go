@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func spanAttribute(span tracetest.SpanStub, key string) attribute.Value {
+	for _, kv := range span.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestFilter(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := WithTracerProvider(tracesdk.NewTracerProvider(tracesdk.WithSyncer(exporter)))
+	srv := khttp.NewServer(khttp.Filter(Filter(provider)), khttp.Middleware(Server(provider)))
+	srv.Route("/").GET("/users/{id}", func(ctx khttp.Context) error {
+		_, err := ctx.Middleware(func(context.Context, interface{}) (interface{}, error) { return nil, nil })(ctx, nil)
+		if err != nil {
+			return err
+		}
+		return ctx.String(http.StatusInternalServerError, "")
+	})
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected the spans of the filter and the middleware, got %v", spans)
+	}
+	filter := spans[1]
+	if filter.Name != "GET /users/{id}" || spans[0].Parent.SpanID() != filter.SpanContext.SpanID() {
+		t.Errorf("expected the parent span of the route, got %q", filter.Name)
+	}
+	if v := spanAttribute(filter, "network.protocol.version").AsString(); v != "1.1" {
+		t.Errorf("expected the protocol version 1.1, got %q", v)
+	}
+	if v := spanAttribute(filter, "http.response.status_code").AsInt64(); v != http.StatusInternalServerError {
+		t.Errorf("expected the status code 500, got %d", v)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		traceparent = req.Header.Get("traceparent")
+	}))
+	defer srv.Close()
+	exporter := tracetest.NewInMemoryExporter()
+	client := &http.Client{Transport: Transport(nil, WithTracerProvider(tracesdk.NewTracerProvider(tracesdk.WithSyncer(exporter))))}
+	res, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || traceparent == "" {
+		t.Fatalf("expected the propagated client span, got %v %q", spans, traceparent)
+	}
+	if v := spanAttribute(spans[0], "network.protocol.version").AsString(); v != "1.1" {
+		t.Errorf("expected the protocol version 1.1, got %q", v)
+	}
+}
@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// Observer returns the observer of the middleware of the servers, e.g. of
+// the MiddlewareObserver option of the HTTP and the gRPC servers, starting a
+// span of each middleware, e.g. "middleware jwt.Server", as the child of the
+// span of the previous one. The durations excluding the next handlers are the
+// "middleware.self_us" attributes, and the errors of the spans are the ones
+// returned by the middleware, not by the next handlers.
+func Observer(opts ...Option) middleware.Observer {
+	op := options{tracerName: "kratos"}
+	for _, o := range opts {
+		o(&op)
+	}
+	if op.tracerProvider == nil {
+		op.tracerProvider = otel.GetTracerProvider()
+	}
+	tracer := op.tracerProvider.Tracer(op.tracerName)
+	return func(ctx context.Context, name string) (context.Context, func(time.Duration, error)) {
+		ctx, span := tracer.Start(ctx, "middleware "+name,
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(attribute.String("middleware.name", name)),
+		)
+		return ctx, func(self time.Duration, err error) {
+			span.SetAttributes(attribute.Int64("middleware.self_us", self.Microseconds()))
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}
+	}
+}
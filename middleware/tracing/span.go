@@ -119,6 +119,20 @@ func parseFullMethod(fullMethod string) (string, []attribute.KeyValue) {
 	return name, attrs
 }
 
+// addTimelineEvents adds the recorded HTTP request phases as span events.
+// The middleware and the encode phases are recorded after the chain returns,
+// i.e. after the span has ended, so they are left out of the span, and are
+// only reported by the Server-Timing header.
+func addTimelineEvents(ctx context.Context, span trace.Span) {
+	tl, ok := http.TimelineFromServerContext(ctx)
+	if !ok {
+		return
+	}
+	for _, p := range tl.Phases() {
+		span.AddEvent(p.Name, trace.WithAttributes(attribute.Int64("duration_us", p.Duration.Microseconds())))
+	}
+}
+
 // peerAttr returns attributes about the peer address.
 func peerAttr(addr string) []attribute.KeyValue {
 	host, port, err := net.SplitHostPort(addr)
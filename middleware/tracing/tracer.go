@@ -47,7 +47,7 @@ func NewTracer(kind trace.SpanKind, opts ...Option) *Tracer {
 // Start start tracing span
 func (t *Tracer) Start(ctx context.Context, operation string, carrier propagation.TextMapCarrier) (context.Context, trace.Span) {
 	if t.kind == trace.SpanKindServer {
-		ctx = t.opt.propagator.Extract(ctx, carrier)
+		ctx = t.extract(ctx, carrier)
 	}
 	ctx, span := t.tracer.Start(ctx,
 		operation,
@@ -59,6 +59,18 @@ func (t *Tracer) Start(ctx context.Context, operation string, carrier propagatio
 	return ctx, span
 }
 
+// extract returns the context extracted from the carrier, the local span of
+// ctx stays the parent only if the carrier has no span context, or has the one
+// of the same trace, e.g. the span of Filter started from the same carrier.
+func (t *Tracer) extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	local := trace.SpanFromContext(ctx)
+	ctx = t.opt.propagator.Extract(ctx, carrier)
+	if sc := local.SpanContext(); sc.IsValid() && !sc.IsRemote() && sc.TraceID() == trace.SpanContextFromContext(ctx).TraceID() {
+		return trace.ContextWithSpan(ctx, local)
+	}
+	return ctx
+}
+
 // End finish tracing span
 func (t *Tracer) End(_ context.Context, span trace.Span, m interface{}, err error) {
 	if err != nil {
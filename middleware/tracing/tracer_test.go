@@ -5,6 +5,8 @@ import (
 	"errors"
 	"testing"
 
+	"go.opentelemetry.io/otel/propagation"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/go-kratos/kratos/v2/internal/testdata/binding"
@@ -53,3 +55,23 @@ func TestTracer_End(_ *testing.T) {
 	})
 	tracer.End(ctx, span, m, nil)
 }
+
+func TestTracer_StartRemoteParent(t *testing.T) {
+	tracer := NewTracer(trace.SpanKindServer, WithTracerProvider(tracesdk.NewTracerProvider()), WithPropagator(propagation.TraceContext{}))
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	carrier := propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+
+	// the local span of an outer tracer does not drop the remote parent.
+	ctx, outer := tracesdk.NewTracerProvider().Tracer("outer").Start(context.Background(), "outer")
+	defer outer.End()
+	_, span := tracer.Start(ctx, "operation", carrier)
+	if got := span.SpanContext().TraceID(); got != traceID {
+		t.Errorf("expected the trace of the carrier %s, got %s", traceID, got)
+	}
+
+	// the local span is the parent without the remote one.
+	_, span = tracer.Start(ctx, "operation", propagation.MapCarrier{})
+	if got := span.SpanContext().TraceID(); got != outer.SpanContext().TraceID() {
+		t.Errorf("expected the trace of the local span %s, got %s", outer.SpanContext().TraceID(), got)
+	}
+}
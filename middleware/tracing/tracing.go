@@ -52,7 +52,10 @@ func Server(opts ...Option) middleware.Middleware {
 				var span trace.Span
 				ctx, span = tracer.Start(ctx, tr.Operation(), tr.RequestHeader())
 				setServerSpan(ctx, span, req)
-				defer func() { tracer.End(ctx, span, reply, err) }()
+				defer func() {
+					addTimelineEvents(ctx, span)
+					tracer.End(ctx, span, reply, err)
+				}()
 			}
 			return handler(ctx, req)
 		}
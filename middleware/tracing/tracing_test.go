@@ -2,16 +2,20 @@ package tracing
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
 	"reflect"
 	"testing"
 
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
@@ -234,3 +238,28 @@ func TestClient(t *testing.T) {
 		t.Errorf("expected %v, got %v", childTraceID, span.SpanContext().TraceID().String())
 	}
 }
+
+func TestObserver(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	o := Observer(WithTracerProvider(tracesdk.NewTracerProvider(tracesdk.WithSyncer(exporter))))
+	errDenied := errors.New("denied")
+	deny := func(middleware.Handler) middleware.Handler {
+		return func(context.Context, interface{}) (interface{}, error) {
+			return nil, errDenied
+		}
+	}
+	pass := func(h middleware.Handler) middleware.Handler { return h }
+	h := middleware.Chain(middleware.Instrument(o, pass, deny)...)(func(context.Context, interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	if _, err := h(context.Background(), nil); !errors.Is(err, errDenied) {
+		t.Fatalf("expected errDenied, got %v", err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 2 || spans[0].Name != "middleware tracing.TestObserver" || spans[0].Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		t.Fatalf("expected the nested spans of the middleware, got %v", spans)
+	}
+	if spans[0].Status.Code != codes.Error || spans[1].Status.Code == codes.Error {
+		t.Errorf("expected the error of the deny middleware only, got %v %v", spans[0].Status, spans[1].Status)
+	}
+}
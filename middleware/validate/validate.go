@@ -11,16 +11,125 @@ type validator interface {
 	Validate() error
 }
 
-// Validator is a validator middleware.
-func Validator() middleware.Middleware {
+// validatorAll is implemented by the messages generated by protoc-gen-validate
+// with the ValidateAll methods, which report all the violations at once.
+type validatorAll interface {
+	ValidateAll() error
+}
+
+// FieldError is a validation error of a field, e.g. the errors generated by
+// protoc-gen-validate. The nested field errors are reported by their Cause.
+type FieldError interface {
+	error
+	Field() string
+	Reason() string
+}
+
+type multiError interface {
+	AllErrors() []error
+}
+
+type causer interface {
+	Cause() error
+}
+
+// ValidateFunc validates the request, e.g. with the CEL rules of protovalidate.
+// The returned errors implementing FieldError, directly or by AllErrors, are
+// reported per field.
+type ValidateFunc func(req interface{}) error
+
+// Option is validator option.
+type Option func(*options)
+
+type options struct {
+	validators []ValidateFunc
+}
+
+// WithValidateFunc with the additional validation of the requests, which runs
+// after the generated Validate methods of protoc-gen-validate.
+func WithValidateFunc(fn ValidateFunc) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, fn)
+	}
+}
+
+// Validator is a validator middleware. The violations are returned as a bad
+// request error, with the reasons of the fields in its metadata keyed by the
+// field paths, e.g. {"address.zip": "value length must be 5 runes"}, so that
+// the API clients can render them as form errors.
+func Validator(opts ...Option) middleware.Middleware {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
-			if v, ok := req.(validator); ok {
-				if err := v.Validate(); err != nil {
-					return nil, errors.BadRequest("VALIDATOR", err.Error()).WithCause(err)
+			if err := validate(req, o); err != nil {
+				e := errors.BadRequest("VALIDATOR", err.Error()).WithCause(err)
+				if fields := violations(err); len(fields) > 0 {
+					e = e.WithMetadata(fields)
 				}
+				return nil, e
 			}
 			return handler(ctx, req)
 		}
 	}
 }
+
+func validate(req interface{}, o *options) error {
+	switch v := req.(type) {
+	case validatorAll:
+		if err := v.ValidateAll(); err != nil {
+			return err
+		}
+	case validator:
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, fn := range o.validators {
+		if err := fn(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// violations returns the reasons of the field errors keyed by the field paths,
+// the reasons of the same field are joined by "; ".
+func violations(err error) map[string]string {
+	fields := make(map[string]string)
+	collect(fields, "", err)
+	return fields
+}
+
+func collect(fields map[string]string, prefix string, err error) {
+	if multi, ok := err.(multiError); ok {
+		for _, e := range multi.AllErrors() {
+			collect(fields, prefix, e)
+		}
+		return
+	}
+	fe, ok := err.(FieldError)
+	if !ok {
+		return
+	}
+	path := prefix + fe.Field()
+	if c, ok := fe.(causer); ok {
+		if cause := c.Cause(); cause != nil {
+			if _, nested := cause.(FieldError); nested {
+				collect(fields, path+".", cause)
+				return
+			}
+			if _, nested := cause.(multiError); nested {
+				collect(fields, path+".", cause)
+				return
+			}
+		}
+	}
+	if prev, ok := fields[path]; ok {
+		fields[path] = prev + "; " + fe.Reason()
+		return
+	}
+	fields[path] = fe.Reason()
+}
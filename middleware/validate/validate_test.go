@@ -41,3 +41,51 @@ func TestTable(t *testing.T) {
 		})
 	}
 }
+
+// fieldError is an error of protoc-gen-validate.
+type fieldError struct {
+	field  string
+	reason string
+	cause  error
+}
+
+func (e fieldError) Field() string  { return e.field }
+func (e fieldError) Reason() string { return e.reason }
+func (e fieldError) Cause() error   { return e.cause }
+func (e fieldError) Error() string  { return e.field + ": " + e.reason }
+
+type multiErr []error
+
+func (m multiErr) Error() string      { return "multiple errors" }
+func (m multiErr) AllErrors() []error { return m }
+
+type allVali struct{}
+
+func (allVali) Validate() error { return fieldError{field: "Name", reason: "first only"} }
+
+func (allVali) ValidateAll() error {
+	return multiErr{
+		fieldError{field: "Name", reason: "value length must be at least 3 runes"},
+		fieldError{field: "Address", reason: "embedded message failed validation", cause: multiErr{
+			fieldError{field: "Zip", reason: "value length must be 5 runes"},
+		}},
+	}
+}
+
+func TestFieldViolations(t *testing.T) {
+	var mock middleware.Handler = func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	_, err := Validator()(mock)(context.Background(), allVali{})
+	e := kratoserrors.FromError(err)
+	if e.Code != 400 || e.Metadata["Name"] != "value length must be at least 3 runes" || e.Metadata["Address.Zip"] != "value length must be 5 runes" {
+		t.Errorf("unexpected error %v with metadata %v", e, e.Metadata)
+	}
+
+	v := Validator(WithValidateFunc(func(req interface{}) error {
+		return multiErr{fieldError{field: "email", reason: "value must be a valid email address"}, fieldError{field: "email", reason: "value is required"}}
+	}))(mock)
+	_, err = v(context.Background(), protoVali{name: "v1"})
+	if e := kratoserrors.FromError(err); e.Metadata["email"] != "value must be a valid email address; value is required" {
+		t.Errorf("unexpected metadata %v", e.Metadata)
+	}
+}
@@ -0,0 +1,252 @@
+// Package warning attaches non-fatal warnings to the responses, e.g. of the
+// deprecations and the partial degradations, which reach the clients as the
+// Warning headers of RFC 7234, or the gRPC metadata, and the warnings field of
+// the HTTP response envelope of http.EnvelopeWarnings.
+package warning
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Header is the header of the warnings.
+const Header = "Warning"
+
+const (
+	// CodeMiscellaneous is the warn code of a warning to be removed on caching.
+	CodeMiscellaneous = 199
+	// CodePersistent is the warn code of a persistent warning, default of Add.
+	CodePersistent = 299
+)
+
+// defaultMax is the default max number of the warnings of a response.
+const defaultMax = 10
+
+var errInvalid = errors.New("warning: invalid warning header")
+
+// Warning is a warning of a response.
+type Warning struct {
+	Code  int    `json:"code"`
+	Agent string `json:"agent"`
+	Text  string `json:"text"`
+}
+
+// String returns the warning value of the header, e.g. `299 - "deprecated"`.
+func (w Warning) String() string {
+	agent := w.Agent
+	if agent == "" {
+		agent = "-"
+	}
+	return strconv.Itoa(w.Code) + " " + agent + " " + strconv.Quote(w.Text)
+}
+
+// Parse parses the warning value of the header, the warn date is ignored.
+func Parse(s string) (Warning, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), " ", 3) //nolint:gomnd
+	if len(parts) != 3 || len(parts[0]) != 3 {
+		return Warning{}, errInvalid
+	}
+	code, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Warning{}, errInvalid
+	}
+	text := parts[2]
+	if strings.HasPrefix(text, `"`) {
+		// the quoted text may be followed by a quoted warn date.
+		end := 1
+		for ; end < len(text) && text[end] != '"'; end++ {
+			if text[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(text) {
+			return Warning{}, errInvalid
+		}
+		if text, err = strconv.Unquote(text[:end+1]); err != nil {
+			return Warning{}, errInvalid
+		}
+	}
+	return Warning{Code: code, Agent: parts[1], Text: text}, nil
+}
+
+// FromHeader returns the warnings of the header values, the invalid ones are skipped.
+func FromHeader(header transport.Header) []Warning {
+	var warnings []Warning
+	for _, v := range header.Values(Header) {
+		if w, err := Parse(v); err == nil {
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+type warningsKey struct{}
+
+// collector aggregates the warnings of a response.
+type collector struct {
+	mu       sync.Mutex
+	warnings []Warning
+}
+
+func (c *collector) add(w Warning) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, v := range c.warnings {
+		if v.Code == w.Code && v.Text == w.Text {
+			return
+		}
+	}
+	c.warnings = append(c.warnings, w)
+}
+
+func (c *collector) list() []Warning {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.warnings
+}
+
+// Add attaches a persistent warning to the response of the context, reports
+// false if the context is not of the Server middleware.
+func Add(ctx context.Context, text string) bool {
+	return AddWarning(ctx, Warning{Code: CodePersistent, Text: text})
+}
+
+// AddWarning attaches the warning to the response of the context, the same
+// code and text are attached once. It reports false if the context is not of
+// the Server middleware.
+func AddWarning(ctx context.Context, w Warning) bool {
+	c, ok := ctx.Value(warningsKey{}).(*collector)
+	if !ok {
+		return false
+	}
+	c.add(w)
+	return true
+}
+
+// FromContext returns the warnings attached to the response of the context.
+func FromContext(ctx context.Context) []Warning {
+	if c, ok := ctx.Value(warningsKey{}).(*collector); ok {
+		return c.list()
+	}
+	return nil
+}
+
+// Option is warning option.
+type Option func(*options)
+
+type options struct {
+	agent   string
+	max     int
+	logging bool
+	handler func(ctx context.Context, warnings []Warning)
+}
+
+// WithAgent with the warn agent of the warnings without one, e.g. the service
+// name, default is "-".
+func WithAgent(agent string) Option {
+	return func(o *options) {
+		o.agent = agent
+	}
+}
+
+// WithMax with the max number of the warnings of a response, the others are
+// dropped, default is 10.
+func WithMax(n int) Option {
+	return func(o *options) {
+		o.max = n
+	}
+}
+
+// WithLogging with whether the warnings are logged, default is true.
+func WithLogging(enabled bool) Option {
+	return func(o *options) {
+		o.logging = enabled
+	}
+}
+
+// WithHandler with the func called with the warnings of the replies of the
+// Client middleware, e.g. to count the calls of the deprecated APIs.
+func WithHandler(f func(ctx context.Context, warnings []Warning)) Option {
+	return func(o *options) {
+		o.handler = f
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{max: defaultMax, logging: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *options) log(ctx context.Context, warnings []Warning) {
+	if !o.logging || len(warnings) == 0 {
+		return
+	}
+	var operation string
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		operation = tr.Operation()
+	} else if tr, ok := transport.FromClientContext(ctx); ok {
+		operation = tr.Operation()
+	}
+	for _, w := range warnings {
+		log.Context(ctx).Warnf("warning of %s: %s", operation, w)
+	}
+}
+
+// Server is a server middleware which collects the warnings attached by Add
+// of the handlers and the inner middleware, and sets them on the reply header
+// of the response, whether the handler fails or not.
+func Server(opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			c := &collector{}
+			reply, err := handler(context.WithValue(ctx, warningsKey{}, c), req)
+			warnings := c.list()
+			if len(warnings) > o.max {
+				warnings = warnings[:o.max]
+			}
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				for _, w := range warnings {
+					if w.Agent == "" && o.agent != "" {
+						w.Agent = o.agent
+					}
+					tr.ReplyHeader().Add(Header, w.String())
+				}
+			}
+			o.log(ctx, warnings)
+			return reply, err
+		}
+	}
+}
+
+// Client is a client middleware which parses the warnings of the reply header,
+// which are logged and passed to the handler of WithHandler.
+func Client(opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			reply, err := handler(ctx, req)
+			tr, ok := transport.FromClientContext(ctx)
+			if !ok {
+				return reply, err
+			}
+			if warnings := FromHeader(tr.ReplyHeader()); len(warnings) > 0 {
+				o.log(ctx, warnings)
+				if o.handler != nil {
+					o.handler(ctx, warnings)
+				}
+			}
+			return reply, err
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package warning
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string      { return http.Header(hc).Get(key) }
+func (hc headerCarrier) Set(key, value string)      { http.Header(hc).Set(key, value) }
+func (hc headerCarrier) Add(key, value string)      { http.Header(hc).Add(key, value) }
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type testTransport struct {
+	request headerCarrier
+	reply   headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "/test.v1.Test/Get" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.request }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.reply }
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Warning
+		ok   bool
+	}{
+		{`299 - "deprecated"`, Warning{Code: 299, Agent: "-", Text: "deprecated"}, true},
+		{`199 api.example.com "say \"hi\"" "Wed, 21 Oct 2015 07:28:00 GMT"`, Warning{Code: 199, Agent: "api.example.com", Text: `say "hi"`}, true},
+		{`299 -`, Warning{}, false},
+		{`abc - "text"`, Warning{}, false},
+		{`299 - "unterminated`, Warning{}, false},
+	}
+	for _, test := range tests {
+		w, err := Parse(test.in)
+		if (err == nil) != test.ok || w != test.want {
+			t.Errorf("%s: expected %v %v got %v %v", test.in, test.want, test.ok, w, err)
+		}
+		if test.ok {
+			if got, _ := Parse(w.String()); got != w {
+				t.Errorf("%s: expected the round trip of %s got %v", test.in, w, got)
+			}
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	tr := &testTransport{request: headerCarrier{}, reply: headerCarrier{}}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		Add(ctx, "the field name is deprecated")
+		Add(ctx, "the field name is deprecated")
+		AddWarning(ctx, Warning{Code: CodeMiscellaneous, Agent: "search", Text: "partial results"})
+		Add(ctx, "dropped")
+		if n := len(FromContext(ctx)); n != 3 {
+			t.Errorf("expected 3 warnings got %d", n)
+		}
+		return nil, errors.New("failed")
+	}
+	server := Server(WithAgent("api"), WithMax(2), WithLogging(false))
+	_, err := server(handler)(transport.NewServerContext(context.Background(), tr), nil)
+	if err == nil {
+		t.Error("expected the error of the handler")
+	}
+	want := []string{`299 api "the field name is deprecated"`, `199 search "partial results"`}
+	if got := tr.reply.Values(Header); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+	if Add(context.Background(), "lost") {
+		t.Error("expected no warning without the server middleware")
+	}
+}
+
+func TestClient(t *testing.T) {
+	tr := &testTransport{request: headerCarrier{}, reply: headerCarrier{}}
+	var got []Warning
+	client := Client(WithHandler(func(_ context.Context, warnings []Warning) {
+		got = warnings
+	}))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		tr.reply.Add(Header, `299 - "deprecated"`)
+		tr.reply.Add(Header, "invalid")
+		return "reply", nil
+	}
+	reply, err := client(handler)(transport.NewClientContext(context.Background(), tr), nil)
+	if err != nil || reply != "reply" {
+		t.Errorf("unexpected reply %v %v", reply, err)
+	}
+	if want := []Warning{{Code: 299, Agent: "-", Text: "deprecated"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+}
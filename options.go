@@ -22,20 +22,26 @@ type options struct {
 	metadata  map[string]string
 	endpoints []*url.URL
 
-	ctx  context.Context
-	sigs []os.Signal
+	ctx        context.Context
+	sigs       []os.Signal
+	reloadSigs []os.Signal
 
 	logger           log.Logger
 	registrar        registry.Registrar
 	registrarTimeout time.Duration
+	selfTestTimeout  time.Duration
 	stopTimeout      time.Duration
+	drainDelay       time.Duration
 	servers          []transport.Server
+	components       []Component
+	dependencies     []Dependency
 
 	// Before and After funcs
 	beforeStart []func(context.Context) error
 	beforeStop  []func(context.Context) error
 	afterStart  []func(context.Context) error
 	afterStop   []func(context.Context) error
+	reload      []func(context.Context) error
 }
 
 // ID with service id.
@@ -78,11 +84,24 @@ func Server(srv ...transport.Server) Option {
 	return func(o *options) { o.servers = srv }
 }
 
+// Components with the lifecycle components, which are started in the topological
+// order of their dependencies and stopped in the reverse order. The servers of
+// the Server option are started after all the components and stopped first.
+func Components(c ...Component) Option {
+	return func(o *options) { o.components = append(o.components, c...) }
+}
+
 // Signal with exit signals.
 func Signal(sigs ...os.Signal) Option {
 	return func(o *options) { o.sigs = sigs }
 }
 
+// ReloadSignal with reload signals.
+// Receiving one of them runs the Reload hooks instead of stopping the app.
+func ReloadSignal(sigs ...os.Signal) Option {
+	return func(o *options) { o.reloadSigs = sigs }
+}
+
 // Registrar with service registry.
 func Registrar(r registry.Registrar) Option {
 	return func(o *options) { o.registrar = r }
@@ -93,11 +112,26 @@ func RegistrarTimeout(t time.Duration) Option {
 	return func(o *options) { o.registrarTimeout = t }
 }
 
+// SelfTest with the timeout of the self-test of the endpoints of the instance,
+// which are probed once the servers are started and before the registration,
+// so that the app fails to run if any of them is unreachable, e.g. of a wrong
+// advertised address. The endpoints are probed by the transport.Prober of the
+// servers of the same protocols, otherwise by the connections to them.
+func SelfTest(timeout time.Duration) Option {
+	return func(o *options) { o.selfTestTimeout = timeout }
+}
+
 // StopTimeout with app stop timeout.
 func StopTimeout(t time.Duration) Option {
 	return func(o *options) { o.stopTimeout = t }
 }
 
+// DrainDelay with the delay between draining the servers and stopping them,
+// for the load balancers to notice the instance is deregistered and not ready.
+func DrainDelay(d time.Duration) Option {
+	return func(o *options) { o.drainDelay = d }
+}
+
 // Before and Afters
 
 // BeforeStart run funcs before app starts
@@ -127,3 +161,10 @@ func AfterStop(fn func(context.Context) error) Option {
 		o.afterStop = append(o.afterStop, fn)
 	}
 }
+
+// Reload run funcs when a reload signal is received
+func Reload(fn func(context.Context) error) Option {
+	return func(o *options) {
+		o.reload = append(o.reload, fn)
+	}
+}
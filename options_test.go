@@ -188,3 +188,23 @@ func TestAfterStop(t *testing.T) {
 	}
 	AfterStop(v)(o)
 }
+
+func TestReloadSignal(t *testing.T) {
+	o := &options{}
+	v := []os.Signal{os.Interrupt}
+	ReloadSignal(v...)(o)
+	if !reflect.DeepEqual(v, o.reloadSigs) {
+		t.Fatal("o.reloadSigs is not equal to v")
+	}
+}
+
+func TestReload(t *testing.T) {
+	o := &options{}
+	v := func(_ context.Context) error {
+		return nil
+	}
+	Reload(v)(o)
+	if len(o.reload) != 1 {
+		t.Fatal("o.reload is not equal to 1")
+	}
+}
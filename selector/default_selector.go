@@ -2,7 +2,9 @@ package selector
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 var (
@@ -14,8 +16,13 @@ var (
 type Default struct {
 	NodeBuilder WeightedNodeBuilder
 	Balancer    Balancer
+	// SlowStart is the window over which the weight of a newly
+	// added node increases linearly, zero means disabled.
+	SlowStart time.Duration
 
-	nodes atomic.Value
+	nodes  atomic.Value
+	mu     sync.Mutex
+	joined map[string]time.Time
 }
 
 // Select is select one node.
@@ -50,7 +57,9 @@ func (d *Default) Select(ctx context.Context, opts ...SelectOption) (selected No
 	if len(candidates) == 0 {
 		return nil, nil, ErrNoAvailable
 	}
-	wn, done, err := d.Balancer.Pick(ctx, candidates)
+	info := &PickInfo{Operation: options.Operation, Metadata: options.Metadata}
+	info.HashKey, _ = FromHashKeyContext(ctx)
+	wn, pickDone, err := d.Balancer.Pick(NewPickInfoContext(ctx, info), candidates)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -58,14 +67,33 @@ func (d *Default) Select(ctx context.Context, opts ...SelectOption) (selected No
 	if ok {
 		p.Node = wn.Raw()
 	}
-	return wn.Raw(), done, nil
+	start := time.Now()
+	return wn.Raw(), func(ctx context.Context, di DoneInfo) {
+		if di.Latency == 0 {
+			di.Latency = time.Since(start)
+		}
+		if pickDone != nil {
+			pickDone(ctx, di)
+		}
+		if ok {
+			p.DoneInfo = &di
+		}
+	}, nil
 }
 
 // Apply update nodes info.
 func (d *Default) Apply(nodes []Node) {
+	var joined map[string]time.Time
+	if d.SlowStart > 0 {
+		joined = d.joinedAt(nodes)
+	}
 	weightedNodes := make([]WeightedNode, 0, len(nodes))
 	for _, n := range nodes {
-		weightedNodes = append(weightedNodes, d.NodeBuilder.Build(n))
+		wn := d.NodeBuilder.Build(n)
+		if t := joined[n.Address()]; time.Since(t) < d.SlowStart {
+			wn = &slowStartNode{WeightedNode: wn, joined: t, window: d.SlowStart}
+		}
+		weightedNodes = append(weightedNodes, wn)
 	}
 	// TODO: Do not delete unchanged nodes
 	d.nodes.Store(weightedNodes)
@@ -75,6 +103,8 @@ func (d *Default) Apply(nodes []Node) {
 type DefaultBuilder struct {
 	Node     WeightedNodeBuilder
 	Balancer BalancerBuilder
+	// SlowStart is the slow start window of the newly added nodes.
+	SlowStart time.Duration
 }
 
 // Build create builder
@@ -82,5 +112,6 @@ func (db *DefaultBuilder) Build() Selector {
 	return &Default{
 		NodeBuilder: db.Node,
 		Balancer:    db.Balancer.Build(),
+		SlowStart:   db.SlowStart,
 	}
 }
@@ -0,0 +1,14 @@
+// Package selector provides the node selection used by the kratos clients.
+//
+// The extension API for custom balancers is stable:
+//
+//   - A Balancer picks a WeightedNode from the candidates. The Pick context
+//     carries a PickInfo with the request operation, metadata and the hash key
+//     set by the caller with NewHashKeyContext, see FromPickInfoContext.
+//   - The DoneFunc returned by Pick is called once the call to the node is
+//     done, with the error, reply metadata and latency in DoneInfo.
+//   - A Builder is registered for all the clients with SetGlobalSelector.
+//
+// The user code gets the selected node and its DoneInfo from the Peer
+// in the client context, see FromPeerContext.
+package selector
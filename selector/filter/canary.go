@@ -0,0 +1,172 @@
+package filter
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// CanaryOption is canary filter option.
+type CanaryOption func(*Canary)
+
+// WithGroupKey with the metadata key of the node group, default is empty,
+// which groups the nodes by their versions.
+func WithGroupKey(key string) CanaryOption {
+	return func(c *Canary) {
+		c.key = key
+	}
+}
+
+// WithOverrideHeader with the request header forcing the group of a request,
+// e.g. for debugging, default is "X-Canary-Group".
+func WithOverrideHeader(key string) CanaryOption {
+	return func(c *Canary) {
+		c.header = key
+	}
+}
+
+// WithWeights with the initial weights of the groups.
+func WithWeights(weights map[string]int) CanaryOption {
+	return func(c *Canary) {
+		c.Update(weights)
+	}
+}
+
+type groupWeight struct {
+	group  string
+	weight int
+}
+
+// Canary is a weighted traffic splitting filter across the groups of nodes,
+// e.g. 95/5 between the version v1 and v2 nodes. The weights are updated at
+// runtime, e.g. by the config watcher with Observer.
+type Canary struct {
+	key     string
+	header  string
+	weights atomic.Value // []groupWeight
+}
+
+// NewCanary new a canary filter.
+func NewCanary(opts ...CanaryOption) *Canary {
+	c := &Canary{header: "X-Canary-Group"}
+	c.weights.Store([]groupWeight(nil))
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Update updates the weights of the groups, the nodes of the groups
+// without weights are not selected unless the weights are empty.
+func (c *Canary) Update(weights map[string]int) {
+	gws := make([]groupWeight, 0, len(weights))
+	for group, weight := range weights {
+		if weight > 0 {
+			gws = append(gws, groupWeight{group: group, weight: weight})
+		}
+	}
+	sort.Slice(gws, func(i, j int) bool { return gws[i].group < gws[j].group })
+	c.weights.Store(gws)
+}
+
+// Weights returns the current weights of the groups.
+func (c *Canary) Weights() map[string]int {
+	gws := c.weights.Load().([]groupWeight)
+	weights := make(map[string]int, len(gws))
+	for _, gw := range gws {
+		weights[gw.group] = gw.weight
+	}
+	return weights
+}
+
+// Observer returns the config observer updating the weights, e.g.
+//
+//	c.Watch("canary.weights", canary.Observer())
+func (c *Canary) Observer() config.Observer {
+	return func(key string, v config.Value) {
+		var weights map[string]int
+		if err := v.Scan(&weights); err != nil {
+			log.Errorf("canary: failed to scan the weights of %s: %v", key, err)
+			return
+		}
+		c.Update(weights)
+	}
+}
+
+// Filter returns the node filter selecting the nodes of a group, which is the one
+// of the override header of the request, or picked randomly by the weights.
+func (c *Canary) Filter() selector.NodeFilter {
+	return func(ctx context.Context, nodes []selector.Node) []selector.Node {
+		if group, ok := c.override(ctx); ok {
+			return c.group(nodes, group)
+		}
+		gws := c.weights.Load().([]groupWeight)
+		if len(gws) == 0 {
+			return nodes
+		}
+		// only the groups with nodes share the traffic.
+		present := make(map[string]bool, len(gws))
+		for _, n := range nodes {
+			present[c.groupOf(n)] = true
+		}
+		total := 0
+		for _, gw := range gws {
+			if present[gw.group] {
+				total += gw.weight
+			}
+		}
+		if total == 0 {
+			return nodes
+		}
+		cur := rand.Intn(total)
+		for _, gw := range gws {
+			if !present[gw.group] {
+				continue
+			}
+			if cur -= gw.weight; cur < 0 {
+				return c.group(nodes, gw.group)
+			}
+		}
+		return nodes
+	}
+}
+
+func (c *Canary) override(ctx context.Context) (string, bool) {
+	if c.header == "" {
+		return "", false
+	}
+	if tr, ok := transport.FromClientContext(ctx); ok {
+		if group := tr.RequestHeader().Get(c.header); group != "" {
+			return group, true
+		}
+	}
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if group := tr.RequestHeader().Get(c.header); group != "" {
+			return group, true
+		}
+	}
+	return "", false
+}
+
+func (c *Canary) groupOf(n selector.Node) string {
+	if c.key == "" {
+		return n.Version()
+	}
+	return n.Metadata()[c.key]
+}
+
+func (c *Canary) group(nodes []selector.Node, group string) []selector.Node {
+	newNodes := make([]selector.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if c.groupOf(n) == group {
+			newNodes = append(newNodes, n)
+		}
+	}
+	return newNodes
+}
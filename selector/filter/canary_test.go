@@ -0,0 +1,89 @@
+package filter
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string      { return http.Header(hc).Get(key) }
+func (hc headerCarrier) Set(key, value string)      { http.Header(hc).Set(key, value) }
+func (hc headerCarrier) Add(key, value string)      { http.Header(hc).Add(key, value) }
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range hc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type testTransport struct{ header headerCarrier }
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.header }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.header }
+
+func TestCanary(t *testing.T) {
+	group := func(i int) string {
+		if i < 3 {
+			return "v1"
+		}
+		return "v2"
+	}
+	nodes := newNodes(4, group)
+	c := NewCanary(WithGroupKey("zone"))
+	if got := c.Filter()(context.Background(), nodes); len(got) != 4 {
+		t.Errorf("expect all the nodes without weights, got %d", len(got))
+	}
+
+	c.Update(map[string]int{"v1": 90, "v2": 10})
+	counts := map[int]int{}
+	for i := 0; i < 1000; i++ {
+		counts[len(c.Filter()(context.Background(), nodes))]++
+	}
+	if counts[3] < 800 || counts[1] < 50 || counts[3]+counts[1] != 1000 {
+		t.Errorf("expect about 90/10 traffic splitting, got %v", counts)
+	}
+
+	c.Update(map[string]int{"v2": 100, "v3": 100})
+	for i := 0; i < 100; i++ {
+		if got := c.Filter()(context.Background(), nodes); len(got) != 1 {
+			t.Fatalf("expect only the v2 node, got %d", len(got))
+		}
+	}
+
+	ctx := transport.NewClientContext(context.Background(), &testTransport{
+		header: headerCarrier{"X-Canary-Group": []string{"v1"}},
+	})
+	if got := c.Filter()(ctx, nodes); len(got) != 3 {
+		t.Errorf("expect the v1 nodes of the override header, got %d", len(got))
+	}
+}
+
+func TestCanaryObserver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("canary:\n  v1: 95\n  v2: 5\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.New(config.WithSource(file.NewSource(path)))
+	if err := cfg.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer cfg.Close()
+	c := NewCanary()
+	c.Observer()("canary", cfg.Value("canary"))
+	if w := c.Weights(); w["v1"] != 95 || w["v2"] != 5 {
+		t.Errorf("expect the weights of the config, got %v", w)
+	}
+}
@@ -0,0 +1,83 @@
+package filter
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// LocalityOption is locality filter option.
+type LocalityOption func(*localityOptions)
+
+type localityOptions struct {
+	key      string
+	factor   float64
+	minLocal int
+}
+
+// WithZoneKey with the metadata key of the node zone, default is "zone".
+func WithZoneKey(key string) LocalityOption {
+	return func(o *localityOptions) {
+		o.key = key
+	}
+}
+
+// WithRemoteFactor with the factor the weights of the nodes in the other zones
+// are scaled by, default is 0, which only selects the local nodes.
+func WithRemoteFactor(factor float64) LocalityOption {
+	return func(o *localityOptions) {
+		o.factor = factor
+	}
+}
+
+// WithMinLocal with the min number of the local nodes for the locality to
+// apply, default is 1, otherwise all the nodes are selected as they are.
+func WithMinLocal(n int) LocalityOption {
+	return func(o *localityOptions) {
+		o.minLocal = n
+	}
+}
+
+// Locality is a zone aware filter preferring the nodes in the zone of the client,
+// read from the registry metadata of the nodes.
+func Locality(zone string, opts ...LocalityOption) selector.NodeFilter {
+	o := localityOptions{key: "zone", minLocal: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		local := 0
+		for _, n := range nodes {
+			if n.Metadata()[o.key] == zone {
+				local++
+			}
+		}
+		if local == 0 || local < o.minLocal || local == len(nodes) {
+			return nodes
+		}
+		newNodes := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			switch wn, ok := n.(selector.WeightedNode); {
+			case n.Metadata()[o.key] == zone:
+				newNodes = append(newNodes, n)
+			case o.factor > 0 && ok:
+				newNodes = append(newNodes, &remoteNode{WeightedNode: wn, factor: o.factor})
+			case o.factor > 0:
+				newNodes = append(newNodes, n)
+			}
+		}
+		return newNodes
+	}
+}
+
+// remoteNode is a node in another zone whose weight is scaled by the remote factor.
+type remoteNode struct {
+	selector.WeightedNode
+
+	factor float64
+}
+
+// Weight is the runtime calculated weight scaled by the remote factor.
+func (n *remoteNode) Weight() float64 {
+	return n.WeightedNode.Weight() * n.factor
+}
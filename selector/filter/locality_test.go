@@ -0,0 +1,48 @@
+package filter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/node/direct"
+)
+
+func TestLocality(t *testing.T) {
+	zone := func(i int) string {
+		if i < 2 {
+			return "a"
+		}
+		return "b"
+	}
+	nodes := newNodes(5, zone)
+	if got := Locality("a")(context.Background(), nodes); len(got) != 2 {
+		t.Errorf("expect 2 local nodes, got %d", len(got))
+	}
+	if got := Locality("a", WithMinLocal(3))(context.Background(), nodes); len(got) != 5 {
+		t.Errorf("expect all the nodes below the min local, got %d", len(got))
+	}
+	if got := Locality("c")(context.Background(), nodes); len(got) != 5 {
+		t.Errorf("expect all the nodes without local nodes, got %d", len(got))
+	}
+
+	builder := &direct.Builder{}
+	weighted := make([]selector.Node, 0, len(nodes))
+	for _, n := range nodes {
+		weighted = append(weighted, builder.Build(n))
+	}
+	got := Locality("a", WithRemoteFactor(0.5))(context.Background(), weighted)
+	if len(got) != 5 {
+		t.Fatalf("expect 5 nodes, got %d", len(got))
+	}
+	for _, n := range got {
+		wn, ok := n.(selector.WeightedNode)
+		if !ok {
+			t.Fatalf("expect weighted node, got %T", n)
+		}
+		local := wn.Metadata()["zone"] == "a"
+		if remote := weighted[0].(selector.WeightedNode).Weight() * 0.5; !local && wn.Weight() != remote {
+			t.Errorf("expect the remote weight %v, got %v", remote, wn.Weight())
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package filter
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// Subset is a deterministic subset filter, each client selects the size nodes
+// of the highest rendezvous hashes of its key, e.g. the hostname, and the node
+// addresses, so a change of the nodes only moves the subsets it affects. It
+// replaces the random subset of the resolvers, which the clients disable by
+// WithSubset(0).
+func Subset(key string, size int) selector.NodeFilter {
+	var (
+		mu       sync.Mutex
+		last     uint64
+		selected map[string]struct{}
+	)
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		if size <= 0 || len(nodes) <= size {
+			return nodes
+		}
+		fp := fingerprint(nodes)
+		mu.Lock()
+		if selected == nil || fp != last {
+			last, selected = fp, rendezvous(key, nodes, size)
+		}
+		subset := selected
+		mu.Unlock()
+		newNodes := make([]selector.Node, 0, size)
+		for _, n := range nodes {
+			if _, ok := subset[n.Address()]; ok {
+				newNodes = append(newNodes, n)
+			}
+		}
+		return newNodes
+	}
+}
+
+// fingerprint hashes the node addresses.
+func fingerprint(nodes []selector.Node) uint64 {
+	h := fnv.New64a()
+	for _, n := range nodes {
+		_, _ = h.Write([]byte(n.Address()))
+		_, _ = h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+func rendezvous(key string, nodes []selector.Node, size int) map[string]struct{} {
+	type scored struct {
+		addr  string
+		score uint64
+	}
+	scores := make([]scored, 0, len(nodes))
+	for _, n := range nodes {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(n.Address()))
+		scores = append(scores, scored{addr: n.Address(), score: mix(h.Sum64())})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].score != scores[j].score {
+			return scores[i].score > scores[j].score
+		}
+		return scores[i].addr < scores[j].addr
+	})
+	selected := make(map[string]struct{}, size)
+	for _, s := range scores[:size] {
+		selected[s.addr] = struct{}{}
+	}
+	return selected
+}
+
+// mix is the finalizer of splitmix64, spreading the fnv hashes of the similar inputs.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,71 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func newNodes(n int, zone func(i int) string) []selector.Node {
+	nodes := make([]selector.Node, 0, n)
+	for i := 0; i < n; i++ {
+		addr := fmt.Sprintf("127.0.0.%d:9090", i+1)
+		nodes = append(nodes, selector.NewNode("http", addr, &registry.ServiceInstance{
+			ID:        addr,
+			Name:      "helloworld",
+			Metadata:  map[string]string{"zone": zone(i)},
+			Endpoints: []string{"http://" + addr},
+		}))
+	}
+	return nodes
+}
+
+func addresses(nodes []selector.Node) map[string]bool {
+	m := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		m[n.Address()] = true
+	}
+	return m
+}
+
+func TestSubset(t *testing.T) {
+	nodes := newNodes(20, func(int) string { return "" })
+	a := Subset("client-a", 5)(context.Background(), nodes)
+	if len(a) != 5 {
+		t.Fatalf("expect 5 nodes, got %d", len(a))
+	}
+	again := Subset("client-a", 5)(context.Background(), nodes)
+	if fmt.Sprint(addresses(a)) != fmt.Sprint(addresses(again)) {
+		t.Errorf("expect the same subset, got %v and %v", addresses(a), addresses(again))
+	}
+	// removing a node out of the subset keeps it.
+	picked := addresses(a)
+	var rest []selector.Node
+	removed := false
+	for _, n := range nodes {
+		if !removed && !picked[n.Address()] {
+			removed = true
+			continue
+		}
+		rest = append(rest, n)
+	}
+	if got := addresses(Subset("client-a", 5)(context.Background(), rest)); fmt.Sprint(got) != fmt.Sprint(picked) {
+		t.Errorf("expect the subset %v kept, got %v", picked, got)
+	}
+	// the clients spread over the nodes.
+	used := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		for addr := range addresses(Subset(fmt.Sprintf("client-%d", i), 5)(context.Background(), nodes)) {
+			used[addr] = true
+		}
+	}
+	if len(used) < 15 {
+		t.Errorf("expect the subsets spread over the nodes, got %d nodes used", len(used))
+	}
+	if got := Subset("client-a", 50)(context.Background(), nodes); len(got) != 20 {
+		t.Errorf("expect all the nodes, got %d", len(got))
+	}
+}
@@ -3,6 +3,8 @@ package selector
 // SelectOptions is Select Options.
 type SelectOptions struct {
 	NodeFilters []NodeFilter
+	Operation   string
+	Metadata    RequestMD
 }
 
 // SelectOption is Selector option.
@@ -14,3 +16,17 @@ func WithNodeFilter(fn ...NodeFilter) SelectOption {
 		opts.NodeFilters = fn
 	}
 }
+
+// WithOperation with the request operation of the pick.
+func WithOperation(operation string) SelectOption {
+	return func(opts *SelectOptions) {
+		opts.Operation = operation
+	}
+}
+
+// WithRequestMD with the request metadata of the pick.
+func WithRequestMD(md RequestMD) SelectOption {
+	return func(opts *SelectOptions) {
+		opts.Metadata = md
+	}
+}
@@ -0,0 +1,227 @@
+// Package outlier provides the passive health checking of the nodes, which
+// ejects the nodes with high error rates or latencies from the balancing pool
+// for exponentially increasing times, like the Envoy outlier detection.
+package outlier
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// Option is ejector option.
+type Option func(*options)
+
+type options struct {
+	interval           time.Duration
+	consecutiveErrors  int
+	failureRate        float64
+	latency            time.Duration
+	minRequests        int
+	baseEjectionTime   time.Duration
+	maxEjectionTime    time.Duration
+	maxEjectionPercent int
+}
+
+// WithInterval with the interval the error rates and the latencies are
+// tracked over, default is 10s.
+func WithInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.interval = d
+	}
+}
+
+// WithConsecutiveErrors with the number of the consecutive errors ejecting a
+// node, default is 5, zero disables it.
+func WithConsecutiveErrors(n int) Option {
+	return func(o *options) {
+		o.consecutiveErrors = n
+	}
+}
+
+// WithFailureRate with the error rate in an interval ejecting a node, e.g. 0.5,
+// default is disabled.
+func WithFailureRate(rate float64) Option {
+	return func(o *options) {
+		o.failureRate = rate
+	}
+}
+
+// WithLatency with the mean latency in an interval ejecting a node, default is disabled.
+func WithLatency(d time.Duration) Option {
+	return func(o *options) {
+		o.latency = d
+	}
+}
+
+// WithMinRequests with the min requests in an interval for the error rate and
+// the latency to eject a node, default is 10.
+func WithMinRequests(n int) Option {
+	return func(o *options) {
+		o.minRequests = n
+	}
+}
+
+// WithEjectionTime with the base and the max ejection times, the ejection time
+// of a node doubles on each consecutive ejection, default is 30s and 300s.
+func WithEjectionTime(base, max time.Duration) Option {
+	return func(o *options) {
+		o.baseEjectionTime = base
+		o.maxEjectionTime = max
+	}
+}
+
+// WithMaxEjectionPercent with the max percent of the nodes ejected at the same
+// time, default is 50.
+func WithMaxEjectionPercent(percent int) Option {
+	return func(o *options) {
+		o.maxEjectionPercent = percent
+	}
+}
+
+// Ejector tracks the results of the calls per node address. Its node filter
+// skips the ejected nodes during load balancing, and its middleware records
+// the result and the latency of every call on the node which served it.
+type Ejector struct {
+	opts  options
+	now   func() time.Time
+	mu    sync.Mutex
+	nodes map[string]*stat
+}
+
+type stat struct {
+	start       time.Time
+	requests    int
+	failures    int
+	latency     time.Duration
+	consecutive int
+	ejections   int
+	ejected     bool
+	until       time.Time
+}
+
+// New returns an ejector.
+func New(opts ...Option) *Ejector {
+	o := options{
+		interval:           10 * time.Second,
+		consecutiveErrors:  5,
+		minRequests:        10,
+		baseEjectionTime:   30 * time.Second,
+		maxEjectionTime:    300 * time.Second,
+		maxEjectionPercent: 50,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Ejector{opts: o, now: time.Now, nodes: make(map[string]*stat)}
+}
+
+// Ejected reports whether the node of the address is ejected.
+func (e *Ejector) Ejected(address string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s, ok := e.nodes[address]
+	return ok && e.now().Before(s.until)
+}
+
+// Record records the result of a call on the node and ejects it when it is an outlier.
+func (e *Ejector) Record(address string, err error, latency time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	s, ok := e.nodes[address]
+	if !ok {
+		s = &stat{start: now}
+		e.nodes[address] = s
+	}
+	if now.Sub(s.start) >= e.opts.interval {
+		// a node healthy for an interval after its ejection recovers a step of the ejection time.
+		if !s.ejected && s.ejections > 0 {
+			s.ejections--
+		}
+		s.reset(now)
+	}
+	s.requests++
+	s.latency += latency
+	if failed(err) {
+		s.failures++
+		s.consecutive++
+	} else {
+		s.consecutive = 0
+	}
+	if e.outlier(s) {
+		s.ejections++
+		d := e.opts.baseEjectionTime << (s.ejections - 1)
+		if d > e.opts.maxEjectionTime || d <= 0 {
+			d = e.opts.maxEjectionTime
+		}
+		s.until = now.Add(d)
+		s.reset(now)
+		s.ejected = true
+		s.consecutive = 0
+	}
+}
+
+func (e *Ejector) outlier(s *stat) bool {
+	if e.opts.consecutiveErrors > 0 && s.consecutive >= e.opts.consecutiveErrors {
+		return true
+	}
+	if s.requests < e.opts.minRequests {
+		return false
+	}
+	if e.opts.failureRate > 0 && float64(s.failures)/float64(s.requests) >= e.opts.failureRate {
+		return true
+	}
+	return e.opts.latency > 0 && s.latency/time.Duration(s.requests) >= e.opts.latency
+}
+
+func (s *stat) reset(now time.Time) {
+	s.start = now
+	s.requests = 0
+	s.failures = 0
+	s.latency = 0
+	s.ejected = false
+}
+
+// Filter returns the node filter which skips the ejected nodes, up to the max
+// ejection percent of the nodes.
+func (e *Ejector) Filter() selector.NodeFilter {
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		limit := len(nodes) * e.opts.maxEjectionPercent / 100
+		filtered := make([]selector.Node, 0, len(nodes))
+		e.mu.Lock()
+		now := e.now()
+		for _, n := range nodes {
+			if s, ok := e.nodes[n.Address()]; ok && now.Before(s.until) && limit > 0 {
+				limit--
+				continue
+			}
+			filtered = append(filtered, n)
+		}
+		e.mu.Unlock()
+		return filtered
+	}
+}
+
+// Middleware returns the client middleware which records the call results on the nodes.
+func (e *Ejector) Middleware() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			start := time.Now()
+			reply, err := handler(ctx, req)
+			if p, ok := selector.FromPeerContext(ctx); ok && p.Node != nil {
+				e.Record(p.Node.Address(), err, time.Since(start))
+			}
+			return reply, err
+		}
+	}
+}
+
+func failed(err error) bool {
+	c := errors.Classify(err)
+	return c == errors.ClassTransient || c == errors.ClassThrottled
+}
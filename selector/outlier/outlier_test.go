@@ -0,0 +1,98 @@
+package outlier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+var errUnavailable = errors.ServiceUnavailable("UNAVAILABLE", "unavailable")
+
+func newNode(addr string) selector.Node {
+	return selector.NewNode("http", addr, &registry.ServiceInstance{ID: addr, Endpoints: []string{"http://" + addr}})
+}
+
+func TestConsecutiveErrors(t *testing.T) {
+	now := time.Now()
+	e := New(WithConsecutiveErrors(3), WithEjectionTime(time.Second, 3*time.Second))
+	e.now = func() time.Time { return now }
+	eject := func() {
+		for i := 0; i < 3; i++ {
+			e.Record("a", errUnavailable, time.Millisecond)
+		}
+	}
+
+	e.Record("a", errUnavailable, time.Millisecond)
+	e.Record("a", errUnavailable, time.Millisecond)
+	e.Record("a", nil, time.Millisecond)
+	e.Record("a", errors.BadRequest("BAD", "bad"), time.Millisecond)
+	if e.Ejected("a") {
+		t.Fatal("expect not ejected")
+	}
+	eject()
+	if !e.Ejected("a") {
+		t.Fatal("expect ejected")
+	}
+	now = now.Add(time.Second)
+	if e.Ejected("a") {
+		t.Fatal("expect ejection ended after 1s")
+	}
+	// the ejection times double up to the max.
+	for _, d := range []time.Duration{2 * time.Second, 3 * time.Second} {
+		eject()
+		now = now.Add(d - time.Millisecond)
+		if !e.Ejected("a") {
+			t.Fatalf("expect ejected for %v", d)
+		}
+		now = now.Add(time.Millisecond)
+		if e.Ejected("a") {
+			t.Fatalf("expect ejection ended after %v", d)
+		}
+	}
+}
+
+func TestFailureRateAndLatency(t *testing.T) {
+	e := New(WithConsecutiveErrors(0), WithFailureRate(0.5), WithLatency(100*time.Millisecond), WithMinRequests(4))
+	for i := 0; i < 4; i++ {
+		err := error(nil)
+		if i%2 == 0 {
+			err = errUnavailable
+		}
+		e.Record("a", err, time.Millisecond)
+		e.Record("b", nil, time.Second)
+		e.Record("c", nil, time.Millisecond)
+	}
+	if !e.Ejected("a") || !e.Ejected("b") || e.Ejected("c") {
+		t.Errorf("expect a and b ejected, got %v %v %v", e.Ejected("a"), e.Ejected("b"), e.Ejected("c"))
+	}
+}
+
+func TestFilter(t *testing.T) {
+	e := New(WithConsecutiveErrors(1), WithMaxEjectionPercent(50))
+	nodes := []selector.Node{newNode("a"), newNode("b"), newNode("c"), newNode("d")}
+	for _, addr := range []string{"a", "b", "c"} {
+		e.Record(addr, errUnavailable, time.Millisecond)
+	}
+	filtered := e.Filter()(context.Background(), nodes)
+	if len(filtered) != 2 || filtered[len(filtered)-1].Address() != "d" {
+		t.Errorf("expect 2 nodes left with d, got %v", filtered)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	e := New(WithConsecutiveErrors(1))
+	ctx := selector.NewPeerContext(context.Background(), &selector.Peer{Node: newNode("a")})
+	_, err := e.Middleware()(func(context.Context, interface{}) (interface{}, error) {
+		return nil, errUnavailable
+	})(ctx, nil)
+	if err != errUnavailable {
+		t.Fatalf("expect %v, got %v", errUnavailable, err)
+	}
+	if !e.Ejected("a") {
+		t.Error("expect ejected")
+	}
+}
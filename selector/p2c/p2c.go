@@ -23,7 +23,17 @@ var _ selector.Balancer = (*Balancer)(nil)
 type Option func(o *options)
 
 // options is p2c builder options
-type options struct{}
+type options struct {
+	slowStart time.Duration
+}
+
+// WithSlowStart with the slow start window, the weight of a newly added
+// node increases linearly over the window instead of the full share instantly.
+func WithSlowStart(window time.Duration) Option {
+	return func(o *options) {
+		o.slowStart = window
+	}
+}
 
 // New creates a p2c selector.
 func New(opts ...Option) selector.Selector {
@@ -86,8 +96,9 @@ func NewBuilder(opts ...Option) selector.Builder {
 		opt(&option)
 	}
 	return &selector.DefaultBuilder{
-		Balancer: &Builder{},
-		Node:     &ewma.Builder{},
+		SlowStart: option.slowStart,
+		Balancer:  &Builder{},
+		Node:      &ewma.Builder{},
 	}
 }
 
@@ -11,6 +11,9 @@ type peerKey struct{}
 type Peer struct {
 	// node is the peer node.
 	Node Node
+	// DoneInfo is the feedback of the call to the node,
+	// it is set once the call to the selected node is done.
+	DoneInfo *DoneInfo
 }
 
 // NewPeerContext creates a new context with peer information attached.
@@ -23,3 +26,41 @@ func FromPeerContext(ctx context.Context) (p *Peer, ok bool) {
 	p, ok = ctx.Value(peerKey{}).(*Peer)
 	return
 }
+
+type pickInfoKey struct{}
+
+// PickInfo is the per-pick request info, the balancers can get it
+// from the Pick context with FromPickInfoContext.
+type PickInfo struct {
+	// Operation is the request operation.
+	Operation string
+	// Metadata is the request metadata, it may be nil.
+	Metadata RequestMD
+	// HashKey is the key set by NewHashKeyContext for the hash based balancers.
+	HashKey string
+}
+
+// NewPickInfoContext creates a new context with pick information attached.
+func NewPickInfoContext(ctx context.Context, info *PickInfo) context.Context {
+	return context.WithValue(ctx, pickInfoKey{}, info)
+}
+
+// FromPickInfoContext returns the pick information in ctx if it exists.
+func FromPickInfoContext(ctx context.Context) (info *PickInfo, ok bool) {
+	info, ok = ctx.Value(pickInfoKey{}).(*PickInfo)
+	return
+}
+
+type hashKey struct{}
+
+// NewHashKeyContext creates a new context with the hash key of the request,
+// which is passed to the balancers by PickInfo.
+func NewHashKeyContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, hashKey{}, key)
+}
+
+// FromHashKeyContext returns the hash key in ctx if it exists.
+func FromHashKeyContext(ctx context.Context) (key string, ok bool) {
+	key, ok = ctx.Value(hashKey{}).(string)
+	return
+}
@@ -0,0 +1,153 @@
+package ringhash
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/node/direct"
+)
+
+const (
+	// Name is ring hash balancer name
+	Name = "ringhash"
+)
+
+var _ selector.Balancer = (*Balancer)(nil)
+
+// Option is ring hash builder option.
+type Option func(o *options)
+
+// options is ring hash builder options
+type options struct {
+	replicas int
+}
+
+// WithReplicas with the number of the virtual nodes of a node on the ring,
+// scaled by the initial weights of the nodes, default is 160.
+func WithReplicas(n int) Option {
+	return func(o *options) {
+		o.replicas = n
+	}
+}
+
+// Balancer is a consistent hash balancer, the requests of the same hash key,
+// set by selector.NewHashKeyContext, are routed to the same node as long as
+// it is available. The requests without a hash key are routed randomly.
+type Balancer struct {
+	replicas int
+
+	mu          sync.Mutex
+	fingerprint uint64
+	ring        []point
+}
+
+type point struct {
+	hash  uint64
+	index int
+}
+
+// New a ring hash selector.
+func New(opts ...Option) selector.Selector {
+	return NewBuilder(opts...).Build()
+}
+
+// Pick is pick a weighted node.
+func (b *Balancer) Pick(ctx context.Context, nodes []selector.WeightedNode) (selector.WeightedNode, selector.DoneFunc, error) {
+	if len(nodes) == 0 {
+		return nil, nil, selector.ErrNoAvailable
+	}
+	info, ok := selector.FromPickInfoContext(ctx)
+	if !ok || info.HashKey == "" {
+		selected := nodes[rand.Intn(len(nodes))]
+		return selected, selected.Pick(), nil
+	}
+	ring := b.build(nodes)
+	h := hash(info.HashKey)
+	i := sort.Search(len(ring), func(i int) bool { return ring[i].hash >= h })
+	if i == len(ring) {
+		i = 0
+	}
+	selected := nodes[ring[i].index]
+	return selected, selected.Pick(), nil
+}
+
+// build returns the ring of the nodes, which is rebuilt when the nodes change.
+func (b *Balancer) build(nodes []selector.WeightedNode) []point {
+	f := fnv.New64a()
+	for _, n := range nodes {
+		_, _ = f.Write([]byte(n.Address()))
+		_, _ = f.Write([]byte{0})
+	}
+	fp := f.Sum64()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ring != nil && b.fingerprint == fp {
+		return b.ring
+	}
+	var max int64 = 1
+	for _, n := range nodes {
+		if w := n.InitialWeight(); w != nil && *w > max {
+			max = *w
+		}
+	}
+	ring := make([]point, 0, len(nodes)*b.replicas)
+	for i, n := range nodes {
+		replicas := b.replicas
+		if w := n.InitialWeight(); w != nil && max > 1 {
+			replicas = int(int64(b.replicas) * *w / max)
+		}
+		if replicas < 1 {
+			replicas = 1
+		}
+		for r := 0; r < replicas; r++ {
+			ring = append(ring, point{hash: hash(n.Address() + "#" + strconv.Itoa(r)), index: i})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i].hash < ring[j].hash })
+	b.fingerprint, b.ring = fp, ring
+	return ring
+}
+
+func hash(s string) uint64 {
+	f := fnv.New64a()
+	_, _ = f.Write([]byte(s))
+	// the finalizer of splitmix64 spreads the fnv hashes of the similar keys.
+	x := f.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// NewBuilder returns a selector builder with ring hash balancer
+func NewBuilder(opts ...Option) selector.Builder {
+	option := options{replicas: 160}
+	for _, opt := range opts {
+		opt(&option)
+	}
+	return &selector.DefaultBuilder{
+		Balancer: &Builder{replicas: option.replicas},
+		Node:     &direct.Builder{},
+	}
+}
+
+// Builder is ring hash builder
+type Builder struct {
+	replicas int
+}
+
+// Build creates Balancer
+func (b *Builder) Build() selector.Balancer {
+	replicas := b.replicas
+	if replicas <= 0 {
+		replicas = 160
+	}
+	return &Balancer{replicas: replicas}
+}
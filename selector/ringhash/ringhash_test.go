@@ -0,0 +1,69 @@
+package ringhash
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+func nodes(n int) []selector.Node {
+	nodes := make([]selector.Node, 0, n)
+	for i := 0; i < n; i++ {
+		addr := fmt.Sprintf("127.0.0.%d:8080", i+1)
+		nodes = append(nodes, selector.NewNode("http", addr, &registry.ServiceInstance{
+			ID:        addr,
+			Endpoints: []string{"http://" + addr},
+		}))
+	}
+	return nodes
+}
+
+func pick(t *testing.T, s selector.Selector, key string) string {
+	ctx := context.Background()
+	if key != "" {
+		ctx = selector.NewHashKeyContext(ctx, key)
+	}
+	n, done, err := s.Select(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done(ctx, selector.DoneInfo{})
+	return n.Address()
+}
+
+func TestRingHash(t *testing.T) {
+	s := New()
+	if _, _, err := s.Select(context.Background()); err == nil {
+		t.Fatal("expect no available node")
+	}
+	s.Apply(nodes(5))
+	before := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		before[key] = pick(t, s, key)
+		if got := pick(t, s, key); got != before[key] {
+			t.Fatalf("expect %s routed to %s, got %s", key, before[key], got)
+		}
+	}
+	// removing a node only moves its keys.
+	s.Apply(nodes(4))
+	moved := 0
+	for key, addr := range before {
+		got := pick(t, s, key)
+		if got != addr {
+			if addr != "127.0.0.5:8080" {
+				t.Errorf("expect %s kept on %s, got %s", key, addr, got)
+			}
+			moved++
+		}
+	}
+	if moved == 0 || moved > 50 {
+		t.Errorf("expect the keys of the removed node moved, got %d", moved)
+	}
+	if pick(t, s, "") == "" {
+		t.Error("expect a random node without the hash key")
+	}
+}
@@ -2,6 +2,7 @@ package selector
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/errors"
 )
@@ -63,6 +64,9 @@ type DoneInfo struct {
 	BytesSent bool
 	// BytesReceived indicates if any byte has been received from the server.
 	BytesReceived bool
+	// Latency is the time elapsed since the node was picked,
+	// it is filled by the selector if the transport does not set it.
+	Latency time.Duration
 }
 
 // ReplyMD is Reply Metadata.
@@ -70,5 +74,10 @@ type ReplyMD interface {
 	Get(key string) string
 }
 
+// RequestMD is Request Metadata.
+type RequestMD interface {
+	Get(key string) string
+}
+
 // DoneFunc is callback function when RPC invoke done.
 type DoneFunc func(ctx context.Context, di DoneInfo)
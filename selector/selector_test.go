@@ -289,3 +289,93 @@ func TestGlobalSelector(t *testing.T) {
 		t.Errorf("expect %v, got %v", nil, gBuilder)
 	}
 }
+
+type mockInfoBalancer struct {
+	info *PickInfo
+}
+
+func (b *mockInfoBalancer) Pick(ctx context.Context, nodes []WeightedNode) (selected WeightedNode, done DoneFunc, err error) {
+	b.info, _ = FromPickInfoContext(ctx)
+	return nodes[0], nodes[0].Pick(), nil
+}
+
+type mockRequestMD map[string]string
+
+func (md mockRequestMD) Get(key string) string {
+	return md[key]
+}
+
+func TestDefaultFeedback(t *testing.T) {
+	balancer := &mockInfoBalancer{}
+	selector := &Default{
+		NodeBuilder: &mockWeightedNodeBuilder{},
+		Balancer:    balancer,
+	}
+	selector.Apply([]Node{NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{
+		ID:   "127.0.0.1:8080",
+		Name: "helloworld",
+	})})
+
+	var p Peer
+	ctx := NewPeerContext(NewHashKeyContext(context.Background(), "user-1"), &p)
+	_, done, err := selector.Select(ctx, WithOperation("/helloworld/SayHello"), WithRequestMD(mockRequestMD{"x-md": "value"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balancer.info == nil {
+		t.Fatal("expect pick info, got nil")
+	}
+	if balancer.info.Operation != "/helloworld/SayHello" {
+		t.Errorf("expect %v, got %v", "/helloworld/SayHello", balancer.info.Operation)
+	}
+	if balancer.info.HashKey != "user-1" {
+		t.Errorf("expect %v, got %v", "user-1", balancer.info.HashKey)
+	}
+	if v := balancer.info.Metadata.Get("x-md"); v != "value" {
+		t.Errorf("expect %v, got %v", "value", v)
+	}
+
+	time.Sleep(time.Millisecond)
+	done(ctx, DoneInfo{Err: errNodeNotMatch})
+	if p.DoneInfo == nil {
+		t.Fatal("expect done info, got nil")
+	}
+	if !errors.Is(p.DoneInfo.Err, errNodeNotMatch) {
+		t.Errorf("expect %v, got %v", errNodeNotMatch, p.DoneInfo.Err)
+	}
+	if p.DoneInfo.Latency < time.Millisecond {
+		t.Errorf("expect latency >= %v, got %v", time.Millisecond, p.DoneInfo.Latency)
+	}
+}
+
+func TestDefaultSlowStart(t *testing.T) {
+	selector := &Default{
+		NodeBuilder: &mockWeightedNodeBuilder{},
+		Balancer:    &mockBalancer{},
+		SlowStart:   time.Second,
+	}
+	warm := NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{ID: "1"})
+	cold := NewNode("http", "127.0.0.1:9090", &registry.ServiceInstance{ID: "2"})
+	selector.Apply([]Node{warm})
+	selector.Apply([]Node{warm, cold})
+
+	weights := make(map[string]float64)
+	for _, n := range selector.nodes.Load().([]WeightedNode) {
+		weights[n.Address()] = n.Weight()
+	}
+	if w := weights[warm.Address()]; w != 100 {
+		t.Errorf("expect %v, got %v", 100, w)
+	}
+	if w := weights[cold.Address()]; w < 10 || w > 50 {
+		t.Errorf("expect slow start weight in [10, 50], got %v", w)
+	}
+
+	// the join time is kept when the nodes are applied again.
+	time.Sleep(100 * time.Millisecond)
+	selector.Apply([]Node{warm, cold})
+	for _, n := range selector.nodes.Load().([]WeightedNode) {
+		if n.Address() == cold.Address() && n.Weight() <= weights[cold.Address()] {
+			t.Errorf("expect weight increased from %v, got %v", weights[cold.Address()], n.Weight())
+		}
+	}
+}
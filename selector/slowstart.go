@@ -0,0 +1,50 @@
+package selector
+
+import (
+	"time"
+)
+
+// minSlowStartFactor is the minimal weight factor of a node in slow start,
+// so that a newly added node always receives some traffic.
+const minSlowStartFactor = 0.1
+
+// slowStartNode is a newly added node whose weight increases linearly
+// over the slow start window.
+type slowStartNode struct {
+	WeightedNode
+
+	joined time.Time
+	window time.Duration
+}
+
+// Weight is the runtime calculated weight scaled by the slow start factor.
+func (n *slowStartNode) Weight() float64 {
+	w := n.WeightedNode.Weight()
+	elapsed := time.Since(n.joined)
+	if elapsed >= n.window {
+		return w
+	}
+	factor := float64(elapsed) / float64(n.window)
+	if factor < minSlowStartFactor {
+		factor = minSlowStartFactor
+	}
+	return w * factor
+}
+
+// joinedAt records the join time of the nodes, the nodes of the
+// first apply are treated as warm.
+func (d *Default) joinedAt(nodes []Node) map[string]time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	joined := make(map[string]time.Time, len(nodes))
+	for _, n := range nodes {
+		t, ok := d.joined[n.Address()]
+		if !ok && d.joined != nil {
+			t = now
+		}
+		joined[n.Address()] = t
+	}
+	d.joined = joined
+	return joined
+}
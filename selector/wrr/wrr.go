@@ -3,6 +3,7 @@ package wrr
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/go-kratos/kratos/v2/selector/node/direct"
@@ -19,7 +20,17 @@ var _ selector.Balancer = (*Balancer)(nil) // Name is balancer name
 type Option func(o *options)
 
 // options is wrr builder options
-type options struct{}
+type options struct {
+	slowStart time.Duration
+}
+
+// WithSlowStart with the slow start window, the weight of a newly added
+// node increases linearly over the window instead of the full share instantly.
+func WithSlowStart(window time.Duration) Option {
+	return func(o *options) {
+		o.slowStart = window
+	}
+}
 
 // Balancer is a wrr balancer.
 type Balancer struct {
@@ -68,8 +79,9 @@ func NewBuilder(opts ...Option) selector.Builder {
 		opt(&option)
 	}
 	return &selector.DefaultBuilder{
-		Balancer: &Builder{},
-		Node:     &direct.Builder{},
+		SlowStart: option.slowStart,
+		Balancer:  &Builder{},
+		Node:      &direct.Builder{},
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
@@ -64,3 +65,25 @@ func TestEmpty(t *testing.T) {
 		t.Errorf("expect no error, got %v", err)
 	}
 }
+
+func TestSlowStart(t *testing.T) {
+	wrr := New(WithSlowStart(time.Hour))
+	warm := selector.NewNode("http", "127.0.0.1:8080", &registry.ServiceInstance{ID: "127.0.0.1:8080"})
+	cold := selector.NewNode("http", "127.0.0.1:9090", &registry.ServiceInstance{ID: "127.0.0.1:9090"})
+	wrr.Apply([]selector.Node{warm})
+	wrr.Apply([]selector.Node{warm, cold})
+	var count int
+	for i := 0; i < 110; i++ {
+		n, done, err := wrr.Select(context.Background())
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		done(context.Background(), selector.DoneInfo{})
+		if n.Address() == cold.Address() {
+			count++
+		}
+	}
+	if count != 10 {
+		t.Errorf("expect 10, got %d", count)
+	}
+}
@@ -0,0 +1,105 @@
+package kratos
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/go-kratos/kratos/v2/internal/endpoint"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// selfTestInterval is the interval of the probes of an endpoint, which are
+// retried until the timeout, as the servers may not serve yet.
+const selfTestInterval = 100 * time.Millisecond
+
+// selfTest probes the endpoints of the instance concurrently.
+func (a *App) selfTest(ctx context.Context, instance *registry.ServiceInstance) error {
+	ctx, cancel := context.WithTimeout(ctx, a.opts.selfTestTimeout)
+	defer cancel()
+	probers := make(map[string]transport.Prober)
+	for _, srv := range a.servers() {
+		p, ok := srv.(transport.Prober)
+		if !ok {
+			continue
+		}
+		es, err := serverEndpoints(srv)
+		if err != nil {
+			return err
+		}
+		for _, e := range es {
+			if _, ok := probers[protocol(e)]; !ok {
+				probers[protocol(e)] = p
+			}
+		}
+	}
+	var eg errgroup.Group
+	for _, e := range instance.Endpoints {
+		e := e
+		eg.Go(func() error {
+			u, err := url.Parse(e)
+			if err != nil {
+				return fmt.Errorf("kratos: self-test of endpoint %s: %w", e, err)
+			}
+			probe := dialProbe
+			if p, ok := probers[protocol(u)]; ok {
+				probe = p.Probe
+			}
+			for {
+				if err = probe(ctx, u); err == nil {
+					return nil
+				}
+				select {
+				case <-ctx.Done():
+					return fmt.Errorf("kratos: self-test of endpoint %s: %w", e, err)
+				case <-time.After(selfTestInterval):
+				}
+			}
+		})
+	}
+	return eg.Wait()
+}
+
+// serverEndpoints returns the endpoints advertised by the server.
+func serverEndpoints(srv transport.Server) ([]*url.URL, error) {
+	if r, ok := srv.(transport.Endpointers); ok {
+		return r.Endpoints()
+	}
+	if r, ok := srv.(transport.Endpointer); ok {
+		e, err := r.Endpoint()
+		if err != nil {
+			return nil, err
+		}
+		return []*url.URL{e}, nil
+	}
+	return nil, nil
+}
+
+// protocol returns the scheme of the endpoint, which is the protocol of the
+// query of the unix domain socket endpoints.
+func protocol(u *url.URL) string {
+	if u.Scheme == endpoint.UnixScheme {
+		return u.Query().Get("protocol")
+	}
+	return u.Scheme
+}
+
+// dialProbe probes the endpoint by a connection to it.
+func dialProbe(ctx context.Context, u *url.URL) error {
+	network, addr := "tcp", u.Host
+	if u.Scheme == endpoint.UnixScheme {
+		network = "unix"
+		addr, _ = endpoint.ParseUnixAddress(endpoint.UnixAddress(u))
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
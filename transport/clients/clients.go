@@ -0,0 +1,126 @@
+// Package clients provides the typed service clients created lazily from the
+// service discovery and cached per target, so the apps do not hand-roll the
+// client singletons and leak their connections.
+package clients
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	kgrpc "github.com/go-kratos/kratos/v2/transport/grpc"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// ErrClosed is returned by Get after the client is closed.
+var ErrClosed = errors.New("clients: client is closed")
+
+// Constructor creates the client of the target and the closer releasing it.
+// The context is canceled once the client is closed.
+type Constructor[T any] func(ctx context.Context, target string) (T, io.Closer, error)
+
+// Client is a typed service client created on the first Get.
+type Client[T any] struct {
+	target      string
+	constructor Constructor[T]
+	ctx         context.Context
+	cancel      context.CancelFunc
+
+	mu      sync.Mutex
+	client  T
+	closer  io.Closer
+	created bool
+	closed  bool
+}
+
+// New returns the lazy client of the discovery name, whose target is
+// discovery:///<name>. The clients created by the GRPC and HTTP constructors
+// with a discovery re-resolve the instances on the registry changes.
+func New[T any](name string, constructor Constructor[T]) *Client[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Client[T]{
+		target:      "discovery:///" + name,
+		constructor: constructor,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Target returns the target of the client.
+func (c *Client[T]) Target() string {
+	return c.target
+}
+
+// Get returns the client, creating it on the first call, a failed creation
+// is retried on the next call.
+func (c *Client[T]) Get() (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		var zero T
+		return zero, ErrClosed
+	}
+	if !c.created {
+		client, closer, err := c.constructor(c.ctx, c.target)
+		if err != nil {
+			return client, err
+		}
+		c.client, c.closer, c.created = client, closer, true
+	}
+	return c.client, nil
+}
+
+// Close closes the client if created, Get returns ErrClosed after it.
+func (c *Client[T]) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	c.cancel()
+	if !c.created || c.closer == nil {
+		return nil
+	}
+	return c.closer.Close()
+}
+
+// GRPC returns the constructor of a typed gRPC client, e.g. pb.NewGreeterClient,
+// dialing the target with the options, e.g. grpc.WithDiscovery.
+func GRPC[T any](newClient func(grpc.ClientConnInterface) T, opts ...kgrpc.ClientOption) Constructor[T] {
+	return grpcConstructor(kgrpc.Dial, newClient, opts)
+}
+
+// GRPCInsecure returns the constructor of a typed gRPC client dialing the target insecurely.
+func GRPCInsecure[T any](newClient func(grpc.ClientConnInterface) T, opts ...kgrpc.ClientOption) Constructor[T] {
+	return grpcConstructor(kgrpc.DialInsecure, newClient, opts)
+}
+
+func grpcConstructor[T any](dial func(context.Context, ...kgrpc.ClientOption) (*grpc.ClientConn, error),
+	newClient func(grpc.ClientConnInterface) T, opts []kgrpc.ClientOption,
+) Constructor[T] {
+	return func(ctx context.Context, target string) (T, io.Closer, error) {
+		conn, err := dial(ctx, append([]kgrpc.ClientOption{kgrpc.WithEndpoint(target)}, opts...)...)
+		if err != nil {
+			var zero T
+			return zero, nil, err
+		}
+		return newClient(conn), conn, nil
+	}
+}
+
+// HTTP returns the constructor of a typed HTTP client, e.g. pb.NewGreeterHTTPClient,
+// with the options, e.g. http.WithDiscovery.
+func HTTP[T any](newClient func(*khttp.Client) T, opts ...khttp.ClientOption) Constructor[T] {
+	return func(ctx context.Context, target string) (T, io.Closer, error) {
+		client, err := khttp.NewClient(ctx, append([]khttp.ClientOption{khttp.WithEndpoint(target)}, opts...)...)
+		if err != nil {
+			var zero T
+			return zero, nil, err
+		}
+		return newClient(client), client, nil
+	}
+}
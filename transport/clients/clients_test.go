@@ -0,0 +1,69 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+type closer struct {
+	closed int
+}
+
+func (c *closer) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestClient(t *testing.T) {
+	var (
+		calls int
+		fail  = true
+		cl    = &closer{}
+		ctx   context.Context
+	)
+	c := New("helloworld", func(c context.Context, target string) (string, io.Closer, error) {
+		calls++
+		ctx = c
+		if fail {
+			return "", nil, errors.New("unavailable")
+		}
+		return target, cl, nil
+	})
+	if c.Target() != "discovery:///helloworld" {
+		t.Errorf("expected discovery:///helloworld got %s", c.Target())
+	}
+	if calls != 0 {
+		t.Errorf("expected lazy creation, got %d calls", calls)
+	}
+	if _, err := c.Get(); err == nil {
+		t.Error("expected error")
+	}
+	fail = false
+	for i := 0; i < 2; i++ {
+		v, err := c.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != "discovery:///helloworld" {
+			t.Errorf("expected discovery:///helloworld got %s", v)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls got %d", calls)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Close()
+	if cl.closed != 1 {
+		t.Errorf("expected closed once got %d", cl.closed)
+	}
+	if ctx.Err() == nil {
+		t.Error("expected the context canceled")
+	}
+	if _, err := c.Get(); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed got %v", err)
+	}
+}
@@ -0,0 +1,296 @@
+// Package cron is the server of the scheduled jobs, which runs the functions
+// of the cron expressions through the middleware, e.g. the logging, tracing
+// and recovery, and starts and stops with kratos.App:
+//
+//	srv := cron.NewServer(
+//		cron.Middleware(recovery.Recovery(), logging.Server(logger)),
+//		cron.WithLocker(locker),
+//	)
+//	_ = srv.Add("report.daily", "0 3 * * *", service.DailyReport)
+//	httpSrv.HandleAdmin("/debug/cron", srv.Handler())
+//	app := kratos.New(kratos.Server(httpSrv, srv))
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Server = (*Server)(nil)
+
+// ErrServerStarted is returned by Add and Start after the server is started.
+var ErrServerStarted = errors.New("cron: server started")
+
+// Job is a scheduled job.
+type Job func(ctx context.Context) error
+
+// Locker is the distributed lock of the runs of the jobs, so that a run is
+// of one of the replicas, e.g. of SET NX PX of Redis.
+type Locker interface {
+	// Acquire acquires the lock of the key for the TTL, and reports false if
+	// it is held, the lock is not released but expires.
+	Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// ServerOption is cron server option.
+type ServerOption func(*Server)
+
+// Middleware with server middleware, the requests of which are nil.
+func Middleware(m ...middleware.Middleware) ServerOption {
+	return func(s *Server) {
+		s.middleware = m
+	}
+}
+
+// WithLocker with the locker of the runs of the jobs, whose keys are the
+// names and the scheduled times of the runs, default is none of a single replica.
+func WithLocker(l Locker) ServerOption {
+	return func(s *Server) {
+		s.locker = l
+	}
+}
+
+// Location with the location of the schedules, default is time.Local.
+func Location(loc *time.Location) ServerOption {
+	return func(s *Server) {
+		s.location = loc
+	}
+}
+
+// Timeout with the timeout of a run, default is none.
+func Timeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeout = timeout
+	}
+}
+
+// JobInfo is the information of a job.
+type JobInfo struct {
+	Name      string    `json:"name"`
+	Spec      string    `json:"spec,omitempty"`
+	Next      time.Time `json:"next"`
+	Last      time.Time `json:"last,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	Runs      uint64    `json:"runs"`
+	Skipped   uint64    `json:"skipped"`
+}
+
+type entry struct {
+	name     string
+	spec     string
+	schedule Schedule
+	handler  middleware.Handler
+
+	mu      sync.Mutex
+	next    time.Time
+	last    time.Time
+	lastErr error
+	runs    uint64
+	skipped uint64
+}
+
+// Server is a cron server of the scheduled jobs.
+type Server struct {
+	middleware []middleware.Middleware
+	locker     Locker
+	location   *time.Location
+	timeout    time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries []*entry
+	started bool
+	stop    context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewServer creates a cron server by options.
+func NewServer(opts ...ServerOption) *Server {
+	srv := &Server{
+		location: time.Local,
+		now:      time.Now,
+	}
+	for _, o := range opts {
+		o(srv)
+	}
+	return srv
+}
+
+// Add adds the job of the name and the cron expression, see Parse.
+func (s *Server) Add(name, spec string, job Job) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+	return s.add(name, spec, schedule, job)
+}
+
+// AddSchedule adds the job of the name and the schedule.
+func (s *Server) AddSchedule(name string, schedule Schedule, job Job) error {
+	return s.add(name, "", schedule, job)
+}
+
+func (s *Server) add(name, spec string, schedule Schedule, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return ErrServerStarted
+	}
+	h := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return nil, job(ctx)
+	}
+	if len(s.middleware) > 0 {
+		h = middleware.Chain(s.middleware...)(h)
+	}
+	s.entries = append(s.entries, &entry{name: name, spec: spec, schedule: schedule, handler: h})
+	return nil
+}
+
+// Start starts running the jobs and blocks until the server is stopped.
+func (s *Server) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return ErrServerStarted
+	}
+	s.started = true
+	ctx, s.stop = context.WithCancel(ctx)
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go func(e *entry) {
+			defer s.wg.Done()
+			s.loop(ctx, e)
+		}(e)
+	}
+	s.mu.Unlock()
+	log.Infof("[cron] server started with %d jobs", len(s.entries))
+	<-ctx.Done()
+	return nil
+}
+
+// Stop stops scheduling the jobs, and waits for the runs until ctx is done.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	stop := s.stop
+	s.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+	log.Info("[cron] server stopping")
+	stop()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop runs the job at the times of its schedule, the runs missed while it
+// is running are skipped.
+func (s *Server) loop(ctx context.Context, e *entry) {
+	for {
+		next := e.schedule.Next(s.now().In(s.location))
+		e.mu.Lock()
+		e.next = next
+		e.mu.Unlock()
+		if next.IsZero() {
+			return
+		}
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.run(ctx, e, next)
+	}
+}
+
+func (s *Server) run(ctx context.Context, e *entry, scheduled time.Time) {
+	if s.locker != nil {
+		ttl := e.schedule.Next(scheduled).Sub(scheduled)
+		if ttl < time.Second {
+			ttl = time.Second
+		}
+		ok, err := s.locker.Acquire(ctx, "cron:"+e.name+":"+strconv.FormatInt(scheduled.Unix(), 10), ttl)
+		if err != nil || !ok {
+			if err != nil {
+				log.Errorf("[cron] failed to lock the job %s: %v", e.name, err)
+			}
+			e.mu.Lock()
+			e.skipped++
+			e.mu.Unlock()
+			return
+		}
+	}
+	ctx = transport.NewServerContext(ctx, &Transport{
+		operation:   e.name,
+		scheduled:   scheduled,
+		reqHeader:   headerCarrier{},
+		replyHeader: headerCarrier{},
+	})
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	_, err := e.handler(ctx, nil)
+	if err != nil {
+		log.Errorf("[cron] job %s failed: %v", e.name, err)
+	}
+	e.mu.Lock()
+	e.last, e.lastErr = scheduled, err
+	e.runs++
+	e.mu.Unlock()
+}
+
+// Jobs returns the information of the jobs sorted by the next runs.
+func (s *Server) Jobs() []JobInfo {
+	s.mu.Lock()
+	entries := append([]*entry(nil), s.entries...)
+	s.mu.Unlock()
+	infos := make([]JobInfo, 0, len(entries))
+	for _, e := range entries {
+		e.mu.Lock()
+		info := JobInfo{Name: e.name, Spec: e.spec, Next: e.next, Last: e.last, Runs: e.runs, Skipped: e.skipped}
+		if e.lastErr != nil {
+			info.LastError = e.lastErr.Error()
+		}
+		e.mu.Unlock()
+		if info.Next.IsZero() {
+			info.Next = e.schedule.Next(s.now().In(s.location))
+		}
+		infos = append(infos, info)
+	}
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].Next.Before(infos[j].Next) })
+	return infos
+}
+
+// Handler returns the admin handler which returns the information of the jobs in JSON.
+func (s *Server) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.Jobs())
+	})
+}
@@ -0,0 +1,120 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type interval time.Duration
+
+func (i interval) Next(t time.Time) time.Time { return t.Add(time.Duration(i)) }
+
+type memoryLocker struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func (l *memoryLocker) Acquire(_ context.Context, key string, _ time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.keys[key] {
+		return false, nil
+	}
+	l.keys[key] = true
+	return true, nil
+}
+
+func serve(t *testing.T, srv *Server) {
+	t.Helper()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Start(context.Background()) }()
+	t.Cleanup(func() {
+		if err := srv.Stop(context.Background()); err != nil {
+			t.Error(err)
+		}
+		if err := <-errc; err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestServer(t *testing.T) {
+	var operation atomic.Value
+	m := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok && tr.Kind() == transport.KindCron {
+				operation.Store(tr.Operation())
+			}
+			return handler(ctx, req)
+		}
+	}
+	srv := NewServer(Middleware(m))
+	runs := make(chan struct{}, 10)
+	if err := srv.AddSchedule("tick", interval(10*time.Millisecond), func(ctx context.Context) error {
+		runs <- struct{}{}
+		return errors.New("failed")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Add("daily", "0 3 * * *", func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Add("invalid", "0 3 * *", nil); err == nil {
+		t.Error("expected the invalid expression")
+	}
+	serve(t, srv)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	if v := operation.Load(); v != "tick" {
+		t.Errorf("unexpected operation %v", v)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/cron", nil))
+	var infos []JobInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &infos); err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[0].Name != "tick" || infos[1].Name != "daily" || infos[1].Spec != "0 3 * * *" {
+		t.Fatalf("unexpected jobs %+v", infos)
+	}
+	if infos[0].Runs < 1 || infos[0].LastError != "failed" || infos[1].Runs != 0 || infos[1].Next.Hour() != 3 {
+		t.Errorf("unexpected jobs %+v", infos)
+	}
+	if err := srv.Add("late", "@daily", nil); !errors.Is(err, ErrServerStarted) {
+		t.Errorf("expected the server started, got %v", err)
+	}
+}
+
+func TestLocker(t *testing.T) {
+	locker := &memoryLocker{keys: map[string]bool{}}
+	var runs atomic.Int32
+	scheduled := time.Now().Add(time.Hour).Truncate(time.Second)
+	for i := 0; i < 3; i++ {
+		srv := NewServer(WithLocker(locker))
+		_ = srv.AddSchedule("job", interval(time.Minute), func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+		srv.run(context.Background(), srv.entries[0], scheduled)
+	}
+	if n := runs.Load(); n != 1 {
+		t.Errorf("expected a run of the replicas, got %d", n)
+	}
+}
@@ -0,0 +1,81 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Transporter = (*Transport)(nil)
+
+// Transport is a cron transport of a run of a job.
+type Transport struct {
+	operation   string
+	scheduled   time.Time
+	reqHeader   headerCarrier
+	replyHeader headerCarrier
+}
+
+// Kind returns the transport kind.
+func (tr *Transport) Kind() transport.Kind {
+	return transport.KindCron
+}
+
+// Endpoint returns the transport endpoint, which is empty.
+func (tr *Transport) Endpoint() string {
+	return ""
+}
+
+// Operation returns the transport operation, which is the name of the job.
+func (tr *Transport) Operation() string {
+	return tr.operation
+}
+
+// RequestHeader returns the request header.
+func (tr *Transport) RequestHeader() transport.Header {
+	return tr.reqHeader
+}
+
+// ReplyHeader returns the reply header.
+func (tr *Transport) ReplyHeader() transport.Header {
+	return tr.replyHeader
+}
+
+// Scheduled returns the time the run is scheduled at.
+func (tr *Transport) Scheduled() time.Time {
+	return tr.scheduled
+}
+
+type headerCarrier map[string]string
+
+// Get returns the value associated with the passed key.
+func (hc headerCarrier) Get(key string) string {
+	return hc[key]
+}
+
+// Set stores the key-value pair.
+func (hc headerCarrier) Set(key string, value string) {
+	hc[key] = value
+}
+
+// Add sets the value of key, as the headers are single valued.
+func (hc headerCarrier) Add(key string, value string) {
+	hc[key] = value
+}
+
+// Keys lists the keys stored in this carrier.
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range hc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns a slice of values associated with the passed key.
+func (hc headerCarrier) Values(key string) []string {
+	if v, ok := hc[key]; ok {
+		return []string{v}
+	}
+	return nil
+}
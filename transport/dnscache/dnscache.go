@@ -0,0 +1,239 @@
+package dnscache
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Resolver looks up the addresses of a host, e.g. net.DefaultResolver.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// TTLResolver is a Resolver which returns the TTL of the records,
+// the cache respects it instead of the configured TTL.
+type TTLResolver interface {
+	Resolver
+	LookupHostTTL(ctx context.Context, host string) ([]string, time.Duration, error)
+}
+
+// DialFunc dials the address of the network.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Option is DNS cache option.
+type Option func(*options)
+
+type options struct {
+	resolver    Resolver
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+// WithResolver with the resolver of the cache misses, default is net.DefaultResolver.
+func WithResolver(r Resolver) Option {
+	return func(o *options) {
+		o.resolver = r
+	}
+}
+
+// WithTTL with the TTL of the resolved addresses, default is 30s.
+// It is the max TTL if the resolver is a TTLResolver.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithNegativeTTL with the TTL of the failed lookups, default is 0
+// which means the failures are not cached.
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.negativeTTL = ttl
+	}
+}
+
+// Stats is the lookup statistics of a cache.
+type Stats struct {
+	// Lookups is the number of the host lookups, Hits of them are served by the cache.
+	Lookups uint64
+	Hits    uint64
+	// Misses is the number of the lookups sent to the resolver, Errors of them failed.
+	Misses uint64
+	Errors uint64
+	// NegativeHits is the number of the cached failures served.
+	NegativeHits uint64
+	// LookupTime is the total time spent by the resolver.
+	LookupTime time.Duration
+}
+
+type entry struct {
+	addrs   []string
+	err     error
+	expires time.Time
+	next    uint32
+}
+
+// Cache is an in-process DNS cache for the client dialers.
+type Cache struct {
+	// the 64-bit counters are first to be aligned for the atomic operations.
+	lookups      uint64
+	hits         uint64
+	misses       uint64
+	errors       uint64
+	negativeHits uint64
+	lookupTime   int64
+
+	opts  options
+	group singleflight.Group
+	now   func() time.Time
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+// New creates a DNS cache.
+func New(opts ...Option) *Cache {
+	o := options{
+		resolver: net.DefaultResolver,
+		ttl:      30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Cache{
+		opts:    o,
+		now:     time.Now,
+		entries: make(map[string]*entry),
+	}
+}
+
+// LookupHost returns the addresses of the host, from the cache if not expired.
+// The concurrent misses of a host share one lookup, the IP addresses are returned as is.
+func (c *Cache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	atomic.AddUint64(&c.lookups, 1)
+	if e, ok := c.get(host); ok {
+		if e.err != nil {
+			atomic.AddUint64(&c.negativeHits, 1)
+			return nil, e.err
+		}
+		atomic.AddUint64(&c.hits, 1)
+		return e.addrs, nil
+	}
+	v, err, _ := c.group.Do(host, func() (interface{}, error) {
+		return c.lookup(ctx, host)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]string), nil
+}
+
+func (c *Cache) get(host string) (*entry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[host]
+	if !ok || !c.now().Before(e.expires) {
+		return nil, false
+	}
+	return e, true
+}
+
+func (c *Cache) lookup(ctx context.Context, host string) ([]string, error) {
+	atomic.AddUint64(&c.misses, 1)
+	var (
+		addrs []string
+		err   error
+		ttl   = c.opts.ttl
+	)
+	start := time.Now()
+	if r, ok := c.opts.resolver.(TTLResolver); ok {
+		var recordTTL time.Duration
+		if addrs, recordTTL, err = r.LookupHostTTL(ctx, host); err == nil && recordTTL < ttl {
+			ttl = recordTTL
+		}
+	} else {
+		addrs, err = c.opts.resolver.LookupHost(ctx, host)
+	}
+	atomic.AddInt64(&c.lookupTime, int64(time.Since(start)))
+	e := &entry{addrs: addrs, err: err}
+	if err != nil {
+		atomic.AddUint64(&c.errors, 1)
+		ttl = c.opts.negativeTTL
+		// the canceled lookups are not the failures of the host.
+		if ctx.Err() != nil {
+			ttl = 0
+		}
+	}
+	c.mu.Lock()
+	if ttl > 0 {
+		e.expires = c.now().Add(ttl)
+		c.entries[host] = e
+	} else {
+		delete(c.entries, host)
+	}
+	c.mu.Unlock()
+	return addrs, err
+}
+
+// Dial returns the dial func which dials the cached addresses of the host in turn,
+// starting from a different address every time to spread the connections.
+// The addresses without a port are dialed as is.
+func (c *Cache) Dial(dial DialFunc) DialFunc {
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			// e.g. the unix socket addresses.
+			return dial(ctx, network, addr)
+		}
+		addrs, err := c.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if len(addrs) == 0 {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		start := c.start(host, len(addrs))
+		for i := range addrs {
+			var conn net.Conn
+			if conn, err = dial(ctx, network, net.JoinHostPort(addrs[(start+i)%len(addrs)], port)); err == nil {
+				return conn, nil
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, err
+	}
+}
+
+func (c *Cache) start(host string, n int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if e, ok := c.entries[host]; ok {
+		return int(atomic.AddUint32(&e.next, 1)-1) % n
+	}
+	return 0
+}
+
+// Stats returns the lookup statistics of the cache.
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Lookups:      atomic.LoadUint64(&c.lookups),
+		Hits:         atomic.LoadUint64(&c.hits),
+		Misses:       atomic.LoadUint64(&c.misses),
+		Errors:       atomic.LoadUint64(&c.errors),
+		NegativeHits: atomic.LoadUint64(&c.negativeHits),
+		LookupTime:   time.Duration(atomic.LoadInt64(&c.lookupTime)),
+	}
+}
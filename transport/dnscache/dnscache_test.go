@@ -0,0 +1,127 @@
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeResolver struct {
+	mu    sync.Mutex
+	calls int
+	addrs map[string][]string
+	ttl   time.Duration
+}
+
+func (r *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if addrs, ok := r.addrs[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+type fakeTTLResolver struct{ *fakeResolver }
+
+func (r fakeTTLResolver) LookupHostTTL(ctx context.Context, host string) ([]string, time.Duration, error) {
+	addrs, err := r.LookupHost(ctx, host)
+	return addrs, r.ttl, err
+}
+
+func TestCache(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := &fakeResolver{addrs: map[string][]string{"kratos.local": {"10.0.0.1", "10.0.0.2"}}}
+	c := New(WithResolver(r), WithTTL(time.Minute), WithNegativeTTL(time.Second))
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		addrs, err := c.LookupHost(ctx, "kratos.local")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(addrs, []string{"10.0.0.1", "10.0.0.2"}) {
+			t.Errorf("unexpected addrs %v", addrs)
+		}
+	}
+	if r.calls != 1 {
+		t.Errorf("expected 1 lookup got %d", r.calls)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.LookupHost(ctx, "missing.local"); err == nil {
+			t.Error("expected lookup error")
+		}
+	}
+	if r.calls != 2 {
+		t.Errorf("expected the failure to be cached, got %d lookups", r.calls)
+	}
+	if addrs, _ := c.LookupHost(ctx, "127.0.0.1"); !reflect.DeepEqual(addrs, []string{"127.0.0.1"}) {
+		t.Errorf("unexpected addrs %v", addrs)
+	}
+
+	now = now.Add(2 * time.Second)
+	_, _ = c.LookupHost(ctx, "missing.local")
+	_, _ = c.LookupHost(ctx, "kratos.local")
+	if r.calls != 3 {
+		t.Errorf("expected the negative entry to expire, got %d lookups", r.calls)
+	}
+	now = now.Add(time.Minute)
+	_, _ = c.LookupHost(ctx, "kratos.local")
+	if r.calls != 4 {
+		t.Errorf("expected the entry to expire, got %d lookups", r.calls)
+	}
+
+	stats := c.Stats()
+	if stats.Lookups != 8 || stats.Hits != 3 || stats.Misses != 4 || stats.Errors != 2 || stats.NegativeHits != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestCacheTTLResolver(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := &fakeResolver{addrs: map[string][]string{"kratos.local": {"10.0.0.1"}}, ttl: time.Second}
+	c := New(WithResolver(fakeTTLResolver{r}), WithTTL(time.Minute))
+	c.now = func() time.Time { return now }
+	_, _ = c.LookupHost(context.Background(), "kratos.local")
+	now = now.Add(2 * time.Second)
+	_, _ = c.LookupHost(context.Background(), "kratos.local")
+	if r.calls != 2 {
+		t.Errorf("expected the record TTL to be respected, got %d lookups", r.calls)
+	}
+}
+
+func TestCacheDial(t *testing.T) {
+	r := &fakeResolver{addrs: map[string][]string{"kratos.local": {"10.0.0.1", "10.0.0.2"}}}
+	c := New(WithResolver(r))
+	var dialed []string
+	dial := c.Dial(func(_ context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		if addr == "10.0.0.1:80" {
+			return nil, errors.New("connection refused")
+		}
+		conn, _ := net.Pipe()
+		return conn, nil
+	})
+	for i := 0; i < 2; i++ {
+		conn, err := dial(context.Background(), "tcp", "kratos.local:80")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.Close()
+	}
+	if want := []string{"10.0.0.1:80", "10.0.0.2:80", "10.0.0.2:80"}; !reflect.DeepEqual(dialed, want) {
+		t.Errorf("expected %v got %v", want, dialed)
+	}
+	if _, err := dial(context.Background(), "tcp", "missing.local:80"); err == nil {
+		t.Error("expected dial error")
+	}
+	if _, err := dial(context.Background(), "unix", "/tmp/kratos.sock"); err != nil || dialed[len(dialed)-1] != "/tmp/kratos.sock" {
+		t.Errorf("expected the address dialed as is, got %v", err)
+	}
+}
@@ -0,0 +1,281 @@
+package gracefulupgrade
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// envListeners is the keys of the inherited listeners, the file
+	// descriptors start from 3 in the same order, and the ready pipe
+	// is the last one.
+	envListeners   = "KRATOS_UPGRADE_LISTENERS"
+	listenFdsStart = 3
+)
+
+// ErrUpgrading is returned when an upgrade is in progress.
+var ErrUpgrading = errors.New("graceful upgrade is in progress")
+
+// Option is graceful upgrader option.
+type Option func(*options)
+
+type options struct {
+	command string
+	args    []string
+	timeout time.Duration
+}
+
+// Command with the command of the new binary, default is the current executable and arguments.
+func Command(name string, args ...string) Option {
+	return func(o *options) {
+		o.command = name
+		o.args = args
+	}
+}
+
+// Timeout with the timeout waiting for the new process to be ready.
+func Timeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+type file interface {
+	File() (*os.File, error)
+}
+
+// Upgrader hands over the listeners to a new process of the binary,
+// the old process drains its connections and exits after the new one is ready.
+//
+//	u := gracefulupgrade.New()
+//	lis, _ := u.Listen("tcp", ":8000")
+//	srv := http.NewServer(http.Listener(lis))
+//	app := kratos.New(
+//		kratos.Server(srv),
+//		kratos.AfterStart(func(context.Context) error { return u.Ready() }),
+//		kratos.Reload(func(context.Context) error { return u.Upgrade() }),
+//	)
+//	go func() { <-u.Exit(); app.Stop() }()
+type Upgrader struct {
+	opts options
+
+	mu        sync.Mutex
+	inherited map[string]*os.File
+	active    map[string]file
+	ready     *os.File
+	upgrading bool
+	exit      chan struct{}
+	exitOnce  sync.Once
+}
+
+// New creates a graceful upgrader, the listeners passed by the parent process are inherited.
+func New(opts ...Option) *Upgrader {
+	o := options{
+		timeout: time.Minute,
+	}
+	if exe, err := os.Executable(); err == nil {
+		o.command, o.args = exe, os.Args[1:]
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	u := &Upgrader{
+		opts:      o,
+		inherited: make(map[string]*os.File),
+		active:    make(map[string]file),
+		exit:      make(chan struct{}),
+	}
+	if v, ok := os.LookupEnv(envListeners); ok {
+		_ = os.Unsetenv(envListeners)
+		var keys []string
+		if v != "" {
+			keys = strings.Split(v, ",")
+		}
+		for i, key := range keys {
+			u.inherited[key] = os.NewFile(uintptr(listenFdsStart+i), key)
+		}
+		u.ready = os.NewFile(uintptr(listenFdsStart+len(keys)), "ready")
+	}
+	return u
+}
+
+// HasParent reports whether the process is started by an upgrade.
+func (u *Upgrader) HasParent() bool {
+	return u.ready != nil
+}
+
+// Listen returns the stream listener inherited from the parent process,
+// or announces on the local network address if not inherited.
+func (u *Upgrader) Listen(network, address string) (net.Listener, error) {
+	key := network + "://" + address
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	var (
+		lis net.Listener
+		err error
+	)
+	if f, ok := u.inherited[key]; ok {
+		delete(u.inherited, key)
+		lis, err = net.FileListener(f)
+		_ = f.Close()
+	} else {
+		lis, err = net.Listen(network, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	fl, ok := lis.(file)
+	if !ok {
+		_ = lis.Close()
+		return nil, fmt.Errorf("unsupported listener: %s", key)
+	}
+	u.active[key] = fl
+	return lis, nil
+}
+
+// ListenPacket returns the packet conn inherited from the parent process,
+// or announces on the local network address if not inherited.
+func (u *Upgrader) ListenPacket(network, address string) (net.PacketConn, error) {
+	key := network + "://" + address
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	var (
+		conn net.PacketConn
+		err  error
+	)
+	if f, ok := u.inherited[key]; ok {
+		delete(u.inherited, key)
+		conn, err = net.FilePacketConn(f)
+		_ = f.Close()
+	} else {
+		conn, err = net.ListenPacket(network, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	fc, ok := conn.(file)
+	if !ok {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unsupported packet conn: %s", key)
+	}
+	u.active[key] = fc
+	return conn, nil
+}
+
+// Ready notifies the parent process that the servers are started,
+// so that the parent process could drain and exit.
+func (u *Upgrader) Ready() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, f := range u.inherited {
+		// the listeners not used by the new binary.
+		_ = f.Close()
+	}
+	u.inherited = map[string]*os.File{}
+	if u.ready == nil {
+		return nil
+	}
+	_, err := u.ready.Write([]byte{1})
+	_ = u.ready.Close()
+	u.ready = nil
+	return err
+}
+
+// Upgrade starts the new process with the listeners, and waits for it to be ready.
+// The Exit channel is closed once the new process is ready.
+func (u *Upgrader) Upgrade() error {
+	u.mu.Lock()
+	if u.upgrading {
+		u.mu.Unlock()
+		return ErrUpgrading
+	}
+	u.upgrading = true
+	keys := make([]string, 0, len(u.active))
+	files := make([]*os.File, 0, len(u.active)+1)
+	for key, fl := range u.active {
+		f, err := fl.File()
+		if err != nil {
+			u.upgrading = false
+			u.mu.Unlock()
+			closeFiles(files)
+			return err
+		}
+		keys = append(keys, key)
+		files = append(files, f)
+	}
+	u.mu.Unlock()
+	defer closeFiles(files)
+
+	err := u.start(keys, files)
+	u.mu.Lock()
+	u.upgrading = false
+	u.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	u.exitOnce.Do(func() { close(u.exit) })
+	return nil
+}
+
+func (u *Upgrader) start(keys []string, files []*os.File) error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	cmd := exec.Command(u.opts.command, u.opts.args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), envListeners+"="+strings.Join(keys, ","))
+	cmd.ExtraFiles = append(files, w)
+	err = cmd.Start()
+	_ = w.Close()
+	if err != nil {
+		return err
+	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	ready := make(chan error, 1)
+	go func() {
+		b := make([]byte, 1)
+		if _, err := io.ReadFull(r, b); err != nil {
+			ready <- fmt.Errorf("new process is not ready: %w", err)
+			return
+		}
+		ready <- nil
+	}()
+	timer := time.NewTimer(u.opts.timeout)
+	defer timer.Stop()
+	select {
+	case err = <-ready:
+		if err != nil {
+			_ = cmd.Process.Kill()
+		}
+		return err
+	case err = <-exited:
+		return fmt.Errorf("new process exited: %v", err)
+	case <-timer.C:
+		_ = cmd.Process.Kill()
+		return fmt.Errorf("new process is not ready in %v", u.opts.timeout)
+	}
+}
+
+// Exit returns a channel which is closed once the new process is ready,
+// then the process should stop its servers to drain the connections and exit.
+func (u *Upgrader) Exit() <-chan struct{} {
+	return u.exit
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		_ = f.Close()
+	}
+}
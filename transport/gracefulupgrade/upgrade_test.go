@@ -0,0 +1,84 @@
+package gracefulupgrade
+
+import (
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+const envHelper = "KRATOS_UPGRADE_TEST_HELPER"
+
+func TestHelperProcess(_ *testing.T) {
+	if os.Getenv(envHelper) == "" {
+		return
+	}
+	u := New()
+	if !u.HasParent() {
+		os.Exit(1)
+	}
+	// the listener is inherited by the same network and address.
+	lis, err := u.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		os.Exit(2)
+	}
+	if err = u.Ready(); err != nil {
+		os.Exit(3)
+	}
+	conn, err := lis.Accept()
+	if err != nil {
+		os.Exit(4)
+	}
+	_, _ = conn.Write([]byte("child"))
+	_ = conn.Close()
+	os.Exit(0)
+}
+
+func TestUpgrade(t *testing.T) {
+	u := New(Command(os.Args[0], "-test.run=^TestHelperProcess$"), Timeout(10*time.Second))
+	if u.HasParent() {
+		t.Fatal("expected no parent")
+	}
+	lis, err := u.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	t.Setenv(envHelper, "1")
+
+	if err = u.Upgrade(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-u.Exit():
+	default:
+		t.Fatal("expected exit after upgrade")
+	}
+	// drain the old process, the new process keeps serving.
+	_ = lis.Close()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	b, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "child" {
+		t.Errorf("expected %s got %s", "child", b)
+	}
+}
+
+func TestUpgradeNotReady(t *testing.T) {
+	u := New(Command(os.Args[0], "-test.run=^TestHelperProcess$"), Timeout(10*time.Second))
+	if err := u.Upgrade(); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	select {
+	case <-u.Exit():
+		t.Fatal("expected no exit")
+	default:
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"google.golang.org/grpc/balancer/base"
 	"google.golang.org/grpc/metadata"
 
+	"github.com/go-kratos/kratos/v2/internal/drain"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/go-kratos/kratos/v2/transport"
@@ -24,14 +25,24 @@ func init() {
 		balancerName,
 		&balancerBuilder{
 			builder: selector.GlobalSelector(),
+			drains:  drain.New(drainTTL),
 		},
 		base.Config{HealthCheck: true},
 	)
 	balancer.Register(b)
 }
 
+// RegisterBalancer registers the selector builder as the gRPC balancer of the
+// name, which the clients select by WithBalancerName, e.g. a ringhash builder.
+// It must only be called at the initialization time.
+func RegisterBalancer(name string, builder selector.Builder) {
+	balancer.Register(base.NewBalancerBuilder(name, &balancerBuilder{builder: builder, drains: drain.New(drainTTL)}, base.Config{HealthCheck: true}))
+}
+
 type balancerBuilder struct {
 	builder selector.Builder
+	// drains are shared by the pickers, which are rebuilt on the state changes.
+	drains *drain.Set
 }
 
 // Build creates a grpc Picker.
@@ -50,6 +61,7 @@ func (b *balancerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
 	}
 	p := &balancerPicker{
 		selector: b.builder.Build(),
+		drains:   b.drains,
 	}
 	p.selector.Apply(nodes)
 	return p
@@ -58,18 +70,22 @@ func (b *balancerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
 // balancerPicker is a grpc picker.
 type balancerPicker struct {
 	selector selector.Selector
+	drains   *drain.Set
 }
 
 // Pick pick instances.
 func (p *balancerPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
-	var filters []selector.NodeFilter
+	opts := []selector.SelectOption{selector.WithOperation(info.FullMethodName)}
+	filters := []selector.NodeFilter{p.drains.Filter()}
 	if tr, ok := transport.FromClientContext(info.Ctx); ok {
+		opts = append(opts, selector.WithRequestMD(tr.RequestHeader()))
 		if gtr, ok := tr.(*Transport); ok {
-			filters = gtr.NodeFilters()
+			filters = append(filters, gtr.NodeFilters()...)
 		}
 	}
+	opts = append(opts, selector.WithNodeFilter(filters...))
 
-	n, done, err := p.selector.Select(info.Ctx, selector.WithNodeFilter(filters...))
+	n, done, err := p.selector.Select(info.Ctx, opts...)
 	if err != nil {
 		return balancer.PickResult{}, err
 	}
@@ -77,6 +93,9 @@ func (p *balancerPicker) Pick(info balancer.PickInfo) (balancer.PickResult, erro
 	return balancer.PickResult{
 		SubConn: n.(*grpcNode).subConn,
 		Done: func(di balancer.DoneInfo) {
+			if draining, delay := drainHint(di.Err, di.Trailer); draining {
+				p.drains.Add(n.Address(), delay)
+			}
 			done(info.Ctx, selector.DoneInfo{
 				Err:           di.Err,
 				BytesSent:     di.BytesSent,
@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	grpcmd "google.golang.org/grpc/metadata"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// UnaryInterceptorMiddleware returns a middleware running the grpc-go unary
+// server interceptors at its position of the middleware chain, the first one
+// is the outermost. The interceptors get the operation of the transport as
+// the FullMethod of the server info, whose Server is nil.
+func UnaryInterceptorMiddleware(in ...grpc.UnaryServerInterceptor) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		h := grpc.UnaryHandler(handler)
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			info := &grpc.UnaryServerInfo{}
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				info.FullMethod = tr.Operation()
+			}
+			return chainUnary(in, info, h)(ctx, req)
+		}
+	}
+}
+
+func chainUnary(in []grpc.UnaryServerInterceptor, info *grpc.UnaryServerInfo, h grpc.UnaryHandler) grpc.UnaryHandler {
+	for i := len(in) - 1; i >= 0; i-- {
+		next, interceptor := h, in[i]
+		h = func(ctx context.Context, req interface{}) (interface{}, error) {
+			return interceptor(ctx, req, info, next)
+		}
+	}
+	return h
+}
+
+// MiddlewareUnaryInterceptor returns a grpc-go unary server interceptor running the
+// kratos middleware, e.g. on a raw grpc-go server. The server transport is created
+// from the incoming metadata unless the kratos server has created it.
+func MiddlewareUnaryInterceptor(m ...middleware.Middleware) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		h := middleware.Chain(m...)(middleware.Handler(handler))
+		if _, ok := transport.FromServerContext(ctx); ok {
+			return h(ctx, req)
+		}
+		md, _ := grpcmd.FromIncomingContext(ctx)
+		replyHeader := grpcmd.MD{}
+		ctx = transport.NewServerContext(ctx, &Transport{
+			operation:   info.FullMethod,
+			reqHeader:   headerCarrier(md),
+			replyHeader: headerCarrier(replyHeader),
+		})
+		reply, err := h(ctx, req)
+		if len(replyHeader) > 0 {
+			_ = grpc.SetHeader(ctx, replyHeader)
+		}
+		return reply, err
+	}
+}
@@ -0,0 +1,92 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	grpcmd "google.golang.org/grpc/metadata"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestUnaryInterceptorMiddleware(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name+":"+info.FullMethod)
+			return handler(ctx, req)
+		}
+	}
+	mw := func(name string) middleware.Middleware {
+		return func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				calls = append(calls, name)
+				return handler(ctx, req)
+			}
+		}
+	}
+	h := middleware.Chain(mw("m1"), UnaryInterceptorMiddleware(interceptor("i1"), interceptor("i2")), mw("m2"))(
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls = append(calls, "handler")
+			return req, nil
+		})
+	ctx := transport.NewServerContext(context.Background(), &Transport{operation: "/helloworld.Greeter/SayHello"})
+	reply, err := h(ctx, "hello")
+	if err != nil || reply != "hello" {
+		t.Fatalf("expect hello, got %v %v", reply, err)
+	}
+	expect := []string{"m1", "i1:/helloworld.Greeter/SayHello", "i2:/helloworld.Greeter/SayHello", "m2", "handler"}
+	if !reflect.DeepEqual(calls, expect) {
+		t.Errorf("expect %v, got %v", expect, calls)
+	}
+}
+
+func TestMiddlewareUnaryInterceptor(t *testing.T) {
+	var operation, header string
+	interceptor := MiddlewareUnaryInterceptor(func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				operation = tr.Operation()
+				header = tr.RequestHeader().Get("x-md-global-user")
+			}
+			return handler(ctx, req)
+		}
+	})
+	ctx := grpcmd.NewIncomingContext(context.Background(), grpcmd.Pairs("x-md-global-user", "kratos"))
+	reply, err := interceptor(ctx, "hello", &grpc.UnaryServerInfo{FullMethod: "/helloworld.Greeter/SayHello"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		})
+	if err != nil || reply != "hello" {
+		t.Fatalf("expect hello, got %v %v", reply, err)
+	}
+	if operation != "/helloworld.Greeter/SayHello" || header != "kratos" {
+		t.Errorf("expect the server transport, got %q %q", operation, header)
+	}
+}
+
+func TestOuterInterceptor(t *testing.T) {
+	var kratos bool
+	unary := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		_, kratos = transport.FromServerContext(ctx)
+		return handler(ctx, req)
+	}
+	stream := func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, ss)
+	}
+	s := NewServer(OuterUnaryInterceptor(unary), OuterStreamInterceptor(stream))
+	if len(s.outerUnary) != 1 || len(s.outerStream) != 1 {
+		t.Fatalf("expect the outer interceptors, got %d %d", len(s.outerUnary), len(s.outerStream))
+	}
+	h := chainUnary([]grpc.UnaryServerInterceptor{unary, s.unaryServerInterceptor()}, &grpc.UnaryServerInfo{FullMethod: "/test"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil })
+	if _, err := h(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if kratos {
+		t.Error("expect the outer interceptor running before the server transport")
+	}
+}
@@ -0,0 +1,132 @@
+package grpc
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// CallOption configures a Call before it starts or extracts information from
+// a Call after it completes. It is also a grpc.CallOption, so it can be passed
+// to the generated gRPC client methods directly.
+type CallOption interface {
+	grpc.CallOption
+
+	// before is called before the call is sent to any server.  If before
+	// returns a non-nil error, the RPC fails with that error.
+	before(*callInfo) error
+
+	// after is called after the call has completed.  after cannot return an
+	// error, so any failures should be reported via output parameters.
+	after(*callInfo, *csAttempt)
+}
+
+type callInfo struct {
+	operation string
+	metadata  metadata.MD
+}
+
+// EmptyCallOption does not alter the Call configuration.
+// It can be embedded in another structure to carry satellite data for use
+// by interceptors.
+type EmptyCallOption struct {
+	grpc.EmptyCallOption
+}
+
+func (EmptyCallOption) before(*callInfo) error      { return nil }
+func (EmptyCallOption) after(*callInfo, *csAttempt) {}
+
+type csAttempt struct {
+	header  metadata.MD
+	trailer metadata.MD
+	peer    peer.Peer
+}
+
+func defaultCallInfo(method string) callInfo {
+	return callInfo{
+		operation: method,
+		metadata:  metadata.MD{},
+	}
+}
+
+func splitCallOptions(opts []grpc.CallOption) ([]grpc.CallOption, []CallOption) {
+	grpcOpts := make([]grpc.CallOption, 0, len(opts))
+	var callOpts []CallOption
+	for _, o := range opts {
+		if co, ok := o.(CallOption); ok {
+			callOpts = append(callOpts, co)
+			continue
+		}
+		grpcOpts = append(grpcOpts, o)
+	}
+	return grpcOpts, callOpts
+}
+
+// Operation is serviceMethod call option
+func Operation(operation string) CallOption {
+	return OperationCallOption{Operation: operation}
+}
+
+// OperationCallOption is set ServiceMethod for client call
+type OperationCallOption struct {
+	EmptyCallOption
+	Operation string
+}
+
+func (o OperationCallOption) before(c *callInfo) error {
+	c.operation = o.Operation
+	return nil
+}
+
+// Metadata returns a CallOption that sets the request metadata pairs,
+// which are visible to the client middleware as the request header.
+func Metadata(kv ...string) CallOption {
+	return MetadataCallOption{MD: metadata.Pairs(kv...)}
+}
+
+// MetadataCallOption is set request metadata for client call
+type MetadataCallOption struct {
+	EmptyCallOption
+	MD metadata.MD
+}
+
+func (o MetadataCallOption) before(c *callInfo) error {
+	for k, v := range o.MD {
+		c.metadata.Append(k, v...)
+	}
+	return nil
+}
+
+// Header returns a CallOption that retrieves the reply header and trailer
+// metadata from server reply.
+func Header(md *metadata.MD) CallOption {
+	return HeaderCallOption{header: md}
+}
+
+// HeaderCallOption is retrieve reply metadata for client call
+type HeaderCallOption struct {
+	EmptyCallOption
+	header *metadata.MD
+}
+
+func (o HeaderCallOption) after(_ *callInfo, cs *csAttempt) {
+	*o.header = metadata.Join(cs.header, cs.trailer)
+}
+
+// Peer returns a CallOption that retrieves the peer address of the server
+// the call has been sent to.
+func Peer(addr *string) CallOption {
+	return PeerCallOption{addr: addr}
+}
+
+// PeerCallOption is retrieve peer address for client call
+type PeerCallOption struct {
+	EmptyCallOption
+	addr *string
+}
+
+func (o PeerCallOption) after(_ *callInfo, cs *csAttempt) {
+	if cs.peer.Addr != nil {
+		*o.addr = cs.peer.Addr.String()
+	}
+}
@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestCallOptions(t *testing.T) {
+	srv := NewServer(Middleware(func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				tr.ReplyHeader().Set("x-echo", tr.RequestHeader().Get("x-md"))
+			}
+			return handler(ctx, req)
+		}
+	}))
+	pb.RegisterGreeterServer(srv, &server{})
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	var (
+		operation string
+		reply     string
+		node      string
+	)
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint(e.Host),
+		WithMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				tr, _ := transport.FromClientContext(ctx)
+				operation = tr.Operation()
+				resp, err := handler(ctx, req)
+				reply = tr.ReplyHeader().Get("x-echo")
+				if p, ok := selector.FromPeerContext(ctx); ok && p.Node != nil {
+					node = p.Node.Address()
+				}
+				return resp, err
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var (
+		header metadata.MD
+		addr   string
+	)
+	_, err = pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"},
+		Operation("/custom/SayHello"), Metadata("x-md", "value"), Header(&header), Peer(&addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if operation != "/custom/SayHello" {
+		t.Errorf("expected operation %s got %s", "/custom/SayHello", operation)
+	}
+	if reply != "value" {
+		t.Errorf("expected reply header %s got %s", "value", reply)
+	}
+	if v := header.Get("x-echo"); len(v) == 0 || v[0] != "value" {
+		t.Errorf("expected header %s got %v", "value", v)
+	}
+	if addr == "" || node != addr {
+		t.Errorf("expected peer %s got node %s", addr, node)
+	}
+}
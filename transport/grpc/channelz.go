@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+
+	"google.golang.org/grpc"
+	channelzgrpc "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	channelzsvc "google.golang.org/grpc/channelz/service"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// channelzRegistrar captures the channelz service, which is called in process.
+type channelzRegistrar struct {
+	server channelzgrpc.ChannelzServer
+}
+
+func (r *channelzRegistrar) RegisterService(_ *grpc.ServiceDesc, impl interface{}) {
+	r.server, _ = impl.(channelzgrpc.ChannelzServer)
+}
+
+// NewChannelzHandler new an HTTP handler of the channelz stats of the gRPC
+// servers and channels of the process in JSON, e.g. mounted by HandleAdmin of
+// the HTTP server, of its last path element:
+//
+//	servers     ?start=<server id>   the servers
+//	channels    ?start=<channel id>  the top channels, e.g. of the clients
+//	server      ?id=<server id>      a server
+//	sockets     ?id=<server id>      the listen sockets of a server
+//	channel     ?id=<channel id>     a channel
+//	subchannel  ?id=<channel id>     a subchannel
+//	socket      ?id=<socket id>      a socket
+func NewChannelzHandler() http.Handler {
+	r := &channelzRegistrar{}
+	channelzsvc.RegisterChannelzServiceToServer(r)
+	cz := r.server
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		var (
+			ctx   = req.Context()
+			query = req.URL.Query()
+			id, _ = strconv.ParseInt(query.Get("id"), 10, 64)
+			start int64
+			res   proto.Message
+			err   error
+		)
+		start, _ = strconv.ParseInt(query.Get("start"), 10, 64)
+		switch path.Base(req.URL.Path) {
+		case "servers":
+			res, err = cz.GetServers(ctx, &channelzgrpc.GetServersRequest{StartServerId: start})
+		case "channels":
+			res, err = cz.GetTopChannels(ctx, &channelzgrpc.GetTopChannelsRequest{StartChannelId: start})
+		case "server":
+			res, err = cz.GetServer(ctx, &channelzgrpc.GetServerRequest{ServerId: id})
+		case "sockets":
+			res, err = cz.GetServerSockets(ctx, &channelzgrpc.GetServerSocketsRequest{ServerId: id, StartSocketId: start})
+		case "channel":
+			res, err = cz.GetChannel(ctx, &channelzgrpc.GetChannelRequest{ChannelId: id})
+		case "subchannel":
+			res, err = cz.GetSubchannel(ctx, &channelzgrpc.GetSubchannelRequest{SubchannelId: id})
+		case "socket":
+			res, err = cz.GetSocket(ctx, &channelzgrpc.GetSocketRequest{SocketId: id})
+		default:
+			http.NotFound(w, req)
+			return
+		}
+		if err != nil {
+			se := errors.FromError(err)
+			http.Error(w, se.Message, int(se.Code))
+			return
+		}
+		data, err := protojson.Marshal(res)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	})
+}
@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDisableReflectionAndChannelz(t *testing.T) {
+	const (
+		reflectionService = "grpc.reflection.v1alpha.ServerReflection"
+		channelzService   = "grpc.channelz.v1.Channelz"
+	)
+	srv := NewServer()
+	defer func() { _ = srv.Stop(context.Background()) }()
+	info := srv.GetServiceInfo()
+	if _, ok := info[reflectionService]; !ok {
+		t.Error("expected the reflection service by default")
+	}
+	if _, ok := info[channelzService]; !ok {
+		t.Error("expected the channelz service by default")
+	}
+
+	srv = NewServer(DisableReflection(), DisableChannelz())
+	defer func() { _ = srv.Stop(context.Background()) }()
+	info = srv.GetServiceInfo()
+	if _, ok := info[reflectionService]; ok {
+		t.Error("expected no reflection service")
+	}
+	if _, ok := info[channelzService]; ok {
+		t.Error("expected no channelz service")
+	}
+}
+
+func TestChannelzHandler(t *testing.T) {
+	srv := NewServer()
+	defer func() { _ = srv.Stop(context.Background()) }()
+	h := NewChannelzHandler()
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, httptest.NewRequest(method, target, nil))
+		return res
+	}
+	res := serve(http.MethodGet, "/debug/channelz/servers")
+	if res.Code != http.StatusOK || !strings.Contains(res.Body.String(), `"server"`) {
+		t.Errorf("unexpected servers %d %s", res.Code, res.Body.String())
+	}
+	if res = serve(http.MethodGet, "/debug/channelz/channel?id=-1"); res.Code != http.StatusNotFound {
+		t.Errorf("expected 404 of the unknown channel got %d", res.Code)
+	}
+	if res = serve(http.MethodGet, "/debug/channelz/other"); res.Code != http.StatusNotFound {
+		t.Errorf("expected 404 got %d", res.Code)
+	}
+	if res = serve(http.MethodPost, "/debug/channelz/servers"); res.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 got %d", res.Code)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
+	"net"
 	"time"
 
 	"google.golang.org/grpc"
@@ -13,11 +15,15 @@ import (
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/circuitbreaker"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/outlier"
 	"github.com/go-kratos/kratos/v2/selector/wrr"
 	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/dnscache"
 	"github.com/go-kratos/kratos/v2/transport/grpc/resolver/discovery"
+	"github.com/go-kratos/kratos/v2/transport/tlscert"
 
 	// init resolver
 	_ "github.com/go-kratos/kratos/v2/transport/grpc/resolver/direct"
@@ -75,6 +81,15 @@ func WithTLSConfig(c *tls.Config) ClientOption {
 	}
 }
 
+// WithUnsafeTLSKeyLogWriter with the writer of the TLS master secrets, so that the
+// sessions can be decrypted by e.g. Wireshark in test environments.
+// It is ignored when TLS is not configured, DO NOT use it in production.
+func WithUnsafeTLSKeyLogWriter(w io.Writer) ClientOption {
+	return func(o *clientOptions) {
+		o.keyLog = w
+	}
+}
+
 // WithUnaryInterceptor returns a DialOption that specifies the interceptor for unary RPCs.
 func WithUnaryInterceptor(in ...grpc.UnaryClientInterceptor) ClientOption {
 	return func(o *clientOptions) {
@@ -96,6 +111,37 @@ func WithOptions(opts ...grpc.DialOption) ClientOption {
 	}
 }
 
+// WithBalancerName with the name of the balancer registered by RegisterBalancer,
+// default is the global selector.
+func WithBalancerName(name string) ClientOption {
+	return func(o *clientOptions) {
+		o.balancerName = name
+	}
+}
+
+// WithCircuitBreaker with the per node circuit breaker, the nodes with open
+// breakers are skipped by the selector and calls fail fast when all are open.
+func WithCircuitBreaker(b *circuitbreaker.NodeBreaker) ClientOption {
+	return func(o *clientOptions) {
+		o.breaker = b
+	}
+}
+
+// WithOutlierDetection with the outlier ejector, the nodes with high error
+// rates or latencies are ejected from the selector for a while.
+func WithOutlierDetection(e *outlier.Ejector) ClientOption {
+	return func(o *clientOptions) {
+		o.ejector = e
+	}
+}
+
+// WithDNSCache with the DNS cache of the client dialer.
+func WithDNSCache(c *dnscache.Cache) ClientOption {
+	return func(o *clientOptions) {
+		o.dnsCache = c
+	}
+}
+
 // WithNodeFilter with select filters
 func WithNodeFilter(filters ...selector.NodeFilter) ClientOption {
 	return func(o *clientOptions) {
@@ -129,9 +175,12 @@ type clientOptions struct {
 	endpoint               string
 	subsetSize             int
 	tlsConf                *tls.Config
+	keyLog                 io.Writer
 	timeout                time.Duration
 	discovery              registry.Discovery
 	middleware             []middleware.Middleware
+	streamMiddleware       []middleware.Middleware
+	messageMiddleware      []middleware.Middleware
 	ints                   []grpc.UnaryClientInterceptor
 	streamInts             []grpc.StreamClientInterceptor
 	grpcOpts               []grpc.DialOption
@@ -139,6 +188,10 @@ type clientOptions struct {
 	filters                []selector.NodeFilter
 	healthCheckConfig      string
 	printDiscoveryDebugLog bool
+	breaker                *circuitbreaker.NodeBreaker
+	ejector                *outlier.Ejector
+	hashKey                HashKeyFunc
+	dnsCache               *dnscache.Cache
 }
 
 // Dial returns a GRPC connection.
@@ -162,11 +215,22 @@ func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.Clien
 	for _, o := range opts {
 		o(&options)
 	}
+	if options.breaker != nil {
+		options.filters = append(options.filters[:len(options.filters):len(options.filters)], options.breaker.Filter())
+		options.middleware = append(options.middleware[:len(options.middleware):len(options.middleware)], options.breaker.Middleware())
+	}
+	if options.ejector != nil {
+		options.filters = append(options.filters[:len(options.filters):len(options.filters)], options.ejector.Filter())
+		options.middleware = append(options.middleware[:len(options.middleware):len(options.middleware)], options.ejector.Middleware())
+	}
 	ints := []grpc.UnaryClientInterceptor{
 		unaryClientInterceptor(options.middleware, options.timeout, options.filters),
 	}
+	if options.hashKey != nil {
+		ints = append(ints, hashKeyInterceptor(options.hashKey))
+	}
 	sints := []grpc.StreamClientInterceptor{
-		streamClientInterceptor(options.filters),
+		streamClientInterceptor(options.streamMiddleware, options.messageMiddleware, options.filters),
 	}
 
 	if len(options.ints) > 0 {
@@ -192,9 +256,20 @@ func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.Clien
 					discovery.PrintDebugLog(options.printDiscoveryDebugLog),
 				)))
 	}
+	if options.dnsCache != nil {
+		dial := options.dnsCache.Dial(nil)
+		grpcOpts = append(grpcOpts, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			// the unix socket addresses have no port.
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return dial(ctx, "unix", addr)
+			}
+			return dial(ctx, "tcp", addr)
+		}))
+	}
 	if insecure {
 		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(grpcinsecure.NewCredentials()))
 	}
+	options.tlsConf = tlscert.KeyLog(options.tlsConf, options.keyLog)
 	if options.tlsConf != nil {
 		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(credentials.NewTLS(options.tlsConf)))
 	}
@@ -206,10 +281,18 @@ func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.Clien
 
 func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration, filters []selector.NodeFilter) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		opts, callOpts := splitCallOptions(opts)
+		c := defaultCallInfo(method)
+		for _, o := range callOpts {
+			if err := o.before(&c); err != nil {
+				return err
+			}
+		}
 		ctx = transport.NewClientContext(ctx, &Transport{
 			endpoint:    cc.Target(),
-			operation:   method,
-			reqHeader:   headerCarrier{},
+			operation:   c.operation,
+			reqHeader:   headerCarrier(c.metadata),
+			replyHeader: headerCarrier{},
 			nodeFilters: filters,
 		})
 		if timeout > 0 {
@@ -218,16 +301,27 @@ func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration, f
 			defer cancel()
 		}
 		h := func(ctx context.Context, req interface{}) (interface{}, error) {
-			if tr, ok := transport.FromClientContext(ctx); ok {
-				header := tr.RequestHeader()
-				keys := header.Keys()
-				keyvals := make([]string, 0, len(keys))
-				for _, k := range keys {
-					keyvals = append(keyvals, k, header.Get(k))
+			tr, _ := transport.FromClientContext(ctx)
+			if tr != nil {
+				ctx = appendOutgoingHeader(ctx, tr.RequestHeader())
+			}
+			var cs csAttempt
+			err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&cs.header), grpc.Trailer(&cs.trailer), grpc.Peer(&cs.peer))...)
+			if tr != nil {
+				header := tr.ReplyHeader()
+				for k, v := range grpcmd.Join(cs.header, cs.trailer) {
+					for _, vv := range v {
+						header.Add(k, vv)
+					}
 				}
-				ctx = grpcmd.AppendToOutgoingContext(ctx, keyvals...)
 			}
-			return reply, invoker(ctx, method, req, reply, cc, opts...)
+			if p, ok := selector.FromPeerContext(ctx); ok && p.Node == nil && cs.peer.Addr != nil {
+				p.Node = selector.NewNode("grpc", cs.peer.Addr.String(), nil)
+			}
+			for _, o := range callOpts {
+				o.after(&c, &cs)
+			}
+			return reply, err
 		}
 		if len(ms) > 0 {
 			h = middleware.Chain(ms...)(h)
@@ -239,16 +333,63 @@ func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration, f
 	}
 }
 
-func streamClientInterceptor(filters []selector.NodeFilter) grpc.StreamClientInterceptor {
+func streamClientInterceptor(ms, messages []middleware.Middleware, filters []selector.NodeFilter) grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) { // nolint
-		ctx = transport.NewClientContext(ctx, &Transport{
+		opts, callOpts := splitCallOptions(opts)
+		c := defaultCallInfo(method)
+		for _, o := range callOpts {
+			if err := o.before(&c); err != nil {
+				return nil, err
+			}
+		}
+		tr := &Transport{
 			endpoint:    cc.Target(),
-			operation:   method,
-			reqHeader:   headerCarrier{},
+			operation:   c.operation,
+			reqHeader:   headerCarrier(c.metadata),
+			replyHeader: headerCarrier{},
 			nodeFilters: filters,
-		})
+		}
+		ctx = transport.NewClientContext(ctx, tr)
 		var p selector.Peer
 		ctx = selector.NewPeerContext(ctx, &p)
-		return streamer(ctx, desc, cc, method, opts...)
+		if len(ms) == 0 && len(messages) == 0 {
+			return streamer(appendOutgoingHeader(ctx, tr.reqHeader), desc, cc, method, opts...)
+		}
+		h := func(ctx context.Context, _ interface{}) (interface{}, error) {
+			cs, err := streamer(appendOutgoingHeader(ctx, tr.reqHeader), desc, cc, method, opts...)
+			if err != nil {
+				return nil, err
+			}
+			if len(messages) > 0 {
+				cs = &clientStream{ClientStream: cs, ctx: ctx, message: middleware.Chain(messages...)}
+			}
+			return cs, nil
+		}
+		if len(ms) > 0 {
+			h = middleware.Chain(ms...)(h)
+		}
+		reply, err := h(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		cs, ok := reply.(grpc.ClientStream)
+		if !ok {
+			return nil, fmt.Errorf("grpc: invalid stream %T of the stream middleware", reply)
+		}
+		return cs, nil
+	}
+}
+
+func appendOutgoingHeader(ctx context.Context, header transport.Header) context.Context {
+	keys := header.Keys()
+	if len(keys) == 0 {
+		return ctx
+	}
+	keyvals := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range header.Values(k) {
+			keyvals = append(keyvals, k, v)
+		}
 	}
+	return grpcmd.AppendToOutgoingContext(ctx, keyvals...)
 }
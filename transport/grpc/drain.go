@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DrainTrailer is the trailer sent by the server in the drain phase,
+// the clients avoid the node and pick another one for the next calls.
+const DrainTrailer = "x-drain"
+
+// drainTTL is how long a node is avoided once it sends a drain hint without a retry delay.
+const drainTTL = 10 * time.Second
+
+// RetryDelay returns the delay of the RetryInfo details of the gRPC status
+// error, e.g. sent by an overloaded server, before the call is retried.
+func RetryDelay(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st == nil {
+		return 0, false
+	}
+	for _, detail := range st.Details() {
+		if ri, ok := detail.(*errdetails.RetryInfo); ok && ri.GetRetryDelay() != nil {
+			return ri.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// drainHint returns the drain hint of a call, the node is draining if the
+// trailer has the drain header, or the status error has the RetryInfo details.
+func drainHint(err error, trailer metadata.MD) (bool, time.Duration) {
+	if err != nil {
+		if delay, ok := RetryDelay(err); ok {
+			return true, delay
+		}
+	}
+	return len(trailer.Get(DrainTrailer)) > 0, 0
+}
@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestDrainHint(t *testing.T) {
+	st, err := status.New(codes.Unavailable, "overloaded").WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(3 * time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := RetryDelay(st.Err()); !ok || d != 3*time.Second {
+		t.Errorf("expect the retry delay 3s, got %v", d)
+	}
+	if draining, delay := drainHint(st.Err(), nil); !draining || delay != 3*time.Second {
+		t.Errorf("expect the RetryInfo hint, got %v %v", draining, delay)
+	}
+	if draining, _ := drainHint(nil, metadata.Pairs(DrainTrailer, "true")); !draining {
+		t.Errorf("expect the drain trailer hint")
+	}
+	if draining, _ := drainHint(status.Error(codes.Internal, "internal"), nil); draining {
+		t.Errorf("expect no hint")
+	}
+}
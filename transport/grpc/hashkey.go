@@ -0,0 +1,66 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	grpcmd "google.golang.org/grpc/metadata"
+
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// HashKeyFunc derives the hash key of a call for the hash based balancers,
+// e.g. the ringhash balancer, an empty key is not set.
+type HashKeyFunc func(ctx context.Context) string
+
+// WithHashKey with the hash key of the calls, unless the caller sets it
+// by selector.NewHashKeyContext.
+func WithHashKey(f HashKeyFunc) ClientOption {
+	return func(o *clientOptions) {
+		o.hashKey = f
+	}
+}
+
+// HashKeyHeader returns the hash key from the request header of the call.
+func HashKeyHeader(name string) HashKeyFunc {
+	return func(ctx context.Context) string {
+		if tr, ok := transport.FromClientContext(ctx); ok {
+			if v := tr.RequestHeader().Get(name); v != "" {
+				return v
+			}
+		}
+		if md, ok := grpcmd.FromOutgoingContext(ctx); ok {
+			if v := md.Get(name); len(v) > 0 {
+				return v[0]
+			}
+		}
+		return ""
+	}
+}
+
+// HashKeyContext returns the hash key from the context value, a string or a fmt.Stringer.
+func HashKeyContext(key interface{}) HashKeyFunc {
+	return func(ctx context.Context) string {
+		switch v := ctx.Value(key).(type) {
+		case string:
+			return v
+		case fmt.Stringer:
+			return v.String()
+		}
+		return ""
+	}
+}
+
+// hashKeyInterceptor sets the hash key of the calls for the balancer.
+func hashKeyInterceptor(f HashKeyFunc) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := selector.FromHashKeyContext(ctx); !ok {
+			if key := f(ctx); key != "" {
+				ctx = selector.NewHashKeyContext(ctx, key)
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
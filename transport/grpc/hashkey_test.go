@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	grpcmd "google.golang.org/grpc/metadata"
+
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type ctxKey struct{}
+
+func TestHashKeyInterceptor(t *testing.T) {
+	var got string
+	invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		got, _ = selector.FromHashKeyContext(ctx)
+		return nil
+	}
+	ctx := transport.NewClientContext(context.Background(), &Transport{reqHeader: headerCarrier(grpcmd.Pairs("x-user", "header-user"))})
+	if err := hashKeyInterceptor(HashKeyHeader("x-user"))(ctx, "/test", nil, nil, nil, invoker); err != nil || got != "header-user" {
+		t.Errorf("expect header-user, got %q %v", got, err)
+	}
+	ctx = grpcmd.NewOutgoingContext(context.Background(), grpcmd.Pairs("x-user", "md-user"))
+	_ = hashKeyInterceptor(HashKeyHeader("x-user"))(ctx, "/test", nil, nil, nil, invoker)
+	if got != "md-user" {
+		t.Errorf("expect md-user, got %q", got)
+	}
+	ctx = context.WithValue(context.Background(), ctxKey{}, "ctx-user")
+	_ = hashKeyInterceptor(HashKeyContext(ctxKey{}))(ctx, "/test", nil, nil, nil, invoker)
+	if got != "ctx-user" {
+		t.Errorf("expect ctx-user, got %q", got)
+	}
+	ctx = selector.NewHashKeyContext(ctx, "caller")
+	_ = hashKeyInterceptor(HashKeyContext(ctxKey{}))(ctx, "/test", nil, nil, nil, invoker)
+	if got != "caller" {
+		t.Errorf("expect caller, got %q", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	ic "github.com/go-kratos/kratos/v2/internal/context"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/matcher"
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
@@ -27,20 +28,26 @@ func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 			tr.endpoint = s.endpoint.String()
 		}
 		ctx = transport.NewServerContext(ctx, tr)
-		if s.timeout > 0 {
-			ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		if timeout := s.requestTimeout(); timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
 			defer cancel()
 		}
 		h := func(ctx context.Context, req interface{}) (interface{}, error) {
 			return handler(ctx, req)
 		}
-		if next := s.middleware.Match(tr.Operation()); len(next) > 0 {
+		if next := matcher.MatchContext(ctx, s.middleware, tr.Operation()); len(next) > 0 {
+			if s.observer != nil {
+				next = middleware.Instrument(s.observer, next...)
+			}
 			h = middleware.Chain(next...)(h)
 		}
 		reply, err := h(ctx, req)
 		if len(replyHeader) > 0 {
 			_ = grpc.SetHeader(ctx, replyHeader)
 		}
+		if s.draining.Load() {
+			_ = grpc.SetTrailer(ctx, grpcmd.Pairs(DrainTrailer, "true"))
+		}
 		return reply, err
 	}
 }
@@ -48,7 +55,8 @@ func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 // wrappedStream is rewrite grpc stream's context
 type wrappedStream struct {
 	grpc.ServerStream
-	ctx context.Context
+	ctx     context.Context
+	message middleware.Middleware
 }
 
 func NewWrappedStream(ctx context.Context, stream grpc.ServerStream) grpc.ServerStream {
@@ -62,6 +70,20 @@ func (w *wrappedStream) Context() context.Context {
 	return w.ctx
 }
 
+func (w *wrappedStream) RecvMsg(m interface{}) error {
+	if w.message == nil {
+		return w.ServerStream.RecvMsg(m)
+	}
+	return recvMsg(w.ctx, w.message, w.ServerStream.RecvMsg, m)
+}
+
+func (w *wrappedStream) SendMsg(m interface{}) error {
+	if w.message == nil {
+		return w.ServerStream.SendMsg(m)
+	}
+	return sendMsg(w.ctx, w.message, w.ServerStream.SendMsg, m)
+}
+
 // streamServerInterceptor is a gRPC stream server interceptor
 func (s *Server) streamServerInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
@@ -69,19 +91,36 @@ func (s *Server) streamServerInterceptor() grpc.StreamServerInterceptor {
 		defer cancel()
 		md, _ := grpcmd.FromIncomingContext(ctx)
 		replyHeader := grpcmd.MD{}
-		ctx = transport.NewServerContext(ctx, &Transport{
-			endpoint:    s.endpoint.String(),
+		tr := &Transport{
 			operation:   info.FullMethod,
 			reqHeader:   headerCarrier(md),
 			replyHeader: headerCarrier(replyHeader),
-		})
-
-		ws := NewWrappedStream(ctx, ss)
+		}
+		if s.endpoint != nil {
+			tr.endpoint = s.endpoint.String()
+		}
+		ctx = transport.NewServerContext(ctx, tr)
 
-		err := handler(srv, ws)
+		var message middleware.Middleware
+		if len(s.messageMiddleware) > 0 {
+			message = middleware.Chain(s.messageMiddleware...)
+		}
+		h := func(ctx context.Context, _ interface{}) (interface{}, error) {
+			return nil, handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx, message: message})
+		}
+		if next := s.streamMiddleware; len(next) > 0 {
+			if s.observer != nil {
+				next = middleware.Instrument(s.observer, next...)
+			}
+			h = middleware.Chain(next...)(h)
+		}
+		_, err := h(ctx, nil)
 		if len(replyHeader) > 0 {
 			_ = grpc.SetHeader(ctx, replyHeader)
 		}
+		if s.draining.Load() {
+			ss.SetTrailer(grpcmd.Pairs(DrainTrailer, "true"))
+		}
 		return err
 	}
 }
@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/url"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpcinsecure "google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/go-kratos/kratos/v2/internal/endpoint"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Prober = (*Server)(nil)
+
+// Probe probes the endpoint by a health check, whose replies of any status
+// mean the endpoint is reachable. The certificates are not verified, as the
+// endpoint is probed for the reachability only.
+func (s *Server) Probe(ctx context.Context, u *url.URL) error {
+	network, addr, scheme := "tcp", u.Host, u.Scheme
+	if u.Scheme == endpoint.UnixScheme {
+		network, scheme = "unix", u.Query().Get("protocol")
+		addr, _ = endpoint.ParseUnixAddress(endpoint.UnixAddress(u))
+	}
+	creds := grpcinsecure.NewCredentials()
+	if scheme == "grpcs" {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: true}) //nolint:gosec
+	}
+	conn, err := grpc.DialContext(ctx, "passthrough:///"+addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if code := status.Code(err); code == codes.Unavailable || code == codes.DeadlineExceeded || code == codes.Canceled {
+		return err
+	}
+	return nil
+}
@@ -3,8 +3,10 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
+	"io"
 	"net"
 	"net/url"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
@@ -15,12 +17,14 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	apimd "github.com/go-kratos/kratos/v2/api/metadata"
+	"github.com/go-kratos/kratos/v2/internal/activation"
 	"github.com/go-kratos/kratos/v2/internal/endpoint"
 	"github.com/go-kratos/kratos/v2/internal/host"
-	"github.com/go-kratos/kratos/v2/internal/matcher"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/matcher"
 	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/tlscert"
 )
 
 var (
@@ -72,6 +76,24 @@ func Middleware(m ...middleware.Middleware) ServerOption {
 	}
 }
 
+// MiddlewareMatcher with the matcher of the middleware of the selectors of Use,
+// e.g. matcher.NewRegex, default is the exact and prefix matcher of matcher.New.
+// It replaces the matcher, so it must precede the Middleware option.
+func MiddlewareMatcher(m matcher.Matcher) ServerOption {
+	return func(s *Server) {
+		s.middleware = m
+	}
+}
+
+// MiddlewareObserver with the observer of each middleware of the requests,
+// e.g. metrics.Observer or tracing.Observer, to record the latency and the
+// errors of each of them, default is none.
+func MiddlewareObserver(o middleware.Observer) ServerOption {
+	return func(s *Server) {
+		s.observer = o
+	}
+}
+
 // CustomHealth Checks server.
 func CustomHealth() ServerOption {
 	return func(s *Server) {
@@ -79,6 +101,24 @@ func CustomHealth() ServerOption {
 	}
 }
 
+// DisableReflection does not register the server reflection service, which is
+// registered by default, so that the tools like grpcurl list and call the
+// services without the proto files.
+func DisableReflection() ServerOption {
+	return func(s *Server) {
+		s.disableReflection = true
+	}
+}
+
+// DisableChannelz does not register the gRPC admin services, which are
+// registered by default, i.e. the channelz service, and the CSDS one if xDS is
+// linked, the stats are also served by the admin endpoint of debug.WithChannelz.
+func DisableChannelz() ServerOption {
+	return func(s *Server) {
+		s.disableChannelz = true
+	}
+}
+
 // TLSConfig with TLS config.
 func TLSConfig(c *tls.Config) ServerOption {
 	return func(s *Server) {
@@ -86,6 +126,15 @@ func TLSConfig(c *tls.Config) ServerOption {
 	}
 }
 
+// TLSProvider with the certificate provider, e.g. tlscert.FileProvider reloading
+// the rotated certificate files, or autocert.Manager for the ACME certificates.
+// It is applied to the TLSConfig, or a default TLS config if not set.
+func TLSProvider(p tlscert.Provider) ServerOption {
+	return func(s *Server) {
+		s.tlsProvider = p
+	}
+}
+
 // Listener with server lis
 func Listener(lis net.Listener) ServerOption {
 	return func(s *Server) {
@@ -93,6 +142,24 @@ func Listener(lis net.Listener) ServerOption {
 	}
 }
 
+// ListenerFromFD with the listener inherited from the file descriptor,
+// e.g. passed by the parent process for the zero-downtime restarts.
+func ListenerFromFD(fd uintptr) ServerOption {
+	return func(s *Server) {
+		lis, err := activation.FileListener(fd, "listener")
+		s.inheritListener(lis, err)
+	}
+}
+
+// SocketActivation with the listener passed by the systemd socket activation,
+// the name is the FileDescriptorName of the socket unit, empty means the first one.
+func SocketActivation(name string) ServerOption {
+	return func(s *Server) {
+		lis, err := activation.Listener(name)
+		s.inheritListener(lis, err)
+	}
+}
+
 // UnaryInterceptor returns a ServerOption that sets the UnaryServerInterceptor for the server.
 func UnaryInterceptor(in ...grpc.UnaryServerInterceptor) ServerOption {
 	return func(s *Server) {
@@ -107,6 +174,23 @@ func StreamInterceptor(in ...grpc.StreamServerInterceptor) ServerOption {
 	}
 }
 
+// OuterUnaryInterceptor returns a ServerOption that sets the UnaryServerInterceptor
+// running before the kratos middleware, without the server transport, while the
+// UnaryInterceptor runs after it.
+func OuterUnaryInterceptor(in ...grpc.UnaryServerInterceptor) ServerOption {
+	return func(s *Server) {
+		s.outerUnary = in
+	}
+}
+
+// OuterStreamInterceptor returns a ServerOption that sets the StreamServerInterceptor
+// running before the kratos stream interceptor.
+func OuterStreamInterceptor(in ...grpc.StreamServerInterceptor) ServerOption {
+	return func(s *Server) {
+		s.outerStream = in
+	}
+}
+
 // Options with grpc options.
 func Options(opts ...grpc.ServerOption) ServerOption {
 	return func(s *Server) {
@@ -117,22 +201,33 @@ func Options(opts ...grpc.ServerOption) ServerOption {
 // Server is a gRPC server wrapper.
 type Server struct {
 	*grpc.Server
-	baseCtx      context.Context
-	tlsConf      *tls.Config
-	lis          net.Listener
-	err          error
-	network      string
-	address      string
-	endpoint     *url.URL
-	timeout      time.Duration
-	middleware   matcher.Matcher
-	unaryInts    []grpc.UnaryServerInterceptor
-	streamInts   []grpc.StreamServerInterceptor
-	grpcOpts     []grpc.ServerOption
-	health       *health.Server
-	customHealth bool
-	metadata     *apimd.Server
-	adminClean   func()
+	baseCtx           context.Context
+	tlsConf           *tls.Config
+	tlsProvider       tlscert.Provider
+	keyLog            io.Writer
+	lis               net.Listener
+	err               error
+	network           string
+	address           string
+	endpoint          *url.URL
+	timeout           time.Duration
+	middleware        matcher.Matcher
+	observer          middleware.Observer
+	streamMiddleware  []middleware.Middleware
+	messageMiddleware []middleware.Middleware
+	unaryInts         []grpc.UnaryServerInterceptor
+	streamInts        []grpc.StreamServerInterceptor
+	outerUnary        []grpc.UnaryServerInterceptor
+	outerStream       []grpc.StreamServerInterceptor
+	grpcOpts          []grpc.ServerOption
+	health            *health.Server
+	customHealth      bool
+	disableReflection bool
+	disableChannelz   bool
+	metadata          *apimd.Server
+	adminClean        func()
+	draining          atomic.Bool
+	liveTimeout       atomic.Pointer[time.Duration]
 }
 
 // NewServer creates a gRPC server by options.
@@ -148,12 +243,8 @@ func NewServer(opts ...ServerOption) *Server {
 	for _, o := range opts {
 		o(srv)
 	}
-	unaryInts := []grpc.UnaryServerInterceptor{
-		srv.unaryServerInterceptor(),
-	}
-	streamInts := []grpc.StreamServerInterceptor{
-		srv.streamServerInterceptor(),
-	}
+	unaryInts := append(srv.outerUnary[:len(srv.outerUnary):len(srv.outerUnary)], srv.unaryServerInterceptor())
+	streamInts := append(srv.outerStream[:len(srv.outerStream):len(srv.outerStream)], srv.streamServerInterceptor())
 	if len(srv.unaryInts) > 0 {
 		unaryInts = append(unaryInts, srv.unaryInts...)
 	}
@@ -164,6 +255,10 @@ func NewServer(opts ...ServerOption) *Server {
 		grpc.ChainUnaryInterceptor(unaryInts...),
 		grpc.ChainStreamInterceptor(streamInts...),
 	}
+	if srv.tlsProvider != nil {
+		srv.tlsConf = tlscert.Config(srv.tlsConf, srv.tlsProvider)
+	}
+	srv.tlsConf = tlscert.KeyLog(srv.tlsConf, srv.keyLog)
 	if srv.tlsConf != nil {
 		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(srv.tlsConf)))
 	}
@@ -177,12 +272,29 @@ func NewServer(opts ...ServerOption) *Server {
 		grpc_health_v1.RegisterHealthServer(srv.Server, srv.health)
 	}
 	apimd.RegisterMetadataServer(srv.Server, srv.metadata)
-	reflection.Register(srv.Server)
-	// admin register
-	srv.adminClean, _ = admin.Register(srv.Server)
+	if !srv.disableReflection {
+		reflection.Register(srv.Server)
+	}
+	if !srv.disableChannelz {
+		srv.adminClean, _ = admin.Register(srv.Server)
+	}
 	return srv
 }
 
+// SetTimeout sets the timeout of the requests at runtime, e.g. bound to a
+// watched config key by config.Bind, 0 means no timeout.
+func (s *Server) SetTimeout(timeout time.Duration) {
+	s.liveTimeout.Store(&timeout)
+}
+
+// requestTimeout returns the timeout of SetTimeout, or the Timeout option.
+func (s *Server) requestTimeout() time.Duration {
+	if timeout := s.liveTimeout.Load(); timeout != nil {
+		return *timeout
+	}
+	return s.timeout
+}
+
 // Use uses a service middleware with selector.
 // selector:
 //   - '/*'
@@ -192,6 +304,12 @@ func (s *Server) Use(selector string, m ...middleware.Middleware) {
 	s.middleware.Add(selector, m...)
 }
 
+// MiddlewareSelectors returns the selectors of the middleware registered by
+// Use matching the operation, "*" is of the Middleware option.
+func (s *Server) MiddlewareSelectors(operation string) []string {
+	return s.middleware.Selectors(operation)
+}
+
 // Endpoint return a real address to registry endpoint.
 // examples:
 //
@@ -214,6 +332,16 @@ func (s *Server) Start(ctx context.Context) error {
 	return s.Serve(s.lis)
 }
 
+// Drain sets the health status to NOT_SERVING so that the health checking
+// clients and load balancers stop sending new requests, and the replies have
+// the DrainTrailer from now on.
+func (s *Server) Drain(_ context.Context) error {
+	log.Info("[gRPC] server draining")
+	s.draining.Store(true)
+	s.health.Shutdown()
+	return nil
+}
+
 // Stop stop the gRPC server.
 func (s *Server) Stop(_ context.Context) error {
 	if s.adminClean != nil {
@@ -225,7 +353,22 @@ func (s *Server) Stop(_ context.Context) error {
 	return nil
 }
 
+// inheritListener uses the inherited listener, the endpoint is
+// resolved from the address which the listener is bound to.
+func (s *Server) inheritListener(lis net.Listener, err error) {
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.lis = lis
+	s.network = lis.Addr().Network()
+	s.address = lis.Addr().String()
+}
+
 func (s *Server) listenAndEndpoint() error {
+	if s.err != nil {
+		return s.err
+	}
 	if s.lis == nil {
 		lis, err := net.Listen(s.network, s.address)
 		if err != nil {
@@ -235,6 +378,10 @@ func (s *Server) listenAndEndpoint() error {
 		s.lis = lis
 	}
 	if s.endpoint == nil {
+		if addr, ok := s.lis.Addr().(*net.UnixAddr); ok {
+			s.endpoint = endpoint.NewUnixEndpoint(endpoint.Scheme("grpc", s.tlsConf != nil), addr.Name)
+			return s.err
+		}
 		addr, err := host.Extract(s.address, s.lis)
 		if err != nil {
 			s.err = err
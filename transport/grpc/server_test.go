@@ -12,11 +12,12 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/go-kratos/kratos/v2/errors"
-	"github.com/go-kratos/kratos/v2/internal/matcher"
 	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/matcher"
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
@@ -294,3 +295,79 @@ func TestListener(t *testing.T) {
 		t.Errorf("expect not empty")
 	}
 }
+
+func TestListenerFromFD(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	f, err := lis.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	s := NewServer(ListenerFromFD(f.Fd()))
+	e, err := s.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Host != lis.Addr().String() {
+		t.Errorf("expected %s got %s", lis.Addr(), e.Host)
+	}
+	_ = s.lis.Close()
+}
+
+func TestDrain(t *testing.T) {
+	srv := NewServer()
+	srv.health.Resume()
+	if err := srv.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	res, err := srv.health.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil || res.Status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected NOT_SERVING got %v %v", res, err)
+	}
+}
+
+func TestServer_Probe(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"))
+	go func() { _ = srv.Start(context.Background()) }()
+	defer func() { _ = srv.Stop(context.Background()) }()
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Probe(ctx, e); err != nil {
+		t.Errorf("expected the endpoint reachable, got %v", err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = lis.Close()
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := srv.Probe(ctx, &url.URL{Scheme: "grpc", Host: lis.Addr().String()}); err == nil {
+		t.Error("expected the closed endpoint unreachable")
+	}
+}
+
+func TestServer_MiddlewareObserver(t *testing.T) {
+	var names []string
+	srv := NewServer(
+		Middleware(EmptyMiddleware()),
+		MiddlewareObserver(func(ctx context.Context, name string) (context.Context, func(time.Duration, error)) {
+			names = append(names, name)
+			return ctx, func(time.Duration, error) {}
+		}),
+	)
+	_, err := srv.unaryServerInterceptor()(context.TODO(), nil, &grpc.UnaryServerInfo{}, func(context.Context, interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	if err != nil || !reflect.DeepEqual(names, []string{"grpc.EmptyMiddleware"}) {
+		t.Errorf("expected the observed middleware, got %v %v", names, err)
+	}
+}
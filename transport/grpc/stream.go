@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// Direction is the direction of a stream message of the message middleware.
+type Direction int
+
+const (
+	// DirectionRecv is of the messages received, the request and the reply are the message.
+	DirectionRecv Direction = iota + 1
+	// DirectionSend is of the messages sent, the request is the message and the reply is nil.
+	DirectionSend
+)
+
+// String returns the name of the direction.
+func (d Direction) String() string {
+	switch d {
+	case DirectionRecv:
+		return "recv"
+	case DirectionSend:
+		return "send"
+	default:
+		return ""
+	}
+}
+
+type directionKey struct{}
+
+// DirectionFromContext returns the direction of the stream message of the
+// message middleware, it reports false out of the message middleware.
+func DirectionFromContext(ctx context.Context) (Direction, bool) {
+	d, ok := ctx.Value(directionKey{}).(Direction)
+	return d, ok
+}
+
+// StreamMiddleware with the server middleware of the streams, which wraps each
+// stream once with the server transport, the request and the reply are nil
+// and the error is of the stream handler, e.g. for auth and the metrics of the
+// stream duration. Note that the health checking Watch of the clients is a
+// stream too. The context returned to the handler is of the stream.
+func StreamMiddleware(m ...middleware.Middleware) ServerOption {
+	return func(s *Server) {
+		s.streamMiddleware = append(s.streamMiddleware, m...)
+	}
+}
+
+// StreamMessageMiddleware with the server middleware of the stream messages,
+// which wraps each message received after it is received, and each message
+// sent, see DirectionFromContext, e.g. for the validation and the logging.
+func StreamMessageMiddleware(m ...middleware.Middleware) ServerOption {
+	return func(s *Server) {
+		s.messageMiddleware = append(s.messageMiddleware, m...)
+	}
+}
+
+// WithStreamMiddleware with the client middleware of the streams, which wraps
+// the stream creation with the client transport, the request is nil and the
+// reply is the grpc.ClientStream.
+func WithStreamMiddleware(m ...middleware.Middleware) ClientOption {
+	return func(o *clientOptions) {
+		o.streamMiddleware = append(o.streamMiddleware, m...)
+	}
+}
+
+// WithStreamMessageMiddleware with the client middleware of the stream
+// messages like StreamMessageMiddleware.
+func WithStreamMessageMiddleware(m ...middleware.Middleware) ClientOption {
+	return func(o *clientOptions) {
+		o.messageMiddleware = append(o.messageMiddleware, m...)
+	}
+}
+
+// recvMsg receives the message, which is then passed to the message middleware.
+func recvMsg(ctx context.Context, mw middleware.Middleware, recv func(interface{}) error, m interface{}) error {
+	if err := recv(m); err != nil {
+		return err
+	}
+	_, err := mw(func(context.Context, interface{}) (interface{}, error) {
+		return m, nil
+	})(context.WithValue(ctx, directionKey{}, DirectionRecv), m)
+	return err
+}
+
+// sendMsg sends the message passed through the message middleware.
+func sendMsg(ctx context.Context, mw middleware.Middleware, send func(interface{}) error, m interface{}) error {
+	_, err := mw(func(_ context.Context, req interface{}) (interface{}, error) {
+		return nil, send(req)
+	})(context.WithValue(ctx, directionKey{}, DirectionSend), m)
+	return err
+}
+
+// clientStream is the client stream of the message middleware.
+type clientStream struct {
+	grpc.ClientStream
+	ctx     context.Context
+	message middleware.Middleware
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return recvMsg(s.ctx, s.message, s.ClientStream.RecvMsg, m)
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	return sendMsg(s.ctx, s.message, s.ClientStream.SendMsg, m)
+}
@@ -0,0 +1,150 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type messageRecorder struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (r *messageRecorder) middleware(handler middleware.Handler) middleware.Handler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		d, _ := DirectionFromContext(ctx)
+		var name string
+		switch m := req.(type) {
+		case *pb.HelloRequest:
+			name = m.Name
+		case *pb.HelloReply:
+			name = m.Message
+		default:
+			// e.g. the health checking of the clients.
+			return handler(ctx, req)
+		}
+		r.mu.Lock()
+		r.msgs = append(r.msgs, d.String()+" "+name)
+		r.mu.Unlock()
+		if name == "invalid" {
+			return nil, errors.BadRequest("INVALID", "invalid name")
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (r *messageRecorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.msgs...)
+}
+
+func TestStreamMiddleware(t *testing.T) {
+	var (
+		serverMsgs, clientMsgs messageRecorder
+		streamErr              error
+		done                   = make(chan struct{}, 1)
+	)
+	srv := NewServer(
+		Address("127.0.0.1:0"),
+		StreamMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				tr, _ := transport.FromServerContext(ctx)
+				if !strings.HasPrefix(tr.Operation(), "/helloworld.") {
+					// e.g. the health checking of the clients.
+					return handler(ctx, req)
+				}
+				if tr.RequestHeader().Get("x-token") != "secret" {
+					return nil, errors.Unauthorized("UNAUTHORIZED", "missing token")
+				}
+				reply, err := handler(ctx, req)
+				streamErr = err
+				done <- struct{}{}
+				return reply, err
+			}
+		}),
+		StreamMessageMiddleware(serverMsgs.middleware),
+	)
+	pb.RegisterGreeterServer(srv, &server{})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Start(context.Background()) }()
+	defer func() { _ = srv.Stop(context.Background()) }()
+	time.Sleep(100 * time.Millisecond)
+
+	dial := func(token string) pb.GreeterClient {
+		conn, err := DialInsecure(context.Background(),
+			WithEndpoint(u.Host),
+			WithOptions(grpc.WithBlock()),
+			WithStreamMiddleware(func(handler middleware.Handler) middleware.Handler {
+				return func(ctx context.Context, req interface{}) (interface{}, error) {
+					if tr, ok := transport.FromClientContext(ctx); ok && token != "" {
+						tr.RequestHeader().Set("x-token", token)
+					}
+					return handler(ctx, req)
+				}
+			}),
+			WithStreamMessageMiddleware(clientMsgs.middleware),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		return pb.NewGreeterClient(conn)
+	}
+
+	stream, err := dial("secret").SayHelloStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if err = stream.Send(&pb.HelloRequest{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = stream.Recv(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+	if streamErr != nil {
+		t.Errorf("unexpected stream error %v", streamErr)
+	}
+	if want := "[recv a send hello a recv b send hello b]"; fmt.Sprint(serverMsgs.list()) != want {
+		t.Errorf("expected the server messages %s got %v", want, serverMsgs.list())
+	}
+	if want := "[send a recv hello a send b recv hello b]"; fmt.Sprint(clientMsgs.list()) != want {
+		t.Errorf("expected the client messages %s got %v", want, clientMsgs.list())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err = dial("secret").SayHelloStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = stream.Send(&pb.HelloRequest{Name: "invalid"}); !errors.IsBadRequest(err) {
+		t.Errorf("expected the error of the client message middleware, got %v", err)
+	}
+	cancel()
+	<-done
+
+	stream, err = dial("").SayHelloStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stream.Recv(); !errors.IsUnauthorized(err) {
+		t.Errorf("expected the error of the server stream middleware, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+type unixDiscovery struct {
+	ins []*registry.ServiceInstance
+}
+
+func (d *unixDiscovery) GetService(_ context.Context, _ string) ([]*registry.ServiceInstance, error) {
+	return d.ins, nil
+}
+
+func (d *unixDiscovery) Watch(_ context.Context, _ string) (registry.Watcher, error) {
+	return &unixWatcher{ins: d.ins, exit: make(chan struct{})}, nil
+}
+
+type unixWatcher struct {
+	ins  []*registry.ServiceInstance
+	sent bool
+	exit chan struct{}
+}
+
+func (w *unixWatcher) Next() ([]*registry.ServiceInstance, error) {
+	if !w.sent {
+		w.sent = true
+		return w.ins, nil
+	}
+	<-w.exit
+	return nil, context.Canceled
+}
+
+func (w *unixWatcher) Stop() error {
+	close(w.exit)
+	return nil
+}
+
+func TestUnixEndpoint(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "grpc.sock")
+	srv := NewServer(Network("unix"), Address(sock))
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "unix://" + sock + "?protocol=grpc"; e.String() != want {
+		t.Fatalf("expected %s got %s", want, e.String())
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+
+	dis := &unixDiscovery{ins: []*registry.ServiceInstance{{
+		ID:        "1",
+		Name:      "unix",
+		Endpoints: []string{e.String()},
+	}}}
+	for _, endpoint := range []string{"unix://" + sock, "discovery:///unix"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		conn, err := DialInsecure(ctx, WithEndpoint(endpoint), WithDiscovery(dis))
+		if err != nil {
+			cancel()
+			t.Fatal(err)
+		}
+		reply, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		cancel()
+		_ = conn.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", endpoint, err)
+		}
+		if reply.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+			t.Errorf("%s: expected %v got %v", endpoint, grpc_health_v1.HealthCheckResponse_SERVING, reply.Status)
+		}
+	}
+}
+
+func TestAbstractUnixEndpoint(t *testing.T) {
+	name := "@kratos-" + strings.ReplaceAll(t.Name(), "/", "-")
+	srv := NewServer(Network("unix"), Address(name))
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Skipf("abstract unix socket is not supported: %v", err)
+	}
+	if want := "unix:" + name + "?protocol=grpc"; e.String() != want {
+		t.Fatalf("expected %s got %s", want, e.String())
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	conn, err := DialInsecure(ctx, WithEndpoint("unix:"+name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,275 @@
+// Package accesslog provides an HTTP server filter logging the structured
+// access entries of the requests, with header redaction, per-route sampling
+// and slow request thresholds.
+package accesslog
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const redacted = "[REDACTED]"
+
+// Entry is an access log entry.
+type Entry struct {
+	Time      time.Time
+	Method    string
+	Path      string
+	Route     string
+	Status    int
+	Latency   time.Duration
+	BytesIn   int64
+	BytesOut  int64
+	Peer      string
+	Protocol  string
+	UserAgent string
+	Headers   map[string]string
+	Slow      bool
+
+	// BytesUncompressed is the response size before compression, which
+	// equals to BytesOut if the response is not compressed.
+	BytesUncompressed int64
+	// Encoding is the Content-Encoding of the response.
+	Encoding string
+}
+
+// FormatFunc returns the key values of the entry logged.
+type FormatFunc func(e *Entry) []interface{}
+
+// DefaultFormat is the default format of the entries.
+func DefaultFormat(e *Entry) []interface{} {
+	kvs := []interface{}{
+		"kind", "access",
+		"method", e.Method,
+		"path", e.Path,
+		"route", e.Route,
+		"status", e.Status,
+		"latency", e.Latency.Seconds(),
+		"bytes_in", e.BytesIn,
+		"bytes_out", e.BytesOut,
+		"peer", e.Peer,
+		"protocol", e.Protocol,
+		"user_agent", e.UserAgent,
+	}
+	if e.Encoding != "" {
+		kvs = append(kvs, "bytes_out_uncompressed", e.BytesUncompressed, "encoding", e.Encoding)
+	}
+	if e.Slow {
+		kvs = append(kvs, "slow", true)
+	}
+	names := make([]string, 0, len(e.Headers))
+	for k := range e.Headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		kvs = append(kvs, "header."+k, e.Headers[k])
+	}
+	return kvs
+}
+
+// Option is access log option.
+type Option func(*options)
+
+type options struct {
+	logger     log.Logger
+	format     FormatFunc
+	headers    []string
+	redact     map[string]bool
+	sampling   map[string]float64
+	sampleRate float64
+	slow       time.Duration
+}
+
+// WithLogger with the logger of the entries, default is the global logger.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithFormat with the format of the entries, default is DefaultFormat.
+func WithFormat(f FormatFunc) Option {
+	return func(o *options) {
+		o.format = f
+	}
+}
+
+// WithHeaders with the request headers logged, the values of Authorization,
+// Proxy-Authorization and Cookie are redacted.
+func WithHeaders(names ...string) Option {
+	return func(o *options) {
+		o.headers = make([]string, 0, len(names))
+		for _, name := range names {
+			o.headers = append(o.headers, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// WithRedactHeaders with more request headers whose values are redacted.
+func WithRedactHeaders(names ...string) Option {
+	return func(o *options) {
+		for _, name := range names {
+			o.redact[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// WithSampleRate with the rate of the requests logged, e.g. 0.01 for the
+// high-QPS routes, or for all routes if no route template is given. The
+// server errors and slow requests are always logged.
+func WithSampleRate(rate float64, routes ...string) Option {
+	return func(o *options) {
+		if len(routes) == 0 {
+			o.sampleRate = rate
+			return
+		}
+		for _, route := range routes {
+			o.sampling[route] = rate
+		}
+	}
+}
+
+// WithSlowThreshold with the latency above which the requests are logged
+// at the warn level as slow, default is no threshold.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(o *options) {
+		o.slow = d
+	}
+}
+
+// Filter returns an HTTP server filter logging the access entries.
+func Filter(opts ...Option) khttp.FilterFunc {
+	o := &options{
+		format:     DefaultFormat,
+		redact:     map[string]bool{"Authorization": true, "Proxy-Authorization": true, "Cookie": true},
+		sampling:   make(map[string]float64),
+		sampleRate: 1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			req, route := khttp.RecordRoute(req)
+			req, uncompressed := khttp.RecordUncompressedSize(req)
+			body := &countBody{ReadCloser: req.Body}
+			if req.Body != nil && req.Body != http.NoBody {
+				req.Body = body
+			}
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, req)
+			e := &Entry{
+				Time:      start,
+				Method:    req.Method,
+				Path:      req.URL.Path,
+				Route:     route(),
+				Status:    rw.status,
+				Latency:   time.Since(start),
+				BytesIn:   body.size,
+				BytesOut:  rw.size,
+				Peer:      peer(req.RemoteAddr),
+				Protocol:  "h" + strconv.Itoa(req.ProtoMajor),
+				UserAgent: req.UserAgent(),
+			}
+			e.BytesUncompressed, e.Encoding = e.BytesOut, rw.Header().Get("Content-Encoding")
+			if size := uncompressed(); size >= 0 {
+				e.BytesUncompressed = size
+			}
+			e.Slow = o.slow > 0 && e.Latency >= o.slow
+			level := log.LevelInfo
+			switch {
+			case e.Status >= http.StatusInternalServerError:
+				level = log.LevelError
+			case e.Slow:
+				level = log.LevelWarn
+			case !o.sampled(e.Route):
+				return
+			}
+			if len(o.headers) > 0 {
+				e.Headers = make(map[string]string, len(o.headers))
+				for _, name := range o.headers {
+					if v := req.Header.Get(name); v != "" {
+						if o.redact[name] {
+							v = redacted
+						}
+						e.Headers[name] = v
+					}
+				}
+			}
+			logger := o.logger
+			if logger == nil {
+				logger = log.GetLogger()
+			}
+			_ = log.WithContext(req.Context(), logger).Log(level, o.format(e)...)
+		})
+	}
+}
+
+func (o *options) sampled(route string) bool {
+	rate, ok := o.sampling[route]
+	if !ok {
+		rate = o.sampleRate
+	}
+	return rate >= 1 || rand.Float64() < rate
+}
+
+func peer(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// countBody counts the bytes of the request body read.
+type countBody struct {
+	io.ReadCloser
+	size int64
+}
+
+func (b *countBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	return n, err
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+	wrote  bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wrote {
+		w.wrote = true
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
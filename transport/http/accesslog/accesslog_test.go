@@ -0,0 +1,104 @@
+package accesslog
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/go-kratos/kratos/v2/transport/http/compress"
+)
+
+func newServer(buf *bytes.Buffer, opts ...Option) *khttp.Server {
+	opts = append([]Option{WithLogger(log.NewStdLogger(buf))}, opts...)
+	srv := khttp.NewServer(khttp.Filter(Filter(opts...)))
+	r := srv.Route("/")
+	r.GET("/users/{id}", func(ctx khttp.Context) error {
+		_, _ = io.Copy(io.Discard, ctx.Request().Body)
+		return ctx.String(http.StatusOK, "kratos")
+	})
+	r.GET("/slow", func(ctx khttp.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return ctx.String(http.StatusOK, "")
+	})
+	r.GET("/fail", func(ctx khttp.Context) error {
+		return ctx.String(http.StatusInternalServerError, "")
+	})
+	return srv
+}
+
+func serve(srv http.Handler, path string, header http.Header) {
+	req := httptest.NewRequest(http.MethodGet, path, strings.NewReader("body"))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestFilter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	srv := newServer(buf, WithHeaders("Authorization", "X-Tenant"))
+	serve(srv, "/users/1", http.Header{"Authorization": {"Bearer token"}, "X-Tenant": {"acme"}, "User-Agent": {"test"}})
+	out := buf.String()
+	for _, want := range []string{
+		"INFO", "method=GET", "path=/users/1", "route=/users/{id}", "status=200", "bytes_in=4", "bytes_out=6",
+		"peer=192.0.2.1", "protocol=h1", "user_agent=test", "header.Authorization=[REDACTED]", "header.X-Tenant=acme",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %s in %s", want, out)
+		}
+	}
+}
+
+func TestFilterSampling(t *testing.T) {
+	buf := new(bytes.Buffer)
+	srv := newServer(buf, WithSampleRate(0, "/users/{id}", "/slow", "/fail"), WithSlowThreshold(10*time.Millisecond))
+	serve(srv, "/users/1", nil)
+	if buf.Len() != 0 {
+		t.Errorf("expected the route not sampled got %s", buf.String())
+	}
+	serve(srv, "/slow", nil)
+	if out := buf.String(); !strings.Contains(out, "WARN") || !strings.Contains(out, "slow=true") {
+		t.Errorf("expected the slow request logged got %s", out)
+	}
+	buf.Reset()
+	serve(srv, "/fail", nil)
+	if out := buf.String(); !strings.Contains(out, "ERROR") || !strings.Contains(out, "status=500") {
+		t.Errorf("expected the server error logged got %s", out)
+	}
+}
+
+func TestFilterFormat(t *testing.T) {
+	buf := new(bytes.Buffer)
+	srv := newServer(buf, WithFormat(func(e *Entry) []interface{} {
+		return []interface{}{"line", e.Method + " " + e.Route}
+	}))
+	serve(srv, "/users/1", nil)
+	if out := buf.String(); !strings.Contains(out, "line=GET /users/{id}") {
+		t.Errorf("unexpected log %s", out)
+	}
+}
+
+func TestFilterCompressed(t *testing.T) {
+	buf := new(bytes.Buffer)
+	srv := khttp.NewServer(khttp.Filter(Filter(WithLogger(log.NewStdLogger(buf))), compress.Filter()))
+	srv.Route("/").GET("/users/{id}", func(ctx khttp.Context) error {
+		return ctx.String(http.StatusOK, strings.Repeat("kratos", 100))
+	})
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	out := buf.String()
+	for _, want := range []string{"bytes_out_uncompressed=600", "encoding=gzip", "bytes_out=" + strconv.Itoa(res.Body.Len())} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %s in %s", want, out)
+		}
+	}
+}
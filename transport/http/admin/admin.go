@@ -0,0 +1,247 @@
+// Package admin guards the admin and debug endpoints, e.g. pprof and the config
+// dump, with a shared authentication and IP allow-list, and audits their use.
+package admin
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/go-kratos/kratos/v2/log"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Record is an audit record of an admin request.
+type Record struct {
+	Time      time.Time
+	Principal string
+	Method    string
+	Path      string
+	Peer      string
+	Status    int
+	Allowed   bool
+}
+
+// Authenticator returns the principal of the request, e.g. the user or the
+// certificate subject, and reports whether the request is authenticated.
+type Authenticator func(req *http.Request) (principal string, ok bool)
+
+// Option is admin auth option.
+type Option func(*Auth)
+
+// WithBasicAuth authenticates the requests with the basic auth credentials.
+func WithBasicAuth(username, password string) Option {
+	return WithAuthenticator(func(req *http.Request) (string, bool) {
+		user, pass, ok := req.BasicAuth()
+		if !ok {
+			return "", false
+		}
+		userOK := subtle.ConstantTimeCompare([]byte(user), []byte(username)) == 1
+		passOK := subtle.ConstantTimeCompare([]byte(pass), []byte(password)) == 1
+		return user, userOK && passOK
+	})
+}
+
+// WithJWT authenticates the requests with the Bearer tokens verified by the key func,
+// the principal is the sub claim.
+func WithJWT(keyFunc jwt.Keyfunc, opts ...jwt.ParserOption) Option {
+	parser := jwt.NewParser(opts...)
+	return WithAuthenticator(func(req *http.Request) (string, bool) {
+		auths := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
+		if len(auths) != 2 || !strings.EqualFold(auths[0], "Bearer") {
+			return "", false
+		}
+		claims := jwt.RegisteredClaims{}
+		if _, err := parser.ParseWithClaims(auths[1], &claims, keyFunc); err != nil {
+			return "", false
+		}
+		return claims.Subject, true
+	})
+}
+
+// WithMTLS authenticates the requests with the verified client certificates of the
+// common names, or any verified client certificate if no name is given.
+func WithMTLS(commonNames ...string) Option {
+	return WithAuthenticator(func(req *http.Request) (string, bool) {
+		if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+			return "", false
+		}
+		cn := req.TLS.VerifiedChains[0][0].Subject.CommonName
+		if len(commonNames) == 0 {
+			return cn, true
+		}
+		for _, name := range commonNames {
+			if name == cn {
+				return cn, true
+			}
+		}
+		return "", false
+	})
+}
+
+// WithAuthenticator with a custom authenticator, the request is authenticated
+// if any of the authenticators accepts it.
+func WithAuthenticator(a Authenticator) Option {
+	return func(o *Auth) {
+		o.authenticators = append(o.authenticators, a)
+	}
+}
+
+// WithAllowedIPs with the IPs or CIDRs of the peers allowed, which are required
+// besides the authentication, e.g. 10.0.0.0/8.
+func WithAllowedIPs(cidrs ...string) Option {
+	return func(o *Auth) {
+		for _, cidr := range cidrs {
+			if !strings.Contains(cidr, "/") {
+				if strings.Contains(cidr, ":") {
+					cidr += "/128"
+				} else {
+					cidr += "/32"
+				}
+			}
+			_, n, err := net.ParseCIDR(cidr)
+			if err != nil {
+				log.Errorf("[Admin] invalid allowed IP %s: %v", cidr, err)
+				continue
+			}
+			o.allowed = append(o.allowed, n)
+		}
+	}
+}
+
+// WithAudit with the func receiving the audit records, default logs them.
+func WithAudit(f func(Record)) Option {
+	return func(o *Auth) {
+		o.audit = f
+	}
+}
+
+// Auth authenticates the admin requests, which are rejected with 401 if they
+// are not authenticated, or 403 if the peer is not allowed. Without any
+// authenticator only the allowed IPs are checked, which are required then.
+type Auth struct {
+	authenticators []Authenticator
+	allowed        []*net.IPNet
+	audit          func(Record)
+}
+
+// New creates an admin auth.
+func New(opts ...Option) *Auth {
+	a := &Auth{audit: logRecord}
+	for _, o := range opts {
+		o(a)
+	}
+	return a
+}
+
+// Filter returns the HTTP filter of the auth, e.g. for the AdminAuth server option.
+func (a *Auth) Filter() khttp.FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			r := Record{
+				Time:   time.Now(),
+				Method: req.Method,
+				Path:   req.URL.Path,
+				Peer:   peer(req.RemoteAddr),
+			}
+			defer func() { a.audit(r) }()
+			if !a.allow(r.Peer) {
+				r.Status = http.StatusForbidden
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			principal, ok := a.authenticate(req)
+			if !ok {
+				r.Status = http.StatusUnauthorized
+				w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			r.Principal, r.Allowed = principal, true
+			rw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, req)
+			r.Status = rw.status
+		})
+	}
+}
+
+func (a *Auth) allow(ip string) bool {
+	if len(a.allowed) == 0 {
+		return len(a.authenticators) > 0
+	}
+	parsed := net.ParseIP(ip)
+	for _, n := range a.allowed {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Auth) authenticate(req *http.Request) (string, bool) {
+	if len(a.authenticators) == 0 {
+		return "", true
+	}
+	for _, auth := range a.authenticators {
+		if principal, ok := auth(req); ok {
+			return principal, true
+		}
+	}
+	return "", false
+}
+
+func logRecord(r Record) {
+	level := log.LevelInfo
+	if !r.Allowed {
+		level = log.LevelWarn
+	}
+	_ = log.GetLogger().Log(level,
+		"kind", "audit",
+		"principal", r.Principal,
+		"method", r.Method,
+		"path", r.Path,
+		"peer", r.Peer,
+		"status", r.Status,
+		"allowed", r.Allowed,
+	)
+}
+
+func peer(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	wrote  bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wrote {
+		w.wrote = true
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
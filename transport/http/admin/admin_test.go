@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var secret = []byte("secret")
+
+func serve(a *Auth, req *http.Request) *httptest.ResponseRecorder {
+	h := a.Filter()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func TestAuth(t *testing.T) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   "ops",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []Record
+	a := New(
+		WithBasicAuth("admin", "pass"),
+		WithJWT(func(*jwt.Token) (interface{}, error) { return secret, nil }),
+		WithMTLS("client"),
+		WithAllowedIPs("10.0.0.0/8", "192.168.1.1"),
+		WithAudit(func(r Record) { records = append(records, r) }),
+	)
+	tests := []struct {
+		name       string
+		remoteAddr string
+		setup      func(req *http.Request)
+		code       int
+		principal  string
+	}{
+		{"basic", "10.1.2.3:80", func(req *http.Request) { req.SetBasicAuth("admin", "pass") }, http.StatusAccepted, "admin"},
+		{"basic wrong", "10.1.2.3:80", func(req *http.Request) { req.SetBasicAuth("admin", "wrong") }, http.StatusUnauthorized, ""},
+		{"jwt", "192.168.1.1:80", func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) }, http.StatusAccepted, "ops"},
+		{"jwt invalid", "192.168.1.1:80", func(req *http.Request) { req.Header.Set("Authorization", "Bearer x") }, http.StatusUnauthorized, ""},
+		{"mtls", "10.0.0.1:80", func(req *http.Request) {
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "client"}}}}}
+		}, http.StatusAccepted, "client"},
+		{"mtls other", "10.0.0.1:80", func(req *http.Request) {
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "other"}}}}}
+		}, http.StatusUnauthorized, ""},
+		{"ip denied", "172.16.0.1:80", func(req *http.Request) { req.SetBasicAuth("admin", "pass") }, http.StatusForbidden, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			records = nil
+			req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+			req.RemoteAddr = test.remoteAddr
+			test.setup(req)
+			res := serve(a, req)
+			if res.Code != test.code {
+				t.Fatalf("expected %d got %d", test.code, res.Code)
+			}
+			if len(records) != 1 {
+				t.Fatalf("expected 1 audit record got %d", len(records))
+			}
+			r := records[0]
+			if r.Status != test.code || r.Principal != test.principal || r.Allowed != (test.code == http.StatusAccepted) {
+				t.Errorf("unexpected record %+v", r)
+			}
+			if r.Path != "/debug/pprof/" || r.Method != http.MethodGet {
+				t.Errorf("unexpected record %+v", r)
+			}
+		})
+	}
+}
+
+func TestAuthIPsOnly(t *testing.T) {
+	a := New(WithAllowedIPs("127.0.0.1"), WithAudit(func(Record) {}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "127.0.0.1:80"
+	if res := serve(a, req); res.Code != http.StatusAccepted {
+		t.Errorf("expected 202 got %d", res.Code)
+	}
+	req.RemoteAddr = "127.0.0.2:80"
+	if res := serve(a, req); res.Code != http.StatusForbidden {
+		t.Errorf("expected 403 got %d", res.Code)
+	}
+}
+
+func TestAuthNone(t *testing.T) {
+	a := New(WithAudit(func(Record) {}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if res := serve(a, req); res.Code != http.StatusForbidden {
+		t.Errorf("expected 403 got %d", res.Code)
+	}
+}
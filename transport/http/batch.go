@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultBatchLimit is the default max number of the concurrent calls of a batch.
+const defaultBatchLimit = 8
+
+// Call is a call of InvokeBatch, whose reply and error are set after the batch.
+type Call struct {
+	Method  string
+	Path    string
+	Args    interface{}
+	Reply   interface{}
+	Options []CallOption
+
+	// Err is the error of the call, nil if it succeeds.
+	Err error
+}
+
+// BatchOption is InvokeBatch option.
+type BatchOption func(*batchOptions)
+
+type batchOptions struct {
+	limit    int
+	failFast bool
+}
+
+// BatchLimit with the max number of the concurrent calls, default is 8.
+func BatchLimit(n int) BatchOption {
+	return func(o *batchOptions) {
+		o.limit = n
+	}
+}
+
+// BatchFailFast with the batch canceled on the first failed call, the calls
+// not done fail with the context error, default is best effort, which makes
+// all the calls.
+func BatchFailFast() BatchOption {
+	return func(o *batchOptions) {
+		o.failFast = true
+	}
+}
+
+// InvokeBatch makes the calls concurrently over the connections of the client,
+// and sets the reply and the error of each of them. It returns the first error
+// in the order of the calls, or the error failing the batch in fail fast.
+func (client *Client) InvokeBatch(ctx context.Context, calls []Call, opts ...BatchOption) error {
+	o := batchOptions{limit: defaultBatchLimit}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.limit <= 0 {
+		o.limit = len(calls)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+		sem   = make(chan struct{}, o.limit)
+	)
+	for i := range calls {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(calls); j++ {
+				calls[j].Err = err
+			}
+			break
+		}
+		wg.Add(1)
+		go func(call *Call) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			call.Err = client.Invoke(ctx, call.Method, call.Path, call.Args, call.Reply, call.Options...)
+			if call.Err != nil && o.failFast {
+				once.Do(func() {
+					first = call.Err
+					cancel()
+				})
+			}
+		}(&calls[i])
+	}
+	wg.Wait()
+	if first != nil {
+		return first
+	}
+	for _, call := range calls {
+		if call.Err != nil {
+			return call.Err
+		}
+	}
+	return nil
+}
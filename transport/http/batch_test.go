@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestInvokeBatch(t *testing.T) {
+	var active, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"code":500,"reason":"FAILED"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"` + strings.TrimPrefix(r.URL.Path, "/") + `"}`))
+	}))
+	defer srv.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	type reply struct {
+		Name string `json:"name"`
+	}
+	calls := make([]Call, 10)
+	for i := range calls {
+		calls[i] = Call{Method: http.MethodGet, Path: "/" + string(rune('a'+i)), Reply: &reply{}}
+	}
+	calls[5].Path = "/fail"
+	err = client.InvokeBatch(context.Background(), calls, BatchLimit(3))
+	if kerrors.Reason(err) != "FAILED" {
+		t.Fatalf("expected the error of the failed call, got %v", err)
+	}
+	for i, call := range calls {
+		if i == 5 {
+			if kerrors.Code(call.Err) != http.StatusInternalServerError {
+				t.Errorf("expected the error of the call 5, got %v", call.Err)
+			}
+			continue
+		}
+		if call.Err != nil || call.Reply.(*reply).Name != string(rune('a'+i)) {
+			t.Errorf("unexpected call %d: %+v %v", i, call.Reply, call.Err)
+		}
+	}
+	if peak > 3 {
+		t.Errorf("expected at most 3 concurrent calls got %d", peak)
+	}
+
+	calls = make([]Call, 10)
+	for i := range calls {
+		calls[i] = Call{Method: http.MethodGet, Path: "/x", Reply: &reply{}}
+	}
+	calls[0].Path = "/fail"
+	err = client.InvokeBatch(context.Background(), calls, BatchLimit(1), BatchFailFast())
+	if kerrors.Reason(err) != "FAILED" {
+		t.Fatalf("expected the error of the failed call, got %v", err)
+	}
+	if !errors.Is(calls[9].Err, context.Canceled) {
+		t.Errorf("expected the last call canceled, got %v", calls[9].Err)
+	}
+}
@@ -12,7 +12,7 @@ import (
 // BindQuery bind vars parameters to target.
 func BindQuery(vars url.Values, target interface{}) error {
 	if err := encoding.GetCodec(form.Name).Unmarshal([]byte(vars.Encode()), target); err != nil {
-		return errors.BadRequest("CODEC", err.Error())
+		return bindError(err)
 	}
 	return nil
 }
@@ -23,7 +23,18 @@ func BindForm(req *http.Request, target interface{}) error {
 		return err
 	}
 	if err := encoding.GetCodec(form.Name).Unmarshal([]byte(req.Form.Encode()), target); err != nil {
-		return errors.BadRequest("CODEC", err.Error())
+		return bindError(err)
 	}
 	return nil
 }
+
+// bindError returns the bad request of the error, whose metadata "field" is
+// the name of the offending field if any.
+func bindError(err error) error {
+	e := errors.BadRequest("CODEC", err.Error())
+	var fe *form.FieldError
+	if errors.As(err, &fe) {
+		return e.WithMetadata(map[string]string{"field": fe.Field})
+	}
+	return e
+}
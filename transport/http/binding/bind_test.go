@@ -118,7 +118,8 @@ func TestBindForm(t *testing.T) {
 				},
 				target: &TestBind2{},
 			},
-			err:  kratoserror.BadRequest("CODEC", "Field Namespace:age ERROR:Invalid Integer Value 'a' Type 'int' Namespace 'age'"),
+			err: kratoserror.BadRequest("CODEC", "invalid value of field age: Invalid Integer Value 'a' Type 'int' Namespace 'age'").
+				WithMetadata(map[string]string{"field": "age"}),
 			want: nil,
 		},
 	}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// bufferSizes are the size classes of the pooled body buffers, the buffers
+// grown larger than the largest class are not pooled.
+var bufferSizes = [...]int{1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10}
+
+var bufferPools [len(bufferSizes)]sync.Pool
+
+// getBuffer returns a pooled buffer of the smallest class fitting size, which
+// is -1 if it is not known.
+func getBuffer(size int64) *bytes.Buffer {
+	for i, n := range bufferSizes {
+		if size <= int64(n) {
+			if b, ok := bufferPools[i].Get().(*bytes.Buffer); ok {
+				return b
+			}
+			return bytes.NewBuffer(make([]byte, 0, n))
+		}
+	}
+	return new(bytes.Buffer)
+}
+
+// putBuffer returns the buffer to the pool of the largest class it fits.
+func putBuffer(b *bytes.Buffer) {
+	c := b.Cap()
+	if c < bufferSizes[0] || c > 2*bufferSizes[len(bufferSizes)-1] {
+		return
+	}
+	b.Reset()
+	for i := len(bufferSizes) - 1; i >= 0; i-- {
+		if c >= bufferSizes[i] {
+			bufferPools[i].Put(b)
+			return
+		}
+	}
+}
+
+// bodyReader is the request body replaced by the one read, so that it is read
+// again by the handlers and the filters.
+type bodyReader struct {
+	bytes.Reader
+}
+
+func (*bodyReader) Close() error { return nil }
+
+// requestBody reads the request body once by a pooled buffer of its size
+// class, so that the decoders of the body share it, and the requests whose
+// bodies are not decoded are not read. The body is copied out of the buffer
+// before it is released, since the decoders and the handlers may keep it, e.g.
+// by the aliasing decoders. The body is reset to be read again.
+func requestBody(req *http.Request) ([]byte, error) {
+	tr, ok := serverTransport(req)
+	if !ok {
+		data, err := io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		return data, err
+	}
+	if tr.data == nil || req.Body != io.ReadCloser(&tr.body) {
+		buf := getBuffer(req.ContentLength)
+		_, err := buf.ReadFrom(req.Body)
+		if err != nil {
+			putBuffer(buf)
+			return nil, err
+		}
+		tr.data = append(make([]byte, 0, buf.Len()), buf.Bytes()...)
+		putBuffer(buf)
+	}
+	tr.body.Reset(tr.data)
+	req.Body = &tr.body
+	return tr.data, nil
+}
+
+// setRequestBody replaces the request body with the data, e.g. of the
+// transformed one, which is read again by requestBody.
+func setRequestBody(req *http.Request, data []byte) {
+	req.ContentLength = int64(len(data))
+	tr, ok := serverTransport(req)
+	if !ok {
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		return
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	tr.data = data
+	tr.body.Reset(data)
+	req.Body = &tr.body
+}
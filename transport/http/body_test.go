@@ -0,0 +1,106 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type countReader struct {
+	io.Reader
+	reads int
+}
+
+func (r *countReader) Read(p []byte) (int, error) {
+	r.reads++
+	return r.Reader.Read(p)
+}
+
+func TestRequestBody(t *testing.T) {
+	srv := NewServer()
+	body := &countReader{Reader: strings.NewReader(`{"path":"/index"}`)}
+	srv.Route("/").POST("/index", func(ctx Context) error {
+		var a, b testData
+		if err := ctx.Bind(&a); err != nil {
+			return err
+		}
+		reads := body.reads
+		if err := ctx.Bind(&b); err != nil {
+			return err
+		}
+		if body.reads != reads {
+			t.Errorf("expected the body read once, got %d reads", body.reads)
+		}
+		data, err := io.ReadAll(ctx.Request().Body)
+		if err != nil || string(data) != `{"path":"/index"}` {
+			t.Errorf("expected the body reset, got %q %v", data, err)
+		}
+		return ctx.Result(http.StatusOK, &b)
+	})
+	srv.Route("/").POST("/skip", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/index", io.NopCloser(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"path":"/index"}` {
+		t.Errorf("unexpected reply %d %s", w.Code, w.Body.String())
+	}
+
+	body = &countReader{Reader: strings.NewReader(`{"path":"/skip"}`)}
+	req = httptest.NewRequest(http.MethodPost, "/skip", io.NopCloser(body))
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+	if body.reads != 0 {
+		t.Errorf("expected the body not decoded not read, got %d reads", body.reads)
+	}
+}
+
+func TestBufferPool(t *testing.T) {
+	tests := []struct {
+		size int64
+		cap  int
+	}{
+		{-1, 1 << 10},
+		{0, 1 << 10},
+		{1 << 10, 1 << 10},
+		{1<<10 + 1, 4 << 10},
+		{100 << 10, 256 << 10},
+		{1 << 20, 0},
+	}
+	for _, test := range tests {
+		b := getBuffer(test.size)
+		if b.Cap() < test.cap {
+			t.Errorf("%d: expected the buffer of %d got %d", test.size, test.cap, b.Cap())
+		}
+		b.WriteString("data")
+		putBuffer(b)
+		if test.cap > 0 && b.Len() != 0 {
+			t.Errorf("%d: expected the buffer reset", test.size)
+		}
+	}
+}
+
+func TestRequestBodyRetained(t *testing.T) {
+	var kept [][]byte
+	keep := func(_ *http.Request, body []byte) ([]byte, error) {
+		kept = append(kept, body)
+		return body, nil
+	}
+	srv := NewServer()
+	srv.Route("/").POST("/index", func(ctx Context) error {
+		var in testData
+		return ctx.Bind(&in)
+	}, TransformRequestBody(keep))
+	for _, body := range []string{`{"path":"/first"}`, `{"path":"/other"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/index", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		srv.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if len(kept) != 2 || string(kept[0]) != `{"path":"/first"}` {
+		t.Errorf("expected the body kept after the request, got %q", kept)
+	}
+}
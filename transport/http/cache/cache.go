@@ -0,0 +1,454 @@
+// Package cache provides an HTTP server filter caching the successful GET
+// responses of the chosen routes in a kv.Store, e.g. in memory or redis. The
+// responses have ETags, the conditional requests are answered with 304, and
+// the stale responses are served while they are revalidated in the background.
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/kv"
+	"github.com/go-kratos/kratos/v2/log"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// StatusHeader is set on the responses of the cached routes, HIT, MISS or STALE.
+const StatusHeader = "X-Cache"
+
+// Option is cache option.
+type Option func(*Cache)
+
+// WithStore with the store of the responses, default is a kv.Memory.
+func WithStore(s kv.Store) Option {
+	return func(c *Cache) {
+		c.store = s
+	}
+}
+
+// WithPrefix with the prefix of the keys in the store, default is "httpcache:".
+func WithPrefix(prefix string) Option {
+	return func(c *Cache) {
+		c.prefix = prefix
+	}
+}
+
+// WithTTL with how long the responses are fresh, default is 1m. The max-age
+// or s-maxage of the Cache-Control response header overrides it.
+func WithTTL(d time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = d
+	}
+}
+
+// WithStaleWhileRevalidate with how long the responses are served after they
+// expire, while they are revalidated in the background, default is 0.
+func WithStaleWhileRevalidate(d time.Duration) Option {
+	return func(c *Cache) {
+		c.stale = d
+	}
+}
+
+// WithPaths with the path patterns of the cached routes, e.g. "/v1/products/*",
+// of the path.Match syntax, default is all the paths.
+func WithPaths(patterns ...string) Option {
+	return func(c *Cache) {
+		c.paths = patterns
+	}
+}
+
+// WithMatch with the func reporting whether the request is cached, besides the paths.
+func WithMatch(fn func(*http.Request) bool) Option {
+	return func(c *Cache) {
+		c.match = fn
+	}
+}
+
+// WithVary with the request headers the cached response must match, e.g.
+// Accept-Language, the response of another value replaces it.
+func WithVary(headers ...string) Option {
+	return func(c *Cache) {
+		c.vary = headers
+	}
+}
+
+// WithMaxBodySize with the max size of the cached bodies, default is 1MB.
+func WithMaxBodySize(n int) Option {
+	return func(c *Cache) {
+		c.maxBodySize = n
+	}
+}
+
+// entry is a cached response.
+type entry struct {
+	Status int               `json:"status"`
+	Header http.Header       `json:"header"`
+	Body   []byte            `json:"body"`
+	Stored time.Time         `json:"stored"`
+	TTL    time.Duration     `json:"ttl"`
+	Vary   map[string]string `json:"vary,omitempty"`
+}
+
+// Cache is an HTTP response cache.
+type Cache struct {
+	store       kv.Store
+	prefix      string
+	ttl         time.Duration
+	stale       time.Duration
+	paths       []string
+	match       func(*http.Request) bool
+	vary        []string
+	maxBodySize int
+	now         func() time.Time
+
+	refreshing sync.Map
+}
+
+// New creates a response cache.
+func New(opts ...Option) *Cache {
+	c := &Cache{
+		prefix:      "httpcache:",
+		ttl:         time.Minute,
+		maxBodySize: 1 << 20,
+		now:         time.Now,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	if c.store == nil {
+		c.store = kv.NewMemory()
+	}
+	return c
+}
+
+// Invalidate removes the cached responses of the request URIs, e.g. "/v1/products/1".
+// The successful unsafe requests of a URI through the filter invalidate it as well.
+func (c *Cache) Invalidate(ctx context.Context, uris ...string) error {
+	for _, uri := range uris {
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		if err != nil {
+			return err
+		}
+		if err = c.store.Delete(ctx, c.key(req)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Filter returns the HTTP server filter caching the responses, e.g. of a route,
+// or of the server with the paths of the cached routes:
+//
+//	http.Filter(cache.New(cache.WithPaths("/v1/products/*")).Filter())
+func (c *Cache) Filter() khttp.FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if !c.matches(req) {
+				next.ServeHTTP(w, req)
+				return
+			}
+			switch req.Method {
+			case http.MethodGet, http.MethodHead:
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+				c.serveUnsafe(next, w, req)
+				return
+			default:
+				next.ServeHTTP(w, req)
+				return
+			}
+			directives := cacheControl(req.Header.Get("Cache-Control"))
+			_, noCache := directives["no-cache"]
+			_, noStore := directives["no-store"]
+			if !noCache && !noStore {
+				if e, ok := c.lookup(req); ok {
+					age := c.now().Sub(e.Stored)
+					status := "HIT"
+					if age >= e.TTL {
+						status = "STALE"
+						c.revalidate(next, req)
+					}
+					w.Header().Set("Age", strconv.Itoa(int(age.Seconds())))
+					c.write(w, req, e, status)
+					return
+				}
+			}
+			if req.Method == http.MethodHead {
+				next.ServeHTTP(w, req)
+				return
+			}
+			w.Header().Set(StatusHeader, "MISS")
+			cw := &captureWriter{ResponseWriter: w, max: c.maxBodySize, status: http.StatusOK}
+			next.ServeHTTP(cw, req)
+			if cw.passthrough {
+				return
+			}
+			e, ok := c.entry(req, w.Header(), cw.status, cw.buf.Bytes())
+			if ok && !noStore {
+				c.save(req, e)
+			}
+			if !ok {
+				cw.flush()
+				return
+			}
+			c.write(w, req, e, "MISS")
+		})
+	}
+}
+
+func (c *Cache) matches(req *http.Request) bool {
+	if c.match != nil && !c.match(req) {
+		return false
+	}
+	if len(c.paths) == 0 {
+		return true
+	}
+	for _, p := range c.paths {
+		if ok, _ := path.Match(p, req.URL.Path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// key is the path and the sorted query of the request.
+func (c *Cache) key(req *http.Request) string {
+	key := c.prefix + req.URL.Path
+	if q := req.URL.Query(); len(q) > 0 {
+		key += "?" + q.Encode()
+	}
+	return key
+}
+
+func (c *Cache) lookup(req *http.Request) (*entry, bool) {
+	data, err := c.store.Get(req.Context(), c.key(req))
+	if err != nil {
+		if !errors.Is(err, kv.ErrNotFound) {
+			log.Errorf("[HTTP Cache] failed to get the response of %s: %v", req.URL, err)
+		}
+		return nil, false
+	}
+	e := new(entry)
+	if err = json.Unmarshal(data, e); err != nil {
+		return nil, false
+	}
+	for _, h := range c.vary {
+		if e.Vary[h] != req.Header.Get(h) {
+			return nil, false
+		}
+	}
+	if c.now().Sub(e.Stored) >= e.TTL+c.stale {
+		return nil, false
+	}
+	return e, true
+}
+
+// entry returns the entry of the response, or false if it is not cacheable.
+func (c *Cache) entry(req *http.Request, header http.Header, status int, body []byte) (*entry, bool) {
+	if status != http.StatusOK {
+		return nil, false
+	}
+	ttl := c.ttl
+	directives := cacheControl(header.Get("Cache-Control"))
+	for _, d := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := directives[d]; ok {
+			return nil, false
+		}
+	}
+	for _, d := range []string{"s-maxage", "max-age"} {
+		if v, ok := directives[d]; ok {
+			if secs, err := strconv.Atoi(v); err == nil {
+				ttl = time.Duration(secs) * time.Second
+				break
+			}
+		}
+	}
+	if ttl <= 0 {
+		return nil, false
+	}
+	h := header.Clone()
+	if h.Get("ETag") == "" {
+		sum := sha256.Sum256(body)
+		h.Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	}
+	e := &entry{Status: status, Header: h, Body: body, Stored: c.now(), TTL: ttl}
+	if len(c.vary) > 0 {
+		e.Vary = make(map[string]string, len(c.vary))
+		for _, v := range c.vary {
+			e.Vary[v] = req.Header.Get(v)
+		}
+	}
+	return e, true
+}
+
+// save stores the entry, which is kept during the stale window after it expires.
+func (c *Cache) save(req *http.Request, e *entry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err = c.store.Set(context.Background(), c.key(req), data, e.TTL+c.stale); err != nil {
+		log.Errorf("[HTTP Cache] failed to save the response of %s: %v", req.URL, err)
+	}
+}
+
+// write writes the entry, or 304 if it matches the If-None-Match request header.
+func (c *Cache) write(w http.ResponseWriter, req *http.Request, e *entry, status string) {
+	header := w.Header()
+	for k, v := range e.Header {
+		header[k] = v
+	}
+	header.Set(StatusHeader, status)
+	if etagMatch(req.Header.Get("If-None-Match"), e.Header.Get("ETag")) {
+		header.Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	header.Set("Content-Length", strconv.Itoa(len(e.Body)))
+	w.WriteHeader(e.Status)
+	if req.Method != http.MethodHead {
+		_, _ = w.Write(e.Body)
+	}
+}
+
+// revalidate refreshes the stale entry of the request in the background,
+// once at a time for a key.
+func (c *Cache) revalidate(next http.Handler, req *http.Request) {
+	key := c.key(req)
+	if _, loaded := c.refreshing.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+	r := req.Clone(context.Background())
+	r.Method = http.MethodGet
+	r.Header.Del("If-None-Match")
+	r.Header.Del("If-Modified-Since")
+	go func() {
+		defer c.refreshing.Delete(key)
+		cw := &captureWriter{ResponseWriter: &discardWriter{header: make(http.Header)}, max: c.maxBodySize, status: http.StatusOK}
+		next.ServeHTTP(cw, r)
+		if cw.passthrough {
+			return
+		}
+		if e, ok := c.entry(r, cw.Header(), cw.status, cw.buf.Bytes()); ok {
+			c.save(r, e)
+		}
+	}()
+}
+
+// serveUnsafe invalidates the cached response of the URI if the unsafe request succeeds.
+func (c *Cache) serveUnsafe(next http.Handler, w http.ResponseWriter, req *http.Request) {
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	next.ServeHTTP(sw, req)
+	if sw.status < http.StatusBadRequest {
+		if err := c.store.Delete(context.Background(), c.key(req)); err != nil {
+			log.Errorf("[HTTP Cache] failed to invalidate the response of %s: %v", req.URL, err)
+		}
+	}
+}
+
+func cacheControl(v string) map[string]string {
+	directives := make(map[string]string)
+	for _, d := range strings.Split(v, ",") {
+		if d = strings.TrimSpace(d); d == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(d, "=")
+		directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+	}
+	return directives
+}
+
+// etagMatch reports whether the weak comparison of the If-None-Match tags matches the etag.
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// captureWriter buffers the response up to the max size, the larger ones are
+// passed through and not cached.
+type captureWriter struct {
+	http.ResponseWriter
+	status      int
+	wrote       bool
+	buf         bytes.Buffer
+	max         int
+	passthrough bool
+}
+
+func (w *captureWriter) WriteHeader(code int) {
+	if w.wrote {
+		return
+	}
+	w.wrote = true
+	w.status = code
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.buf.Len()+len(b) <= w.max {
+		return w.buf.Write(b)
+	}
+	w.flush()
+	return w.ResponseWriter.Write(b)
+}
+
+// flush writes the buffered response and passes the rest through.
+func (w *captureWriter) flush() {
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	wrote  bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wrote {
+		w.wrote = true
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// discardWriter is the response writer of the background revalidations.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
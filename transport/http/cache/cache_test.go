@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newHandler(calls *int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.URL.Path == "/private" {
+			w.Header().Set("Cache-Control", "private")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"n":` + strconv.Itoa(int(n)) + `}`))
+	})
+}
+
+func do(h http.Handler, method, uri string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, uri, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestFilter(t *testing.T) {
+	var calls int32
+	now := time.Unix(1000, 0)
+	c := New(WithTTL(time.Minute), WithPaths("/products", "/private"))
+	c.now = func() time.Time { return now }
+	h := c.Filter()(newHandler(&calls))
+
+	first := do(h, http.MethodGet, "/products?b=2&a=1", nil)
+	if first.Header().Get(StatusHeader) != "MISS" || first.Header().Get("ETag") == "" {
+		t.Fatalf("expected a miss with ETag, got %v", first.Header())
+	}
+	second := do(h, http.MethodGet, "/products?a=1&b=2", nil)
+	if second.Header().Get(StatusHeader) != "HIT" || second.Body.String() != first.Body.String() {
+		t.Errorf("expected a hit of the normalized query, got %v %s", second.Header(), second.Body)
+	}
+	etag := first.Header().Get("ETag")
+	if rec := do(h, http.MethodGet, "/products?a=1&b=2", http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("expected 304, got %d", rec.Code)
+	}
+	if rec := do(h, http.MethodGet, "/products?a=1&b=2", http.Header{"Cache-Control": {"no-cache"}}); rec.Header().Get(StatusHeader) != "MISS" {
+		t.Errorf("expected the no-cache request to bypass the cache")
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+
+	do(h, http.MethodGet, "/private", nil)
+	if rec := do(h, http.MethodGet, "/private", nil); rec.Header().Get(StatusHeader) != "MISS" {
+		t.Errorf("expected the private response not cached")
+	}
+	if rec := do(h, http.MethodGet, "/other", nil); rec.Header().Get(StatusHeader) != "" {
+		t.Errorf("expected the other paths not cached")
+	}
+
+	do(h, http.MethodDelete, "/products?a=1&b=2", nil)
+	if rec := do(h, http.MethodGet, "/products?a=1&b=2", nil); rec.Header().Get(StatusHeader) != "MISS" {
+		t.Errorf("expected the unsafe request to invalidate the response")
+	}
+	if err := c.Invalidate(context.Background(), "/products?b=2&a=1"); err != nil {
+		t.Fatal(err)
+	}
+	if rec := do(h, http.MethodGet, "/products?a=1&b=2", nil); rec.Header().Get(StatusHeader) != "MISS" {
+		t.Errorf("expected the invalidated response")
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var calls int32
+	now := time.Unix(1000, 0)
+	c := New(WithTTL(time.Second), WithStaleWhileRevalidate(time.Minute))
+	c.now = func() time.Time { return now }
+	h := c.Filter()(newHandler(&calls))
+
+	do(h, http.MethodGet, "/products", nil)
+	now = now.Add(2 * time.Second)
+	if rec := do(h, http.MethodGet, "/products", nil); rec.Header().Get(StatusHeader) != "STALE" || rec.Body.String() != `{"n":1}` {
+		t.Fatalf("expected the stale response, got %v %s", rec.Header(), rec.Body)
+	}
+	for i := 0; i < 100 && atomic.LoadInt32(&calls) < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	for i := 0; i < 100; i++ {
+		if rec := do(h, http.MethodGet, "/products", nil); rec.Header().Get(StatusHeader) == "HIT" {
+			if rec.Body.String() != `{"n":2}` {
+				t.Errorf("expected the revalidated response, got %s", rec.Body)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("expected the response revalidated in the background")
+}
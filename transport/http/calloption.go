@@ -2,6 +2,8 @@ package http
 
 import (
 	"net/http"
+	"net/url"
+	"time"
 )
 
 // CallOption configures a Call before it starts or extracts information from
@@ -21,6 +23,10 @@ type callInfo struct {
 	operation     string
 	pathTemplate  string
 	headerCarrier *http.Header
+	idempotent    bool
+	header        http.Header
+	query         url.Values
+	timeout       time.Duration
 }
 
 // EmptyCallOption does not alter the Call configuration.
@@ -113,3 +119,90 @@ func (o HeaderCallOption) after(_ *callInfo, cs *csAttempt) {
 		*o.header = cs.res.Header
 	}
 }
+
+// Idempotent marks the call as idempotent, so that it can be retried or hedged
+// by the client retry policy even if the method is not GET or HEAD.
+func Idempotent() CallOption {
+	return IdempotentCallOption{}
+}
+
+// IdempotentCallOption is mark the client call as safe to retry
+type IdempotentCallOption struct {
+	EmptyCallOption
+}
+
+func (o IdempotentCallOption) before(c *callInfo) error {
+	c.idempotent = true
+	return nil
+}
+
+// WithHeader adds the request header of the call.
+func WithHeader(key, value string) CallOption {
+	return RequestHeaderCallOption{Key: key, Value: value}
+}
+
+// RequestHeaderCallOption is add request header for client call
+type RequestHeaderCallOption struct {
+	EmptyCallOption
+	Key   string
+	Value string
+}
+
+func (o RequestHeaderCallOption) before(c *callInfo) error {
+	if c.header == nil {
+		c.header = make(http.Header)
+	}
+	c.header.Add(o.Key, o.Value)
+	return nil
+}
+
+// WithQuery adds the url query parameter of the call.
+func WithQuery(key, value string) CallOption {
+	return QueryCallOption{Key: key, Value: value}
+}
+
+// QueryCallOption is add url query parameter for client call
+type QueryCallOption struct {
+	EmptyCallOption
+	Key   string
+	Value string
+}
+
+func (o QueryCallOption) before(c *callInfo) error {
+	if c.query == nil {
+		c.query = make(url.Values)
+	}
+	c.query.Add(o.Key, o.Value)
+	return nil
+}
+
+// WithCallTimeout sets the timeout of the call, including the retries.
+// It can only shorten the client timeout, which still applies to every attempt.
+func WithCallTimeout(d time.Duration) CallOption {
+	return TimeoutCallOption{Timeout: d}
+}
+
+// TimeoutCallOption is set timeout for client call
+type TimeoutCallOption struct {
+	EmptyCallOption
+	Timeout time.Duration
+}
+
+func (o TimeoutCallOption) before(c *callInfo) error {
+	c.timeout = o.Timeout
+	return nil
+}
+
+// apply sets the request headers and query parameters of the call to the request.
+func (c *callInfo) apply(req *http.Request) {
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	if len(c.query) > 0 {
+		query := req.URL.Query()
+		for k, v := range c.query {
+			query[k] = append(query[k], v...)
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+}
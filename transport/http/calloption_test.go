@@ -1,9 +1,12 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestEmptyCallOptions(t *testing.T) {
@@ -109,3 +112,71 @@ func TestHeaderCallOption_after(t *testing.T) {
 		t.Errorf("want: %v,got: %v", &h, o.(HeaderCallOption).header)
 	}
 }
+
+func TestRequestCallOptions(t *testing.T) {
+	c := defaultCallInfo("/hello")
+	for _, o := range []CallOption{
+		WithHeader("X-Tenant", "a"),
+		WithHeader("X-Tenant", "b"),
+		WithQuery("page", "1"),
+		WithCallTimeout(time.Second),
+	} {
+		if err := o.before(&c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.timeout != time.Second {
+		t.Errorf("expected timeout %v got %v", time.Second, c.timeout)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/hello?name=kratos", nil)
+	req.Header.Set("X-Tenant", "default")
+	c.apply(req)
+	if v := req.Header.Values("X-Tenant"); !reflect.DeepEqual(v, []string{"a", "b"}) {
+		t.Errorf("unexpected header %v", v)
+	}
+	if v := req.URL.RawQuery; v != "name=kratos&page=1" {
+		t.Errorf("unexpected query %s", v)
+	}
+}
+
+func TestInvokeCallOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("delay") != "" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"tenant":"` + r.Header.Get("X-Tenant") + `","page":"` + r.URL.Query().Get("page") + `"}`))
+	}))
+	defer srv.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(srv.Listener.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var reply map[string]string
+	err = client.Invoke(context.Background(), http.MethodGet, "/hello", nil, &reply, WithHeader("X-Tenant", "a"), WithQuery("page", "2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply["tenant"] != "a" || reply["page"] != "2" {
+		t.Errorf("unexpected reply %v", reply)
+	}
+	err = client.Invoke(context.Background(), http.MethodGet, "/hello", nil, &reply, WithQuery("delay", "1"), WithCallTimeout(10*time.Millisecond))
+	if err == nil {
+		t.Error("expected the call to time out")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/hello", nil)
+	res, err := client.Do(req, WithHeader("X-Tenant", "b"), WithCallTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if err = DefaultResponseDecoder(context.Background(), res, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply["tenant"] != "b" {
+		t.Errorf("unexpected reply %v", reply)
+	}
+}
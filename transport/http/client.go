@@ -6,18 +6,26 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/drain"
+	"github.com/go-kratos/kratos/v2/internal/endpoint"
 	"github.com/go-kratos/kratos/v2/internal/host"
 	"github.com/go-kratos/kratos/v2/internal/httputil"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/circuitbreaker"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/outlier"
 	"github.com/go-kratos/kratos/v2/selector/wrr"
 	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/dnscache"
+	"github.com/go-kratos/kratos/v2/transport/tlscert"
 )
 
 func init() {
@@ -42,18 +50,93 @@ type ClientOption func(*clientOptions)
 type clientOptions struct {
 	ctx          context.Context
 	tlsConf      *tls.Config
+	keyLog       io.Writer
 	timeout      time.Duration
 	endpoint     string
 	userAgent    string
 	encoder      EncodeRequestFunc
 	decoder      DecodeResponseFunc
 	errorDecoder DecodeErrorFunc
+	statusMapper ErrorMapFunc
 	transport    http.RoundTripper
 	nodeFilters  []selector.NodeFilter
 	discovery    registry.Discovery
 	middleware   []middleware.Middleware
 	block        bool
 	subsetSize   int
+	schemes      []string
+	retry        *retryPolicy
+	breaker      *circuitbreaker.NodeBreaker
+	ejector      *outlier.Ejector
+	pool         poolOptions
+	dial         dialOptions
+	repicks      int
+
+	schemeTransports map[string]http.RoundTripper
+	hashKey          HashKeyFunc
+	sticky           SessionFunc
+	stickyTTL        time.Duration
+	selector         selector.Builder
+	drains           *drain.Set
+	checkRedirect    func(req *http.Request, via []*http.Request) error
+	maxDecompressed  int64
+}
+
+// poolOptions tunes the connection pool of the client transport.
+type poolOptions struct {
+	maxIdleConns        *int
+	maxIdleConnsPerHost *int
+	maxConnsPerHost     *int
+	idleConnTimeout     *time.Duration
+}
+
+func (o poolOptions) isSet() bool {
+	return o.maxIdleConns != nil || o.maxIdleConnsPerHost != nil || o.maxConnsPerHost != nil || o.idleConnTimeout != nil
+}
+
+// dialOptions tunes the dialer and the proxy of the client transport.
+type dialOptions struct {
+	proxy       func(*http.Request) (*url.URL, error)
+	dialContext dnscache.DialFunc
+	resolver    *net.Resolver
+	dnsCache    *dnscache.Cache
+}
+
+func (o dialOptions) isSet() bool {
+	return o.proxy != nil || o.dialContext != nil || o.resolver != nil || o.dnsCache != nil
+}
+
+func (o dialOptions) apply(tr *http.Transport) {
+	if o.proxy != nil {
+		tr.Proxy = o.proxy
+	}
+	if o.dialContext != nil {
+		tr.DialContext = o.dialContext
+	} else if o.resolver != nil {
+		tr.DialContext = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Resolver:  o.resolver,
+		}).DialContext
+	}
+	if o.dnsCache != nil {
+		tr.DialContext = o.dnsCache.Dial(tr.DialContext)
+	}
+}
+
+func (o poolOptions) apply(tr *http.Transport) {
+	if o.maxIdleConns != nil {
+		tr.MaxIdleConns = *o.maxIdleConns
+	}
+	if o.maxIdleConnsPerHost != nil {
+		tr.MaxIdleConnsPerHost = *o.maxIdleConnsPerHost
+	}
+	if o.maxConnsPerHost != nil {
+		tr.MaxConnsPerHost = *o.maxConnsPerHost
+	}
+	if o.idleConnTimeout != nil {
+		tr.IdleConnTimeout = *o.idleConnTimeout
+	}
 }
 
 // WithSubset with client discovery subset size.
@@ -92,6 +175,16 @@ func WithMiddleware(m ...middleware.Middleware) ClientOption {
 	}
 }
 
+// WithPreferredSchemes with the endpoint schemes preferred in order when the
+// instances advertise them, e.g. "h3", falling back to http or https. The requests
+// to such a node have its scheme, which the transport must support, e.g. by
+// http.Transport.RegisterProtocol.
+func WithPreferredSchemes(schemes ...string) ClientOption {
+	return func(o *clientOptions) {
+		o.schemes = schemes
+	}
+}
+
 // WithEndpoint with client addr.
 func WithEndpoint(endpoint string) ClientOption {
 	return func(o *clientOptions) {
@@ -120,6 +213,15 @@ func WithErrorDecoder(errorDecoder DecodeErrorFunc) ClientOption {
 	}
 }
 
+// WithCheckRedirect with the redirect policy of the client, default is
+// following at most 10 redirects, http.ErrUseLastResponse returns them, e.g.
+// of the reverse proxies.
+func WithCheckRedirect(fn func(req *http.Request, via []*http.Request) error) ClientOption {
+	return func(o *clientOptions) {
+		o.checkRedirect = fn
+	}
+}
+
 // WithDiscovery with client discovery.
 func WithDiscovery(d registry.Discovery) ClientOption {
 	return func(o *clientOptions) {
@@ -134,6 +236,39 @@ func WithNodeFilter(filters ...selector.NodeFilter) ClientOption {
 	}
 }
 
+// WithSelector with the selector builder of the client, e.g. a ringhash builder,
+// default is the global selector.
+func WithSelector(b selector.Builder) ClientOption {
+	return func(o *clientOptions) {
+		o.selector = b
+	}
+}
+
+// WithCircuitBreaker with the per node circuit breaker, the nodes with open
+// breakers are skipped by the selector and calls fail fast when all are open.
+func WithCircuitBreaker(b *circuitbreaker.NodeBreaker) ClientOption {
+	return func(o *clientOptions) {
+		o.breaker = b
+	}
+}
+
+// WithOutlierDetection with the outlier ejector, the nodes with high error
+// rates or latencies are ejected from the selector for a while.
+func WithOutlierDetection(e *outlier.Ejector) ClientOption {
+	return func(o *clientOptions) {
+		o.ejector = e
+	}
+}
+
+// WithNodeRepick with the max times another node is picked when the selected node
+// refuses the connection or sends GOAWAY, e.g. when it is draining in a rolling
+// deploy, default is 1 and 0 disables it. It only applies to the discovery clients.
+func WithNodeRepick(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.repicks = n
+	}
+}
+
 // WithBlock with client block.
 func WithBlock() ClientOption {
 	return func(o *clientOptions) {
@@ -141,6 +276,74 @@ func WithBlock() ClientOption {
 	}
 }
 
+// WithRetry with client retry policy, only GET and HEAD requests or the calls with
+// the Idempotent call option are retried, see RetryOption for the defaults.
+func WithRetry(opts ...RetryOption) ClientOption {
+	return func(o *clientOptions) {
+		o.retry = newRetryPolicy(opts...)
+	}
+}
+
+// WithMaxIdleConns with the max idle connections across all hosts, zero means no limit.
+func WithMaxIdleConns(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.maxIdleConns = &n
+	}
+}
+
+// WithMaxIdleConnsPerHost with the max idle connections kept per host,
+// the net/http default is 2 which causes connection churn under load.
+func WithMaxIdleConnsPerHost(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.maxIdleConnsPerHost = &n
+	}
+}
+
+// WithMaxConnsPerHost with the max connections per host, including the ones
+// in the dialing, active and idle states, zero means no limit.
+func WithMaxConnsPerHost(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.maxConnsPerHost = &n
+	}
+}
+
+// WithIdleConnTimeout with the duration an idle connection is kept in the pool.
+func WithIdleConnTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.idleConnTimeout = &d
+	}
+}
+
+// WithDNSCache with the DNS cache of the client dialer.
+func WithDNSCache(c *dnscache.Cache) ClientOption {
+	return func(o *clientOptions) {
+		o.dial.dnsCache = c
+	}
+}
+
+// WithProxy with the proxy func of the requests, e.g. http.ProxyURL of a HTTP(S)
+// or SOCKS5 proxy URL, default is http.ProxyFromEnvironment.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) ClientOption {
+	return func(o *clientOptions) {
+		o.dial.proxy = proxy
+	}
+}
+
+// WithDialContext with the dial func of the connections, e.g. a fake dialer in tests.
+func WithDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) ClientOption {
+	return func(o *clientOptions) {
+		o.dial.dialContext = dial
+	}
+}
+
+// WithResolver with the DNS resolver of the default dialer,
+// it is ignored when the dial func is set by WithDialContext.
+func WithResolver(r *net.Resolver) ClientOption {
+	return func(o *clientOptions) {
+		o.dial.resolver = r
+	}
+}
+
 // WithTLSConfig with tls config.
 func WithTLSConfig(c *tls.Config) ClientOption {
 	return func(o *clientOptions) {
@@ -148,6 +351,15 @@ func WithTLSConfig(c *tls.Config) ClientOption {
 	}
 }
 
+// WithUnsafeTLSKeyLogWriter with the writer of the TLS master secrets, so that the
+// sessions can be decrypted by e.g. Wireshark in test environments.
+// It is ignored when TLS is not configured, DO NOT use it in production.
+func WithUnsafeTLSKeyLogWriter(w io.Writer) ClientOption {
+	return func(o *clientOptions) {
+		o.keyLog = w
+	}
+}
+
 // Client is an HTTP client.
 type Client struct {
 	opts     clientOptions
@@ -156,6 +368,9 @@ type Client struct {
 	cc       *http.Client
 	insecure bool
 	selector selector.Selector
+	stats    *connStats
+	owned    bool
+	sticky   *stickyTable
 }
 
 // NewClient returns an HTTP client.
@@ -168,10 +383,36 @@ func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 		errorDecoder: DefaultErrorDecoder,
 		transport:    http.DefaultTransport,
 		subsetSize:   25,
+		repicks:      1,
+		drains:       drain.New(10 * time.Second),
+
+		maxDecompressed: defaultMaxDecompressedSize,
 	}
 	for _, o := range opts {
 		o(&options)
 	}
+	if options.breaker != nil {
+		options.nodeFilters = append(options.nodeFilters[:len(options.nodeFilters):len(options.nodeFilters)], options.breaker.Filter())
+		options.middleware = append(options.middleware[:len(options.middleware):len(options.middleware)], options.breaker.Middleware())
+	}
+	if options.ejector != nil {
+		options.nodeFilters = append(options.nodeFilters[:len(options.nodeFilters):len(options.nodeFilters)], options.ejector.Filter())
+		options.middleware = append(options.middleware[:len(options.middleware):len(options.middleware)], options.ejector.Middleware())
+	}
+	if options.drains != nil {
+		options.nodeFilters = append(options.nodeFilters[:len(options.nodeFilters):len(options.nodeFilters)], options.drains.Filter())
+	}
+	stats := new(connStats)
+	// the client owns a copy of the default transport, or of the given one when its pool or dialer is tuned.
+	tr, owned := options.transport.(*http.Transport)
+	if owned = owned && (tr == http.DefaultTransport || options.pool.isSet() || options.dial.isSet()); owned {
+		tr = tr.Clone()
+		options.pool.apply(tr)
+		options.dial.apply(tr)
+		stats.instrument(tr)
+		options.transport = tr
+	}
+	options.tlsConf = tlscert.KeyLog(options.tlsConf, options.keyLog)
 	if options.tlsConf != nil {
 		if tr, ok := options.transport.(*http.Transport); ok {
 			tr.TLSClientConfig = options.tlsConf
@@ -182,32 +423,116 @@ func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	selector := selector.GlobalSelector().Build()
+	if target.Scheme == endpoint.UnixScheme || options.discovery != nil {
+		options.transport = unixTransport(options.transport)
+	}
+	if len(options.schemeTransports) > 0 {
+		options.transport = &schemeTransport{base: options.transport, schemes: options.schemeTransports}
+	}
+	if name, ok := endpoint.ParseUnixAddress(options.endpoint); ok {
+		target.Scheme = endpoint.Scheme("http", !insecure)
+		target.Authority = unixHost(name)
+	}
+	builder := options.selector
+	if builder == nil {
+		builder = selector.GlobalSelector()
+	}
+	selector := builder.Build()
 	var r *resolver
 	if options.discovery != nil {
 		if target.Scheme == "discovery" {
-			if r, err = newResolver(ctx, options.discovery, target, selector, options.block, insecure, options.subsetSize); err != nil {
+			if r, err = newResolver(ctx, options.discovery, target, selector, options.block, insecure, options.subsetSize, options.schemes...); err != nil {
 				return nil, fmt.Errorf("[http client] new resolver failed!err: %v", options.endpoint)
 			}
-		} else if _, _, err := host.ExtractHostPort(options.endpoint); err != nil {
-			return nil, fmt.Errorf("[http client] invalid endpoint format: %v", options.endpoint)
+		} else if _, ok := endpoint.ParseUnixAddress(options.endpoint); !ok {
+			if _, _, err := host.ExtractHostPort(options.endpoint); err != nil {
+				return nil, fmt.Errorf("[http client] invalid endpoint format: %v", options.endpoint)
+			}
 		}
 	}
+	var sticky *stickyTable
+	if options.sticky != nil && r != nil {
+		sticky = newStickyTable(options.sticky, options.stickyTTL)
+	}
 	return &Client{
 		opts:     options,
 		target:   target,
 		insecure: insecure,
 		r:        r,
 		cc: &http.Client{
-			Timeout:   options.timeout,
-			Transport: options.transport,
+			Timeout:       options.timeout,
+			Transport:     options.transport,
+			CheckRedirect: options.checkRedirect,
 		},
 		selector: selector,
+		stats:    stats,
+		owned:    owned,
+		sticky:   sticky,
 	}, nil
 }
 
 // Invoke makes a rpc call procedure for remote service.
 func (client *Client) Invoke(ctx context.Context, method, path string, args interface{}, reply interface{}, opts ...CallOption) error {
+	req, c, err := client.newRequest(ctx, method, path, args, opts)
+	if err != nil {
+		return err
+	}
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	ctx = client.newClientContext(ctx, req, c)
+	return client.invoke(ctx, req, args, reply, c, opts...)
+}
+
+// Stream makes a rpc call procedure for remote service like Invoke, but hands back
+// the response body to be read incrementally, e.g. the server-sent events or NDJSON.
+// The middleware runs until the response header is received, the caller must close the body.
+func (client *Client) Stream(ctx context.Context, method, path string, args interface{}, opts ...CallOption) (io.ReadCloser, error) {
+	req, c, err := client.newRequest(ctx, method, path, args, opts)
+	if err != nil {
+		return nil, err
+	}
+	cancel := context.CancelFunc(func() {})
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	}
+	ctx = client.newClientContext(ctx, req, c)
+	h := func(ctx context.Context, in interface{}) (interface{}, error) {
+		res, err := client.send(req.WithContext(ctx), c)
+		if res != nil {
+			setReplyHeader(ctx, res)
+			cs := csAttempt{res: res}
+			for _, o := range opts {
+				o.after(&c, &cs)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	var p selector.Peer
+	ctx = selector.NewPeerContext(ctx, &p)
+	if len(client.opts.middleware) > 0 {
+		h = middleware.Chain(client.opts.middleware...)(h)
+	}
+	reply, err := h(ctx, args)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res, ok := reply.(*http.Response)
+	if !ok {
+		cancel()
+		return nil, fmt.Errorf("[http client] unexpected stream reply: %T", reply)
+	}
+	return &cancelBody{ReadCloser: res.Body, cancel: cancel}, nil
+}
+
+// newRequest creates the request of the call with the encoded args.
+func (client *Client) newRequest(ctx context.Context, method, path string, args interface{}, opts []CallOption) (*http.Request, callInfo, error) {
 	var (
 		contentType string
 		body        io.Reader
@@ -215,21 +540,24 @@ func (client *Client) Invoke(ctx context.Context, method, path string, args inte
 	c := defaultCallInfo(path)
 	for _, o := range opts {
 		if err := o.before(&c); err != nil {
-			return err
+			return nil, c, err
 		}
 	}
 	if args != nil {
 		data, err := client.opts.encoder(ctx, c.contentType, args)
 		if err != nil {
-			return err
+			return nil, c, err
 		}
 		contentType = c.contentType
 		body = bytes.NewReader(data)
 	}
-	url := fmt.Sprintf("%s://%s%s", client.target.Scheme, client.target.Authority, path)
-	req, err := http.NewRequest(method, url, body)
+	rawURL := fmt.Sprintf("%s://%s%s", client.target.Scheme, client.target.Authority, path)
+	req, err := http.NewRequest(method, rawURL, body)
 	if err != nil {
-		return err
+		return nil, c, err
+	}
+	if _, ok := parseUnixHost(client.target.Authority); ok {
+		req.Host = "localhost"
 	}
 	if c.headerCarrier != nil {
 		req.Header = *c.headerCarrier
@@ -241,20 +569,43 @@ func (client *Client) Invoke(ctx context.Context, method, path string, args inte
 	if client.opts.userAgent != "" {
 		req.Header.Set("User-Agent", client.opts.userAgent)
 	}
-	ctx = transport.NewClientContext(ctx, &Transport{
+	c.apply(req)
+	return req, c, nil
+}
+
+func (client *Client) newClientContext(ctx context.Context, req *http.Request, c callInfo) context.Context {
+	return transport.NewClientContext(ctx, &Transport{
 		endpoint:     client.opts.endpoint,
 		reqHeader:    headerCarrier(req.Header),
+		replyHeader:  headerCarrier{},
 		operation:    c.operation,
 		request:      req,
 		pathTemplate: c.pathTemplate,
 	})
-	return client.invoke(ctx, req, args, reply, c, opts...)
+}
+
+// setReplyHeader copies the header of the response into the reply header of the client transport.
+func setReplyHeader(ctx context.Context, res *http.Response) {
+	if tr, ok := transport.FromClientContext(ctx); ok {
+		header := tr.ReplyHeader()
+		for k, v := range res.Header {
+			for i, vv := range v {
+				// the header of a retried call replaces the previous one.
+				if i == 0 {
+					header.Set(k, vv)
+				} else {
+					header.Add(k, vv)
+				}
+			}
+		}
+	}
 }
 
 func (client *Client) invoke(ctx context.Context, req *http.Request, args interface{}, reply interface{}, c callInfo, opts ...CallOption) error {
 	h := func(ctx context.Context, in interface{}) (interface{}, error) {
-		res, err := client.do(req.WithContext(ctx))
+		res, err := client.send(req.WithContext(ctx), c)
 		if res != nil {
+			setReplyHeader(ctx, res)
 			cs := csAttempt{res: res}
 			for _, o := range opts {
 				o.after(&c, &cs)
@@ -287,43 +638,137 @@ func (client *Client) Do(req *http.Request, opts ...CallOption) (*http.Response,
 			return nil, err
 		}
 	}
+	c.apply(req)
+	if c.timeout <= 0 {
+		return client.send(req, c)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), c.timeout)
+	res, err := client.send(req.WithContext(ctx), c)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
 
-	return client.do(req)
+// send sends the request with the retry policy of the client.
+func (client *Client) send(req *http.Request, c callInfo) (*http.Response, error) {
+	if client.opts.retry == nil {
+		return client.do(req)
+	}
+	return client.opts.retry.do(req, c.idempotent, client.do)
 }
 
 func (client *Client) do(req *http.Request) (*http.Response, error) {
-	var done func(context.Context, selector.DoneInfo)
+	var (
+		excluded []string
+		lastErr  error
+	)
+	for repicks := 0; ; repicks++ {
+		res, addr, err := client.doNode(req, excluded)
+		if addr == "" && lastErr != nil {
+			// no other node to re-pick.
+			return nil, lastErr
+		}
+		if err == nil || addr == "" || repicks >= client.opts.repicks || !isDrainingError(err) || !resetBody(req) {
+			return res, err
+		}
+		excluded = append(excluded, addr)
+		lastErr = err
+	}
+}
+
+// doNode sends the request to a node picked by the selector without the excluded
+// addresses, and returns the address of the node if it is sent through discovery.
+func (client *Client) doNode(req *http.Request, excluded []string) (*http.Response, string, error) {
+	var (
+		done    func(context.Context, selector.DoneInfo)
+		addr    string
+		session string
+	)
 	if client.r != nil {
 		var (
 			err  error
 			node selector.Node
 		)
-		if node, done, err = client.selector.Select(req.Context(), selector.WithNodeFilter(client.opts.nodeFilters...)); err != nil {
-			return nil, errors.ServiceUnavailable("NODE_NOT_FOUND", err.Error())
+		filters := client.opts.nodeFilters
+		if len(excluded) > 0 {
+			filters = append(filters[:len(filters):len(filters)], excludeNodes(excluded))
+		}
+		if client.sticky != nil {
+			if session = client.sticky.key(req, nil); session != "" {
+				if sticky := client.sticky.get(session); sticky != "" {
+					filters = append(filters[:len(filters):len(filters)], stickyNode(sticky))
+				}
+			}
+		}
+		opts := []selector.SelectOption{selector.WithNodeFilter(filters...)}
+		if tr, ok := transport.FromClientContext(req.Context()); ok {
+			opts = append(opts, selector.WithOperation(tr.Operation()), selector.WithRequestMD(tr.RequestHeader()))
+		}
+		ctx := req.Context()
+		if client.opts.hashKey != nil {
+			if _, ok := selector.FromHashKeyContext(ctx); !ok {
+				if key := client.opts.hashKey(req); key != "" {
+					ctx = selector.NewHashKeyContext(ctx, key)
+				}
+			}
+		}
+		if node, done, err = client.selector.Select(ctx, opts...); err != nil {
+			return nil, "", errors.ServiceUnavailable("NODE_NOT_FOUND", err.Error())
 		}
-		if client.insecure {
+		addr = node.Address()
+		switch {
+		case node.Scheme() != "http":
+			req.URL.Scheme = node.Scheme()
+		case client.insecure:
 			req.URL.Scheme = "http"
-		} else {
+		default:
 			req.URL.Scheme = "https"
 		}
-		req.URL.Host = node.Address()
-		req.Host = node.Address()
+		if name, ok := endpoint.ParseUnixAddress(node.Address()); ok {
+			req.URL.Host = unixHost(name)
+			req.Host = "localhost"
+		} else {
+			req.URL.Host = node.Address()
+			req.Host = node.Address()
+		}
 	}
-	resp, err := client.cc.Do(req)
+	resp, err := client.cc.Do(req.WithContext(client.stats.trace(req.Context())))
 	if err == nil {
-		err = client.opts.errorDecoder(req.Context(), resp)
+		client.drainHint(req, resp, addr)
+		if err = decompress(resp, client.opts.maxDecompressed); err == nil {
+			err = client.opts.errorDecoder(req.Context(), resp)
+		}
+		if se := new(errors.Error); err != nil && client.opts.statusMapper != nil && errors.As(err, &se) {
+			client.opts.statusMapper(resp, se)
+		}
 	}
 	if done != nil {
 		done(req.Context(), selector.DoneInfo{Err: err})
 	}
+	if client.sticky != nil && addr != "" {
+		client.sticky.done(req, resp, session, addr, err)
+	}
 	if err != nil {
-		return nil, err
+		return nil, addr, err
 	}
-	return resp, nil
+	return resp, addr, nil
+}
+
+// Stats returns the connection statistics of the client. The dials and open
+// connections are only counted when the client owns its transport, that is
+// unless WithTransport is given without any connection pool option.
+func (client *Client) Stats() ClientStats {
+	return client.stats.stats()
 }
 
 // Close tears down the Transport and all underlying connections.
 func (client *Client) Close() error {
+	if client.owned {
+		client.cc.CloseIdleConnections()
+	}
 	if client.r != nil {
 		return client.r.Close()
 	}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strconv"
 	"testing"
@@ -19,6 +20,7 @@ import (
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/transport"
 )
 
 type mockRoundTripper struct{}
@@ -362,3 +364,95 @@ func TestNewClient(t *testing.T) {
 		t.Error("err should be equal to encoder error")
 	}
 }
+
+func TestClientStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, v := range []string{"a", "b"} {
+			_, _ = w.Write([]byte(`{"name":"` + v + `"}` + "\n"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer srv.Close()
+	var operations []string
+	client, err := NewClient(context.Background(),
+		WithEndpoint(srv.Listener.Addr().String()),
+		WithMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				if tr, ok := transport.FromClientContext(ctx); ok {
+					operations = append(operations, tr.Operation())
+				}
+				return handler(ctx, req)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	body, err := client.Stream(context.Background(), http.MethodPost, "/stream", map[string]string{"name": "kratos"}, Operation("/stream.v1/Watch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(body)
+	var names []string
+	for dec.More() {
+		var v map[string]string
+		if err = dec.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, v["name"])
+	}
+	if err = body.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("unexpected names %v", names)
+	}
+	if !reflect.DeepEqual(operations, []string{"/stream.v1/Watch"}) {
+		t.Errorf("unexpected operations %v", operations)
+	}
+
+	_, err = client.Stream(context.Background(), http.MethodGet, "/error", nil, WithCallTimeout(time.Second))
+	if kratoserrors.Code(err) != http.StatusInternalServerError {
+		t.Errorf("expected 500 error got %v", err)
+	}
+}
+
+func TestClientReplyHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Warning", `299 - "deprecated"`)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	var warnings []string
+	client, err := NewClient(context.Background(),
+		WithEndpoint(srv.Listener.Addr().String()),
+		WithMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				reply, err := handler(ctx, req)
+				if tr, ok := transport.FromClientContext(ctx); ok {
+					warnings = tr.ReplyHeader().Values("Warning")
+				}
+				return reply, err
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var reply map[string]interface{}
+	if err = client.Invoke(context.Background(), http.MethodGet, "/hello", nil, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(warnings, []string{`299 - "deprecated"`}) {
+		t.Errorf("expected the warnings of the reply header, got %v", warnings)
+	}
+}
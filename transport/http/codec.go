@@ -1,14 +1,10 @@
 package http
 
 import (
-	"bytes"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 
-	"github.com/gorilla/mux"
-
 	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/internal/httputil"
@@ -44,7 +40,7 @@ type EncodeErrorFunc func(http.ResponseWriter, *http.Request, error)
 
 // DefaultRequestVars decodes the request vars to object.
 func DefaultRequestVars(r *http.Request, v interface{}) error {
-	raws := mux.Vars(r)
+	raws := pathVars(r)
 	vars := make(url.Values, len(raws))
 	for k, v := range raws {
 		vars[k] = []string{v}
@@ -54,7 +50,13 @@ func DefaultRequestVars(r *http.Request, v interface{}) error {
 
 // DefaultRequestQuery decodes the request vars to object.
 func DefaultRequestQuery(r *http.Request, v interface{}) error {
-	return binding.BindQuery(r.URL.Query(), v)
+	query := r.URL.Query()
+	if disallowUnknownFields(r) {
+		if err := checkUnknownValues(query, v); err != nil {
+			return err
+		}
+	}
+	return binding.BindQuery(query, v)
 }
 
 // DefaultRequestDecoder decodes the request body to object.
@@ -63,18 +65,20 @@ func DefaultRequestDecoder(r *http.Request, v interface{}) error {
 	if !ok {
 		return errors.BadRequest("CODEC", fmt.Sprintf("unregister Content-Type: %s", r.Header.Get("Content-Type")))
 	}
-	data, err := io.ReadAll(r.Body)
-
-	// reset body.
-	r.Body = io.NopCloser(bytes.NewBuffer(data))
-
+	// the body is read once, and reset.
+	data, err := requestBody(r)
 	if err != nil {
 		return errors.BadRequest("CODEC", err.Error())
 	}
 	if len(data) == 0 {
 		return nil
 	}
-	if err = codec.Unmarshal(data, v); err != nil {
+	if disallowUnknownFields(r) && codec.Name() == "json" {
+		err = strictUnmarshal(data, v)
+	} else {
+		err = codec.Unmarshal(data, v)
+	}
+	if err != nil {
 		return errors.BadRequest("CODEC", fmt.Sprintf("body unmarshal %s", err.Error()))
 	}
 	return nil
@@ -90,7 +94,24 @@ func DefaultResponseEncoder(w http.ResponseWriter, r *http.Request, v interface{
 		http.Redirect(w, r, url, code)
 		return nil
 	}
+	if sr, ok := v.(StatusReplier); ok {
+		return encodeStatusReply(w, sr, func(w http.ResponseWriter, reply interface{}) error {
+			return DefaultResponseEncoder(w, r, reply)
+		})
+	}
 	codec, _ := CodecForRequest(r, "Accept")
+	if wc, ok := codec.(encoding.WriterCodec); ok {
+		// the value is marshaled into a pooled buffer, and written only once it
+		// is marshaled, so that the errors are encoded with their statuses.
+		buf := getBuffer(-1)
+		defer putBuffer(buf)
+		if err := wc.MarshalTo(buf, v); err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", httputil.ContentType(codec.Name()))
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
 	data, err := codec.Marshal(v)
 	if err != nil {
 		return err
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
 )
 
@@ -88,6 +89,30 @@ func TestDefaultResponseEncoder(t *testing.T) {
 	}
 }
 
+// partialCodec writes a part of the value before it fails.
+type partialCodec struct{}
+
+func (partialCodec) Marshal(interface{}) ([]byte, error) { return nil, errors.New(500, "", "") }
+func (partialCodec) Unmarshal([]byte, interface{}) error { return nil }
+func (partialCodec) Name() string                        { return "partial" }
+func (partialCodec) MarshalTo(w io.Writer, _ interface{}) error {
+	_, _ = w.Write([]byte(`{"a":`))
+	return errors.InternalServer("CODEC", "partial")
+}
+
+func TestDefaultResponseEncoderPartial(t *testing.T) {
+	encoding.RegisterCodec(partialCodec{})
+	w := &mockResponseWriter{StatusCode: 200, header: make(http.Header)}
+	r, _ := http.NewRequest(http.MethodGet, "", nil)
+	r.Header.Set("Accept", "application/partial")
+	if err := DefaultResponseEncoder(w, r, struct{}{}); err == nil {
+		t.Fatal("expected the error of the codec")
+	}
+	if w.Data != nil || w.Header().Get("Content-Type") != "" {
+		t.Errorf("expected nothing written, got %q %v", w.Data, w.Header())
+	}
+}
+
 func TestDefaultErrorEncoder(t *testing.T) {
 	var (
 		w    = &mockResponseWriter{header: make(http.Header)}
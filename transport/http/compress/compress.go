@@ -0,0 +1,162 @@
+// Package compress provides an HTTP server filter compressing the response
+// bodies with gzip for the clients accepting it.
+package compress
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Option is compress option.
+type Option func(*options)
+
+type options struct {
+	level int
+	types []string
+}
+
+// WithLevel with the gzip compression level, default is gzip.DefaultCompression.
+func WithLevel(level int) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
+
+// WithContentTypes with the prefixes of the response content types compressed,
+// e.g. "application/json" or "text/", default compresses all content types.
+func WithContentTypes(types ...string) Option {
+	return func(o *options) {
+		o.types = types
+	}
+}
+
+// Filter returns an HTTP server filter compressing the response bodies, the
+// uncompressed sizes are recorded by khttp.AddUncompressedSize for the filters
+// wrapping it, e.g. the access log or the response size metrics.
+func Filter(opts ...Option) khttp.FilterFunc {
+	o := &options{level: gzip.DefaultCompression}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if _, err := gzip.NewWriterLevel(nil, o.level); err != nil {
+		panic(err)
+	}
+	pool := &sync.Pool{New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(nil, o.level)
+		return w
+	}}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if req.Method == http.MethodHead || !acceptGzip(req.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, req)
+				return
+			}
+			rw := &responseWriter{ResponseWriter: w, req: req, opts: o, pool: pool}
+			defer rw.close()
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// acceptGzip reports whether the Accept-Encoding header accepts gzip.
+func acceptGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if coding = strings.TrimSpace(coding); coding != "gzip" && coding != "*" {
+			continue
+		}
+		params = strings.TrimSpace(params)
+		if !strings.HasPrefix(params, "q=") {
+			return true
+		}
+		q, err := strconv.ParseFloat(params[2:], 64)
+		return err == nil && q > 0
+	}
+	return false
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	req   *http.Request
+	opts  *options
+	pool  *sync.Pool
+	gz    *gzip.Writer
+	wrote bool
+}
+
+// start decides whether the response is compressed before its header is written.
+func (w *responseWriter) start(code int) {
+	if w.wrote {
+		return
+	}
+	w.wrote = true
+	h := w.Header()
+	if code == http.StatusNoContent || code == http.StatusNotModified || code < http.StatusOK ||
+		h.Get("Content-Encoding") != "" || !w.compressible(h.Get("Content-Type")) {
+		return
+	}
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	w.gz = w.pool.Get().(*gzip.Writer)
+	w.gz.Reset(w.ResponseWriter)
+}
+
+func (w *responseWriter) compressible(contentType string) bool {
+	if len(w.opts.types) == 0 {
+		return true
+	}
+	for _, t := range w.opts.types {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.start(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wrote {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	khttp.AddUncompressedSize(w.req.Context(), int64(len(b)))
+	return w.gz.Write(b)
+}
+
+func (w *responseWriter) Flush() {
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) close() {
+	if w.gz == nil {
+		return
+	}
+	_ = w.gz.Close()
+	w.gz.Reset(nil)
+	w.pool.Put(w.gz)
+	w.gz = nil
+}
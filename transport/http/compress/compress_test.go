@@ -0,0 +1,71 @@
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var body = strings.Repeat("kratos", 100)
+
+func serve(h http.Handler, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func TestFilter(t *testing.T) {
+	h := Filter()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "600")
+		_, _ = io.WriteString(w, body)
+	}))
+	res := serve(h, "br, gzip;q=0.8")
+	if res.Header().Get("Content-Encoding") != "gzip" || res.Header().Get("Content-Length") != "" {
+		t.Fatalf("expected gzip response got %v", res.Header())
+	}
+	r, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != body {
+		t.Errorf("expected %q got %q", body, b)
+	}
+
+	for _, accept := range []string{"", "br", "gzip;q=0"} {
+		res = serve(h, accept)
+		if res.Header().Get("Content-Encoding") != "" || res.Body.String() != body {
+			t.Errorf("%q: expected identity response got %v", accept, res.Header())
+		}
+		if res.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("%q: expected Vary header got %v", accept, res.Header())
+		}
+	}
+}
+
+func TestFilterContentTypes(t *testing.T) {
+	h := Filter(WithContentTypes("text/"))(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", req.URL.Query().Get("type"))
+		_, _ = io.WriteString(w, body)
+	}))
+	for typ, encoding := range map[string]string{"text/plain": "gzip", "image/png": ""} {
+		req := httptest.NewRequest(http.MethodGet, "/?type="+typ, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		if got := res.Header().Get("Content-Encoding"); got != encoding {
+			t.Errorf("%s: expected encoding %q got %q", typ, encoding, got)
+		}
+	}
+}
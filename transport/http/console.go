@@ -0,0 +1,313 @@
+package http
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+const (
+	// defaultSwaggerUIAssets is the default base URL of the Swagger UI bundle.
+	defaultSwaggerUIAssets = "https://unpkg.com/swagger-ui-dist@5"
+	// defaultRedocAssets is the default base URL of the Redoc bundle.
+	defaultRedocAssets = "https://unpkg.com/redoc@2/bundles"
+)
+
+var consolePages = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.Assets}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.Assets}}/swagger-ui-bundle.js"></script>
+<script>window.ui = SwaggerUIBundle({url: {{.URL}}, dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`))
+
+func init() {
+	template.Must(consolePages.New("redoc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<redoc spec-url="{{.URL}}"></redoc>
+<script src="{{.Assets}}/redoc.standalone.js"></script>
+</body>
+</html>
+`))
+}
+
+// ConsoleOption is API console option.
+type ConsoleOption func(*console)
+
+type console struct {
+	path    string
+	title   string
+	doc     []byte
+	docURL  string
+	redoc   bool
+	assets  string
+	assetFS http.FileSystem
+	filters []FilterFunc
+}
+
+// ConsoleTitle with the title of the console and the generated document, default is "API".
+func ConsoleTitle(title string) ConsoleOption {
+	return func(c *console) {
+		c.title = title
+	}
+}
+
+// ConsoleDocument with the OpenAPI document in JSON or YAML, e.g. generated by
+// protoc-gen-openapi, default is generated from the routes of the server.
+func ConsoleDocument(doc []byte) ConsoleOption {
+	return func(c *console) {
+		c.doc = doc
+	}
+}
+
+// ConsoleDocumentURL with the URL of the OpenAPI document served elsewhere.
+func ConsoleDocumentURL(url string) ConsoleOption {
+	return func(c *console) {
+		c.docURL = url
+	}
+}
+
+// ConsoleRedoc with the Redoc bundle in place of the Swagger UI one.
+func ConsoleRedoc() ConsoleOption {
+	return func(c *console) {
+		c.redoc = true
+	}
+}
+
+// ConsoleAssets with the base URL of the UI bundle, default is of unpkg.com.
+func ConsoleAssets(url string) ConsoleOption {
+	return func(c *console) {
+		c.assets = url
+	}
+}
+
+// ConsoleAssetsFS with the files of the UI bundle served by the console, e.g.
+// of an embedded swagger-ui-dist, so that no external host is required.
+func ConsoleAssetsFS(fsys http.FileSystem) ConsoleOption {
+	return func(c *console) {
+		c.assetFS = fsys
+	}
+}
+
+// ConsoleBasicAuth guards the console with the basic auth credentials.
+func ConsoleBasicAuth(username, password string) ConsoleOption {
+	return ConsoleFilter(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			user, pass, ok := req.BasicAuth()
+			if !ok ||
+				subtle.ConstantTimeCompare([]byte(user), []byte(username)) != 1 ||
+				subtle.ConstantTimeCompare([]byte(pass), []byte(password)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="console"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+}
+
+// ConsoleFilter with the filters guarding the console, e.g. the admin.Auth filter.
+func ConsoleFilter(filters ...FilterFunc) ConsoleOption {
+	return func(c *console) {
+		c.filters = append(c.filters, filters...)
+	}
+}
+
+// EnableAPIConsole serves the Swagger UI, or Redoc, of the OpenAPI document of
+// the server at the path, e.g. "/q/console", the document is at its
+// "openapi.json", or "openapi.yaml" of a YAML ConsoleDocument.
+func EnableAPIConsole(path string, opts ...ConsoleOption) ServerOption {
+	return func(s *Server) {
+		c := &console{path: strings.TrimSuffix(path, "/"), title: "API"}
+		for _, o := range opts {
+			o(c)
+		}
+		s.console = c
+	}
+}
+
+// mountConsole registers the handlers of the API console.
+func (s *Server) mountConsole() {
+	c := s.console
+	docName := "openapi.json"
+	if len(c.doc) > 0 && !isJSONDocument(c.doc) {
+		docName = "openapi.yaml"
+	}
+	docURL := c.docURL
+	if docURL == "" {
+		docURL = docName
+	}
+	assets := c.assets
+	if c.assetFS != nil {
+		assets = "assets"
+	} else if assets == "" {
+		assets = defaultSwaggerUIAssets
+		if c.redoc {
+			assets = defaultRedocAssets
+		}
+	}
+	page := "swagger"
+	if c.redoc {
+		page = "redoc"
+	}
+	full := s.prefix + c.path
+	mux := http.NewServeMux()
+	mux.HandleFunc(full, func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, full+"/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc(full+"/", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != full+"/" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = consolePages.ExecuteTemplate(w, page, struct {
+			Title  string
+			URL    string
+			Assets string
+		}{c.title, docURL, strings.TrimSuffix(assets, "/")})
+	})
+	if c.docURL == "" {
+		mux.HandleFunc(full+"/"+docName, func(w http.ResponseWriter, req *http.Request) {
+			if len(c.doc) > 0 {
+				if docName == "openapi.yaml" {
+					w.Header().Set("Content-Type", "application/yaml")
+				} else {
+					w.Header().Set("Content-Type", "application/json")
+				}
+				_, _ = w.Write(c.doc)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(s.openAPIDocument(c))
+		})
+	}
+	if c.assetFS != nil {
+		mux.Handle(full+"/assets/", http.StripPrefix(full+"/assets", http.FileServer(c.assetFS)))
+	}
+	s.HandlePrefix(c.path, FilterChain(c.filters...)(mux))
+}
+
+func isJSONDocument(doc []byte) bool {
+	doc = bytes.TrimSpace(doc)
+	return len(doc) > 0 && doc[0] == '{'
+}
+
+// openAPIDocument generates the OpenAPI 3 document of the routes of the
+// server, of their paths, methods and typed path parameters.
+func (s *Server) openAPIDocument(c *console) map[string]interface{} {
+	paths := make(map[string]map[string]interface{})
+	_ = s.WalkRoute(func(r RouteInfo) error {
+		if strings.HasPrefix(r.Path, s.prefix+c.path+"/") {
+			return nil
+		}
+		path, names := openAPIPath(r.Path)
+		types := make(map[string]string, len(r.Params))
+		for _, p := range r.Params {
+			types[p.Name] = p.Type
+		}
+		op := map[string]interface{}{
+			"responses": map[string]interface{}{
+				"default": map[string]interface{}{"description": "the response of " + r.Method + " " + path},
+			},
+		}
+		if len(names) > 0 {
+			params := make([]interface{}, 0, len(names))
+			for _, name := range names {
+				params = append(params, map[string]interface{}{
+					"name":     name,
+					"in":       "path",
+					"required": true,
+					"schema":   openAPISchema(types[name]),
+				})
+			}
+			op["parameters"] = params
+		}
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			op["requestBody"] = map[string]interface{}{
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": map[string]interface{}{"type": "object"}},
+				},
+			}
+		}
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path][strings.ToLower(r.Method)] = op
+		return nil
+	})
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": c.title, "version": "1.0.0"},
+		"paths":   paths,
+	}
+}
+
+// openAPIPath returns the OpenAPI path of the route template and the names of
+// its parameters, the patterns are removed, e.g. "/users/{id:[0-9]+}" is
+// "/users/{id}".
+func openAPIPath(template string) (string, []string) {
+	var (
+		b     strings.Builder
+		names []string
+	)
+	for i := 0; i < len(template); {
+		if template[i] != '{' {
+			b.WriteByte(template[i])
+			i++
+			continue
+		}
+		depth, end := 0, len(template)
+		for j := i; j < len(template); j++ {
+			if template[j] == '{' {
+				depth++
+			} else if template[j] == '}' {
+				if depth--; depth == 0 {
+					end = j
+					break
+				}
+			}
+		}
+		name := template[i+1 : end]
+		if k := strings.IndexByte(name, ':'); k >= 0 {
+			name = name[:k]
+		}
+		names = append(names, name)
+		b.WriteString("{" + name + "}")
+		i = end + 1
+	}
+	return b.String(), names
+}
+
+func openAPISchema(paramType string) map[string]interface{} {
+	switch paramType {
+	case ParamInt:
+		return map[string]interface{}{"type": "integer"}
+	case ParamUint:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case ParamFloat:
+		return map[string]interface{}{"type": "number"}
+	case ParamBool:
+		return map[string]interface{}{"type": "boolean"}
+	case ParamUUID:
+		return map[string]interface{}{"type": "string", "format": "uuid"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
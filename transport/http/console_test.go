@@ -0,0 +1,88 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAPIConsole(t *testing.T) {
+	srv := NewServer(EnableAPIConsole("/q/console", ConsoleTitle("Users"), ConsoleBasicAuth("dev", "pass")))
+	r := srv.Route("/")
+	r.GET("/users/{id:int}/items/{name:[a-z]+}", func(ctx Context) error { return nil })
+	r.POST("/users", func(ctx Context) error { return nil })
+	serve := func(target string, auth bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if auth {
+			req.SetBasicAuth("dev", "pass")
+		}
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		return res
+	}
+	if res := serve("/q/console/", false); res.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 got %d", res.Code)
+	}
+	res := serve("/q/console/", true)
+	if res.Code != http.StatusOK || !strings.Contains(res.Body.String(), `SwaggerUIBundle({url: "openapi.json"`) ||
+		!strings.Contains(res.Body.String(), defaultSwaggerUIAssets+"/swagger-ui-bundle.js") {
+		t.Errorf("unexpected page %d %s", res.Code, res.Body.String())
+	}
+	if res = serve("/q/console", true); res.Code != http.StatusMovedPermanently || res.Header().Get("Location") != "/q/console/" {
+		t.Errorf("expected the redirect, got %d %s", res.Code, res.Header().Get("Location"))
+	}
+
+	res = serve("/q/console/openapi.json", true)
+	var doc struct {
+		Info  map[string]string `json:"info"`
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name   string                 `json:"name"`
+				Schema map[string]interface{} `json:"schema"`
+			} `json:"parameters"`
+			RequestBody interface{} `json:"requestBody"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Info["title"] != "Users" || len(doc.Paths) != 2 {
+		t.Fatalf("unexpected document %s", res.Body.String())
+	}
+	get := doc.Paths["/users/{id}/items/{name}"]["get"]
+	if len(get.Parameters) != 2 || get.Parameters[0].Schema["type"] != "integer" || get.Parameters[1].Schema["type"] != "string" {
+		t.Errorf("unexpected parameters %+v", get.Parameters)
+	}
+	if doc.Paths["/users"]["post"].RequestBody == nil {
+		t.Error("expected the request body of POST")
+	}
+}
+
+func TestAPIConsoleDocument(t *testing.T) {
+	assets := fstest.MapFS{"redoc.standalone.js": {Data: []byte("redoc")}}
+	srv := NewServer(
+		PathPrefix("/api"),
+		EnableAPIConsole("/docs/", ConsoleRedoc(), ConsoleDocument([]byte("openapi: 3.0.3\n")), ConsoleAssetsFS(http.FS(assets))),
+	)
+	serve := func(target string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, target, nil))
+		return res
+	}
+	res := serve("/api/docs/")
+	if !strings.Contains(res.Body.String(), `<redoc spec-url="openapi.yaml">`) || !strings.Contains(res.Body.String(), `src="assets/redoc.standalone.js"`) {
+		t.Errorf("unexpected page %s", res.Body.String())
+	}
+	if res = serve("/api/docs/openapi.yaml"); res.Body.String() != "openapi: 3.0.3\n" || res.Header().Get("Content-Type") != "application/yaml" {
+		t.Errorf("unexpected document %s %s", res.Header().Get("Content-Type"), res.Body.String())
+	}
+	if res = serve("/api/docs/assets/redoc.standalone.js"); res.Body.String() != "redoc" {
+		t.Errorf("unexpected asset %d %s", res.Code, res.Body.String())
+	}
+	if res = serve("/api/docs/other"); res.Code != http.StatusNotFound {
+		t.Errorf("expected 404 got %d", res.Code)
+	}
+}
@@ -9,9 +9,8 @@ import (
 	"net/url"
 	"time"
 
-	"github.com/gorilla/mux"
-
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/matcher"
 	"github.com/go-kratos/kratos/v2/transport"
 	"github.com/go-kratos/kratos/v2/transport/http/binding"
 )
@@ -27,6 +26,7 @@ type Context interface {
 	Header() http.Header
 	Request() *http.Request
 	Response() http.ResponseWriter
+	ResponseHeader() ResponseHeader
 	Middleware(middleware.Handler) middleware.Handler
 	Bind(interface{}) error
 	BindVars(interface{}) error
@@ -39,6 +39,11 @@ type Context interface {
 	String(int, string) error
 	Blob(int, string, []byte) error
 	Stream(int, string, io.Reader) error
+	Poll(time.Duration, PollFunc, ...PollOption) error
+	File(string) error
+	Attachment(string, io.Reader, int64) error
+	GetSession(string) (string, error)
+	SetSession(string, string) error
 	Reset(http.ResponseWriter, *http.Request)
 }
 
@@ -63,6 +68,8 @@ type wrapper struct {
 	req    *http.Request
 	res    http.ResponseWriter
 	w      responseWriter
+	// session is the session of the request loaded by GetSession or SetSession.
+	session *session
 }
 
 func (c *wrapper) Header() http.Header {
@@ -70,7 +77,7 @@ func (c *wrapper) Header() http.Header {
 }
 
 func (c *wrapper) Vars() url.Values {
-	raws := mux.Vars(c.req)
+	raws := pathVars(c.req)
 	vars := make(url.Values, len(raws))
 	for k, v := range raws {
 		vars[k] = []string{v}
@@ -90,26 +97,82 @@ func (c *wrapper) Query() url.Values {
 }
 func (c *wrapper) Request() *http.Request        { return c.req }
 func (c *wrapper) Response() http.ResponseWriter { return c.res }
+func (c *wrapper) ResponseHeader() ResponseHeader {
+	return NewResponseHeader(c.res.Header())
+}
 func (c *wrapper) Middleware(h middleware.Handler) middleware.Handler {
+	operation := c.req.URL.Path
 	if tr, ok := transport.FromServerContext(c.req.Context()); ok {
-		return middleware.Chain(c.router.srv.middleware.Match(tr.Operation())...)(h)
+		operation = tr.Operation()
+	}
+	ms := matcher.MatchContext(c.req.Context(), c.router.middleware(), operation)
+	if o := c.router.srv.observer; o != nil {
+		ms = middleware.Instrument(o, ms...)
+	}
+	tl := timelineFromRequest(c.req)
+	if tl == nil {
+		return middleware.Chain(ms...)(h)
+	}
+	var handled time.Duration
+	next := middleware.Chain(ms...)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		start := time.Now()
+		defer func() {
+			handled = time.Since(start)
+			tl.Add(PhaseHandler, handled)
+		}()
+		return h(ctx, req)
+	})
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		start := time.Now()
+		defer func() { tl.Add(PhaseMiddleware, time.Since(start)-handled) }()
+		return next(ctx, req)
+	}
+}
+
+func (c *wrapper) Bind(v interface{}) error {
+	defer timelineFromRequest(c.req).Begin(PhaseDecode)()
+	if err := checkContentType(c.req, c.router.srv.strictContentType); err != nil {
+		return err
+	}
+	if err := transformRequest(c.req); err != nil {
+		return err
 	}
-	return middleware.Chain(c.router.srv.middleware.Match(c.req.URL.Path)...)(h)
+	return c.router.srv.decBody(c.req, v)
+}
+
+func (c *wrapper) BindVars(v interface{}) error {
+	defer timelineFromRequest(c.req).Begin(PhaseDecode)()
+	return c.router.srv.decVars(c.req, v)
+}
+
+func (c *wrapper) BindQuery(v interface{}) error {
+	defer timelineFromRequest(c.req).Begin(PhaseDecode)()
+	return c.router.srv.decQuery(c.req, v)
 }
-func (c *wrapper) Bind(v interface{}) error      { return c.router.srv.decBody(c.req, v) }
-func (c *wrapper) BindVars(v interface{}) error  { return c.router.srv.decVars(c.req, v) }
-func (c *wrapper) BindQuery(v interface{}) error { return c.router.srv.decQuery(c.req, v) }
-func (c *wrapper) BindForm(v interface{}) error  { return binding.BindForm(c.req, v) }
+
+func (c *wrapper) BindForm(v interface{}) error {
+	defer timelineFromRequest(c.req).Begin(PhaseDecode)()
+	return binding.BindForm(c.req, v)
+}
+
 func (c *wrapper) Returns(v interface{}, err error) error {
 	if err != nil {
 		return err
 	}
-	return c.router.srv.enc(&c.w, c.req, v)
+	defer timelineFromRequest(c.req).Begin(PhaseEncode)()
+	return c.encode(v)
 }
 
 func (c *wrapper) Result(code int, v interface{}) error {
 	c.w.WriteHeader(code)
-	return c.router.srv.enc(&c.w, c.req, v)
+	defer timelineFromRequest(c.req).Begin(PhaseEncode)()
+	return c.encode(v)
+}
+
+func (c *wrapper) encode(v interface{}) error {
+	return transformResponse(&c.w, c.req, func(w http.ResponseWriter) error {
+		return c.router.srv.enc(w, c.req, v)
+	})
 }
 
 func (c *wrapper) JSON(code int, v interface{}) error {
@@ -155,6 +218,7 @@ func (c *wrapper) Reset(res http.ResponseWriter, req *http.Request) {
 	c.w.reset(res)
 	c.res = res
 	c.req = req
+	c.session = nil
 }
 
 func (c *wrapper) Deadline() (time.Time, bool) {
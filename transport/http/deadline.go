@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+	"time"
+)
+
+// RouteWriteTimeout returns a FilterFunc that sets the write deadline of the
+// responses of a route, instead of the WriteTimeout of the server, e.g. of the
+// streaming routes, 0 means no deadline. The request timeout of the route is
+// still of the Timeout of the server. It requires the response writer to
+// support SetWriteDeadline, e.g. net/http since Go 1.20, otherwise it is ignored.
+func RouteWriteTimeout(timeout time.Duration) FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_ = ExtendWriteDeadline(w, timeout)
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// ExtendWriteDeadline extends the write deadline of the response by the
+// timeout from now, e.g. of a streaming handler before every write of it,
+// 0 means no deadline.
+func ExtendWriteDeadline(w http.ResponseWriter, timeout time.Duration) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	return setWriteDeadline(w, deadline)
+}
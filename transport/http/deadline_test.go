@@ -0,0 +1,56 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServerTimeouts(t *testing.T) {
+	srv := NewServer(ReadHeaderTimeout(time.Second), ReadTimeout(2*time.Second), WriteTimeout(3*time.Second), IdleTimeout(4*time.Second))
+	if srv.ReadHeaderTimeout != time.Second || srv.ReadTimeout != 2*time.Second ||
+		srv.WriteTimeout != 3*time.Second || srv.IdleTimeout != 4*time.Second {
+		t.Errorf("unexpected timeouts %v %v %v %v", srv.ReadHeaderTimeout, srv.ReadTimeout, srv.WriteTimeout, srv.IdleTimeout)
+	}
+}
+
+func TestRouteWriteTimeout(t *testing.T) {
+	srv := NewServer(WriteTimeout(50 * time.Millisecond))
+	slow := func(ctx Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return ctx.String(http.StatusOK, "ok")
+	}
+	srv.Route("/").GET("/slow", slow)
+	srv.Route("/").GET("/stream", slow, RouteWriteTimeout(0))
+	srv.Route("/").GET("/extend", func(ctx Context) error {
+		time.Sleep(30 * time.Millisecond)
+		if err := ExtendWriteDeadline(ctx.Response(), 200*time.Millisecond); err != nil {
+			t.Error(err)
+		}
+		return slow(ctx)
+	})
+	ts := httptest.NewUnstartedServer(srv.Handler)
+	ts.Config.WriteTimeout = srv.WriteTimeout
+	ts.Start()
+	defer ts.Close()
+
+	get := func(path string) (string, error) {
+		res, err := http.Get(ts.URL + path)
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+		data, err := io.ReadAll(res.Body)
+		return string(data), err
+	}
+	if body, err := get("/slow"); err == nil {
+		t.Errorf("expected the write timeout, got %q", body)
+	}
+	for _, path := range []string{"/stream", "/extend"} {
+		if body, err := get(path); err != nil || body != "ok" {
+			t.Errorf("%s: expected the write deadline extended, got %q %v", path, body, err)
+		}
+	}
+}
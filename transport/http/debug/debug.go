@@ -0,0 +1,197 @@
+// Package debug mounts the debug endpoints /debug/pprof/*, /debug/vars,
+// /debug/log/levels, /debug/channelz/*, /debug/config, /debug/routes,
+// /debug/drain, /healthz, /readyz and /metrics on an HTTP server, either the
+// main server or a separate admin server, which is registered with the app
+// like the others, so that the operational traffic is isolated:
+//
+//	admin := debug.NewServer(debug.WithHealth(h), debug.WithRoutes(httpSrv, grpcSrv), debug.WithDrain())
+//	app := kratos.New(kratos.Server(httpSrv, grpcSrv, admin))
+//
+// Note that net/http/pprof and expvar register their handlers on http.DefaultServeMux,
+// which serves the requests not matching any route of the server.
+package debug
+
+import (
+	"expvar"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2"
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/health"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	kgrpc "github.com/go-kratos/kratos/v2/transport/grpc"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/go-kratos/kratos/v2/transport/http/pprof"
+	"github.com/go-kratos/kratos/v2/transport/routes"
+)
+
+// DefaultAddress is the default address of the admin server.
+const DefaultAddress = "127.0.0.1:9090"
+
+// Option is debug endpoints option.
+type Option func(*options)
+
+type options struct {
+	metrics    http.Handler
+	levels     *log.Levels
+	pprof      bool
+	channelz   bool
+	vars       bool
+	drain      bool
+	health     *health.Server
+	config     config.Config
+	routes     []transport.Server
+	serverOpts []khttp.ServerOption
+}
+
+// WithMetrics with the handler of /metrics, e.g. promhttp.Handler(),
+// the endpoint is not mounted without it.
+func WithMetrics(h http.Handler) Option {
+	return func(o *options) {
+		o.metrics = h
+	}
+}
+
+// WithLogLevels with the levels of /debug/log/levels changed at runtime,
+// the endpoint is not mounted without it.
+func WithLogLevels(l *log.Levels) Option {
+	return func(o *options) {
+		o.levels = l
+	}
+}
+
+// WithChannelz with the channelz stats of the gRPC servers and channels of
+// /debug/channelz/*, see grpc.NewChannelzHandler, the endpoint is not mounted
+// without it.
+func WithChannelz() Option {
+	return func(o *options) {
+		o.channelz = true
+	}
+}
+
+// WithHealth with the liveness and the readiness of /healthz and /readyz,
+// the endpoints are not mounted without it.
+func WithHealth(h *health.Server) Option {
+	return func(o *options) {
+		o.health = h
+	}
+}
+
+// WithConfig with the config dumped by /debug/config with the secret keys
+// masked, see config.NewHandler, the endpoint is not mounted without it.
+func WithConfig(c config.Config) Option {
+	return func(o *options) {
+		o.config = c
+	}
+}
+
+// WithRoutes with the servers whose routes are listed by /debug/routes, see
+// routes.Handler, the endpoint is not mounted without them.
+func WithRoutes(servers ...transport.Server) Option {
+	return func(o *options) {
+		o.routes = append(o.routes, servers...)
+	}
+}
+
+// WithDrain with POST /debug/drain draining the app of the server, see
+// App.DrainHandler, e.g. for the pre-stop hook of kubernetes, the endpoint
+// is not mounted without it.
+func WithDrain() Option {
+	return func(o *options) {
+		o.drain = true
+	}
+}
+
+// WithoutPprof disables the /debug/pprof/* endpoints.
+func WithoutPprof() Option {
+	return func(o *options) {
+		o.pprof = false
+	}
+}
+
+// WithoutVars disables the /debug/vars endpoint.
+func WithoutVars() Option {
+	return func(o *options) {
+		o.vars = false
+	}
+}
+
+// WithServerOptions with the options of the admin server created by NewServer,
+// e.g. khttp.Address or khttp.AdminAuth.
+func WithServerOptions(opts ...khttp.ServerOption) Option {
+	return func(o *options) {
+		o.serverOpts = append(o.serverOpts, opts...)
+	}
+}
+
+// Register mounts the debug endpoints on the server, which are guarded by
+// the AdminAuth filter of the server, default only allows the loopback peers.
+func Register(srv *khttp.Server, opts ...Option) {
+	o := options{pprof: true, vars: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.pprof {
+		srv.HandleAdmin("/debug/pprof", pprof.NewHandler())
+	}
+	if o.vars {
+		srv.HandleAdmin("/debug/vars", expvar.Handler())
+	}
+	if o.levels != nil {
+		srv.HandleAdmin("/debug/log/levels", log.NewLevelsHandler(o.levels))
+	}
+	if o.channelz {
+		srv.HandleAdmin("/debug/channelz/", kgrpc.NewChannelzHandler())
+	}
+	if o.health != nil {
+		srv.HandleAdmin("/healthz", o.health.LivenessHandler())
+		srv.HandleAdmin("/readyz", o.health.ReadinessHandler())
+	}
+	if o.config != nil {
+		srv.HandleAdmin("/debug/config", config.NewHandler(o.config))
+	}
+	if len(o.routes) > 0 {
+		srv.HandleAdmin("/debug/routes", routes.Handler(o.routes))
+	}
+	if o.drain {
+		srv.HandleAdmin("/debug/drain", drainHandler())
+	}
+	if o.metrics != nil {
+		srv.HandleAdmin("/metrics", o.metrics)
+	}
+}
+
+// drainHandler drains the app which started the server, found in the context of the requests.
+func drainHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		app, ok := kratos.FromContext(req.Context())
+		if !ok {
+			http.Error(w, "the server is not started by an app", http.StatusNotImplemented)
+			return
+		}
+		drainer, ok := app.(interface{ DrainHandler() http.Handler })
+		if !ok {
+			http.Error(w, "the app does not drain", http.StatusNotImplemented)
+			return
+		}
+		drainer.DrainHandler().ServeHTTP(w, req)
+	})
+}
+
+// NewServer creates an admin server listening on DefaultAddress by default,
+// separate from the public listener, with the debug endpoints mounted.
+func NewServer(opts ...Option) *khttp.Server {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	srv := khttp.NewServer(append([]khttp.ServerOption{khttp.Address(DefaultAddress)}, o.serverOpts...)...)
+	Register(srv, opts...)
+	return srv
+}
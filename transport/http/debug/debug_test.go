@@ -0,0 +1,119 @@
+package debug
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2"
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/go-kratos/kratos/v2/health"
+	"github.com/go-kratos/kratos/v2/log"
+	kgrpc "github.com/go-kratos/kratos/v2/transport/grpc"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func get(srv http.Handler, path, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	return res
+}
+
+func TestRegister(t *testing.T) {
+	srv := khttp.NewServer()
+	Register(srv, WithMetrics(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "requests_total 1")
+	})), WithLogLevels(log.NewLevels(log.LevelInfo)), WithChannelz())
+	_ = kgrpc.NewServer()
+	for path, want := range map[string]string{
+		"/debug/pprof/":           "goroutine",
+		"/debug/vars":             "memstats",
+		"/debug/log/levels":       `"default":"info"`,
+		"/debug/channelz/servers": `"server"`,
+		"/metrics":                "requests_total 1",
+	} {
+		res := get(srv, path, "127.0.0.1:1234")
+		if res.Code != http.StatusOK || !strings.Contains(res.Body.String(), want) {
+			t.Errorf("%s: expected %q got %d %q", path, want, res.Code, res.Body.String())
+		}
+		if res = get(srv, path, "10.0.0.1:1234"); res.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 got %d", path, res.Code)
+		}
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	srv := NewServer(WithServerOptions(khttp.Address("127.0.0.1:0")))
+	if res := get(srv, "/debug/pprof/", "127.0.0.1:1234"); res.Code != http.StatusOK {
+		t.Errorf("expected 200 got %d", res.Code)
+	}
+	if res := get(srv, "/debug/pprof/", "10.0.0.1:1234"); res.Code != http.StatusForbidden {
+		t.Errorf("expected 403 got %d", res.Code)
+	}
+	if res := get(srv, "/metrics", "127.0.0.1:1234"); res.Code == http.StatusOK {
+		t.Errorf("expected /metrics not mounted got %d", res.Code)
+	}
+}
+
+func TestOperational(t *testing.T) {
+	h := health.NewServer()
+	h.AddReadiness("db", health.CheckerFunc(func(context.Context) error { return errors.New("down") }))
+	name := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(name, []byte(`{"server":{"addr":":8000"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := config.New(config.WithSource(file.NewSource(name)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	public := khttp.NewServer()
+	public.Route("/").GET("/hello", func(khttp.Context) error { return nil })
+
+	srv := NewServer(WithHealth(h), WithConfig(c), WithRoutes(public), WithDrain())
+	for path, want := range map[string]struct {
+		code int
+		body string
+	}{
+		"/healthz":      {http.StatusOK, `"status":"up"`},
+		"/readyz":       {http.StatusServiceUnavailable, `"status":"down"`},
+		"/debug/config": {http.StatusOK, `":8000"`},
+		"/debug/routes": {http.StatusOK, `"/hello"`},
+		"/debug/drain":  {http.StatusMethodNotAllowed, ""},
+	} {
+		res := get(srv, path, "127.0.0.1:1234")
+		if res.Code != want.code || !strings.Contains(res.Body.String(), want.body) {
+			t.Errorf("%s: expected %d %q got %d %q", path, want.code, want.body, res.Code, res.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/debug/drain", nil)
+	req.RemoteAddr = "127.0.0.1:1234"
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if res.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 without the app got %d", res.Code)
+	}
+
+	drained := khttp.NewServer()
+	drained.HandleFunc("/hello", func(http.ResponseWriter, *http.Request) {})
+	app := kratos.New(kratos.Server(drained))
+	req = req.WithContext(kratos.NewContext(context.Background(), app))
+	res = httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if res.Code != http.StatusOK {
+		t.Errorf("expected the app drained got %d %q", res.Code, res.Body.String())
+	}
+	if res = get(drained, "/hello", "127.0.0.1:1234"); res.Header().Get(khttp.DrainHeader) != "true" {
+		t.Errorf("expected the server of the app draining, got %v", res.Header())
+	}
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// defaultMaxDecompressedSize is the default max size of the decompressed bodies.
+const defaultMaxDecompressedSize = 64 << 20
+
+// ErrResponseTooLarge is the error of the decompressed response bodies
+// exceeding the max size of WithMaxDecompressedSize.
+var ErrResponseTooLarge = errors.New(http.StatusBadGateway, "RESPONSE_TOO_LARGE", "response body is too large")
+
+// Decompressor returns the reader decompressing the body of a content encoding.
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+var (
+	decompressorsMu sync.RWMutex
+	decompressors   = map[string]Decompressor{
+		"gzip": func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+		"deflate": func(r io.Reader) (io.ReadCloser, error) {
+			return flate.NewReader(r), nil
+		},
+	}
+)
+
+// RegisterDecompressor registers the decompressor of the content encoding of
+// the client responses, gzip and deflate are registered by default, e.g. of
+// br by github.com/andybalholm/brotli:
+//
+//	http.RegisterDecompressor("br", func(r io.Reader) (io.ReadCloser, error) {
+//		return io.NopCloser(brotli.NewReader(r)), nil
+//	})
+func RegisterDecompressor(encoding string, d Decompressor) {
+	decompressorsMu.Lock()
+	decompressors[strings.ToLower(encoding)] = d
+	decompressorsMu.Unlock()
+}
+
+func getDecompressor(encoding string) (Decompressor, bool) {
+	decompressorsMu.RLock()
+	defer decompressorsMu.RUnlock()
+	d, ok := decompressors[encoding]
+	return d, ok
+}
+
+// acceptEncodings returns the registered content encodings, sorted.
+func acceptEncodings() []string {
+	decompressorsMu.RLock()
+	defer decompressorsMu.RUnlock()
+	encodings := make([]string, 0, len(decompressors))
+	for encoding := range decompressors {
+		encodings = append(encodings, encoding)
+	}
+	sort.Strings(encodings)
+	return encodings
+}
+
+// WithMaxDecompressedSize with the max size of the decompressed response
+// bodies, default is 64MB, reading more fails with ErrResponseTooLarge. The
+// bodies are not decompressed if it is negative, e.g. of the reverse proxies.
+func WithMaxDecompressedSize(n int64) ClientOption {
+	return func(o *clientOptions) {
+		o.maxDecompressed = n
+	}
+}
+
+// WithAcceptEncoding sets the Accept-Encoding header of the call, default is
+// the registered content encodings, e.g. "br, gzip, deflate". The responses of
+// them are decompressed before the decoders, while the ones of gzip are
+// decompressed by net/http without it.
+func WithAcceptEncoding(encodings ...string) CallOption {
+	return AcceptEncodingCallOption{Encodings: encodings}
+}
+
+// AcceptEncodingCallOption is set Accept-Encoding header for client call
+type AcceptEncodingCallOption struct {
+	EmptyCallOption
+	Encodings []string
+}
+
+func (o AcceptEncodingCallOption) before(c *callInfo) error {
+	encodings := o.Encodings
+	if len(encodings) == 0 {
+		encodings = acceptEncodings()
+	}
+	if c.header == nil {
+		c.header = make(http.Header)
+	}
+	c.header.Set("Accept-Encoding", strings.Join(encodings, ", "))
+	return nil
+}
+
+// decompress replaces the body of the response of the content encodings by the
+// decompressed one, whose size is limited by max, the content encoding and the
+// length headers are removed.
+func decompress(res *http.Response, max int64) error {
+	header := res.Header.Get("Content-Encoding")
+	if header == "" || max < 0 {
+		return nil
+	}
+	var encodings []string
+	for _, encoding := range strings.Split(header, ",") {
+		if encoding = strings.ToLower(strings.TrimSpace(encoding)); encoding != "" && encoding != "identity" {
+			encodings = append(encodings, encoding)
+		}
+	}
+	body := res.Body
+	// the encodings are decompressed in the reverse order of the compressions.
+	for i := len(encodings) - 1; i >= 0; i-- {
+		d, ok := getDecompressor(encodings[i])
+		if !ok {
+			res.Body.Close()
+			return fmt.Errorf("http: unsupported content encoding %q", encodings[i])
+		}
+		r, err := d(body)
+		if err != nil {
+			res.Body.Close()
+			return fmt.Errorf("http: decompress %s: %w", encodings[i], err)
+		}
+		body = &decompressedBody{Reader: r, r: r, body: res.Body}
+	}
+	res.Body = &limitedBody{ReadCloser: body, n: max}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+	return nil
+}
+
+// decompressedBody closes the decompressor and the response body.
+type decompressedBody struct {
+	io.Reader
+	r    io.ReadCloser
+	body io.Closer
+}
+
+func (b *decompressedBody) Close() error {
+	b.r.Close()
+	return b.body.Close()
+}
+
+// limitedBody fails with ErrResponseTooLarge once more than n bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.n < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > b.n+1 {
+		p = p[:b.n+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if b.n -= int64(n); b.n < 0 {
+		return n + int(b.n), ErrResponseTooLarge
+	}
+	return n, err
+}
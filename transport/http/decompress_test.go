@@ -0,0 +1,74 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecompress(t *testing.T) {
+	name := strings.Repeat("kratos", 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = io.WriteString(zw, `{"name":"`+name+`"}`)
+		_ = zw.Close()
+		encoding := "gzip"
+		if r.URL.Path == "/chained" {
+			data := buf.Bytes()
+			buf = bytes.Buffer{}
+			fw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+			_, _ = fw.Write(data)
+			_ = fw.Close()
+			encoding = "gzip, deflate"
+		}
+		if r.URL.Path == "/unsupported" {
+			encoding = "x-unknown"
+		}
+		w.Header().Set("Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", encoding)
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(srv.Listener.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	for _, path := range []string{"/", "/chained"} {
+		var reply struct {
+			Name string `json:"name"`
+		}
+		header := http.Header{}
+		err := client.Invoke(context.Background(), http.MethodGet, path, nil, &reply, WithAcceptEncoding(), Header(&header))
+		if err != nil || reply.Name != name {
+			t.Errorf("%s: expected the decompressed reply, got %v", path, err)
+		}
+		if header.Get("Accept-Encoding") != "deflate, gzip" {
+			t.Errorf("%s: unexpected Accept-Encoding %q", path, header.Get("Accept-Encoding"))
+		}
+	}
+
+	var reply struct{}
+	if err := client.Invoke(context.Background(), http.MethodGet, "/unsupported", nil, &reply, WithAcceptEncoding("x-unknown")); err == nil || !strings.Contains(err.Error(), "unsupported content encoding") {
+		t.Errorf("expected the unsupported content encoding, got %v", err)
+	}
+
+	limited, err := NewClient(context.Background(), WithEndpoint(srv.Listener.Addr().String()), WithMaxDecompressedSize(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer limited.Close()
+	if err := limited.Invoke(context.Background(), http.MethodGet, "/", nil, &reply, WithAcceptEncoding("gzip")); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/internal/drain"
+)
+
+// DrainHeader is the response header sent by the server in the drain phase,
+// the clients avoid the node and pick another one for the next requests.
+const DrainHeader = drain.Header
+
+// WithDrainHints with how long a node is avoided once it sends a drain hint,
+// that is the DrainHeader, or Connection: close with Retry-After, default is 10s
+// and 0 disables it. The Retry-After delay is used instead if present, and
+// it delays the next retry as well. It only applies to the discovery clients.
+func WithDrainHints(ttl time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		if ttl <= 0 {
+			o.drains = nil
+			return
+		}
+		o.drains = drain.New(ttl)
+	}
+}
+
+// retryHintKey is the context key of the retry hint of an attempt.
+type retryHintKey struct{}
+
+// retryHint is the delay before the next attempt hinted by the response.
+type retryHint struct {
+	delay time.Duration
+}
+
+// drainHint applies the drain hint of the response of the node.
+func (client *Client) drainHint(req *http.Request, res *http.Response, addr string) {
+	draining, delay := drain.Hint(res)
+	if draining && addr != "" && client.opts.drains != nil {
+		client.opts.drains.Add(addr, delay)
+	}
+	if hint, ok := req.Context().Value(retryHintKey{}).(*retryHint); ok && delay > 0 {
+		hint.delay = delay
+	}
+}
+
+// withRetryHint returns the context of an attempt recording its retry hint.
+func withRetryHint(ctx context.Context) (context.Context, *retryHint) {
+	hint := new(retryHint)
+	return context.WithValue(ctx, retryHintKey{}, hint), hint
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func TestServerDrainHeader(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").GET("/hello", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "hello")
+	})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	if rec.Header().Get(DrainHeader) != "" {
+		t.Errorf("expect no drain header before draining")
+	}
+	_ = srv.Drain(context.Background())
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	if rec.Header().Get(DrainHeader) == "" {
+		t.Errorf("expect the drain header while draining")
+	}
+}
+
+func TestClientDrainHints(t *testing.T) {
+	var draining, serving int32
+	newServer := func(hits *int32, drain bool) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(hits, 1)
+			if drain {
+				w.Header().Set(DrainHeader, "true")
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{}`))
+		}))
+	}
+	drainSrv, srv := newServer(&draining, true), newServer(&serving, false)
+	defer drainSrv.Close()
+	defer srv.Close()
+	dis := &unixDiscovery{ins: []*registry.ServiceInstance{
+		{ID: "1", Name: "drain", Endpoints: []string{"http://" + strings.TrimPrefix(drainSrv.URL, "http://")}},
+		{ID: "2", Name: "drain", Endpoints: []string{"http://" + strings.TrimPrefix(srv.URL, "http://")}},
+	}}
+	client, err := NewClient(context.Background(), WithEndpoint("discovery:///drain"), WithDiscovery(dis), WithBlock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for i := 0; i < 20; i++ {
+		var reply map[string]string
+		if err := client.Invoke(context.Background(), http.MethodGet, "/hello", nil, &reply); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&draining); n > 1 {
+		t.Errorf("expect the draining node avoided after its hint, got %d requests", n)
+	}
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(srv.URL), WithRetry(RetryBackoff(0, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var reply map[string]string
+	if err := client.Invoke(context.Background(), http.MethodGet, "/hello", nil, &reply); err == nil {
+		t.Fatal("expect the unavailable error")
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("expect no retry for the Retry-After beyond the max delay, got %d requests", n)
+	}
+}
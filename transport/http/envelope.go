@@ -0,0 +1,171 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/httputil"
+	"github.com/go-kratos/kratos/v2/middleware/warning"
+)
+
+// EnvelopeOption is a response envelope option.
+type EnvelopeOption func(*envelope)
+
+// EnvelopeKeys with the JSON keys of the envelope, default is code, message and data.
+func EnvelopeKeys(code, message, data string) EnvelopeOption {
+	return func(e *envelope) {
+		e.codeKey = code
+		e.messageKey = message
+		e.dataKey = data
+	}
+}
+
+// EnvelopeSuccessCode with the envelope code of successful responses, default is 0.
+func EnvelopeSuccessCode(code int) EnvelopeOption {
+	return func(e *envelope) {
+		e.successCode = code
+	}
+}
+
+// EnvelopeErrorCode with the func mapping kratos errors into the envelope code,
+// default is the error code.
+func EnvelopeErrorCode(f func(*errors.Error) int) EnvelopeOption {
+	return func(e *envelope) {
+		e.errorCode = f
+	}
+}
+
+// EnvelopeStatusOK replies errors with HTTP status 200 instead of the error code,
+// for legacy clients which only inspect the envelope code.
+func EnvelopeStatusOK() EnvelopeOption {
+	return func(e *envelope) {
+		e.statusOK = true
+	}
+}
+
+// EnvelopeWarnings with the JSON key of the warnings of the response set by
+// the warning middleware, e.g. "warnings", which is omitted without warnings.
+func EnvelopeWarnings(key string) EnvelopeOption {
+	return func(e *envelope) {
+		e.warningsKey = key
+	}
+}
+
+type envelope struct {
+	codeKey     string
+	messageKey  string
+	dataKey     string
+	warningsKey string
+	successCode int
+	errorCode   func(*errors.Error) int
+	statusOK    bool
+}
+
+// ResponseEnvelope wraps the JSON responses of the routes into an envelope,
+// e.g. {"code":0,"message":"","data":{...}}, and encodes the returned errors
+// into it. It is usually passed to Server.Route to select it per Router.
+func ResponseEnvelope(opts ...EnvelopeOption) FilterFunc {
+	e := &envelope{
+		codeKey:    "code",
+		messageKey: "message",
+		dataKey:    "data",
+		errorCode:  func(se *errors.Error) int { return int(se.Code) },
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if tr, ok := serverTransport(req); ok {
+				tr.envelope = e
+				tr.resTransforms = append(tr.resTransforms, e.wrap)
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+func envelopeFromRequest(req *http.Request) (*envelope, bool) {
+	if tr, ok := serverTransport(req); ok && tr.envelope != nil {
+		return tr.envelope, true
+	}
+	return nil, false
+}
+
+// wrap is a BodyTransformFunc which wraps the encoded JSON body as the envelope data,
+// bodies of other content types are left untouched.
+func (e *envelope) wrap(req *http.Request, body []byte) ([]byte, error) {
+	tr, _ := serverTransport(req)
+	header := tr.replyHeader
+	if len(body) > 0 && httputil.ContentSubtype(header.Get("Content-Type")) != "json" {
+		return body, nil
+	}
+	header.Set("Content-Type", httputil.ContentType("json"))
+	return e.marshal(e.successCode, "", body, e.warnings(header))
+}
+
+// encodeError encodes the error into the envelope.
+func (e *envelope) encodeError(w http.ResponseWriter, _ *http.Request, err error) {
+	se := errors.FromError(err)
+	body, err := e.marshal(e.errorCode(se), se.Message, nil, e.warnings(headerCarrier(w.Header())))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", httputil.ContentType("json"))
+	if e.statusOK {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(int(se.Code))
+	}
+	_, _ = w.Write(body)
+}
+
+// warnings returns the warnings of the reply header of EnvelopeWarnings.
+func (e *envelope) warnings(header headerCarrier) []warning.Warning {
+	if e.warningsKey == "" {
+		return nil
+	}
+	return warning.FromHeader(header)
+}
+
+// marshal writes the envelope with the keys in order, an empty data is encoded as null.
+func (e *envelope) marshal(code int, message string, data []byte, warnings []warning.Warning) ([]byte, error) {
+	if len(data) == 0 {
+		data = []byte("null")
+	}
+	type field struct {
+		key   string
+		value interface{}
+	}
+	fields := []field{
+		{e.codeKey, code},
+		{e.messageKey, message},
+		{e.dataKey, json.RawMessage(data)},
+	}
+	if len(warnings) > 0 {
+		fields = append(fields, field{e.warningsKey, warnings})
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
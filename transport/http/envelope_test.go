@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestResponseEnvelope(t *testing.T) {
+	srv := NewServer()
+	handle := func(r *Router) {
+		r.POST("/ok", func(ctx Context) error {
+			var in strictRequest
+			if err := ctx.Bind(&in); err != nil {
+				return err
+			}
+			return ctx.Result(http.StatusOK, in)
+		})
+		r.GET("/empty", func(ctx Context) error {
+			return ctx.Returns(nil, nil)
+		})
+		r.GET("/error", func(ctx Context) error {
+			return errors.NotFound("USER_NOT_FOUND", "user not found")
+		})
+	}
+	handle(srv.Route("/v1", ResponseEnvelope()))
+	handle(srv.Route("/v2", ResponseEnvelope(
+		EnvelopeKeys("errno", "errmsg", "result"),
+		EnvelopeSuccessCode(200),
+		EnvelopeErrorCode(func(se *errors.Error) int { return 10000 + int(se.Code) }),
+		EnvelopeStatusOK(),
+	)))
+	handle(srv.Route("/v3"))
+
+	tests := []struct {
+		method string
+		target string
+		code   int
+		body   string
+	}{
+		{http.MethodPost, "/v1/ok", http.StatusOK, `{"code":0,"message":"","data":{"name":"kratos"}}`},
+		{http.MethodGet, "/v1/empty", http.StatusOK, `{"code":0,"message":"","data":null}`},
+		{http.MethodGet, "/v1/error", http.StatusNotFound, `{"code":404,"message":"user not found","data":null}`},
+		{http.MethodPost, "/v2/ok", http.StatusOK, `{"errno":200,"errmsg":"","result":{"name":"kratos"}}`},
+		{http.MethodGet, "/v2/error", http.StatusOK, `{"errno":10404,"errmsg":"user not found","result":null}`},
+		{http.MethodPost, "/v3/ok", http.StatusOK, `{"name":"kratos"}`},
+	}
+	for _, test := range tests {
+		res := serveStrict(srv, test.method, test.target, "application/json", `{"name":"kratos"}`)
+		if res.Code != test.code {
+			t.Errorf("%s: expected %d got %d", test.target, test.code, res.Code)
+		}
+		if got := strings.TrimSpace(res.Body.String()); got != test.body {
+			t.Errorf("%s: expected %s got %s", test.target, test.body, got)
+		}
+	}
+}
+
+func TestResponseEnvelopeWarnings(t *testing.T) {
+	srv := NewServer()
+	r := srv.Route("/v1", ResponseEnvelope(EnvelopeWarnings("warnings")))
+	r.GET("/ok", func(ctx Context) error {
+		ctx.Response().Header().Add("Warning", `299 - "deprecated"`)
+		return ctx.Result(http.StatusOK, map[string]string{"name": "kratos"})
+	})
+	r.GET("/plain", func(ctx Context) error {
+		return ctx.Result(http.StatusOK, map[string]string{"name": "kratos"})
+	})
+	r.GET("/error", func(ctx Context) error {
+		ctx.Response().Header().Add("Warning", `199 - "degraded"`)
+		return errors.NotFound("USER_NOT_FOUND", "user not found")
+	})
+	tests := []struct {
+		target string
+		body   string
+	}{
+		{"/v1/ok", `{"code":0,"message":"","data":{"name":"kratos"},"warnings":[{"code":299,"agent":"-","text":"deprecated"}]}`},
+		{"/v1/plain", `{"code":0,"message":"","data":{"name":"kratos"}}`},
+		{"/v1/error", `{"code":404,"message":"user not found","data":null,"warnings":[{"code":199,"agent":"-","text":"degraded"}]}`},
+	}
+	for _, test := range tests {
+		res := serveStrict(srv, http.MethodGet, test.target, "application/json", "")
+		if got := strings.TrimSpace(res.Body.String()); got != test.body {
+			t.Errorf("%s: expected %s got %s", test.target, test.body, got)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// ErrFileNotFound is returned by File of the missing files.
+var ErrFileNotFound = errors.NotFound("FILE_NOT_FOUND", "file not found")
+
+// File replies the file of the name, which honors the Range and If-Range
+// headers of the resumable downloads, and is sent by sendfile on TCP.
+func (c *wrapper) File(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrFileNotFound
+		}
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.IsDir() {
+		return ErrFileNotFound
+	}
+	http.ServeContent(c.res, c.req, fi.Name(), fi.ModTime(), f)
+	return nil
+}
+
+// Attachment replies the content of the size as the download of the file
+// name. The content which is an io.Seeker, e.g. an fs.File, honors the Range
+// and If-Range headers, with the modification time of its Stat if any, and
+// the others are streamed as they are.
+func (c *wrapper) Attachment(name string, content io.Reader, size int64) error {
+	header := c.res.Header()
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	if rs, ok := content.(io.ReadSeeker); ok {
+		var modtime time.Time
+		if f, ok := content.(fs.File); ok {
+			if fi, err := f.Stat(); err == nil {
+				modtime = fi.ModTime()
+			}
+		}
+		http.ServeContent(c.res, c.req, name, modtime, rs)
+		return nil
+	}
+	if header.Get("Content-Type") == "" {
+		ctype := mime.TypeByExtension(path.Ext(name))
+		if ctype == "" {
+			ctype = "application/octet-stream"
+		}
+		header.Set("Content-Type", ctype)
+	}
+	if size >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+	header.Set("Accept-Ranges", "none")
+	c.res.WriteHeader(http.StatusOK)
+	if c.req.Method == http.MethodHead {
+		return nil
+	}
+	if size >= 0 {
+		content = io.LimitReader(content, size)
+	}
+	_, err := io.Copy(c.res, content)
+	return err
+}
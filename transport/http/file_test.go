@@ -0,0 +1,79 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+)
+
+func serveFile(f func(*wrapper) error, header http.Header) (*httptest.ResponseRecorder, error) {
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/download", nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := &wrapper{router: testRouter, req: req, res: res, w: responseWriter{200, res}}
+	return res, f(w)
+}
+
+func TestContextFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "data.txt")
+	if err := os.WriteFile(name, []byte("0123456789"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file := func(w *wrapper) error { return w.File(name) }
+
+	res, err := serveFile(file, nil)
+	if err != nil || res.Code != http.StatusOK || res.Body.String() != "0123456789" || res.Header().Get("Accept-Ranges") != "bytes" {
+		t.Fatalf("unexpected reply %d %q %v", res.Code, res.Body, err)
+	}
+	modified := res.Header().Get("Last-Modified")
+
+	res, _ = serveFile(file, http.Header{"Range": []string{"bytes=4-"}, "If-Range": []string{modified}})
+	if res.Code != http.StatusPartialContent || res.Body.String() != "456789" || res.Header().Get("Content-Range") != "bytes 4-9/10" {
+		t.Errorf("unexpected resumed reply %d %q %v", res.Code, res.Body, res.Header())
+	}
+	res, _ = serveFile(file, http.Header{"Range": []string{"bytes=4-"}, "If-Range": []string{"Mon, 02 Jan 2006 15:04:05 GMT"}})
+	if res.Code != http.StatusOK || res.Body.String() != "0123456789" {
+		t.Errorf("expected the whole file of the changed one, got %d %q", res.Code, res.Body)
+	}
+
+	if _, err = serveFile(func(w *wrapper) error { return w.File(name + ".missing") }, nil); !kerrors.IsNotFound(err) {
+		t.Errorf("expected not found got %v", err)
+	}
+	if _, err = serveFile(func(w *wrapper) error { return w.File(filepath.Dir(name)) }, nil); !kerrors.IsNotFound(err) {
+		t.Errorf("expected not found of the directory got %v", err)
+	}
+}
+
+func TestContextAttachment(t *testing.T) {
+	res, err := serveFile(func(w *wrapper) error {
+		return w.Attachment("报告.pdf", bytes.NewReader([]byte("0123456789")), 10)
+	}, http.Header{"Range": []string{"bytes=0-3"}})
+	if err != nil || res.Code != http.StatusPartialContent || res.Body.String() != "0123" {
+		t.Fatalf("unexpected reply %d %q %v", res.Code, res.Body, err)
+	}
+	if cd := res.Header().Get("Content-Disposition"); cd != "attachment; filename*=utf-8''%E6%8A%A5%E5%91%8A.pdf" {
+		t.Errorf("unexpected content disposition %q", cd)
+	}
+	if ct := res.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	res, err = serveFile(func(w *wrapper) error {
+		return w.Attachment("data.csv", io.MultiReader(strings.NewReader("a,b\n"), strings.NewReader("1,2\nextra")), 8)
+	}, http.Header{"Range": []string{"bytes=0-3"}})
+	if err != nil || res.Code != http.StatusOK || res.Body.String() != "a,b\n1,2\n" {
+		t.Fatalf("unexpected streamed reply %d %q %v", res.Code, res.Body, err)
+	}
+	if res.Header().Get("Accept-Ranges") != "none" || res.Header().Get("Content-Length") != "8" || res.Header().Get("Content-Disposition") != "attachment; filename=data.csv" {
+		t.Errorf("unexpected header %v", res.Header())
+	}
+}
@@ -1,6 +1,9 @@
 package http
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 // FilterFunc is a function which receives a http.Handler and returns another http.Handler.
 type FilterFunc func(http.Handler) http.Handler
@@ -14,3 +17,53 @@ func FilterChain(filters ...FilterFunc) FilterFunc {
 		return next
 	}
 }
+
+type (
+	routeKey            struct{}
+	operationKey        struct{}
+	uncompressedSizeKey struct{}
+)
+
+// RecordRoute returns a copy of the request which records the path template of the
+// route serving it, the template func returns it after the request is served.
+// It is for the server filters wrapping the router, e.g. an access log.
+func RecordRoute(req *http.Request) (*http.Request, func() string) {
+	if route, ok := req.Context().Value(routeKey{}).(*string); ok {
+		return req, func() string { return *route }
+	}
+	route := new(string)
+	return req.WithContext(context.WithValue(req.Context(), routeKey{}, route)), func() string { return *route }
+}
+
+// RecordOperation returns a copy of the request which records the operation of the
+// route serving it, the operation func returns it after the request is served.
+func RecordOperation(req *http.Request) (*http.Request, func() string) {
+	if operation, ok := req.Context().Value(operationKey{}).(*string); ok {
+		return req, func() string { return *operation }
+	}
+	operation := new(string)
+	return req.WithContext(context.WithValue(req.Context(), operationKey{}, operation)), func() string { return *operation }
+}
+
+// RecordUncompressedSize returns a copy of the request which records the size of the
+// response body before it is compressed by a compression filter, the size func returns
+// it after the request is served, or -1 if the response is not compressed.
+func RecordUncompressedSize(req *http.Request) (*http.Request, func() int64) {
+	if size, ok := req.Context().Value(uncompressedSizeKey{}).(*int64); ok {
+		return req, func() int64 { return *size }
+	}
+	size := new(int64)
+	*size = -1
+	return req.WithContext(context.WithValue(req.Context(), uncompressedSizeKey{}, size)), func() int64 { return *size }
+}
+
+// AddUncompressedSize adds n bytes to the uncompressed size recorded of the response,
+// it is for the compression filters.
+func AddUncompressedSize(ctx context.Context, n int64) {
+	if size, ok := ctx.Value(uncompressedSizeKey{}).(*int64); ok {
+		if *size < 0 {
+			*size = 0
+		}
+		*size += n
+	}
+}
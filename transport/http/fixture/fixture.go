@@ -0,0 +1,326 @@
+// Package fixture records the responses of the HTTP client into golden files,
+// which are replayed hermetically afterwards, e.g. in CI:
+//
+//	rec := fixture.New("testdata/fixtures", fixture.WithMode(fixture.ModeAuto))
+//	client, err := http.NewClient(ctx, http.WithEndpoint(addr), rec.ClientOption())
+//
+// The fixtures are keyed by the method, the path with the query and the hash
+// of the body of the requests, or by the name of the Name call option. The
+// replayed responses are decoded by the client as the recorded ones, e.g. the
+// errors of the error decoder.
+package fixture
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// HeaderName is the request header of the fixture name of the Name call
+// option, which is not sent.
+const HeaderName = "X-Kratos-Fixture"
+
+// redacted is the value of the redacted headers.
+const redacted = "***"
+
+// ErrNotFound is returned by the replays of the requests without fixture.
+var ErrNotFound = errors.New("fixture: not found")
+
+// Mode is the mode of the recorder.
+type Mode int
+
+const (
+	// ModeReplay replays the fixtures, the requests without fixture fail with ErrNotFound.
+	ModeReplay Mode = iota
+	// ModeRecord sends the requests and records their responses into the fixtures.
+	ModeRecord
+	// ModeAuto replays the fixtures, and records the ones missing.
+	ModeAuto
+)
+
+// ParseMode parses the mode of "replay", "record" or "auto", e.g. of a test flag.
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(s) {
+	case "", "replay":
+		return ModeReplay, nil
+	case "record":
+		return ModeRecord, nil
+	case "auto":
+		return ModeAuto, nil
+	}
+	return ModeReplay, fmt.Errorf("fixture: invalid mode %q", s)
+}
+
+// Name returns the call option of the fixture name of the call, e.g. of the
+// calls of the same request replied differently.
+func Name(name string) khttp.CallOption {
+	return khttp.WithHeader(HeaderName, name)
+}
+
+// Fixture is a recorded request and its response.
+type Fixture struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Body is a recorded body, in text if it is valid UTF-8, or else in base64.
+type Body []byte
+
+// MarshalJSON marshals the body in text, or in base64 prefixed by "base64:".
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) && !bytes.HasPrefix(b, []byte("base64:")) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal("base64:" + base64.StdEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON unmarshals the body of MarshalJSON.
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if strings.HasPrefix(s, "base64:") {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, "base64:"))
+		if err != nil {
+			return err
+		}
+		*b = raw
+		return nil
+	}
+	*b = Body(s)
+	return nil
+}
+
+// Option is fixture recorder option.
+type Option func(*Recorder)
+
+// WithMode with the mode of the recorder, default is ModeReplay.
+func WithMode(m Mode) Option {
+	return func(r *Recorder) {
+		r.mode = m
+	}
+}
+
+// WithTransport with the transport of the recorded requests, default is http.DefaultTransport.
+func WithTransport(t http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.next = t
+	}
+}
+
+// WithRedactHeaders with the header names whose values are redacted in the
+// fixtures, default are Authorization, Cookie and Set-Cookie.
+func WithRedactHeaders(names ...string) Option {
+	return func(r *Recorder) {
+		for _, name := range names {
+			r.redact[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// Recorder is the transport of the HTTP client recording or replaying the fixtures.
+type Recorder struct {
+	dir    string
+	mode   Mode
+	next   http.RoundTripper
+	redact map[string]bool
+	mu     sync.Mutex
+}
+
+var _ http.RoundTripper = (*Recorder)(nil)
+
+// New new a recorder of the fixtures of the directory.
+func New(dir string, opts ...Option) *Recorder {
+	r := &Recorder{
+		dir:  dir,
+		next: http.DefaultTransport,
+		redact: map[string]bool{
+			"Authorization": true,
+			"Cookie":        true,
+			"Set-Cookie":    true,
+		},
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// ClientOption returns the client option of the recorder as the transport.
+func (r *Recorder) ClientOption() khttp.ClientOption {
+	return khttp.WithTransport(r)
+}
+
+// RoundTrip replays the fixture of the request, or records it.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+	}
+	name := req.Header.Get(HeaderName)
+	if name != "" {
+		req = req.Clone(req.Context())
+		req.Header.Del(HeaderName)
+	}
+	path := r.path(req, name, body)
+	if r.mode != ModeRecord {
+		f, err := r.load(path)
+		if err == nil {
+			return f.Response.http(req), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s %s of %s", ErrNotFound, req.Method, req.URL.RequestURI(), path)
+		}
+	}
+	return r.record(req, body, path)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte, path string) (*http.Response, error) {
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	f := &Fixture{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.RequestURI(),
+			Header: r.redactHeader(req.Header),
+			Body:   body,
+		},
+		Response: Response{
+			StatusCode: res.StatusCode,
+			Header:     r.redactHeader(res.Header),
+			Body:       resBody,
+		},
+	}
+	if err = r.save(path, f); err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+	return res, nil
+}
+
+// path returns the fixture path of the request, of its name if any.
+func (r *Recorder) path(req *http.Request, name string, body []byte) string {
+	if name == "" {
+		h := sha256.New()
+		_, _ = io.WriteString(h, req.Method+" "+req.URL.RequestURI()+"\n")
+		_, _ = h.Write(body)
+		name = req.Method + " " + req.URL.Path + " " + hex.EncodeToString(h.Sum(nil))[:12]
+	}
+	return filepath.Join(r.dir, sanitize(name)+".json")
+}
+
+func (r *Recorder) load(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f Fixture
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("fixture: invalid fixture %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+func (r *Recorder) save(path string, f *Fixture) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err = os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644) //nolint:gosec
+}
+
+func (r *Recorder) redactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	c := h.Clone()
+	for k := range c {
+		if r.redact[k] {
+			c[k] = []string{redacted}
+		}
+	}
+	return c
+}
+
+func (res Response) http(req *http.Request) *http.Response {
+	header := res.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode)),
+		StatusCode:    res.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(res.Body)),
+		ContentLength: int64(len(res.Body)),
+		Request:       req,
+	}
+}
+
+// sanitize returns the file name of the fixture name, of which the
+// characters other than the letters, the digits, '-', '_' and '.' are '_'.
+func sanitize(name string) string {
+	b := []byte(strings.Trim(name, "/ "))
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '.':
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
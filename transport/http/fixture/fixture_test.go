@@ -0,0 +1,130 @@
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func newTestServer(hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		if req.URL.Path == "/users/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":404,"reason":"USER_NOT_FOUND","message":"not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"` + strings.TrimPrefix(req.URL.Path, "/users/") + `"}`))
+	}))
+}
+
+func newClient(t *testing.T, endpoint string, rec *Recorder) *khttp.Client {
+	client, err := khttp.NewClient(context.Background(), khttp.WithEndpoint(endpoint), rec.ClientOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestRecordReplay(t *testing.T) {
+	var hits int32
+	srv := newTestServer(&hits)
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	client := newClient(t, srv.URL, New(dir, WithMode(ModeRecord)))
+	var reply user
+	if err := client.Invoke(ctx, http.MethodGet, "/users/alice", nil, &reply, khttp.WithHeader("Authorization", "Bearer t")); err != nil || reply.Name != "alice" {
+		t.Fatalf("unexpected reply %+v %v", reply, err)
+	}
+	if err := client.Invoke(ctx, http.MethodGet, "/users/missing", nil, &reply); errors.Reason(err) != "USER_NOT_FOUND" {
+		t.Fatalf("expected the error of the server, got %v", err)
+	}
+	if err := client.Invoke(ctx, http.MethodPost, "/users/bob", &user{Name: "bob"}, &reply, Name("create bob")); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	if hits != 3 {
+		t.Fatalf("expected 3 hits got %d", hits)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 3 {
+		t.Fatalf("expected 3 fixtures got %v", files)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "create_bob.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f Fixture
+	if err = json.Unmarshal(data, &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Request.Method != http.MethodPost || string(f.Request.Body) != `{"name":"bob"}` || f.Response.Header.Get("Set-Cookie") != redacted {
+		t.Errorf("unexpected fixture %s", data)
+	}
+	for _, file := range files {
+		data, _ = os.ReadFile(file)
+		if strings.Contains(string(data), "Bearer t") || strings.Contains(string(data), "session=secret") {
+			t.Errorf("expected the redacted headers in %s", data)
+		}
+	}
+
+	client = newClient(t, srv.URL, New(dir))
+	reply = user{}
+	if err = client.Invoke(ctx, http.MethodGet, "/users/alice", nil, &reply); err != nil || reply.Name != "alice" {
+		t.Errorf("unexpected replay %+v %v", reply, err)
+	}
+	if err = client.Invoke(ctx, http.MethodGet, "/users/missing", nil, &reply); !errors.IsNotFound(err) || errors.Reason(err) != "USER_NOT_FOUND" {
+		t.Errorf("expected the replayed error, got %v", err)
+	}
+	if err = client.Invoke(ctx, http.MethodPost, "/users/bob", &user{Name: "bob"}, &reply, Name("create bob")); err != nil || reply.Name != "bob" {
+		t.Errorf("unexpected replay %+v %v", reply, err)
+	}
+	if err = client.Invoke(ctx, http.MethodGet, "/users/carol", nil, &reply); err == nil || !strings.Contains(err.Error(), ErrNotFound.Error()) {
+		t.Errorf("expected the error of the missing fixture, got %v", err)
+	}
+}
+
+func TestAuto(t *testing.T) {
+	var hits int32
+	srv := newTestServer(&hits)
+	defer srv.Close()
+	client := newClient(t, srv.URL, New(t.TempDir(), WithMode(ModeAuto)))
+	for i := 0; i < 2; i++ {
+		var reply user
+		if err := client.Invoke(context.Background(), http.MethodGet, "/users/alice", nil, &reply); err != nil || reply.Name != "alice" {
+			t.Fatalf("unexpected reply %+v %v", reply, err)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("expected the second call replayed, got %d hits", hits)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for s, want := range map[string]Mode{"": ModeReplay, "record": ModeRecord, "AUTO": ModeAuto} {
+		if got, err := ParseMode(s); err != nil || got != want {
+			t.Errorf("%s: expected %v got %v %v", s, want, got, err)
+		}
+	}
+	if _, err := ParseMode("live"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// TrustedProxies with the IPs or CIDRs of the proxies, e.g. the load
+// balancers, whose Forwarded, or X-Forwarded-For and X-Forwarded-Proto headers
+// are trusted for the client IP and the scheme of the requests. The hops are
+// read from the nearest one, and the client is the first not trusted, default
+// is none, so the client is the remote address.
+func TrustedProxies(cidrs ...string) ServerOption {
+	return func(s *Server) {
+		for _, cidr := range cidrs {
+			n, err := host.ParseCIDR(cidr)
+			if err != nil {
+				log.Errorf("[HTTP] invalid trusted proxy %s: %v", cidr, err)
+				continue
+			}
+			s.trustedProxies = append(s.trustedProxies, n)
+		}
+	}
+}
+
+// ClientIP returns the IP of the client of the request, which is forwarded by
+// the trusted proxies, or else the one of the remote address.
+func (tr *Transport) ClientIP() string {
+	return tr.clientIP
+}
+
+// Scheme returns the scheme of the client of the request, "http" or "https",
+// which is forwarded by the trusted proxies, or else the one of the connection.
+func (tr *Transport) Scheme() string {
+	return tr.scheme
+}
+
+type hop struct {
+	ip    string
+	proto string
+}
+
+// realClient returns the client IP and the scheme of the request behind the trusted proxies.
+func realClient(req *http.Request, trusted []*net.IPNet) (string, string) {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ip = req.RemoteAddr
+	}
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	if !isTrusted(ip, trusted) {
+		return ip, scheme
+	}
+	hops := forwardedHops(req.Header)
+	for i := len(hops) - 1; i >= 0; i-- {
+		if hops[i].ip == "" {
+			break
+		}
+		ip = hops[i].ip
+		if hops[i].proto != "" {
+			scheme = hops[i].proto
+		}
+		if !isTrusted(ip, trusted) {
+			break
+		}
+	}
+	return ip, scheme
+}
+
+// forwardedHops returns the hops of the Forwarded header of RFC 7239, or of
+// the X-Forwarded-For and X-Forwarded-Proto headers, from the farthest one.
+func forwardedHops(header http.Header) []hop {
+	var hops []hop
+	if values := header.Values("Forwarded"); len(values) > 0 {
+		for _, elem := range splitList(values) {
+			var h hop
+			for _, pair := range strings.Split(elem, ";") {
+				k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok {
+					continue
+				}
+				v = strings.Trim(v, `"`)
+				switch strings.ToLower(k) {
+				case "for":
+					h.ip = forwardedIP(v)
+				case "proto":
+					h.proto = strings.ToLower(v)
+				}
+			}
+			hops = append(hops, h)
+		}
+		return hops
+	}
+	ips := splitList(header.Values("X-Forwarded-For"))
+	protos := splitList(header.Values("X-Forwarded-Proto"))
+	for i, ip := range ips {
+		h := hop{ip: forwardedIP(ip)}
+		switch {
+		case len(protos) == len(ips):
+			h.proto = strings.ToLower(protos[i])
+		case len(protos) > 0:
+			// the scheme set by the farthest proxy only.
+			h.proto = strings.ToLower(protos[0])
+		}
+		hops = append(hops, h)
+	}
+	return hops
+}
+
+// forwardedIP returns the IP of the node of the hop, e.g. "[2001:db8::1]:4711",
+// empty if it is obfuscated or unknown.
+func forwardedIP(node string) string {
+	if h, _, err := net.SplitHostPort(node); err == nil {
+		node = h
+	}
+	node = strings.TrimSuffix(strings.TrimPrefix(node, "["), "]")
+	if net.ParseIP(node) == nil {
+		return ""
+	}
+	return node
+}
+
+func splitList(values []string) []string {
+	var list []string
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
+
+func isTrusted(ip string, trusted []*net.IPNet) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, n := range trusted {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestRealClient(t *testing.T) {
+	var trusted []*net.IPNet
+	for _, cidr := range []string{"10.0.0.0/8", "192.168.1.1"} {
+		n, _ := host.ParseCIDR(cidr)
+		trusted = append(trusted, n)
+	}
+	tests := []struct {
+		name   string
+		remote string
+		header http.Header
+		tls    bool
+		ip     string
+		scheme string
+	}{
+		{"direct", "203.0.113.7:1234", nil, false, "203.0.113.7", "http"},
+		{"direct tls", "203.0.113.7:1234", nil, true, "203.0.113.7", "https"},
+		{"untrusted peer", "203.0.113.7:1234", http.Header{"X-Forwarded-For": {"198.51.100.1"}, "X-Forwarded-Proto": {"https"}}, false, "203.0.113.7", "http"},
+		{"xff", "10.0.0.1:1234", http.Header{"X-Forwarded-For": {"198.51.100.1"}, "X-Forwarded-Proto": {"https"}}, false, "198.51.100.1", "https"},
+		{"xff spoofed", "10.0.0.1:1234", http.Header{"X-Forwarded-For": {"1.1.1.1, 198.51.100.1", "192.168.1.1"}, "X-Forwarded-Proto": {"https"}}, false, "198.51.100.1", "https"},
+		{"xff all trusted", "10.0.0.1:1234", http.Header{"X-Forwarded-For": {"10.0.0.2"}}, false, "10.0.0.2", "http"},
+		{"forwarded", "10.0.0.1:1234", http.Header{"Forwarded": {`for=198.51.100.1;proto=https, for="[2001:db8::1]:4711";proto=http`, "for=10.0.0.3"}}, false, "2001:db8::1", "http"},
+		{"forwarded obfuscated", "10.0.0.1:1234", http.Header{"Forwarded": {"for=_hidden;proto=https"}}, false, "10.0.0.1", "http"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = test.remote
+			req.TLS = nil
+			if test.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			for k, v := range test.header {
+				req.Header[k] = v
+			}
+			ip, scheme := realClient(req, trusted)
+			if ip != test.ip || scheme != test.scheme {
+				t.Errorf("expected %s %s got %s %s", test.ip, test.scheme, ip, scheme)
+			}
+		})
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	srv := NewServer(TrustedProxies("127.0.0.1", "invalid"))
+	srv.HandleFunc("/ip", func(w http.ResponseWriter, req *http.Request) {
+		tr, _ := transport.FromServerContext(req.Context())
+		ht := tr.(*Transport)
+		_, _ = w.Write([]byte(ht.ClientIP() + " " + ht.Scheme()))
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/ip", nil)
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	buf := make([]byte, 64)
+	n, _ := res.Body.Read(buf)
+	if got := string(buf[:n]); got != "198.51.100.1 https" {
+		t.Errorf("unexpected client %q", got)
+	}
+}
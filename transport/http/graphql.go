@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/matcher"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// GraphQL is the GraphQL endpoint of a path of the server, whose executor is
+// an http.Handler, e.g. of the gqlgen, and whose resolvers are through the
+// middleware of their operations, e.g. of the gqlgen:
+//
+//	gql := srv.GraphQL("/graphql", h)
+//	gql.Use("/graphql/Mutation/*", auth)
+//	h.AroundFields(func(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+//		fc := graphql.GetFieldContext(ctx)
+//		return gql.Resolve(ctx, fc.Object, fc.Field.Name, next)
+//	})
+type GraphQL struct {
+	path       string
+	handler    http.Handler
+	middleware matcher.Matcher
+}
+
+type graphqlError struct {
+	Message    string                 `json:"message"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQL mounts the GraphQL handler on the GET and POST requests of the path,
+// which are through the server middleware of the operation of the path, e.g.
+// of the authentication, and are served of the context of them. The errors of
+// the middleware are replied as the GraphQL errors of their status codes.
+func (s *Server) GraphQL(path string, h http.Handler, filters ...FilterFunc) *GraphQL {
+	g := &GraphQL{path: path, handler: h, middleware: matcher.New()}
+	r := s.Route("/")
+	r.GET(path, g.serve, filters...)
+	r.POST(path, g.serve, filters...)
+	return g
+}
+
+// Use uses the resolver middleware of the selector of the operations, which
+// are the path of the object and the field, e.g. "/graphql/Query/user".
+func (g *GraphQL) Use(selector string, m ...middleware.Middleware) {
+	g.middleware.Add(selector, m...)
+}
+
+// Resolve resolves the field of the object by next through the resolver
+// middleware, of the transport whose operation is of the field, so that the
+// selector middleware match it. The resolvers of a request are concurrent, and
+// share the request header and the reply header.
+func (g *GraphQL) Resolve(ctx context.Context, object, field string, next func(context.Context) (interface{}, error)) (interface{}, error) {
+	operation := path.Join(g.path, object, field)
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if tr, ok := tr.(*Transport); ok {
+			rt := *tr
+			rt.operation = operation
+			ctx = transport.NewServerContext(ctx, &rt)
+		}
+	}
+	ms := matcher.MatchContext(ctx, g.middleware, operation)
+	if len(ms) == 0 {
+		return next(ctx)
+	}
+	h := middleware.Chain(ms...)(func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return next(ctx)
+	})
+	return h(ctx, field)
+}
+
+func (g *GraphQL) serve(ctx Context) error {
+	var served bool
+	h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+		served = true
+		g.handler.ServeHTTP(ctx.Response(), ctx.Request().WithContext(c))
+		return nil, nil
+	})
+	_, err := h(ctx, ctx.Request())
+	if err == nil || served {
+		// the handler replied.
+		return nil
+	}
+	se := errors.FromError(err)
+	body, err := json.Marshal(map[string][]graphqlError{
+		"errors": {{Message: se.Message, Extensions: GraphQLExtensions(se)}},
+	})
+	if err != nil {
+		return err
+	}
+	w := ctx.Response()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(se.Code))
+	_, err = w.Write(body)
+	return err
+}
+
+// GraphQLExtensions returns the extensions of the GraphQL error of the kratos
+// error, of which the code is the reason, e.g. of the error presenter of the
+// gqlgen:
+//
+//	e := graphql.DefaultErrorPresenter(ctx, err)
+//	e.Extensions = khttp.GraphQLExtensions(err)
+func GraphQLExtensions(err error) map[string]interface{} {
+	se := errors.FromError(err)
+	if se == nil {
+		return nil
+	}
+	ext := map[string]interface{}{"status": se.Code}
+	if se.Reason != "" {
+		ext["code"] = se.Reason
+	}
+	if len(se.Metadata) > 0 {
+		ext["metadata"] = se.Metadata
+	}
+	return ext
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type graphqlUserKey struct{}
+
+func TestGraphQL(t *testing.T) {
+	auth := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, _ := transport.FromServerContext(ctx)
+			user := tr.RequestHeader().Get("Authorization")
+			if user == "" {
+				return nil, errors.Unauthorized("UNAUTHORIZED", "missing token")
+			}
+			return handler(context.WithValue(ctx, graphqlUserKey{}, user), req)
+		}
+	}
+	srv := NewServer()
+	srv.Use("/graphql", auth)
+	var gql *GraphQL
+	var operations []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the executor resolving a field of the query.
+		res, err := gql.Resolve(r.Context(), "Query", "user", func(ctx context.Context) (interface{}, error) {
+			tr, _ := transport.FromServerContext(ctx)
+			operations = append(operations, tr.Operation())
+			return ctx.Value(graphqlUserKey{}), nil
+		})
+		if err != nil {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errors": []interface{}{
+				map[string]interface{}{"message": err.Error(), "extensions": GraphQLExtensions(err)},
+			}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"user": res}})
+	})
+	gql = srv.GraphQL("/graphql", h)
+	gql.Use("/graphql/Mutation/*", func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errors.Forbidden("FORBIDDEN", "read only")
+		}
+	})
+	gql.Use("/graphql/Query/*", func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if req != "user" {
+				t.Errorf("expected the field of the request, got %v", req)
+			}
+			return handler(ctx, req)
+		}
+	})
+
+	serve := func(token string) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{user}"}`))
+		if token != "" {
+			req.Header.Set("Authorization", token)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Code, strings.TrimSpace(w.Body.String())
+	}
+	if code, body := serve("alice"); code != http.StatusOK || body != `{"data":{"user":"alice"}}` {
+		t.Errorf("unexpected reply %d %s", code, body)
+	}
+	if len(operations) != 1 || operations[0] != "/graphql/Query/user" {
+		t.Errorf("unexpected operations %v", operations)
+	}
+	want := `{"errors":[{"message":"missing token","extensions":{"code":"UNAUTHORIZED","status":401}}]}`
+	if code, body := serve(""); code != http.StatusUnauthorized || body != want {
+		t.Errorf("unexpected reply %d %s", code, body)
+	}
+
+	_, err := gql.Resolve(context.Background(), "Mutation", "deleteUser", func(ctx context.Context) (interface{}, error) {
+		t.Error("expected the resolver not called")
+		return nil, nil
+	})
+	ext := GraphQLExtensions(err)
+	if ext["code"] != "FORBIDDEN" || ext["status"] != int32(http.StatusForbidden) {
+		t.Errorf("unexpected extensions %v", ext)
+	}
+	if GraphQLExtensions(nil) != nil {
+		t.Error("expected no extensions of nil")
+	}
+}
@@ -0,0 +1,376 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2"
+	"github.com/go-kratos/kratos/v2/log"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const (
+	redacted          = "***"
+	defaultMaxBody    = 64 << 10
+	defaultMaxEntries = 1000
+	defaultDuration   = time.Minute
+	maxDuration       = time.Hour
+)
+
+// Option is HAR recorder option.
+type Option func(*options)
+
+type options struct {
+	dir           string
+	sampleRate    float64
+	maxBodySize   int
+	maxEntries    int
+	redactHeaders map[string]bool
+	redactQuery   map[string]bool
+	redactBody    []*regexp.Regexp
+}
+
+// WithDir with the directory of the HAR files, default is the temp directory.
+func WithDir(dir string) Option {
+	return func(o *options) {
+		o.dir = dir
+	}
+}
+
+// WithSampleRate with the sample rate of the captured requests in [0, 1], default is 1.
+func WithSampleRate(rate float64) Option {
+	return func(o *options) {
+		o.sampleRate = rate
+	}
+}
+
+// WithMaxBodySize with the max captured body size of the requests and responses.
+func WithMaxBodySize(size int) Option {
+	return func(o *options) {
+		o.maxBodySize = size
+	}
+}
+
+// WithMaxEntries with the max captured entries in a recording window.
+func WithMaxEntries(n int) Option {
+	return func(o *options) {
+		o.maxEntries = n
+	}
+}
+
+// WithRedactHeaders with the header names whose values are redacted,
+// default are Authorization, Cookie and Set-Cookie.
+func WithRedactHeaders(names ...string) Option {
+	return func(o *options) {
+		for _, name := range names {
+			o.redactHeaders[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// WithRedactQuery with the query parameter names whose values are redacted.
+func WithRedactQuery(names ...string) Option {
+	return func(o *options) {
+		for _, name := range names {
+			o.redactQuery[name] = true
+		}
+	}
+}
+
+// WithRedactBody with the patterns whose matches in the bodies are redacted,
+// e.g. regexp.MustCompile(`"password":"[^"]*"`).
+func WithRedactBody(patterns ...*regexp.Regexp) Option {
+	return func(o *options) {
+		o.redactBody = append(o.redactBody, patterns...)
+	}
+}
+
+// Recorder captures the sampled requests and responses into HAR files
+// in a time-boxed recording window started by the admin handler.
+type Recorder struct {
+	opts options
+
+	mu      sync.Mutex
+	active  bool
+	until   time.Time
+	timer   *time.Timer
+	entries []Entry
+	files   []string
+}
+
+// NewRecorder creates a HAR recorder.
+func NewRecorder(opts ...Option) *Recorder {
+	o := options{
+		dir:         os.TempDir(),
+		sampleRate:  1,
+		maxBodySize: defaultMaxBody,
+		maxEntries:  defaultMaxEntries,
+		redactHeaders: map[string]bool{
+			"Authorization": true,
+			"Cookie":        true,
+			"Set-Cookie":    true,
+		},
+		redactQuery: map[string]bool{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Recorder{opts: o}
+}
+
+// Start starts a recording window, the captures are written into
+// a HAR file when the window ends.
+func (r *Recorder) Start(d time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.active {
+		return fmt.Errorf("recording until %s", r.until.Format(time.RFC3339))
+	}
+	r.active = true
+	r.until = time.Now().Add(d)
+	r.entries = nil
+	r.timer = time.AfterFunc(d, func() {
+		if _, err := r.Stop(); err != nil {
+			log.Errorf("failed to write HAR file: %v", err)
+		}
+	})
+	return nil
+}
+
+// Stop ends the recording window and returns the written HAR file.
+func (r *Recorder) Stop() (string, error) {
+	r.mu.Lock()
+	if !r.active {
+		r.mu.Unlock()
+		return "", nil
+	}
+	r.active = false
+	r.timer.Stop()
+	entries := r.entries
+	r.entries = nil
+	r.mu.Unlock()
+
+	name := filepath.Join(r.opts.dir, fmt.Sprintf("kratos-%s.har", time.Now().Format("20060102T150405.000")))
+	data, err := json.MarshalIndent(HAR{Log: Log{
+		Version: Version,
+		Creator: Creator{Name: "kratos", Version: kratos.Release},
+		Entries: entries,
+	}}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(name, data, 0o600); err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	r.files = append(r.files, name)
+	r.mu.Unlock()
+	return name, nil
+}
+
+func (r *Recorder) sampled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.active || len(r.entries) >= r.opts.maxEntries {
+		return false
+	}
+	return r.opts.sampleRate >= 1 || rand.Float64() < r.opts.sampleRate
+}
+
+func (r *Recorder) add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.active && len(r.entries) < r.opts.maxEntries {
+		r.entries = append(r.entries, e)
+	}
+}
+
+// Filter returns an HTTP server filter capturing the requests in the recording window.
+func (r *Recorder) Filter() khttp.FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if !r.sampled() {
+				next.ServeHTTP(w, req)
+				return
+			}
+			start := time.Now()
+			var body []byte
+			if req.Body != nil && req.Body != http.NoBody {
+				body, _ = io.ReadAll(io.LimitReader(req.Body, int64(r.opts.maxBodySize)))
+				req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+			}
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK, max: r.opts.maxBodySize}
+			next.ServeHTTP(rw, req)
+			r.add(r.entry(req, body, rw, start))
+		})
+	}
+}
+
+func (r *Recorder) entry(req *http.Request, body []byte, rw *responseWriter, start time.Time) Entry {
+	elapsed := float64(time.Since(start)) / float64(time.Millisecond)
+	u := *req.URL
+	u.Host = req.Host
+	if u.Scheme = "http"; req.TLS != nil {
+		u.Scheme = "https"
+	}
+	query := u.Query()
+	for k := range query {
+		if r.opts.redactQuery[k] {
+			for i := range query[k] {
+				query[k][i] = redacted
+			}
+		}
+	}
+	u.RawQuery = query.Encode()
+	e := Entry{
+		StartedDateTime: start.Format(time.RFC3339Nano),
+		Time:            elapsed,
+		Request: Request{
+			Method:      req.Method,
+			URL:         u.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     []NameValue{},
+			Headers:     headerPairs(r.redactHeader(req.Header)),
+			QueryString: queryPairs(query),
+			HeadersSize: -1,
+			BodySize:    len(body),
+		},
+		Response: Response{
+			Status:      rw.status,
+			StatusText:  http.StatusText(rw.status),
+			HTTPVersion: req.Proto,
+			Cookies:     []NameValue{},
+			Headers:     headerPairs(r.redactHeader(rw.Header())),
+			Content: Content{
+				Size:     rw.size,
+				MimeType: rw.Header().Get("Content-Type"),
+				Text:     r.redactText(rw.body.Bytes()),
+			},
+			HeadersSize: -1,
+			BodySize:    rw.size,
+		},
+		Timings: Timings{Wait: elapsed},
+	}
+	if len(body) > 0 {
+		e.Request.PostData = &PostData{MimeType: req.Header.Get("Content-Type"), Text: r.redactText(body)}
+	}
+	return e
+}
+
+func (r *Recorder) redactHeader(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for k, v := range h {
+		if r.opts.redactHeaders[k] {
+			out[k] = []string{redacted}
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func (r *Recorder) redactText(b []byte) string {
+	for _, re := range r.opts.redactBody {
+		b = re.ReplaceAll(b, []byte(redacted))
+	}
+	return string(b)
+}
+
+type status struct {
+	Active  bool     `json:"active"`
+	Until   string   `json:"until,omitempty"`
+	Entries int      `json:"entries"`
+	Files   []string `json:"files"`
+}
+
+// Handler returns the admin handler of the recorder:
+//   - POST starts a recording window, the duration query is the window length, default is 1m.
+//   - DELETE stops the recording window and writes the HAR file.
+//   - GET returns the recording status and the written HAR files.
+func (r *Recorder) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodPost:
+			d := defaultDuration
+			if v := req.URL.Query().Get("duration"); v != "" {
+				var err error
+				if d, err = time.ParseDuration(v); err != nil || d <= 0 || d > maxDuration {
+					http.Error(w, fmt.Sprintf("invalid duration: %s", v), http.StatusBadRequest)
+					return
+				}
+			}
+			if err := r.Start(d); err != nil {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+		case http.MethodDelete:
+			if _, err := r.Stop(); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		case http.MethodGet:
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		r.mu.Lock()
+		s := status{Active: r.active, Entries: len(r.entries), Files: append([]string{}, r.files...)}
+		if r.active {
+			s.Until = r.until.Format(time.RFC3339)
+		}
+		r.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s)
+	})
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// responseWriter captures the response status and body.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+	max    int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wrote {
+		w.wrote = true
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	if n := w.max - w.body.Len(); n > 0 {
+		if n > len(b) {
+			n = len(b)
+		}
+		w.body.Write(b[:n])
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,97 @@
+package har
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder(
+		WithDir(t.TempDir()),
+		WithRedactQuery("token"),
+		WithRedactBody(regexp.MustCompile(`"password":"[^"]*"`)),
+	)
+	h := rec.Filter()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(b)
+	}))
+
+	admin := httptest.NewRecorder()
+	rec.Handler().ServeHTTP(admin, httptest.NewRequest(http.MethodPost, "/debug/har?duration=1m", nil))
+	if admin.Code != http.StatusOK {
+		t.Fatalf("expected %d got %d", http.StatusOK, admin.Code)
+	}
+	admin = httptest.NewRecorder()
+	rec.Handler().ServeHTTP(admin, httptest.NewRequest(http.MethodPost, "/debug/har", nil))
+	if admin.Code != http.StatusConflict {
+		t.Fatalf("expected %d got %d", http.StatusConflict, admin.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/users?token=secret&id=1", strings.NewReader(`{"name":"kratos","password":"secret"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Body.String() != `{"name":"kratos","password":"secret"}` {
+		t.Fatalf("unexpected response: %s", w.Body.String())
+	}
+
+	name, err := rec.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("expected secrets redacted: %s", data)
+	}
+	var har HAR
+	if err = json.Unmarshal(data, &har); err != nil {
+		t.Fatal(err)
+	}
+	if len(har.Log.Entries) != 1 {
+		t.Fatalf("expected 1 entry got %d", len(har.Log.Entries))
+	}
+	e := har.Log.Entries[0]
+	if e.Request.Method != http.MethodPost || e.Response.Status != http.StatusCreated {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e.Request.PostData == nil || !strings.Contains(e.Request.PostData.Text, `"name":"kratos"`) {
+		t.Errorf("unexpected post data: %+v", e.Request.PostData)
+	}
+
+	// no captures out of the recording window.
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	admin = httptest.NewRecorder()
+	rec.Handler().ServeHTTP(admin, httptest.NewRequest(http.MethodGet, "/debug/har", nil))
+	var s status
+	if err = json.Unmarshal(admin.Body.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Active || s.Entries != 0 || len(s.Files) != 1 {
+		t.Errorf("unexpected status: %+v", s)
+	}
+}
+
+func TestRecorderWindow(t *testing.T) {
+	rec := NewRecorder(WithDir(t.TempDir()))
+	if err := rec.Start(50 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.active || len(rec.files) != 1 {
+		t.Errorf("expected the window ended with a HAR file, got %v %v", rec.active, rec.files)
+	}
+}
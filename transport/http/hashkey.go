@@ -0,0 +1,45 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// HashKeyFunc derives the hash key of a request for the hash based balancers,
+// e.g. the ringhash balancer, an empty key is not set.
+type HashKeyFunc func(req *http.Request) string
+
+// WithHashKey with the hash key of the requests, unless the caller sets it
+// by selector.NewHashKeyContext.
+func WithHashKey(f HashKeyFunc) ClientOption {
+	return func(o *clientOptions) {
+		o.hashKey = f
+	}
+}
+
+// HashKeyHeader returns the hash key from the request header.
+func HashKeyHeader(name string) HashKeyFunc {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+// HashKeyQuery returns the hash key from the query parameter.
+func HashKeyQuery(name string) HashKeyFunc {
+	return func(req *http.Request) string {
+		return req.URL.Query().Get(name)
+	}
+}
+
+// HashKeyContext returns the hash key from the context value, a string or a fmt.Stringer.
+func HashKeyContext(key interface{}) HashKeyFunc {
+	return func(req *http.Request) string {
+		switch v := req.Context().Value(key).(type) {
+		case string:
+			return v
+		case fmt.Stringer:
+			return v.String()
+		}
+		return ""
+	}
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/selector/ringhash"
+)
+
+type ctxKey struct{}
+
+func TestHashKeyFunc(t *testing.T) {
+	req, _ := http.NewRequestWithContext(context.WithValue(context.Background(), ctxKey{}, "ctx-user"),
+		http.MethodGet, "http://127.0.0.1/hello?user=query-user", nil)
+	req.Header.Set("X-User", "header-user")
+	cases := []struct {
+		f    HashKeyFunc
+		want string
+	}{
+		{HashKeyHeader("X-User"), "header-user"},
+		{HashKeyQuery("user"), "query-user"},
+		{HashKeyContext(ctxKey{}), "ctx-user"},
+		{HashKeyContext("missing"), ""},
+	}
+	for _, c := range cases {
+		if got := c.f(req); got != c.want {
+			t.Errorf("expect %q, got %q", c.want, got)
+		}
+	}
+}
+
+func TestWithHashKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rt := &recordRoundTripper{hosts: make(map[string]int)}
+	client, err := NewClient(ctx,
+		WithEndpoint("discovery:///helloworld"),
+		WithBlock(),
+		WithDiscovery(staticDiscovery{
+			{ID: "1", Endpoints: []string{"http://127.0.0.1:8001"}},
+			{ID: "2", Endpoints: []string{"http://127.0.0.1:8002"}},
+			{ID: "3", Endpoints: []string{"http://127.0.0.1:8003"}},
+		}),
+		WithTransport(rt),
+		WithSelector(ringhash.NewBuilder()),
+		WithHashKey(HashKeyHeader("X-User")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for i := 0; i < 10; i++ {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/hello", nil)
+		req.Header.Set("X-User", "kratos")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if len(rt.hosts) != 1 {
+		t.Errorf("expect the requests routed to one node, got %v", rt.hosts)
+	}
+}
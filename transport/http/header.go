@@ -0,0 +1,159 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheDirective is a Cache-Control directive.
+type CacheDirective string
+
+// The Cache-Control directives without the values.
+const (
+	CachePublic         CacheDirective = "public"
+	CachePrivate        CacheDirective = "private"
+	CacheNoCache        CacheDirective = "no-cache"
+	CacheNoStore        CacheDirective = "no-store"
+	CacheNoTransform    CacheDirective = "no-transform"
+	CacheMustRevalidate CacheDirective = "must-revalidate"
+	CacheImmutable      CacheDirective = "immutable"
+)
+
+// CacheMaxAge is the max-age directive, in seconds rounded down.
+func CacheMaxAge(d time.Duration) CacheDirective { return seconds("max-age", d) }
+
+// CacheSMaxAge is the s-maxage directive of the shared caches.
+func CacheSMaxAge(d time.Duration) CacheDirective { return seconds("s-maxage", d) }
+
+// CacheStaleWhileRevalidate is the stale-while-revalidate directive of RFC 5861.
+func CacheStaleWhileRevalidate(d time.Duration) CacheDirective {
+	return seconds("stale-while-revalidate", d)
+}
+
+// CacheStaleIfError is the stale-if-error directive of RFC 5861.
+func CacheStaleIfError(d time.Duration) CacheDirective { return seconds("stale-if-error", d) }
+
+func seconds(name string, d time.Duration) CacheDirective {
+	if d < 0 {
+		d = 0
+	}
+	return CacheDirective(name + "=" + strconv.FormatInt(int64(d/time.Second), 10))
+}
+
+// Link is a web link of the Link header of RFC 8288.
+type Link struct {
+	URL string
+	Rel string
+}
+
+// ResponseHeader sets the common response headers with the correct formatting.
+type ResponseHeader struct {
+	header http.Header
+}
+
+// NewResponseHeader returns the ResponseHeader of the header, e.g. of an http.ResponseWriter.
+func NewResponseHeader(h http.Header) ResponseHeader {
+	return ResponseHeader{header: h}
+}
+
+// CacheControl sets the Cache-Control header of the directives,
+// e.g. CacheControl(CachePublic, CacheMaxAge(time.Hour)).
+func (h ResponseHeader) CacheControl(directives ...CacheDirective) ResponseHeader {
+	values := make([]string, 0, len(directives))
+	for _, d := range directives {
+		values = append(values, string(d))
+	}
+	h.header.Set("Cache-Control", strings.Join(values, ", "))
+	return h
+}
+
+// Link adds the links to the Link header, e.g. `<https://example.com/?page=2>; rel="next"`.
+func (h ResponseHeader) Link(links ...Link) ResponseHeader {
+	for _, l := range links {
+		h.header.Add("Link", "<"+l.URL+">; rel=\""+l.Rel+"\"")
+	}
+	return h
+}
+
+// Pagination adds the first, prev, next and last links of the page of the pages
+// from 1 to last, of the URL with the page numbers in the query param.
+func (h ResponseHeader) Pagination(u *url.URL, param string, page, last int) ResponseHeader {
+	link := func(rel string, n int) Link {
+		ref := *u
+		query := ref.Query()
+		query.Set(param, strconv.Itoa(n))
+		ref.RawQuery = query.Encode()
+		return Link{URL: ref.String(), Rel: rel}
+	}
+	links := []Link{link("first", 1)}
+	if page > 1 {
+		links = append(links, link("prev", page-1))
+	}
+	if page < last {
+		links = append(links, link("next", page+1))
+	}
+	return h.Link(append(links, link("last", last))...)
+}
+
+// ContentDisposition sets the Content-Disposition header of the disposition,
+// e.g. "attachment", and the filename. The filename is encoded by RFC 5987 as
+// filename* if it is not ASCII, with an ASCII filename as the fallback.
+func (h ResponseHeader) ContentDisposition(disposition, filename string) ResponseHeader {
+	var (
+		fallback strings.Builder
+		ascii    = true
+	)
+	for _, r := range filename {
+		switch {
+		case r > 0x7e || r < 0x20:
+			ascii = false
+			fallback.WriteByte('_')
+		case r == '"' || r == '\\':
+			fallback.WriteByte('\\')
+			fallback.WriteRune(r)
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+	value := disposition + "; filename=\"" + fallback.String() + "\""
+	if !ascii {
+		value += "; filename*=UTF-8''" + encodeExtValue(filename)
+	}
+	h.header.Set("Content-Disposition", value)
+	return h
+}
+
+// encodeExtValue percent-encodes the value of RFC 5987 except its attr-chars.
+func encodeExtValue(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0xf])
+	}
+	return b.String()
+}
+
+// RetryAfter sets the Retry-After header of the delay, in seconds rounded up.
+func (h ResponseHeader) RetryAfter(d time.Duration) ResponseHeader {
+	if d < 0 {
+		d = 0
+	}
+	h.header.Set("Retry-After", strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10))
+	return h
+}
+
+// RetryAfterDate sets the Retry-After header of the HTTP date.
+func (h ResponseHeader) RetryAfterDate(t time.Time) ResponseHeader {
+	h.header.Set("Retry-After", t.UTC().Format(http.TimeFormat))
+	return h
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResponseHeader(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").GET("/files", func(ctx Context) error {
+		u, _ := url.Parse("https://example.com/files?sort=name")
+		ctx.ResponseHeader().
+			CacheControl(CachePrivate, CacheMaxAge(90*time.Second), CacheStaleIfError(-time.Second)).
+			Pagination(u, "page", 2, 3).
+			ContentDisposition("attachment", `résumé "v1".pdf`).
+			RetryAfter(1500 * time.Millisecond)
+		return ctx.String(http.StatusOK, "ok")
+	})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files", nil))
+	h := rec.Header()
+	if v := h.Get("Cache-Control"); v != "private, max-age=90, stale-if-error=0" {
+		t.Errorf("unexpected Cache-Control %q", v)
+	}
+	links := []string{
+		`<https://example.com/files?page=1&sort=name>; rel="first"`,
+		`<https://example.com/files?page=1&sort=name>; rel="prev"`,
+		`<https://example.com/files?page=3&sort=name>; rel="next"`,
+		`<https://example.com/files?page=3&sort=name>; rel="last"`,
+	}
+	if v := h.Values("Link"); !reflect.DeepEqual(v, links) {
+		t.Errorf("unexpected Link %q", v)
+	}
+	if v := h.Get("Content-Disposition"); v != `attachment; filename="r_sum_ \"v1\".pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9%20%22v1%22.pdf` {
+		t.Errorf("unexpected Content-Disposition %q", v)
+	}
+	if v := h.Get("Retry-After"); v != "2" {
+		t.Errorf("unexpected Retry-After %q", v)
+	}
+
+	header := http.Header{}
+	NewResponseHeader(header).ContentDisposition("inline", "report.pdf").RetryAfterDate(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	if v := header.Get("Content-Disposition"); v != `inline; filename="report.pdf"` {
+		t.Errorf("unexpected Content-Disposition %q", v)
+	}
+	if v := header.Get("Retry-After"); v != "Tue, 02 Jan 2024 03:04:05 GMT" {
+		t.Errorf("unexpected Retry-After %q", v)
+	}
+}
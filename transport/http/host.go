@@ -0,0 +1,81 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/matcher"
+)
+
+// routeMatch is the host and the headers matched by the routes of a router of
+// Server.Host or Server.Header, whose middleware are independent of the ones
+// of the server.
+type routeMatch struct {
+	host       string
+	headers    []string
+	middleware matcher.Matcher
+}
+
+func (m *routeMatch) String() string {
+	pairs := make([]string, 0, len(m.headers)/2)
+	for i := 0; i+1 < len(m.headers); i += 2 {
+		pairs = append(pairs, m.headers[i]+"="+m.headers[i+1])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Host returns the router of the routes of the requests of the host, e.g.
+// "api.example.com" or "{tenant}.example.com", so that one listener serves
+// several virtual APIs:
+//
+//	api := srv.Host("api.example.com")
+//	api.Use("/*", auth.Server())
+//	v1.RegisterGreeterHTTPServerTo(api, greeter)
+//
+// The middleware of the router are registered by Router.Use, the ones of the
+// server are not applied to its routes.
+func (s *Server) Host(host string, filters ...FilterFunc) *Router {
+	r := newRouter("", s, filters...)
+	r.match = &routeMatch{host: host, middleware: matcher.New()}
+	return r
+}
+
+// Header returns the router of the routes of the requests with the header
+// value, e.g. of "X-Api-Version", whose middleware are independent like the
+// ones of Host.
+func (s *Server) Header(key, val string, filters ...FilterFunc) *Router {
+	r := newRouter("", s, filters...)
+	r.match = &routeMatch{headers: []string{key, val}, middleware: matcher.New()}
+	return r
+}
+
+// Header returns the group of the router whose routes match the header value
+// too, e.g. of the host of Server.Host, sharing the middleware of the router.
+func (r *Router) Header(key, val string, filters ...FilterFunc) *Router {
+	nr := r.Group("", filters...)
+	m := &routeMatch{headers: []string{key, val}, middleware: r.middleware()}
+	if r.match != nil {
+		m.host = r.match.host
+		m.headers = append(append([]string{}, r.match.headers...), key, val)
+	}
+	nr.match = m
+	return nr
+}
+
+// Use uses a service middleware with selector of the routes of the router of
+// Server.Host or Server.Header, otherwise it is the middleware of the server.
+func (r *Router) Use(selector string, m ...middleware.Middleware) {
+	if r.match == nil {
+		r.srv.Use(selector, m...)
+		return
+	}
+	r.match.middleware.Add(selector, m...)
+}
+
+// middleware returns the middleware matcher of the routes of the router.
+func (r *Router) middleware() matcher.Matcher {
+	if r.match != nil {
+		return r.match.middleware
+	}
+	return r.srv.middleware
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+func tagMiddleware(tag string) middleware.Middleware {
+	return func(h middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			reply, err := h(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return reply.(string) + "+" + tag, nil
+		}
+	}
+}
+
+func TestHost(t *testing.T) {
+	srv := NewServer(Middleware(tagMiddleware("server")))
+	hello := func(name string) HandlerFunc {
+		return func(ctx Context) error {
+			reply, err := ctx.Middleware(func(context.Context, interface{}) (interface{}, error) {
+				return name, nil
+			})(ctx, nil)
+			if err != nil {
+				return err
+			}
+			return ctx.String(http.StatusOK, reply.(string))
+		}
+	}
+	api := srv.Host("api.example.com")
+	api.Use("/*", tagMiddleware("api"))
+	api.GET("/hello", hello("api"))
+	admin := srv.Host("{tenant}.admin.example.com")
+	admin.Group("/v1").GET("/hello", hello("admin"))
+	v2 := srv.Header("X-Api-Version", "v2")
+	v2.Use("/hello", tagMiddleware("v2"))
+	v2.GET("/hello", hello("v2"))
+	canary := api.Header("X-Canary", "1")
+	canary.GET("/hello", hello("canary"))
+	srv.Route("/").GET("/hello", hello("default"))
+
+	tests := []struct {
+		host   string
+		header http.Header
+		path   string
+		want   string
+	}{
+		{"api.example.com", nil, "/hello", "api+api"},
+		{"api.example.com", http.Header{"X-Canary": {"1"}}, "/hello", "api+api"},
+		{"foo.admin.example.com", nil, "/v1/hello", "admin"},
+		{"example.com", http.Header{"X-Api-Version": {"v2"}}, "/hello", "v2+v2"},
+		{"example.com", nil, "/hello", "default+server"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "http://"+test.host+test.path, nil)
+		for k, v := range test.header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if got := w.Body.String(); got != test.want {
+			t.Errorf("%s%s: expected %q got %q", test.host, test.path, test.want, got)
+		}
+	}
+
+	err := srv.Validate()
+	if err == nil || !strings.Contains(err.Error(), "GET api.example.com/hello shadows GET api.example.com/hello [X-Canary=1]") {
+		t.Errorf("expected the canary route shadowed, got %v", err)
+	}
+	if strings.Contains(err.Error(), "default") || strings.Count(err.Error(), ";") != 0 {
+		t.Errorf("unexpected conflicts %v", err)
+	}
+	for _, e := range srv.Routes() {
+		if e.Host == "api.example.com" && e.Headers == "" && len(e.Selectors) != 1 {
+			t.Errorf("expected the selectors of the host, got %v", e.Selectors)
+		}
+	}
+}
+
+func TestHostWithoutMatchMux(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"), RouterMux(struct{ Mux }{newGorillaMux(true)}))
+	srv.Host("api.example.com").GET("/users", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "ok")
+	})
+	if err := srv.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "does not match the hosts") {
+		_ = srv.Stop(context.Background())
+		t.Errorf("expected the error of the router mux, got %v", err)
+	}
+}
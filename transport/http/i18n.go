@@ -0,0 +1,105 @@
+package http
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// Catalog is the catalog of the localized messages of the error reasons.
+type Catalog interface {
+	// Message returns the message of the reason in the language, e.g. "zh-cn",
+	// false if there is none.
+	Message(lang, reason string) (string, bool)
+}
+
+// Messages is the Catalog of the messages keyed by the lower case languages
+// and the reasons, e.g. {"zh": {"USER_NOT_FOUND": "用户 {name} 不存在"}}.
+type Messages map[string]map[string]string
+
+// Message returns the message of the reason in the language.
+func (m Messages) Message(lang, reason string) (string, bool) {
+	msg, ok := m[lang][reason]
+	return msg, ok
+}
+
+// ErrorCatalog with the catalog of the messages of the errors replied in the
+// languages of the Accept-Language header, or of their base languages, e.g.
+// "zh" of "zh-CN", the placeholders of the messages, e.g. "{name}", are the
+// metadata of the errors. The reasons, the codes and the metadata are kept,
+// and the errors of no message are replied as they are.
+func ErrorCatalog(c Catalog) ServerOption {
+	return func(s *Server) {
+		s.catalog = c
+	}
+}
+
+// localize returns the error encoder replying the messages of the catalog.
+func localize(c Catalog, enc EncodeErrorFunc) EncodeErrorFunc {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		se := errors.FromError(err)
+		w.Header().Add("Vary", "Accept-Language")
+		for _, lang := range acceptLanguages(r.Header.Get("Accept-Language")) {
+			msg, ok := c.Message(lang, se.Reason)
+			if !ok {
+				continue
+			}
+			se = errors.Clone(se)
+			se.Message = expand(msg, se.Metadata)
+			w.Header().Set("Content-Language", lang)
+			enc(w, r, se)
+			return
+		}
+		enc(w, r, err)
+	}
+}
+
+// acceptLanguages returns the lower case languages of the Accept-Language
+// header by the quality, each followed by its base language.
+func acceptLanguages(header string) []string {
+	type lang struct {
+		tag string
+		q   float64
+	}
+	var langs []lang
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			if f, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			langs = append(langs, lang{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+	tags := make([]string, 0, len(langs)*2) //nolint:gomnd
+	for _, l := range langs {
+		tags = append(tags, l.tag)
+		if base, _, ok := strings.Cut(l.tag, "-"); ok {
+			tags = append(tags, base)
+		}
+	}
+	return tags
+}
+
+// expand replaces the "{key}" placeholders of the message by the metadata.
+func expand(msg string, md map[string]string) string {
+	if len(md) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+	pairs := make([]string, 0, len(md)*2) //nolint:gomnd
+	for k, v := range md {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestAcceptLanguages(t *testing.T) {
+	got := acceptLanguages("fr;q=0.5, zh-CN, en;q=0.8, *;q=0.1, de;q=0")
+	want := []string{"zh-cn", "zh", "en", "fr"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+}
+
+func TestErrorCatalog(t *testing.T) {
+	srv := NewServer(ErrorCatalog(Messages{
+		"zh":    {"USER_NOT_FOUND": "用户 {name} 不存在"},
+		"fr-ca": {"USER_NOT_FOUND": "utilisateur {name} introuvable"},
+	}))
+	srv.Route("/").GET("/users/{name}", func(ctx Context) error {
+		return errors.NotFound("USER_NOT_FOUND", "user not found").WithMetadata(map[string]string{"name": ctx.Vars().Get("name")})
+	})
+	tests := []struct {
+		accept  string
+		message string
+		lang    string
+	}{
+		{"", "user not found", ""},
+		{"de", "user not found", ""},
+		{"zh-CN,zh;q=0.9", "用户 kratos 不存在", "zh"},
+		{"fr-CA, zh;q=0.5", "utilisateur kratos introuvable", "fr-ca"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/users/kratos", nil)
+		req.Header.Set("Accept-Language", test.accept)
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		var se errors.Error
+		if err := json.Unmarshal(res.Body.Bytes(), &se); err != nil {
+			t.Fatal(err)
+		}
+		if res.Code != http.StatusNotFound || se.Reason != "USER_NOT_FOUND" || se.Message != test.message || se.Metadata["name"] != "kratos" {
+			t.Errorf("%q: unexpected reply %d %s", test.accept, res.Code, res.Body)
+		}
+		if lang := res.Header().Get("Content-Language"); lang != test.lang {
+			t.Errorf("%q: expected the language %q got %q", test.accept, test.lang, lang)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Package idempotency replays the first response of the unsafe requests with
+// the same Idempotency-Key header, so that the retried calls of the clients,
+// e.g. to create a payment, take effect exactly once.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const (
+	// Header is the request header holding the idempotency key.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set on the replayed responses.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	reason = "IDEMPOTENCY"
+)
+
+var (
+	// ErrInProgress is returned when a request with the same key is in progress.
+	ErrInProgress = errors.Conflict(reason, "a request with the same idempotency key is in progress")
+	// ErrKeyReused is returned when the key is reused by a request of another method, path or body.
+	ErrKeyReused = errors.New(http.StatusUnprocessableEntity, reason, "the idempotency key is reused by a different request")
+)
+
+// Response is a stored response.
+type Response struct {
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+	Fingerprint string      `json:"fingerprint"`
+}
+
+// Store stores the responses of the idempotency keys.
+type Store interface {
+	// Lock marks the key in progress for the ttl and returns nil if it has no
+	// response, or returns the stored response, or ErrInProgress if the key is
+	// already in progress.
+	Lock(ctx context.Context, key string, ttl time.Duration) (*Response, error)
+	// Save stores the response of the key for the ttl.
+	Save(ctx context.Context, key string, res *Response, ttl time.Duration) error
+	// Unlock removes the key in progress so that the request can be retried.
+	Unlock(ctx context.Context, key string) error
+}
+
+// Option is idempotency option.
+type Option func(*options)
+
+type options struct {
+	store       Store
+	ttl         time.Duration
+	lockTimeout time.Duration
+	methods     map[string]struct{}
+}
+
+// WithStore with the response store, default is a memory store.
+func WithStore(s Store) Option {
+	return func(o *options) {
+		o.store = s
+	}
+}
+
+// WithTTL with the duration the responses are replayed for, default is 24h.
+func WithTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.ttl = d
+	}
+}
+
+// WithLockTimeout with the max duration a key is in progress, default is 1m.
+func WithLockTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.lockTimeout = d
+	}
+}
+
+// WithMethods with the methods the keys are recognized on, default are POST and PATCH.
+func WithMethods(methods ...string) Option {
+	return func(o *options) {
+		o.methods = make(map[string]struct{}, len(methods))
+		for _, m := range methods {
+			o.methods[m] = struct{}{}
+		}
+	}
+}
+
+// Filter returns an HTTP server filter which stores the first response of the
+// requests with an Idempotency-Key header and replays it for the duplicates within
+// the ttl. The concurrent duplicates are rejected with 409, and the server errors
+// are not stored so that the requests can be retried.
+func Filter(opts ...Option) khttp.FilterFunc {
+	o := &options{
+		ttl:         24 * time.Hour,
+		lockTimeout: time.Minute,
+		methods:     map[string]struct{}{http.MethodPost: {}, http.MethodPatch: {}},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.store == nil {
+		o.store = NewMemoryStore()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			key := req.Header.Get(Header)
+			if _, ok := o.methods[req.Method]; !ok || key == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+			fingerprint, err := requestFingerprint(req)
+			if err != nil {
+				khttp.DefaultErrorEncoder(w, req, errors.BadRequest("CODEC", err.Error()))
+				return
+			}
+			ctx := req.Context()
+			res, err := o.store.Lock(ctx, key, o.lockTimeout)
+			if err != nil {
+				khttp.DefaultErrorEncoder(w, req, err)
+				return
+			}
+			if res != nil {
+				if res.Fingerprint != fingerprint {
+					khttp.DefaultErrorEncoder(w, req, ErrKeyReused)
+					return
+				}
+				replay(w, res)
+				return
+			}
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			saved := false
+			defer func() {
+				if !saved {
+					if err := o.store.Unlock(context.Background(), key); err != nil {
+						log.Errorf("[Idempotency] failed to unlock the key %s: %v", key, err)
+					}
+				}
+			}()
+			next.ServeHTTP(rw, req)
+			if rw.status >= http.StatusInternalServerError {
+				return
+			}
+			res = &Response{
+				Status:      rw.status,
+				Header:      w.Header().Clone(),
+				Body:        rw.body.Bytes(),
+				Fingerprint: fingerprint,
+			}
+			if err := o.store.Save(context.Background(), key, res, o.ttl); err != nil {
+				log.Errorf("[Idempotency] failed to save the response of the key %s: %v", key, err)
+				return
+			}
+			saved = true
+		})
+	}
+}
+
+func replay(w http.ResponseWriter, res *Response) {
+	header := w.Header()
+	for k, v := range res.Header {
+		header[k] = v
+	}
+	header.Set(ReplayedHeader, "true")
+	w.WriteHeader(res.Status)
+	_, _ = w.Write(res.Body)
+}
+
+// requestFingerprint returns the hash of the request method, path and body,
+// the body is reset to be read again.
+func requestFingerprint(req *http.Request) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(req.Method + "\n" + req.URL.RequestURI() + "\n"))
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		h.Write(body)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wrote {
+		w.wrote = true
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
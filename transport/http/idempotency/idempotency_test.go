@@ -0,0 +1,145 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/kv"
+)
+
+func newHandler(calls *int32, started, release chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+		if started != nil {
+			started <- struct{}{}
+			<-release
+		}
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":` + strconv.Itoa(int(n)) + `}`))
+	})
+}
+
+func do(h http.Handler, method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestFilter(t *testing.T) {
+	var calls int32
+	h := Filter()(newHandler(&calls, nil, nil))
+
+	first := do(h, http.MethodPost, "/payments", "k1", `{"amount":1}`)
+	if first.Code != http.StatusCreated || first.Body.String() != `{"id":1}` {
+		t.Fatalf("unexpected response %d %s", first.Code, first.Body.String())
+	}
+	replayed := do(h, http.MethodPost, "/payments", "k1", `{"amount":1}`)
+	if replayed.Code != http.StatusCreated || replayed.Body.String() != `{"id":1}` || replayed.Header().Get(ReplayedHeader) != "true" {
+		t.Errorf("unexpected replay %d %s %v", replayed.Code, replayed.Body.String(), replayed.Header())
+	}
+	if replayed.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected the header replayed got %v", replayed.Header())
+	}
+	if w := do(h, http.MethodPost, "/payments", "k1", `{"amount":2}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for the reused key got %d", w.Code)
+	}
+	if w := do(h, http.MethodPost, "/payments", "k2", `{"amount":1}`); w.Body.String() != `{"id":2}` {
+		t.Errorf("expected a new response for another key got %s", w.Body.String())
+	}
+	_ = do(h, http.MethodPost, "/payments", "", `{"amount":1}`)
+	_ = do(h, http.MethodPut, "/payments", "k1", `{"amount":1}`)
+	if calls != 4 {
+		t.Errorf("expected the requests without key or of other methods handled, got %d calls", calls)
+	}
+
+	_ = do(h, http.MethodPost, "/fail", "k3", "")
+	_ = do(h, http.MethodPost, "/fail", "k3", "")
+	if calls != 6 {
+		t.Errorf("expected the server errors not to be stored, got %d calls", calls)
+	}
+}
+
+func TestFilterInProgress(t *testing.T) {
+	var calls int32
+	started, release := make(chan struct{}), make(chan struct{})
+	h := Filter()(newHandler(&calls, started, release))
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- do(h, http.MethodPost, "/payments", "k1", "") }()
+	<-started
+
+	w := do(h, http.MethodPost, "/payments", "k1", "")
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for the concurrent request got %d", w.Code)
+	}
+	close(release)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Errorf("unexpected response %d", w.Code)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if res, err := s.Lock(ctx, "k", time.Second); res != nil || err != nil {
+		t.Fatalf("expected the key locked got %v %v", res, err)
+	}
+	if _, err := s.Lock(ctx, "k", time.Second); err != ErrInProgress {
+		t.Errorf("expected %v got %v", ErrInProgress, err)
+	}
+	now = now.Add(2 * time.Second)
+	if _, err := s.Lock(ctx, "k", time.Second); err != nil {
+		t.Errorf("expected the expired lock to be taken over got %v", err)
+	}
+	_ = s.Save(ctx, "k", &Response{Status: http.StatusOK}, time.Minute)
+	_ = s.Unlock(ctx, "k")
+	if res, _ := s.Lock(ctx, "k", time.Second); res == nil || res.Status != http.StatusOK {
+		t.Errorf("expected the saved response got %v", res)
+	}
+	now = now.Add(2 * time.Minute)
+	if res, err := s.Lock(ctx, "k", time.Second); res != nil || err != nil || len(s.entries) != 1 {
+		t.Errorf("expected the response to expire got %v %v", res, err)
+	}
+}
+
+func TestKVStore(t *testing.T) {
+	m := kv.NewMemory()
+	s := NewKVStore(m, "idempotency:")
+	ctx := context.Background()
+
+	if res, err := s.Lock(ctx, "k", time.Minute); res != nil || err != nil {
+		t.Fatalf("expected the key locked got %v %v", res, err)
+	}
+	if _, err := m.Get(ctx, "idempotency:k"); err != nil {
+		t.Errorf("expected the prefixed key got %v", err)
+	}
+	if _, err := s.Lock(ctx, "k", time.Minute); err != ErrInProgress {
+		t.Errorf("expected %v got %v", ErrInProgress, err)
+	}
+	_ = s.Unlock(ctx, "k")
+	if _, err := s.Lock(ctx, "k", time.Minute); err != nil {
+		t.Errorf("expected the unlocked key locked again got %v", err)
+	}
+	_ = s.Save(ctx, "k", &Response{Status: http.StatusCreated, Body: []byte(`{"id":1}`)}, time.Minute)
+	_ = s.Unlock(ctx, "k")
+	if res, err := s.Lock(ctx, "k", time.Minute); err != nil || res == nil || res.Status != http.StatusCreated || string(res.Body) != `{"id":1}` {
+		t.Errorf("expected the saved response got %v %v", res, err)
+	}
+}
@@ -0,0 +1,69 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/kv"
+)
+
+// locked is the value of the keys in progress.
+var locked = []byte("\x00locked")
+
+// KVStore is a Store of a kv.Store, e.g. of redis or memcached, the responses
+// are stored as JSON.
+type KVStore struct {
+	store  kv.Store
+	prefix string
+}
+
+var _ Store = (*KVStore)(nil)
+
+// NewKVStore creates a store of the kv store whose keys have the prefix, e.g. "idempotency:".
+func NewKVStore(store kv.Store, prefix string) *KVStore {
+	return &KVStore{store: store, prefix: prefix}
+}
+
+// Lock marks the key in progress if it has no response.
+func (s *KVStore) Lock(ctx context.Context, key string, ttl time.Duration) (*Response, error) {
+	key = s.prefix + key
+	for {
+		ok, err := s.store.SetNX(ctx, key, locked, ttl)
+		if err != nil || ok {
+			return nil, err
+		}
+		data, err := s.store.Get(ctx, key)
+		if errors.Is(err, kv.ErrNotFound) {
+			// the key expired or was unlocked just now.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if string(data) == string(locked) {
+			return nil, ErrInProgress
+		}
+		res := new(Response)
+		if err = json.Unmarshal(data, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+}
+
+// Save stores the response of the key.
+func (s *KVStore) Save(ctx context.Context, key string, res *Response, ttl time.Duration) error {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return s.store.Set(ctx, s.prefix+key, data, ttl)
+}
+
+// Unlock removes the key if it is in progress.
+func (s *KVStore) Unlock(ctx context.Context, key string) error {
+	_, err := s.store.CompareAndDelete(ctx, s.prefix+key, locked)
+	return err
+}
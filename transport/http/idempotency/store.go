@@ -0,0 +1,70 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	res     *Response
+	expires time.Time
+}
+
+// MemoryStore is a Store in the process memory, the expired keys are
+// swept at most once a minute when a key is locked.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]entry
+	swept   time.Time
+	now     func() time.Time
+}
+
+// NewMemoryStore creates a memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]entry),
+		now:     time.Now,
+	}
+}
+
+// Lock marks the key in progress if it has no response.
+func (s *MemoryStore) Lock(_ context.Context, key string, ttl time.Duration) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.Sub(s.swept) >= time.Minute {
+		s.swept = now
+		for k, e := range s.entries {
+			if !now.Before(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+	}
+	if e, ok := s.entries[key]; ok && now.Before(e.expires) {
+		if e.res == nil {
+			return nil, ErrInProgress
+		}
+		return e.res, nil
+	}
+	s.entries[key] = entry{expires: now.Add(ttl)}
+	return nil, nil
+}
+
+// Save stores the response of the key.
+func (s *MemoryStore) Save(_ context.Context, key string, res *Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = entry{res: res, expires: s.now().Add(ttl)}
+	return nil
+}
+
+// Unlock removes the key if it is in progress.
+func (s *MemoryStore) Unlock(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok && e.res == nil {
+		delete(s.entries, key)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	kjson "github.com/go-kratos/kratos/v2/encoding/json"
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// The error codes of JSON-RPC 2.0, the codes of the kratos errors are their
+// HTTP status codes, e.g. 404.
+const (
+	JSONRPCParseError     = -32700
+	JSONRPCInvalidRequest = -32600
+	JSONRPCMethodNotFound = -32601
+	JSONRPCInvalidParams  = -32602
+	JSONRPCInternalError  = -32603
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// JSONRPC is the JSON-RPC 2.0 router of a path, of which the calls are
+// through the middleware of the server matched by the method names.
+type JSONRPC struct {
+	mu      sync.RWMutex
+	methods map[string]reflect.Value
+}
+
+type jsonrpcRequest struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type jsonrpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// JSONRPCError is the error object of JSON-RPC 2.0, whose data of the kratos
+// errors is of their reasons and metadata.
+type JSONRPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type jsonrpcErrorData struct {
+	Reason   string            `json:"reason,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// JSONRPC returns the JSON-RPC 2.0 router of the POST requests of the path,
+// of the batches and the notifications, see JSONRPC.Register.
+func (s *Server) JSONRPC(path string, filters ...FilterFunc) *JSONRPC {
+	rpc := &JSONRPC{methods: make(map[string]reflect.Value)}
+	s.Route("/").POST(path, rpc.serve, filters...)
+	return rpc
+}
+
+// Register registers the func of the method, of the signature
+// func(context.Context, *Request) (*Reply, error), whose params are decoded
+// into the request by the json codec, e.g. of the proto messages. It panics
+// if the func is not of the signature.
+func (r *JSONRPC) Register(method string, fn interface{}) {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 2 || t.NumOut() != 2 ||
+		!t.In(0).Implements(contextType) || t.Out(1) != errorType {
+		panic(fmt.Sprintf("http: invalid JSON-RPC method %s of %s", method, t))
+	}
+	r.mu.Lock()
+	r.methods[method] = v
+	r.mu.Unlock()
+}
+
+func (r *JSONRPC) serve(ctx Context) error {
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return err
+	}
+	body = bytes.TrimSpace(body)
+	var res interface{}
+	if len(body) > 0 && body[0] == '[' {
+		var reqs []json.RawMessage
+		if err = json.Unmarshal(body, &reqs); err != nil {
+			res = jsonrpcFailure(nil, JSONRPCParseError, "parse error")
+		} else if len(reqs) == 0 {
+			res = jsonrpcFailure(nil, JSONRPCInvalidRequest, "invalid request")
+		} else {
+			replies := make([]*jsonrpcResponse, 0, len(reqs))
+			for _, raw := range reqs {
+				if reply := r.call(ctx, raw); reply != nil {
+					replies = append(replies, reply)
+				}
+			}
+			if len(replies) > 0 {
+				res = replies
+			}
+		}
+	} else if reply := r.call(ctx, body); reply != nil {
+		res = reply
+	}
+	w := ctx.Response()
+	if res == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(data)
+	return err
+}
+
+// call calls the method of the request, the reply is nil of a notification.
+func (r *JSONRPC) call(ctx Context, raw json.RawMessage) *jsonrpcResponse {
+	var req jsonrpcRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		if _, ok := err.(*json.SyntaxError); ok {
+			return jsonrpcFailure(nil, JSONRPCParseError, "parse error")
+		}
+		return jsonrpcFailure(nil, JSONRPCInvalidRequest, "invalid request")
+	}
+	if req.Version != "2.0" || req.Method == "" {
+		return jsonrpcFailure(req.ID, JSONRPCInvalidRequest, "invalid request")
+	}
+	notification := len(req.ID) == 0
+	reply := r.invoke(ctx, &req)
+	if notification {
+		return nil
+	}
+	reply.ID = req.ID
+	return reply
+}
+
+func (r *JSONRPC) invoke(ctx Context, req *jsonrpcRequest) *jsonrpcResponse {
+	r.mu.RLock()
+	fn, ok := r.methods[req.Method]
+	r.mu.RUnlock()
+	if !ok {
+		return jsonrpcFailure(nil, JSONRPCMethodNotFound, "method not found")
+	}
+	inType := fn.Type().In(1)
+	in := reflect.New(inType)
+	if inType.Kind() == reflect.Ptr {
+		in.Elem().Set(reflect.New(inType.Elem()))
+		in = in.Elem()
+	} else {
+		in = in.Elem()
+	}
+	codec := encoding.GetCodec(kjson.Name)
+	if len(req.Params) > 0 && !bytes.Equal(req.Params, []byte("null")) {
+		if req.Params[0] != '{' {
+			return jsonrpcFailure(nil, JSONRPCInvalidParams, "invalid params: expected an object")
+		}
+		target := in.Interface()
+		if inType.Kind() != reflect.Ptr {
+			target = in.Addr().Interface()
+		}
+		if err := codec.Unmarshal(req.Params, target); err != nil {
+			return jsonrpcFailure(nil, JSONRPCInvalidParams, "invalid params: "+err.Error())
+		}
+	}
+	SetOperation(ctx, req.Method)
+	h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := fn.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(req)})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return nil, err
+		}
+		return out[0].Interface(), nil
+	})
+	out, err := h(ctx, in.Interface())
+	if err != nil {
+		se := errors.FromError(err)
+		return &jsonrpcResponse{Version: "2.0", Error: &JSONRPCError{
+			Code:    int(se.Code),
+			Message: se.Message,
+			Data:    jsonrpcErrorData{Reason: se.Reason, Metadata: se.Metadata},
+		}}
+	}
+	result, err := codec.Marshal(out)
+	if err != nil {
+		return jsonrpcFailure(nil, JSONRPCInternalError, "internal error: "+err.Error())
+	}
+	return &jsonrpcResponse{Version: "2.0", Result: result}
+}
+
+func jsonrpcFailure(id json.RawMessage, code int, message string) *jsonrpcResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &jsonrpcResponse{Version: "2.0", Error: &JSONRPCError{Code: code, Message: message}, ID: id}
+}
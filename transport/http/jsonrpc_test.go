@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type sumArgs struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+type sumReply struct {
+	Sum int `json:"sum"`
+}
+
+func TestJSONRPC(t *testing.T) {
+	var operations []string
+	srv := NewServer(Middleware(func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				operations = append(operations, tr.Operation())
+			}
+			return handler(ctx, req)
+		}
+	}))
+	rpc := srv.JSONRPC("/rpc")
+	notified := 0
+	rpc.Register("sum", func(ctx context.Context, args *sumArgs) (*sumReply, error) {
+		return &sumReply{Sum: args.A + args.B}, nil
+	})
+	rpc.Register("notify", func(ctx context.Context, args *sumArgs) (*sumReply, error) {
+		notified++
+		return nil, nil
+	})
+	rpc.Register("fail", func(ctx context.Context, args *sumArgs) (*sumReply, error) {
+		return nil, errors.NotFound("USER_NOT_FOUND", "user not found").WithMetadata(map[string]string{"id": "1"})
+	})
+
+	call := func(body string) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Code, strings.TrimSpace(w.Body.String())
+	}
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"jsonrpc":"2.0","method":"sum","params":{"a":1,"b":2},"id":1}`, `{"jsonrpc":"2.0","result":{"sum":3},"id":1}`},
+		{`{"jsonrpc":"2.0","method":"fail","id":"x"}`, `{"jsonrpc":"2.0","error":{"code":404,"message":"user not found","data":{"reason":"USER_NOT_FOUND","metadata":{"id":"1"}}},"id":"x"}`},
+		{`{"jsonrpc":"2.0","method":"unknown","id":2}`, `{"jsonrpc":"2.0","error":{"code":-32601,"message":"method not found"},"id":2}`},
+		{`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":3}`, `{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid params: expected an object"},"id":3}`},
+		{`{"jsonrpc":"1.0","method":"sum","id":4}`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":4}`},
+		{`{"jsonrpc":`, `{"jsonrpc":"2.0","error":{"code":-32700,"message":"parse error"},"id":null}`},
+		{`[]`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":null}`},
+		{`[{"jsonrpc":"2.0","method":"sum","params":{"a":1},"id":1},{"jsonrpc":"2.0","method":"notify"},1]`,
+			`[{"jsonrpc":"2.0","result":{"sum":1},"id":1},{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":null}]`},
+	}
+	for _, test := range tests {
+		code, body := call(test.body)
+		if code != http.StatusOK || body != test.want {
+			t.Errorf("%s: expected %s got %d %s", test.body, test.want, code, body)
+		}
+		if !json.Valid([]byte(body)) {
+			t.Errorf("%s: invalid JSON %s", test.body, body)
+		}
+	}
+	if code, body := call(`{"jsonrpc":"2.0","method":"notify","params":{}}`); code != http.StatusNoContent || body != "" {
+		t.Errorf("expected no response of the notification, got %d %s", code, body)
+	}
+	if notified != 2 {
+		t.Errorf("expected the notifications called, got %d", notified)
+	}
+	if len(operations) == 0 || operations[0] != "sum" {
+		t.Errorf("unexpected operations %v", operations)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected the invalid method panics")
+		}
+	}()
+	rpc.Register("invalid", func(args *sumArgs) error { return nil })
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIPLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newIPLimiter(1, 2)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if !l.allow("10.0.0.1:1234") {
+			t.Fatalf("expected request %d allowed", i)
+		}
+	}
+	if l.allow("10.0.0.1:5678") {
+		t.Error("expected request over burst rejected")
+	}
+	if !l.allow("10.0.0.2:1234") {
+		t.Error("expected other ip allowed")
+	}
+	now = now.Add(time.Second)
+	if !l.allow("10.0.0.1:1234") {
+		t.Error("expected request allowed after refill")
+	}
+
+	now = now.Add(2 * time.Minute)
+	l.allow("10.0.0.3:1234")
+	if _, ok := l.buckets["10.0.0.1"]; ok {
+		t.Error("expected idle bucket swept")
+	}
+}
+
+func TestRateLimitPerIP(t *testing.T) {
+	srv := NewServer(RateLimitPerIP(1, 1))
+	srv.HandleFunc("/limit", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	codes := make([]int, 0, 2)
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/limit", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		codes = append(codes, res.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+		t.Errorf("expected [200 429] got %v", codes)
+	}
+}
+
+func TestMaxConnections(t *testing.T) {
+	srv := NewServer(MaxConnections(1))
+	srv.HandleFunc("/conn", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	conn, err := net.Dial("tcp", e.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	client := &http.Client{Timeout: 200 * time.Millisecond, Transport: &http.Transport{DisableKeepAlives: true}}
+	if resp, err := client.Get("http://" + e.Host + "/conn"); err == nil {
+		resp.Body.Close()
+		t.Fatal("expected request to wait for a free connection")
+	}
+	conn.Close()
+	client.Timeout = time.Second
+	resp, err := client.Get("http://" + e.Host + "/conn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestMaxConcurrentStreams(t *testing.T) {
+	srv := NewServer(MaxConcurrentStreams(8), TLSConfig(&tls.Config{}))
+	if srv.err != nil {
+		t.Fatal(srv.err)
+	}
+	if _, ok := srv.TLSNextProto["h2"]; !ok {
+		t.Error("expected http2 configured")
+	}
+}
@@ -0,0 +1,162 @@
+// Package mirror provides an HTTP server filter mirroring a percentage of the
+// requests to a shadow endpoint, e.g. a new version of the service validated
+// against the production traffic. The mirrored requests are fire and forget,
+// their responses are discarded.
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const (
+	// Header is set on the mirrored requests, so the shadow can tell them apart.
+	Header = "X-Mirrored"
+	// OptOutHeader of a request opts it out of the mirroring.
+	OptOutHeader = "X-Mirror-Opt-Out"
+)
+
+// Option is mirror option.
+type Option func(*options)
+
+type options struct {
+	percent       float64
+	client        *http.Client
+	optOut        string
+	maxBodySize   int64
+	maxConcurrent int
+}
+
+// WithPercent with the percentage of the requests mirrored, default is 100.
+func WithPercent(percent float64) Option {
+	return func(o *options) {
+		o.percent = percent
+	}
+}
+
+// WithClient with the HTTP client sending the mirrored requests, default has a 5s timeout.
+func WithClient(c *http.Client) Option {
+	return func(o *options) {
+		o.client = c
+	}
+}
+
+// WithOptOutHeader with the request header opting a request out of the mirroring,
+// default is OptOutHeader.
+func WithOptOutHeader(name string) Option {
+	return func(o *options) {
+		o.optOut = name
+	}
+}
+
+// WithMaxBodySize with the max size of the request bodies mirrored, the requests
+// with larger bodies are not mirrored, default is 1MB.
+func WithMaxBodySize(n int64) Option {
+	return func(o *options) {
+		o.maxBodySize = n
+	}
+}
+
+// WithMaxConcurrent with the max number of the mirrored requests in flight, the
+// requests are not mirrored beyond it, default is 100.
+func WithMaxConcurrent(n int) Option {
+	return func(o *options) {
+		o.maxConcurrent = n
+	}
+}
+
+// Filter returns an HTTP server filter mirroring the requests to the target,
+// e.g. http://shadow:8000, keeping their paths and queries.
+func Filter(target string, opts ...Option) khttp.FilterFunc {
+	o := &options{
+		percent:       100,
+		client:        &http.Client{Timeout: 5 * time.Second},
+		optOut:        OptOutHeader,
+		maxBodySize:   1 << 20,
+		maxConcurrent: 100,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	base, err := url.Parse(target)
+	if err != nil {
+		panic(err)
+	}
+	inflight := make(chan struct{}, o.maxConcurrent)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get(o.optOut) == "" && req.Header.Get(Header) == "" && rand.Float64()*100 < o.percent {
+				if body, ok := readBody(req, o.maxBodySize); ok {
+					select {
+					case inflight <- struct{}{}:
+						m := mirrored(req, base, body)
+						go func() {
+							defer func() { <-inflight }()
+							o.send(m)
+						}()
+					default:
+					}
+				}
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// readBody reads the request body up to the max size and restores it, it
+// reports false if the body is larger.
+func readBody(req *http.Request, max int64) ([]byte, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true
+	}
+	if req.ContentLength > max {
+		return nil, false
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, max+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+	if err != nil || int64(len(body)) > max {
+		return nil, false
+	}
+	return body, true
+}
+
+// mirrored returns the mirrored request, whose context is detached from the request.
+func mirrored(req *http.Request, base *url.URL, body []byte) *http.Request {
+	u := *base
+	u.Path = strings.TrimSuffix(base.Path, "/") + req.URL.Path
+	u.RawPath = ""
+	u.RawQuery = req.URL.RawQuery
+	m, _ := http.NewRequestWithContext(context.Background(), req.Method, u.String(), bytes.NewReader(body))
+	m.Header = req.Header.Clone()
+	for _, h := range []string{"Connection", "Keep-Alive", "Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade"} {
+		m.Header.Del(h)
+	}
+	m.Header.Set(Header, "true")
+	m.ContentLength = int64(len(body))
+	if len(body) == 0 {
+		m.Body = http.NoBody
+	}
+	return m
+}
+
+func (o *options) send(req *http.Request) {
+	res, err := o.client.Do(req)
+	if err != nil {
+		log.Debugf("[mirror] the mirrored request %s %s failed: %v", req.Method, req.URL, err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+}
@@ -0,0 +1,84 @@
+package mirror
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mirroredRequest struct {
+	method, uri, body, header string
+}
+
+func shadow(t *testing.T) (*httptest.Server, chan mirroredRequest) {
+	ch := make(chan mirroredRequest, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		ch <- mirroredRequest{req.Method, req.URL.RequestURI(), string(body), req.Header.Get(Header)}
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, ch
+}
+
+func TestFilter(t *testing.T) {
+	srv, ch := shadow(t)
+	var got string
+	h := Filter(srv.URL + "/shadow")(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		got = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/users?id=1", strings.NewReader(`{"name":"kratos"}`)))
+	if res.Code != http.StatusOK || got != `{"name":"kratos"}` {
+		t.Fatalf("unexpected primary response %d %q", res.Code, got)
+	}
+	select {
+	case m := <-ch:
+		if m.method != http.MethodPost || m.uri != "/shadow/users?id=1" || m.body != `{"name":"kratos"}` || m.header != "true" {
+			t.Errorf("unexpected mirrored request %+v", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the mirrored request")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(OptOutHeader, "1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	select {
+	case m := <-ch:
+		t.Errorf("expected no mirrored request, got %+v", m)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestFilterLimits(t *testing.T) {
+	srv, ch := shadow(t)
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	h := Filter(srv.URL, WithMaxBodySize(4), WithPercent(0))(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("1234")))
+	h = Filter(srv.URL, WithMaxBodySize(4))(h)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("123456789")))
+	select {
+	case m := <-ch:
+		t.Errorf("expected no mirrored request, got %+v", m)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if len(bodies) != 2 || bodies[0] != "1234" || bodies[1] != "123456789" {
+		t.Errorf("expected the bodies kept, got %v", bodies)
+	}
+}
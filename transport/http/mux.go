@@ -0,0 +1,144 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ MatchMux = (*gorillaMux)(nil)
+
+// Mux is the request multiplexer used as the router backend of the Server.
+// Handlers registered on a Mux are already wrapped by the server filter,
+// which records the path template of the route in the Transport. The errors
+// of the registrations, e.g. of the conflicting routes, are returned by the
+// Start of the Server.
+//
+// The order of the routes is up to the Mux, the default one of gorilla/mux
+// matches them in the order they are registered, the others may match the
+// header routes before the path routes.
+type Mux interface {
+	http.Handler
+	// Handle registers the handler for the given method and path template,
+	// an empty method matches any method.
+	Handle(method, path string, h http.Handler) error
+	// HandlePrefix registers the handler for the given path prefix.
+	HandlePrefix(prefix string, h http.Handler) error
+	// HandleHeader registers the handler for requests of the path prefix with
+	// the given header value, an empty prefix matches any path.
+	HandleHeader(prefix, key, val string, h http.Handler) error
+	// Vars returns the path variables of the matched route.
+	Vars(*http.Request) map[string]string
+	// NotFound sets the handler called when no route matches.
+	NotFound(http.Handler)
+	// MethodNotAllowed sets the handler called when the route matches but the method does not.
+	MethodNotAllowed(http.Handler)
+	// Walk walks all the registered routes, calling fn for each route.
+	Walk(fn WalkRouteFunc) error
+}
+
+// MatchMux is a Mux matching the routes by the hosts and the headers too,
+// which is required by the routers of Server.Host and Server.Header.
+type MatchMux interface {
+	Mux
+	// HandleMatch registers the handler for the given method and path template
+	// of the requests of the host, an empty host matches any host, and of the
+	// header pairs, e.g. "X-Api-Version", "v2".
+	HandleMatch(method, host, path string, headers []string, h http.Handler) error
+}
+
+// gorillaMux is the default Mux backed by gorilla/mux.
+type gorillaMux struct {
+	router *mux.Router
+}
+
+func newGorillaMux(strictSlash bool) *gorillaMux {
+	return &gorillaMux{router: mux.NewRouter().StrictSlash(strictSlash)}
+}
+
+func (m *gorillaMux) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	m.router.ServeHTTP(w, req)
+}
+
+func (m *gorillaMux) Handle(method, path string, h http.Handler) error {
+	route := m.router.Handle(path, h)
+	if method != "" {
+		route.Methods(method)
+	}
+	return route.GetError()
+}
+
+func (m *gorillaMux) HandleMatch(method, host, path string, headers []string, h http.Handler) error {
+	route := m.router.NewRoute()
+	if host != "" {
+		route.Host(host)
+	}
+	if len(headers) > 0 {
+		route.Headers(headers...)
+	}
+	route.Path(path).Handler(h)
+	if method != "" {
+		route.Methods(method)
+	}
+	return route.GetError()
+}
+
+func (m *gorillaMux) HandlePrefix(prefix string, h http.Handler) error {
+	return m.router.PathPrefix(prefix).Handler(h).GetError()
+}
+
+func (m *gorillaMux) HandleHeader(prefix, key, val string, h http.Handler) error {
+	route := m.router.Headers(key, val)
+	if prefix != "" {
+		route.PathPrefix(prefix)
+	}
+	return route.Handler(h).GetError()
+}
+
+func (m *gorillaMux) Vars(req *http.Request) map[string]string {
+	return mux.Vars(req)
+}
+
+func (m *gorillaMux) NotFound(h http.Handler) {
+	m.router.NotFoundHandler = h
+}
+
+func (m *gorillaMux) MethodNotAllowed(h http.Handler) {
+	m.router.MethodNotAllowedHandler = h
+}
+
+func (m *gorillaMux) Walk(fn WalkRouteFunc) error {
+	return m.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil // ignore no methods
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			if err := fn(RouteInfo{Method: method, Path: path}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// pathVars returns the path variables of the route matched by the server Mux.
+func pathVars(req *http.Request) map[string]string {
+	if tr, ok := transport.FromServerContext(req.Context()); ok {
+		if tr, ok := tr.(*Transport); ok {
+			if tr.vars != nil {
+				return tr.vars
+			}
+			if tr.mux != nil {
+				return tr.mux.Vars(req)
+			}
+		}
+	}
+	return mux.Vars(req)
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type testMux struct {
+	*gorillaMux
+	handled []string
+}
+
+func (m *testMux) Handle(method, path string, h http.Handler) error {
+	m.handled = append(m.handled, method+" "+path)
+	return m.gorillaMux.Handle(method, path, h)
+}
+
+func TestRouterMux(t *testing.T) {
+	m := &testMux{gorillaMux: newGorillaMux(true)}
+	srv := NewServer(RouterMux(m), PathPrefix("/api"))
+	srv.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {
+		tr, ok := RequestFromServerContext(r.Context())
+		if !ok {
+			t.Fatal("transport not found")
+		}
+		if tr.URL.Path != "/api/index" {
+			t.Errorf("expected /api/index got %s", tr.URL.Path)
+		}
+	})
+	srv.Route("/v1").GET("/users/{name}", func(ctx Context) error {
+		if v := ctx.Vars().Get("name"); v != "kratos" {
+			t.Errorf("expected kratos got %s", v)
+		}
+		tr, _ := transport.FromServerContext(ctx)
+		if ht, ok := tr.(Transporter); !ok || ht.PathTemplate() != "/api/v1/users/{name}" {
+			t.Errorf("unexpected transport: %v", tr)
+		}
+		return ctx.String(200, "ok")
+	})
+
+	want := []string{" /api/index", "GET /api/v1/users/{name}"}
+	if !reflect.DeepEqual(m.handled, want) {
+		t.Errorf("expected %v got %v", want, m.handled)
+	}
+	for _, path := range []string{"/api/index", "/api/v1/users/kratos"} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: expected 200 got %d", path, rec.Code)
+		}
+	}
+	var routes []RouteInfo
+	_ = srv.WalkRoute(func(r RouteInfo) error {
+		routes = append(routes, r)
+		return nil
+	})
+	if !reflect.DeepEqual(routes, []RouteInfo{{Method: http.MethodGet, Path: "/api/v1/users/{name}"}}) {
+		t.Errorf("unexpected routes: %v", routes)
+	}
+}
+
+func TestHandleHeaderPrefix(t *testing.T) {
+	srv := NewServer(PathPrefix("/api"))
+	srv.HandleHeader("X-Test", "header", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("header"))
+	})
+	for path, code := range map[string]int{"/api/index": http.StatusOK, "/other": http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Test", "header")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != code {
+			t.Errorf("%s: expected %d got %d", path, code, rec.Code)
+		}
+	}
+}
+
+func TestMuxRegisterError(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"))
+	srv.HandleFunc("/users/{id:[}", func(http.ResponseWriter, *http.Request) {})
+	if err := srv.Start(context.Background()); err == nil {
+		_ = srv.Stop(context.Background())
+		t.Error("expected the error of the invalid route")
+	}
+}
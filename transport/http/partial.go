@@ -0,0 +1,147 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FieldsHeader is the header of the fields of the partial response.
+const FieldsHeader = "X-Fields"
+
+// PartialResponse with the partial responses of the fields selected by the
+// "fields" or "$select" query parameter, or the X-Fields header, e.g.
+// "id,user.name,items.title", which are the dotted proto or JSON names of the
+// fields of the proto messages, or the JSON names of the ones of the other
+// replies, and the fields of the repeated ones apply to each of their elements.
+// The unknown fields are ignored, default is the whole replies.
+func PartialResponse() ServerOption {
+	return func(s *Server) {
+		s.partial = true
+	}
+}
+
+// fieldTree is the tree of the selected fields, an empty one selects all of the field.
+type fieldTree map[string]fieldTree
+
+// selectedFields returns the tree of the fields selected by the request, nil if none.
+func selectedFields(r *http.Request) fieldTree {
+	query := r.URL.Query()
+	fields := query.Get("fields")
+	if fields == "" {
+		fields = query.Get("$select")
+	}
+	if fields == "" {
+		fields = r.Header.Get(FieldsHeader)
+	}
+	var tree fieldTree
+	for _, path := range strings.Split(fields, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		if tree == nil {
+			tree = fieldTree{}
+		}
+		node := tree
+		for _, name := range strings.Split(path, ".") {
+			sub, ok := node[name]
+			if !ok {
+				sub = fieldTree{}
+				node[name] = sub
+			}
+			node = sub
+		}
+	}
+	return tree
+}
+
+// partialResponse returns the response encoder of the partial replies.
+func partialResponse(enc EncodeResponseFunc) EncodeResponseFunc {
+	return func(w http.ResponseWriter, r *http.Request, v interface{}) error {
+		w.Header().Add("Vary", FieldsHeader)
+		tree := selectedFields(r)
+		if tree == nil || v == nil {
+			return enc(w, r, v)
+		}
+		switch reply := v.(type) {
+		case Redirector, StatusReplier:
+		case proto.Message:
+			m := proto.Clone(reply)
+			pruneMessage(m.ProtoReflect(), tree)
+			v = m
+		default:
+			data, err := json.Marshal(reply)
+			if err != nil {
+				return err
+			}
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.UseNumber()
+			var value interface{}
+			if err = dec.Decode(&value); err != nil {
+				return err
+			}
+			v = pruneValue(value, tree)
+		}
+		return enc(w, r, v)
+	}
+}
+
+// pruneMessage clears the fields of the message not in the tree.
+func pruneMessage(m protoreflect.Message, tree fieldTree) {
+	var cleared []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		sub, ok := tree[string(fd.Name())]
+		if !ok {
+			sub, ok = tree[fd.JSONName()]
+		}
+		switch {
+		case !ok:
+			cleared = append(cleared, fd)
+		case len(sub) == 0:
+		case fd.IsList():
+			if fd.Message() != nil {
+				list := v.List()
+				for i := 0; i < list.Len(); i++ {
+					pruneMessage(list.Get(i).Message(), sub)
+				}
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					pruneMessage(mv.Message(), sub)
+					return true
+				})
+			}
+		case fd.Message() != nil:
+			pruneMessage(v.Message(), sub)
+		}
+		return true
+	})
+	for _, fd := range cleared {
+		m.Clear(fd)
+	}
+}
+
+// pruneValue deletes the keys of the JSON objects of the value not in the tree.
+func pruneValue(v interface{}, tree fieldTree) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, e := range value {
+			sub, ok := tree[k]
+			if !ok {
+				delete(value, k)
+			} else if len(sub) > 0 {
+				value[k] = pruneValue(e, sub)
+			}
+		}
+	case []interface{}:
+		for i, e := range value {
+			value[i] = pruneValue(e, tree)
+		}
+	}
+	return v
+}
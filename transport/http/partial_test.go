@@ -0,0 +1,89 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/apipb"
+)
+
+func TestPartialResponse(t *testing.T) {
+	api := &apipb.Api{
+		Name:    "greeter",
+		Version: "v1",
+		Methods: []*apipb.Method{
+			{Name: "SayHello", RequestTypeUrl: "HelloRequest", ResponseTypeUrl: "HelloReply"},
+			{Name: "SayBye", RequestTypeUrl: "ByeRequest"},
+		},
+	}
+	type reply struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+		User struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"user"`
+	}
+	var r reply
+	r.ID, r.Name, r.User.Name, r.User.Email = 1, "kratos", "go", "go@example.com"
+	srv := NewServer(PartialResponse())
+	srv.Route("/").GET("/api", func(ctx Context) error { return ctx.Result(http.StatusOK, api) })
+	srv.Route("/").GET("/reply", func(ctx Context) error { return ctx.Result(http.StatusOK, r) })
+
+	get := func(url string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		url    string
+		header http.Header
+		want   *apipb.Api
+	}{
+		{"/api", nil, api},
+		{"/api?fields=name,methods.name", nil, &apipb.Api{
+			Name:    "greeter",
+			Methods: []*apipb.Method{{Name: "SayHello"}, {Name: "SayBye"}},
+		}},
+		{"/api?$select=version,methods.requestTypeUrl", nil, &apipb.Api{
+			Version: "v1",
+			Methods: []*apipb.Method{{RequestTypeUrl: "HelloRequest"}, {RequestTypeUrl: "ByeRequest"}},
+		}},
+		{"/api", http.Header{FieldsHeader: {"methods,unknown"}}, &apipb.Api{Methods: api.Methods}},
+	}
+	for _, test := range tests {
+		w := get(test.url, test.header)
+		got := new(apipb.Api)
+		if err := protojson.Unmarshal(w.Body.Bytes(), got); err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(got, test.want) {
+			t.Errorf("%s: expected %v got %v", test.url, test.want, got)
+		}
+		if w.Header().Get("Vary") != FieldsHeader {
+			t.Errorf("%s: unexpected Vary %q", test.url, w.Header().Get("Vary"))
+		}
+	}
+	if len(api.Methods[0].ResponseTypeUrl) == 0 {
+		t.Error("expected the reply not modified")
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(get("/reply?fields=id,user.name", nil).Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"id": float64(1), "user": map[string]interface{}{"name": "go"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+}
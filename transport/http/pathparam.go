@@ -0,0 +1,130 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// The types of the typed path parameters, e.g. {id:int}, {uuid} is the
+// parameter uuid of the type uuid.
+const (
+	ParamInt   = "int"
+	ParamUint  = "uint"
+	ParamFloat = "float"
+	ParamBool  = "bool"
+	ParamUUID  = "uuid"
+)
+
+// PathParam is a typed path parameter of a route, the path variable is validated
+// and converted to its canonical form before the handler runs, and the invalid
+// ones get 400 responses.
+type PathParam struct {
+	Name string
+	Type string
+}
+
+func isParamType(t string) bool {
+	switch t {
+	case ParamInt, ParamUint, ParamFloat, ParamBool, ParamUUID:
+		return true
+	}
+	return false
+}
+
+// parsePathParams rewrites the typed path parameters of the template to the
+// plain ones, the patterns of the other parameters are kept.
+func parsePathParams(template string) (string, []PathParam) {
+	var (
+		b      strings.Builder
+		params []PathParam
+	)
+	for i := 0; i < len(template); {
+		if template[i] != '{' {
+			b.WriteByte(template[i])
+			i++
+			continue
+		}
+		// the patterns may have braces, e.g. {id:[0-9]{3}}.
+		depth, end := 0, -1
+		for j := i; j < len(template) && end < 0; j++ {
+			switch template[j] {
+			case '{':
+				depth++
+			case '}':
+				if depth--; depth == 0 {
+					end = j
+				}
+			}
+		}
+		if end < 0 {
+			b.WriteString(template[i:])
+			break
+		}
+		name, typ, _ := strings.Cut(template[i+1:end], ":")
+		if typ == "" && name == ParamUUID {
+			typ = ParamUUID
+		}
+		if isParamType(typ) {
+			params = append(params, PathParam{Name: name, Type: typ})
+			b.WriteString("{" + name + "}")
+		} else {
+			b.WriteString(template[i : end+1])
+		}
+		i = end + 1
+	}
+	return b.String(), params
+}
+
+// convert validates the path variable and returns its canonical form.
+func (p PathParam) convert(v string) (string, bool) {
+	switch p.Type {
+	case ParamInt:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return strconv.FormatInt(n, 10), err == nil
+	case ParamUint:
+		n, err := strconv.ParseUint(v, 10, 64)
+		return strconv.FormatUint(n, 10), err == nil
+	case ParamFloat:
+		f, err := strconv.ParseFloat(v, 64)
+		return strconv.FormatFloat(f, 'g', -1, 64), err == nil
+	case ParamBool:
+		b, err := strconv.ParseBool(v)
+		return strconv.FormatBool(b), err == nil
+	case ParamUUID:
+		id, err := uuid.Parse(v)
+		return id.String(), err == nil
+	}
+	return v, true
+}
+
+// validatePathParams returns the filter validating and converting the typed path
+// parameters of the route, it runs inside the server filter of the route.
+func (s *Server) validatePathParams(params []PathParam) FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			raws := pathVars(req)
+			vars := make(map[string]string, len(raws))
+			for k, v := range raws {
+				vars[k] = v
+			}
+			for _, p := range params {
+				v, ok := p.convert(vars[p.Name])
+				if !ok {
+					s.ene(w, req, errors.BadRequest("INVALID_PATH_PARAM", fmt.Sprintf("path parameter %s must be %s", p.Name, p.Type)))
+					return
+				}
+				vars[p.Name] = v
+			}
+			if tr, ok := serverTransport(req); ok {
+				tr.vars = vars
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParsePathParams(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+		params   []PathParam
+	}{
+		{"/users/{id:int}", "/users/{id}", []PathParam{{Name: "id", Type: ParamInt}}},
+		{"/items/{uuid}/{ok:bool}", "/items/{uuid}/{ok}", []PathParam{{Name: "uuid", Type: ParamUUID}, {Name: "ok", Type: ParamBool}}},
+		{"/codes/{id:[0-9]{3}}", "/codes/{id:[0-9]{3}}", nil},
+		{"/names/{name}", "/names/{name}", nil},
+	}
+	for _, test := range tests {
+		got, params := parsePathParams(test.template)
+		if got != test.want {
+			t.Errorf("template %s: got %s want %s", test.template, got, test.want)
+		}
+		if !reflect.DeepEqual(params, test.params) {
+			t.Errorf("template %s: got params %v want %v", test.template, params, test.params)
+		}
+	}
+}
+
+func TestPathParamValidation(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").GET("/users/{id:int}", func(ctx Context) error {
+		var in struct {
+			ID string `json:"id"`
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		return ctx.String(http.StatusOK, in.ID)
+	})
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/users/007", http.StatusOK, "7"},
+		{"/users/abc", http.StatusBadRequest, ""},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rec.Code != test.code {
+			t.Errorf("path %s: got code %d want %d", test.path, rec.Code, test.code)
+		}
+		if test.body != "" && rec.Body.String() != test.body {
+			t.Errorf("path %s: got body %s want %s", test.path, rec.Body.String(), test.body)
+		}
+	}
+	var params []PathParam
+	_ = srv.WalkRoute(func(r RouteInfo) error {
+		if r.Path == "/users/{id}" {
+			params = r.Params
+		}
+		return nil
+	})
+	if !reflect.DeepEqual(params, []PathParam{{Name: "id", Type: ParamInt}}) {
+		t.Errorf("got route params %v", params)
+	}
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// pollMargin is the time before the deadline of the server timeout at which
+// the polls reply, so that they are not failed by the timeout.
+const pollMargin = 50 * time.Millisecond
+
+// PollFunc returns the reply of the poll, nil if there is nothing yet. The
+// channel is closed by the next notification of the topic of the poll, e.g.
+// for the function waiting for it by itself.
+type PollFunc func(notify <-chan struct{}) (interface{}, error)
+
+// PollOption is long polling option.
+type PollOption func(*pollOptions)
+
+type pollOptions struct {
+	topic string
+}
+
+// PollTopic with the topic of the poll notified by Server.Notify, default is
+// the operation of the route.
+func PollTopic(topic string) PollOption {
+	return func(o *pollOptions) {
+		o.topic = topic
+	}
+}
+
+// PollLimit with the max number of the concurrent polls of each route, the
+// others fail with ErrTooManyRequests, default is unlimited.
+func PollLimit(n int) ServerOption {
+	return func(s *Server) {
+		s.pollLimit = n
+	}
+}
+
+// Notify wakes up the polls of the topic, which call their functions again.
+func (s *Server) Notify(topic string) {
+	s.polls.notify(topic)
+}
+
+// pollHub is the notification hub of the long polls.
+type pollHub struct {
+	mu      sync.Mutex
+	topics  map[string]chan struct{}
+	pollers map[string]int
+	done    chan struct{}
+	closed  bool
+}
+
+func newPollHub() *pollHub {
+	return &pollHub{
+		topics:  make(map[string]chan struct{}),
+		pollers: make(map[string]int),
+		done:    make(chan struct{}),
+	}
+}
+
+// acquire counts a poller of the route, false if there are too many.
+func (h *pollHub) acquire(route string, limit int) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if limit > 0 && h.pollers[route] >= limit {
+		return false
+	}
+	h.pollers[route]++
+	return true
+}
+
+func (h *pollHub) release(route string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.pollers[route]--; h.pollers[route] <= 0 {
+		delete(h.pollers, route)
+	}
+}
+
+// wait returns the channel closed by the next notification of the topic.
+func (h *pollHub) wait(topic string) <-chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch, ok := h.topics[topic]
+	if !ok {
+		ch = make(chan struct{})
+		h.topics[topic] = ch
+	}
+	return ch
+}
+
+func (h *pollHub) notify(topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ch, ok := h.topics[topic]; ok {
+		close(ch)
+		delete(h.topics, topic)
+	}
+}
+
+// close replies the polls, e.g. when the server stops, so that they do not
+// hold the graceful shutdown.
+func (h *pollHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.closed {
+		h.closed = true
+		close(h.done)
+	}
+}
+
+// Poll replies the long poll by the function, which is called at once, and
+// again on every notification of the topic of the poll, until it returns a
+// reply or an error. It replies 204 No Content if there is none within the
+// timeout, which is bounded by the server timeout, or once the server stops.
+// It replies nothing if the client disconnects.
+func (c *wrapper) Poll(timeout time.Duration, fn PollFunc, opts ...PollOption) error {
+	srv := c.router.srv
+	hub := srv.polls
+	route := c.req.URL.Path
+	if tr, ok := transport.FromServerContext(c.req.Context()); ok {
+		route = tr.Operation()
+	}
+	o := pollOptions{topic: route}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !hub.acquire(route, srv.pollLimit) {
+		return ErrTooManyRequests
+	}
+	defer hub.release(route)
+
+	ctx := c.req.Context()
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline) - pollMargin; remaining < timeout {
+			timeout = remaining
+		}
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		notify := hub.wait(o.topic)
+		v, err := fn(notify)
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			return c.Result(http.StatusOK, v)
+		}
+		select {
+		case <-notify:
+		case <-timer.C:
+			c.res.WriteHeader(http.StatusNoContent)
+			return nil
+		case <-hub.done:
+			c.res.WriteHeader(http.StatusNoContent)
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
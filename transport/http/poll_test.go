@@ -0,0 +1,77 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoll(t *testing.T) {
+	var version int32
+	srv := NewServer(Timeout(300*time.Millisecond), PollLimit(1))
+	srv.Route("/").GET("/watch", func(ctx Context) error {
+		since := ctx.Query().Get("since")
+		return ctx.Poll(10*time.Second, func(<-chan struct{}) (interface{}, error) {
+			if v := atomic.LoadInt32(&version); since != "" && int32(since[0]-'0') < v {
+				return map[string]int32{"version": v}, nil
+			}
+			return nil, nil
+		})
+	})
+	srv.Route("/").GET("/topic", func(ctx Context) error {
+		return ctx.Poll(10*time.Second, func(notify <-chan struct{}) (interface{}, error) {
+			select {
+			case <-notify:
+				return map[string]string{"topic": "config"}, nil
+			case <-ctx.Done():
+				return nil, nil
+			}
+		}, PollTopic("config"))
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	get := func(path string) (int, string) {
+		res, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Error(err)
+			return 0, ""
+		}
+		defer res.Body.Close()
+		data, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(data)
+	}
+
+	start := time.Now()
+	if code, _ := get("/watch?since=0"); code != http.StatusNoContent {
+		t.Errorf("expected 204 got %d", code)
+	}
+	if d := time.Since(start); d > 300*time.Millisecond {
+		t.Errorf("expected the poll bounded by the server timeout, took %v", d)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if code, body := get("/watch?since=0"); code != http.StatusOK || body != `{"version":1}` {
+			t.Errorf("unexpected reply %d %s", code, body)
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if code, _ := get("/watch?since=0"); code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 over the poll limit got %d", code)
+	}
+	atomic.StoreInt32(&version, 1)
+	srv.Notify("/watch")
+	<-done
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		srv.Notify("config")
+	}()
+	if code, body := get("/topic"); code != http.StatusOK || body != `{"topic":"config"}` {
+		t.Errorf("unexpected reply of the topic %d %s", code, body)
+	}
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+)
+
+// ClientStats is the connection statistics of a client.
+type ClientStats struct {
+	// Requests is the number of requests which got a connection.
+	Requests uint64
+	// NewConns is the number of requests served by a newly dialed connection.
+	NewConns uint64
+	// ReusedConns is the number of requests served by a pooled connection.
+	ReusedConns uint64
+	// Dials is the number of connections dialed, DialErrors of them failed.
+	Dials      uint64
+	DialErrors uint64
+	// OpenConns is the number of connections currently open.
+	OpenConns int64
+}
+
+type connStats struct {
+	requests   uint64
+	newConns   uint64
+	reused     uint64
+	dials      uint64
+	dialErrors uint64
+	open       int64
+}
+
+func (s *connStats) stats() ClientStats {
+	return ClientStats{
+		Requests:    atomic.LoadUint64(&s.requests),
+		NewConns:    atomic.LoadUint64(&s.newConns),
+		ReusedConns: atomic.LoadUint64(&s.reused),
+		Dials:       atomic.LoadUint64(&s.dials),
+		DialErrors:  atomic.LoadUint64(&s.dialErrors),
+		OpenConns:   atomic.LoadInt64(&s.open),
+	}
+}
+
+// trace returns the request context which counts how the connection is got.
+func (s *connStats) trace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			atomic.AddUint64(&s.requests, 1)
+			if info.Reused {
+				atomic.AddUint64(&s.reused, 1)
+			} else {
+				atomic.AddUint64(&s.newConns, 1)
+			}
+		},
+	})
+}
+
+// instrument wraps the dialer of the transport to count the open connections.
+func (s *connStats) instrument(tr *http.Transport) {
+	dial := tr.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		atomic.AddUint64(&s.dials, 1)
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			atomic.AddUint64(&s.dialErrors, 1)
+			return nil, err
+		}
+		atomic.AddInt64(&s.open, 1)
+		return &statsConn{Conn: conn, stats: s}, nil
+	}
+}
+
+// statsConn decrements the open connections once it is closed.
+type statsConn struct {
+	net.Conn
+	stats *connStats
+	once  sync.Once
+}
+
+func (c *statsConn) Close() error {
+	c.once.Do(func() { atomic.AddInt64(&c.stats.open, -1) })
+	return c.Conn.Close()
+}
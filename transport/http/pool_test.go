@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport/dnscache"
+)
+
+func TestClientPool(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(context.Background(),
+		WithEndpoint(strings.TrimPrefix(srv.URL, "http://")),
+		WithMaxIdleConns(8),
+		WithMaxIdleConnsPerHost(4),
+		WithMaxConnsPerHost(16),
+		WithIdleConnTimeout(time.Minute),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, ok := client.cc.Transport.(*http.Transport)
+	if !ok || tr == http.DefaultTransport {
+		t.Fatal("expected the client to own its transport")
+	}
+	if tr.MaxIdleConns != 8 || tr.MaxIdleConnsPerHost != 4 || tr.MaxConnsPerHost != 16 || tr.IdleConnTimeout != time.Minute {
+		t.Errorf("unexpected transport pool settings")
+	}
+
+	for i := 0; i < 3; i++ {
+		reply := make(map[string]string)
+		if err = client.Invoke(context.Background(), http.MethodGet, "/pool", nil, &reply); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := client.Stats()
+	want := ClientStats{Requests: 3, NewConns: 1, ReusedConns: 2, Dials: 1, OpenConns: 1}
+	if stats != want {
+		t.Errorf("expected %+v got %+v", want, stats)
+	}
+	if err = client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if open := client.Stats().OpenConns; open != 0 {
+		t.Errorf("expected no open connections after close, got %d", open)
+	}
+}
+
+func TestClientSharedTransport(t *testing.T) {
+	shared := &http.Transport{}
+	client, err := NewClient(context.Background(), WithTransport(shared))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.cc.Transport != shared || client.owned {
+		t.Error("expected the given transport to be used as is")
+	}
+}
+
+type staticResolver map[string][]string
+
+func (r staticResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	return r[host], nil
+}
+
+func TestClientDNSCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	cache := dnscache.New(dnscache.WithResolver(staticResolver{"kratos.local": {"127.0.0.1"}}))
+	client, err := NewClient(context.Background(), WithEndpoint("kratos.local:"+port), WithDNSCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var reply map[string]interface{}
+	if err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if stats := cache.Stats(); stats.Lookups != 1 || stats.Misses != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestClientDialOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"host":"` + r.Host + `"}`))
+	}))
+	defer srv.Close()
+
+	var dialed []string
+	client, err := NewClient(context.Background(),
+		WithEndpoint("kratos.local:80"),
+		WithDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var reply map[string]string
+	if err = client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(dialed) != 1 || dialed[0] != "kratos.local:80" || reply["host"] != "kratos.local:80" {
+		t.Errorf("unexpected dial %v reply %v", dialed, reply)
+	}
+}
+
+func TestClientProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	client, err := NewClient(context.Background(), WithEndpoint("kratos.local:8000"), WithProxy(http.ProxyURL(proxyURL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var reply map[string]string
+	if err = client.Invoke(context.Background(), http.MethodGet, "/hello", nil, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://kratos.local:8000/hello" {
+		t.Errorf("unexpected proxied requests %v", proxied)
+	}
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/go-kratos/kratos/v2/internal/endpoint"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Prober = (*Server)(nil)
+
+// Probe probes the endpoint by an "OPTIONS *" request, which is replied by
+// net/http without the handlers of the server. The certificates are not
+// verified, as the endpoint is probed for the reachability only.
+func (s *Server) Probe(ctx context.Context, u *url.URL) error {
+	network, addr, scheme := "tcp", u.Host, u.Scheme
+	if u.Scheme == endpoint.UnixScheme {
+		network, scheme = "unix", u.Query().Get("protocol")
+		addr, _ = endpoint.ParseUnixAddress(endpoint.UnixAddress(u))
+	}
+	tr := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		DisableKeepAlives: true,
+	}
+	defer tr.CloseIdleConnections()
+	req, err := http.NewRequestWithContext(ctx, http.MethodOptions, scheme+"://"+u.Host, nil)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == endpoint.UnixScheme {
+		req.URL.Host, req.Host = "localhost", "localhost"
+	}
+	req.URL.Opaque = "*"
+	res, err := tr.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestServer_Probe(t *testing.T) {
+	var handled atomic.Bool
+	srv := NewServer(Address("127.0.0.1:0"))
+	srv.HandlePrefix("/", http.HandlerFunc(func(http.ResponseWriter, *http.Request) { handled.Store(true) }))
+	// the endpoint is listened before the server is started.
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Start(context.Background()) }()
+	defer func() { _ = srv.Stop(context.Background()) }()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Probe(ctx, e); err != nil || handled.Load() {
+		t.Errorf("expected the endpoint probed without the handlers, got %v %v", err, handled.Load())
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = lis.Close()
+	if err := srv.Probe(ctx, &url.URL{Scheme: "http", Host: lis.Addr().String()}); err == nil {
+		t.Error("expected the closed endpoint unreachable")
+	}
+}
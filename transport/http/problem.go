@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// ProblemContentType is the content type of the problem details of RFC 9457.
+const ProblemContentType = "application/problem+json"
+
+// ProblemOption is a problem details option.
+type ProblemOption func(*problemOptions)
+
+type problemOptions struct {
+	typeURI func(*errors.Error) string
+}
+
+// ProblemType with the func mapping kratos errors into the problem type URIs,
+// e.g. "https://example.com/problems/" + reason, default is "about:blank".
+func ProblemType(f func(*errors.Error) string) ProblemOption {
+	return func(o *problemOptions) {
+		o.typeURI = f
+	}
+}
+
+// problem is the problem details, of which the reason and the metadata of the
+// kratos errors are the extension members.
+type problem struct {
+	Type     string            `json:"type,omitempty"`
+	Title    string            `json:"title,omitempty"`
+	Status   int               `json:"status,omitempty"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Reason   string            `json:"reason,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ProblemDetails with the problem details error encoder of the server, see ProblemErrorEncoder.
+func ProblemDetails(opts ...ProblemOption) ServerOption {
+	return ErrorEncoder(ProblemErrorEncoder(opts...))
+}
+
+// ProblemErrorEncoder encodes the errors as the problem details of RFC 9457 in
+// application/problem+json, for the interop with the non-kratos consumers.
+// The detail is the error message and the instance is the request path.
+func ProblemErrorEncoder(opts ...ProblemOption) EncodeErrorFunc {
+	o := &problemOptions{typeURI: func(*errors.Error) string { return "about:blank" }}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		se := errors.FromError(err)
+		body, err := json.Marshal(&problem{
+			Type:     o.typeURI(se),
+			Title:    http.StatusText(int(se.Code)),
+			Status:   int(se.Code),
+			Detail:   se.Message,
+			Instance: r.URL.Path,
+			Reason:   se.Reason,
+			Metadata: se.Metadata,
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.WriteHeader(int(se.Code))
+		_, _ = w.Write(body)
+	}
+}
+
+// ProblemErrorDecoder is an HTTP error decoder of the problem details, the other
+// responses are decoded by DefaultErrorDecoder.
+func ProblemErrorDecoder(ctx context.Context, res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		return nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType != ProblemContentType {
+		return DefaultErrorDecoder(ctx, res)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err == nil {
+		p := new(problem)
+		if err = json.Unmarshal(data, p); err == nil {
+			detail := p.Detail
+			if detail == "" {
+				detail = p.Title
+			}
+			return errors.New(res.StatusCode, p.Reason, detail).WithMetadata(p.Metadata)
+		}
+	}
+	return errors.Newf(res.StatusCode, errors.UnknownReason, "").WithCause(err)
+}
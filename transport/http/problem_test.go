@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestProblemDetails(t *testing.T) {
+	srv := NewServer(ProblemDetails(ProblemType(func(se *errors.Error) string {
+		return "https://example.com/problems/" + se.Reason
+	})))
+	srv.Route("/").GET("/users/{id}", func(ctx Context) error {
+		return errors.BadRequest("INVALID_USER", "the user id is invalid").WithMetadata(map[string]string{"id": "abc"})
+	})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/abc", nil))
+	if rec.Code != http.StatusBadRequest || rec.Header().Get("Content-Type") != ProblemContentType {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var p map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"type":     "https://example.com/problems/INVALID_USER",
+		"title":    "Bad Request",
+		"status":   float64(400),
+		"detail":   "the user id is invalid",
+		"instance": "/users/abc",
+		"reason":   "INVALID_USER",
+		"metadata": map[string]interface{}{"id": "abc"},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("expected %v got %v", want, p)
+	}
+
+	err := ProblemErrorDecoder(context.Background(), rec.Result())
+	if se := errors.FromError(err); se.Code != 400 || se.Reason != "INVALID_USER" || se.Message != "the user id is invalid" || se.Metadata["id"] != "abc" {
+		t.Errorf("unexpected decoded error %v", err)
+	}
+}
+
+func TestProblemErrorDecoderFallback(t *testing.T) {
+	rec := httptest.NewRecorder()
+	DefaultErrorEncoder(rec, httptest.NewRequest(http.MethodGet, "/", nil), errors.NotFound("USER_NOT_FOUND", "not found"))
+	err := ProblemErrorDecoder(context.Background(), rec.Result())
+	if se := errors.FromError(err); se.Code != 404 || se.Reason != "USER_NOT_FOUND" {
+		t.Errorf("expected the kratos error decoded, got %v", err)
+	}
+}
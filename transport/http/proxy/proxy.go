@@ -0,0 +1,220 @@
+// Package proxy is the reverse proxy of the HTTP services, e.g. of an API
+// gateway, whose backends are resolved by the discovery and load balanced by
+// the selector of the HTTP client:
+//
+//	p, err := proxy.NewReverseProxy("discovery:///user",
+//		proxy.WithDiscovery(r),
+//		proxy.WithStripPrefix("/user"),
+//		proxy.WithMiddleware(metadata.Client(), tracing.Client()),
+//	)
+//	httpSrv.HandlePrefix("/user/", p)
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// ErrBadGateway is the error of the requests failed to be proxied.
+var ErrBadGateway = errors.New(http.StatusBadGateway, "BAD_GATEWAY", "bad gateway")
+
+// Option is reverse proxy option.
+type Option func(*options)
+
+type options struct {
+	ctx           context.Context
+	discovery     registry.Discovery
+	clientOpts    []khttp.ClientOption
+	middleware    []middleware.Middleware
+	rewrite       func(req *http.Request)
+	flushInterval time.Duration
+}
+
+// WithContext with the context of the resolver of the discovery.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithDiscovery with the discovery of the backends.
+func WithDiscovery(d registry.Discovery) Option {
+	return func(o *options) {
+		o.discovery = d
+	}
+}
+
+// WithClientOptions with the options of the HTTP client of the backends, e.g.
+// WithSchemeTransport of the HTTP/3 backends, WithNodeFilter or WithTLSConfig.
+func WithClientOptions(opts ...khttp.ClientOption) Option {
+	return func(o *options) {
+		o.clientOpts = append(o.clientOpts, opts...)
+	}
+}
+
+// WithMiddleware with the client middleware of the proxied requests, which
+// are the *http.Request, e.g. of the metadata and the tracing propagation.
+func WithMiddleware(m ...middleware.Middleware) Option {
+	return func(o *options) {
+		o.middleware = m
+	}
+}
+
+// WithStripPrefix strips the prefix of the paths of the proxied requests.
+func WithStripPrefix(prefix string) Option {
+	return WithRewrite(func(req *http.Request) {
+		req.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, prefix), "/")
+		if req.URL.RawPath != "" {
+			req.URL.RawPath = "/" + strings.TrimPrefix(strings.TrimPrefix(req.URL.RawPath, prefix), "/")
+		}
+	})
+}
+
+// WithRewrite with the func rewriting the proxied requests, e.g. the paths.
+func WithRewrite(fn func(req *http.Request)) Option {
+	return func(o *options) {
+		o.rewrite = fn
+	}
+}
+
+// WithFlushInterval with the flush interval of the response bodies, default
+// is -1, which flushes every write of them, e.g. of the streaming responses.
+func WithFlushInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.flushInterval = d
+	}
+}
+
+// ReverseProxy is the http.Handler proxying the requests to the backends of
+// an endpoint, whose bodies are streamed.
+type ReverseProxy struct {
+	client     *khttp.Client
+	target     *url.URL
+	middleware []middleware.Middleware
+	rewrite    func(req *http.Request)
+	proxy      *httputil.ReverseProxy
+}
+
+// NewReverseProxy new a reverse proxy of the endpoint, which is of the
+// discovery, e.g. discovery:///user, or the URL of the backend, e.g.
+// http://127.0.0.1:8000. The responses of the backends, including the errors
+// and the redirects, are replied as they are.
+func NewReverseProxy(endpoint string, opts ...Option) (*ReverseProxy, error) {
+	o := options{ctx: context.Background(), flushInterval: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	clientOpts := []khttp.ClientOption{
+		khttp.WithEndpoint(endpoint),
+		// the streams are not timed out, but canceled with the requests.
+		khttp.WithTimeout(0),
+		khttp.WithErrorDecoder(func(context.Context, *http.Response) error { return nil }),
+		khttp.WithCheckRedirect(func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }),
+		// the compressed bodies are proxied as they are.
+		khttp.WithMaxDecompressedSize(-1),
+	}
+	if o.discovery != nil {
+		clientOpts = append(clientOpts, khttp.WithDiscovery(o.discovery))
+	}
+	client, err := khttp.NewClient(o.ctx, append(clientOpts, o.clientOpts...)...)
+	if err != nil {
+		return nil, err
+	}
+	p := &ReverseProxy{
+		client:     client,
+		target:     target,
+		middleware: o.middleware,
+		rewrite:    o.rewrite,
+	}
+	p.proxy = &httputil.ReverseProxy{
+		Director:      p.direct,
+		Transport:     roundTripper(p.roundTrip),
+		FlushInterval: o.flushInterval,
+		ErrorHandler:  p.fail,
+	}
+	return p, nil
+}
+
+// ServeHTTP proxies the request to a backend.
+func (p *ReverseProxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	p.proxy.ServeHTTP(w, req)
+}
+
+// Close closes the resolver and the connections of the backends.
+func (p *ReverseProxy) Close() error {
+	return p.client.Close()
+}
+
+func (p *ReverseProxy) direct(req *http.Request) {
+	if p.rewrite != nil {
+		p.rewrite(req)
+	}
+	// the host of the discovery is of the node selected by the client.
+	req.URL.Scheme, req.URL.Host = p.target.Scheme, p.target.Host
+	if p.target.Scheme == "discovery" {
+		req.URL.Scheme, req.URL.Host = "http", p.target.Path
+	}
+	// the requests of the client are not of the server.
+	req.Host, req.RequestURI = "", ""
+	if _, ok := req.Header["User-Agent"]; !ok {
+		// the default User-Agent of net/http is not set.
+		req.Header.Set("User-Agent", "")
+	}
+}
+
+func (p *ReverseProxy) roundTrip(req *http.Request) (*http.Response, error) {
+	tr := &Transport{
+		endpoint:  p.target.String(),
+		operation: req.URL.Path,
+		request:   req,
+		reqHeader: headerCarrier(req.Header),
+	}
+	h := func(ctx context.Context, in interface{}) (interface{}, error) {
+		res, err := p.client.Do(in.(*http.Request).WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		tr.replyHeader = headerCarrier(res.Header)
+		return res, nil
+	}
+	if len(p.middleware) > 0 {
+		h = middleware.Chain(p.middleware...)(h)
+	}
+	res, err := h(transport.NewClientContext(req.Context(), tr), req)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*http.Response), nil
+}
+
+// fail replies the error of the request failed to be proxied, the errors
+// other than the kratos ones, e.g. of the connections, are ErrBadGateway.
+func (p *ReverseProxy) fail(w http.ResponseWriter, req *http.Request, err error) {
+	if se := new(errors.Error); !errors.As(err, &se) {
+		err = ErrBadGateway.WithCause(err)
+	}
+	khttp.DefaultErrorEncoder(w, req, err)
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,140 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type testDiscovery struct {
+	endpoints []string
+}
+
+func (d testDiscovery) GetService(context.Context, string) ([]*registry.ServiceInstance, error) {
+	return []*registry.ServiceInstance{{ID: "1", Name: "user", Endpoints: d.endpoints}}, nil
+}
+
+func (d testDiscovery) Watch(ctx context.Context, _ string) (registry.Watcher, error) {
+	return &testWatcher{ctx: ctx, d: d}, nil
+}
+
+type testWatcher struct {
+	ctx  context.Context
+	d    testDiscovery
+	sent bool
+}
+
+func (w *testWatcher) Next() ([]*registry.ServiceInstance, error) {
+	if !w.sent {
+		w.sent = true
+		return w.d.GetService(w.ctx, "")
+	}
+	<-w.ctx.Done()
+	return nil, w.ctx.Err()
+}
+
+func (w *testWatcher) Stop() error { return nil }
+
+func TestReverseProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/users/1":
+			w.Header().Set("X-From", req.Header.Get("x-md-global-from"))
+			data, _ := io.ReadAll(req.Body)
+			_, _ = w.Write(append([]byte(req.Method+" "+req.URL.RequestURI()+" "), data...))
+		case "/redirect":
+			http.Redirect(w, req, "/users/1", http.StatusFound)
+		case "/stream":
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+			_, _ = w.Write([]byte("done"))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer backend.Close()
+	u, _ := url.Parse(backend.URL)
+
+	var operations []string
+	p, err := NewReverseProxy("discovery:///user",
+		WithDiscovery(testDiscovery{endpoints: []string{"http://" + u.Host}}),
+		WithClientOptions(khttp.WithBlock()),
+		WithStripPrefix("/user"),
+		WithMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				if tr, ok := transport.FromClientContext(ctx); ok {
+					operations = append(operations, tr.Operation())
+					tr.RequestHeader().Set("x-md-global-from", "gateway")
+				}
+				return handler(ctx, req)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	gateway := httptest.NewServer(p)
+	defer gateway.Close()
+
+	res, err := http.Post(gateway.URL+"/user/users/1?q=1", "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(data) != "POST /users/1?q=1 body" || res.Header.Get("X-From") != "gateway" {
+		t.Errorf("unexpected response %q %v", data, res.Header)
+	}
+	if len(operations) != 1 || operations[0] != "/users/1" {
+		t.Errorf("unexpected operations %v", operations)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	if res, err = client.Get(gateway.URL + "/user/redirect"); err != nil || res.StatusCode != http.StatusFound {
+		t.Errorf("expected the redirect replied, got %v %v", res, err)
+	} else {
+		res.Body.Close()
+	}
+	if res, err = http.Get(gateway.URL + "/user/missing"); err != nil || res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the error replied, got %v %v", res, err)
+	} else {
+		res.Body.Close()
+	}
+	if res, err = http.Get(gateway.URL + "/user/stream"); err != nil {
+		t.Fatal(err)
+	}
+	// the header of the stream is flushed before the body.
+	data, _ = io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || string(data) != "done" {
+		t.Errorf("unexpected stream %d %q", res.StatusCode, data)
+	}
+}
+
+func TestReverseProxyBadGateway(t *testing.T) {
+	backend := httptest.NewServer(http.NotFoundHandler())
+	u := backend.URL
+	backend.Close()
+	p, err := NewReverseProxy(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if w.Code != http.StatusBadGateway || !strings.Contains(w.Body.String(), "BAD_GATEWAY") {
+		t.Errorf("expected bad gateway, got %d %s", w.Code, w.Body.String())
+	}
+}
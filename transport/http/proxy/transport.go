@@ -0,0 +1,89 @@
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+var _ khttp.Transporter = (*Transport)(nil)
+
+// Transport is the client transport of a proxied request, whose operation is
+// the path of it.
+type Transport struct {
+	endpoint    string
+	operation   string
+	request     *http.Request
+	reqHeader   headerCarrier
+	replyHeader headerCarrier
+}
+
+// Kind returns the transport kind.
+func (tr *Transport) Kind() transport.Kind {
+	return transport.KindHTTP
+}
+
+// Endpoint returns the transport endpoint.
+func (tr *Transport) Endpoint() string {
+	return tr.endpoint
+}
+
+// Operation returns the transport operation.
+func (tr *Transport) Operation() string {
+	return tr.operation
+}
+
+// Request returns the proxied request.
+func (tr *Transport) Request() *http.Request {
+	return tr.request
+}
+
+// PathTemplate returns the path of the proxied request.
+func (tr *Transport) PathTemplate() string {
+	return tr.operation
+}
+
+// RequestHeader returns the request header.
+func (tr *Transport) RequestHeader() transport.Header {
+	return tr.reqHeader
+}
+
+// ReplyHeader returns the reply header, which is of the response once it is received.
+func (tr *Transport) ReplyHeader() transport.Header {
+	if tr.replyHeader == nil {
+		tr.replyHeader = headerCarrier{}
+	}
+	return tr.replyHeader
+}
+
+type headerCarrier http.Header
+
+// Get returns the value associated with the passed key.
+func (hc headerCarrier) Get(key string) string {
+	return http.Header(hc).Get(key)
+}
+
+// Set stores the key-value pair.
+func (hc headerCarrier) Set(key string, value string) {
+	http.Header(hc).Set(key, value)
+}
+
+// Add append value to key-values pair.
+func (hc headerCarrier) Add(key string, value string) {
+	http.Header(hc).Add(key, value)
+}
+
+// Keys lists the keys stored in this carrier.
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns a slice of values associated with the passed key.
+func (hc headerCarrier) Values(key string) []string {
+	return http.Header(hc).Values(key)
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"syscall"
+
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// isDrainingError reports whether the request was not sent because the node is
+// draining, it refused the connection or sent GOAWAY before the stream started.
+func isDrainingError(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) || strings.Contains(err.Error(), "server sent GOAWAY")
+}
+
+// resetBody resets the body of the request to be sent again,
+// it returns false if the body cannot be replayed.
+func resetBody(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	req.Body = body
+	return true
+}
+
+// excludeNodes filters out the nodes of the addresses.
+func excludeNodes(addrs []string) selector.NodeFilter {
+	excluded := make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		excluded[addr] = struct{}{}
+	}
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		filtered := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if _, ok := excluded[n.Address()]; !ok {
+				filtered = append(filtered, n)
+			}
+		}
+		return filtered
+	}
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func TestIsDrainingError(t *testing.T) {
+	if isDrainingError(&net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{}}) {
+		t.Error("expected DNS error not to be draining")
+	}
+	if !isDrainingError(&net.OpError{Op: "dial", Net: "tcp", Err: errConnRefused()}) {
+		t.Error("expected connection refused to be draining")
+	}
+	if !isDrainingError(errors.New("http2: server sent GOAWAY and closed the connection; LastStreamID=1")) {
+		t.Error("expected GOAWAY to be draining")
+	}
+}
+
+func errConnRefused() error {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	addr := lis.Addr().String()
+	_ = lis.Close()
+	_, err = net.Dial("tcp", addr)
+	return err
+}
+
+func TestClientNodeRepick(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	draining := lis.Addr().String()
+	_ = lis.Close()
+
+	dis := &unixDiscovery{ins: []*registry.ServiceInstance{
+		{ID: "1", Name: "repick", Endpoints: []string{"http://" + draining}},
+		{ID: "2", Name: "repick", Endpoints: []string{"http://" + strings.TrimPrefix(srv.URL, "http://")}},
+	}}
+	newClient := func(opts ...ClientOption) *Client {
+		client, err := NewClient(context.Background(), append([]ClientOption{WithEndpoint("discovery:///repick"), WithDiscovery(dis), WithBlock()}, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+
+	client := newClient()
+	defer client.Close()
+	for i := 0; i < 10; i++ {
+		var reply map[string]string
+		if err := client.Invoke(context.Background(), http.MethodPost, "/hello", map[string]string{"name": "kratos"}, &reply); err != nil {
+			t.Fatalf("expected the draining node to be skipped, got %v", err)
+		}
+	}
+
+	client = newClient(WithNodeRepick(0))
+	defer client.Close()
+	var failed bool
+	for i := 0; i < 10 && !failed; i++ {
+		var reply map[string]string
+		failed = client.Invoke(context.Background(), http.MethodGet, "/hello", nil, &reply) != nil
+	}
+	if !failed {
+		t.Error("expected the draining node error without repick")
+	}
+}
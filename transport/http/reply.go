@@ -0,0 +1,64 @@
+package http
+
+import "net/http"
+
+// StatusReplier replies to the request with a successful status other than
+// 200 OK, e.g. 201 Created with the Location header, and the reply encoded by
+// the response encoder as the body, a nil reply means no body.
+type StatusReplier interface {
+	StatusReply() (code int, header http.Header, reply interface{})
+}
+
+type statusReply struct {
+	code   int
+	header http.Header
+	reply  interface{}
+}
+
+func (r *statusReply) StatusReply() (int, http.Header, interface{}) {
+	return r.code, r.header, r.reply
+}
+
+// NewStatusReply new a reply of the status code and the header.
+func NewStatusReply(code int, header http.Header, reply interface{}) StatusReplier {
+	return &statusReply{code: code, header: header, reply: reply}
+}
+
+// Created new a 201 Created reply with the Location header of the created resource.
+func Created(location string, reply interface{}) StatusReplier {
+	return NewStatusReply(http.StatusCreated, http.Header{"Location": []string{location}}, reply)
+}
+
+// Accepted new a 202 Accepted reply, e.g. of the status of an async operation.
+func Accepted(reply interface{}) StatusReplier {
+	return NewStatusReply(http.StatusAccepted, nil, reply)
+}
+
+// NoContent new a 204 No Content reply.
+func NoContent() StatusReplier {
+	return NewStatusReply(http.StatusNoContent, nil, nil)
+}
+
+// encodeStatusReply writes the status and the header of the reply, then its
+// body by encode unless it is nil or the status allows no body.
+func encodeStatusReply(w http.ResponseWriter, sr StatusReplier, encode func(http.ResponseWriter, interface{}) error) error {
+	code, header, reply := sr.StatusReply()
+	for k, v := range header {
+		w.Header()[k] = v
+	}
+	if reply == nil || code == http.StatusNoContent || code == http.StatusNotModified {
+		writeStatus(w, code)
+		return nil
+	}
+	// the status is written by the first Write, after the encoder sets the Content-Type.
+	return encode(&responseWriter{code: code, w: w}, reply)
+}
+
+// writeStatus writes the status of a response without a body, which is deferred
+// until the body by responseWriter otherwise.
+func writeStatus(w http.ResponseWriter, code int) {
+	if rw, ok := w.(*responseWriter); ok {
+		w = rw.w
+	}
+	w.WriteHeader(code)
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusReply(t *testing.T) {
+	srv := NewServer()
+	r := srv.Route("/")
+	r.POST("/users", func(ctx Context) error {
+		return ctx.Returns(Created("/users/1", map[string]string{"id": "1"}), nil)
+	})
+	r.POST("/jobs", func(ctx Context) error {
+		return ctx.Returns(Accepted(map[string]string{"status": "pending"}), nil)
+	})
+	r.DELETE("/users/1", func(ctx Context) error {
+		return ctx.Returns(NoContent(), nil)
+	})
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		header string
+		body   string
+	}{
+		{http.MethodPost, "/users", http.StatusCreated, "/users/1", `{"id":"1"}`},
+		{http.MethodPost, "/jobs", http.StatusAccepted, "", `{"status":"pending"}`},
+		{http.MethodDelete, "/users/1", http.StatusNoContent, "", ""},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(test.method, test.path, nil))
+		if rec.Code != test.code || rec.Header().Get("Location") != test.header || rec.Body.String() != test.body {
+			t.Errorf("%s %s: unexpected response %d %q %q", test.method, test.path, rec.Code, rec.Header().Get("Location"), rec.Body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	if err := DefaultResponseEncoder(rec, httptest.NewRequest(http.MethodPost, "/", nil), Created("/users/2", map[string]string{"id": "2"})); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusCreated || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected the Content-Type before the status, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
@@ -49,12 +49,13 @@ type resolver struct {
 	watcher     registry.Watcher
 	selecterKey string
 	subsetSize  int
+	schemes     []string
 
 	insecure bool
 }
 
 func newResolver(ctx context.Context, discovery registry.Discovery, target *Target,
-	rebalancer selector.Rebalancer, block, insecure bool, subsetSize int,
+	rebalancer selector.Rebalancer, block, insecure bool, subsetSize int, schemes ...string,
 ) (*resolver, error) {
 	// this is new resolver
 	watcher, err := discovery.Watch(ctx, target.Endpoint)
@@ -68,6 +69,7 @@ func newResolver(ctx context.Context, discovery registry.Discovery, target *Targ
 		insecure:    insecure,
 		selecterKey: uuid.New().String(),
 		subsetSize:  subsetSize,
+		schemes:     schemes,
 	}
 	if block {
 		done := make(chan error, 1)
@@ -122,7 +124,7 @@ func newResolver(ctx context.Context, discovery registry.Discovery, target *Targ
 func (r *resolver) update(services []*registry.ServiceInstance) bool {
 	filtered := make([]*registry.ServiceInstance, 0, len(services))
 	for _, ins := range services {
-		ept, err := endpoint.ParseEndpoint(ins.Endpoints, endpoint.Scheme("http", !r.insecure))
+		_, ept, err := r.parseEndpoint(ins)
 		if err != nil {
 			log.Errorf("Failed to parse (%v) discovery endpoint: %v error %v", r.target, ins.Endpoints, err)
 			continue
@@ -137,8 +139,8 @@ func (r *resolver) update(services []*registry.ServiceInstance) bool {
 	}
 	nodes := make([]selector.Node, 0, len(filtered))
 	for _, ins := range filtered {
-		ept, _ := endpoint.ParseEndpoint(ins.Endpoints, endpoint.Scheme("http", !r.insecure))
-		nodes = append(nodes, selector.NewNode("http", ept, ins))
+		scheme, ept, _ := r.parseEndpoint(ins)
+		nodes = append(nodes, selector.NewNode(scheme, ept, ins))
 	}
 
 	if len(nodes) == 0 {
@@ -149,6 +151,18 @@ func (r *resolver) update(services []*registry.ServiceInstance) bool {
 	return true
 }
 
+// parseEndpoint returns the endpoint of the first preferred scheme the instance
+// advertises, or of http.
+func (r *resolver) parseEndpoint(ins *registry.ServiceInstance) (scheme, ept string, err error) {
+	for _, scheme = range r.schemes {
+		if ept, err = endpoint.ParseEndpoint(ins.Endpoints, scheme); err != nil || ept != "" {
+			return scheme, ept, err
+		}
+	}
+	ept, err = endpoint.ParseEndpoint(ins.Endpoints, endpoint.Scheme("http", !r.insecure))
+	return "http", ept, err
+}
+
 func (r *resolver) Close() error {
 	return r.watcher.Stop()
 }
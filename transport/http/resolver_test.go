@@ -173,3 +173,27 @@ func TestResolver(t *testing.T) {
 		t.Errorf("expect ctx cancel err, got nil")
 	}
 }
+
+type nodesRebalancer struct {
+	nodes []selector.Node
+}
+
+func (m *nodesRebalancer) Apply(nodes []selector.Node) { m.nodes = nodes }
+
+func TestResolverPreferredSchemes(t *testing.T) {
+	rb := &nodesRebalancer{}
+	r := &resolver{rebalancer: rb, insecure: true, schemes: []string{"h3"}}
+	r.update([]*registry.ServiceInstance{
+		{ID: "1", Endpoints: []string{"http://127.0.0.1:8000", "h3://127.0.0.1:8443"}},
+		{ID: "2", Endpoints: []string{"http://127.0.0.1:9000"}},
+	})
+	if len(rb.nodes) != 2 {
+		t.Fatalf("expect 2 nodes, got %d", len(rb.nodes))
+	}
+	if got := rb.nodes[0]; got.Scheme() != "h3" || got.Address() != "127.0.0.1:8443" {
+		t.Errorf("expect h3 127.0.0.1:8443, got %s %s", got.Scheme(), got.Address())
+	}
+	if got := rb.nodes[1]; got.Scheme() != "http" || got.Address() != "127.0.0.1:9000" {
+		t.Errorf("expect http 127.0.0.1:9000, got %s %s", got.Scheme(), got.Address())
+	}
+}
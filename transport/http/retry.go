@@ -0,0 +1,355 @@
+package http
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// RetryOption is a client retry policy option.
+type RetryOption func(*retryPolicy)
+
+// RetryMax with the max number of retries after the first attempt, default is 2.
+func RetryMax(n int) RetryOption {
+	return func(p *retryPolicy) {
+		p.max = n
+	}
+}
+
+// RetryCodes with the retryable HTTP status codes instead of the errors.ClassTransient
+// errors, e.g. 500, 502, 503 and 504. Transport errors, e.g. connection refused,
+// are always retryable.
+func RetryCodes(codes ...int) RetryOption {
+	return func(p *retryPolicy) {
+		p.codes = make(map[int]struct{}, len(codes))
+		for _, c := range codes {
+			p.codes[c] = struct{}{}
+		}
+	}
+}
+
+// RetryReasons with the kratos error reasons which are retryable besides the codes.
+func RetryReasons(reasons ...string) RetryOption {
+	return func(p *retryPolicy) {
+		p.reasons = make(map[string]struct{}, len(reasons))
+		for _, r := range reasons {
+			p.reasons[r] = struct{}{}
+		}
+	}
+}
+
+// RetryBackoff with the exponential backoff base and max delay, a random jitter
+// of up to half the delay is applied. Default is 25ms and 1s. The Retry-After
+// header of a response delays the next retry instead if it is longer, unless
+// it exceeds the max delay or the deadline, which stops the retries.
+func RetryBackoff(base, max time.Duration) RetryOption {
+	return func(p *retryPolicy) {
+		p.base = base
+		p.maxDelay = max
+	}
+}
+
+// RetryPerTryTimeout with the timeout of each attempt. The client timeout applies
+// to every attempt as well, the deadline of the call context bounds all of them.
+func RetryPerTryTimeout(d time.Duration) RetryOption {
+	return func(p *retryPolicy) {
+		p.perTry = d
+	}
+}
+
+// RetryHedge sends another attempt when the previous one has not replied
+// within the delay, the first reply that is not retryable wins.
+func RetryHedge(delay time.Duration) RetryOption {
+	return func(p *retryPolicy) {
+		p.hedge = delay
+	}
+}
+
+// RetryBudget limits the retries of the client to the ratio of its requests,
+// e.g. 0.1 allows one retry for every ten requests, with up to min retries banked
+// for bursts. The budget is shared by all calls of the client.
+func RetryBudget(ratio float64, min int) RetryOption {
+	return func(p *retryPolicy) {
+		p.budget = &retryBudget{ratio: ratio, tokens: float64(min), max: float64(min)}
+		if p.budget.max < 1 {
+			p.budget.max = 1
+		}
+	}
+}
+
+type retryPolicy struct {
+	max      int
+	codes    map[int]struct{}
+	reasons  map[string]struct{}
+	base     time.Duration
+	maxDelay time.Duration
+	perTry   time.Duration
+	hedge    time.Duration
+	budget   *retryBudget
+}
+
+func newRetryPolicy(opts ...RetryOption) *retryPolicy {
+	p := &retryPolicy{
+		max:      2,
+		base:     25 * time.Millisecond,
+		maxDelay: time.Second,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// retryable reports whether the error of an attempt can be retried.
+func (p *retryPolicy) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	se := new(errors.Error)
+	if errors.As(err, &se) {
+		if _, ok := p.reasons[se.Reason]; ok {
+			return true
+		}
+		if p.codes != nil {
+			_, ok := p.codes[int(se.Code)]
+			return ok
+		}
+	}
+	return errors.Classify(err) == errors.ClassTransient
+}
+
+// backoff returns the delay before the nth retry.
+func (p *retryPolicy) backoff(n int) time.Duration {
+	d := p.base << (n - 1)
+	if d > p.maxDelay || d <= 0 {
+		d = p.maxDelay
+	}
+	half := int64(d / 2)
+	if half <= 0 {
+		return d
+	}
+	return time.Duration(half + rand.Int63n(half))
+}
+
+// delayable reports whether the retry can wait for the delay hinted by the
+// Retry-After header, which must not exceed the max backoff delay or the deadline.
+func (p *retryPolicy) delayable(ctx context.Context, delay time.Duration) bool {
+	if delay > p.maxDelay {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+	return true
+}
+
+// canRetry reports whether the request is idempotent and its body can be sent again.
+func canRetry(req *http.Request, idempotent bool) bool {
+	if !idempotent && req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+type attemptResult struct {
+	index int
+	res   *http.Response
+	err   error
+}
+
+// do sends the request with the retry policy.
+func (p *retryPolicy) do(req *http.Request, idempotent bool, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx := req.Context()
+	if !canRetry(req, idempotent) {
+		return send(req)
+	}
+	p.budget.deposit()
+	if p.hedge > 0 {
+		return p.doHedged(ctx, req, send)
+	}
+	var (
+		err  error
+		hint *retryHint
+	)
+	for n := 0; ; n++ {
+		if n > 0 {
+			if n > p.max || !p.retryable(ctx, err) || !p.delayable(ctx, hint.delay) || !p.budget.withdraw() {
+				return nil, err
+			}
+			delay := p.backoff(n)
+			if hint.delay > delay {
+				delay = hint.delay
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, err
+			case <-timer.C:
+			}
+		}
+		var (
+			res  *http.Response
+			actx context.Context
+		)
+		actx, hint = withRetryHint(ctx)
+		if res, err = p.attempt(actx, req, send, nil); err == nil {
+			return res, nil
+		}
+	}
+}
+
+func (p *retryPolicy) doHedged(ctx context.Context, req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	var (
+		results  = make(chan attemptResult, p.max+1)
+		cancels  []context.CancelFunc
+		inflight int
+		err      error
+	)
+	launch := func() {
+		actx, cancel := context.WithCancel(ctx)
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		inflight++
+		go func() {
+			res, err := p.attempt(actx, req, send, cancel)
+			results <- attemptResult{index: index, res: res, err: err}
+		}()
+	}
+	// finish cancels the other attempts and discards their replies.
+	finish := func(winner int) {
+		for i, cancel := range cancels {
+			if i != winner {
+				cancel()
+			}
+		}
+		go func(n int) {
+			for ; n > 0; n-- {
+				if r := <-results; r.res != nil {
+					r.res.Body.Close()
+				}
+			}
+		}(inflight)
+	}
+	launch()
+	timer := time.NewTimer(p.hedge)
+	defer timer.Stop()
+	for {
+		select {
+		case r := <-results:
+			inflight--
+			if r.err == nil || !p.retryable(ctx, r.err) {
+				finish(r.index)
+				return r.res, r.err
+			}
+			err = r.err
+			if len(cancels) <= p.max && p.budget.withdraw() {
+				launch()
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(p.hedge)
+			} else if inflight == 0 {
+				return nil, err
+			}
+		case <-timer.C:
+			if len(cancels) <= p.max && p.budget.withdraw() {
+				launch()
+				timer.Reset(p.hedge)
+			}
+		case <-ctx.Done():
+			finish(-1)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// attempt sends a copy of the request, the attempt context is canceled
+// when the response body is closed.
+func (p *retryPolicy) attempt(ctx context.Context, req *http.Request, send func(*http.Request) (*http.Response, error), cancel context.CancelFunc) (*http.Response, error) {
+	if p.perTry > 0 {
+		var cancelTry context.CancelFunc
+		ctx, cancelTry = context.WithTimeout(ctx, p.perTry)
+		if cancel == nil {
+			cancel = cancelTry
+		} else {
+			parent := cancel
+			cancel = func() { cancelTry(); parent() }
+		}
+	}
+	r := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			if cancel != nil {
+				cancel()
+			}
+			return nil, err
+		}
+		r.Body = body
+	}
+	res, err := send(r)
+	if cancel == nil {
+		return res, err
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// cancelBody cancels the attempt context when the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryBudget is a token bucket shared by the calls of a client,
+// every call deposits ratio tokens and every retry withdraws one.
+// A nil budget allows unlimited retries.
+type retryBudget struct {
+	mu     sync.Mutex
+	ratio  float64
+	tokens float64
+	max    float64
+}
+
+func (b *retryBudget) deposit() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens += b.ratio; b.tokens > b.max {
+		b.tokens = b.max
+	}
+}
+
+func (b *retryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
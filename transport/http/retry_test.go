@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRetryServer replies with the status of the nth request, the delay of the
+// nth request is applied before it replies.
+func newRetryServer(statuses []int, delays []time.Duration) (*httptest.Server, *int32) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&hits, 1)) - 1
+		if n < len(delays) {
+			select {
+			case <-time.After(delays[n]):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		status := http.StatusOK
+		if n < len(statuses) {
+			status = statuses[n]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"name":"kratos"}`))
+	}))
+	return srv, &hits
+}
+
+func TestClientRetry(t *testing.T) {
+	unavailable := []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}
+	tests := []struct {
+		name     string
+		method   string
+		opts     []RetryOption
+		callOpts []CallOption
+		statuses []int
+		hits     int32
+		err      bool
+	}{
+		{"get", http.MethodGet, nil, nil, unavailable, 3, false},
+		{"max", http.MethodGet, []RetryOption{RetryMax(1)}, nil, unavailable, 2, true},
+		{"not retryable", http.MethodGet, nil, nil, []int{http.StatusBadRequest}, 1, true},
+		{"transient", http.MethodGet, nil, nil, []int{http.StatusInternalServerError}, 2, false},
+		{"throttled", http.MethodGet, nil, nil, []int{http.StatusTooManyRequests}, 1, true},
+		{"codes", http.MethodGet, []RetryOption{RetryCodes(http.StatusBadRequest)}, nil, []int{http.StatusBadRequest}, 2, false},
+		{"post", http.MethodPost, nil, nil, unavailable, 1, true},
+		{"idempotent post", http.MethodPost, nil, []CallOption{Idempotent()}, unavailable, 3, false},
+		{"budget", http.MethodGet, []RetryOption{RetryBudget(0, 0)}, nil, unavailable, 1, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv, hits := newRetryServer(test.statuses, nil)
+			defer srv.Close()
+			opts := append([]RetryOption{RetryBackoff(time.Millisecond, 5*time.Millisecond)}, test.opts...)
+			client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")), WithRetry(opts...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var args interface{}
+			if test.method == http.MethodPost {
+				args = map[string]string{"name": "kratos"}
+			}
+			reply := make(map[string]string)
+			err = client.Invoke(context.Background(), test.method, "/retry", args, &reply, test.callOpts...)
+			if (err != nil) != test.err {
+				t.Fatalf("expected error %v got %v", test.err, err)
+			}
+			if got := atomic.LoadInt32(hits); got != test.hits {
+				t.Errorf("expected %d attempts got %d", test.hits, got)
+			}
+			if err == nil && reply["name"] != "kratos" {
+				t.Errorf("unexpected reply %v", reply)
+			}
+		})
+	}
+}
+
+func TestClientRetryPerTryTimeout(t *testing.T) {
+	srv, hits := newRetryServer(nil, []time.Duration{time.Second})
+	defer srv.Close()
+	client, err := NewClient(context.Background(),
+		WithEndpoint(strings.TrimPrefix(srv.URL, "http://")),
+		WithRetry(RetryPerTryTimeout(50*time.Millisecond), RetryBackoff(time.Millisecond, time.Millisecond)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := make(map[string]string)
+	if err = client.Invoke(context.Background(), http.MethodGet, "/retry", nil, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Errorf("expected 2 attempts got %d", got)
+	}
+}
+
+func TestClientRetryHedge(t *testing.T) {
+	srv, hits := newRetryServer(nil, []time.Duration{time.Second})
+	defer srv.Close()
+	client, err := NewClient(context.Background(),
+		WithEndpoint(strings.TrimPrefix(srv.URL, "http://")),
+		WithRetry(RetryHedge(20*time.Millisecond)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	reply := make(map[string]string)
+	if err = client.Invoke(context.Background(), http.MethodGet, "/retry", nil, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("expected hedged reply, took %s", d)
+	}
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Errorf("expected 2 attempts got %d", got)
+	}
+	if reply["name"] != "kratos" {
+		t.Errorf("unexpected reply %v", reply)
+	}
+}
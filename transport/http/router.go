@@ -12,16 +12,34 @@ type WalkRouteFunc func(RouteInfo) error
 type RouteInfo struct {
 	Path   string
 	Method string
+	// Params are the typed path parameters of the route, e.g. for the OpenAPI documents.
+	Params []PathParam
 }
 
 // HandlerFunc defines a function to serve HTTP requests.
 type HandlerFunc func(Context) error
 
+// RouteOperation returns a FilterFunc that sets the transport operation of a route,
+// so that the middleware selectors registered by Server.Use match manual routes
+// just like the protoc generated ones, e.g. "/helloworld.v1.Greeter/SayHello".
+// Handlers can still override it by calling SetOperation.
+func RouteOperation(operation string) FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			SetOperation(req.Context(), operation)
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
 // Router is an HTTP router.
 type Router struct {
 	prefix  string
 	srv     *Server
 	filters []FilterFunc
+	// match is the host and the headers of the routers of Server.Host and
+	// Server.Header, which is shared by the groups of them.
+	match *routeMatch
 }
 
 func newRouter(prefix string, srv *Server, filters ...FilterFunc) *Router {
@@ -38,21 +56,69 @@ func (r *Router) Group(prefix string, filters ...FilterFunc) *Router {
 	var newFilters []FilterFunc
 	newFilters = append(newFilters, r.filters...)
 	newFilters = append(newFilters, filters...)
-	return newRouter(path.Join(r.prefix, prefix), r.srv, newFilters...)
+	nr := newRouter(path.Join(r.prefix, prefix), r.srv, newFilters...)
+	nr.match = r.match
+	return nr
 }
 
 // Handle registers a new route with a matcher for the URL path and method.
 func (r *Router) Handle(method, relativePath string, h HandlerFunc, filters ...FilterFunc) {
+	r.srv.handleMatch(method, path.Join(r.prefix, relativePath), r.match, r.handler(h, filters))
+}
+
+// ExternalRouteFunc returns the path template and the path variables of a request
+// routed by another router, e.g. of gin or echo.
+type ExternalRouteFunc func(req *http.Request) (template string, vars map[string]string)
+
+// Handler returns the handler of h with the route filters, which is mounted on
+// another router, e.g. of gin or echo, during the migration. The path template
+// and the variables of the request are returned by route, and the server
+// transport is created unless the request is served by the server already,
+// e.g. by a router mounted with HandlePrefix.
+func (r *Router) Handler(h HandlerFunc, route ExternalRouteFunc, filters ...FilterFunc) http.Handler {
+	next := r.handler(h, filters)
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		template, vars := route(req)
+		if route, ok := req.Context().Value(routeKey{}).(*string); ok {
+			*route = template
+		}
+		if tr, ok := serverTransport(req); ok {
+			tr.operation, tr.pathTemplate, tr.vars = template, template, vars
+			next.ServeHTTP(res, req)
+			return
+		}
+		r.srv.filter(template)(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if tr, ok := serverTransport(req); ok {
+				tr.vars = vars
+			}
+			next.ServeHTTP(res, req)
+		})).ServeHTTP(res, req)
+	})
+}
+
+func (r *Router) handler(h HandlerFunc, filters []FilterFunc) http.Handler {
 	next := http.Handler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		ctx := &wrapper{router: r}
 		ctx.Reset(res, req)
+		// the transport request is the routed one, whose body is reset by Bind.
+		tr, ok := serverTransport(req)
+		if ok {
+			tr.request = req
+			tr.route = ctx
+		}
 		if err := h(ctx); err != nil {
+			if ok && tr.responded {
+				return
+			}
+			if e, ok := envelopeFromRequest(req); ok {
+				e.encodeError(res, req, err)
+				return
+			}
 			r.srv.ene(res, req, err)
 		}
 	}))
 	next = FilterChain(filters...)(next)
-	next = FilterChain(r.filters...)(next)
-	r.srv.router.Handle(path.Join(r.prefix, relativePath), next).Methods(method)
+	return FilterChain(r.filters...)(next)
 }
 
 // GET registers a new GET route for a path with matching handler in the router.
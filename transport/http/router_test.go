@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"runtime"
 	"strings"
@@ -15,6 +16,9 @@ import (
 	"time"
 
 	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/matcher"
+	"github.com/go-kratos/kratos/v2/transport"
 )
 
 const appJSONStr = "application/json"
@@ -248,3 +252,101 @@ func TestRouter_ContextDataRace(t *testing.T) {
 	_ = srv.Stop(ctx)
 	t.Log("test end")
 }
+
+func TestRouteOperation(t *testing.T) {
+	var matched []string
+	srv := NewServer()
+	srv.Use("/helloworld.v1.Greeter/*", func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				matched = append(matched, tr.Operation())
+			}
+			return handler(ctx, req)
+		}
+	})
+	handler := func(ctx Context) error {
+		h := ctx.Middleware(func(ctx context.Context, in interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		return ctx.Returns(h(ctx, nil))
+	}
+	route := srv.Route("/v1")
+	route.GET("/hello/{name}", handler, RouteOperation("/helloworld.v1.Greeter/SayHello"))
+	route.GET("/manual", handler)
+	route.GET("/override", func(ctx Context) error {
+		SetOperation(ctx, "/helloworld.v1.Greeter/Override")
+		return handler(ctx)
+	}, RouteOperation("/helloworld.v1.Greeter/SayHello"))
+
+	for _, path := range []string{"/v1/hello/kratos", "/v1/manual", "/v1/override"} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200 got %d", path, rec.Code)
+		}
+	}
+	want := []string{"/helloworld.v1.Greeter/SayHello", "/helloworld.v1.Greeter/Override"}
+	if !reflect.DeepEqual(matched, want) {
+		t.Errorf("expected %v got %v", want, matched)
+	}
+}
+
+func TestMiddlewareMatcher(t *testing.T) {
+	var matched []string
+	srv := NewServer(MiddlewareMatcher(matcher.NewMethod(matcher.NewRegex)))
+	srv.Use("POST ^/v1/users", func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, _ := transport.FromServerContext(ctx)
+			matched = append(matched, tr.(Transporter).Request().Method)
+			return handler(ctx, req)
+		}
+	})
+	handler := func(ctx Context) error {
+		h := ctx.Middleware(func(ctx context.Context, in interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		return ctx.Returns(h(ctx, nil))
+	}
+	route := srv.Route("/v1")
+	route.GET("/users", handler)
+	route.POST("/users", handler)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(method, "/v1/users", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200 got %d", method, rec.Code)
+		}
+	}
+	if !reflect.DeepEqual(matched, []string{http.MethodPost}) {
+		t.Errorf("expected the middleware of POST only, got %v", matched)
+	}
+}
+
+func TestRouterHandler(t *testing.T) {
+	srv := NewServer()
+	route := func(req *http.Request) (string, map[string]string) {
+		return "/users/:id", map[string]string{"id": strings.TrimPrefix(req.URL.Path, "/users/")}
+	}
+	h := srv.Route("/").Handler(func(ctx Context) error {
+		var in struct {
+			ID string `json:"id"`
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		tr, _ := transport.FromServerContext(ctx)
+		return ctx.Result(http.StatusOK, map[string]string{"id": in.ID, "operation": tr.Operation()})
+	}, route)
+
+	for _, next := range []http.Handler{h, srv.filter("/users")(h)} {
+		res := httptest.NewRecorder()
+		next.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+		if res.Code != http.StatusOK {
+			t.Fatalf("expected 200 got %d", res.Code)
+		}
+		if body := res.Body.String(); !strings.Contains(body, `"id":"42"`) || !strings.Contains(body, `"operation":"/users/:id"`) {
+			t.Errorf("unexpected body %s", body)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var routeVarPattern = regexp.MustCompile(`\{[^{}:]*(:[^{}]*)?\}`)
+
+// RouteEntry is a route of the route table of the server.
+type RouteEntry struct {
+	// Method is empty if the route matches any method.
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path"`
+	// Prefix is whether the route matches the paths of the prefix.
+	Prefix bool `json:"prefix,omitempty"`
+	// Header is the "key=value" of the routes of HandleHeader.
+	Header string `json:"header,omitempty"`
+	// Host is the host of the routes of Server.Host.
+	Host string `json:"host,omitempty"`
+	// Headers are the "key=value,..." of the routes of the Header routers.
+	Headers string `json:"headers,omitempty"`
+	// Operation is the default operation of the route, which is overridden by
+	// the handlers calling SetOperation, e.g. the generated ones.
+	Operation string `json:"operation"`
+	// Selectors are the middleware selectors matching the operation.
+	Selectors []string `json:"selectors,omitempty"`
+
+	match *routeMatch
+}
+
+func (e RouteEntry) String() string {
+	method := e.Method
+	if method == "" {
+		method = "*"
+	}
+	path := e.Path
+	if e.Prefix {
+		path += "*"
+	}
+	if e.Header != "" {
+		path = "[" + e.Header + "]"
+		if e.Prefix {
+			path = e.Path + "* " + path
+		}
+	}
+	path = e.Host + path
+	if e.Headers != "" {
+		path += " [" + e.Headers + "]"
+	}
+	return method + " " + path
+}
+
+// pattern returns the path of the route whose variable names are removed, so
+// that the paths matching the same requests are the same.
+func (e RouteEntry) pattern() string {
+	return routeVarPattern.ReplaceAllString(e.Path, "{$1}")
+}
+
+// ValidateRoutes validates the routes by Validate once the server is started,
+// which fails to start if they conflict.
+func ValidateRoutes() ServerOption {
+	return func(s *Server) {
+		s.validateRoutes = true
+	}
+}
+
+// LogRoutes logs the route table once the server is started.
+func LogRoutes() ServerOption {
+	return func(s *Server) {
+		s.logRoutes = true
+	}
+}
+
+// addRoute records the route registered, in the order of the registrations.
+func (s *Server) addRoute(e RouteEntry) {
+	s.routes = append(s.routes, e)
+}
+
+// Routes returns the route table of the server, in the order of the
+// registrations, which is the order the routes are matched in.
+func (s *Server) Routes() []RouteEntry {
+	routes := make([]RouteEntry, len(s.routes))
+	for i, e := range s.routes {
+		e.Operation = e.Path
+		if e.Header != "" {
+			e.Operation = ""
+		}
+		if e.match != nil {
+			e.Selectors = e.match.middleware.Selectors(e.Operation)
+		} else {
+			e.Selectors = s.middleware.Selectors(e.Operation)
+		}
+		routes[i] = e
+	}
+	return routes
+}
+
+// Validate detects the routes which are never matched, as they are
+// duplicated or shadowed by the routes registered before them, e.g. the
+// routes of any method or the prefix routes, since the first route matching a
+// request serves it. The routes of other hosts or headers do not conflict,
+// while the ones of any host shadow the ones of the hosts.
+func (s *Server) Validate() error {
+	routes := s.Routes()
+	var conflicts []string
+	for i, e := range routes {
+		if e.Header != "" {
+			continue
+		}
+		for _, prev := range routes[:i] {
+			if prev.Header != "" || (prev.Method != "" && prev.Method != e.Method) {
+				continue
+			}
+			if (prev.Host != "" && prev.Host != e.Host) || (prev.Headers != "" && !strings.HasPrefix(e.Headers+",", prev.Headers+",")) {
+				continue
+			}
+			if prev.Prefix {
+				if !strings.HasPrefix(e.pattern(), prev.pattern()) {
+					continue
+				}
+			} else if e.Prefix || prev.pattern() != e.pattern() {
+				continue
+			}
+			if prev.Method == e.Method && prev.Prefix == e.Prefix && prev.pattern() == e.pattern() &&
+				prev.Host == e.Host && prev.Headers == e.Headers {
+				conflicts = append(conflicts, fmt.Sprintf("%s is duplicated by %s", prev, e))
+			} else {
+				conflicts = append(conflicts, fmt.Sprintf("%s shadows %s", prev, e))
+			}
+			break
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("http: route conflicts: %s", strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// RoutesHandler returns the handler of the route table in JSON, e.g. of the
+// admin endpoint of HandleAdmin, whose conflicts are of Validate.
+func (s *Server) RoutesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		res := struct {
+			Routes    []RouteEntry `json:"routes"`
+			Conflicts string       `json:"conflicts,omitempty"`
+		}{Routes: s.Routes()}
+		if err := s.Validate(); err != nil {
+			res.Conflicts = err.Error()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	})
+}
+
+// startRoutes validates and logs the routes of the options once the server is started.
+func (s *Server) startRoutes() error {
+	if s.logRoutes {
+		routes := s.Routes()
+		sort.SliceStable(routes, func(i, j int) bool { return routes[i].Path < routes[j].Path })
+		for _, e := range routes {
+			log.Infof("[HTTP] route %s operation=%s selectors=%v", e, e.Operation, e.Selectors)
+		}
+	}
+	if s.validateRoutes {
+		return s.Validate()
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+func TestRoutes(t *testing.T) {
+	srv := NewServer(Middleware(func(h middleware.Handler) middleware.Handler { return h }))
+	srv.Use("/users/*", func(h middleware.Handler) middleware.Handler { return h })
+	h := func(ctx Context) error { return nil }
+	r := srv.Route("/")
+	r.GET("/users/{id}", h)
+	r.POST("/users", h)
+	srv.HandlePrefix("/static/", http.NotFoundHandler())
+	srv.HandleHeader("X-Debug", "1", http.NotFound)
+
+	want := []RouteEntry{
+		{Method: http.MethodGet, Path: "/users/{id}", Operation: "/users/{id}", Selectors: []string{"*", "/users/*"}},
+		{Method: http.MethodPost, Path: "/users", Operation: "/users", Selectors: []string{"*"}},
+		{Path: "/static/", Prefix: true, Operation: "/static/", Selectors: []string{"*"}},
+		{Header: "X-Debug=1", Selectors: []string{"*"}},
+	}
+	if routes := srv.Routes(); !reflect.DeepEqual(routes, want) {
+		t.Errorf("expected %+v got %+v", want, routes)
+	}
+	if err := srv.Validate(); err != nil {
+		t.Errorf("expected no conflicts, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/routes", nil)
+	w := httptest.NewRecorder()
+	srv.RoutesHandler().ServeHTTP(w, req)
+	var res struct {
+		Routes []RouteEntry `json:"routes"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil || len(res.Routes) != len(want) {
+		t.Errorf("unexpected route table %s %v", w.Body.String(), err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	srv := NewServer(ValidateRoutes(), LogRoutes())
+	h := func(ctx Context) error { return nil }
+	r := srv.Route("/")
+	r.GET("/users/{id}", h)
+	r.GET("/users/{name}", h)
+	r.GET("/users/{id:[0-9]+}/posts", h)
+	r.GET("/users/{name:[a-z]+}/posts", h)
+	srv.HandleFunc("/orders", http.NotFound)
+	r.DELETE("/orders", h)
+	srv.HandlePrefix("/files/", http.NotFoundHandler())
+	r.GET("/files/{name}", h)
+	r.PUT("/books", h)
+	r.GET("/books", h)
+
+	err := srv.Validate()
+	if err == nil {
+		t.Fatal("expected the conflicts")
+	}
+	for _, conflict := range []string{
+		"GET /users/{id} is duplicated by GET /users/{name}",
+		"* /orders shadows DELETE /orders",
+		"* /files/* shadows GET /files/{name}",
+	} {
+		if !strings.Contains(err.Error(), conflict) {
+			t.Errorf("expected %q in %v", conflict, err)
+		}
+	}
+	if strings.Contains(err.Error(), "posts") || strings.Contains(err.Error(), "books") {
+		t.Errorf("unexpected conflicts %v", err)
+	}
+	if err := srv.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "route conflicts") {
+		t.Errorf("expected the server not started, got %v", err)
+	}
+}
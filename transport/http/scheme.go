@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+)
+
+// WithSchemeTransport with the transport of the nodes advertising the endpoint
+// scheme, e.g. an HTTP/3 round tripper for "h3", which is then preferred like
+// WithPreferredSchemes. The requests are load balanced across the instances by
+// the resolver and the selector, and passed to the transport as https requests.
+func WithSchemeTransport(scheme string, trans http.RoundTripper) ClientOption {
+	return func(o *clientOptions) {
+		if o.schemeTransports == nil {
+			o.schemeTransports = make(map[string]http.RoundTripper)
+		}
+		if _, ok := o.schemeTransports[scheme]; !ok {
+			o.schemes = append(o.schemes, scheme)
+		}
+		o.schemeTransports[scheme] = trans
+	}
+}
+
+// schemeTransport routes the requests to the transport of their scheme.
+type schemeTransport struct {
+	base    http.RoundTripper
+	schemes map[string]http.RoundTripper
+}
+
+func (t *schemeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt, ok := t.schemes[req.URL.Scheme]
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	r := *req
+	u := *req.URL
+	u.Scheme = "https"
+	r.URL = &u
+	return rt.RoundTrip(&r)
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+type staticDiscovery []*registry.ServiceInstance
+
+func (d staticDiscovery) GetService(_ context.Context, _ string) ([]*registry.ServiceInstance, error) {
+	return d, nil
+}
+
+func (d staticDiscovery) Watch(ctx context.Context, _ string) (registry.Watcher, error) {
+	return &staticWatcher{ctx: ctx, instances: d}, nil
+}
+
+type staticWatcher struct {
+	ctx       context.Context
+	instances []*registry.ServiceInstance
+	once      sync.Once
+}
+
+func (w *staticWatcher) Next() (instances []*registry.ServiceInstance, err error) {
+	next := false
+	w.once.Do(func() { next = true })
+	if next {
+		return w.instances, nil
+	}
+	<-w.ctx.Done()
+	return nil, w.ctx.Err()
+}
+
+func (w *staticWatcher) Stop() error {
+	return nil
+}
+
+type recordRoundTripper struct {
+	mu    sync.Mutex
+	hosts map[string]int
+}
+
+func (rt *recordRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.hosts[req.URL.Scheme+"://"+req.URL.Host]++
+	rt.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func TestWithSchemeTransport(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h3 := &recordRoundTripper{hosts: make(map[string]int)}
+	base := &recordRoundTripper{hosts: make(map[string]int)}
+	client, err := NewClient(ctx,
+		WithEndpoint("discovery:///helloworld"),
+		WithBlock(),
+		WithDiscovery(staticDiscovery{
+			{ID: "1", Endpoints: []string{"http://127.0.0.1:8001", "h3://127.0.0.1:8443"}},
+			{ID: "2", Endpoints: []string{"http://127.0.0.1:8002", "h3://127.0.0.1:8444"}},
+		}),
+		WithTransport(base),
+		WithSchemeTransport("h3", h3),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for i := 0; i < 20; i++ {
+		var reply struct{}
+		if err = client.Invoke(ctx, http.MethodGet, "/hello", nil, &reply); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(base.hosts) != 0 {
+		t.Errorf("expected no base requests, got %v", base.hosts)
+	}
+	if len(h3.hosts) != 2 || h3.hosts["https://127.0.0.1:8443"] == 0 || h3.hosts["https://127.0.0.1:8444"] == 0 {
+		t.Errorf("expected the requests balanced over the h3 endpoints, got %v", h3.hosts)
+	}
+}
@@ -4,19 +4,26 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 
-	"github.com/gorilla/mux"
-
+	"github.com/go-kratos/kratos/v2/internal/activation"
 	"github.com/go-kratos/kratos/v2/internal/endpoint"
 	"github.com/go-kratos/kratos/v2/internal/host"
-	"github.com/go-kratos/kratos/v2/internal/matcher"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/matcher"
 	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/tlscert"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/net/netutil"
 )
 
 var (
@@ -69,6 +76,24 @@ func Middleware(m ...middleware.Middleware) ServerOption {
 	}
 }
 
+// MiddlewareMatcher with the matcher of the middleware of the selectors of Use,
+// e.g. matcher.NewRegex, default is the exact and prefix matcher of matcher.New.
+// It replaces the matcher, so it must precede the Middleware option.
+func MiddlewareMatcher(m matcher.Matcher) ServerOption {
+	return func(s *Server) {
+		s.middleware = m
+	}
+}
+
+// MiddlewareObserver with the observer of each middleware of the requests,
+// e.g. metrics.Observer or tracing.Observer, to record the latency and the
+// errors of each of them, default is none.
+func MiddlewareObserver(o middleware.Observer) ServerOption {
+	return func(s *Server) {
+		s.observer = o
+	}
+}
+
 // Filter with HTTP middleware option.
 func Filter(filters ...FilterFunc) ServerOption {
 	return func(o *Server) {
@@ -118,6 +143,24 @@ func TLSConfig(c *tls.Config) ServerOption {
 	}
 }
 
+// TLSProvider with the certificate provider, e.g. tlscert.FileProvider reloading
+// the rotated certificate files, or autocert.Manager for the ACME certificates.
+// It is applied to the TLSConfig, or a default TLS config if not set.
+func TLSProvider(p tlscert.Provider) ServerOption {
+	return func(o *Server) {
+		o.tlsProvider = p
+	}
+}
+
+// UnsafeTLSKeyLogWriter with the writer of the TLS master secrets, so that the
+// HTTPS and HTTP/2 sessions can be decrypted by e.g. Wireshark in test environments.
+// It is ignored when TLS is not configured, DO NOT use it in production.
+func UnsafeTLSKeyLogWriter(w io.Writer) ServerOption {
+	return func(o *Server) {
+		o.keyLog = w
+	}
+}
+
 // StrictSlash is with mux's StrictSlash
 // If true, when the path pattern is "/path/", accessing "/path" will
 // redirect to the former and vice versa.
@@ -134,32 +177,224 @@ func Listener(lis net.Listener) ServerOption {
 	}
 }
 
-// PathPrefix with mux's PathPrefix, router will be replaced by a subrouter that start with prefix.
+// ListenerFromFD with the listener inherited from the file descriptor,
+// e.g. passed by the parent process for the zero-downtime restarts.
+func ListenerFromFD(fd uintptr) ServerOption {
+	return func(s *Server) {
+		lis, err := activation.FileListener(fd, "listener")
+		s.inheritListener(lis, err)
+	}
+}
+
+// SocketActivation with the listener passed by the systemd socket activation,
+// the name is the FileDescriptorName of the socket unit, empty means the first one.
+func SocketActivation(name string) ServerOption {
+	return func(s *Server) {
+		lis, err := activation.Listener(name)
+		s.inheritListener(lis, err)
+	}
+}
+
+// PathPrefix with mux's PathPrefix, all the routes will be registered with the prefix.
 func PathPrefix(prefix string) ServerOption {
 	return func(s *Server) {
-		s.router = s.router.PathPrefix(prefix).Subrouter()
+		s.prefix += prefix
+	}
+}
+
+// EnableH2C with HTTP/2 cleartext support, which serves HTTP/2 without TLS.
+func EnableH2C() ServerOption {
+	return func(s *Server) {
+		s.h2c = true
 	}
 }
 
+// EnableTimeline records the decode, middleware, handler and encode durations of
+// every request, which can be read by TimelineFromServerContext.
+func EnableTimeline() ServerOption {
+	return func(s *Server) {
+		s.timeline = true
+	}
+}
+
+// ServerTiming emits the phases recorded before the response header is written
+// as a Server-Timing response header, it implies EnableTimeline.
+func ServerTiming() ServerOption {
+	return func(s *Server) {
+		s.timeline = true
+		s.serverTiming = true
+	}
+}
+
+// StrictContentType rejects request bodies whose Content-Type has no registered codec
+// with 415 instead of 400.
+func StrictContentType() ServerOption {
+	return func(s *Server) {
+		s.strictContentType = true
+	}
+}
+
+// MaxConnections limits the number of simultaneously accepted connections,
+// further connections wait in the listen backlog until one is closed.
+func MaxConnections(n int) ServerOption {
+	return func(s *Server) {
+		s.maxConns = n
+	}
+}
+
+// MaxConcurrentStreams limits the number of concurrent HTTP/2 streams per connection,
+// it applies to TLS and h2c connections.
+func MaxConcurrentStreams(n uint32) ServerOption {
+	return func(s *Server) {
+		s.maxStreams = n
+	}
+}
+
+// ReadHeaderTimeout with the timeout of reading the request headers, which
+// protects the server from the slow clients, e.g. of the slowloris.
+func ReadHeaderTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.readHeaderTimeout = timeout
+	}
+}
+
+// ReadTimeout with the timeout of reading the entire request, including the body.
+func ReadTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.readTimeout = timeout
+	}
+}
+
+// WriteTimeout with the timeout of writing the response, which is extended of
+// the routes by RouteWriteTimeout, e.g. of the streaming ones.
+func WriteTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.writeTimeout = timeout
+	}
+}
+
+// IdleTimeout with the timeout of the keep-alive connections waiting for the
+// next request, default is the ReadTimeout.
+func IdleTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.idleTimeout = timeout
+	}
+}
+
+// RateLimitPerIP limits the requests per second of each client IP with the burst size,
+// which is forwarded by the TrustedProxies if any, requests over the limit are
+// rejected with ErrTooManyRequests.
+func RateLimitPerIP(rps float64, burst int) ServerOption {
+	return func(s *Server) {
+		s.ipLimiter = newIPLimiter(rps, burst)
+	}
+}
+
+// RouterMux with the router backend, default is gorilla/mux.
+// The StrictSlash option only applies to the default backend.
+func RouterMux(m Mux) ServerOption {
+	return func(s *Server) {
+		s.router = m
+	}
+}
+
+// AdminAuth with the filter guarding the admin handlers registered by HandleAdmin,
+// e.g. the admin.Auth filter, default only allows the loopback peers.
+func AdminAuth(f FilterFunc) ServerOption {
+	return func(s *Server) {
+		s.adminAuth = f
+	}
+}
+
+// AdvertiseEndpoint with the additional endpoints advertised to the registry
+// besides the server endpoint, e.g. h3://host:port of a QUIC listener.
+func AdvertiseEndpoint(endpoints ...*url.URL) ServerOption {
+	return func(s *Server) {
+		s.advertised = append(s.advertised, endpoints...)
+	}
+}
+
+// ContextValue with the value of the key in the contexts of all the requests,
+// e.g. an application scoped client, before the filters and the middleware.
+func ContextValue(key, val interface{}) ServerOption {
+	return ContextFunc(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, key, val)
+	})
+}
+
+// ContextFunc with the func deriving the contexts of all the requests, e.g.
+// of the values of the application, before the filters and the middleware.
+func ContextFunc(f func(context.Context) context.Context) ServerOption {
+	return func(s *Server) {
+		s.contextFuncs = append(s.contextFuncs, f)
+	}
+}
+
+// contextValues derives the contexts of the requests by the ContextFunc options.
+func (s *Server) contextValues(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		for _, f := range s.contextFuncs {
+			ctx = f(ctx)
+		}
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
 // Server is an HTTP server wrapper.
 type Server struct {
 	*http.Server
-	lis         net.Listener
-	tlsConf     *tls.Config
-	endpoint    *url.URL
-	err         error
-	network     string
-	address     string
-	timeout     time.Duration
-	filters     []FilterFunc
-	middleware  matcher.Matcher
-	decVars     DecodeRequestFunc
-	decQuery    DecodeRequestFunc
-	decBody     DecodeRequestFunc
-	enc         EncodeResponseFunc
-	ene         EncodeErrorFunc
-	strictSlash bool
-	router      *mux.Router
+	lis               net.Listener
+	tlsConf           *tls.Config
+	tlsProvider       tlscert.Provider
+	keyLog            io.Writer
+	endpoint          *url.URL
+	err               error
+	network           string
+	address           string
+	timeout           time.Duration
+	filters           []FilterFunc
+	middleware        matcher.Matcher
+	observer          middleware.Observer
+	decVars           DecodeRequestFunc
+	decQuery          DecodeRequestFunc
+	decBody           DecodeRequestFunc
+	enc               EncodeResponseFunc
+	ene               EncodeErrorFunc
+	strictSlash       bool
+	prefix            string
+	router            Mux
+	h2c               bool
+	timeline          bool
+	serverTiming      bool
+	strictContentType bool
+	maxConns          int
+	maxStreams        uint32
+	readHeaderTimeout time.Duration
+	readTimeout       time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	ipLimiter         *ipLimiter
+	adminAuth         FilterFunc
+	advertised        []*url.URL
+	pathParams        map[string][]PathParam
+	console           *console
+	polls             *pollHub
+	pollLimit         int
+	trustedProxies    []*net.IPNet
+	contextFuncs      []func(context.Context) context.Context
+	statusMapper      StatusMapFunc
+	catalog           Catalog
+	sessions          *sessionOptions
+	versions          []*apiVersion
+	acceptVersion     string
+	routeVersions     bool
+	partial           bool
+	routes            []RouteEntry
+	validateRoutes    bool
+	logRoutes         bool
+	draining          atomic.Bool
+	liveTimeout       atomic.Pointer[time.Duration]
 }
 
 // NewServer creates an HTTP server by options.
@@ -175,22 +410,74 @@ func NewServer(opts ...ServerOption) *Server {
 		enc:         DefaultResponseEncoder,
 		ene:         DefaultErrorEncoder,
 		strictSlash: true,
-		router:      mux.NewRouter(),
+		adminAuth:   loopbackOnly,
+		polls:       newPollHub(),
 	}
 	for _, o := range opts {
 		o(srv)
 	}
-	srv.router.StrictSlash(srv.strictSlash)
-	srv.router.NotFoundHandler = http.DefaultServeMux
-	srv.router.MethodNotAllowedHandler = http.DefaultServeMux
-	srv.router.Use(srv.filter())
+	if srv.partial {
+		srv.enc = partialResponse(srv.enc)
+	}
+	if srv.catalog != nil {
+		srv.ene = localize(srv.catalog, srv.ene)
+	}
+	if srv.statusMapper != nil {
+		srv.ene = mapStatus(srv.statusMapper, srv.ene)
+	}
+	if srv.router == nil {
+		srv.router = newGorillaMux(srv.strictSlash)
+	}
+	if srv.tlsProvider != nil {
+		srv.tlsConf = tlscert.Config(srv.tlsConf, srv.tlsProvider)
+	}
+	srv.tlsConf = tlscert.KeyLog(srv.tlsConf, srv.keyLog)
+	srv.router.NotFound(http.DefaultServeMux)
+	srv.router.MethodNotAllowed(http.DefaultServeMux)
+	if srv.console != nil {
+		srv.mountConsole()
+	}
+	var router http.Handler = srv.router
+	if srv.routeVersions {
+		router = srv.routeVersion(router)
+	}
+	var handler http.Handler = FilterChain(srv.filters...)(router)
+	if len(srv.contextFuncs) > 0 {
+		handler = srv.contextValues(handler)
+	}
+	if srv.h2c {
+		handler = h2c.NewHandler(handler, &http2.Server{MaxConcurrentStreams: srv.maxStreams})
+	}
 	srv.Server = &http.Server{
-		Handler:   FilterChain(srv.filters...)(srv.router),
-		TLSConfig: srv.tlsConf,
+		Handler:           handler,
+		TLSConfig:         srv.tlsConf,
+		ReadHeaderTimeout: srv.readHeaderTimeout,
+		ReadTimeout:       srv.readTimeout,
+		WriteTimeout:      srv.writeTimeout,
+		IdleTimeout:       srv.idleTimeout,
+	}
+	if srv.maxStreams > 0 && srv.tlsConf != nil {
+		if err := http2.ConfigureServer(srv.Server, &http2.Server{MaxConcurrentStreams: srv.maxStreams}); err != nil {
+			srv.err = err
+		}
 	}
 	return srv
 }
 
+// SetTimeout sets the timeout of the requests at runtime, e.g. bound to a
+// watched config key by config.Bind, 0 means no timeout.
+func (s *Server) SetTimeout(timeout time.Duration) {
+	s.liveTimeout.Store(&timeout)
+}
+
+// requestTimeout returns the timeout of SetTimeout, or the Timeout option.
+func (s *Server) requestTimeout() time.Duration {
+	if timeout := s.liveTimeout.Load(); timeout != nil {
+		return *timeout
+	}
+	return s.timeout
+}
+
 // Use uses a service middleware with selector.
 // selector:
 //   - '/*'
@@ -200,23 +487,17 @@ func (s *Server) Use(selector string, m ...middleware.Middleware) {
 	s.middleware.Add(selector, m...)
 }
 
+// MiddlewareSelectors returns the selectors of the middleware registered by
+// Use matching the operation, "*" is of the Middleware option.
+func (s *Server) MiddlewareSelectors(operation string) []string {
+	return s.middleware.Selectors(operation)
+}
+
 // WalkRoute walks the router and all its sub-routers, calling walkFn for each route in the tree.
 func (s *Server) WalkRoute(fn WalkRouteFunc) error {
-	return s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
-		methods, err := route.GetMethods()
-		if err != nil {
-			return nil // ignore no methods
-		}
-		path, err := route.GetPathTemplate()
-		if err != nil {
-			return err
-		}
-		for _, method := range methods {
-			if err := fn(RouteInfo{Method: method, Path: path}); err != nil {
-				return err
-			}
-		}
-		return nil
+	return s.router.Walk(func(r RouteInfo) error {
+		r.Params = s.pathParams[r.Method+" "+r.Path]
+		return fn(r)
 	})
 }
 
@@ -235,22 +516,69 @@ func (s *Server) Route(prefix string, filters ...FilterFunc) *Router {
 
 // Handle registers a new route with a matcher for the URL path.
 func (s *Server) Handle(path string, h http.Handler) {
-	s.router.Handle(path, h)
+	s.handle("", path, h)
 }
 
 // HandlePrefix registers a new route with a matcher for the URL path prefix.
 func (s *Server) HandlePrefix(prefix string, h http.Handler) {
-	s.router.PathPrefix(prefix).Handler(h)
+	prefix = s.prefix + prefix
+	s.addRoute(RouteEntry{Path: prefix, Prefix: true})
+	s.registered(s.router.HandlePrefix(prefix, s.filter(prefix)(h)))
+}
+
+// HandleAdmin registers an admin handler with a matcher for the URL path prefix,
+// e.g. pprof or the config dump, which is guarded by the AdminAuth filter.
+func (s *Server) HandleAdmin(prefix string, h http.Handler) {
+	s.HandlePrefix(prefix, s.adminAuth(h))
 }
 
 // HandleFunc registers a new route with a matcher for the URL path.
 func (s *Server) HandleFunc(path string, h http.HandlerFunc) {
-	s.router.HandleFunc(path, h)
+	s.handle("", path, h)
 }
 
-// HandleHeader registers a new route with a matcher for the header.
+// HandleHeader registers a new route with a matcher for the header, of the
+// paths of the PathPrefix if any.
 func (s *Server) HandleHeader(key, val string, h http.HandlerFunc) {
-	s.router.Headers(key, val).Handler(h)
+	s.addRoute(RouteEntry{Path: s.prefix, Prefix: s.prefix != "", Header: key + "=" + val})
+	s.registered(s.router.HandleHeader(s.prefix, key, val, s.filter("")(h)))
+}
+
+// registered records the first error of the route registrations, which is
+// returned by Start.
+func (s *Server) registered(err error) {
+	if err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// handle registers a route whose handler is wrapped by the server filter.
+func (s *Server) handle(method, path string, h http.Handler) {
+	s.handleMatch(method, path, nil, h)
+}
+
+// handleMatch registers a route of the host and the headers of m, if any.
+func (s *Server) handleMatch(method, path string, m *routeMatch, h http.Handler) {
+	path, params := parsePathParams(s.prefix + path)
+	if len(params) > 0 {
+		if s.pathParams == nil {
+			s.pathParams = make(map[string][]PathParam)
+		}
+		s.pathParams[method+" "+path] = params
+		h = s.validatePathParams(params)(h)
+	}
+	if m == nil {
+		s.addRoute(RouteEntry{Method: method, Path: path})
+		s.registered(s.router.Handle(method, path, s.filter(path)(h)))
+		return
+	}
+	mm, ok := s.router.(MatchMux)
+	if !ok {
+		s.registered(fmt.Errorf("http: the router mux %T does not match the hosts and the headers", s.router))
+		return
+	}
+	s.addRoute(RouteEntry{Method: method, Path: path, Host: m.host, Headers: m.String(), match: m})
+	s.registered(mm.HandleMatch(method, m.host, path, m.headers, s.filter(path)(h)))
 }
 
 // ServeHTTP should write reply headers and data to the ResponseWriter and then return.
@@ -258,36 +586,66 @@ func (s *Server) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	s.Handler.ServeHTTP(res, req)
 }
 
-func (s *Server) filter() mux.MiddlewareFunc {
+// filter creates the server Transport of the route with the given path template,
+// an empty template means the request path is used.
+func (s *Server) filter(template string) FilterFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			var (
 				ctx    context.Context
 				cancel context.CancelFunc
 			)
-			if s.timeout > 0 {
-				ctx, cancel = context.WithTimeout(req.Context(), s.timeout)
+			if timeout := s.requestTimeout(); timeout > 0 {
+				ctx, cancel = context.WithTimeout(req.Context(), timeout)
 			} else {
 				ctx, cancel = context.WithCancel(req.Context())
 			}
 			defer cancel()
 
-			pathTemplate := req.URL.Path
-			if route := mux.CurrentRoute(req); route != nil {
-				// /path/123 -> /path/{id}
-				pathTemplate, _ = route.GetPathTemplate()
+			if s.draining.Load() {
+				w.Header().Set(DrainHeader, "true")
+			}
+			clientIP, scheme := realClient(req, s.trustedProxies)
+			if s.ipLimiter != nil && !s.ipLimiter.allow(clientIP) {
+				s.ene(w, req, ErrTooManyRequests)
+				return
+			}
+
+			// /path/123 -> /path/{id}
+			pathTemplate := template
+			if pathTemplate == "" {
+				pathTemplate = req.URL.Path
+			}
+			if route, ok := req.Context().Value(routeKey{}).(*string); ok {
+				*route = pathTemplate
 			}
 
+			// the transport is not pooled, as the request context carrying it may
+			// outlive the request, e.g. of the goroutines of the handlers.
 			tr := &Transport{
 				operation:    pathTemplate,
 				pathTemplate: pathTemplate,
+				clientIP:     clientIP,
+				scheme:       scheme,
 				reqHeader:    headerCarrier(req.Header),
 				replyHeader:  headerCarrier(w.Header()),
 				request:      req,
+				mux:          s.router,
 			}
 			if s.endpoint != nil {
 				tr.endpoint = s.endpoint.String()
 			}
+			capture, _ := req.Context().Value(captureTimelineKey{}).(bool)
+			if s.timeline || capture {
+				tr.timeline = &Timeline{}
+				if s.serverTiming || capture {
+					tr.serverTiming = true
+					w = &timingWriter{ResponseWriter: w, timeline: tr.timeline}
+				}
+			}
+			if operation, ok := req.Context().Value(operationKey{}).(*string); ok {
+				defer func() { *operation = tr.operation }()
+			}
 			tr.request = req.WithContext(transport.NewServerContext(ctx, tr))
 			next.ServeHTTP(w, tr.request)
 		})
@@ -306,8 +664,20 @@ func (s *Server) Endpoint() (*url.URL, error) {
 	return s.endpoint, nil
 }
 
+// Endpoints returns the server endpoint and the advertised endpoints.
+func (s *Server) Endpoints() ([]*url.URL, error) {
+	e, err := s.Endpoint()
+	if err != nil {
+		return nil, err
+	}
+	return append([]*url.URL{e}, s.advertised...), nil
+}
+
 // Start start the HTTP server.
 func (s *Server) Start(ctx context.Context) error {
+	if err := s.startRoutes(); err != nil {
+		return err
+	}
 	if err := s.listenAndEndpoint(); err != nil {
 		return err
 	}
@@ -315,11 +685,15 @@ func (s *Server) Start(ctx context.Context) error {
 		return ctx
 	}
 	log.Infof("[HTTP] server listening on: %s", s.lis.Addr().String())
+	lis := s.lis
+	if s.maxConns > 0 {
+		lis = netutil.LimitListener(lis, s.maxConns)
+	}
 	var err error
 	if s.tlsConf != nil {
-		err = s.ServeTLS(s.lis, "", "")
+		err = s.ServeTLS(lis, "", "")
 	} else {
-		err = s.Serve(s.lis)
+		err = s.Serve(lis)
 	}
 	if !errors.Is(err, http.ErrServerClosed) {
 		return err
@@ -330,10 +704,36 @@ func (s *Server) Start(ctx context.Context) error {
 // Stop stop the HTTP server.
 func (s *Server) Stop(ctx context.Context) error {
 	log.Info("[HTTP] server stopping")
+	s.polls.close()
 	return s.Shutdown(ctx)
 }
 
+// Drain disables the keep-alives so that the clients reconnect to the other
+// instances, the idle connections are closed and the others after their
+// current requests. The responses have the DrainHeader from now on.
+func (s *Server) Drain(_ context.Context) error {
+	log.Info("[HTTP] server draining")
+	s.draining.Store(true)
+	s.SetKeepAlivesEnabled(false)
+	return nil
+}
+
+// inheritListener uses the inherited listener, the endpoint is
+// resolved from the address which the listener is bound to.
+func (s *Server) inheritListener(lis net.Listener, err error) {
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.lis = lis
+	s.network = lis.Addr().Network()
+	s.address = lis.Addr().String()
+}
+
 func (s *Server) listenAndEndpoint() error {
+	if s.err != nil {
+		return s.err
+	}
 	if s.lis == nil {
 		lis, err := net.Listen(s.network, s.address)
 		if err != nil {
@@ -343,6 +743,10 @@ func (s *Server) listenAndEndpoint() error {
 		s.lis = lis
 	}
 	if s.endpoint == nil {
+		if addr, ok := s.lis.Addr().(*net.UnixAddr); ok {
+			s.endpoint = endpoint.NewUnixEndpoint(endpoint.Scheme("http", s.tlsConf != nil), addr.Name)
+			return s.err
+		}
 		addr, err := host.Extract(s.address, s.lis)
 		if err != nil {
 			s.err = err
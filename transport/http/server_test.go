@@ -9,6 +9,8 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
@@ -16,6 +18,10 @@ import (
 
 	kratoserrors "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+
+	"golang.org/x/net/http2"
 )
 
 var h = func(w http.ResponseWriter, r *http.Request) {
@@ -281,6 +287,32 @@ func BenchmarkServer(b *testing.B) {
 	_ = srv.Stop(ctx)
 }
 
+func BenchmarkServerBind(b *testing.B) {
+	srv := NewServer()
+	srv.Route("/").POST("/index", func(ctx Context) error {
+		var in testData
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusOK, &in)
+	})
+	srv.Route("/").GET("/healthz", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "ok")
+	})
+	body := `{"path":"/index"}`
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/index", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			b.Fatalf("expected 200 got %d", w.Code)
+		}
+	}
+}
+
 func TestNetwork(t *testing.T) {
 	o := &Server{}
 	v := "abc"
@@ -348,6 +380,44 @@ func TestTLSConfig(t *testing.T) {
 	}
 }
 
+func TestEnableH2C(t *testing.T) {
+	srv := NewServer(EnableH2C())
+	srv.HandleFunc("/proto", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+	resp, err := client.Get("http://" + e.Host + "/proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "HTTP/2.0" {
+		t.Errorf("expected HTTP/2.0 got %s", b)
+	}
+}
+
 func TestStrictSlash(t *testing.T) {
 	o := &Server{}
 	v := true
@@ -371,3 +441,191 @@ func TestListener(t *testing.T) {
 		t.Errorf("expected not empty")
 	}
 }
+
+func TestAdvertiseEndpoint(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	h3 := &url.URL{Scheme: "h3", Host: lis.Addr().String()}
+	s := NewServer(Listener(lis), AdvertiseEndpoint(h3))
+	es, err := s.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 2 || es[0].Scheme != "http" || es[1] != h3 {
+		t.Errorf("expected http and h3 endpoints, got %v", es)
+	}
+}
+
+func TestListenerFromFD(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	f, err := lis.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	s := NewServer(ListenerFromFD(f.Fd()))
+	e, err := s.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Host != lis.Addr().String() {
+		t.Errorf("expected %s got %s", lis.Addr(), e.Host)
+	}
+	_ = s.lis.Close()
+
+	s = NewServer(ListenerFromFD(^uintptr(0)))
+	if _, err = s.Endpoint(); err == nil {
+		t.Errorf("expected error got nil")
+	}
+}
+
+type testCertProvider struct{}
+
+func (testCertProvider) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return &tls.Certificate{}, nil
+}
+
+func TestTLSProvider(t *testing.T) {
+	s := NewServer(TLSConfig(&tls.Config{MinVersion: tls.VersionTLS13}), TLSProvider(testCertProvider{}))
+	if s.tlsConf == nil || s.tlsConf.GetCertificate == nil {
+		t.Fatal("expected tls config with GetCertificate")
+	}
+	if s.tlsConf.MinVersion != tls.VersionTLS13 {
+		t.Errorf("expected %v got %v", tls.VersionTLS13, s.tlsConf.MinVersion)
+	}
+	if s.Server.TLSConfig != s.tlsConf {
+		t.Errorf("expected the tls config applied to the server")
+	}
+}
+
+func TestHandleAdmin(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("admin"))
+	})
+	srv := NewServer()
+	srv.HandleAdmin("/debug", handler)
+	tests := []struct {
+		remoteAddr string
+		code       int
+	}{
+		{"127.0.0.1:1234", http.StatusOK},
+		{"[::1]:1234", http.StatusOK},
+		{"10.0.0.1:1234", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+		req.RemoteAddr = test.remoteAddr
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s: expected %d got %d", test.remoteAddr, test.code, res.Code)
+		}
+	}
+
+	srv = NewServer(AdminAuth(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("X-Admin") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}))
+	srv.HandleAdmin("/debug", handler)
+	req := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if res.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 got %d", res.Code)
+	}
+	req.Header.Set("X-Admin", "1")
+	res = httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if res.Code != http.StatusOK || res.Body.String() != "admin" {
+		t.Errorf("expected admin got %d %q", res.Code, res.Body.String())
+	}
+}
+
+func TestContextValues(t *testing.T) {
+	type dbKey struct{}
+	type flagsKey struct{}
+	var filtered interface{}
+	srv := NewServer(
+		ContextValue(dbKey{}, "db"),
+		ContextFunc(func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, flagsKey{}, ctx.Value(dbKey{}).(string)+"+flags")
+		}),
+		Filter(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				filtered = req.Context().Value(dbKey{})
+				next.ServeHTTP(w, req)
+			})
+		}),
+	)
+	srv.Route("/").GET("/values", func(ctx Context) error {
+		h := ctx.Middleware(func(ctx context.Context, _ interface{}) (interface{}, error) {
+			return map[string]interface{}{"db": ctx.Value(dbKey{}), "flags": ctx.Value(flagsKey{})}, nil
+		})
+		reply, err := h(ctx, nil)
+		if err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusOK, reply)
+	})
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/values", nil))
+	if body := strings.TrimSpace(res.Body.String()); body != `{"db":"db","flags":"db+flags"}` {
+		t.Errorf("unexpected values %s", body)
+	}
+	if filtered != "db" {
+		t.Errorf("expected the value in the filters got %v", filtered)
+	}
+}
+
+func TestMiddlewareObserver(t *testing.T) {
+	var names []string
+	srv := NewServer(
+		Middleware(func(h middleware.Handler) middleware.Handler { return h }),
+		MiddlewareObserver(func(ctx context.Context, name string) (context.Context, func(time.Duration, error)) {
+			return ctx, func(time.Duration, error) { names = append(names, name) }
+		}),
+	)
+	srv.Route("/").GET("/", func(ctx Context) error {
+		_, err := ctx.Middleware(func(context.Context, interface{}) (interface{}, error) { return nil, nil })(ctx, nil)
+		return err
+	})
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !reflect.DeepEqual(names, []string{"http.TestMiddlewareObserver"}) {
+		t.Errorf("expected the observed middleware, got %v", names)
+	}
+}
+
+func TestServerTransportOutlivesRequest(t *testing.T) {
+	srv := NewServer()
+	done := make(chan string, 1)
+	srv.Route("/").GET("/a", func(ctx Context) error {
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			tr, _ := transport.FromServerContext(ctx)
+			done <- tr.Operation()
+		}()
+		return ctx.String(http.StatusOK, "a")
+	})
+	srv.Route("/").GET("/b", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "b")
+	})
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	for i := 0; i < 10; i++ {
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+	}
+	if operation := <-done; operation != "/a" {
+		t.Errorf("expected the operation of the request, got %q", operation)
+	}
+}
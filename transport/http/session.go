@@ -0,0 +1,307 @@
+package http
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxCookieSize is the max size of the cookies of the browsers.
+const maxCookieSize = 4096
+
+var (
+	// ErrNoSessionStore is returned by the sessions of Context if the server
+	// has no Sessions option.
+	ErrNoSessionStore = errors.New("http: no session store")
+	// ErrSessionInvalid is returned by SessionStore.Load if the cookie is
+	// invalid, tampered or expired, the session of it is a new one.
+	ErrSessionInvalid = errors.New("http: session is invalid")
+	// ErrSessionTooLarge is returned by CookieStore.Save if the session
+	// exceeds the max size of the cookies.
+	ErrSessionTooLarge = errors.New("http: session is too large")
+)
+
+// SessionStore is the store of the sessions of the cookies, e.g. the
+// CookieStore, or the stores keeping the sessions of the IDs of the cookies.
+type SessionStore interface {
+	// Load returns the values and the expiry of the session of the cookie
+	// value, ErrSessionInvalid if it is invalid or expired.
+	Load(ctx context.Context, cookie string) (values map[string]string, expires time.Time, err error)
+	// Save saves the values of the session of the cookie value, which is empty
+	// for a new session, until the expiry, and returns the cookie value of it.
+	Save(ctx context.Context, cookie string, values map[string]string, expires time.Time) (string, error)
+	// Delete deletes the session of the cookie value.
+	Delete(ctx context.Context, cookie string) error
+}
+
+// SessionOption is the session option of the Sessions option.
+type SessionOption func(*sessionOptions)
+
+type sessionOptions struct {
+	store    SessionStore
+	name     string
+	path     string
+	domain   string
+	maxAge   time.Duration
+	sameSite http.SameSite
+	insecure bool
+}
+
+// SessionName with the name of the session cookie, default is "session".
+func SessionName(name string) SessionOption {
+	return func(o *sessionOptions) {
+		o.name = name
+	}
+}
+
+// SessionPath with the path of the session cookie, default is "/".
+func SessionPath(path string) SessionOption {
+	return func(o *sessionOptions) {
+		o.path = path
+	}
+}
+
+// SessionDomain with the domain of the session cookie, default is the host.
+func SessionDomain(domain string) SessionOption {
+	return func(o *sessionOptions) {
+		o.domain = domain
+	}
+}
+
+// SessionMaxAge with the max age of the sessions, default is 24h. The
+// sessions are renewed once half of it is passed, so that the active ones
+// are not expired.
+func SessionMaxAge(d time.Duration) SessionOption {
+	return func(o *sessionOptions) {
+		o.maxAge = d
+	}
+}
+
+// SessionSameSite with the SameSite of the session cookie, default is Lax.
+func SessionSameSite(mode http.SameSite) SessionOption {
+	return func(o *sessionOptions) {
+		o.sameSite = mode
+	}
+}
+
+// SessionInsecure sets the session cookie without Secure, e.g. of the local
+// development over plain HTTP.
+func SessionInsecure() SessionOption {
+	return func(o *sessionOptions) {
+		o.insecure = true
+	}
+}
+
+// Sessions with the store of the sessions of Context.GetSession and
+// Context.SetSession, whose cookies are HttpOnly and Secure:
+//
+//	store, err := http.NewCookieStore(newKey, oldKey)
+//	srv := http.NewServer(http.Sessions(store, http.SessionMaxAge(time.Hour)))
+func Sessions(store SessionStore, opts ...SessionOption) ServerOption {
+	return func(s *Server) {
+		o := &sessionOptions{
+			store:    store,
+			name:     "session",
+			path:     "/",
+			maxAge:   24 * time.Hour,
+			sameSite: http.SameSiteLaxMode,
+		}
+		for _, opt := range opts {
+			opt(o)
+		}
+		s.sessions = o
+	}
+}
+
+// session is the session of a request, which is loaded once.
+type session struct {
+	cookie string
+	values map[string]string
+}
+
+func (c *wrapper) GetSession(key string) (string, error) {
+	s, err := c.loadSession()
+	if err != nil {
+		return "", err
+	}
+	return s.values[key], nil
+}
+
+func (c *wrapper) SetSession(key, value string) error {
+	s, err := c.loadSession()
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		if _, ok := s.values[key]; !ok {
+			return nil
+		}
+		delete(s.values, key)
+	} else {
+		s.values[key] = value
+	}
+	return c.saveSession(s)
+}
+
+// loadSession loads the session of the cookie, which is renewed once half of
+// the max age is passed, a new session is of no or an invalid cookie.
+func (c *wrapper) loadSession() (*session, error) {
+	if c.session != nil {
+		return c.session, nil
+	}
+	o := c.router.srv.sessions
+	if o == nil {
+		return nil, ErrNoSessionStore
+	}
+	s := &session{values: make(map[string]string)}
+	if cookie, err := c.req.Cookie(o.name); err == nil && cookie.Value != "" {
+		values, expires, err := o.store.Load(c.req.Context(), cookie.Value)
+		switch {
+		case err == nil:
+			s.cookie = cookie.Value
+			if values != nil {
+				s.values = values
+			}
+			if time.Until(expires) < o.maxAge/2 {
+				if err := c.saveSession(s); err != nil {
+					return nil, err
+				}
+			}
+		case !errors.Is(err, ErrSessionInvalid):
+			return nil, err
+		}
+	}
+	c.session = s
+	return s, nil
+}
+
+// saveSession saves the session and sets the cookie of it, the session of no
+// values is deleted.
+func (c *wrapper) saveSession(s *session) error {
+	o := c.router.srv.sessions
+	cookie := &http.Cookie{
+		Name:     o.name,
+		Path:     o.path,
+		Domain:   o.domain,
+		HttpOnly: true,
+		Secure:   !o.insecure,
+		SameSite: o.sameSite,
+	}
+	if len(s.values) == 0 {
+		if s.cookie != "" {
+			if err := o.store.Delete(c.req.Context(), s.cookie); err != nil {
+				return err
+			}
+		}
+		s.cookie, cookie.MaxAge = "", -1
+	} else {
+		expires := time.Now().Add(o.maxAge)
+		value, err := o.store.Save(c.req.Context(), s.cookie, s.values, expires)
+		if err != nil {
+			return err
+		}
+		s.cookie, cookie.Value = value, value
+		cookie.Expires, cookie.MaxAge = expires, int(o.maxAge/time.Second)
+	}
+	// the cookie set by the previous saves of the request is replaced.
+	header := c.res.Header()
+	cookies := header["Set-Cookie"][:0]
+	for _, v := range header["Set-Cookie"] {
+		if !strings.HasPrefix(v, o.name+"=") {
+			cookies = append(cookies, v)
+		}
+	}
+	header["Set-Cookie"] = append(cookies, cookie.String())
+	return nil
+}
+
+// CookieStore is the SessionStore keeping the sessions in the cookies, which
+// are authenticated and encrypted by AES-GCM.
+type CookieStore struct {
+	aeads []cipher.AEAD
+}
+
+// NewCookieStore new a cookie store of the AES keys of 16, 24 or 32 bytes, the
+// sessions are encrypted by the first key and decrypted by any of them, so
+// that the keys are rotated by prepending the new ones.
+func NewCookieStore(keys ...[]byte) (*CookieStore, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("http: no cookie store keys")
+	}
+	s := &CookieStore{}
+	for _, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("http: cookie store key: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		s.aeads = append(s.aeads, aead)
+	}
+	return s, nil
+}
+
+type cookieSession struct {
+	Values  map[string]string `json:"v"`
+	Expires int64             `json:"e"`
+}
+
+// Load decrypts the session of the cookie.
+func (s *CookieStore) Load(_ context.Context, cookie string) (map[string]string, time.Time, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cookie)
+	if err != nil {
+		return nil, time.Time{}, ErrSessionInvalid
+	}
+	for _, aead := range s.aeads {
+		if len(data) < aead.NonceSize() {
+			break
+		}
+		plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+		if err != nil {
+			continue
+		}
+		var cs cookieSession
+		if err := json.Unmarshal(plain, &cs); err != nil {
+			return nil, time.Time{}, ErrSessionInvalid
+		}
+		expires := time.Unix(cs.Expires, 0)
+		if !time.Now().Before(expires) {
+			return nil, time.Time{}, ErrSessionInvalid
+		}
+		return cs.Values, expires, nil
+	}
+	return nil, time.Time{}, ErrSessionInvalid
+}
+
+// Save encrypts the session into the cookie by the first key.
+func (s *CookieStore) Save(_ context.Context, _ string, values map[string]string, expires time.Time) (string, error) {
+	plain, err := json.Marshal(cookieSession{Values: values, Expires: expires.Unix()})
+	if err != nil {
+		return "", err
+	}
+	aead := s.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	cookie := base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plain, nil))
+	if len(cookie) > maxCookieSize {
+		return "", ErrSessionTooLarge
+	}
+	return cookie, nil
+}
+
+// Delete does nothing, as the sessions are kept by the cookies.
+func (s *CookieStore) Delete(context.Context, string) error {
+	return nil
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessions(t *testing.T) {
+	oldKey, newKey := []byte("0123456789abcdef"), []byte("fedcba9876543210")
+	oldStore, err := NewCookieStore(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewCookieStore(newKey, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(Sessions(store, SessionMaxAge(time.Hour)))
+	r := srv.Route("/")
+	r.POST("/login", func(ctx Context) error {
+		if err := ctx.SetSession("user", "kratos"); err != nil {
+			return err
+		}
+		return ctx.SetSession("role", "admin")
+	})
+	r.GET("/me", func(ctx Context) error {
+		user, err := ctx.GetSession("user")
+		if err != nil {
+			return err
+		}
+		return ctx.String(http.StatusOK, user)
+	})
+	r.POST("/logout", func(ctx Context) error {
+		if err := ctx.SetSession("user", ""); err != nil {
+			return err
+		}
+		return ctx.SetSession("role", "")
+	})
+	do := func(method, path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/login", nil)
+	if n := len(w.Result().Cookies()); n != 1 {
+		t.Fatalf("expected one session cookie, got %d", n)
+	}
+	cookie := w.Result().Cookies()[0]
+	if cookie.Name != "session" || !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode || cookie.MaxAge != 3600 {
+		t.Errorf("unexpected cookie %v", cookie)
+	}
+	if w := do(http.MethodGet, "/me", cookie); w.Body.String() != "kratos" || len(w.Result().Cookies()) != 0 {
+		t.Errorf("expected the session, got %q %v", w.Body.String(), w.Result().Cookies())
+	}
+
+	// the cookies of the rotated keys are decrypted, and renewed if old.
+	old, _ := oldStore.Save(context.Background(), "", map[string]string{"user": "old"}, time.Now().Add(time.Minute))
+	w = do(http.MethodGet, "/me", &http.Cookie{Name: "session", Value: old})
+	if w.Body.String() != "old" || len(w.Result().Cookies()) != 1 {
+		t.Fatalf("expected the renewed session, got %q %v", w.Body.String(), w.Result().Cookies())
+	}
+	if _, _, err := oldStore.Load(context.Background(), w.Result().Cookies()[0].Value); !errors.Is(err, ErrSessionInvalid) {
+		t.Errorf("expected the renewed cookie of the new key, got %v", err)
+	}
+
+	flip := "A"
+	if cookie.Value[10] == 'A' {
+		flip = "B"
+	}
+	tampered := &http.Cookie{Name: "session", Value: cookie.Value[:10] + flip + cookie.Value[11:]}
+	if w := do(http.MethodGet, "/me", tampered); w.Code != http.StatusOK || w.Body.String() != "" {
+		t.Errorf("expected a new session, got %d %q", w.Code, w.Body.String())
+	}
+	expired, _ := store.Save(context.Background(), "", map[string]string{"user": "kratos"}, time.Now().Add(-time.Second))
+	if _, _, err := store.Load(context.Background(), expired); !errors.Is(err, ErrSessionInvalid) {
+		t.Errorf("expected the expired session invalid, got %v", err)
+	}
+
+	if w := do(http.MethodPost, "/logout", cookie); len(w.Result().Cookies()) != 1 || w.Result().Cookies()[0].MaxAge != -1 {
+		t.Errorf("expected the session cookie deleted, got %v", w.Result().Cookies())
+	}
+	if _, err := store.Save(context.Background(), "", map[string]string{"user": strings.Repeat("x", maxCookieSize)}, time.Now().Add(time.Hour)); !errors.Is(err, ErrSessionTooLarge) {
+		t.Errorf("expected ErrSessionTooLarge, got %v", err)
+	}
+}
+
+func TestNoSessionStore(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").GET("/", func(ctx Context) error {
+		if _, err := ctx.GetSession("user"); !errors.Is(err, ErrNoSessionStore) {
+			t.Errorf("expected ErrNoSessionStore, got %v", err)
+		}
+		return nil
+	})
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if _, err := NewCookieStore([]byte("short")); err == nil {
+		t.Error("expected the invalid key")
+	}
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// StatusMapFunc maps the error replied by the server to the HTTP status and
+// the headers set on the reply, e.g. Retry-After, a zero status keeps the one
+// of the error.
+type StatusMapFunc func(err *errors.Error) (status int, header http.Header)
+
+// ErrorMapFunc adjusts the error decoded by the client of the response, e.g.
+// restores the code of the reason mapped by the server, or keeps Retry-After
+// in the metadata.
+type ErrorMapFunc func(res *http.Response, err *errors.Error)
+
+// StatusMapper with the mapping of the errors to the HTTP statuses and the
+// reply headers, which applies to the error encoder of the server, default is
+// the code of the errors.
+func StatusMapper(f StatusMapFunc) ServerOption {
+	return func(s *Server) {
+		s.statusMapper = f
+	}
+}
+
+// WithStatusMapper with the mapping of the errors decoded by the error decoder
+// of the client, e.g. the reverse of the StatusMapper of the server.
+func WithStatusMapper(f ErrorMapFunc) ClientOption {
+	return func(o *clientOptions) {
+		o.statusMapper = f
+	}
+}
+
+// ReasonStatus returns the StatusMapFunc mapping the reasons of the errors to the statuses.
+func ReasonStatus(statuses map[string]int) StatusMapFunc {
+	return func(err *errors.Error) (int, http.Header) {
+		return statuses[err.Reason], nil
+	}
+}
+
+// mapStatus returns the error encoder replying the statuses and the headers of the mapper.
+func mapStatus(f StatusMapFunc, enc EncodeErrorFunc) EncodeErrorFunc {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		status, header := f(errors.FromError(err))
+		for k, v := range header {
+			w.Header()[http.CanonicalHeaderKey(k)] = v
+		}
+		if status > 0 {
+			w = &mappedWriter{ResponseWriter: w, status: status}
+		}
+		enc(w, r, err)
+	}
+}
+
+// mappedWriter writes the mapped status instead of the one of the encoder.
+type mappedWriter struct {
+	http.ResponseWriter
+	status  int
+	written bool
+}
+
+func (w *mappedWriter) WriteHeader(int) {
+	if !w.written {
+		w.written = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *mappedWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(0)
+	return w.ResponseWriter.Write(b)
+}
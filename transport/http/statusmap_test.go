@@ -0,0 +1,70 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestStatusMapper(t *testing.T) {
+	srv := NewServer(StatusMapper(func(err *errors.Error) (int, http.Header) {
+		if err.Code == http.StatusTooManyRequests {
+			return 0, http.Header{"Retry-After": []string{"3"}}
+		}
+		return ReasonStatus(map[string]int{"INVALID_EMAIL": http.StatusUnprocessableEntity})(err)
+	}))
+	srv.Route("/").GET("/users/{name}", func(ctx Context) error {
+		switch ctx.Vars().Get("name") {
+		case "invalid":
+			return errors.BadRequest("INVALID_EMAIL", "invalid email")
+		case "busy":
+			return errors.New(http.StatusTooManyRequests, "BUSY", "too many requests")
+		}
+		return errors.NotFound("USER_NOT_FOUND", "not found")
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	for path, want := range map[string]struct {
+		code       int
+		retryAfter string
+	}{
+		"/users/invalid": {http.StatusUnprocessableEntity, ""},
+		"/users/busy":    {http.StatusTooManyRequests, "3"},
+		"/users/missing": {http.StatusNotFound, ""},
+	} {
+		res, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = res.Body.Close()
+		if res.StatusCode != want.code || res.Header.Get("Retry-After") != want.retryAfter {
+			t.Errorf("%s: expected %d %q got %d %q", path, want.code, want.retryAfter, res.StatusCode, res.Header.Get("Retry-After"))
+		}
+	}
+
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(ts.URL, "http://")), WithStatusMapper(func(res *http.Response, err *errors.Error) {
+		if err.Reason == "INVALID_EMAIL" {
+			err.Code = http.StatusBadRequest
+		}
+		if v := res.Header.Get("Retry-After"); v != "" {
+			err.Metadata = map[string]string{"retry-after": v}
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var reply struct{}
+	if err = client.Invoke(context.Background(), http.MethodGet, "/users/invalid", nil, &reply); !errors.IsBadRequest(err) {
+		t.Errorf("expected the code restored got %v", err)
+	}
+	err = client.Invoke(context.Background(), http.MethodGet, "/users/busy", nil, &reply)
+	if se := errors.FromError(err); se.Code != http.StatusTooManyRequests || se.Metadata["retry-after"] != "3" {
+		t.Errorf("expected the retry after in the metadata got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// StdMiddleware adapts the net/http middleware, e.g. of chi or negroni, to the
+// kratos middleware running at its position of the middleware chain. The request
+// it gets has the context of the chain with the server transport, and the chain
+// continues with the context of the request it passes to the next handler.
+//
+// A net/http middleware which responds without calling the next handler, e.g.
+// with 401, ends the chain with an error of the status code, whose response is
+// not written by the server. The reply is written after the chain returns, so the
+// net/http middleware wrapping the ResponseWriter must be used as filters instead.
+func StdMiddleware(m func(http.Handler) http.Handler) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			htr, ok := tr.(*Transport)
+			if !ok || htr.route == nil {
+				return handler(ctx, req)
+			}
+			var (
+				reply  interface{}
+				err    error
+				called bool
+				w      = &statusWriter{ResponseWriter: htr.route.res}
+			)
+			m(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				called = true
+				htr.request = r
+				reply, err = handler(r.Context(), req)
+			})).ServeHTTP(w, htr.request.WithContext(ctx))
+			if !called {
+				htr.responded = true
+				return nil, errors.New(w.status(), "RESPONDED", "the response is written by the net/http middleware")
+			}
+			return reply, err
+		}
+	}
+}
+
+// statusWriter records the status code of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *statusWriter) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
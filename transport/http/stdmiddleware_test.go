@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type userKey struct{}
+
+func TestStdMiddleware(t *testing.T) {
+	var order []string
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			order = append(order, "auth")
+			if _, ok := transport.FromServerContext(req.Context()); !ok {
+				t.Error("expected the server transport")
+			}
+			user := req.Header.Get("X-User")
+			if user == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("X-Auth", "ok")
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), userKey{}, user)))
+		})
+	}
+	var codes []int
+	outer := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			order = append(order, "outer")
+			reply, err := handler(ctx, req)
+			if err != nil {
+				codes = append(codes, int(errors.FromError(err).Code))
+			}
+			return reply, err
+		}
+	}
+	srv := NewServer(Middleware(outer, StdMiddleware(auth)))
+	srv.Route("/").GET("/user", func(ctx Context) error {
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			order = append(order, "handler")
+			user, _ := ctx.Value(userKey{}).(string)
+			return map[string]string{"user": user}, nil
+		})
+		out, err := h(ctx, nil)
+		if err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusOK, out)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/user", nil)
+	req.Header.Set("X-User", "kratos")
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if res.Code != http.StatusOK || !strings.Contains(res.Body.String(), `"user":"kratos"`) || res.Header().Get("X-Auth") != "ok" {
+		t.Fatalf("unexpected response %d %s %v", res.Code, res.Body.String(), res.Header())
+	}
+	if strings.Join(order, ",") != "outer,auth,handler" {
+		t.Errorf("expected outer,auth,handler got %v", order)
+	}
+
+	order = nil
+	res = httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/user", nil))
+	if res.Code != http.StatusUnauthorized || strings.TrimSpace(res.Body.String()) != "unauthorized" {
+		t.Fatalf("unexpected response %d %s", res.Code, res.Body.String())
+	}
+	if strings.Join(order, ",") != "outer,auth" || len(codes) != 1 || codes[0] != http.StatusUnauthorized {
+		t.Errorf("expected the chain ended with 401, got %v %v", order, codes)
+	}
+}
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+const (
+	// defaultStickyTTL is the default idle time of the sticky sessions.
+	defaultStickyTTL = 30 * time.Minute
+	// maxStickySessions is the max number of the sticky sessions remembered.
+	maxStickySessions = 65536
+)
+
+// SessionFunc derives the session key of a request for the sticky sessions,
+// an empty key is not sticky. The response is nil before the request is sent,
+// and is the response of the node afterwards, e.g. of the session created by it.
+type SessionFunc func(req *http.Request, res *http.Response) string
+
+// WithStickySession with the session key of the requests, whose following
+// requests are routed to the node of the first one as long as it is selected
+// by the node filters, e.g. of the circuit breaker or the outlier detection,
+// or else to the node newly picked, e.g. for the services of the in memory
+// per user state. It only applies to the discovery clients.
+func WithStickySession(f SessionFunc) ClientOption {
+	return func(o *clientOptions) {
+		o.sticky = f
+	}
+}
+
+// WithStickyTTL with the idle time after which the sticky session is
+// forgotten, default is 30 minutes.
+func WithStickyTTL(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.stickyTTL = d
+	}
+}
+
+// SessionCookie returns the session key from the cookie of the request, or
+// from the one set by the response of the node.
+func SessionCookie(name string) SessionFunc {
+	return func(req *http.Request, res *http.Response) string {
+		if c, err := req.Cookie(name); err == nil && c.Value != "" {
+			return c.Value
+		}
+		if res != nil {
+			for _, c := range res.Cookies() {
+				if c.Name == name && c.Value != "" {
+					return c.Value
+				}
+			}
+		}
+		return ""
+	}
+}
+
+// SessionHeader returns the session key from the request header, or from the
+// reply header of the node.
+func SessionHeader(name string) SessionFunc {
+	return func(req *http.Request, res *http.Response) string {
+		if v := req.Header.Get(name); v != "" {
+			return v
+		}
+		if res != nil {
+			return res.Header.Get(name)
+		}
+		return ""
+	}
+}
+
+// SessionContext returns the session key from the context value, a string or a fmt.Stringer.
+func SessionContext(key interface{}) SessionFunc {
+	f := HashKeyContext(key)
+	return func(req *http.Request, _ *http.Response) string {
+		return f(req)
+	}
+}
+
+type stickyEntry struct {
+	addr    string
+	expires time.Time
+}
+
+// stickyTable remembers the node addresses of the sessions.
+type stickyTable struct {
+	key SessionFunc
+	ttl time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]stickyEntry
+}
+
+func newStickyTable(key SessionFunc, ttl time.Duration) *stickyTable {
+	if ttl <= 0 {
+		ttl = defaultStickyTTL
+	}
+	return &stickyTable{key: key, ttl: ttl, sessions: make(map[string]stickyEntry)}
+}
+
+// get returns the node address of the session if it is not expired.
+func (t *stickyTable) get(session string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.sessions[session]
+	if !ok {
+		return ""
+	}
+	if time.Now().After(e.expires) {
+		delete(t.sessions, session)
+		return ""
+	}
+	return e.addr
+}
+
+// done remembers the node of the session of the request replied by it, or
+// forgets it if the node fails, so that another node is picked next time.
+func (t *stickyTable) done(req *http.Request, res *http.Response, session, addr string, err error) {
+	if session == "" {
+		if session = t.key(req, res); session == "" {
+			return
+		}
+	}
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil && isNodeError(err) {
+		if e, ok := t.sessions[session]; ok && e.addr == addr {
+			delete(t.sessions, session)
+		}
+		return
+	}
+	if _, ok := t.sessions[session]; !ok && len(t.sessions) >= maxStickySessions {
+		t.evict(now)
+	}
+	t.sessions[session] = stickyEntry{addr: addr, expires: now.Add(t.ttl)}
+}
+
+// evict deletes the expired sessions, and the arbitrary ones down to the half
+// of the max if there are still too many.
+func (t *stickyTable) evict(now time.Time) {
+	for k, e := range t.sessions {
+		if now.After(e.expires) {
+			delete(t.sessions, k)
+		}
+	}
+	for k := range t.sessions {
+		if len(t.sessions) < maxStickySessions/2 {
+			return
+		}
+		delete(t.sessions, k)
+	}
+}
+
+// isNodeError reports whether the error is of the node rather than of the
+// request, i.e. the transport errors and the 5xx replies.
+func isNodeError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if se := new(errors.Error); errors.As(err, &se) {
+		return se.Code >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// stickyNode is the node filter selecting the node of the address if it is
+// selected by the other filters, or else all the nodes.
+func stickyNode(addr string) selector.NodeFilter {
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		for _, n := range nodes {
+			if n.Address() == addr {
+				return []selector.Node{n}
+			}
+		}
+		return nodes
+	}
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func TestClientStickySession(t *testing.T) {
+	var (
+		srvs []*httptest.Server
+		ins  []*registry.ServiceInstance
+	)
+	for i := 0; i < 3; i++ {
+		node := strconv.Itoa(i)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := r.Cookie("sid"); err != nil {
+				http.SetCookie(w, &http.Cookie{Name: "sid", Value: "created-" + node})
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"node":"` + node + `"}`))
+		}))
+		defer srv.Close()
+		srvs = append(srvs, srv)
+		ins = append(ins, &registry.ServiceInstance{ID: node, Name: "sticky", Endpoints: []string{"http://" + strings.TrimPrefix(srv.URL, "http://")}})
+	}
+	client, err := NewClient(context.Background(), WithEndpoint("discovery:///sticky"), WithDiscovery(&unixDiscovery{ins: ins}), WithBlock(), WithStickySession(SessionCookie("sid")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	call := func(sid string) string {
+		var reply map[string]string
+		var opts []CallOption
+		if sid != "" {
+			opts = append(opts, WithHeader("Cookie", "sid="+sid))
+		}
+		if err := client.Invoke(context.Background(), http.MethodGet, "/hello", nil, &reply, opts...); err != nil {
+			t.Fatal(err)
+		}
+		return reply["node"]
+	}
+
+	node := call("alice")
+	for i := 0; i < 10; i++ {
+		if got := call("alice"); got != node {
+			t.Fatalf("expected the sticky node %s got %s", node, got)
+		}
+	}
+
+	created := call("")
+	for i := 0; i < 10; i++ {
+		if got := call("created-" + created); got != created {
+			t.Fatalf("expected the node %s of the created session got %s", created, got)
+		}
+	}
+
+	i, _ := strconv.Atoi(node)
+	srvs[i].Close()
+	moved := call("alice")
+	if moved == node {
+		t.Fatalf("expected another node than the failed %s", node)
+	}
+	for i := 0; i < 10; i++ {
+		if got := call("alice"); got != moved {
+			t.Fatalf("expected the new sticky node %s got %s", moved, got)
+		}
+	}
+}
+
+func TestStickyTable(t *testing.T) {
+	table := newStickyTable(SessionHeader("X-Session"), 0)
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	res := &http.Response{Header: http.Header{"X-Session": []string{"s1"}}}
+	table.done(req, res, "", "10.0.0.1:80", nil)
+	if addr := table.get("s1"); addr != "10.0.0.1:80" {
+		t.Errorf("expected the node of the reply session got %q", addr)
+	}
+	table.done(req, nil, "s1", "10.0.0.1:80", &testError{})
+	if addr := table.get("s1"); addr != "" {
+		t.Errorf("expected the session forgotten after the node error, got %q", addr)
+	}
+	table.done(req, nil, "s2", "10.0.0.2:80", context.Canceled)
+	if addr := table.get("s2"); addr != "10.0.0.2:80" {
+		t.Errorf("expected the session kept after the canceled request, got %q", addr)
+	}
+}
+
+type testError struct{}
+
+func (*testError) Error() string { return "connection reset" }
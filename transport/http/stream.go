@@ -0,0 +1,281 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/encoding/json"
+)
+
+const defaultHighWaterMark = 1 << 20
+
+var (
+	// ErrSlowConsumer is returned by StreamWriter.Send when the buffered data
+	// exceeds the high-water mark, the message is dropped.
+	ErrSlowConsumer = errors.New("http: stream consumer is too slow")
+	// ErrStreamClosed is returned by StreamWriter.Send after the writer is closed.
+	ErrStreamClosed = errors.New("http: stream is closed")
+)
+
+// StreamState is the flow control state of a StreamWriter.
+type StreamState struct {
+	// Buffered is the size of the data not written to the client yet.
+	Buffered int
+	// HighWaterMark is the max size of the buffered data.
+	HighWaterMark int
+	// Dropped is the number of the messages rejected by Send.
+	Dropped int
+	// Err is the write error, e.g. the write deadline is exceeded.
+	Err error
+}
+
+// StreamOption is stream writer option.
+type StreamOption func(*streamOptions)
+
+type streamOptions struct {
+	writeTimeout  time.Duration
+	highWaterMark int
+	onSlow        func(StreamState)
+}
+
+// StreamWriteTimeout with the write deadline of every batch of messages, the stream
+// fails when the client does not read them in time. It requires the response writer
+// to support SetWriteDeadline, e.g. net/http since Go 1.20, otherwise it is ignored.
+func StreamWriteTimeout(d time.Duration) StreamOption {
+	return func(o *streamOptions) {
+		o.writeTimeout = d
+	}
+}
+
+// StreamHighWaterMark with the max size of the buffered data, default is 1MB.
+func StreamHighWaterMark(size int) StreamOption {
+	return func(o *streamOptions) {
+		o.highWaterMark = size
+	}
+}
+
+// StreamOnSlow with the callback called when a message is dropped at the
+// high-water mark, or the write deadline is exceeded, so that the producer
+// can drop or aggregate the messages.
+func StreamOnSlow(fn func(StreamState)) StreamOption {
+	return func(o *streamOptions) {
+		o.onSlow = fn
+	}
+}
+
+// StreamWriter writes the messages of a streaming response, e.g. SSE or NDJSON,
+// from a buffer drained by a goroutine, so that a slow client is surfaced to the
+// handler instead of blocking it or growing the memory.
+type StreamWriter struct {
+	w    http.ResponseWriter
+	opts streamOptions
+	wake chan struct{}
+	done chan struct{}
+
+	mu       sync.Mutex
+	queue    [][]byte
+	buffered int
+	dropped  int
+	closed   bool
+	err      error
+}
+
+// NewStreamWriter writes the response header with the content type and starts the
+// stream, which ends when Close is called or the request context is done. Close
+// must be called before the handler returns.
+func NewStreamWriter(ctx Context, contentType string, opts ...StreamOption) *StreamWriter {
+	o := streamOptions{highWaterMark: defaultHighWaterMark}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	w := ctx.Response()
+	w.Header().Set("Content-Type", contentType)
+	if contentType == "text/event-stream" {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	w.WriteHeader(http.StatusOK)
+	flush(w)
+	s := &StreamWriter{
+		w:    w,
+		opts: o,
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	go s.run(ctx.Request().Context())
+	return s
+}
+
+// Send buffers the message to be written. It returns ErrSlowConsumer and drops
+// the message when the buffered data would exceed the high-water mark, or the
+// error which failed the stream.
+func (s *StreamWriter) Send(data []byte) error {
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return s.err
+	}
+	if s.closed {
+		s.mu.Unlock()
+		return ErrStreamClosed
+	}
+	// a message larger than the high-water mark is written when nothing is buffered.
+	if s.buffered > 0 && s.buffered+len(data) > s.opts.highWaterMark {
+		s.dropped++
+		state := s.state()
+		s.mu.Unlock()
+		if s.opts.onSlow != nil {
+			s.opts.onSlow(state)
+		}
+		return ErrSlowConsumer
+	}
+	s.queue = append(s.queue, data)
+	s.buffered += len(data)
+	s.mu.Unlock()
+	s.notify()
+	return nil
+}
+
+// SendEvent sends a server-sent event, the event name is omitted if empty.
+func (s *StreamWriter) SendEvent(event string, data []byte) error {
+	var b bytes.Buffer
+	if event != "" {
+		b.WriteString("event: " + event + "\n")
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteByte('\n')
+	return s.Send(b.Bytes())
+}
+
+// SendJSON sends the message as a line of newline delimited JSON.
+func (s *StreamWriter) SendJSON(v interface{}) error {
+	data, err := encoding.GetCodec(json.Name).Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.Send(append(data, '\n'))
+}
+
+// State returns the current flow control state.
+func (s *StreamWriter) State() StreamState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state()
+}
+
+func (s *StreamWriter) state() StreamState {
+	return StreamState{
+		Buffered:      s.buffered,
+		HighWaterMark: s.opts.highWaterMark,
+		Dropped:       s.dropped,
+		Err:           s.err,
+	}
+}
+
+// Close waits for the buffered messages to be written and returns the error
+// which failed the stream, if any.
+func (s *StreamWriter) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.notify()
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *StreamWriter) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *StreamWriter) run(ctx context.Context) {
+	defer close(s.done)
+	for {
+		s.mu.Lock()
+		queue, closed := s.queue, s.closed
+		s.queue = nil
+		s.mu.Unlock()
+		if len(queue) == 0 {
+			if closed {
+				return
+			}
+			select {
+			case <-s.wake:
+				continue
+			case <-ctx.Done():
+				s.fail(ctx.Err())
+				return
+			}
+		}
+		if err := s.write(queue); err != nil {
+			s.fail(err)
+			return
+		}
+	}
+}
+
+func (s *StreamWriter) write(queue [][]byte) error {
+	if s.opts.writeTimeout > 0 {
+		if err := setWriteDeadline(s.w, time.Now().Add(s.opts.writeTimeout)); err == nil {
+			defer func() { _ = setWriteDeadline(s.w, time.Time{}) }()
+		}
+	}
+	for _, data := range queue {
+		if _, err := s.w.Write(data); err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.buffered -= len(data)
+		s.mu.Unlock()
+	}
+	flush(s.w)
+	return nil
+}
+
+func (s *StreamWriter) fail(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.queue = nil
+	state := s.state()
+	s.mu.Unlock()
+	if s.opts.onSlow != nil && isTimeout(err) {
+		s.opts.onSlow(state)
+	}
+}
+
+func isTimeout(err error) bool {
+	var e interface{ Timeout() bool }
+	return errors.As(err, &e) && e.Timeout()
+}
+
+// setWriteDeadline sets the write deadline of the first response writer in the
+// Unwrap chain that supports it, like net/http.ResponseController.
+func setWriteDeadline(w http.ResponseWriter, t time.Time) error {
+	for {
+		switch rw := w.(type) {
+		case interface{ SetWriteDeadline(time.Time) error }:
+			return rw.SetWriteDeadline(t)
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return errors.New("http: write deadline not supported")
+		}
+	}
+}
+
+func flush(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
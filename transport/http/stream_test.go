@@ -0,0 +1,152 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// blockingWriter blocks the writes until unblocked.
+type blockingWriter struct {
+	*httptest.ResponseRecorder
+	unblock  chan struct{}
+	deadline time.Time
+}
+
+func (w *blockingWriter) Write(data []byte) (int, error) {
+	if !w.deadline.IsZero() {
+		select {
+		case <-w.unblock:
+		case <-time.After(time.Until(w.deadline)):
+			return 0, os.ErrDeadlineExceeded
+		}
+	} else {
+		<-w.unblock
+	}
+	return w.ResponseRecorder.Write(data)
+}
+
+func (w *blockingWriter) SetWriteDeadline(t time.Time) error {
+	w.deadline = t
+	return nil
+}
+
+func newStreamContext(w http.ResponseWriter) Context {
+	ctx := &wrapper{}
+	ctx.Reset(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	return ctx
+}
+
+func TestStreamWriter(t *testing.T) {
+	res := httptest.NewRecorder()
+	s := NewStreamWriter(newStreamContext(res), "text/event-stream")
+	if err := s.SendEvent("message", []byte("hello\nkratos")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SendJSON(map[string]string{"name": "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send([]byte("closed")); err != ErrStreamClosed {
+		t.Errorf("expected %v got %v", ErrStreamClosed, err)
+	}
+	if v := res.Header().Get("Content-Type"); v != "text/event-stream" {
+		t.Errorf("unexpected content type %s", v)
+	}
+	if got, want := res.Body.String(), "event: message\ndata: hello\ndata: kratos\n\n{\"name\":\"kratos\"}\n"; got != want {
+		t.Errorf("expected %q got %q", want, got)
+	}
+	if !res.Flushed {
+		t.Error("expected the stream to be flushed")
+	}
+}
+
+func TestStreamWriterHighWaterMark(t *testing.T) {
+	w := &blockingWriter{ResponseRecorder: httptest.NewRecorder(), unblock: make(chan struct{})}
+	var slow []StreamState
+	s := NewStreamWriter(newStreamContext(w), "application/x-ndjson",
+		StreamHighWaterMark(8),
+		StreamOnSlow(func(state StreamState) { slow = append(slow, state) }),
+	)
+	// the first message is written alone when nothing is buffered.
+	if err := s.Send([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send([]byte("x")); err != ErrSlowConsumer {
+		t.Fatalf("expected %v got %v", ErrSlowConsumer, err)
+	}
+	if len(slow) != 1 || slow[0].Buffered != 10 || slow[0].Dropped != 1 || slow[0].HighWaterMark != 8 {
+		t.Errorf("unexpected slow states %+v", slow)
+	}
+	close(w.unblock)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if state := s.State(); state.Buffered != 0 || state.Dropped != 1 {
+		t.Errorf("unexpected state %+v", state)
+	}
+	if got := w.Body.String(); got != "0123456789" {
+		t.Errorf("unexpected body %q", got)
+	}
+}
+
+func TestStreamWriterTimeout(t *testing.T) {
+	w := &blockingWriter{ResponseRecorder: httptest.NewRecorder(), unblock: make(chan struct{})}
+	slow := make(chan StreamState, 1)
+	s := NewStreamWriter(newStreamContext(w), "application/x-ndjson",
+		StreamWriteTimeout(10*time.Millisecond),
+		StreamOnSlow(func(state StreamState) { slow <- state }),
+	)
+	if err := s.Send([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case state := <-slow:
+		if !errors.Is(state.Err, os.ErrDeadlineExceeded) {
+			t.Errorf("unexpected state %+v", state)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the slow callback")
+	}
+	if err := s.Close(); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("expected %v got %v", os.ErrDeadlineExceeded, err)
+	}
+	if err := s.Send([]byte("hello")); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("expected %v got %v", os.ErrDeadlineExceeded, err)
+	}
+}
+
+func TestStreamWriterServer(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").GET("/events", func(ctx Context) error {
+		s := NewStreamWriter(ctx, "text/event-stream", StreamWriteTimeout(time.Second))
+		for _, v := range []string{"a", "b"} {
+			if err := s.SendEvent("", []byte(v)); err != nil {
+				return err
+			}
+		}
+		return s.Close()
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL+"/events", nil)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "data: a\n\ndata: b\n\n"; got != want {
+		t.Errorf("expected %q got %q", want, got)
+	}
+}
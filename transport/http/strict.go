@@ -0,0 +1,129 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/encoding/form"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Consumes restricts the request Content-Type of the route to the given media types,
+// e.g. application/json, a request body of any other type is rejected with 415.
+func Consumes(contentTypes ...string) FilterFunc {
+	consumes := make([]string, 0, len(contentTypes))
+	for _, ct := range contentTypes {
+		consumes = append(consumes, mediaType(ct))
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if tr, ok := serverTransport(req); ok {
+				tr.consumes = consumes
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// DisallowUnknownFields rejects the unknown fields of the route query and JSON body
+// with 400 instead of silently discarding them.
+func DisallowUnknownFields() FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if tr, ok := serverTransport(req); ok {
+				tr.disallowUnknown = true
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+func serverTransport(req *http.Request) (*Transport, bool) {
+	if tr, ok := transport.FromServerContext(req.Context()); ok {
+		if tr, ok := tr.(*Transport); ok {
+			return tr, true
+		}
+	}
+	return nil, false
+}
+
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mt
+}
+
+// checkContentType verifies the request body Content-Type against the route
+// declared types, and against the registered codecs in strict mode.
+func checkContentType(req *http.Request, strict bool) error {
+	if req.ContentLength == 0 {
+		return nil
+	}
+	var consumes []string
+	if tr, ok := serverTransport(req); ok {
+		consumes = tr.consumes
+	}
+	if len(consumes) == 0 && !strict {
+		return nil
+	}
+	contentType := req.Header.Get("Content-Type")
+	mt := mediaType(contentType)
+	if len(consumes) > 0 {
+		for _, ct := range consumes {
+			if ct == mt {
+				return nil
+			}
+		}
+		return errors.New(http.StatusUnsupportedMediaType, "CODEC", fmt.Sprintf("unsupported Content-Type: %s", contentType))
+	}
+	if mt == "" || encoding.GetCodec(contentSubtype(mt)) == nil {
+		return errors.New(http.StatusUnsupportedMediaType, "CODEC", fmt.Sprintf("unregister Content-Type: %s", contentType))
+	}
+	return nil
+}
+
+func contentSubtype(mt string) string {
+	if i := strings.Index(mt, "/"); i >= 0 {
+		return mt[i+1:]
+	}
+	return ""
+}
+
+func disallowUnknownFields(req *http.Request) bool {
+	tr, ok := serverTransport(req)
+	return ok && tr.disallowUnknown
+}
+
+// checkUnknownValues rejects the url values which are not fields of the proto message.
+func checkUnknownValues(vars url.Values, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil
+	}
+	if unknown := form.UnknownValues(m, vars); len(unknown) > 0 {
+		return errors.BadRequest("CODEC", fmt.Sprintf("unknown query fields: %s", strings.Join(unknown, ", ")))
+	}
+	return nil
+}
+
+// strictUnmarshal unmarshals a JSON body and rejects the unknown fields.
+func strictUnmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return protojson.Unmarshal(data, m)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type strictRequest struct {
+	Name string `json:"name"`
+}
+
+func serveStrict(srv *Server, method, target, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	return res
+}
+
+func TestConsumes(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").POST("/consumes", func(ctx Context) error {
+		var in strictRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusOK, in)
+	}, Consumes("application/json"))
+
+	tests := []struct {
+		contentType string
+		code        int
+	}{
+		{"application/json", http.StatusOK},
+		{"application/json; charset=utf-8", http.StatusOK},
+		{"application/xml", http.StatusUnsupportedMediaType},
+		{"", http.StatusUnsupportedMediaType},
+	}
+	for _, test := range tests {
+		res := serveStrict(srv, http.MethodPost, "/consumes", test.contentType, `{"name":"kratos"}`)
+		if res.Code != test.code {
+			t.Errorf("%q: expected %d got %d", test.contentType, test.code, res.Code)
+		}
+	}
+}
+
+func TestStrictContentType(t *testing.T) {
+	handler := func(ctx Context) error {
+		var in strictRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusOK, in)
+	}
+	srv := NewServer(StrictContentType())
+	srv.Route("/").POST("/strict", handler)
+	if res := serveStrict(srv, http.MethodPost, "/strict", "text/unknown", `{}`); res.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected %d got %d", http.StatusUnsupportedMediaType, res.Code)
+	}
+	if res := serveStrict(srv, http.MethodPost, "/strict", "application/json", `{}`); res.Code != http.StatusOK {
+		t.Errorf("expected %d got %d", http.StatusOK, res.Code)
+	}
+
+	srv = NewServer()
+	srv.Route("/").POST("/strict", handler)
+	if res := serveStrict(srv, http.MethodPost, "/strict", "text/unknown", `{}`); res.Code != http.StatusBadRequest {
+		t.Errorf("expected %d got %d", http.StatusBadRequest, res.Code)
+	}
+}
+
+func TestDisallowUnknownFields(t *testing.T) {
+	srv := NewServer()
+	r := srv.Route("/")
+	r.POST("/body", func(ctx Context) error {
+		var in strictRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusOK, in)
+	}, DisallowUnknownFields())
+	r.POST("/proto", func(ctx Context) error {
+		var in wrapperspb.StringValue
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusOK, &in)
+	}, DisallowUnknownFields())
+	r.GET("/query", func(ctx Context) error {
+		var in wrapperspb.StringValue
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusOK, &in)
+	}, DisallowUnknownFields())
+	r.POST("/loose", func(ctx Context) error {
+		var in strictRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusOK, in)
+	})
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		code   int
+	}{
+		{http.MethodPost, "/body", `{"name":"kratos"}`, http.StatusOK},
+		{http.MethodPost, "/body", `{"name":"kratos","age":1}`, http.StatusBadRequest},
+		{http.MethodPost, "/proto", `"kratos"`, http.StatusOK},
+		{http.MethodGet, "/query?value=kratos", "", http.StatusOK},
+		{http.MethodGet, "/query?value=kratos&unknown=1", "", http.StatusBadRequest},
+		{http.MethodPost, "/loose", `{"name":"kratos","age":1}`, http.StatusOK},
+	}
+	for _, test := range tests {
+		res := serveStrict(srv, test.method, test.target, "application/json", test.body)
+		if res.Code != test.code {
+			t.Errorf("%s %s: expected %d got %d: %s", test.method, test.target, test.code, res.Code, res.Body)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Timeline phase names recorded by the server.
+const (
+	PhaseDecode     = "decode"
+	PhaseMiddleware = "middleware"
+	PhaseHandler    = "handler"
+	PhaseEncode     = "encode"
+)
+
+// TimelinePhase is the accumulated duration of a named request phase.
+type TimelinePhase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// TimingMetric is a custom Server-Timing metric, e.g. db;dur=12 or cache;desc="hit".
+// A zero Duration is omitted from the header.
+type TimingMetric struct {
+	Name        string
+	Duration    time.Duration
+	Description string
+}
+
+// Timeline records the phase durations of a server request.
+// A nil Timeline is valid and records nothing.
+type Timeline struct {
+	mu      sync.Mutex
+	phases  []TimelinePhase
+	metrics []TimingMetric
+}
+
+// Add accumulates the duration of the named phase.
+func (t *Timeline) Add(name string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.phases {
+		if t.phases[i].Name == name {
+			t.phases[i].Duration += d
+			return
+		}
+	}
+	t.phases = append(t.phases, TimelinePhase{Name: name, Duration: d})
+}
+
+// Begin starts timing the named phase, the returned func ends it.
+func (t *Timeline) Begin(name string) func() {
+	if t == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { t.Add(name, time.Since(start)) }
+}
+
+// Phases returns the recorded phases in the order they were first added.
+func (t *Timeline) Phases() []TimelinePhase {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TimelinePhase(nil), t.phases...)
+}
+
+// AddMetric appends a custom Server-Timing metric.
+func (t *Timeline) AddMetric(m TimingMetric) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.metrics = append(t.metrics, m)
+}
+
+// Metrics returns the custom Server-Timing metrics in the order they were added.
+func (t *Timeline) Metrics() []TimingMetric {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TimingMetric(nil), t.metrics...)
+}
+
+// ServerTiming formats the recorded phases and metrics as a Server-Timing header value.
+func (t *Timeline) ServerTiming() string {
+	phases := t.Phases()
+	metrics := t.Metrics()
+	values := make([]string, 0, len(phases)+len(metrics))
+	for _, p := range phases {
+		values = append(values, p.Name+";dur="+formatDuration(p.Duration))
+	}
+	for _, m := range metrics {
+		name := timingToken(m.Name)
+		if name == "" {
+			continue
+		}
+		if m.Duration != 0 {
+			name += ";dur=" + formatDuration(m.Duration)
+		}
+		if m.Description != "" {
+			name += ";desc=" + strconv.QuoteToASCII(m.Description)
+		}
+		values = append(values, name)
+	}
+	return strings.Join(values, ", ")
+}
+
+// AddServerTiming appends a custom metric to the Server-Timing header of the request,
+// it must be called before the response is written. It returns false when the server
+// is not created with ServerTiming, so metrics can be left in code and disabled by config.
+func AddServerTiming(ctx context.Context, m TimingMetric) bool {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if tr, ok := tr.(*Transport); ok && tr.serverTiming {
+			tr.timeline.AddMetric(m)
+			return true
+		}
+	}
+	return false
+}
+
+func formatDuration(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// timingToken strips the characters that are not allowed in a metric name token.
+func timingToken(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r > 0x20 && r < 0x7f && !strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return r
+		}
+		return -1
+	}, name)
+}
+
+// TimelineFromServerContext returns the request timeline from context,
+// it is only present when the server is created with EnableTimeline.
+func TimelineFromServerContext(ctx context.Context) (*Timeline, bool) {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if tr, ok := tr.(*Transport); ok && tr.timeline != nil {
+			return tr.timeline, true
+		}
+	}
+	return nil, false
+}
+
+type captureTimelineKey struct{}
+
+// CaptureTimeline returns a copy of the request whose timeline is recorded and emitted
+// as a Server-Timing header even if the server is not created with ServerTiming, e.g.
+// for a debug session. It must be called by a server filter of the Filter option.
+func CaptureTimeline(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), captureTimelineKey{}, true))
+}
+
+func timelineFromRequest(req *http.Request) *Timeline {
+	tl, _ := TimelineFromServerContext(req.Context())
+	return tl
+}
+
+// timingWriter sets the Server-Timing header before the response header is written.
+type timingWriter struct {
+	http.ResponseWriter
+	timeline *Timeline
+	wrote    bool
+}
+
+func (w *timingWriter) WriteHeader(statusCode int) {
+	if !w.wrote {
+		w.wrote = true
+		if v := w.timeline.ServerTiming(); v != "" {
+			w.ResponseWriter.Header().Set("Server-Timing", v)
+		}
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *timingWriter) Write(data []byte) (int, error) {
+	if !w.wrote {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wrote {
+			w.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+func (w *timingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("http: response does not implement http.Hijacker")
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *timingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeline(t *testing.T) {
+	var tl *Timeline
+	tl.Add(PhaseDecode, time.Second)
+	tl.Begin(PhaseHandler)()
+	if tl.Phases() != nil {
+		t.Errorf("expected no phases on nil timeline")
+	}
+
+	tl = &Timeline{}
+	tl.Add(PhaseDecode, time.Millisecond)
+	tl.Add(PhaseHandler, 2*time.Millisecond)
+	tl.Add(PhaseDecode, time.Millisecond)
+	phases := tl.Phases()
+	if len(phases) != 2 {
+		t.Fatalf("expected 2 phases got %v", phases)
+	}
+	if phases[0].Name != PhaseDecode || phases[0].Duration != 2*time.Millisecond {
+		t.Errorf("unexpected decode phase %v", phases[0])
+	}
+	if got, want := tl.ServerTiming(), "decode;dur=2.000, handler;dur=2.000"; got != want {
+		t.Errorf("expected %q got %q", want, got)
+	}
+}
+
+func TestServerTiming(t *testing.T) {
+	var phases []TimelinePhase
+	srv := NewServer(ServerTiming())
+	srv.Route("/").POST("/timeline", func(ctx Context) error {
+		var in map[string]string
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			time.Sleep(time.Millisecond)
+			return req, nil
+		})
+		out, err := h(ctx, in)
+		if err != nil {
+			return err
+		}
+		tl, ok := TimelineFromServerContext(ctx)
+		if !ok {
+			t.Fatal("expected timeline in context")
+		}
+		defer func() { phases = tl.Phases() }()
+		return ctx.Result(http.StatusOK, out)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/timeline", strings.NewReader(`{"name":"kratos"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", res.Code)
+	}
+	header := res.Header().Get("Server-Timing")
+	for _, name := range []string{PhaseDecode, PhaseMiddleware, PhaseHandler} {
+		if !strings.Contains(header, name+";dur=") {
+			t.Errorf("expected %s in Server-Timing %q", name, header)
+		}
+	}
+	if len(phases) != 4 || phases[3].Name != PhaseEncode {
+		t.Errorf("expected encode phase recorded, got %v", phases)
+	}
+}
+
+func TestTimelineDisabled(t *testing.T) {
+	srv := NewServer()
+	srv.Route("/").GET("/timeline", func(ctx Context) error {
+		if _, ok := TimelineFromServerContext(ctx); ok {
+			t.Error("expected no timeline in context")
+		}
+		return ctx.String(http.StatusOK, "ok")
+	})
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/timeline", nil))
+	if v := res.Header().Get("Server-Timing"); v != "" {
+		t.Errorf("expected no Server-Timing header got %q", v)
+	}
+}
+
+func TestCaptureTimeline(t *testing.T) {
+	capture := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("X-Capture") != "" {
+				req = CaptureTimeline(req)
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+	srv := NewServer(Filter(capture))
+	srv.Route("/").GET("/timeline", func(ctx Context) error {
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+		out, err := h(ctx, nil)
+		if err != nil {
+			return err
+		}
+		return ctx.String(http.StatusOK, out.(string))
+	})
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/timeline", nil))
+	if v := res.Header().Get("Server-Timing"); v != "" {
+		t.Errorf("expected no Server-Timing header got %q", v)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/timeline", nil)
+	req.Header.Set("X-Capture", "1")
+	res = httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if v := res.Header().Get("Server-Timing"); !strings.Contains(v, PhaseHandler+";dur=") {
+		t.Errorf("expected the captured Server-Timing header got %q", v)
+	}
+}
+
+func TestAddServerTiming(t *testing.T) {
+	handler := func(ctx Context) error {
+		AddServerTiming(ctx, TimingMetric{Name: "db", Duration: 12 * time.Millisecond})
+		AddServerTiming(ctx, TimingMetric{Name: "cache", Description: `hit "L1"`})
+		AddServerTiming(ctx, TimingMetric{Name: ";"})
+		return ctx.String(http.StatusOK, "ok")
+	}
+
+	srv := NewServer(ServerTiming())
+	srv.Route("/").GET("/metrics", handler)
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if got, want := res.Header().Get("Server-Timing"), `db;dur=12.000, cache;desc="hit \"L1\""`; got != want {
+		t.Errorf("expected %q got %q", want, got)
+	}
+
+	srv = NewServer(EnableTimeline())
+	srv.Route("/").GET("/metrics", func(ctx Context) error {
+		if AddServerTiming(ctx, TimingMetric{Name: "db"}) {
+			t.Error("expected metric to be dropped without ServerTiming")
+		}
+		return handler(ctx)
+	})
+	res = httptest.NewRecorder()
+	srv.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if v := res.Header().Get("Server-Timing"); v != "" {
+		t.Errorf("expected no Server-Timing header got %q", v)
+	}
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// BodyTransformFunc transforms the raw request body before decoding,
+// or the raw response body after encoding, which may be empty.
+type BodyTransformFunc func(req *http.Request, body []byte) ([]byte, error)
+
+// TransformRequestBody transforms the raw request body of the route before it is decoded
+// by Bind, e.g. to unwrap the envelope of a legacy client.
+func TransformRequestBody(f BodyTransformFunc) FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if tr, ok := serverTransport(req); ok {
+				tr.reqTransforms = append(tr.reqTransforms, f)
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// TransformResponseBody transforms the raw response body of the route after it is encoded
+// by Result or Returns, e.g. to convert the key case for a legacy client.
+func TransformResponseBody(f BodyTransformFunc) FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if tr, ok := serverTransport(req); ok {
+				tr.resTransforms = append(tr.resTransforms, f)
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// transformRequest replaces the request body with the transformed one.
+func transformRequest(req *http.Request) error {
+	tr, ok := serverTransport(req)
+	if !ok || len(tr.reqTransforms) == 0 {
+		return nil
+	}
+	data, err := requestBody(req)
+	if err != nil {
+		return errors.BadRequest("CODEC", err.Error())
+	}
+	for _, f := range tr.reqTransforms {
+		if data, err = f(req, data); err != nil {
+			return err
+		}
+	}
+	setRequestBody(req, data)
+	return nil
+}
+
+// transformResponse encodes the response into a buffer and writes the transformed body.
+func transformResponse(w http.ResponseWriter, req *http.Request, encode func(http.ResponseWriter) error) error {
+	tr, ok := serverTransport(req)
+	if !ok || len(tr.resTransforms) == 0 {
+		return encode(w)
+	}
+	buf := &bufferWriter{ResponseWriter: w}
+	if err := encode(buf); err != nil {
+		return err
+	}
+	code := buf.code
+	if rw, ok := w.(*responseWriter); ok && code == 0 {
+		code = rw.code
+	}
+	if !bodyAllowed(code) {
+		// the transforms would add a body to a status which forbids it.
+		writeStatus(w, code)
+		return nil
+	}
+	data := buf.body.Bytes()
+	var err error
+	for _, f := range tr.resTransforms {
+		if data, err = f(req, data); err != nil {
+			return err
+		}
+	}
+	if len(data) == 0 {
+		if buf.code != 0 {
+			writeStatus(w, buf.code)
+		}
+		return nil
+	}
+	if buf.code != 0 {
+		w.WriteHeader(buf.code)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// bodyAllowed reports whether a response of the status may have a body, a zero code is 200.
+func bodyAllowed(code int) bool {
+	if code == 0 {
+		return true
+	}
+	return code >= http.StatusOK && code != http.StatusNoContent && code != http.StatusNotModified
+}
+
+// bufferWriter buffers the response body and status code, the header is shared.
+// A zero code means WriteHeader was not called.
+type bufferWriter struct {
+	http.ResponseWriter
+	code int
+	body bytes.Buffer
+}
+
+func (w *bufferWriter) WriteHeader(statusCode int)     { w.code = statusCode }
+func (w *bufferWriter) Write(data []byte) (int, error) { return w.body.Write(data) }
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestTransformBody(t *testing.T) {
+	unwrap := func(_ *http.Request, body []byte) ([]byte, error) {
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return nil, errors.BadRequest("ENVELOPE", err.Error())
+		}
+		return envelope.Data, nil
+	}
+	upper := func(_ *http.Request, body []byte) ([]byte, error) {
+		return bytes.ToUpper(body), nil
+	}
+
+	srv := NewServer()
+	r := srv.Route("/")
+	r.POST("/transform", func(ctx Context) error {
+		var in strictRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusCreated, in)
+	}, TransformRequestBody(unwrap), TransformResponseBody(upper))
+	r.POST("/plain", func(ctx Context) error {
+		var in strictRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(http.StatusOK, in)
+	})
+
+	res := serveStrict(srv, http.MethodPost, "/transform", "application/json", `{"data":{"name":"kratos"}}`)
+	if res.Code != http.StatusCreated {
+		t.Fatalf("expected %d got %d", http.StatusCreated, res.Code)
+	}
+	if got, want := strings.TrimSpace(res.Body.String()), `{"NAME":"KRATOS"}`; got != want {
+		t.Errorf("expected %s got %s", want, got)
+	}
+
+	res = serveStrict(srv, http.MethodPost, "/transform", "application/json", `[]`)
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("expected %d got %d", http.StatusBadRequest, res.Code)
+	}
+
+	res = serveStrict(srv, http.MethodPost, "/plain", "application/json", `{"name":"kratos"}`)
+	if got, want := strings.TrimSpace(res.Body.String()), `{"name":"kratos"}`; got != want {
+		t.Errorf("expected %s got %s", want, got)
+	}
+}
+
+func TestTransformResponseNoBody(t *testing.T) {
+	envelope := func(_ *http.Request, body []byte) ([]byte, error) {
+		if len(body) == 0 {
+			body = []byte("null")
+		}
+		return append(append([]byte(`{"data":`), body...), '}'), nil
+	}
+
+	srv := NewServer()
+	r := srv.Route("/")
+	r.DELETE("/users/1", func(ctx Context) error {
+		return ctx.Returns(NoContent(), nil)
+	}, TransformResponseBody(envelope))
+	r.GET("/users/1", func(ctx Context) error {
+		return ctx.Result(http.StatusNotModified, nil)
+	}, TransformResponseBody(envelope))
+
+	for _, test := range []struct {
+		method string
+		code   int
+	}{
+		{http.MethodDelete, http.StatusNoContent},
+		{http.MethodGet, http.StatusNotModified},
+	} {
+		res := serveStrict(srv, test.method, "/users/1", "", "")
+		if res.Code != test.code || res.Body.Len() != 0 {
+			t.Errorf("%s: expected %d without a body got %d %q", test.method, test.code, res.Code, res.Body)
+		}
+	}
+}
@@ -20,10 +20,29 @@ type Transporter interface {
 type Transport struct {
 	endpoint     string
 	operation    string
+	clientIP     string
+	scheme       string
 	reqHeader    headerCarrier
 	replyHeader  headerCarrier
 	request      *http.Request
 	pathTemplate string
+	mux          Mux
+	timeline     *Timeline
+	serverTiming bool
+	// route rules set by the route filters.
+	consumes        []string
+	disallowUnknown bool
+	reqTransforms   []BodyTransformFunc
+	resTransforms   []BodyTransformFunc
+	envelope        *envelope
+	// the route context of the net/http middleware, and whether one responded.
+	route     *wrapper
+	responded bool
+	// the path variables of a request routed by another router.
+	vars map[string]string
+	// the request body read once, nil if it is not read.
+	data []byte
+	body bodyReader
 }
 
 // Kind returns the transport kind.
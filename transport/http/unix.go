@@ -0,0 +1,52 @@
+package http
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// unixHostSuffix marks the request host which is encoded from a unix socket name,
+// so that the connections to different sockets are not pooled together.
+const unixHostSuffix = ".unix"
+
+func unixHost(name string) string {
+	return hex.EncodeToString([]byte(name)) + unixHostSuffix
+}
+
+func parseUnixHost(addr string) (string, bool) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if !strings.HasSuffix(host, unixHostSuffix) {
+		return "", false
+	}
+	name, err := hex.DecodeString(strings.TrimSuffix(host, unixHostSuffix))
+	if err != nil {
+		return "", false
+	}
+	return string(name), true
+}
+
+// unixTransport returns a copy of the transport which dials the unix socket hosts.
+func unixTransport(rt http.RoundTripper) http.RoundTripper {
+	tr, ok := rt.(*http.Transport)
+	if !ok {
+		return rt
+	}
+	tr = tr.Clone()
+	dial := tr.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if name, ok := parseUnixHost(addr); ok {
+			return dial(ctx, "unix", name)
+		}
+		return dial(ctx, network, addr)
+	}
+	return tr
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+type unixDiscovery struct {
+	ins []*registry.ServiceInstance
+}
+
+func (d *unixDiscovery) GetService(_ context.Context, _ string) ([]*registry.ServiceInstance, error) {
+	return d.ins, nil
+}
+
+func (d *unixDiscovery) Watch(_ context.Context, _ string) (registry.Watcher, error) {
+	return &unixWatcher{ins: d.ins, exit: make(chan struct{})}, nil
+}
+
+type unixWatcher struct {
+	ins  []*registry.ServiceInstance
+	sent bool
+	exit chan struct{}
+}
+
+func (w *unixWatcher) Next() ([]*registry.ServiceInstance, error) {
+	if !w.sent {
+		w.sent = true
+		return w.ins, nil
+	}
+	<-w.exit
+	return nil, context.Canceled
+}
+
+func (w *unixWatcher) Stop() error {
+	close(w.exit)
+	return nil
+}
+
+func TestUnixEndpoint(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "http.sock")
+	srv := NewServer(Network("unix"), Address(sock))
+	srv.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `"}`))
+	})
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "unix://" + sock + "?protocol=http"; e.String() != want {
+		t.Fatalf("expected %s got %s", want, e.String())
+	}
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+
+	dis := &unixDiscovery{ins: []*registry.ServiceInstance{{
+		ID:        "1",
+		Name:      "unix",
+		Endpoints: []string{e.String()},
+	}}}
+	for _, endpoint := range []string{"unix://" + sock, "discovery:///unix"} {
+		client, err := NewClient(context.Background(), WithEndpoint(endpoint), WithDiscovery(dis), WithBlock())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var reply testData
+		err = client.Invoke(context.Background(), http.MethodGet, "/hello", nil, &reply)
+		_ = client.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", endpoint, err)
+		}
+		if reply.Path != "/hello" {
+			t.Errorf("%s: expected %s got %s", endpoint, "/hello", reply.Path)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/go-kratos/kratos/v2/internal/endpoint"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/transport/http/binding"
+)
+
+var errUnixURL = errors.New("http: no URL of a unix socket endpoint")
+
+// URL returns the absolute URL of the operation of the path template, e.g.
+// "/v1/orders/{id}", filled with the fields of in, of which the others are
+// the query, as the generated clients encode the requests. The host is of an
+// instance picked by the selector when the client resolves the target through
+// discovery, otherwise of the endpoint, e.g. a VIP, so that the URLs of the
+// callbacks, webhooks and Location headers point at the right instance.
+func (client *Client) URL(ctx context.Context, pathTemplate string, in interface{}) (*url.URL, error) {
+	u := &url.URL{Scheme: client.target.Scheme, Host: client.target.Authority}
+	if client.r != nil {
+		node, done, err := client.selector.Select(ctx, selector.WithNodeFilter(client.opts.nodeFilters...))
+		if err != nil {
+			return nil, err
+		}
+		// nothing is sent to the node.
+		done(ctx, selector.DoneInfo{})
+		switch {
+		case node.Scheme() != "http":
+			u.Scheme = node.Scheme()
+		case client.insecure:
+			u.Scheme = "http"
+		default:
+			u.Scheme = "https"
+		}
+		if _, ok := endpoint.ParseUnixAddress(node.Address()); ok {
+			return nil, errUnixURL
+		}
+		u.Host = node.Address()
+	} else if _, ok := parseUnixHost(u.Host); ok {
+		return nil, errUnixURL
+	}
+	return resolveURL(u, pathTemplate, in)
+}
+
+// URL returns the absolute URL of the operation of the path template on the
+// endpoint of the server, filled with the fields of in like Client.URL,
+// e.g. of the Location header of a created resource.
+func (s *Server) URL(pathTemplate string, in interface{}) (*url.URL, error) {
+	e, err := s.Endpoint()
+	if err != nil {
+		return nil, err
+	}
+	return resolveURL(e, pathTemplate, in)
+}
+
+// resolveURL resolves the path of the template filled with in against the base.
+func resolveURL(base *url.URL, pathTemplate string, in interface{}) (*url.URL, error) {
+	ref, err := url.Parse(binding.EncodeURL(pathTemplate, in, true))
+	if err != nil {
+		return nil, err
+	}
+	return base.ResolveReference(ref), nil
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type urlRequest struct {
+	Name string `json:"name"`
+	Page int    `json:"page,omitempty"`
+}
+
+func TestClientURL(t *testing.T) {
+	ctx := context.Background()
+	in := &urlRequest{Name: "kratos", Page: 2}
+
+	client, err := NewClient(ctx, WithEndpoint("vip.example.com:8000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	u, err := client.URL(ctx, "/helloworld/{name}", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.String(); got != "http://vip.example.com:8000/helloworld/kratos?page=2" {
+		t.Errorf("unexpected URL of the endpoint %s", got)
+	}
+
+	client, err = NewClient(ctx,
+		WithEndpoint("discovery:///helloworld"),
+		WithDiscovery(staticDiscovery{
+			{ID: "1", Endpoints: []string{"http://10.0.0.1:8000"}},
+		}),
+		WithBlock(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	u, err = client.URL(ctx, "/callback/{name}", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.String(); got != "http://10.0.0.1:8000/callback/kratos?page=2" {
+		t.Errorf("unexpected URL of the discovered instance %s", got)
+	}
+
+	client, err = NewClient(ctx, WithEndpoint("unix:///tmp/kratos.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if _, err = client.URL(ctx, "/helloworld/{name}", in); err == nil {
+		t.Error("expected an error of the unix socket endpoint")
+	}
+}
+
+func TestServerURL(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"))
+	defer srv.Stop(context.Background())
+	u, err := srv.URL("/helloworld/{name}", &urlRequest{Name: "kratos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(u.String(), "http://127.0.0.1:") || u.Path != "/helloworld/kratos" {
+		t.Errorf("unexpected URL of the server %s", u)
+	}
+}
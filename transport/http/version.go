@@ -0,0 +1,160 @@
+package http
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type versionKey struct{}
+
+// VersionOption is API version option.
+type VersionOption func(*apiVersion)
+
+type apiVersion struct {
+	base       string
+	version    string
+	deprecated time.Time
+	sunset     time.Time
+	link       string
+}
+
+// Deprecated with the time the version is deprecated at, replied in the
+// Deprecation header of RFC 9745.
+func Deprecated(at time.Time) VersionOption {
+	return func(v *apiVersion) {
+		v.deprecated = at
+	}
+}
+
+// Sunset with the time the version is removed at, replied in the Sunset
+// header of RFC 8594.
+func Sunset(at time.Time) VersionOption {
+	return func(v *apiVersion) {
+		v.sunset = at
+	}
+}
+
+// DeprecationLink with the link of the documentation of the deprecation,
+// e.g. the migration guide, replied in the Link header.
+func DeprecationLink(url string) VersionOption {
+	return func(v *apiVersion) {
+		v.link = url
+	}
+}
+
+// AcceptVersion with the API version of the requests without the version path
+// prefix read from the Accept header, e.g. "application/vnd.api.v2+json" or
+// "application/json; version=v2", or the fallback if none, which are routed
+// to the routes of the Router.Version of it. An empty fallback leaves the
+// requests of no version as they are.
+func AcceptVersion(fallback string) ServerOption {
+	return func(s *Server) {
+		s.acceptVersion = fallback
+		s.routeVersions = true
+	}
+}
+
+// VersionFromContext returns the API version of the route in ctx, see Router.Version.
+func VersionFromContext(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(versionKey{}).(string)
+	return v, ok
+}
+
+// Version returns a new router group of the routes of the API version, e.g.
+// "v2" of "/v2/users", whose version is in the contexts of the requests, see
+// VersionFromContext.
+func (r *Router) Version(version string, opts ...VersionOption) *Router {
+	v := &apiVersion{base: r.prefix, version: version}
+	for _, o := range opts {
+		o(v)
+	}
+	r.srv.versions = append(r.srv.versions, v)
+	return r.Group(version, v.filter)
+}
+
+func (v *apiVersion) filter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		header := w.Header()
+		if !v.deprecated.IsZero() {
+			header.Set("Deprecation", "@"+strconv.FormatInt(v.deprecated.Unix(), 10))
+		}
+		if !v.sunset.IsZero() {
+			header.Set("Sunset", v.sunset.UTC().Format(http.TimeFormat))
+		}
+		if v.link != "" {
+			header.Add("Link", "<"+v.link+`>; rel="deprecation"`)
+		}
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), versionKey{}, v.version)))
+	})
+}
+
+// routeVersion routes the requests without the version path prefix to the
+// routes of the version of the Accept header, or of the fallback.
+func (s *Server) routeVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if p, ok := s.versionPath(req); ok {
+			req = req.Clone(req.Context())
+			req.URL.Path = p
+			req.URL.RawPath = ""
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (s *Server) versionPath(req *http.Request) (string, bool) {
+	p := req.URL.Path
+	for _, v := range s.versions {
+		if prefix := path.Join(v.base, v.version); p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return "", false
+		}
+	}
+	version := acceptedVersion(req.Header.Values("Accept"))
+	if version == "" {
+		version = s.acceptVersion
+	}
+	for _, v := range s.versions {
+		if v.version != version {
+			continue
+		}
+		base := strings.TrimSuffix(v.base, "/")
+		if base == "" || p == base || strings.HasPrefix(p, base+"/") {
+			return path.Join(base, v.version, strings.TrimPrefix(p, base)), true
+		}
+	}
+	return "", false
+}
+
+// acceptedVersion returns the version of the Accept header, of the version
+// parameter, or of the vendor media type, e.g. "application/vnd.api.v2+json".
+func acceptedVersion(accepts []string) string {
+	for _, accept := range accepts {
+		for _, part := range strings.Split(accept, ",") {
+			mediatype, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			if v := params["version"]; v != "" {
+				if !strings.HasPrefix(v, "v") {
+					v = "v" + v
+				}
+				return v
+			}
+			subtype := mediatype[strings.IndexByte(mediatype, '/')+1:]
+			if !strings.HasPrefix(subtype, "vnd.") {
+				continue
+			}
+			subtype, _, _ = strings.Cut(subtype, "+")
+			if i := strings.LastIndexByte(subtype, '.'); i > 0 {
+				if v := subtype[i+1:]; len(v) > 1 && v[0] == 'v' && v[1] >= '0' && v[1] <= '9' {
+					return v
+				}
+			}
+		}
+	}
+	return ""
+}
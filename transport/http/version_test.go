@@ -0,0 +1,80 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVersion(t *testing.T) {
+	sunset := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv := NewServer(AcceptVersion("v1"))
+	api := srv.Route("/api")
+	for _, v := range []*Router{
+		api.Version("v1", Deprecated(time.Unix(1700000000, 0)), Sunset(sunset), DeprecationLink("https://example.com/v2")),
+		api.Version("v2"),
+	} {
+		v.GET("/users", func(ctx Context) error {
+			version, _ := VersionFromContext(ctx)
+			return ctx.String(http.StatusOK, version)
+		})
+	}
+	tests := []struct {
+		path   string
+		accept string
+		want   string
+	}{
+		{"/api/v1/users", "", "v1"},
+		{"/api/v2/users", "application/vnd.api.v1+json", "v2"},
+		{"/api/users", "", "v1"},
+		{"/api/users", "application/vnd.api.v2+json", "v2"},
+		{"/api/users", "application/json; version=2", "v2"},
+		{"/api/users", "text/html, application/json;version=v2;q=0.9", "v2"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || w.Body.String() != test.want {
+			t.Errorf("%s %q: expected %s got %d %s", test.path, test.accept, test.want, w.Code, w.Body.String())
+			continue
+		}
+		deprecated := w.Header().Get("Deprecation") != ""
+		if deprecated != (test.want == "v1") {
+			t.Errorf("%s %q: unexpected Deprecation %q", test.path, test.accept, w.Header().Get("Deprecation"))
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if v := w.Header().Get("Deprecation"); v != "@1700000000" {
+		t.Errorf("unexpected Deprecation %q", v)
+	}
+	if v := w.Header().Get("Sunset"); v != "Tue, 01 Jan 2030 00:00:00 GMT" {
+		t.Errorf("unexpected Sunset %q", v)
+	}
+	if v := w.Header().Get("Link"); v != `<https://example.com/v2>; rel="deprecation"` {
+		t.Errorf("unexpected Link %q", v)
+	}
+}
+
+func TestAcceptedVersion(t *testing.T) {
+	tests := map[string]string{
+		"application/json":                 "",
+		"application/vnd.github+json":      "",
+		"application/vnd.api.v3+json":      "v3",
+		"application/vnd.api.v3":           "v3",
+		"application/json; version=4":      "v4",
+		"application/vnd.company.vendor+x": "",
+	}
+	for accept, want := range tests {
+		if got := acceptedVersion([]string{accept}); got != want {
+			t.Errorf("%q: expected %q got %q", accept, want, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Package inprocess wires the kratos HTTP and gRPC servers to their clients
+// over an in-memory listener, so that the services are tested end-to-end with
+// their middleware, codecs and error mapping without binding real ports:
+//
+//	lis := inprocess.NewListener()
+//	srv := lis.NewGRPCServer(grpc.Middleware(recovery.Recovery()))
+//	pb.RegisterGreeterServer(srv, service)
+//	inprocess.Serve(t, srv)
+//	conn, err := lis.DialGRPC(ctx)
+//
+// Note that the HTTP/3 mode is not available, as there is no QUIC transport.
+package inprocess
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	kgrpc "github.com/go-kratos/kratos/v2/transport/grpc"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Host is the host of the endpoints of the in-memory servers.
+const Host = "inprocess"
+
+// defaultBufferSize is the size of the buffers of the in-memory connections.
+const defaultBufferSize = 1 << 20
+
+// Listener is an in-memory listener, of which the connections are dialed in process.
+type Listener struct {
+	lis *bufconn.Listener
+}
+
+var _ net.Listener = (*Listener)(nil)
+
+// NewListener new an in-memory listener.
+func NewListener() *Listener {
+	return &Listener{lis: bufconn.Listen(defaultBufferSize)}
+}
+
+// Accept waits for and returns the next connection dialed.
+func (l *Listener) Accept() (net.Conn, error) {
+	return l.lis.Accept()
+}
+
+// Close closes the listener, the connections dialed are not closed.
+func (l *Listener) Close() error {
+	return l.lis.Close()
+}
+
+// Addr returns the in-memory address of the listener.
+func (l *Listener) Addr() net.Addr {
+	return l.lis.Addr()
+}
+
+// DialContext dials a connection of the listener, the network and the address are ignored.
+func (l *Listener) DialContext(ctx context.Context, _, _ string) (net.Conn, error) {
+	return l.lis.DialContext(ctx)
+}
+
+// NewHTTPServer new an HTTP server serving the listener, of the endpoint http://inprocess.
+func (l *Listener) NewHTTPServer(opts ...khttp.ServerOption) *khttp.Server {
+	return khttp.NewServer(append(opts, khttp.Listener(l), khttp.Endpoint(&url.URL{Scheme: "http", Host: Host}))...)
+}
+
+// NewGRPCServer new a gRPC server serving the listener, of the endpoint grpc://inprocess.
+func (l *Listener) NewGRPCServer(opts ...kgrpc.ServerOption) *kgrpc.Server {
+	return kgrpc.NewServer(append(opts, kgrpc.Listener(l), kgrpc.Endpoint(&url.URL{Scheme: "grpc", Host: Host}))...)
+}
+
+// NewHTTPClient new an HTTP client of the server of the listener.
+func (l *Listener) NewHTTPClient(ctx context.Context, opts ...khttp.ClientOption) (*khttp.Client, error) {
+	return khttp.NewClient(ctx, append(opts, khttp.WithEndpoint("http://"+Host), khttp.WithDialContext(l.DialContext))...)
+}
+
+// DialGRPC dials an insecure gRPC connection of the server of the listener.
+// WithOptions replaces the dial options, so that they must include DialOption.
+func (l *Listener) DialGRPC(ctx context.Context, opts ...kgrpc.ClientOption) (*grpc.ClientConn, error) {
+	opts = append([]kgrpc.ClientOption{kgrpc.WithOptions(l.DialOption())}, opts...)
+	return kgrpc.DialInsecure(ctx, append(opts, kgrpc.WithEndpoint("passthrough:///"+Host))...)
+}
+
+// DialOption returns the gRPC dial option dialing the listener.
+func (l *Listener) DialOption() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return l.lis.DialContext(ctx)
+	})
+}
+
+// Serve starts the server until the end of the test, when it is stopped.
+func Serve(tb testing.TB, srv transport.Server) {
+	tb.Helper()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Start(context.Background())
+	}()
+	tb.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Stop(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			tb.Errorf("inprocess: stop server: %v", err)
+		}
+		if err := <-errc; err != nil {
+			tb.Errorf("inprocess: serve: %v", err)
+		}
+	})
+}
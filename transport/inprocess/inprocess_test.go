@@ -0,0 +1,107 @@
+package inprocess
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	kgrpc "github.com/go-kratos/kratos/v2/transport/grpc"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type greeter struct {
+	pb.UnimplementedGreeterServer
+}
+
+func (greeter) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	if in.Name == "" {
+		return nil, errors.BadRequest("MISSING_NAME", "missing name")
+	}
+	tr, _ := transport.FromServerContext(ctx)
+	return &pb.HelloReply{Message: "hello " + in.Name + " " + tr.RequestHeader().Get("x-md-global-from")}, nil
+}
+
+func from(handler middleware.Handler) middleware.Handler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		if tr, ok := transport.FromClientContext(ctx); ok {
+			tr.RequestHeader().Set("x-md-global-from", "inprocess")
+		}
+		return handler(ctx, req)
+	}
+}
+
+func TestGRPC(t *testing.T) {
+	lis := NewListener()
+	srv := lis.NewGRPCServer()
+	pb.RegisterGreeterServer(srv, greeter{})
+	Serve(t, srv)
+	if u, err := srv.Endpoint(); err != nil || u.String() != "grpc://inprocess" {
+		t.Errorf("unexpected endpoint %v %v", u, err)
+	}
+
+	conn, err := lis.DialGRPC(context.Background(), kgrpc.WithMiddleware(from))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := pb.NewGreeterClient(conn)
+	reply, err := client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+	if err != nil || reply.Message != "hello kratos inprocess" {
+		t.Errorf("unexpected reply %v %v", reply, err)
+	}
+	if _, err = client.SayHello(context.Background(), &pb.HelloRequest{}); !errors.IsBadRequest(err) || errors.Reason(err) != "MISSING_NAME" {
+		t.Errorf("expected the error of the server, got %v", err)
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	lis := NewListener()
+	srv := lis.NewHTTPServer()
+	pb.RegisterGreeterHTTPServer(srv, greeter{})
+	Serve(t, srv)
+
+	client, err := lis.NewHTTPClient(context.Background(), khttp.WithMiddleware(from))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+	hc := pb.NewGreeterHTTPClient(client)
+	reply, err := hc.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+	if err != nil || reply.Message != "hello kratos inprocess" {
+		t.Errorf("unexpected reply %v %v", reply, err)
+	}
+	var res map[string]interface{}
+	err = client.Invoke(context.Background(), http.MethodGet, "/helloworld/", nil, &res)
+	if errors.Code(err) != http.StatusNotFound {
+		t.Errorf("expected 404 got %v", err)
+	}
+}
+
+func TestHTTPRouter(t *testing.T) {
+	lis := NewListener()
+	srv := lis.NewHTTPServer()
+	// the generated service mounted of the prefixes of the groups.
+	pb.RegisterGreeterHTTPServerTo(srv.Route("/v1"), greeter{})
+	pb.RegisterGreeterHTTPServerTo(srv.Route("/").Group("/v2"), greeter{})
+	Serve(t, srv)
+
+	client, err := lis.NewHTTPClient(context.Background(), khttp.WithMiddleware(from))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+	for _, path := range []string{"/v1/helloworld/kratos", "/v2/helloworld/kratos"} {
+		var reply pb.HelloReply
+		if err = client.Invoke(context.Background(), http.MethodGet, path, nil, &reply); err != nil || reply.Message != "hello kratos inprocess" {
+			t.Errorf("%s: unexpected reply %v %v", path, reply.Message, err)
+		}
+	}
+	var res map[string]interface{}
+	if err = client.Invoke(context.Background(), http.MethodGet, "/helloworld/kratos", nil, &res); errors.Code(err) != http.StatusNotFound {
+		t.Errorf("expected 404 got %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+// Package proxyproto wraps the listeners behind the load balancers sending
+// the PROXY protocol v1 or v2 header, so that the remote addresses of the
+// connections are the ones of the clients, e.g. of the HTTP requests or the
+// gRPC peers:
+//
+//	lis, err := net.Listen("tcp", ":8000")
+//	srv := http.NewServer(http.Listener(proxyproto.NewListener(lis, proxyproto.WithTrusted("10.0.0.0/8"))))
+//
+// The connections without the header are used as they are.
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// signature is the signature of the v2 header.
+var signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+const (
+	// maxV1Length is the max length of the v1 header.
+	maxV1Length = 107
+	// defaultHeaderTimeout is the default timeout of reading the header.
+	defaultHeaderTimeout = 5 * time.Second
+)
+
+// ErrInvalidHeader is returned by the reads of the connections of an invalid header.
+var ErrInvalidHeader = errors.New("proxyproto: invalid header")
+
+// Option is proxy protocol listener option.
+type Option func(*Listener)
+
+// WithTrusted with the IPs or CIDRs of the load balancers whose headers are
+// trusted, the headers of the others are not read, default is all.
+func WithTrusted(cidrs ...string) Option {
+	return func(l *Listener) {
+		for _, cidr := range cidrs {
+			n, err := host.ParseCIDR(cidr)
+			if err != nil {
+				log.Errorf("[PROXY] invalid trusted IP %s: %v", cidr, err)
+				continue
+			}
+			l.trusted = append(l.trusted, n)
+		}
+	}
+}
+
+// WithHeaderTimeout with the timeout of reading the header, default is 5s.
+func WithHeaderTimeout(d time.Duration) Option {
+	return func(l *Listener) {
+		l.timeout = d
+	}
+}
+
+// Listener is the listener of the connections of the PROXY protocol.
+type Listener struct {
+	net.Listener
+	trusted []*net.IPNet
+	timeout time.Duration
+}
+
+// NewListener wraps the listener of the PROXY protocol.
+func NewListener(lis net.Listener, opts ...Option) *Listener {
+	l := &Listener{Listener: lis, timeout: defaultHeaderTimeout}
+	for _, o := range opts {
+		o(l)
+	}
+	return l
+}
+
+// Accept accepts a connection, whose header is read on the first read or the
+// first call of its addresses, not to block the accept loop.
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if !l.trust(conn.RemoteAddr()) {
+		return conn, nil
+	}
+	return &Conn{Conn: conn, r: bufio.NewReader(conn), timeout: l.timeout}, nil
+}
+
+func (l *Listener) trust(addr net.Addr) bool {
+	if len(l.trusted) == 0 {
+		return true
+	}
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, n := range l.trusted {
+		if n.Contains(tcp.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// Conn is a connection of the PROXY protocol.
+type Conn struct {
+	net.Conn
+	r       *bufio.Reader
+	timeout time.Duration
+
+	once     sync.Once
+	err      error
+	src, dst net.Addr
+}
+
+// Read reads the data after the header.
+func (c *Conn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(b)
+}
+
+// ReadFrom uses the ReadFrom of the connection if any, e.g. sendfile on TCP.
+func (c *Conn) ReadFrom(r io.Reader) (int64, error) {
+	if rf, ok := c.Conn.(io.ReaderFrom); ok {
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(struct{ io.Writer }{c.Conn}, r)
+}
+
+// RemoteAddr returns the source address of the header, or of the connection.
+func (c *Conn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.src != nil {
+		return c.src
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns the destination address of the header, or of the connection.
+func (c *Conn) LocalAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.dst != nil {
+		return c.dst
+	}
+	return c.Conn.LocalAddr()
+}
+
+func (c *Conn) readHeader() {
+	if c.timeout > 0 {
+		_ = c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+		defer func() { _ = c.Conn.SetReadDeadline(time.Time{}) }()
+	}
+	peek, err := c.r.Peek(len(signature))
+	switch {
+	case bytes.Equal(peek, signature):
+		c.src, c.dst, c.err = c.readV2()
+	case bytes.HasPrefix(peek, []byte("PROXY ")):
+		c.src, c.dst, c.err = c.readV1()
+	case err != nil && !bytes.HasPrefix([]byte("PROXY "), peek) && !bytes.HasPrefix(signature, peek):
+		// a short connection without the header.
+	case err != nil:
+		c.err = err
+	}
+}
+
+func (c *Conn) readV1() (net.Addr, net.Addr, error) {
+	var line []byte
+	for len(line) < maxV1Length {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	s := string(line)
+	if !strings.HasSuffix(s, "\r\n") {
+		return nil, nil, ErrInvalidHeader
+	}
+	fields := strings.Fields(strings.TrimSuffix(s, "\r\n"))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") { //nolint:gomnd
+		return nil, nil, fmt.Errorf("%w: %q", ErrInvalidHeader, s)
+	}
+	src, err := tcpAddr(fields[2], fields[4])
+	if err != nil {
+		return nil, nil, err
+	}
+	dst, err := tcpAddr(fields[3], fields[5])
+	if err != nil {
+		return nil, nil, err
+	}
+	return src, dst, nil
+}
+
+func tcpAddr(ip, port string) (*net.TCPAddr, error) {
+	addr := net.ParseIP(ip)
+	p, err := strconv.ParseUint(port, 10, 16)
+	if addr == nil || err != nil {
+		return nil, fmt.Errorf("%w: address %s:%s", ErrInvalidHeader, ip, port)
+	}
+	return &net.TCPAddr{IP: addr, Port: int(p)}, nil
+}
+
+func (c *Conn) readV2() (net.Addr, net.Addr, error) {
+	header := make([]byte, 16) //nolint:gomnd
+	if _, err := io.ReadFull(c.r, header); err != nil {
+		return nil, nil, err
+	}
+	if header[12]>>4 != 2 { //nolint:gomnd
+		return nil, nil, fmt.Errorf("%w: version %d", ErrInvalidHeader, header[12]>>4)
+	}
+	body := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return nil, nil, err
+	}
+	// LOCAL command, e.g. the health checks of the load balancer.
+	if header[12]&0xf == 0 {
+		return nil, nil, nil
+	}
+	var size int
+	switch header[13] >> 4 {
+	case 1: // AF_INET
+		size = net.IPv4len
+	case 2: // AF_INET6
+		size = net.IPv6len
+	default:
+		return nil, nil, nil
+	}
+	if len(body) < 2*size+4 {
+		return nil, nil, fmt.Errorf("%w: length %d", ErrInvalidHeader, len(body))
+	}
+	src := &net.TCPAddr{IP: net.IP(body[:size]), Port: int(binary.BigEndian.Uint16(body[2*size:]))}
+	dst := &net.TCPAddr{IP: net.IP(body[size : 2*size]), Port: int(binary.BigEndian.Uint16(body[2*size+2:]))}
+	return src, dst, nil
+}
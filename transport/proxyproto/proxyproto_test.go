@@ -0,0 +1,89 @@
+package proxyproto
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+func v2Header(command byte, src, dst *net.TCPAddr) []byte {
+	b := append([]byte{}, signature...)
+	b = append(b, 0x20|command, 0x11, 0, 12)
+	b = append(b, src.IP.To4()...)
+	b = append(b, dst.IP.To4()...)
+	b = binary.BigEndian.AppendUint16(b, uint16(src.Port))
+	return binary.BigEndian.AppendUint16(b, uint16(dst.Port))
+}
+
+func accept(t *testing.T, header []byte, opts ...Option) (net.Conn, string, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewListener(lis, opts...)
+	defer l.Close()
+	go func() {
+		conn, err := net.Dial("tcp", lis.Addr().String())
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write(append(header, "hello"...))
+	}()
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	data, err := io.ReadAll(conn)
+	return conn, string(data), err
+}
+
+func TestListener(t *testing.T) {
+	src := &net.TCPAddr{IP: net.ParseIP("203.0.113.7").To4(), Port: 51000}
+	dst := &net.TCPAddr{IP: net.ParseIP("10.0.0.1").To4(), Port: 443}
+	tests := []struct {
+		name   string
+		header []byte
+		opts   []Option
+		remote string
+		data   string
+	}{
+		{"v1", []byte("PROXY TCP4 203.0.113.7 10.0.0.1 51000 443\r\n"), nil, "203.0.113.7:51000", "hello"},
+		{"v1 ipv6", []byte("PROXY TCP6 2001:db8::1 2001:db8::2 51000 443\r\n"), nil, "[2001:db8::1]:51000", "hello"},
+		{"v1 unknown", []byte("PROXY UNKNOWN\r\n"), nil, "", "hello"},
+		{"v2", v2Header(1, src, dst), nil, "203.0.113.7:51000", "hello"},
+		{"v2 local", v2Header(0, src, dst), nil, "", "hello"},
+		{"none", nil, nil, "", "hello"},
+		{"untrusted", []byte("PROXY TCP4 203.0.113.7 10.0.0.1 51000 443\r\n"), []Option{WithTrusted("10.0.0.0/8")}, "", "PROXY TCP4 203.0.113.7 10.0.0.1 51000 443\r\nhello"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn, data, err := accept(t, test.header, test.opts...)
+			if err != nil || data != test.data {
+				t.Fatalf("unexpected data %q %v", data, err)
+			}
+			remote := conn.RemoteAddr().String()
+			if test.remote == "" {
+				if ip := conn.RemoteAddr().(*net.TCPAddr).IP; !ip.IsLoopback() {
+					t.Errorf("expected the address of the connection got %s", remote)
+				}
+			} else if remote != test.remote {
+				t.Errorf("expected %s got %s", test.remote, remote)
+			}
+		})
+	}
+	if c, _, _ := accept(t, v2Header(1, src, dst)); c.LocalAddr().String() != "10.0.0.1:443" {
+		t.Errorf("unexpected local address %s", c.LocalAddr())
+	}
+}
+
+func TestInvalidHeader(t *testing.T) {
+	for _, header := range []string{"PROXY TCP4 203.0.113.7\r\n", "PROXY TCP4 a b 1 2\r\n"} {
+		if _, _, err := accept(t, []byte(header)); !errors.Is(err, ErrInvalidHeader) {
+			t.Errorf("%q: expected the invalid header got %v", header, err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Package routes exports the routes of the servers as a machine readable JSON
+// document, e.g. for the API gateways and the security scanners in CI/CD.
+// The HTTP routes are identified by their path templates, which are their
+// operations unless the handlers set another one at runtime.
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Param is a typed path parameter of a route.
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Route is an exported route.
+type Route struct {
+	Kind         string   `json:"kind"`
+	Method       string   `json:"method,omitempty"`
+	Path         string   `json:"path,omitempty"`
+	Operation    string   `json:"operation"`
+	Params       []Param  `json:"params,omitempty"`
+	Streaming    bool     `json:"streaming,omitempty"`
+	ContentTypes []string `json:"content_types"`
+	Middleware   []string `json:"middleware,omitempty"`
+	Auth         []string `json:"auth,omitempty"`
+}
+
+// Document is the exported routes of the servers, sorted by kind, operation and method.
+type Document struct {
+	Routes []Route `json:"routes"`
+}
+
+// Option is routes export option.
+type Option func(*options)
+
+type auth struct {
+	selector    string
+	requirement string
+}
+
+type options struct {
+	auth []auth
+}
+
+// WithAuth declares the auth requirement of the operations of the selector,
+// e.g. "/api.v1.*" requires "jwt", of the same syntax as the middleware selectors.
+func WithAuth(selector, requirement string) Option {
+	return func(o *options) {
+		o.auth = append(o.auth, auth{selector: selector, requirement: requirement})
+	}
+}
+
+func (o *options) requirements(operation string) []string {
+	var requirements []string
+	for _, a := range o.auth {
+		if a.selector == operation || (strings.HasSuffix(a.selector, "*") && strings.HasPrefix(operation, strings.TrimSuffix(a.selector, "*"))) {
+			requirements = append(requirements, a.requirement)
+		}
+	}
+	sort.Strings(requirements)
+	return requirements
+}
+
+// Export exports the routes of the HTTP and gRPC servers, the other servers are skipped.
+func Export(servers []transport.Server, opts ...Option) (*Document, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	doc := &Document{Routes: []Route{}}
+	for _, srv := range servers {
+		switch s := srv.(type) {
+		case *khttp.Server:
+			if err := exportHTTP(doc, s, o); err != nil {
+				return nil, err
+			}
+		case *grpc.Server:
+			exportGRPC(doc, s, o)
+		}
+	}
+	sort.SliceStable(doc.Routes, func(i, j int) bool {
+		a, b := doc.Routes[i], doc.Routes[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Operation != b.Operation {
+			return a.Operation < b.Operation
+		}
+		return a.Method < b.Method
+	})
+	return doc, nil
+}
+
+func exportHTTP(doc *Document, srv *khttp.Server, o *options) error {
+	codecs := encoding.Codecs()
+	contentTypes := make([]string, 0, len(codecs))
+	for _, name := range codecs {
+		contentTypes = append(contentTypes, "application/"+name)
+	}
+	return srv.WalkRoute(func(r khttp.RouteInfo) error {
+		route := Route{
+			Kind:         transport.KindHTTP.String(),
+			Method:       r.Method,
+			Path:         r.Path,
+			Operation:    r.Path,
+			ContentTypes: contentTypes,
+			Middleware:   srv.MiddlewareSelectors(r.Path),
+			Auth:         o.requirements(r.Path),
+		}
+		for _, p := range r.Params {
+			route.Params = append(route.Params, Param{Name: p.Name, Type: p.Type})
+		}
+		doc.Routes = append(doc.Routes, route)
+		return nil
+	})
+}
+
+func exportGRPC(doc *Document, srv *grpc.Server, o *options) {
+	for name, info := range srv.GetServiceInfo() {
+		for _, m := range info.Methods {
+			operation := "/" + name + "/" + m.Name
+			doc.Routes = append(doc.Routes, Route{
+				Kind:         transport.KindGRPC.String(),
+				Operation:    operation,
+				Streaming:    m.IsClientStream || m.IsServerStream,
+				ContentTypes: []string{"application/grpc"},
+				Middleware:   srv.MiddlewareSelectors(operation),
+				Auth:         o.requirements(operation),
+			})
+		}
+	}
+}
+
+// Handler returns the handler serving the exported routes in JSON, e.g.
+// mounted with HandleAdmin of an HTTP server on "/debug/routes".
+func Handler(servers []transport.Server, opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		doc, err := Export(servers, opts...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(doc)
+	})
+}
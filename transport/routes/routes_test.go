@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func noop(handler middleware.Handler) middleware.Handler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) { return handler(ctx, req) }
+}
+
+func TestExport(t *testing.T) {
+	hs := khttp.NewServer(khttp.Middleware(noop))
+	hs.Use("/users/*", noop)
+	r := hs.Route("/")
+	r.GET("/users/{id:int}", func(ctx khttp.Context) error { return nil })
+	r.POST("/login", func(ctx khttp.Context) error { return nil })
+	gs := grpc.NewServer()
+
+	doc, err := Export([]transport.Server{hs, gs}, WithAuth("/users/*", "jwt"), WithAuth("/grpc.health.v1.Health/*", "mtls"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := make(map[string]Route, len(doc.Routes))
+	for _, r := range doc.Routes {
+		routes[r.Kind+" "+r.Method+" "+r.Operation] = r
+	}
+	user, ok := routes["http GET /users/{id}"]
+	if !ok {
+		t.Fatalf("expected the user route, got %v", doc.Routes)
+	}
+	if !reflect.DeepEqual(user.Params, []Param{{Name: "id", Type: khttp.ParamInt}}) {
+		t.Errorf("unexpected params %v", user.Params)
+	}
+	if !reflect.DeepEqual(user.Middleware, []string{"*", "/users/*"}) || !reflect.DeepEqual(user.Auth, []string{"jwt"}) {
+		t.Errorf("unexpected middleware %v or auth %v", user.Middleware, user.Auth)
+	}
+	if len(user.ContentTypes) == 0 {
+		t.Error("expected the content types of the codecs")
+	}
+	if login := routes["http POST /login"]; len(login.Auth) != 0 {
+		t.Errorf("expected the login route without auth, got %v", login.Auth)
+	}
+	health, ok := routes["grpc  /grpc.health.v1.Health/Watch"]
+	if !ok || !health.Streaming || !reflect.DeepEqual(health.Auth, []string{"mtls"}) {
+		t.Errorf("unexpected health route %+v", health)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler([]transport.Server{hs}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+	var got Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || len(got.Routes) != 2 {
+		t.Errorf("expected the HTTP routes in JSON, got %s %v", rec.Body, err)
+	}
+}
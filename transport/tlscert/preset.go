@@ -0,0 +1,130 @@
+package tlscert
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+)
+
+// Preset is a TLS policy of an environment, configuring the versions, the
+// cipher suites, the curves, the session tickets and the client auth of
+// the servers and the clients consistently. It can be selected via config
+// by its name, e.g. tls_preset: public-modern.
+type Preset string
+
+const (
+	// PresetInternalMTLS is the policy of the internal traffic, TLS 1.3 only
+	// with the client certificates required and verified, and no session
+	// tickets so each connection is authenticated by its certificates.
+	PresetInternalMTLS Preset = "internal-mtls"
+	// PresetPublicModern is the policy of the public endpoints with modern
+	// clients, TLS 1.3 only.
+	PresetPublicModern Preset = "public-modern"
+	// PresetPublicCompatible is the policy of the public endpoints with older
+	// clients, TLS 1.2 with the forward secret AEAD cipher suites, or TLS 1.3.
+	PresetPublicCompatible Preset = "public-compatible"
+)
+
+// compatibleCipherSuites are the TLS 1.2 cipher suites of PresetPublicCompatible,
+// the TLS 1.3 cipher suites are not configurable.
+var compatibleCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// ParsePreset parses the preset name.
+func ParsePreset(name string) (Preset, error) {
+	switch p := Preset(name); p {
+	case PresetInternalMTLS, PresetPublicModern, PresetPublicCompatible:
+		return p, nil
+	}
+	return "", fmt.Errorf("tlscert: unknown preset %q", name)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so the preset is
+// validated when the config is scanned.
+func (p *Preset) UnmarshalText(text []byte) error {
+	v, err := ParsePreset(string(text))
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// Server returns a copy of the base TLS config, e.g. with the certificates
+// and the client CAs, with the server policy of the preset applied.
+func (p Preset) Server(base *tls.Config) (*tls.Config, error) {
+	c, err := p.apply(base)
+	if err != nil {
+		return nil, err
+	}
+	if p == PresetInternalMTLS {
+		if c.ClientCAs == nil {
+			return nil, errors.New("tlscert: internal-mtls requires the client CAs")
+		}
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return c, nil
+}
+
+// Client returns a copy of the base TLS config, e.g. with the root CAs and
+// the client certificates, with the client policy of the preset applied.
+func (p Preset) Client(base *tls.Config) (*tls.Config, error) {
+	c, err := p.apply(base)
+	if err != nil {
+		return nil, err
+	}
+	if p == PresetInternalMTLS {
+		if len(c.Certificates) == 0 && c.GetClientCertificate == nil {
+			return nil, errors.New("tlscert: internal-mtls requires the client certificates")
+		}
+		return c, nil
+	}
+	if c.ClientSessionCache == nil {
+		c.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+	}
+	return c, nil
+}
+
+func (p Preset) apply(base *tls.Config) (*tls.Config, error) {
+	if _, err := ParsePreset(string(p)); err != nil {
+		return nil, err
+	}
+	var c *tls.Config
+	if base != nil {
+		c = base.Clone()
+	} else {
+		c = &tls.Config{}
+	}
+	c.CurvePreferences = []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384}
+	c.MaxVersion = 0
+	switch p {
+	case PresetInternalMTLS:
+		c.MinVersion = tls.VersionTLS13
+		c.SessionTicketsDisabled = true
+	case PresetPublicModern:
+		c.MinVersion = tls.VersionTLS13
+		c.SessionTicketsDisabled = false
+	case PresetPublicCompatible:
+		c.MinVersion = tls.VersionTLS12
+		c.CipherSuites = compatibleCipherSuites
+		c.SessionTicketsDisabled = false
+	}
+	return c, nil
+}
+
+// QUIC returns a copy of the TLS config for the QUIC listeners and dialers,
+// which require TLS 1.3, with the h3 ALPN when no protocols are set.
+func QUIC(c *tls.Config) *tls.Config {
+	c = c.Clone()
+	c.MinVersion = tls.VersionTLS13
+	if len(c.NextProtos) == 0 {
+		c.NextProtos = []string{"h3"}
+	}
+	return c
+}
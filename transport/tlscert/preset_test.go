@@ -0,0 +1,61 @@
+package tlscert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"testing"
+)
+
+func TestPreset(t *testing.T) {
+	var v struct {
+		Preset Preset `json:"preset"`
+	}
+	if err := json.Unmarshal([]byte(`{"preset":"public-modern"}`), &v); err != nil || v.Preset != PresetPublicModern {
+		t.Fatalf("expected public-modern, got %q %v", v.Preset, err)
+	}
+	if err := json.Unmarshal([]byte(`{"preset":"legacy"}`), &v); err == nil {
+		t.Fatal("expected unknown preset error")
+	}
+	if _, err := Preset("legacy").Server(nil); err == nil {
+		t.Fatal("expected unknown preset error")
+	}
+
+	c, err := PresetPublicCompatible.Server(&tls.Config{MaxVersion: tls.VersionTLS12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.MinVersion != tls.VersionTLS12 || c.MaxVersion != 0 || len(c.CipherSuites) == 0 || c.ClientAuth != tls.NoClientCert {
+		t.Errorf("unexpected public-compatible config %+v", c)
+	}
+	c, err = PresetPublicModern.Client(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.MinVersion != tls.VersionTLS13 || c.ClientSessionCache == nil || c.SessionTicketsDisabled {
+		t.Errorf("unexpected public-modern config %+v", c)
+	}
+
+	if _, err = PresetInternalMTLS.Server(nil); err == nil {
+		t.Error("expected client CAs error")
+	}
+	if _, err = PresetInternalMTLS.Client(nil); err == nil {
+		t.Error("expected client certificates error")
+	}
+	base := &tls.Config{ClientCAs: x509.NewCertPool()}
+	c, err = PresetInternalMTLS.Server(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ClientAuth != tls.RequireAndVerifyClientCert || !c.SessionTicketsDisabled || c.MinVersion != tls.VersionTLS13 {
+		t.Errorf("unexpected internal-mtls config %+v", c)
+	}
+	if base.ClientAuth != tls.NoClientCert {
+		t.Error("expected the base config not modified")
+	}
+
+	q := QUIC(&tls.Config{MinVersion: tls.VersionTLS12})
+	if q.MinVersion != tls.VersionTLS13 || len(q.NextProtos) != 1 || q.NextProtos[0] != "h3" {
+		t.Errorf("unexpected QUIC config %+v", q)
+	}
+}
@@ -0,0 +1,140 @@
+package tlscert
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Provider provides the certificates for the TLS handshakes, e.g. the
+// FileProvider reloading the certificate files, or autocert.Manager.
+type Provider interface {
+	GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error)
+}
+
+// Config returns a copy of the base TLS config which gets the certificates
+// from the provider. The NextProtos of the provider TLS config, e.g. acme-tls/1
+// of autocert.Manager, are appended.
+func Config(base *tls.Config, p Provider) *tls.Config {
+	var c *tls.Config
+	if base != nil {
+		c = base.Clone()
+	} else {
+		c = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	c.GetCertificate = p.GetCertificate
+	if pc, ok := p.(interface{ TLSConfig() *tls.Config }); ok {
+		for _, proto := range pc.TLSConfig().NextProtos {
+			if !contains(c.NextProtos, proto) {
+				c.NextProtos = append(c.NextProtos, proto)
+			}
+		}
+	}
+	return c
+}
+
+// KeyLog returns a copy of the TLS config which writes the TLS master secrets
+// to w in the NSS key log format, e.g. for Wireshark to decrypt the sessions.
+// It compromises the security of the connections and must only be used for debugging.
+func KeyLog(c *tls.Config, w io.Writer) *tls.Config {
+	if c == nil || w == nil {
+		return c
+	}
+	log.Warn("[TLS] the key log writer is enabled, the TLS sessions can be decrypted, DO NOT use it in production")
+	c = c.Clone()
+	c.KeyLogWriter = w
+	return c
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+var _ Provider = (*FileProvider)(nil)
+
+// FileProvider provides the certificate of the files, which is reloaded
+// once the files change, so that the certificates could rotate without
+// restarting the server.
+type FileProvider struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Value
+	watcher  *fsnotify.Watcher
+}
+
+// NewFileProvider creates a certificate provider of the PEM encoded certificate and key files.
+func NewFileProvider(certFile, keyFile string) (*FileProvider, error) {
+	p := &FileProvider{certFile: certFile, keyFile: keyFile}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// watch the directories, the files may be replaced by renames or symlinks.
+	for _, dir := range []string{filepath.Dir(certFile), filepath.Dir(keyFile)} {
+		if err = w.Add(dir); err != nil {
+			_ = w.Close()
+			return nil, err
+		}
+	}
+	p.watcher = w
+	go p.watch()
+	return p, nil
+}
+
+func (p *FileProvider) watch() {
+	for {
+		select {
+		case _, ok := <-p.watcher.Events:
+			if !ok {
+				return
+			}
+			if err := p.Reload(); err != nil {
+				log.Errorf("failed to reload tls certificate: %v", err)
+			}
+		case err, ok := <-p.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Errorf("failed to watch tls certificate: %v", err)
+		}
+	}
+}
+
+// Reload reloads the certificate files, the loaded certificate
+// is kept if the files are invalid.
+func (p *FileProvider) Reload() error {
+	cert, err := tls.LoadX509KeyPair(p.certFile, p.keyFile)
+	if err != nil {
+		return err
+	}
+	p.cert.Store(&cert)
+	return nil
+}
+
+// GetCertificate returns the loaded certificate.
+func (p *FileProvider) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert, ok := p.cert.Load().(*tls.Certificate)
+	if !ok {
+		return nil, errors.New("no tls certificate loaded")
+	}
+	return cert, nil
+}
+
+// Close stops watching the certificate files.
+func (p *FileProvider) Close() error {
+	return p.watcher.Close()
+}
@@ -0,0 +1,129 @@
+package tlscert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCert(t *testing.T, certFile, keyFile, cn string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// write the key first, the provider reloads once both files are updated.
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func commonName(t *testing.T, p Provider) string {
+	cert, err := p.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x.Subject.CommonName
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if _, err := NewFileProvider(certFile, keyFile); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	writeCert(t, certFile, keyFile, "v1")
+	p, err := NewFileProvider(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if cn := commonName(t, p); cn != "v1" {
+		t.Fatalf("expected %s got %s", "v1", cn)
+	}
+
+	writeCert(t, certFile, keyFile, "v2")
+	deadline := time.Now().Add(3 * time.Second)
+	for commonName(t, p) != "v2" {
+		if time.Now().After(deadline) {
+			t.Fatal("certificate is not reloaded")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+type acmeProvider struct{}
+
+func (acmeProvider) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return &tls.Certificate{}, nil
+}
+
+func (acmeProvider) TLSConfig() *tls.Config {
+	return &tls.Config{NextProtos: []string{"h2", "http/1.1", "acme-tls/1"}}
+}
+
+func TestConfig(t *testing.T) {
+	base := &tls.Config{NextProtos: []string{"h2"}, MinVersion: tls.VersionTLS13}
+	c := Config(base, acmeProvider{})
+	if c.GetCertificate == nil {
+		t.Fatal("expected GetCertificate, got nil")
+	}
+	if c.MinVersion != tls.VersionTLS13 {
+		t.Errorf("expected %v got %v", tls.VersionTLS13, c.MinVersion)
+	}
+	if len(c.NextProtos) != 3 || c.NextProtos[2] != "acme-tls/1" {
+		t.Errorf("unexpected next protos: %v", c.NextProtos)
+	}
+	if len(base.NextProtos) != 1 {
+		t.Errorf("expected base config unchanged, got %v", base.NextProtos)
+	}
+	if c = Config(nil, acmeProvider{}); c.MinVersion != tls.VersionTLS12 {
+		t.Errorf("expected %v got %v", tls.VersionTLS12, c.MinVersion)
+	}
+}
+
+func TestKeyLog(t *testing.T) {
+	if c := KeyLog(nil, os.Stderr); c != nil {
+		t.Errorf("expected nil config got %v", c)
+	}
+	base := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c := KeyLog(base, nil); c != base {
+		t.Errorf("expected base config without writer")
+	}
+	c := KeyLog(base, os.Stderr)
+	if c == base || c.KeyLogWriter != os.Stderr {
+		t.Errorf("expected a copy with the key log writer")
+	}
+	if base.KeyLogWriter != nil {
+		t.Errorf("expected base config unchanged")
+	}
+}
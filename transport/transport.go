@@ -18,11 +18,29 @@ type Server interface {
 	Stop(context.Context) error
 }
 
+// Drainer is a server which stops accepting new work before it is stopped,
+// e.g. by disabling the keep-alives or failing the health checks.
+type Drainer interface {
+	Drain(context.Context) error
+}
+
 // Endpointer is registry endpoint.
 type Endpointer interface {
 	Endpoint() (*url.URL, error)
 }
 
+// Endpointers is a server which advertises multiple endpoints, e.g. of the
+// listeners of different protocols, it is preferred over the Endpointer.
+type Endpointers interface {
+	Endpoints() ([]*url.URL, error)
+}
+
+// Prober is a server which probes its advertised endpoints by the loopback
+// requests, e.g. of the self-test of the App, to check they are reachable.
+type Prober interface {
+	Probe(ctx context.Context, endpoint *url.URL) error
+}
+
 // Header is the storage medium used by a Header.
 type Header interface {
 	Get(key string) string
@@ -63,8 +81,11 @@ func (k Kind) String() string { return string(k) }
 
 // Defines a set of transport kind
 const (
-	KindGRPC Kind = "grpc"
-	KindHTTP Kind = "http"
+	KindGRPC   Kind = "grpc"
+	KindHTTP   Kind = "http"
+	KindWorker Kind = "worker"
+	KindCron   Kind = "cron"
+	KindEvent  Kind = "event"
 )
 
 type (
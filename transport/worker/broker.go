@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Message is a message of a topic.
+type Message struct {
+	// ID is the ID of the message set by the broker.
+	ID    string
+	Topic string
+	// Key is the key of the message, e.g. the key of the ordering or of the
+	// partition, if the broker supports.
+	Key    string
+	Header map[string]string
+	Body   []byte
+	// Attempt is the number of the failed attempts of handling it before.
+	Attempt int
+	// Time is the time the message is published at.
+	Time time.Time
+}
+
+// BrokerHandler handles a message delivered by the broker, the message is
+// acknowledged if it returns nil, or else redelivered.
+type BrokerHandler func(ctx context.Context, msg *Message) error
+
+// Broker delivers the messages of the topics, e.g. MemoryBroker in process,
+// or the brokers of Redis streams or NATS JetStream.
+type Broker interface {
+	// Publish publishes the message to its topic.
+	Publish(ctx context.Context, msg *Message) error
+	// Subscribe delivers the messages of the topic to the handler one at a
+	// time until ctx is done, the subscribers of a topic compete for them.
+	Subscribe(ctx context.Context, topic string, handler BrokerHandler) error
+}
+
+var _ Broker = (*MemoryBroker)(nil)
+
+// MemoryBroker is an in-memory Broker, of which the messages are lost if the process exits.
+type MemoryBroker struct {
+	mu     sync.Mutex
+	topics map[string]chan *Message
+	size   int
+	seq    atomic.Uint64
+}
+
+// NewMemoryBroker new an in-memory broker buffering the size of the messages
+// of a topic, the publishers are blocked if it is full.
+func NewMemoryBroker(size int) *MemoryBroker {
+	return &MemoryBroker{topics: make(map[string]chan *Message), size: size}
+}
+
+func (b *MemoryBroker) topic(name string) chan *Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch, ok := b.topics[name]
+	if !ok {
+		ch = make(chan *Message, b.size)
+		b.topics[name] = ch
+	}
+	return ch
+}
+
+// Publish publishes the message to its topic.
+func (b *MemoryBroker) Publish(ctx context.Context, msg *Message) error {
+	m := *msg
+	if m.ID == "" {
+		m.ID = strconv.FormatUint(b.seq.Add(1), 10)
+	}
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+	select {
+	case b.topic(m.Topic) <- &m:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Subscribe delivers the messages of the topic to the handler until ctx is done,
+// the messages failed are redelivered unless the buffer is full.
+func (b *MemoryBroker) Subscribe(ctx context.Context, topic string, handler BrokerHandler) error {
+	ch := b.topic(topic)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-ch:
+			if err := handler(ctx, msg); err != nil {
+				select {
+				case ch <- msg:
+				default:
+					log.Errorf("[worker] memory broker dropped the message %s of %s: %v", msg.ID, topic, err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Package worker is the server of the background jobs, which handles the
+// messages of the topics of a broker with the middleware, the retries of the
+// backoff and the dead letter topics, and starts and stops with kratos.App:
+//
+//	srv := worker.NewServer(
+//		worker.WithBroker(broker),
+//		worker.Middleware(recovery.Recovery(), tracing.Server()),
+//		worker.DeadLetter(".dlq"),
+//	)
+//	srv.Handle("email.send", func(ctx context.Context, msg *worker.Message) error {
+//		return sendEmail(ctx, msg.Body)
+//	})
+//	app := kratos.New(kratos.Server(httpSrv, srv))
+package worker
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	ictx "github.com/go-kratos/kratos/v2/internal/context"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Server = (*Server)(nil)
+
+// ErrServerStarted is returned by Start if the server is started.
+var ErrServerStarted = errors.New("worker: server started")
+
+// Handler handles a message of a topic.
+type Handler func(ctx context.Context, msg *Message) error
+
+// ServerOption is worker server option.
+type ServerOption func(*Server)
+
+// WithBroker with the broker of the messages, default is a MemoryBroker of 1024 messages a topic.
+func WithBroker(b Broker) ServerOption {
+	return func(s *Server) {
+		s.broker = b
+	}
+}
+
+// Middleware with server middleware, the requests of which are the *Message.
+func Middleware(m ...middleware.Middleware) ServerOption {
+	return func(s *Server) {
+		s.middleware = m
+	}
+}
+
+// Concurrency with the number of the messages of a topic handled at a time, default is 1.
+func Concurrency(n int) ServerOption {
+	return func(s *Server) {
+		s.concurrency = n
+	}
+}
+
+// Timeout with the timeout of handling a message, default is none.
+func Timeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeout = timeout
+	}
+}
+
+// RetryMax with the max number of retries after the first attempt of a
+// message, the errors.ClassPermanent errors are not retried, default is 3.
+func RetryMax(n int) ServerOption {
+	return func(s *Server) {
+		s.retryMax = n
+	}
+}
+
+// RetryBackoff with the exponential backoff base and max delay of the retries,
+// a random jitter of up to half the delay is applied, default is 100ms and 10s.
+func RetryBackoff(base, max time.Duration) ServerOption {
+	return func(s *Server) {
+		s.backoffBase = base
+		s.backoffMax = max
+	}
+}
+
+// DeadLetter with the suffix of the dead letter topics of the messages failed
+// after the retries, e.g. ".dlq" of "email.send.dlq", whose X-Error header is
+// the error, default is none which drops them.
+func DeadLetter(suffix string) ServerOption {
+	return func(s *Server) {
+		s.deadLetter = suffix
+	}
+}
+
+// Server is a worker server of the messages of the topics of a broker.
+type Server struct {
+	broker      Broker
+	middleware  []middleware.Middleware
+	concurrency int
+	timeout     time.Duration
+	retryMax    int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	deadLetter  string
+
+	mu       sync.Mutex
+	handlers map[string]middleware.Handler
+	started  bool
+	stop     context.CancelFunc
+	cancel   context.CancelFunc
+	base     context.Context
+	wg       sync.WaitGroup
+}
+
+// NewServer creates a worker server by options.
+func NewServer(opts ...ServerOption) *Server {
+	srv := &Server{
+		concurrency: 1,
+		retryMax:    3,
+		backoffBase: 100 * time.Millisecond,
+		backoffMax:  10 * time.Second,
+		handlers:    make(map[string]middleware.Handler),
+	}
+	for _, o := range opts {
+		o(srv)
+	}
+	if srv.broker == nil {
+		srv.broker = NewMemoryBroker(1024)
+	}
+	return srv
+}
+
+// Handle registers the handler of the messages of the topic, before the server is started.
+func (s *Server) Handle(topic string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, h(ctx, req.(*Message))
+	}
+	if len(s.middleware) > 0 {
+		next = middleware.Chain(s.middleware...)(next)
+	}
+	s.handlers[topic] = next
+}
+
+// Publish publishes the message to the broker of the server, e.g. enqueues a job.
+func (s *Server) Publish(ctx context.Context, msg *Message) error {
+	return s.broker.Publish(ctx, msg)
+}
+
+// Start starts the subscriptions of the topics and blocks until the server is stopped.
+func (s *Server) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return ErrServerStarted
+	}
+	s.started = true
+	// the handlers outlive the subscriptions and ctx until they are stopped.
+	s.base, s.cancel = context.WithCancel(ictx.Detach(ctx))
+	subCtx, stop := context.WithCancel(ctx)
+	s.stop = stop
+	errc := make(chan error, len(s.handlers)*s.concurrency)
+	for topic, h := range s.handlers {
+		for i := 0; i < s.concurrency; i++ {
+			s.wg.Add(1)
+			go func(topic string, h middleware.Handler) {
+				defer s.wg.Done()
+				if err := s.broker.Subscribe(subCtx, topic, func(_ context.Context, msg *Message) error {
+					return s.handle(topic, h, msg)
+				}); err != nil {
+					errc <- err
+				}
+			}(topic, h)
+		}
+	}
+	s.mu.Unlock()
+	log.Infof("[worker] server started with %d topics", len(s.handlers))
+	select {
+	case <-subCtx.Done():
+		return nil
+	case err := <-errc:
+		stop()
+		return err
+	}
+}
+
+// Stop stops the subscriptions, and waits for the messages being handled
+// until ctx is done, when their contexts are canceled.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	stop, cancel := s.stop, s.cancel
+	s.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+	log.Info("[worker] server stopping")
+	stop()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	defer cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handle handles the message with the retries and dead letters it if failed,
+// the error is returned if it is not done, so that the broker redelivers it.
+func (s *Server) handle(topic string, h middleware.Handler, msg *Message) error {
+	if msg.Header == nil {
+		msg.Header = map[string]string{}
+	}
+	var err error
+	for retry := 0; ; retry++ {
+		if err = s.invoke(topic, h, msg); err == nil {
+			return nil
+		}
+		msg.Attempt++
+		if s.base.Err() != nil {
+			return err
+		}
+		if retry >= s.retryMax || kerrors.Classify(err) == kerrors.ClassPermanent {
+			break
+		}
+		select {
+		case <-time.After(s.backoff(retry)):
+		case <-s.base.Done():
+			return err
+		}
+	}
+	if s.deadLetter == "" {
+		log.Errorf("[worker] dropped the message %s of %s: %v", msg.ID, topic, err)
+		return nil
+	}
+	dead := *msg
+	dead.ID = ""
+	dead.Topic = topic + s.deadLetter
+	dead.Header = make(map[string]string, len(msg.Header)+1)
+	for k, v := range msg.Header {
+		dead.Header[k] = v
+	}
+	dead.Header["X-Error"] = err.Error()
+	if perr := s.broker.Publish(s.base, &dead); perr != nil {
+		log.Errorf("[worker] failed to dead letter the message %s of %s: %v", msg.ID, topic, perr)
+		return err
+	}
+	return nil
+}
+
+func (s *Server) invoke(topic string, h middleware.Handler, msg *Message) error {
+	ctx := transport.NewServerContext(s.base, &Transport{
+		operation:   topic,
+		message:     msg,
+		replyHeader: headerCarrier{},
+	})
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	_, err := h(ctx, msg)
+	return err
+}
+
+// backoff returns the delay of the retry, of an exponential backoff with jitter.
+func (s *Server) backoff(retry int) time.Duration {
+	d := s.backoffBase << uint(retry)
+	if d <= 0 || d > s.backoffMax {
+		d = s.backoffMax
+	}
+	//nolint:gosec
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
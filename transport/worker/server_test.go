@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func start(t *testing.T, srv *Server) {
+	t.Helper()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Start(context.Background()) }()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := srv.Stop(ctx); err != nil {
+			t.Errorf("stop: %v", err)
+		}
+		if err := <-errc; err != nil {
+			t.Errorf("start: %v", err)
+		}
+	})
+}
+
+func receive(t *testing.T, ch <-chan *Message) *Message {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+		return nil
+	}
+}
+
+func TestServer(t *testing.T) {
+	var operation atomic.Value
+	m := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok && tr.Kind() == transport.KindWorker {
+				operation.Store(tr.Operation() + " " + tr.RequestHeader().Get("trace"))
+			}
+			return handler(ctx, req)
+		}
+	}
+	srv := NewServer(Middleware(m), RetryBackoff(time.Millisecond, time.Millisecond))
+	done := make(chan *Message, 1)
+	var calls atomic.Int32
+	srv.Handle("email.send", func(ctx context.Context, msg *Message) error {
+		if calls.Add(1) < 3 {
+			return errors.New("unavailable")
+		}
+		done <- msg
+		return nil
+	})
+	start(t, srv)
+	if err := srv.Publish(context.Background(), &Message{Topic: "email.send", Header: map[string]string{"trace": "1"}, Body: []byte("hi")}); err != nil {
+		t.Fatal(err)
+	}
+	msg := receive(t, done)
+	if string(msg.Body) != "hi" || msg.Attempt != 2 || msg.ID == "" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if v := operation.Load(); v != "email.send 1" {
+		t.Errorf("unexpected transport %v", v)
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	srv := NewServer(RetryMax(5), RetryBackoff(time.Millisecond, time.Millisecond), DeadLetter(".dlq"))
+	var calls atomic.Int32
+	srv.Handle("job", func(ctx context.Context, msg *Message) error {
+		calls.Add(1)
+		return kerrors.BadRequest("INVALID", "invalid job")
+	})
+	dead := make(chan *Message, 1)
+	srv.Handle("job.dlq", func(ctx context.Context, msg *Message) error {
+		dead <- msg
+		return nil
+	})
+	start(t, srv)
+	if err := srv.Publish(context.Background(), &Message{Topic: "job", Body: []byte("x")}); err != nil {
+		t.Fatal(err)
+	}
+	msg := receive(t, dead)
+	if msg.Topic != "job.dlq" || string(msg.Body) != "x" || msg.Header["X-Error"] == "" {
+		t.Errorf("unexpected dead letter %+v", msg)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected the permanent error not retried, got %d calls", n)
+	}
+}
+
+func TestStopWaits(t *testing.T) {
+	srv := NewServer()
+	started := make(chan struct{})
+	var finished atomic.Bool
+	srv.Handle("slow", func(ctx context.Context, msg *Message) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	})
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Start(context.Background()) }()
+	_ = srv.Publish(context.Background(), &Message{Topic: "slow"})
+	<-started
+	if err := srv.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !finished.Load() {
+		t.Error("expected the message handled before stopped")
+	}
+	if err := <-errc; err != nil {
+		t.Error(err)
+	}
+}
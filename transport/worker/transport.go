@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Transporter = (*Transport)(nil)
+
+// Transport is a worker transport of a message.
+type Transport struct {
+	endpoint    string
+	operation   string
+	message     *Message
+	replyHeader headerCarrier
+}
+
+// Kind returns the transport kind.
+func (tr *Transport) Kind() transport.Kind {
+	return transport.KindWorker
+}
+
+// Endpoint returns the transport endpoint.
+func (tr *Transport) Endpoint() string {
+	return tr.endpoint
+}
+
+// Operation returns the transport operation, which is the topic of the message.
+func (tr *Transport) Operation() string {
+	return tr.operation
+}
+
+// RequestHeader returns the header of the message.
+func (tr *Transport) RequestHeader() transport.Header {
+	return headerCarrier(tr.message.Header)
+}
+
+// ReplyHeader returns the reply header, which is not delivered.
+func (tr *Transport) ReplyHeader() transport.Header {
+	return tr.replyHeader
+}
+
+// Message returns the message handled.
+func (tr *Transport) Message() *Message {
+	return tr.message
+}
+
+type headerCarrier map[string]string
+
+// Get returns the value associated with the passed key.
+func (hc headerCarrier) Get(key string) string {
+	return hc[key]
+}
+
+// Set stores the key-value pair.
+func (hc headerCarrier) Set(key string, value string) {
+	hc[key] = value
+}
+
+// Add sets the value of key, as the message headers are single valued.
+func (hc headerCarrier) Add(key string, value string) {
+	hc[key] = value
+}
+
+// Keys lists the keys stored in this carrier.
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range hc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns a slice of values associated with the passed key.
+func (hc headerCarrier) Values(key string) []string {
+	if v, ok := hc[key]; ok {
+		return []string{v}
+	}
+	return nil
+}